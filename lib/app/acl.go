@@ -227,6 +227,18 @@ func (r *ApplicationsACL) StreamAppHookLogs(ctx context.Context, ref HookRef, ou
 	return r.applications.StreamAppHookLogs(ctx, ref, out)
 }
 
+// GetAppHookRuns returns the recorded application hook runs matching the filter
+func (r *ApplicationsACL) GetAppHookRuns(filter storage.HookRunFilter) ([]storage.HookRun, error) {
+	repository := defaults.SystemAccountOrg
+	if filter.Application != nil {
+		repository = filter.Application.Repository
+	}
+	if err := r.check(repository, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return r.applications.GetAppHookRuns(filter)
+}
+
 // FetchChart returns Helm chart package with the specified application.
 func (r *ApplicationsACL) FetchChart(locator loc.Locator) (io.ReadCloser, error) {
 	if err := r.checkApp(locator, teleservices.VerbRead); err != nil {
@@ -110,6 +110,9 @@ type Applications interface {
 	// StreamAppHookLogs streams app hook logs to output writer, this is a blocking call
 	StreamAppHookLogs(ctx context.Context, ref HookRef, out io.Writer) error
 
+	// GetAppHookRuns returns the recorded application hook runs matching the filter
+	GetAppHookRuns(storage.HookRunFilter) ([]storage.HookRun, error)
+
 	// FetchChart returns Helm chart package with the specified application.
 	FetchChart(loc.Locator) (io.ReadCloser, error)
 
@@ -123,6 +126,9 @@ type DeleteAppHookJobRequest struct {
 	HookRef
 	// Cascade specifies whether dependent pods should be deleted as well.
 	Cascade bool `json:"cascade"`
+	// DeleteNamespace specifies whether the job's namespace should be deleted
+	// as well. Used for jobs run in a scratch namespace.
+	DeleteNamespace bool `json:"delete_namespace"`
 }
 
 // ListAppsRequest is a request to show applications in a repository
@@ -237,6 +243,9 @@ type HookRunRequest struct {
 	// which will be replaced with the ID of the effective service user during
 	// installation and when running application hooks.
 	ServiceUser storage.OSUser
+	// Namespace optionally overrides the namespace the hook job is created in.
+	// The namespace is created if it does not exist
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // Check validates this request
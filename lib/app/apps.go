@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/gravitational/gravity/lib/app/hooks"
 	"github.com/gravitational/gravity/lib/defaults"
@@ -41,8 +42,48 @@ func RunAppHook(ctx context.Context, apps Applications, req HookRunRequest) (*Ho
 }
 
 // StreamAppHook launches the specified hook and starts streaming its
-// output into the provided writer until the job completes
+// output into the provided writer until the job completes.
+//
+// A failed hook is retried according to the hook's retry policy.
+// If the hook fails after all retries, the returned error is a *HookError
+// that carries the hook's failure policy
 func StreamAppHook(ctx context.Context, apps Applications, req HookRunRequest, wc io.WriteCloser) (*HookRef, error) {
+	defer wc.Close()
+	hook, err := CheckHasAppHook(apps, req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	backoff := hook.GetRetryBackoff()
+	for attempt := 0; ; attempt++ {
+		ref, err := streamAppHook(ctx, apps, req, wc)
+		if err == nil {
+			return ref, nil
+		}
+		if ref == nil || ctx.Err() != nil {
+			return ref, trace.Wrap(err)
+		}
+		if attempt >= hook.Retries {
+			return ref, trace.Wrap(&HookError{
+				Hook:      req.Hook,
+				OnFailure: hook.GetFailurePolicy(),
+				Attempts:  attempt + 1,
+				Err:       err,
+			})
+		}
+		log.Warnf("Hook %v failed, retrying in %v (%v/%v).", ref, backoff, attempt+1, hook.Retries)
+		fmt.Fprintf(wc, "Hook %v failed, retrying in %v (%v/%v).\n", req.Hook, backoff, attempt+1, hook.Retries)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ref, trace.Wrap(err)
+		}
+		backoff *= 2
+	}
+}
+
+// streamAppHook runs a single attempt of the specified hook streaming
+// its output into the provided writer until the job completes
+func streamAppHook(ctx context.Context, apps Applications, req HookRunRequest, w io.Writer) (*HookRef, error) {
 	ref, err := apps.StartAppHook(ctx, req)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -53,8 +94,7 @@ func StreamAppHook(ctx context.Context, apps Applications, req HookRunRequest, w
 
 	go func() {
 		defer localCancel()
-		err := apps.StreamAppHookLogs(ctx, *ref, wc)
-		wc.Close()
+		err := apps.StreamAppHookLogs(ctx, *ref, w)
 		if err != nil && !trace.IsEOF(err) {
 			log.Warnf("Failed to stream logs for hook %v: %v",
 				ref, trace.DebugReport(err))
@@ -84,6 +124,42 @@ func StreamAppHook(ctx context.Context, apps Applications, req HookRunRequest, w
 	return ref, trace.Wrap(err)
 }
 
+// HookError is returned when a hook has failed after all retries
+type HookError struct {
+	// Hook is the type of the failed hook
+	Hook schema.HookType
+	// OnFailure is the failure policy of the hook
+	OnFailure schema.HookFailurePolicy
+	// Attempts is the number of times the hook has been run
+	Attempts int
+	// Err is the error of the last hook attempt
+	Err error
+}
+
+// Error returns the error message
+func (e *HookError) Error() string {
+	return fmt.Sprintf("hook %v failed after %v attempt(s): %v",
+		e.Hook, e.Attempts, trace.UserMessage(e.Err))
+}
+
+// HookFailurePolicy returns the failure policy of the hook that has failed
+// with the specified error. Errors not caused by a hook failure abort
+// the operation. If err aggregates several errors, rollback takes
+// precedence over other policies
+func HookFailurePolicy(err error) schema.HookFailurePolicy {
+	switch e := trace.Unwrap(err).(type) {
+	case *HookError:
+		return e.OnFailure
+	case trace.Aggregate:
+		for _, err := range e.Errors() {
+			if HookFailurePolicy(err) == schema.HookFailureRollback {
+				return schema.HookFailureRollback
+			}
+		}
+	}
+	return schema.HookFailureAbort
+}
+
 // CheckHasAppHook checks if the app has specified hook
 func CheckHasAppHook(apps Applications, req HookRunRequest) (*schema.Hook, error) {
 	app, err := apps.GetApp(req.Application)
@@ -129,8 +205,9 @@ func DeleteAppHookJob(ctx context.Context, client *kubernetes.Clientset, req Del
 		return trace.Wrap(err)
 	}
 	return runner.DeleteJob(ctx, hooks.DeleteJobRequest{
-		JobRef:  hooks.JobRef{Name: req.Name, Namespace: req.Namespace},
-		Cascade: req.Cascade,
+		JobRef:          hooks.JobRef{Name: req.Name, Namespace: req.Namespace},
+		Cascade:         req.Cascade,
+		DeleteNamespace: req.DeleteNamespace,
 	})
 }
 
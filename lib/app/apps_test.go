@@ -23,6 +23,7 @@ import (
 	"github.com/gravitational/gravity/lib/pack"
 	"github.com/gravitational/gravity/lib/schema"
 
+	"github.com/gravitational/trace"
 	. "gopkg.in/check.v1"
 )
 
@@ -32,6 +33,16 @@ type AppUtilsSuite struct{}
 
 var _ = Suite(&AppUtilsSuite{})
 
+func (s *AppUtilsSuite) TestHookFailurePolicy(c *C) {
+	rollback := trace.Wrap(&HookError{Hook: schema.HookUpdate, OnFailure: schema.HookFailureRollback})
+	abort := trace.Wrap(&HookError{Hook: schema.HookUpdated, OnFailure: schema.HookFailureAbort})
+
+	c.Assert(HookFailurePolicy(trace.BadParameter("not a hook")), Equals, schema.HookFailureAbort)
+	c.Assert(HookFailurePolicy(trace.Wrap(rollback, "failed to execute phase")), Equals, schema.HookFailureRollback)
+	c.Assert(HookFailurePolicy(trace.NewAggregate(abort, rollback)), Equals, schema.HookFailureRollback)
+	c.Assert(HookFailurePolicy(trace.NewAggregate(abort)), Equals, schema.HookFailureAbort)
+}
+
 func (s *AppUtilsSuite) TestUpdatedDependencies(c *C) {
 	manifest, err := schema.ParseManifestYAMLNoValidate([]byte(appManifest))
 	c.Assert(err, IsNil)
@@ -375,13 +375,43 @@ func (c *Client) StreamAppHookLogs(ctx context.Context, ref app.HookRef, out io.
 func (c *Client) DeleteAppHookJob(ctx context.Context, req app.DeleteAppHookJobRequest) error {
 	_, err := c.Delete(c.Endpoint(
 		"applications", req.Application.Repository, req.Application.Name, req.Application.Version, "hook", req.Namespace, req.Name),
-		url.Values{"cascade": []string{strconv.FormatBool(req.Cascade)}})
+		url.Values{
+			"cascade":          []string{strconv.FormatBool(req.Cascade)},
+			"delete_namespace": []string{strconv.FormatBool(req.DeleteNamespace)},
+		})
 	if err != nil {
 		return trace.Wrap(err)
 	}
 	return nil
 }
 
+// GetAppHookRuns returns the recorded application hook runs matching the filter
+//
+// GET app/v1/hookruns
+func (c *Client) GetAppHookRuns(filter storage.HookRunFilter) ([]storage.HookRun, error) {
+	query := url.Values{}
+	if filter.Application != nil {
+		query.Set("repository", filter.Application.Repository)
+		query.Set("name", filter.Application.Name)
+		query.Set("version", filter.Application.Version)
+	}
+	if filter.Hook != "" {
+		query.Set("hook", filter.Hook)
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	out, err := c.Get(c.Endpoint("hookruns"), query)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var runs []storage.HookRun
+	if err = json.Unmarshal(out.Bytes(), &runs); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return runs, nil
+}
+
 // FetchChart returns Helm chart package with the specified application.
 //
 // GET charts/:name
@@ -120,6 +120,7 @@ func NewWebHandler(cfg WebHandlerConfig) (*WebHandler, error) {
 	h.GET("/app/v1/applications/:repository_id/:package_id/:version/hook/:namespace/:name/wait", h.needsAuth(h.waitAppHook))
 	h.GET("/app/v1/applications/:repository_id/:package_id/:version/hook/:namespace/:name/stream", h.needsAuth(h.streamAppHookLogs))
 	h.DELETE("/app/v1/applications/:repository_id/:package_id/:version/hook/:namespace/:name", h.needsAuth(h.deleteAppHookJob))
+	h.GET("/app/v1/hookruns", h.needsAuth(h.getAppHookRuns))
 
 	h.GET("/app/v1/applications/:repository_id/:package_id/:version/status", h.needsAuth(h.getAppStatus))
 	h.DELETE("/app/v1/applications/:repository_id/:package_id/:version", h.needsAuth(h.deleteApp))
@@ -757,6 +758,46 @@ func (h *WebHandler) streamAppHookLogs(w http.ResponseWriter,
 	return nil
 }
 
+/* getAppHookRuns returns the recorded application hook runs
+
+GET /app/v1/hookruns?repository=<repository>&name=<name>&version=<version>&hook=<hook>&limit=<limit>
+
+Success Response:
+
+   [{"id": "...", "hook": "install", "state": "completed", ...}]
+*/
+func (h *WebHandler) getAppHookRuns(w http.ResponseWriter,
+	req *http.Request, params httprouter.Params,
+	context *handlerContext) error {
+
+	filter := storage.HookRunFilter{
+		Hook: req.FormValue("hook"),
+	}
+	if name := req.FormValue("name"); name != "" {
+		filter.Application = &loc.Locator{
+			Repository: req.FormValue("repository"),
+			Name:       name,
+			Version:    req.FormValue("version"),
+		}
+	}
+	if limit := req.FormValue("limit"); limit != "" {
+		var err error
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return trace.BadParameter("invalid limit %q", limit)
+		}
+	}
+	runs, err := context.applications.GetAppHookRuns(filter)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if runs == nil {
+		runs = []storage.HookRun{}
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, runs)
+	return nil
+}
+
 /* deleteAppHookJob deletes app hook job
 
 DLETE /app/v1/applications/:repository_id/:package_id/:version/hook/:namespace/:name
@@ -782,9 +823,14 @@ func (h *WebHandler) deleteAppHookJob(w http.ResponseWriter,
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	deleteNamespace, _, err := telehttplib.ParseBool(req.URL.Query(), "delete_namespace")
+	if err != nil {
+		return trace.Wrap(err)
+	}
 	err = context.applications.DeleteAppHookJob(req.Context(), app.DeleteAppHookJobRequest{
-		HookRef: hookRef,
-		Cascade: cascade,
+		HookRef:         hookRef,
+		Cascade:         cascade,
+		DeleteNamespace: deleteNamespace,
 	})
 	if err != nil {
 		return trace.Wrap(err)
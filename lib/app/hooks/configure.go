@@ -74,6 +74,10 @@ func configureMetadata(job *batchv1.Job, p Params) error {
 	if job.ObjectMeta.Namespace == "" {
 		job.ObjectMeta.Namespace = defaults.KubeSystemNamespace
 	}
+	// namespace requested by the caller takes precedence
+	if p.Namespace != "" {
+		job.ObjectMeta.Namespace = p.Namespace
+	}
 
 	// make sure the name is unique
 	suffix, err := teleutils.CryptoRandomHex(3)
@@ -48,3 +48,12 @@ func (s *ConfigureSuite) TestConfigureMetadata(c *check.C) {
 	c.Assert(*job.Spec.ActiveDeadlineSeconds, check.Equals, int64(deadline.Seconds()))
 	c.Assert(job.Spec.Template.Spec.SecurityContext, check.DeepEquals, defaults.HookSecurityContext())
 }
+
+func (s *ConfigureSuite) TestConfigureMetadataNamespace(c *check.C) {
+	job := &batchv1.Job{}
+	job.ObjectMeta.Namespace = "default"
+
+	err := configureMetadata(job, Params{Namespace: "restore-verify"})
+	c.Assert(err, check.IsNil)
+	c.Assert(job.ObjectMeta.Namespace, check.Equals, "restore-verify")
+}
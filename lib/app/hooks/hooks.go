@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
 	"time"
 
 	"github.com/gravitational/gravity/lib/constants"
@@ -85,6 +86,8 @@ type Params struct {
 	ServiceUser storage.OSUser
 	// Values are helm values in a marshaled yaml format
 	Values []byte
+	// Namespace optionally overrides the namespace the job is created in
+	Namespace string
 }
 
 // JobRef is a reference to a hook job
@@ -134,6 +137,8 @@ type DeleteJobRequest struct {
 	JobRef
 	// Cascade specifies whether dependent objects should be deleted.
 	Cascade bool
+	// DeleteNamespace specifies whether the job's namespace should be deleted.
+	DeleteNamespace bool
 }
 
 // DeleteJob deletes job by ref
@@ -151,6 +156,13 @@ func (r *Runner) DeleteJob(ctx context.Context, req DeleteJobRequest) error {
 	} else {
 		r.Debugf("Deleted job %q in namespace %q.", req.Name, req.Namespace)
 	}
+	if req.DeleteNamespace {
+		err := r.client.CoreV1().Namespaces().Delete(req.Namespace, &metav1.DeleteOptions{})
+		if err = rigging.ConvertError(err); err != nil && !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+		r.Debugf("Deleted namespace %q.", req.Namespace)
+	}
 	return nil
 }
 
@@ -291,6 +303,89 @@ func (r *Runner) StreamLogs(ctx context.Context, ref JobRef, out io.Writer) erro
 	return nil
 }
 
+// CollectLogs writes the logs of all containers of the job's pods to out.
+// Unlike StreamLogs, it does not follow the logs and is meant to be used
+// to capture the output of a job that has already completed or failed
+func (r *Runner) CollectLogs(ctx context.Context, ref JobRef, out io.Writer) error {
+	pods, err := r.getJobPods(ref)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, pod := range pods {
+		var containers []v1.Container
+		containers = append(containers, pod.Spec.InitContainers...)
+		containers = append(containers, pod.Spec.Containers...)
+		for _, container := range containers {
+			if ctx.Err() != nil {
+				return trace.Wrap(ctx.Err())
+			}
+			fmt.Fprintf(out, "%v, container %q:\n", describe(&pod), container.Name)
+			req := r.client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+				Container: container.Name,
+			})
+			readCloser, err := req.Stream()
+			if err != nil {
+				fmt.Fprintf(out, "failed to get logs: %v\n", err)
+				continue
+			}
+			_, err = io.Copy(out, readCloser)
+			readCloser.Close()
+			if err != nil {
+				return trace.Wrap(err)
+			}
+		}
+	}
+	return nil
+}
+
+// ExitCode returns the exit code of the containers of the most recent
+// job's pod. The first non-zero exit code is returned if several
+// containers have failed
+func (r *Runner) ExitCode(ref JobRef) (int, error) {
+	pods, err := r.getJobPods(ref)
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	if len(pods) == 0 {
+		return 0, trace.NotFound("job %v/%v has no pods", ref.Namespace, ref.Name)
+	}
+	pod := pods[len(pods)-1]
+	terminated := false
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated == nil {
+			continue
+		}
+		if status.State.Terminated.ExitCode != 0 {
+			return int(status.State.Terminated.ExitCode), nil
+		}
+		terminated = true
+	}
+	if !terminated {
+		return 0, trace.NotFound("%v has not terminated", describe(&pod))
+	}
+	return 0, nil
+}
+
+// getJobPods returns the pods created by the job sorted by creation time
+func (r *Runner) getJobPods(ref JobRef) ([]v1.Pod, error) {
+	job, err := r.client.BatchV1().Jobs(ref.Namespace).Get(ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, rigging.ConvertError(err)
+	}
+	podSet, err := r.collectPods(job)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	pods := make([]v1.Pod, 0, len(podSet))
+	for _, pod := range podSet {
+		pods = append(pods, pod)
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].CreationTimestamp.Before(&pods[j].CreationTimestamp)
+	})
+	return pods, nil
+}
+
 func (r *Runner) monitorPods(ctx context.Context, eventsC <-chan watch.Event,
 	job batchv1.Job, jobControl rigging.JobControl, w io.Writer) error {
 	// podSet keeps state of currently monitored pods
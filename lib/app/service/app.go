@@ -320,18 +320,24 @@ func (r *applications) StartAppHook(ctx context.Context, req appservice.HookRunR
 		GravityPackage:     req.GravityPackage,
 		ServiceUser:        req.ServiceUser,
 		Values:             req.Values,
+		Namespace:          req.Namespace,
+	}
+	if params.JobDeadline == 0 {
+		params.JobDeadline = hook.GetTimeout()
 	}
 
 	ref, err := runner.Start(ctx, params)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	return &appservice.HookRef{
+	hookRef := appservice.HookRef{
 		Name:        ref.Name,
 		Namespace:   ref.Namespace,
 		Application: req.Application,
 		Hook:        req.Hook,
-	}, nil
+	}
+	r.recordHookStart(hookRef)
+	return &hookRef, nil
 }
 
 // injectEnvVars updates the provided hook run request with additional
@@ -358,7 +364,12 @@ func (r *applications) WaitAppHook(ctx context.Context, ref appservice.HookRef)
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	return appservice.WaitAppHook(ctx, client, ref)
+	err = appservice.WaitAppHook(ctx, client, ref)
+	// the hook is still running if the wait has been interrupted
+	if ctx.Err() == nil && !trace.IsConnectionProblem(err) {
+		r.recordHookCompletion(ctx, client, ref, err)
+	}
+	return trace.Wrap(err)
 }
 
 // StreamAppHookLogs streams app hook logs to output writer, this is a blocking call
@@ -0,0 +1,165 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	appservice "github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/app/hooks"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetAppHookRuns returns the recorded application hook runs matching the filter
+func (r *applications) GetAppHookRuns(filter storage.HookRunFilter) ([]storage.HookRun, error) {
+	runs, err := r.Backend.GetHookRuns(filter)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return runs, nil
+}
+
+// recordHookStart adds the started hook to the hook run history
+func (r *applications) recordHookStart(ref appservice.HookRef) {
+	err := r.Backend.UpsertHookRun(storage.HookRun{
+		ID:          ref.Name,
+		Namespace:   ref.Namespace,
+		Application: ref.Application,
+		Hook:        string(ref.Hook),
+		State:       storage.HookRunRunning,
+		Started:     r.Backend.Now().UTC(),
+	})
+	if err != nil {
+		r.WithError(err).Warnf("Failed to record start of %v.", ref.Name)
+	}
+}
+
+// recordHookCompletion updates the hook run history with the outcome
+// of the finished hook and its logs. hookErr is the hook failure
+func (r *applications) recordHookCompletion(ctx context.Context, client *kubernetes.Clientset, ref appservice.HookRef, hookErr error) {
+	run, err := r.Backend.GetHookRun(ref.Name)
+	if err != nil && !trace.IsNotFound(err) {
+		r.WithError(err).Warnf("Failed to query hook run %v.", ref.Name)
+		return
+	}
+	if run == nil {
+		if ref.Hook == "" {
+			r.Debugf("Hook run %v has not been recorded.", ref.Name)
+			return
+		}
+		run = &storage.HookRun{
+			ID:          ref.Name,
+			Namespace:   ref.Namespace,
+			Application: ref.Application,
+			Hook:        string(ref.Hook),
+			Started:     r.Backend.Now().UTC(),
+		}
+	}
+	if run.IsFinished() {
+		return
+	}
+	run.Finished = r.Backend.Now().UTC()
+	run.State = storage.HookRunCompleted
+	if hookErr != nil {
+		run.State = storage.HookRunFailed
+		run.Error = trace.UserMessage(hookErr)
+	}
+	runner, err := hooks.NewRunner(client)
+	if err != nil {
+		r.WithError(err).Warn("Failed to create hook runner.")
+		return
+	}
+	jobRef := hooks.JobRef{Name: ref.Name, Namespace: ref.Namespace}
+	run.ExitCode, err = runner.ExitCode(jobRef)
+	if err != nil {
+		r.WithError(err).Debugf("Failed to determine exit code of %v.", ref.Name)
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaults.HookRunLogsTimeout)
+	defer cancel()
+	logs := &tailBuffer{max: defaults.HookRunLogsMaxSize}
+	if err := runner.CollectLogs(ctx, jobRef, logs); err != nil {
+		r.WithError(err).Warnf("Failed to collect logs of %v.", ref.Name)
+	}
+	run.Logs = logs.String()
+	run.LogsTruncated = logs.truncated
+	if err := r.Backend.UpsertHookRun(*run); err != nil {
+		r.WithError(err).Warnf("Failed to record completion of %v.", ref.Name)
+		return
+	}
+	if err := r.pruneHookRuns(); err != nil {
+		r.WithError(err).Warn("Failed to prune hook run history.")
+	}
+}
+
+// pruneHookRuns removes all but the most recent hook runs of each
+// hook type from the hook run history, so frequently running hooks
+// like status do not evict the history of other hooks
+func (r *applications) pruneHookRuns() error {
+	runs, err := r.Backend.GetHookRuns(storage.HookRunFilter{SkipLogs: true})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// runs are sorted most recent first
+	counts := make(map[string]int)
+	for _, run := range runs {
+		counts[run.Hook]++
+		if counts[run.Hook] <= defaults.HookRunsRetain {
+			continue
+		}
+		err := r.Backend.DeleteHookRun(run.ID)
+		if err != nil && !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// tailBuffer is an io.Writer that keeps the last max bytes written to it
+type tailBuffer struct {
+	buf []byte
+	max int
+	// truncated is set if some of the written bytes have been discarded
+	truncated bool
+}
+
+// Write appends p to the buffer discarding the oldest bytes
+// once the buffer grows beyond its limit
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > 2*b.max {
+		b.compact()
+	}
+	return len(p), nil
+}
+
+// String returns the last max bytes written to the buffer
+func (b *tailBuffer) String() string {
+	b.compact()
+	return string(b.buf)
+}
+
+func (b *tailBuffer) compact() {
+	if len(b.buf) <= b.max {
+		return
+	}
+	b.buf = append([]byte(nil), b.buf[len(b.buf)-b.max:]...)
+	b.truncated = true
+}
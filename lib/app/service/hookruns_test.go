@@ -0,0 +1,103 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	appservice "github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+type HookRunsSuite struct{}
+
+var _ = Suite(&HookRunsSuite{})
+
+func (s *HookRunsSuite) TestTailBuffer(c *C) {
+	buf := &tailBuffer{max: 8}
+	fmt.Fprint(buf, "12345")
+	c.Assert(buf.String(), Equals, "12345")
+	c.Assert(buf.truncated, Equals, false)
+
+	fmt.Fprint(buf, strings.Repeat("x", 20), "abcdefgh")
+	c.Assert(buf.String(), Equals, "abcdefgh")
+	c.Assert(buf.truncated, Equals, true)
+}
+
+func (s *HookRunsSuite) TestRecordsHookRuns(c *C) {
+	backend, _, apps := setupServices(c)
+	application := loc.MustParseLocator("gravitational.io/app:0.0.1")
+
+	apps.recordHookStart(appservice.HookRef{
+		Name:        "app-install-1",
+		Namespace:   "kube-system",
+		Application: application,
+		Hook:        schema.HookInstall,
+	})
+	runs, err := apps.GetAppHookRuns(storage.HookRunFilter{Application: &application})
+	c.Assert(err, IsNil)
+	c.Assert(runs, HasLen, 1)
+	c.Assert(runs[0].Hook, Equals, string(schema.HookInstall))
+	c.Assert(runs[0].State, Equals, storage.HookRunRunning)
+	c.Assert(runs[0].IsFinished(), Equals, false)
+
+	started := runs[0].Started
+	for i := 0; i < defaults.HookRunsRetain; i++ {
+		err := backend.UpsertHookRun(storage.HookRun{
+			ID:          fmt.Sprintf("app-status-%v", i),
+			Application: application,
+			Hook:        string(schema.HookStatus),
+			State:       storage.HookRunCompleted,
+			Started:     started.Add(time.Duration(i+1) * time.Second),
+		})
+		c.Assert(err, IsNil)
+	}
+	c.Assert(apps.pruneHookRuns(), IsNil)
+
+	runs, err = apps.GetAppHookRuns(storage.HookRunFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(runs, HasLen, defaults.HookRunsRetain+1)
+	_, err = backend.GetHookRun("app-install-1")
+	c.Assert(err, IsNil, Commentf("status hook runs should not evict other hook runs"))
+
+	err = backend.UpsertHookRun(storage.HookRun{
+		ID:          fmt.Sprintf("app-status-%v", defaults.HookRunsRetain),
+		Application: application,
+		Hook:        string(schema.HookStatus),
+		State:       storage.HookRunCompleted,
+		Started:     started.Add(time.Duration(defaults.HookRunsRetain+1) * time.Second),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(apps.pruneHookRuns(), IsNil)
+
+	runs, err = apps.GetAppHookRuns(storage.HookRunFilter{Hook: string(schema.HookStatus)})
+	c.Assert(err, IsNil)
+	c.Assert(runs, HasLen, defaults.HookRunsRetain)
+	c.Assert(runs[0].ID, Equals, fmt.Sprintf("app-status-%v", defaults.HookRunsRetain))
+	_, err = backend.GetHookRun("app-status-0")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	_, err = backend.GetHookRun("app-install-1")
+	c.Assert(err, IsNil)
+}
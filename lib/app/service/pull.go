@@ -63,6 +63,19 @@ func (r *PackagePullRequest) CheckAndSetDefaults() error {
 	return nil
 }
 
+// labels returns labels for the pulled package: runtime labels of the
+// source package are copied unless overridden by the request
+func (r *PackagePullRequest) labels(env pack.PackageEnvelope) map[string]string {
+	labels := make(map[string]string)
+	for label, value := range env.RuntimeLabels {
+		labels[label] = value
+	}
+	for label, value := range r.Labels {
+		labels[label] = value
+	}
+	return labels
+}
+
 // AppPullRequest describes a request to pull an app with all its dependencies from one app service
 // into another
 type AppPullRequest struct {
@@ -180,6 +193,17 @@ func pullPackage(req PackagePullRequest) (*pack.PackageEnvelope, error) {
 
 	req.Infof("Pulling package %v.", req.Package)
 
+	if !req.MetadataOnly {
+		env, err = pullPackageChunks(req)
+		if err == nil {
+			return env, nil
+		}
+		if !trace.IsNotImplemented(err) && !trace.IsNotFound(err) {
+			return nil, trace.Wrap(err)
+		}
+		req.Debugf("Chunked transfer is not available for %v: %v.", req.Package, err)
+	}
+
 	reader := ioutil.NopCloser(utils.NopReader())
 	if req.MetadataOnly {
 		env, err = req.SrcPack.ReadPackageEnvelope(req.Package)
@@ -189,13 +213,6 @@ func pullPackage(req PackagePullRequest) (*pack.PackageEnvelope, error) {
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-
-	if req.Progress != nil {
-		reader = utils.TeeReadCloser(reader, &pack.ProgressWriter{
-			Size: env.SizeBytes,
-			R:    req.Progress,
-		})
-	}
 	defer reader.Close()
 
 	err = req.DstPack.UpsertRepository(env.Locator.Repository, time.Time{})
@@ -203,22 +220,31 @@ func pullPackage(req PackagePullRequest) (*pack.PackageEnvelope, error) {
 		return nil, trace.Wrap(err)
 	}
 
-	// Copy runtime labels
-	if req.Labels == nil {
-		req.Labels = make(map[string]string)
-	}
-	for label, value := range env.RuntimeLabels {
-		if _, exists := req.Labels[label]; !exists {
-			req.Labels[label] = value
+	if !req.MetadataOnly {
+		uploaded, err := uploadPackage(req, *env, reader)
+		if err == nil {
+			return uploaded, nil
+		}
+		if !trace.IsNotImplemented(err) {
+			return nil, trace.Wrap(err)
 		}
+		req.Debugf("Resumable upload is not available for %v: %v.", req.Package, err)
+	}
+
+	if req.Progress != nil {
+		reader = utils.TeeReadCloser(reader, &pack.ProgressWriter{
+			Size: env.SizeBytes,
+			R:    req.Progress,
+		})
 	}
 
+	labels := req.labels(*env)
 	if req.Upsert {
 		env, err = req.DstPack.UpsertPackage(
-			env.Locator, reader, pack.WithLabels(req.Labels))
+			env.Locator, reader, pack.WithLabels(labels))
 	} else {
 		env, err = req.DstPack.CreatePackage(
-			env.Locator, reader, pack.WithLabels(req.Labels))
+			env.Locator, reader, pack.WithLabels(labels))
 	}
 	if err != nil {
 		return nil, trace.Wrap(err)
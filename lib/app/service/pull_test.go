@@ -18,11 +18,15 @@ package service
 
 import (
 	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
 	"path/filepath"
 	"time"
 
 	"github.com/gravitational/gravity/lib/app"
 	apptest "github.com/gravitational/gravity/lib/app/service/test"
+	"github.com/gravitational/gravity/lib/blob/dedup"
 	"github.com/gravitational/gravity/lib/blob/fs"
 	"github.com/gravitational/gravity/lib/compare"
 	"github.com/gravitational/gravity/lib/defaults"
@@ -85,6 +89,71 @@ func (s *PullerSuite) TestPullPackage(c *C) {
 	c.Assert(trace.IsAlreadyExists(err), Equals, true)
 }
 
+func (s *PullerSuite) TestPullPackageChunks(c *C) {
+	srcPack := setupChunkedPackages(c)
+	dstPack := setupChunkedPackages(c)
+	logger := log.WithField("test", "PullPackageChunks")
+
+	previous := randomBytes(256 * 1024)
+	latest := append(randomBytes(4096), previous...)
+	previousLoc := loc.MustParseLocator("example.com/package:0.0.1")
+	latestLoc := loc.MustParseLocator("example.com/package:0.0.2")
+	_, err := srcPack.CreatePackage(previousLoc, bytes.NewReader(previous))
+	c.Assert(err, IsNil)
+	_, err = srcPack.CreatePackage(latestLoc, bytes.NewReader(latest))
+	c.Assert(err, IsNil)
+	_, err = dstPack.CreatePackage(previousLoc, bytes.NewReader(previous))
+	c.Assert(err, IsNil)
+
+	src := &flakyChunkedPackages{PackageServer: srcPack, failures: 1}
+	env, err := PullPackage(PackagePullRequest{
+		FieldLogger: logger,
+		SrcPack:     src,
+		DstPack:     dstPack,
+		Package:     latestLoc,
+		Labels:      map[string]string{"purpose": "test"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(env.Locator, Equals, latestLoc)
+	c.Assert(env.RuntimeLabels, DeepEquals, map[string]string{"purpose": "test"})
+
+	_, reader, err := dstPack.ReadPackage(latestLoc)
+	c.Assert(err, IsNil)
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(data, latest), Equals, true)
+
+	// only the chunks missing on the destination should have been transferred
+	c.Assert(src.transferred < int64(len(latest))/2, Equals, true,
+		Commentf("transferred %v bytes of %v", src.transferred, len(latest)))
+}
+
+func (s *PullerSuite) TestResumesInterruptedUpload(c *C) {
+	data := randomBytes(2*defaults.UploadChunkSize + 100)
+	loc := loc.MustParseLocator("example.com/package:0.0.1")
+	_, err := s.srcPack.CreatePackage(loc, bytes.NewReader(data))
+	c.Assert(err, IsNil)
+
+	dst := &flakyUploads{PackageServer: s.dstPack.(*localpack.PackageServer), failures: 1}
+	env, err := PullPackage(PackagePullRequest{
+		FieldLogger: log.WithField("test", "ResumesInterruptedUpload"),
+		SrcPack:     s.srcPack,
+		DstPack:     dst,
+		Package:     loc,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(env.Locator, Equals, loc)
+	c.Assert(dst.appends, Equals, 3)
+
+	_, reader, err := s.dstPack.ReadPackage(loc)
+	c.Assert(err, IsNil)
+	defer reader.Close()
+	received, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(received, data), Equals, true)
+}
+
 func (s *PullerSuite) TestPullApp(c *C) {
 	s.pullApp(c, 0)
 }
@@ -192,6 +261,84 @@ func setupServices(c *C) (storage.Backend, pack.PackageService, *applications) {
 	return backend, packService, appService
 }
 
+func setupChunkedPackages(c *C) *localpack.PackageServer {
+	dir := c.MkDir()
+	backend, err := keyval.NewBolt(keyval.BoltConfig{
+		Path: filepath.Join(dir, "bolt.db"),
+	})
+	c.Assert(err, IsNil)
+	objects, err := dedup.New(dedup.Config{Path: dir, AvgChunkSize: 4096})
+	c.Assert(err, IsNil)
+	packages, err := localpack.New(localpack.Config{
+		Backend:     backend,
+		UnpackedDir: filepath.Join(dir, defaults.UnpackedDir),
+		Objects:     objects,
+	})
+	c.Assert(err, IsNil)
+	err = packages.UpsertRepository("example.com", time.Time{})
+	c.Assert(err, IsNil)
+	return packages
+}
+
+// flakyChunkedPackages interrupts the specified number of chunk
+// transfers midway and counts the transferred bytes
+type flakyChunkedPackages struct {
+	*localpack.PackageServer
+	failures    int
+	transferred int64
+}
+
+func (p *flakyChunkedPackages) ReadPackageChunks(loc loc.Locator, hashes []string) (io.ReadCloser, error) {
+	reader, err := p.PackageServer.ReadPackageChunks(loc, hashes)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	counter := &countingReader{ReadCloser: reader, count: &p.transferred}
+	if p.failures == 0 {
+		return counter, nil
+	}
+	p.failures--
+	return ioutil.NopCloser(io.LimitReader(counter, 6000)), nil
+}
+
+type countingReader struct {
+	io.ReadCloser
+	count *int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	*r.count += int64(n)
+	return n, err
+}
+
+// flakyUploads loses the response to the specified number of
+// upload chunks after the chunk has been received
+type flakyUploads struct {
+	*localpack.PackageServer
+	failures int
+	appends  int
+}
+
+func (p *flakyUploads) AppendUpload(id string, chunk pack.UploadChunk, data io.Reader) (*pack.UploadSession, error) {
+	session, err := p.PackageServer.AppendUpload(id, chunk, data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	p.appends++
+	if p.failures == 0 {
+		return session, nil
+	}
+	p.failures--
+	return nil, trace.ConnectionProblem(nil, "connection reset")
+}
+
+func randomBytes(size int) []byte {
+	data := make([]byte, size)
+	rand.Read(data)
+	return data
+}
+
 func locators(envelopes []pack.PackageEnvelope) []loc.Locator {
 	out := make([]loc.Locator, 0, len(envelopes))
 	for _, env := range envelopes {
@@ -0,0 +1,147 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"io"
+	"time"
+
+	"github.com/gravitational/gravity/lib/blob"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/pack"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gravitational/trace"
+)
+
+// pullPackageChunks pulls the package by transferring only the chunks
+// the destination package service does not have yet.
+// Chunks are persisted as soon as they are received, so an interrupted
+// transfer is resumed from the first missing chunk.
+// Returns trace.NotImplemented if either side does not support chunked transfer
+func pullPackageChunks(req PackagePullRequest) (*pack.PackageEnvelope, error) {
+	src, ok := req.SrcPack.(pack.ChunkedPackageService)
+	if !ok {
+		return nil, trace.NotImplemented("source does not support chunked transfer")
+	}
+	dst, ok := req.DstPack.(pack.ChunkedPackageWriter)
+	if !ok {
+		return nil, trace.NotImplemented("destination does not support chunked transfer")
+	}
+	chunks, err := src.GetPackageChunks(req.Package)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	missing, err := dst.GetMissingChunks(chunks.Chunks)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var missingBytes, transferred int64
+	for _, chunk := range missing {
+		missingBytes += chunk.SizeBytes
+	}
+	req.Infof("Pulling package %v: %v of %v chunks are missing (%v of %v).",
+		req.Package, len(missing), len(chunks.Chunks),
+		humanize.Bytes(uint64(missingBytes)), humanize.Bytes(uint64(chunks.Envelope.SizeBytes)))
+	for attempt := 1; len(missing) != 0; attempt++ {
+		err = fetchChunks(src, dst, chunks.Envelope, missing, func(chunk blob.Chunk) {
+			transferred += chunk.SizeBytes
+			if req.Progress != nil {
+				req.Progress.Report(transferred, missingBytes)
+			}
+		})
+		if err == nil {
+			break
+		}
+		if !isResumableTransferError(err) || attempt >= defaults.DedupTransferAttempts {
+			return nil, trace.Wrap(err)
+		}
+		req.WithError(err).Warnf("Transfer of %v interrupted, will resume (attempt %v).",
+			req.Package, attempt)
+		time.Sleep(defaults.RetryInterval)
+		missing, err = dst.GetMissingChunks(missing)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+
+	err = req.DstPack.UpsertRepository(chunks.Envelope.Locator.Repository, time.Time{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	options := []pack.PackageOption{pack.WithLabels(req.labels(chunks.Envelope))}
+	var env *pack.PackageEnvelope
+	if req.Upsert {
+		env, err = dst.UpsertPackageFromChunks(chunks.Envelope.Locator, chunks.Chunks, options...)
+	} else {
+		env, err = dst.CreatePackageFromChunks(chunks.Envelope.Locator, chunks.Chunks, options...)
+	}
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if env.SHA512 != chunks.Envelope.SHA512 {
+		return nil, trace.BadParameter("checksum mismatch for %v: expected %v, got %v",
+			req.Package, chunks.Envelope.SHA512, env.SHA512)
+	}
+	return env, nil
+}
+
+// fetchChunks streams the missing chunks from src into dst in batches
+func fetchChunks(src pack.ChunkedPackageService, dst pack.ChunkedPackageWriter, env pack.PackageEnvelope, missing []blob.Chunk, onChunk func(blob.Chunk)) error {
+	for len(missing) != 0 {
+		batch := missing
+		if len(batch) > defaults.DedupTransferBatchSize {
+			batch = batch[:defaults.DedupTransferBatchSize]
+		}
+		missing = missing[len(batch):]
+		hashes := make([]string, 0, len(batch))
+		for _, chunk := range batch {
+			hashes = append(hashes, chunk.Hash)
+		}
+		err := fetchChunkBatch(src, dst, env, batch, hashes, onChunk)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+func fetchChunkBatch(src pack.ChunkedPackageService, dst pack.ChunkedPackageWriter, env pack.PackageEnvelope, batch []blob.Chunk, hashes []string, onChunk func(blob.Chunk)) error {
+	reader, err := src.ReadPackageChunks(env.Locator, hashes)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+	for _, chunk := range batch {
+		err := dst.WriteChunk(chunk, io.LimitReader(reader, chunk.SizeBytes))
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		onChunk(chunk)
+	}
+	return nil
+}
+
+// isResumableTransferError returns true if the chunked transfer
+// should be resumed after the specified error
+func isResumableTransferError(err error) bool {
+	switch {
+	case trace.IsNotFound(err), trace.IsAccessDenied(err), trace.IsNotImplemented(err):
+		return false
+	}
+	return true
+}
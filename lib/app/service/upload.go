@@ -0,0 +1,125 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/pack"
+
+	"github.com/gravitational/trace"
+)
+
+// uploadPackage sends the package data to the destination package service
+// in a resumable upload: data is sent in checksummed chunks and an interrupted
+// upload is continued from the last chunk the destination has received.
+// Returns trace.NotImplemented if the destination does not support
+// resumable uploads or the package is too small to benefit from one
+func uploadPackage(req PackagePullRequest, env pack.PackageEnvelope, data io.ReadCloser) (*pack.PackageEnvelope, error) {
+	uploads, ok := req.DstPack.(pack.UploadService)
+	if !ok {
+		return nil, trace.NotImplemented("destination does not support resumable uploads")
+	}
+	seeker, ok := data.(io.ReadSeeker)
+	if !ok {
+		return nil, trace.NotImplemented("package data is not seekable")
+	}
+	if env.SizeBytes < defaults.UploadChunkSize {
+		return nil, trace.NotImplemented("package is too small for resumable upload")
+	}
+	session, err := uploads.StartUpload(pack.NewUploadRequest(env.Locator, env.SizeBytes,
+		req.Upsert, pack.WithLabels(req.labels(env)), pack.WithEncrypted(env.Encrypted),
+		pack.WithCreatedBy(env.CreatedBy)))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			// the destination is running an older version without upload API
+			return nil, trace.NotImplemented("destination does not support resumable uploads: %v", err)
+		}
+		return nil, trace.Wrap(err)
+	}
+	req.Infof("Uploading package %v (upload %v).", env.Locator, session.ID)
+	buf := make([]byte, defaults.UploadChunkSize)
+	for attempt := 1; !session.Complete(); {
+		updated, err := uploadChunk(uploads, *session, seeker, buf)
+		if err == nil {
+			session = updated
+			if req.Progress != nil {
+				req.Progress.Report(session.Offset, session.Request.SizeBytes)
+			}
+			continue
+		}
+		if !isResumableUploadError(err) || attempt >= defaults.UploadAttempts {
+			if errAbort := uploads.AbortUpload(session.ID); errAbort != nil {
+				req.WithError(errAbort).Warnf("Failed to abort upload %v.", session.ID)
+			}
+			return nil, trace.Wrap(err)
+		}
+		req.WithError(err).Warnf("Upload of %v interrupted at offset %v, will resume (attempt %v).",
+			env.Locator, session.Offset, attempt)
+		attempt++
+		time.Sleep(defaults.RetryInterval)
+		// the chunk might have been received before the connection dropped
+		updated, err = uploads.GetUpload(session.ID)
+		if err != nil {
+			req.WithError(err).Warnf("Failed to query upload %v.", session.ID)
+			continue
+		}
+		session = updated
+	}
+	envelope, err := uploads.CommitUpload(session.ID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if envelope.SHA512 != env.SHA512 {
+		return nil, trace.BadParameter("checksum mismatch for %v: expected %v, got %v",
+			env.Locator, env.SHA512, envelope.SHA512)
+	}
+	return envelope, nil
+}
+
+// uploadChunk reads the next chunk of data at the upload offset and sends it
+func uploadChunk(uploads pack.UploadService, session pack.UploadSession, data io.ReadSeeker, buf []byte) (*pack.UploadSession, error) {
+	if remaining := session.Request.SizeBytes - session.Offset; remaining < int64(len(buf)) {
+		buf = buf[:remaining]
+	}
+	if _, err := data.Seek(session.Offset, io.SeekStart); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if _, err := io.ReadFull(data, buf); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	checksum := sha256.Sum256(buf)
+	return uploads.AppendUpload(session.ID, pack.UploadChunk{
+		Offset: session.Offset,
+		SHA256: hex.EncodeToString(checksum[:]),
+	}, bytes.NewReader(buf))
+}
+
+// isResumableUploadError returns true if the upload
+// should be resumed after the specified error
+func isResumableUploadError(err error) bool {
+	switch {
+	case trace.IsBadParameter(err), trace.IsAlreadyExists(err):
+		return false
+	}
+	return isResumableTransferError(err)
+}
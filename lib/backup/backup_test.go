@@ -0,0 +1,276 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/testutils"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+func TestBackup(t *testing.T) { check.TestingT(t) }
+
+type BackupSuite struct{}
+
+var _ = check.Suite(&BackupSuite{})
+
+func (s *BackupSuite) TestEncryption(c *check.C) {
+	for _, size := range []int{0, 1, defaults.BackupEncryptionChunkSize,
+		3*defaults.BackupEncryptionChunkSize + 17} {
+		data := make([]byte, size)
+		_, err := rand.Read(data)
+		c.Assert(err, check.IsNil)
+
+		var encrypted bytes.Buffer
+		w, err := NewEncryptWriter(&encrypted, "secret")
+		c.Assert(err, check.IsNil)
+		_, err = w.Write(data)
+		c.Assert(err, check.IsNil)
+		c.Assert(w.Close(), check.IsNil)
+		c.Assert(IsEncrypted(encrypted.Bytes()), check.Equals, true)
+
+		r, err := NewDecryptReader(bytes.NewReader(encrypted.Bytes()), "secret")
+		c.Assert(err, check.IsNil)
+		decrypted, err := ioutil.ReadAll(r)
+		c.Assert(err, check.IsNil)
+		c.Assert(bytes.Equal(decrypted, data), check.Equals, true, check.Commentf("size %v", size))
+
+		r, err = NewDecryptReader(bytes.NewReader(encrypted.Bytes()), "invalid")
+		c.Assert(err, check.IsNil)
+		_, err = ioutil.ReadAll(r)
+		c.Assert(trace.IsBadParameter(err), check.Equals, true)
+
+		// truncate the archive at the chunk boundary
+		truncated := encrypted.Bytes()[:encrypted.Len()-16]
+		if size > defaults.BackupEncryptionChunkSize {
+			truncated = encrypted.Bytes()[:len(encryptionMagic)+saltSize+defaults.BackupEncryptionChunkSize+16]
+		}
+		r, err = NewDecryptReader(bytes.NewReader(truncated), "secret")
+		c.Assert(err, check.IsNil)
+		_, err = ioutil.ReadAll(r)
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf("size %v", size))
+	}
+}
+
+func (s *BackupSuite) TestLocalStore(c *check.C) {
+	store, err := NewLocalStore(filepath.Join(c.MkDir(), "backups"))
+	c.Assert(err, check.IsNil)
+	testStore(c, store)
+}
+
+func (s *BackupSuite) TestS3Store(c *check.C) {
+	s3 := testutils.NewS3()
+	store, err := NewS3Store(S3Config{
+		S3Destination: storage.S3Destination{Bucket: "backups", Prefix: "cluster"},
+		S3:            s3,
+		Uploader:      s3,
+	})
+	c.Assert(err, check.IsNil)
+	testStore(c, store)
+}
+
+func testStore(c *check.C, store Store) {
+	ctx := context.TODO()
+	backups, err := store.List(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(backups, check.HasLen, 0)
+
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"daily-2", "daily-1"} {
+		backup, err := store.Put(ctx, storage.Backup{
+			Name:    name,
+			Policy:  "daily",
+			Created: now.Add(-time.Duration(i) * time.Hour),
+		}, bytes.NewReader([]byte(name)))
+		c.Assert(err, check.IsNil)
+		c.Assert(backup.Size, check.Equals, int64(len(name)))
+	}
+
+	backups, err = store.List(ctx)
+	c.Assert(err, check.IsNil)
+	compare.DeepCompare(c, backups, []storage.Backup{
+		{Name: "daily-1", Policy: "daily", Created: now.Add(-time.Hour), Size: 7},
+		{Name: "daily-2", Policy: "daily", Created: now, Size: 7},
+	})
+
+	rc, err := store.Open(ctx, "daily-1")
+	c.Assert(err, check.IsNil)
+	data, err := ioutil.ReadAll(rc)
+	c.Assert(err, check.IsNil)
+	c.Assert(rc.Close(), check.IsNil)
+	c.Assert(string(data), check.Equals, "daily-1")
+
+	c.Assert(store.Delete(ctx, "daily-1"), check.IsNil)
+	_, err = store.Get(ctx, "daily-1")
+	c.Assert(trace.IsNotFound(err), check.Equals, true)
+	_, err = store.Open(ctx, "daily-1")
+	c.Assert(trace.IsNotFound(err), check.Equals, true)
+
+	_, err = store.Get(ctx, "../daily-2")
+	c.Assert(trace.IsBadParameter(err), check.Equals, true)
+
+	backups, err = store.List(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(backups, check.HasLen, 1)
+}
+
+func (s *BackupSuite) TestSchedule(c *check.C) {
+	now := time.Date(2018, 1, 10, 0, 0, 0, 0, time.UTC)
+	var backups []storage.Backup
+	for i := 5; i > 0; i-- {
+		backups = append(backups, storage.Backup{
+			Name:    fmt.Sprintf("daily-%v", i),
+			Created: now.Add(-time.Duration(i) * 24 * time.Hour),
+		})
+	}
+	c.Assert(isDue(nil, time.Hour, now), check.Equals, true)
+	c.Assert(isDue(backups, 24*time.Hour, now), check.Equals, true)
+	c.Assert(isDue(backups, 48*time.Hour, now), check.Equals, false)
+
+	var testCases = []struct {
+		retention storage.BackupRetention
+		expired   []string
+		comment   string
+	}{
+		{
+			comment: "everything is retained by default",
+		},
+		{
+			retention: storage.BackupRetention{Count: 3},
+			expired:   []string{"daily-5", "daily-4"},
+			comment:   "count",
+		},
+		{
+			retention: storage.BackupRetention{MaxAge: teleservices.NewDuration(60 * time.Hour)},
+			expired:   []string{"daily-5", "daily-4", "daily-3"},
+			comment:   "max age",
+		},
+		{
+			retention: storage.BackupRetention{MaxAge: teleservices.NewDuration(time.Hour)},
+			expired:   []string{"daily-5", "daily-4", "daily-3", "daily-2"},
+			comment:   "latest backup is always retained",
+		},
+		{
+			retention: storage.BackupRetention{Count: 4, MaxAge: teleservices.NewDuration(84 * time.Hour)},
+			expired:   []string{"daily-5", "daily-4"},
+			comment:   "count and max age",
+		},
+	}
+	for _, tc := range testCases {
+		var expired []string
+		for _, backup := range expiredBackups(backups, tc.retention, now) {
+			expired = append(expired, backup.Name)
+		}
+		c.Assert(expired, check.DeepEquals, tc.expired, check.Commentf(tc.comment))
+	}
+}
+
+func (s *BackupSuite) TestWritesEncryptedArchive(c *check.C) {
+	dir := c.MkDir()
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, stateFile), []byte("state"), defaults.PrivateFileMask), check.IsNil)
+
+	var buf bytes.Buffer
+	c.Assert(writeArchive(&buf, dir, "secret"), check.IsNil)
+
+	r, err := NewDecryptReader(&buf, "secret")
+	c.Assert(err, check.IsNil)
+	gz, err := gzip.NewReader(r)
+	c.Assert(err, check.IsNil)
+	tr := tar.NewReader(gz)
+	header, err := tr.Next()
+	c.Assert(err, check.IsNil)
+	c.Assert(header.Name, check.Equals, stateFile)
+	data, err := ioutil.ReadAll(tr)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "state")
+	_, err = tr.Next()
+	c.Assert(err, check.Equals, io.EOF)
+}
+
+func (s *BackupSuite) TestManifest(c *check.C) {
+	dir := c.MkDir()
+	c.Assert(os.MkdirAll(filepath.Join(dir, "application"), defaults.SharedDirMask), check.IsNil)
+	writeFile(c, filepath.Join(dir, stateFile), "state")
+	writeFile(c, filepath.Join(dir, "application", "dump.sql"), "dump")
+
+	locator := loc.MustParseLocator("gravitational.io/app:0.0.1")
+	written, err := WriteManifest(dir, Manifest{
+		Application: locator,
+		RestoreHook: &schema.Hook{Job: "restore"},
+		HookDir:     "application",
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(written.Files, check.HasLen, 2)
+
+	manifest, err := Verify(dir)
+	c.Assert(err, check.IsNil)
+	compare.DeepCompare(c, manifest, written)
+
+	writeFile(c, filepath.Join(dir, stateFile), "corrupted")
+	writeFile(c, filepath.Join(dir, "extra"), "extra")
+	c.Assert(os.Remove(filepath.Join(dir, "application", "dump.sql")), check.IsNil)
+	_, err = Verify(dir)
+	c.Assert(err, check.NotNil)
+	c.Assert(err, check.ErrorMatches, "file application/dump.sql is missing, file state.gz has size 9, expected 5, file extra is not in the manifest")
+
+	c.Assert(os.Remove(filepath.Join(dir, ManifestFile)), check.IsNil)
+	_, err = Verify(dir)
+	c.Assert(trace.IsNotFound(err), check.Equals, true, check.Commentf("%v", err))
+}
+
+func (s *BackupSuite) TestUnpack(c *check.C) {
+	dir := c.MkDir()
+	writeFile(c, filepath.Join(dir, stateFile), "state")
+	_, err := WriteManifest(dir, Manifest{})
+	c.Assert(err, check.IsNil)
+
+	var buf bytes.Buffer
+	c.Assert(writeArchive(&buf, dir, "secret"), check.IsNil)
+
+	err = Unpack(bytes.NewReader(buf.Bytes()), c.MkDir(), "")
+	c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf("%v", err))
+
+	target := c.MkDir()
+	c.Assert(Unpack(bytes.NewReader(buf.Bytes()), target, "secret"), check.IsNil)
+	manifest, err := Verify(target)
+	c.Assert(err, check.IsNil)
+	c.Assert(manifest.Files, check.HasLen, 1)
+}
+
+func writeFile(c *check.C, path, data string) {
+	c.Assert(ioutil.WriteFile(path, []byte(data), defaults.SharedReadMask), check.IsNil)
+}
@@ -0,0 +1,492 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/app/hooks"
+	"github.com/gravitational/gravity/lib/archive"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/pkg/transport"
+	dockerarchive "github.com/docker/docker/pkg/archive"
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Config is the backup controller configuration
+type Config struct {
+	// Backend is the cluster storage backend
+	Backend storage.Backend
+	// Apps is the cluster application service used to run the backup hook
+	Apps app.Applications
+	// Etcd is the configuration of the etcd cluster to snapshot.
+	// The snapshot is skipped if no etcd nodes are configured
+	Etcd keyval.ETCDConfig
+	// StateDir is the directory backups are collected in.
+	// The directory must be mounted at the same path from the host
+	// since the backup hook writes into it via a host path volume
+	StateDir string
+	// NodeAddr is the advertise address of the node the controller
+	// runs on. The backup hook is scheduled on this node
+	NodeAddr string
+	// NewStore returns the store for the backup destination
+	NewStore func(storage.BackupDestination) (Store, error)
+	// Clock is used to schedule backups
+	Clock clockwork.Clock
+	// FieldLogger is used for logging
+	logrus.FieldLogger
+}
+
+// CheckAndSetDefaults validates config and sets defaults
+func (c *Config) CheckAndSetDefaults() error {
+	if c.Backend == nil {
+		return trace.BadParameter("missing parameter Backend")
+	}
+	if c.Apps == nil {
+		return trace.BadParameter("missing parameter Apps")
+	}
+	if c.StateDir == "" {
+		c.StateDir = defaults.BackupHookStateDir
+	}
+	if c.NewStore == nil {
+		c.NewStore = NewStore
+	}
+	if c.Clock == nil {
+		c.Clock = clockwork.NewRealClock()
+	}
+	if c.FieldLogger == nil {
+		c.FieldLogger = logrus.WithField(trace.Component, "backup")
+	}
+	return nil
+}
+
+// Controller takes cluster backups according to the backup policies
+// and removes the backups that are no longer retained
+type Controller struct {
+	Config
+}
+
+// NewController returns a new backup controller
+func NewController(config Config) (*Controller, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Controller{Config: config}, nil
+}
+
+// Run periodically checks the backup policies for due backups
+// until the context expires
+func (c *Controller) Run(ctx context.Context) {
+	c.Info("Starting backup controller.")
+	ticker := c.Clock.NewTicker(defaults.BackupCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.Chan():
+			policies, err := c.Backend.GetBackupPolicies()
+			if err != nil {
+				c.WithError(err).Warn("Failed to get backup policies.")
+				continue
+			}
+			for _, policy := range policies {
+				if err := c.checkPolicy(ctx, policy); err != nil {
+					c.WithError(err).Warnf("Failed to back up cluster with policy %v.",
+						policy.GetName())
+				}
+			}
+		case <-ctx.Done():
+			c.Info("Stopping backup controller.")
+			return
+		}
+	}
+}
+
+// checkPolicy takes a new backup if one is due according to the policy
+// and removes the policy backups that are no longer retained
+func (c *Controller) checkPolicy(ctx context.Context, policy storage.BackupPolicy) error {
+	store, err := c.NewStore(policy.GetDestination())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	backups, err := listPolicyBackups(ctx, store, policy.GetName())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !isDue(backups, policy.GetInterval(), c.Clock.Now()) {
+		return nil
+	}
+	c.Infof("Backing up cluster with policy %v to %v.", policy.GetName(), policy.GetDestination())
+	backupCtx, cancel := context.WithTimeout(ctx, policy.GetTimeout())
+	defer cancel()
+	backup, err := c.backup(backupCtx, policy, store)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	c.Infof("Stored backup %v of %v bytes.", backup.Name, backup.Size)
+	backups = append(backups, *backup)
+	for _, expired := range expiredBackups(backups, policy.GetRetention(), c.Clock.Now()) {
+		c.Infof("Removing expired backup %v.", expired.Name)
+		if err := store.Delete(ctx, expired.Name); err != nil && !trace.IsNotFound(err) {
+			c.WithError(err).Warnf("Failed to remove expired backup %v.", expired.Name)
+		}
+	}
+	return nil
+}
+
+// backup collects the cluster state into the backup archive
+// and writes it to the store
+func (c *Controller) backup(ctx context.Context, policy storage.BackupPolicy, store Store) (*storage.Backup, error) {
+	cluster, err := c.Backend.GetLocalSite(defaults.SystemAccountID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	now := c.Clock.Now().UTC()
+	backup := storage.Backup{
+		Name:        fmt.Sprintf("%v-%v", policy.GetName(), now.Format(backupTimeFormat)),
+		Policy:      policy.GetName(),
+		ClusterName: cluster.Domain,
+		Application: cluster.App.Locator().String(),
+		Created:     now,
+		Encrypted:   policy.GetEncryptionKey() != "",
+	}
+	dir := filepath.Join(c.StateDir, backup.Name)
+	if err := os.MkdirAll(dir, defaults.SharedDirMask); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			c.WithError(err).Warnf("Failed to remove backup directory %v.", dir)
+		}
+	}()
+	manifestHooks, err := c.getHooks(*cluster)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	manifest := Manifest{
+		Created:     now,
+		Application: cluster.App.Locator(),
+	}
+	if manifestHooks != nil && manifestHooks.Backup != nil {
+		manifest.BackupHook = manifestHooks.Backup
+		manifest.RestoreHook = manifestHooks.Restore
+		manifest.VerifyRestoreHook = manifestHooks.VerifyRestore
+		manifest.HookDir = storage.BackupComponentApplication
+		err := c.runHook(ctx, *cluster, filepath.Join(dir, storage.BackupComponentApplication),
+			policy.GetTimeout())
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		backup.Components = append(backup.Components, storage.BackupComponentApplication)
+	}
+	if err := c.exportState(filepath.Join(dir, stateFile)); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	backup.Components = append(backup.Components, storage.BackupComponentState)
+	if len(c.Etcd.Nodes) != 0 {
+		if err := c.snapshotEtcd(ctx, filepath.Join(dir, etcdSnapshotFile)); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		backup.Components = append(backup.Components, storage.BackupComponentEtcd)
+	}
+	if _, err := WriteManifest(dir, manifest); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeArchive(writer, dir, policy.GetEncryptionKey()))
+	}()
+	stored, err := store.Put(ctx, backup, reader)
+	reader.Close()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return stored, nil
+}
+
+// getHooks returns the hooks defined by the cluster application
+func (c *Controller) getHooks(cluster storage.Site) (*schema.Hooks, error) {
+	application, err := c.Apps.GetApp(cluster.App.Locator())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return application.Manifest.Hooks, nil
+}
+
+// runHook runs the application backup hook on this node
+// and waits for it to write the backup into dir
+func (c *Controller) runHook(ctx context.Context, cluster storage.Site, dir string, timeout time.Duration) error {
+	if c.NodeAddr == "" {
+		return trace.BadParameter("backup hook requires the address of the controller node")
+	}
+	server, err := cluster.ClusterState.FindServerByIP(c.NodeAddr)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := os.MkdirAll(dir, defaults.SharedDirMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	req := app.HookRunRequest{
+		Application: cluster.App.Locator(),
+		Hook:        schema.HookBackup,
+		Volumes: []v1.Volume{{
+			Name: hooks.VolumeBackup,
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{
+					Path: dir,
+				},
+			},
+		}},
+		VolumeMounts: []v1.VolumeMount{{
+			Name:      hooks.VolumeBackup,
+			MountPath: hooks.ContainerBackupDir,
+		}},
+		NodeSelector: map[string]string{
+			v1.LabelHostname: server.KubeNodeID(),
+		},
+		Timeout: timeout,
+	}
+	ref, err := app.StreamAppHook(ctx, c.Apps, req, utils.NopWriteCloser(ioutil.Discard))
+	if ref != nil {
+		defer func() {
+			err := c.Apps.DeleteAppHookJob(context.TODO(), app.DeleteAppHookJobRequest{
+				HookRef: *ref,
+			})
+			if err != nil {
+				c.WithError(err).Warnf("Failed to delete hook %v.", ref)
+			}
+		}()
+	}
+	return trace.Wrap(err)
+}
+
+// exportState writes the gravity state archive to the file at path
+func (c *Controller) exportState(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, defaults.PrivateFileMask)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	if _, err := keyval.ExportState(c.Backend, f, keyval.ExportConfig{
+		// backups are stored outside the cluster, do not leak
+		// object storage credentials and encryption keys
+		ExcludeSecrets: true,
+	}); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.ConvertSystemError(f.Close())
+}
+
+// snapshotEtcd writes the snapshot of the etcd keyspace to the file at path
+func (c *Controller) snapshotEtcd(ctx context.Context, path string) error {
+	tlsInfo := transport.TLSInfo{
+		CAFile:   c.Etcd.TLSCAFile,
+		CertFile: c.Etcd.TLSCertFile,
+		KeyFile:  c.Etcd.TLSKeyFile,
+	}
+	tlsConfig, err := tlsInfo.ClientConfig()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   c.Etcd.Nodes,
+		DialTimeout: defaults.DialTimeout,
+		TLS:         tlsConfig,
+		Context:     ctx,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer client.Close()
+	snapshot, err := client.Snapshot(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer snapshot.Close()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, defaults.PrivateFileMask)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, snapshot); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.ConvertSystemError(f.Close())
+}
+
+// writeArchive writes the compressed and, if the key is set,
+// encrypted archive of the directory to w
+func writeArchive(w io.Writer, dir, encryptionKey string) error {
+	var encrypter io.WriteCloser
+	if encryptionKey != "" {
+		var err error
+		encrypter, err = NewEncryptWriter(w, encryptionKey)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		w = encrypter
+	}
+	gz := gzip.NewWriter(w)
+	if err := archive.CompressDirectory(dir, gz); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := gz.Close(); err != nil {
+		return trace.Wrap(err)
+	}
+	if encrypter != nil {
+		return trace.Wrap(encrypter.Close())
+	}
+	return nil
+}
+
+// Unpack extracts the backup archive read from r into dir.
+// The archive is decrypted with encryptionKey if it is encrypted
+func Unpack(r io.Reader, dir, encryptionKey string) error {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(len(encryptionMagic))
+	if err != nil && err != io.EOF {
+		return trace.Wrap(err)
+	}
+	r = buffered
+	if IsEncrypted(header) {
+		if encryptionKey == "" {
+			return trace.BadParameter("backup is encrypted, provide the encryption key")
+		}
+		r, err = NewDecryptReader(r, encryptionKey)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	err = dockerarchive.Untar(r, dir, archive.DefaultOptions())
+	return trace.Wrap(err)
+}
+
+// List returns the backups taken by all backup policies
+// sorted by creation time
+func List(ctx context.Context, policies storage.BackupPolicies) ([]storage.Backup, error) {
+	items, err := policies.GetBackupPolicies()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var backups []storage.Backup
+	for _, policy := range items {
+		store, err := NewStore(policy.GetDestination())
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		policyBackups, err := listPolicyBackups(ctx, store, policy.GetName())
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		backups = append(backups, policyBackups...)
+	}
+	sortBackups(backups)
+	return backups, nil
+}
+
+// Find returns the backup with the specified name along with
+// the store it is kept in
+func Find(ctx context.Context, policies storage.BackupPolicies, name string) (*storage.Backup, Store, error) {
+	items, err := policies.GetBackupPolicies()
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	for _, policy := range items {
+		store, err := NewStore(policy.GetDestination())
+		if err != nil {
+			return nil, nil, trace.Wrap(err)
+		}
+		backup, err := store.Get(ctx, name)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, nil, trace.Wrap(err)
+		}
+		if backup.Policy == policy.GetName() {
+			return backup, store, nil
+		}
+	}
+	return nil, nil, trace.NotFound("backup %q not found", name)
+}
+
+// listPolicyBackups returns the backups in the store taken by the policy
+func listPolicyBackups(ctx context.Context, store Store, policy string) ([]storage.Backup, error) {
+	backups, err := store.List(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var result []storage.Backup
+	for _, backup := range backups {
+		if backup.Policy == policy {
+			result = append(result, backup)
+		}
+	}
+	return result, nil
+}
+
+// isDue returns true if the interval has passed since the latest backup.
+// The backups are sorted by creation time
+func isDue(backups []storage.Backup, interval time.Duration, now time.Time) bool {
+	if len(backups) == 0 {
+		return true
+	}
+	return now.Sub(backups[len(backups)-1].Created) >= interval
+}
+
+// expiredBackups returns the backups that are no longer retained.
+// The backups are sorted by creation time, the latest backup is always retained
+func expiredBackups(backups []storage.Backup, retention storage.BackupRetention, now time.Time) (expired []storage.Backup) {
+	for i, backup := range backups {
+		if i == len(backups)-1 {
+			break
+		}
+		switch {
+		case retention.Count > 0 && i < len(backups)-retention.Count:
+			expired = append(expired, backup)
+		case retention.MaxAge.Duration > 0 && now.Sub(backup.Created) > retention.MaxAge.Duration:
+			expired = append(expired, backup)
+		}
+	}
+	return expired
+}
+
+const (
+	// backupTimeFormat is the format of the backup creation time in backup names
+	backupTimeFormat = "20060102150405"
+	// stateFile is the name of the gravity state archive in the backup
+	stateFile = "state.gz"
+	// etcdSnapshotFile is the name of the etcd snapshot in the backup
+	etcdSnapshotFile = "etcd.db"
+)
@@ -0,0 +1,227 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypted backup archives are sealed with AES-256-GCM in chunks
+// of defaults.BackupEncryptionChunkSize bytes so they can be streamed.
+//
+// The archive starts with the magic string and the random salt
+// the key is derived from the passphrase with. Each chunk is sealed
+// with the nonce made of the chunk counter and the flag marking
+// the final chunk, so reordered, truncated or extended archives
+// fail to decrypt
+const (
+	encryptionMagic = "GRAVBAK1"
+	saltSize        = 16
+	keySize         = 32
+	nonceSize       = 12
+)
+
+// NewEncryptWriter returns a writer that encrypts the data written to it
+// with the key derived from the passphrase and writes it to w.
+// The writer must be closed to write the final chunk, closing it
+// does not close w
+func NewEncryptWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if _, err := w.Write(append([]byte(encryptionMagic), salt...)); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &encryptWriter{
+		w:    w,
+		aead: aead,
+		buf:  make([]byte, 0, defaults.BackupEncryptionChunkSize),
+	}, nil
+}
+
+// NewDecryptReader returns a reader that decrypts the data read from r
+// with the key derived from the passphrase
+func NewDecryptReader(r io.Reader, passphrase string) (io.Reader, error) {
+	header := make([]byte, len(encryptionMagic)+saltSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, trace.BadParameter("backup archive is not encrypted")
+	}
+	if string(header[:len(encryptionMagic)]) != encryptionMagic {
+		return nil, trace.BadParameter("backup archive is not encrypted")
+	}
+	aead, err := newAEAD(passphrase, header[len(encryptionMagic):])
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &decryptReader{
+		r:     bufio.NewReader(r),
+		aead:  aead,
+		chunk: make([]byte, defaults.BackupEncryptionChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// IsEncrypted returns true if the data starts with the encrypted archive header
+func IsEncrypted(header []byte) bool {
+	return len(header) >= len(encryptionMagic) &&
+		string(header[:len(encryptionMagic)]) == encryptionMagic
+}
+
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+	closed  bool
+}
+
+// Write buffers p and seals the complete chunks.
+// A full chunk is only sealed once more data arrives since
+// the last chunk is sealed with the final flag on Close
+func (r *encryptWriter) Write(p []byte) (int, error) {
+	if r.closed {
+		return 0, trace.BadParameter("write to closed encrypt writer")
+	}
+	written := 0
+	for len(p) > 0 {
+		if len(r.buf) == cap(r.buf) {
+			if err := r.seal(false); err != nil {
+				return written, trace.Wrap(err)
+			}
+		}
+		n := copy(r.buf[len(r.buf):cap(r.buf)], p)
+		r.buf = r.buf[:len(r.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close seals the final chunk
+func (r *encryptWriter) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	return trace.Wrap(r.seal(true))
+}
+
+func (r *encryptWriter) seal(final bool) error {
+	sealed := r.aead.Seal(nil, chunkNonce(r.counter, final), r.buf, nil)
+	if _, err := r.w.Write(sealed); err != nil {
+		return trace.Wrap(err)
+	}
+	r.counter++
+	r.buf = r.buf[:0]
+	return nil
+}
+
+type decryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	chunk   []byte
+	plain   []byte
+	counter uint64
+	done    bool
+}
+
+// Read returns the decrypted data
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, trace.Wrap(err)
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// open reads and decrypts the next chunk
+func (r *decryptReader) open() error {
+	n, err := io.ReadFull(r.r, r.chunk)
+	final := false
+	switch err {
+	case nil:
+		_, err := r.r.Peek(1)
+		final = err == io.EOF
+	case io.EOF, io.ErrUnexpectedEOF:
+		final = true
+	default:
+		return trace.Wrap(err)
+	}
+	if n < r.aead.Overhead() {
+		return trace.BadParameter("backup archive is truncated")
+	}
+	plain, err := r.aead.Open(r.chunk[:0], chunkNonce(r.counter, final), r.chunk[:n], nil)
+	if err != nil {
+		return trace.BadParameter("failed to decrypt backup archive: " +
+			"invalid encryption key or corrupted archive")
+	}
+	r.plain = plain
+	r.counter++
+	r.done = final
+	return nil
+}
+
+// newAEAD returns the AES-GCM cipher keyed with the key derived
+// from the passphrase and salt
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, trace.BadParameter("missing encryption key")
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, keySize)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return aead, nil
+}
+
+// chunkNonce returns the nonce for the chunk with the specified counter
+func chunkNonce(counter uint64, final bool) []byte {
+	nonce := make([]byte, nonceSize)
+	binary.BigEndian.PutUint64(nonce, counter)
+	if final {
+		nonce[nonceSize-1] = 1
+	}
+	return nonce
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/schema"
+
+	"github.com/gravitational/trace"
+)
+
+// Manifest describes the contents of a backup.
+// It is stored in the root of the backup directory as ManifestFile
+type Manifest struct {
+	// Version is the manifest format version
+	Version string `json:"version"`
+	// Created is the time the backup was taken
+	Created time.Time `json:"created"`
+	// Application is the cluster application the backup was taken of
+	Application loc.Locator `json:"application"`
+	// BackupHook is the application hook that produced the backup
+	BackupHook *schema.Hook `json:"backup_hook,omitempty"`
+	// RestoreHook is the application hook that restores the backup
+	RestoreHook *schema.Hook `json:"restore_hook,omitempty"`
+	// VerifyRestoreHook is the application hook that verifies the backup
+	VerifyRestoreHook *schema.Hook `json:"verify_restore_hook,omitempty"`
+	// HookDir is the directory relative to the backup root
+	// with the data written by the backup hook
+	HookDir string `json:"hook_dir,omitempty"`
+	// Files lists the checksums of all files in the backup
+	Files []File `json:"files"`
+}
+
+// File describes a single file in the backup
+type File struct {
+	// Path is the file path relative to the backup root
+	Path string `json:"path"`
+	// Size is the file size in bytes
+	Size int64 `json:"size"`
+	// SHA256 is the hex-encoded SHA256 checksum of the file
+	SHA256 string `json:"sha256"`
+}
+
+// WriteManifest computes the checksums of all files in dir and
+// writes the manifest into the root of dir
+func WriteManifest(dir string, manifest Manifest) (*Manifest, error) {
+	files, err := checksumFiles(dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	manifest.Version = ManifestVersion
+	manifest.Files = files
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, ManifestFile), data, defaults.SharedReadMask)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return &manifest, nil
+}
+
+// ReadManifest reads the manifest from the root of the backup directory dir
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		err = trace.ConvertSystemError(err)
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("backup has no manifest")
+		}
+		return nil, trace.Wrap(err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, trace.Wrap(err, "failed to parse backup manifest")
+	}
+	if manifest.Version != ManifestVersion {
+		return nil, trace.BadParameter("unsupported backup manifest version %q", manifest.Version)
+	}
+	return &manifest, nil
+}
+
+// Verify validates the contents of the backup directory dir
+// against its manifest.
+// Returns the manifest and an aggregate of all discrepancies found
+func Verify(dir string) (*Manifest, error) {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	files, err := checksumFiles(dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	actual := make(map[string]File, len(files))
+	for _, file := range files {
+		actual[file.Path] = file
+	}
+	var errors []error
+	for _, expected := range manifest.Files {
+		file, ok := actual[expected.Path]
+		if !ok {
+			errors = append(errors, trace.NotFound("file %v is missing", expected.Path))
+			continue
+		}
+		delete(actual, expected.Path)
+		if file.Size != expected.Size {
+			errors = append(errors, trace.BadParameter("file %v has size %v, expected %v",
+				expected.Path, file.Size, expected.Size))
+			continue
+		}
+		if file.SHA256 != expected.SHA256 {
+			errors = append(errors, trace.BadParameter("file %v has checksum %v, expected %v",
+				expected.Path, file.SHA256, expected.SHA256))
+		}
+	}
+	for _, path := range sortedPaths(actual) {
+		errors = append(errors, trace.BadParameter("file %v is not in the manifest", path))
+	}
+	return manifest, trace.NewAggregate(errors...)
+}
+
+// checksumFiles computes the checksums of all regular files in dir
+// except the manifest itself
+func checksumFiles(dir string) (files []File, err error) {
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ManifestFile {
+			return nil
+		}
+		checksum, err := checksumFile(path)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		files = append(files, File{
+			Path:   rel,
+			Size:   info.Size(),
+			SHA256: checksum,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return files, nil
+}
+
+func checksumFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func sortedPaths(files map[string]File) (paths []string) {
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+const (
+	// ManifestFile is the name of the backup manifest file
+	ManifestFile = "manifest.json"
+	// ManifestVersion is the current version of the backup manifest format
+	ManifestVersion = "v1"
+)
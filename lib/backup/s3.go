@@ -0,0 +1,249 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/gravitational/trace"
+)
+
+// S3Config is the S3-backed backup store configuration
+type S3Config struct {
+	// S3Destination describes the bucket backups are stored in
+	storage.S3Destination
+	// S3 is optional S3 API client
+	S3 s3iface.S3API
+	// Uploader is optional S3 upload manager
+	Uploader Uploader
+}
+
+// Uploader uploads objects to S3
+type Uploader interface {
+	// UploadWithContext uploads the object to S3
+	UploadWithContext(aws.Context, *s3manager.UploadInput, ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error)
+}
+
+// CheckAndSetDefaults validates config and sets defaults
+func (c *S3Config) CheckAndSetDefaults() error {
+	if c.Bucket == "" {
+		return trace.BadParameter("missing parameter Bucket")
+	}
+	if c.AccessKeyID != "" && c.SecretAccessKey == "" {
+		return trace.BadParameter("missing parameter SecretAccessKey")
+	}
+	if c.Region == "" {
+		c.Region = defaults.AWSRegion
+	}
+	if c.S3 == nil {
+		config := &aws.Config{
+			Region: aws.String(c.Region),
+		}
+		if c.Endpoint != "" {
+			config.Endpoint = aws.String(c.Endpoint)
+			config.S3ForcePathStyle = aws.Bool(true)
+		}
+		if c.AccessKeyID != "" {
+			config.Credentials = credentials.NewStaticCredentials(
+				c.AccessKeyID, c.SecretAccessKey, "")
+		}
+		session, err := session.NewSession(config)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		c.S3 = s3.New(session)
+	}
+	if c.Uploader == nil {
+		c.Uploader = s3manager.NewUploaderWithClient(c.S3)
+	}
+	return nil
+}
+
+// NewS3Store returns the store that keeps backups in the S3 bucket
+func NewS3Store(config S3Config) (*s3Store, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &s3Store{S3Config: config}, nil
+}
+
+// s3Store keeps backup archives and their metadata as objects
+// in the S3 bucket
+type s3Store struct {
+	S3Config
+}
+
+// Put uploads the backup archive read from r
+func (s *s3Store) Put(ctx context.Context, backup storage.Backup, r io.Reader) (*storage.Backup, error) {
+	if err := checkName(backup.Name); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	reader := &countingReader{r: r}
+	_, err := s.Uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.key(backup.Name + archiveExt)),
+		Body:   reader,
+	})
+	if err != nil {
+		return nil, trace.Wrap(convertS3Error(err))
+	}
+	backup.Size = reader.count
+	data, err := json.Marshal(backup)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// metadata is uploaded last so incomplete backups are never listed
+	_, err = s.S3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.key(backup.Name + metadataExt)),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return nil, trace.Wrap(convertS3Error(err))
+	}
+	return &backup, nil
+}
+
+// List returns all stored backups sorted by creation time
+func (s *s3Store) List(ctx context.Context) ([]storage.Backup, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(s.key("")),
+	}
+	var backups []storage.Backup
+	for {
+		output, err := s.S3.ListObjectsV2WithContext(ctx, input)
+		if err != nil {
+			return nil, trace.Wrap(convertS3Error(err))
+		}
+		for _, object := range output.Contents {
+			key := aws.StringValue(object.Key)
+			name := strings.TrimPrefix(key, s.key(""))
+			if !strings.HasSuffix(name, metadataExt) || strings.Contains(name, "/") {
+				continue
+			}
+			backup, err := s.Get(ctx, strings.TrimSuffix(name, metadataExt))
+			if err != nil {
+				if trace.IsNotFound(err) {
+					continue
+				}
+				return nil, trace.Wrap(err)
+			}
+			backups = append(backups, *backup)
+		}
+		if !aws.BoolValue(output.IsTruncated) {
+			break
+		}
+		input.ContinuationToken = output.NextContinuationToken
+	}
+	sortBackups(backups)
+	return backups, nil
+}
+
+// Get returns the backup by name
+func (s *s3Store) Get(ctx context.Context, name string) (*storage.Backup, error) {
+	if err := checkName(name); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	rc, err := s.open(ctx, name+metadataExt)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("backup %q not found", name)
+		}
+		return nil, trace.Wrap(err)
+	}
+	defer rc.Close()
+	var backup storage.Backup
+	if err := json.NewDecoder(rc).Decode(&backup); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &backup, nil
+}
+
+// Open returns the reader of the backup archive
+func (s *s3Store) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	if _, err := s.Get(ctx, name); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	rc, err := s.open(ctx, name+archiveExt)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return rc, nil
+}
+
+// Delete deletes the backup by name
+func (s *s3Store) Delete(ctx context.Context, name string) error {
+	if _, err := s.Get(ctx, name); err != nil {
+		return trace.Wrap(err)
+	}
+	for _, key := range []string{name + metadataExt, name + archiveExt} {
+		_, err := s.S3.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(s.Bucket),
+			Key:    aws.String(s.key(key)),
+		})
+		if err != nil {
+			return trace.Wrap(convertS3Error(err))
+		}
+	}
+	return nil
+}
+
+func (s *s3Store) open(ctx context.Context, name string) (io.ReadCloser, error) {
+	output, err := s.S3.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.key(name)),
+	})
+	if err != nil {
+		return nil, trace.Wrap(convertS3Error(err))
+	}
+	return output.Body, nil
+}
+
+// key returns the object key for the specified name
+func (s *s3Store) key(name string) string {
+	prefix := strings.Trim(s.Prefix, "/")
+	if prefix == "" {
+		return name
+	}
+	return prefix + "/" + name
+}
+
+// convertS3Error converts the missing object errors to trace.NotFound
+func convertS3Error(err error) error {
+	if awsErr, ok := trace.Unwrap(err).(awserr.Error); ok {
+		switch awsErr.Code() {
+		case s3.ErrCodeNoSuchKey, "NotFound":
+			return trace.NotFound("%v", awsErr.Message())
+		}
+	}
+	return err
+}
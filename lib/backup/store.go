@@ -0,0 +1,256 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// Store stores backup archives along with their metadata
+type Store interface {
+	// Put stores the backup archive read from r.
+	// Returns the stored backup with the archive size set
+	Put(ctx context.Context, backup storage.Backup, r io.Reader) (*storage.Backup, error)
+	// List returns all stored backups sorted by creation time
+	List(ctx context.Context) ([]storage.Backup, error)
+	// Get returns the backup by name
+	Get(ctx context.Context, name string) (*storage.Backup, error)
+	// Open returns the reader of the backup archive
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	// Delete deletes the backup by name
+	Delete(ctx context.Context, name string) error
+}
+
+// NewStore returns the store for the specified backup destination
+func NewStore(destination storage.BackupDestination) (Store, error) {
+	if destination.S3 != nil {
+		store, err := NewS3Store(S3Config{S3Destination: *destination.S3})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return store, nil
+	}
+	return NewLocalStore(destination.Path)
+}
+
+// NewLocalStore returns the store that keeps backups in the specified directory
+func NewLocalStore(dir string) (*localStore, error) {
+	if dir == "" {
+		return nil, trace.BadParameter("missing backup directory")
+	}
+	return &localStore{dir: dir}, nil
+}
+
+// localStore keeps backup archives and their metadata as files
+// in a local directory
+type localStore struct {
+	dir string
+}
+
+// Put stores the backup archive read from r
+func (s *localStore) Put(ctx context.Context, backup storage.Backup, r io.Reader) (*storage.Backup, error) {
+	if err := checkName(backup.Name); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := os.MkdirAll(s.dir, defaults.SharedDirMask); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	size, err := writeFileAtomic(s.archivePath(backup.Name), func(w io.Writer) error {
+		_, err := io.Copy(w, r)
+		return trace.Wrap(err)
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	backup.Size = size
+	// metadata is written last so incomplete backups are never listed
+	_, err = writeFileAtomic(s.metadataPath(backup.Name), func(w io.Writer) error {
+		return trace.Wrap(json.NewEncoder(w).Encode(backup))
+	})
+	if err != nil {
+		os.Remove(s.archivePath(backup.Name))
+		return nil, trace.Wrap(err)
+	}
+	return &backup, nil
+}
+
+// List returns all stored backups sorted by creation time
+func (s *localStore) List(ctx context.Context) ([]storage.Backup, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*"+metadataExt))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var backups []storage.Backup
+	for _, path := range paths {
+		backup, err := s.Get(ctx, strings.TrimSuffix(filepath.Base(path), metadataExt))
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		backups = append(backups, *backup)
+	}
+	sortBackups(backups)
+	return backups, nil
+}
+
+// Get returns the backup by name
+func (s *localStore) Get(ctx context.Context, name string) (*storage.Backup, error) {
+	if err := checkName(name); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	data, err := ioutil.ReadFile(s.metadataPath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, trace.NotFound("backup %q not found", name)
+		}
+		return nil, trace.ConvertSystemError(err)
+	}
+	var backup storage.Backup
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &backup, nil
+}
+
+// Open returns the reader of the backup archive
+func (s *localStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	if _, err := s.Get(ctx, name); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	f, err := os.Open(s.archivePath(name))
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return f, nil
+}
+
+// Delete deletes the backup by name
+func (s *localStore) Delete(ctx context.Context, name string) error {
+	if _, err := s.Get(ctx, name); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := os.Remove(s.metadataPath(name)); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if err := os.Remove(s.archivePath(name)); err != nil && !os.IsNotExist(err) {
+		return trace.ConvertSystemError(err)
+	}
+	return nil
+}
+
+func (s *localStore) archivePath(name string) string {
+	return filepath.Join(s.dir, name+archiveExt)
+}
+
+func (s *localStore) metadataPath(name string) string {
+	return filepath.Join(s.dir, name+metadataExt)
+}
+
+// writeFileAtomic writes the file at the specified path using fn.
+// The data is written into a temporary file that is renamed
+// once fn succeeds. Returns the number of bytes written
+func writeFileAtomic(path string, fn func(io.Writer) error) (size int64, err error) {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return 0, trace.ConvertSystemError(err)
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	w := &countingWriter{w: f}
+	if err = fn(w); err != nil {
+		return 0, trace.Wrap(err)
+	}
+	if err = f.Sync(); err != nil {
+		return 0, trace.ConvertSystemError(err)
+	}
+	if err = f.Close(); err != nil {
+		return 0, trace.ConvertSystemError(err)
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return 0, trace.ConvertSystemError(err)
+	}
+	return w.count, nil
+}
+
+// checkName makes sure the backup name can be safely used as a file name
+func checkName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return trace.BadParameter("invalid backup name %q", name)
+	}
+	return nil
+}
+
+// sortBackups sorts backups by creation time, oldest first
+func sortBackups(backups []storage.Backup) {
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].Created.Equal(backups[j].Created) {
+			return backups[i].Name < backups[j].Name
+		}
+		return backups[i].Created.Before(backups[j].Created)
+	})
+}
+
+// countingWriter counts the bytes written to the underlying writer
+type countingWriter struct {
+	w     io.Writer
+	count int64
+}
+
+// Write writes p to the underlying writer
+func (r *countingWriter) Write(p []byte) (int, error) {
+	n, err := r.w.Write(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// countingReader counts the bytes read from the underlying reader
+type countingReader struct {
+	r     io.Reader
+	count int64
+}
+
+// Read reads from the underlying reader
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+const (
+	// archiveExt is the extension of backup archive files
+	archiveExt = ".tar.gz"
+	// metadataExt is the extension of backup metadata files
+	metadataExt = ".json"
+)
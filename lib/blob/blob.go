@@ -54,3 +54,56 @@ type Objects interface {
 	// GetBLOBEnvelope returns BLOB envelope
 	GetBLOBEnvelope(hash string) (*Envelope, error)
 }
+
+// DedupStats describes storage savings of a deduplicating BLOB store
+type DedupStats struct {
+	// BLOBs is the number of BLOBs in the store
+	BLOBs int `json:"blobs"`
+	// Chunks is the number of unique chunks persisted on disk
+	Chunks int `json:"chunks"`
+	// LogicalBytes is the total size of all BLOBs as seen by clients
+	LogicalBytes int64 `json:"logical_bytes"`
+	// PhysicalBytes is the total size of data persisted on disk
+	PhysicalBytes int64 `json:"physical_bytes"`
+}
+
+// Ratio returns the deduplication ratio, i.e. how many times
+// the logical size exceeds the physical size
+func (s DedupStats) Ratio() float64 {
+	if s.PhysicalBytes == 0 {
+		return 1
+	}
+	return float64(s.LogicalBytes) / float64(s.PhysicalBytes)
+}
+
+// Deduplicator is implemented by BLOB stores that deduplicate
+// data they persist
+type Deduplicator interface {
+	// GetDedupStats returns deduplication statistics for the store
+	GetDedupStats() (*DedupStats, error)
+}
+
+// Chunk describes a single chunk of a BLOB persisted as a sequence of chunks
+type Chunk struct {
+	// Hash is the SHA512 half hash of the chunk data
+	Hash string `json:"hash"`
+	// SizeBytes is the chunk size in bytes
+	SizeBytes int64 `json:"size_bytes"`
+}
+
+// ChunkedObjects is implemented by BLOB stores that persist BLOBs
+// as sequences of content-addressed chunks. It allows to transfer
+// only the chunks a receiver does not have yet
+type ChunkedObjects interface {
+	Objects
+	// GetBLOBChunks returns the ordered list of chunks the BLOB consists of
+	GetBLOBChunks(hash string) ([]Chunk, error)
+	// OpenChunk opens the chunk by hash
+	OpenChunk(hash string) (io.ReadCloser, error)
+	// HasChunk returns true if the chunk is present in the store
+	HasChunk(hash string) (bool, error)
+	// WriteChunk writes the chunk after verifying its size and hash
+	WriteChunk(chunk Chunk, data io.Reader) error
+	// WriteBLOBFromChunks assembles a BLOB from the chunks present in the store
+	WriteBLOBFromChunks(chunks []Chunk) (*Envelope, error)
+}
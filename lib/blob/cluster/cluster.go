@@ -299,7 +299,12 @@ func (c *cluster) WriteBLOB(data io.Reader) (*blob.Envelope, error) {
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	return c.replicate(envelope, peers)
+}
 
+// replicate pushes the BLOB written to the local storage to peers
+// until the write factor is satisfied
+func (c *cluster) replicate(envelope *blob.Envelope, peers []storage.Peer) (*blob.Envelope, error) {
 	successWrites := []string{c.ID}
 	if len(successWrites) >= c.WriteFactor {
 		c.Debugf("Got enough success writes for %v %v.", envelope.SHA512, successWrites)
@@ -350,6 +355,80 @@ func (c *cluster) WriteBLOB(data io.Reader) (*blob.Envelope, error) {
 	return nil, trace.Wrap(trace.NewAggregate(errors...), "not enough successfull writes")
 }
 
+// GetBLOBChunks returns the ordered list of chunks the BLOB consists of,
+// fetching the BLOB from peers if it is not available locally yet
+func (c *cluster) GetBLOBChunks(hash string) ([]blob.Chunk, error) {
+	local, err := c.chunkedLocal()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	chunks, err := local.GetBLOBChunks(hash)
+	if err == nil || !trace.IsNotFound(err) {
+		return chunks, trace.Wrap(err)
+	}
+	if err := c.fetchObject(hash); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return local.GetBLOBChunks(hash)
+}
+
+// OpenChunk opens the chunk from the local storage
+func (c *cluster) OpenChunk(hash string) (io.ReadCloser, error) {
+	local, err := c.chunkedLocal()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return local.OpenChunk(hash)
+}
+
+// HasChunk returns true if the local storage has the chunk
+func (c *cluster) HasChunk(hash string) (bool, error) {
+	local, err := c.chunkedLocal()
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	return local.HasChunk(hash)
+}
+
+// WriteChunk writes the chunk to the local storage
+func (c *cluster) WriteChunk(chunk blob.Chunk, data io.Reader) error {
+	local, err := c.chunkedLocal()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return local.WriteChunk(chunk, data)
+}
+
+// WriteBLOBFromChunks assembles the BLOB in the local storage
+// and replicates it to peers
+func (c *cluster) WriteBLOBFromChunks(chunks []blob.Chunk) (*blob.Envelope, error) {
+	local, err := c.chunkedLocal()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	peers, err := c.getPeers(nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if len(peers) < c.WriteFactor {
+		return nil, trace.ConnectionProblem(
+			nil, "not enough peers online %#v, need %v", peers, c.WriteFactor)
+	}
+	envelope, err := local.WriteBLOBFromChunks(chunks)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return c.replicate(envelope, peers)
+}
+
+func (c *cluster) chunkedLocal() (blob.ChunkedObjects, error) {
+	local, ok := c.Local.(blob.ChunkedObjects)
+	if !ok {
+		return nil, trace.NotImplemented("local storage does not support chunks")
+	}
+	return local, nil
+}
+
 func (c *cluster) getObjects(p storage.Peer) (blob.Objects, error) {
 	if p.ID == c.ID {
 		return c.Local, nil
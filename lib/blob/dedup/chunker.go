@@ -0,0 +1,145 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"io"
+	"math/bits"
+
+	"github.com/gravitational/trace"
+)
+
+// chunker splits a stream into content-defined chunks using a gear
+// rolling hash with normalized chunking: cut points depend only on the
+// data around them, so an insertion or removal in one part of the stream
+// does not shift chunk boundaries in the rest of it
+type chunker struct {
+	r io.Reader
+	// buf holds up to max bytes of unconsumed data
+	buf []byte
+	// start and end delimit unconsumed data in buf
+	start, end int
+	eof        bool
+
+	min, avg, max int
+	// maskSmall is used before the average chunk size is reached
+	// and makes a cut point less likely
+	maskSmall uint64
+	// maskLarge is used after the average chunk size is reached
+	// and makes a cut point more likely
+	maskLarge uint64
+}
+
+func newChunker(r io.Reader, min, avg, max int) *chunker {
+	avgBits := uint(bits.Len(uint(avg)) - 1)
+	return &chunker{
+		r:         r,
+		buf:       make([]byte, max),
+		min:       min,
+		avg:       avg,
+		max:       max,
+		maskSmall: topBits(avgBits + 2),
+		maskLarge: topBits(avgBits - 2),
+	}
+}
+
+// next returns the next chunk from the stream or io.EOF when the stream
+// has been consumed. The returned slice is only valid until the next call
+func (c *chunker) next() ([]byte, error) {
+	if err := c.fill(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if c.start == c.end {
+		return nil, io.EOF
+	}
+	data := c.buf[c.start:c.end]
+	cut := c.cutpoint(data)
+	c.start += cut
+	return data[:cut], nil
+}
+
+// fill moves unconsumed data to the beginning of the buffer
+// and reads from the stream until the buffer is full
+func (c *chunker) fill() error {
+	if c.start != 0 {
+		c.end = copy(c.buf, c.buf[c.start:c.end])
+		c.start = 0
+	}
+	for !c.eof && c.end < len(c.buf) {
+		n, err := c.r.Read(c.buf[c.end:])
+		c.end += n
+		if err == io.EOF {
+			c.eof = true
+			break
+		}
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+	}
+	return nil
+}
+
+func (c *chunker) cutpoint(data []byte) int {
+	if len(data) <= c.min {
+		return len(data)
+	}
+	n := len(data)
+	if n > c.max {
+		n = c.max
+	}
+	normal := c.avg
+	if normal > n {
+		normal = n
+	}
+	var hash uint64
+	i := c.min
+	for ; i < normal; i++ {
+		hash = (hash << 1) + gear[data[i]]
+		if hash&c.maskSmall == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		hash = (hash << 1) + gear[data[i]]
+		if hash&c.maskLarge == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
+
+// topBits returns a mask with the specified number of most significant bits set.
+// Gear hash accumulates the influence of the most recent bytes in the upper bits
+func topBits(n uint) uint64 {
+	return ^uint64(0) << (64 - n)
+}
+
+// gear is a table of random values used by the rolling hash.
+// It is generated deterministically so that chunk boundaries are stable
+// across processes and versions
+var gear = func() (table [256]uint64) {
+	// splitmix64
+	seed := uint64(0x6772617669747921)
+	for i := range table {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
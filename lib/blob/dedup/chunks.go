@@ -0,0 +1,196 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gravitational/gravity/lib/blob"
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/gravitational/trace"
+)
+
+// GetBLOBChunks returns the ordered list of chunks the BLOB consists of.
+// Legacy BLOBs are converted to chunks on demand
+func (o *Objects) GetBLOBChunks(hash string) ([]blob.Chunk, error) {
+	m, err := o.readManifest(hash)
+	if err == nil {
+		return m.Chunks, nil
+	}
+	if !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	if _, err := o.migrateBLOB(hash); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	m, err = o.readManifest(hash)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return m.Chunks, nil
+}
+
+// OpenChunk opens the chunk identified by hash
+func (o *Objects) OpenChunk(hash string) (io.ReadCloser, error) {
+	if err := checkHash(hash); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	f, err := os.Open(o.chunkPath(hash))
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return f, nil
+}
+
+// HasChunk returns true if the chunk identified by hash is in the storage
+func (o *Objects) HasChunk(hash string) (bool, error) {
+	if err := checkHash(hash); err != nil {
+		return false, trace.Wrap(err)
+	}
+	_, err := os.Stat(o.chunkPath(hash))
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, trace.ConvertSystemError(err)
+}
+
+// WriteChunk reads the chunk from data and persists it
+// after verifying its size and hash
+func (o *Objects) WriteChunk(chunk blob.Chunk, data io.Reader) error {
+	if err := checkHash(chunk.Hash); err != nil {
+		return trace.Wrap(err)
+	}
+	if chunk.SizeBytes <= 0 || chunk.SizeBytes > defaults.DedupMaxTransferChunkSize {
+		return trace.BadParameter("chunk %v has unsupported size %v", chunk.Hash, chunk.SizeBytes)
+	}
+	buf := make([]byte, chunk.SizeBytes)
+	if _, err := io.ReadFull(data, buf); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	ref, err := o.writeChunk(buf)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if ref.Hash != chunk.Hash {
+		// the chunk is persisted under its real hash and will
+		// be collected along with the BLOBs that reference it
+		return trace.BadParameter("chunk hash mismatch: expected %v, got %v", chunk.Hash, ref.Hash)
+	}
+	return nil
+}
+
+// WriteBLOBFromChunks assembles a BLOB from the chunks already in the storage
+func (o *Objects) WriteBLOBFromChunks(chunks []blob.Chunk) (*blob.Envelope, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	hasher := sha512.New()
+	m := manifest{Chunks: chunks}
+	for _, chunk := range chunks {
+		r, err := o.OpenChunk(chunk.Hash)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		n, err := io.Copy(hasher, r)
+		r.Close()
+		if err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+		if n != chunk.SizeBytes {
+			return nil, trace.BadParameter("chunk %v size mismatch: expected %v, got %v",
+				chunk.Hash, chunk.SizeBytes, n)
+		}
+		m.SizeBytes += n
+	}
+	hash := fmt.Sprintf("%x", hasher.Sum(nil)[:sha512.Size/2])
+	m.Modified = time.Now().UTC()
+	if err := o.writeManifest(hash, m); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &blob.Envelope{
+		SizeBytes: m.SizeBytes,
+		SHA512:    hash,
+		Modified:  m.Modified,
+	}, nil
+}
+
+// SweepOrphanChunks removes chunks that are not referenced by any BLOB
+// manifest and were written more than olderThan ago, for example chunks
+// left behind by an interrupted chunked transfer. Recent chunks are kept
+// since the transfer they belong to might still be in progress
+func (o *Objects) SweepOrphanChunks(olderThan time.Duration) (*SweepResult, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	referenced, err := o.referencedChunks()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	cutoff := time.Now().Add(-olderThan)
+	var result SweepResult
+	var orphans []os.FileInfo
+	err = walkFiles(o.chunkDir(), func(info os.FileInfo) {
+		if !referenced.Has(info.Name()) && info.ModTime().Before(cutoff) {
+			orphans = append(orphans, info)
+		}
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, info := range orphans {
+		if checkHash(info.Name()) != nil {
+			continue
+		}
+		err := os.Remove(o.chunkPath(info.Name()))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, trace.ConvertSystemError(err)
+		}
+		result.Chunks++
+		result.BytesFreed += info.Size()
+	}
+	return &result, nil
+}
+
+// SweepResult describes the outcome of the orphan chunk sweep
+type SweepResult struct {
+	// Chunks is the number of removed chunks
+	Chunks int
+	// BytesFreed is the total size of removed chunks
+	BytesFreed int64
+}
+
+// checkHash makes sure hash can be safely used as a file name
+func checkHash(hash string) error {
+	// SHA512 half hash has the same length as SHA256
+	if len(hash) != hex.EncodedLen(sha256.Size) {
+		return trace.BadParameter("invalid hash %q", hash)
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return trace.BadParameter("invalid hash %q", hash)
+	}
+	return nil
+}
@@ -0,0 +1,432 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package dedup implements a content-addressed BLOB storage that splits
+BLOBs into content-defined chunks and persists every unique chunk only once.
+
+The directory layout is:
+
+	tmp/                       - temporary files for in-flight writes
+	chunks/<hash[0:3]>/<hash>  - unique chunks addressed by their SHA512 half hash
+	manifests/<hash[0:3]>/<hash> - BLOB manifests listing chunks in order
+	blobs/<hash[0:3]>/<hash>   - legacy whole-file BLOBs written by the fs storage
+
+Legacy BLOBs are served transparently until they are converted with Migrate.
+*/
+package dedup
+
+import (
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gravitational/gravity/lib/blob"
+	"github.com/gravitational/gravity/lib/blob/fs"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// Config defines the deduplicating BLOB storage configuration
+type Config struct {
+	// Path is the storage root directory
+	Path string
+	// MinChunkSize is the minimum chunk size in bytes
+	MinChunkSize int
+	// AvgChunkSize is the target average chunk size in bytes, must be a power of 2
+	AvgChunkSize int
+	// MaxChunkSize is the maximum chunk size in bytes
+	MaxChunkSize int
+}
+
+// CheckAndSetDefaults validates the config and sets default values
+func (c *Config) CheckAndSetDefaults() error {
+	if c.Path == "" {
+		return trace.BadParameter("missing Path parameter")
+	}
+	if c.AvgChunkSize == 0 {
+		c.AvgChunkSize = defaults.DedupAvgChunkSize
+	}
+	if c.MinChunkSize == 0 {
+		c.MinChunkSize = c.AvgChunkSize / 4
+	}
+	if c.MaxChunkSize == 0 {
+		c.MaxChunkSize = c.AvgChunkSize * 4
+	}
+	if c.AvgChunkSize&(c.AvgChunkSize-1) != 0 || c.AvgChunkSize < 64 {
+		return trace.BadParameter("AvgChunkSize should be a power of 2 and at least 64 bytes, got %v",
+			c.AvgChunkSize)
+	}
+	if c.MinChunkSize > c.AvgChunkSize || c.AvgChunkSize > c.MaxChunkSize {
+		return trace.BadParameter("expected MinChunkSize <= AvgChunkSize <= MaxChunkSize, got %v, %v, %v",
+			c.MinChunkSize, c.AvgChunkSize, c.MaxChunkSize)
+	}
+	return nil
+}
+
+// New returns a new instance of the deduplicating BLOB storage
+func New(config Config) (*Objects, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	o := &Objects{Config: config}
+	for _, d := range []string{o.tempDir(), o.chunkDir(), o.manifestDir()} {
+		if err := os.MkdirAll(d, defaults.SharedDirMask); err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+	}
+	legacy, err := fs.New(config.Path)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	o.legacy = legacy
+	return o, nil
+}
+
+// Objects is a BLOB storage that persists BLOBs as sequences
+// of deduplicated chunks
+type Objects struct {
+	// Config is the storage configuration
+	Config
+	// legacy serves BLOBs written by the whole-file storage
+	// before the migration
+	legacy blob.Objects
+	// mu protects chunks from being garbage collected while
+	// they are being referenced by a write in progress.
+	// Writes take a read lock, garbage collection takes a write lock
+	mu sync.RWMutex
+}
+
+// manifest describes a BLOB as an ordered list of chunks
+type manifest struct {
+	// SizeBytes is the BLOB size in bytes
+	SizeBytes int64 `json:"size_bytes"`
+	// Modified is the time the BLOB was last written
+	Modified time.Time `json:"modified"`
+	// Chunks lists BLOB chunks in order
+	Chunks []blob.Chunk `json:"chunks"`
+}
+
+func (o *Objects) tempDir() string {
+	return filepath.Join(o.Path, "tmp")
+}
+
+func (o *Objects) chunkDir() string {
+	return filepath.Join(o.Path, "chunks")
+}
+
+func (o *Objects) manifestDir() string {
+	return filepath.Join(o.Path, "manifests")
+}
+
+func (o *Objects) chunkPath(hash string) string {
+	return filepath.Join(o.chunkDir(), hash[0:3], hash)
+}
+
+func (o *Objects) manifestPath(hash string) string {
+	return filepath.Join(o.manifestDir(), hash[0:3], hash)
+}
+
+// Close closes the storage
+func (o *Objects) Close() error {
+	return o.legacy.Close()
+}
+
+// WriteBLOB splits data into chunks, persists chunks that are not
+// in the storage yet and records the BLOB manifest
+func (o *Objects) WriteBLOB(data io.Reader) (*blob.Envelope, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	hasher := sha512.New()
+	chunker := newChunker(io.TeeReader(data, hasher), o.MinChunkSize, o.AvgChunkSize, o.MaxChunkSize)
+	var m manifest
+	for {
+		chunk, err := chunker.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		ref, err := o.writeChunk(chunk)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		m.Chunks = append(m.Chunks, *ref)
+		m.SizeBytes += ref.SizeBytes
+	}
+	hash := fmt.Sprintf("%x", hasher.Sum(nil)[:sha512.Size/2])
+	m.Modified = time.Now().UTC()
+	if err := o.writeManifest(hash, m); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &blob.Envelope{
+		SizeBytes: m.SizeBytes,
+		SHA512:    hash,
+		Modified:  m.Modified,
+	}, nil
+}
+
+// writeChunk persists the chunk unless the storage already has it
+func (o *Objects) writeChunk(data []byte) (*blob.Chunk, error) {
+	hash, err := utils.SHA512Half(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	ref := &blob.Chunk{Hash: hash, SizeBytes: int64(len(data))}
+	path := o.chunkPath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return ref, nil
+	}
+	if err := o.writeFile(path, data); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return ref, nil
+}
+
+func (o *Objects) writeManifest(hash string, m manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(o.writeFile(o.manifestPath(hash), data))
+}
+
+// writeFile atomically writes data to the specified path
+// by writing to a temporary file first
+func (o *Objects) writeFile(path string, data []byte) error {
+	f, err := ioutil.TempFile(o.tempDir(), "chunk")
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return trace.ConvertSystemError(err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return trace.ConvertSystemError(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), defaults.SharedDirMask); err != nil {
+		os.Remove(f.Name())
+		return trace.ConvertSystemError(err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return trace.ConvertSystemError(err)
+	}
+	return nil
+}
+
+func (o *Objects) readManifest(hash string) (*manifest, error) {
+	if err := checkHash(hash); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	data, err := ioutil.ReadFile(o.manifestPath(hash))
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, trace.Wrap(err, "failed to read manifest for %v", hash)
+	}
+	return &m, nil
+}
+
+// GetBLOBEnvelope returns BLOB envelope identified by hash
+func (o *Objects) GetBLOBEnvelope(hash string) (*blob.Envelope, error) {
+	m, err := o.readManifest(hash)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return o.legacy.GetBLOBEnvelope(hash)
+		}
+		return nil, trace.Wrap(err)
+	}
+	return &blob.Envelope{
+		SizeBytes: m.SizeBytes,
+		SHA512:    hash,
+		Modified:  m.Modified,
+	}, nil
+}
+
+// OpenBLOB returns a reader that reassembles the BLOB from its chunks
+func (o *Objects) OpenBLOB(hash string) (blob.ReadSeekCloser, error) {
+	m, err := o.readManifest(hash)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return o.legacy.OpenBLOB(hash)
+		}
+		return nil, trace.Wrap(err)
+	}
+	return newReader(o, *m), nil
+}
+
+// GetBLOBs returns a sorted list of BLOBs in the storage
+func (o *Objects) GetBLOBs() ([]string, error) {
+	hashes, err := listFiles(o.manifestDir())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	legacy, err := o.legacy.GetBLOBs()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	out := utils.NewStringSet()
+	out.AddSlice(hashes)
+	out.AddSlice(legacy)
+	return out.Slice(), nil
+}
+
+// DeleteBLOB deletes the BLOB and the chunks no longer referenced
+// by any other BLOB
+func (o *Objects) DeleteBLOB(hash string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	m, err := o.readManifest(hash)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return o.legacy.DeleteBLOB(hash)
+		}
+		return trace.Wrap(err)
+	}
+	if err := os.Remove(o.manifestPath(hash)); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	// the same data might also be present as a legacy BLOB
+	if err := o.legacy.DeleteBLOB(hash); err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(o.collectChunks(m.Chunks))
+}
+
+// collectChunks removes the specified chunks unless they are
+// referenced by other BLOB manifests. Must be called under write lock
+func (o *Objects) collectChunks(chunks []blob.Chunk) error {
+	referenced, err := o.referencedChunks()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, chunk := range chunks {
+		if referenced.Has(chunk.Hash) {
+			continue
+		}
+		err := os.Remove(o.chunkPath(chunk.Hash))
+		if err != nil && !os.IsNotExist(err) {
+			return trace.ConvertSystemError(err)
+		}
+		// the same chunk can appear several times in the BLOB
+		referenced.Add(chunk.Hash)
+	}
+	return nil
+}
+
+// referencedChunks returns the set of chunks referenced by all manifests
+func (o *Objects) referencedChunks() (utils.StringSet, error) {
+	hashes, err := listFiles(o.manifestDir())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	referenced := utils.NewStringSet()
+	for _, hash := range hashes {
+		m, err := o.readManifest(hash)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, chunk := range m.Chunks {
+			referenced.Add(chunk.Hash)
+		}
+	}
+	return referenced, nil
+}
+
+// GetDedupStats returns deduplication statistics for the storage
+func (o *Objects) GetDedupStats() (*blob.DedupStats, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	var stats blob.DedupStats
+	hashes, err := listFiles(o.manifestDir())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, hash := range hashes {
+		m, err := o.readManifest(hash)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		stats.BLOBs++
+		stats.LogicalBytes += m.SizeBytes
+	}
+	err = walkFiles(o.chunkDir(), func(info os.FileInfo) {
+		stats.Chunks++
+		stats.PhysicalBytes += info.Size()
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	legacy, err := o.legacy.GetBLOBs()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, hash := range legacy {
+		envelope, err := o.legacy.GetBLOBEnvelope(hash)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		stats.BLOBs++
+		stats.LogicalBytes += envelope.SizeBytes
+		stats.PhysicalBytes += envelope.SizeBytes
+	}
+	return &stats, nil
+}
+
+// listFiles returns a sorted list of file names found under dir
+func listFiles(dir string) ([]string, error) {
+	var out []string
+	err := walkFiles(dir, func(info os.FileInfo) {
+		out = append(out, info.Name())
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func walkFiles(dir string, fn func(os.FileInfo)) error {
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			log.Warningf("Error while traversing %v: %v.", dir, err)
+			return nil
+		}
+		if !info.IsDir() {
+			fn(info)
+		}
+		return nil
+	})
+	return trace.ConvertSystemError(err)
+}
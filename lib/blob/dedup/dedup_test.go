@@ -0,0 +1,195 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/blob"
+	"github.com/gravitational/gravity/lib/blob/fs"
+	"github.com/gravitational/gravity/lib/blob/suite"
+	"github.com/gravitational/gravity/lib/utils"
+
+	log "github.com/sirupsen/logrus"
+	. "gopkg.in/check.v1"
+)
+
+func TestDedup(t *testing.T) { TestingT(t) }
+
+type DedupSuite struct {
+	suite   suite.BLOBSuite
+	dir     string
+	objects *Objects
+}
+
+var _ = Suite(&DedupSuite{})
+
+func (s *DedupSuite) SetUpTest(c *C) {
+	log.SetOutput(os.Stderr)
+	s.dir = c.MkDir()
+
+	obj, err := New(Config{Path: s.dir, AvgChunkSize: 1024})
+	c.Assert(err, IsNil)
+
+	s.objects = obj
+	s.suite.Objects = obj
+}
+
+func (s *DedupSuite) TestBLOB(c *C) {
+	s.suite.BLOB(c)
+}
+
+func (s *DedupSuite) TestBLOBSeek(c *C) {
+	s.suite.BLOBSeek(c)
+}
+
+func (s *DedupSuite) TestBLOBWriteTwice(c *C) {
+	s.suite.BLOBWriteTwice(c)
+}
+
+func (s *DedupSuite) TestBLOBList(c *C) {
+	s.suite.BLOBList(c)
+}
+
+func (s *DedupSuite) TestDeduplicatesSharedData(c *C) {
+	shared := randomData(64 * 1024)
+	first := append(randomData(3000), shared...)
+	second := append(randomData(5000), shared...)
+
+	e1, err := s.objects.WriteBLOB(bytes.NewReader(first))
+	c.Assert(err, IsNil)
+	e2, err := s.objects.WriteBLOB(bytes.NewReader(second))
+	c.Assert(err, IsNil)
+
+	stats, err := s.objects.GetDedupStats()
+	c.Assert(err, IsNil)
+	c.Assert(stats.BLOBs, Equals, 2)
+	c.Assert(stats.LogicalBytes, Equals, int64(len(first)+len(second)))
+	c.Assert(stats.PhysicalBytes < stats.LogicalBytes*3/4, Equals, true,
+		Commentf("expected shared data to be deduplicated: %#v", stats))
+
+	assertBLOB(c, s.objects, e1.SHA512, first)
+	assertBLOB(c, s.objects, e2.SHA512, second)
+
+	// deleting one BLOB should keep chunks shared with the other one
+	c.Assert(s.objects.DeleteBLOB(e1.SHA512), IsNil)
+	assertBLOB(c, s.objects, e2.SHA512, second)
+
+	c.Assert(s.objects.DeleteBLOB(e2.SHA512), IsNil)
+	stats, err = s.objects.GetDedupStats()
+	c.Assert(err, IsNil)
+	c.Assert(*stats, DeepEquals, blob.DedupStats{})
+}
+
+func (s *DedupSuite) TestSeekAcrossChunks(c *C) {
+	data := randomData(32 * 1024)
+	e, err := s.objects.WriteBLOB(bytes.NewReader(data))
+	c.Assert(err, IsNil)
+
+	r, err := s.objects.OpenBLOB(e.SHA512)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	for _, offset := range []int64{20000, 5, 1023, 32*1024 - 10} {
+		_, err = r.Seek(offset, io.SeekStart)
+		c.Assert(err, IsNil)
+		out, err := ioutil.ReadAll(r)
+		c.Assert(err, IsNil)
+		c.Assert(out, DeepEquals, data[offset:])
+	}
+}
+
+func (s *DedupSuite) TestMigratesLegacyBLOBs(c *C) {
+	legacy, err := fs.New(s.dir)
+	c.Assert(err, IsNil)
+	data := randomData(16 * 1024)
+	e, err := legacy.WriteBLOB(bytes.NewReader(data))
+	c.Assert(err, IsNil)
+
+	// legacy BLOBs are available before the migration
+	assertBLOB(c, s.objects, e.SHA512, data)
+
+	result, err := s.objects.Migrate()
+	c.Assert(err, IsNil)
+	c.Assert(result.Migrated, DeepEquals, []string{e.SHA512})
+	c.Assert(result.BytesBefore, Equals, int64(len(data)))
+
+	hashes, err := legacy.GetBLOBs()
+	c.Assert(err, IsNil)
+	c.Assert(hashes, HasLen, 0)
+
+	hashes, err = s.objects.GetBLOBs()
+	c.Assert(err, IsNil)
+	c.Assert(hashes, DeepEquals, []string{e.SHA512})
+	assertBLOB(c, s.objects, e.SHA512, data)
+}
+
+func (s *DedupSuite) TestSweepsOrphanChunks(c *C) {
+	data := randomData(8 * 1024)
+	e, err := s.objects.WriteBLOB(bytes.NewReader(data))
+	c.Assert(err, IsNil)
+
+	orphan := randomData(1024)
+	hash, err := utils.SHA512Half(orphan)
+	c.Assert(err, IsNil)
+	err = s.objects.WriteChunk(blob.Chunk{Hash: hash, SizeBytes: int64(len(orphan))},
+		bytes.NewReader(orphan))
+	c.Assert(err, IsNil)
+
+	// recent orphan chunks might belong to a transfer in progress
+	result, err := s.objects.SweepOrphanChunks(time.Hour)
+	c.Assert(err, IsNil)
+	c.Assert(*result, DeepEquals, SweepResult{})
+
+	old := time.Now().Add(-2 * time.Hour)
+	c.Assert(os.Chtimes(s.objects.chunkPath(hash), old, old), IsNil)
+	chunks, err := s.objects.GetBLOBChunks(e.SHA512)
+	c.Assert(err, IsNil)
+	for _, chunk := range chunks {
+		c.Assert(os.Chtimes(s.objects.chunkPath(chunk.Hash), old, old), IsNil)
+	}
+
+	result, err = s.objects.SweepOrphanChunks(time.Hour)
+	c.Assert(err, IsNil)
+	c.Assert(*result, DeepEquals, SweepResult{Chunks: 1, BytesFreed: int64(len(orphan))})
+
+	exists, err := s.objects.HasChunk(hash)
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, false)
+	assertBLOB(c, s.objects, e.SHA512, data)
+}
+
+func assertBLOB(c *C, objects *Objects, hash string, expected []byte) {
+	r, err := objects.OpenBLOB(hash)
+	c.Assert(err, IsNil)
+	defer r.Close()
+	out, err := ioutil.ReadAll(r)
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(out, expected), Equals, true)
+}
+
+func randomData(size int) []byte {
+	data := make([]byte, size)
+	rand.Read(data)
+	return data
+}
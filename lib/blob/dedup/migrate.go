@@ -0,0 +1,86 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"github.com/gravitational/gravity/lib/blob"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// MigrationResult describes the outcome of the legacy BLOB migration
+type MigrationResult struct {
+	// Migrated lists hashes of the converted BLOBs
+	Migrated []string
+	// BytesBefore is the disk space taken by the converted legacy BLOBs
+	BytesBefore int64
+}
+
+// Migrate converts all legacy whole-file BLOBs into chunked BLOBs
+// and removes the originals. BLOB hashes are preserved, so package
+// metadata referencing them does not need to be updated.
+// The migration can be interrupted and resumed at any point
+func (o *Objects) Migrate() (*MigrationResult, error) {
+	hashes, err := o.legacy.GetBLOBs()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var result MigrationResult
+	for _, hash := range hashes {
+		envelope, err := o.migrateBLOB(hash)
+		if err != nil {
+			return &result, trace.Wrap(err)
+		}
+		result.Migrated = append(result.Migrated, hash)
+		result.BytesBefore += envelope.SizeBytes
+	}
+	return &result, nil
+}
+
+func (o *Objects) migrateBLOB(hash string) (*blob.Envelope, error) {
+	logger := log.WithField("blob", hash)
+	legacyEnvelope, err := o.legacy.GetBLOBEnvelope(hash)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	_, err = o.readManifest(hash)
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	if trace.IsNotFound(err) {
+		logger.Debug("Migrate legacy BLOB.")
+		r, err := o.legacy.OpenBLOB(hash)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		envelope, err := o.WriteBLOB(r)
+		r.Close()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if envelope.SHA512 != hash {
+			// the legacy BLOB is corrupted: keep it around for investigation
+			return nil, trace.BadParameter("hash mismatch for BLOB %v: got %v",
+				hash, envelope.SHA512)
+		}
+	}
+	if err := o.legacy.DeleteBLOB(hash); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return legacyEnvelope, nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"io"
+	"os"
+	"sort"
+
+	"github.com/gravitational/gravity/lib/blob"
+
+	"github.com/gravitational/trace"
+)
+
+// reader reassembles a BLOB from its chunks, opening
+// chunk files lazily as the read position advances
+type reader struct {
+	objects *Objects
+	chunks  []blob.Chunk
+	// offsets contains the offset of each chunk within the BLOB
+	offsets []int64
+	size    int64
+	// pos is the current read position
+	pos int64
+	// current is the currently open chunk file or nil
+	current *os.File
+	// index is the index of the currently open chunk
+	index int
+}
+
+func newReader(objects *Objects, m manifest) *reader {
+	offsets := make([]int64, len(m.Chunks))
+	var offset int64
+	for i, chunk := range m.Chunks {
+		offsets[i] = offset
+		offset += chunk.SizeBytes
+	}
+	return &reader{
+		objects: objects,
+		chunks:  m.Chunks,
+		offsets: offsets,
+		size:    m.SizeBytes,
+	}
+}
+
+// Read reads data from the current position
+func (r *reader) Read(p []byte) (int, error) {
+	if r.pos >= r.size {
+		return 0, io.EOF
+	}
+	if err := r.open(); err != nil {
+		return 0, trace.Wrap(err)
+	}
+	remaining := r.offsets[r.index] + r.chunks[r.index].SizeBytes - r.pos
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := r.current.Read(p)
+	r.pos += int64(n)
+	if err == io.EOF && n != 0 {
+		err = nil
+	}
+	if r.pos == r.offsets[r.index]+r.chunks[r.index].SizeBytes {
+		r.closeCurrent()
+	}
+	if err == io.EOF {
+		return n, trace.BadParameter("chunk %v is truncated", r.chunks[r.index].Hash)
+	}
+	return n, trace.ConvertSystemError(err)
+}
+
+// open makes sure the chunk covering the current position is open
+func (r *reader) open() error {
+	if r.current != nil {
+		return nil
+	}
+	index := sort.Search(len(r.offsets), func(i int) bool {
+		return r.offsets[i] > r.pos
+	}) - 1
+	f, err := os.Open(r.objects.chunkPath(r.chunks[index].Hash))
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if _, err := f.Seek(r.pos-r.offsets[index], io.SeekStart); err != nil {
+		f.Close()
+		return trace.ConvertSystemError(err)
+	}
+	r.current = f
+	r.index = index
+	return nil
+}
+
+// Seek sets the position for the next Read
+func (r *reader) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = r.pos + offset
+	case io.SeekEnd:
+		pos = r.size + offset
+	default:
+		return 0, trace.BadParameter("unsupported whence: %v", whence)
+	}
+	if pos < 0 {
+		return 0, trace.BadParameter("negative position: %v", pos)
+	}
+	if pos != r.pos {
+		r.closeCurrent()
+		r.pos = pos
+	}
+	return pos, nil
+}
+
+// Close closes the reader
+func (r *reader) Close() error {
+	r.closeCurrent()
+	return nil
+}
+
+func (r *reader) closeCurrent() {
+	if r.current != nil {
+		r.current.Close()
+		r.current = nil
+	}
+}
@@ -19,7 +19,9 @@ package autofix
 import (
 	"context"
 	"encoding/json"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/gravitational/gravity/lib/defaults"
@@ -33,26 +35,7 @@ import (
 
 // Fix takes a list of failed probes and attempts to fix some of them
 func Fix(ctx context.Context, probes []*agentpb.Probe, progress utils.Progress) (fixed, unfixed []*agentpb.Probe) {
-	// reorder the probes so "kernel module" ones go before "sysctl parameter"
-	// ones because some kernel parameters cannot be set unless a certain
-	// module is loaded, so they have to be fixed in order
-	sort.Slice(probes, func(i, j int) bool {
-		return probes[i].Checker == monitoring.KernelModuleCheckerID
-	})
-	for _, probe := range probes {
-		// we should only have gotten failed probes here but in case we got
-		// something else, skip it
-		if probe.Status != agentpb.Probe_Failed {
-			continue
-		}
-		if err := fixProbe(ctx, probe, progress); err != nil {
-			logrus.Debugf("Failed to auto-fix probe %#v: %v", *probe, err)
-			unfixed = append(unfixed, probe)
-		} else {
-			fixed = append(fixed, probe)
-		}
-	}
-	return fixed, unfixed
+	return Fixer{Progress: progress}.Fix(ctx, probes)
 }
 
 // AutoloadModules generates a systemd modules-load.d file for all kernel modules required by gravity.
@@ -76,48 +59,162 @@ func AutoloadModules(ctx context.Context, modules []monitoring.ModuleRequest, pr
 
 // GetFixable returns a list of failed probes that can be attempted to auto-fix
 func GetFixable(probes []*agentpb.Probe) (failed, fixable []*agentpb.Probe) {
+	return Fixer{}.GetFixable(probes)
+}
+
+// Fixer attempts to fix failed probes
+type Fixer struct {
+	// Progress is used to report information about fixed problems
+	utils.Progress
+	// Record optionally collects the changes made to the host
+	// so they can be reverted later
+	Record *Record
+	// CreateDirectories enables creating missing directories
+	CreateDirectories bool
+	// StopServices enables stopping and disabling conflicting services
+	// and services occupying required ports
+	StopServices bool
+}
+
+// Fix attempts to fix the provided failed probes
+func (r Fixer) Fix(ctx context.Context, probes []*agentpb.Probe) (fixed, unfixed []*agentpb.Probe) {
+	if r.Progress == nil {
+		r.Progress = utils.DiscardProgress
+	}
+	// reorder the probes so "kernel module" ones go before "sysctl parameter"
+	// ones because some kernel parameters cannot be set unless a certain
+	// module is loaded, so they have to be fixed in order
+	sort.SliceStable(probes, func(i, j int) bool {
+		return probes[i].Checker == monitoring.KernelModuleCheckerID &&
+			probes[j].Checker != monitoring.KernelModuleCheckerID
+	})
 	for _, probe := range probes {
 		// we should only have gotten failed probes here but in case we got
 		// something else, skip it
-		if probe.Status == agentpb.Probe_Failed {
-			switch probe.Checker {
-			case monitoring.KernelModuleCheckerID, monitoring.IPForwardCheckerID, monitoring.NetfilterCheckerID, monitoring.MountsCheckerID:
-				fixable = append(fixable, probe)
-			default:
-				failed = append(failed, probe)
-			}
+		if probe.Status != agentpb.Probe_Failed {
+			continue
+		}
+		if err := r.fixProbe(ctx, probe); err != nil {
+			logrus.Debugf("Failed to auto-fix probe %#v: %v", *probe, err)
+			unfixed = append(unfixed, probe)
+		} else {
+			fixed = append(fixed, probe)
+		}
+	}
+	return fixed, unfixed
+}
+
+// GetFixable returns a list of failed probes that can be attempted to auto-fix
+func (r Fixer) GetFixable(probes []*agentpb.Probe) (failed, fixable []*agentpb.Probe) {
+	for _, probe := range probes {
+		// we should only have gotten failed probes here but in case we got
+		// something else, skip it
+		if probe.Status != agentpb.Probe_Failed {
+			continue
+		}
+		if _, err := r.getFix(probe); err != nil {
+			failed = append(failed, probe)
+		} else {
+			fixable = append(fixable, probe)
 		}
 	}
 	return failed, fixable
 }
 
+// Describe returns the description of the fix for the specified probe
+func (r Fixer) Describe(probe *agentpb.Probe) (string, error) {
+	fix, err := r.getFix(probe)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return fix.String(), nil
+}
+
 // fixProbe attempts to fix the provided failed probe
-func fixProbe(ctx context.Context, probe *agentpb.Probe, progress utils.Progress) error {
+func (r Fixer) fixProbe(ctx context.Context, probe *agentpb.Probe) error {
+	fix, err := r.getFix(probe)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	changes, err := fix.apply(ctx, r.Progress)
+	if r.Record != nil {
+		// record the partial changes as well
+		r.Record.Changes = append(r.Record.Changes, changes...)
+	}
+	return trace.Wrap(err)
+}
+
+// getFix returns the fix for the provided failed probe
+func (r Fixer) getFix(probe *agentpb.Probe) (fix, error) {
 	switch probe.Checker {
 	case monitoring.KernelModuleCheckerID:
 		var data monitoring.KernelModuleCheckerData
 		if err := json.Unmarshal(probe.CheckerData, &data); err != nil {
-			return trace.Wrap(err)
+			return nil, trace.Wrap(err)
 		}
 		if data.Module.Name == "" {
-			return trace.BadParameter("empty probe data: %#v", data)
-		}
-		if err := modprobe(ctx, data.Module.Name, data.Module.Names, progress); err != nil {
-			return trace.Wrap(err)
+			return nil, trace.BadParameter("empty probe data: %#v", data)
 		}
+		return moduleFix{name: data.Module.Name, altNames: data.Module.Names}, nil
 	case monitoring.IPForwardCheckerID, monitoring.NetfilterCheckerID, monitoring.MountsCheckerID:
 		var data monitoring.SysctlCheckerData
 		if err := json.Unmarshal(probe.CheckerData, &data); err != nil {
-			return trace.Wrap(err)
+			return nil, trace.Wrap(err)
 		}
 		if data.ParameterName == "" || data.ParameterValue == "" {
-			return trace.BadParameter("empty probe data: %#v", data)
+			return nil, trace.BadParameter("empty probe data: %#v", data)
+		}
+		return sysctlFix{name: data.ParameterName, value: data.ParameterValue}, nil
+	}
+	switch {
+	case probe.Checker == processCheckerID && r.StopServices:
+		match := conflictingProgramsRegexp.FindStringSubmatch(probe.Detail)
+		if len(match) != 2 || len(strings.Fields(match[1])) == 0 {
+			return nil, trace.BadParameter("unexpected probe detail: %q", probe.Detail)
 		}
-		if err := setSysctlParameter(ctx, data.ParameterName, data.ParameterValue, progress); err != nil {
-			return trace.Wrap(err)
+		return serviceFix{programs: strings.Fields(match[1])}, nil
+	case probe.Checker == portCheckerID && r.StopServices:
+		match := occupiedPortRegexp.FindStringSubmatch(probe.Detail)
+		if len(match) != 4 {
+			return nil, trace.BadParameter("unexpected probe detail: %q", probe.Detail)
 		}
-	default:
-		return trace.NotImplemented("probe %v can't be auto-fixed", probe.Checker)
+		pid, err := strconv.Atoi(match[2])
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return portFix{program: match[1], pid: pid, port: match[3]}, nil
+	case strings.HasPrefix(probe.Checker, storageCheckerPrefix) && r.CreateDirectories:
+		match := missingPathRegexp.FindStringSubmatch(probe.Error)
+		if len(match) != 2 {
+			return nil, trace.BadParameter("unexpected probe error: %q", probe.Error)
+		}
+		return directoryFix{path: match[1]}, nil
 	}
-	return nil
+	return nil, trace.NotImplemented("probe %v can't be auto-fixed", probe.Checker)
+}
+
+// fix is a remedy for a failed probe
+type fix interface {
+	// String describes the fix
+	String() string
+	// apply fixes the problem and returns the changes made to the host
+	apply(ctx context.Context, progress utils.Progress) ([]Change, error)
 }
+
+var (
+	// conflictingProgramsRegexp matches the detail of the failed process checker probe
+	conflictingProgramsRegexp = regexp.MustCompile(`conflicting programs running: \[([^\]]*)\]`)
+	// occupiedPortRegexp matches the detail of the failed port checker probe
+	occupiedPortRegexp = regexp.MustCompile(`conflicting program "([^"]*)"\(pid=(\d+)\) is occupying port (\S+?)\(`)
+	// missingPathRegexp matches the error of the failed storage checker probe
+	missingPathRegexp = regexp.MustCompile(`^(/\S*) does not exist$`)
+)
+
+const (
+	// processCheckerID is the name of the satellite conflicting process checker
+	processCheckerID = "process-checker"
+	// portCheckerID is the name of the satellite port checker
+	portCheckerID = "port-checker"
+	// storageCheckerPrefix is the name prefix of the satellite storage checkers
+	storageCheckerPrefix = "io-check("
+)
@@ -0,0 +1,158 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autofix
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/satellite/agent/proto/agentpb"
+	"gopkg.in/check.v1"
+)
+
+func TestAutofix(t *testing.T) { check.TestingT(t) }
+
+type AutofixSuite struct{}
+
+var _ = check.Suite(&AutofixSuite{})
+
+func (s *AutofixSuite) TestClassifiesProbes(c *check.C) {
+	probes := []*agentpb.Probe{
+		{
+			Checker: processCheckerID,
+			Detail:  "potentially conflicting programs running: [dnsmasq named], note this is an issue only before Telekube is installed",
+			Status:  agentpb.Probe_Failed,
+		},
+		{
+			Checker: portCheckerID,
+			Detail:  `conflicting program "etcd"(pid=1234) is occupying port tcp/2379(LISTEN)`,
+			Status:  agentpb.Probe_Failed,
+		},
+		{
+			Checker: "io-check(/var/lib/data)",
+			Error:   "/var/lib/data does not exist",
+			Status:  agentpb.Probe_Failed,
+		},
+		{
+			Checker: "disk-space",
+			Status:  agentpb.Probe_Failed,
+		},
+	}
+	failed, fixable := Fixer{}.GetFixable(probes)
+	c.Assert(failed, check.DeepEquals, probes)
+	c.Assert(fixable, check.HasLen, 0)
+
+	failed, fixable = Fixer{CreateDirectories: true}.GetFixable(probes)
+	c.Assert(failed, check.DeepEquals, append(probes[:2:2], probes[3:]...))
+	c.Assert(fixable, check.DeepEquals, probes[2:3])
+
+	fixer := Fixer{CreateDirectories: true, StopServices: true}
+	failed, fixable = fixer.GetFixable(probes)
+	c.Assert(failed, check.DeepEquals, probes[3:])
+	c.Assert(fixable, check.DeepEquals, probes[:3])
+
+	var descriptions []string
+	for _, probe := range fixable {
+		description, err := fixer.Describe(probe)
+		c.Assert(err, check.IsNil)
+		descriptions = append(descriptions, description)
+	}
+	c.Assert(descriptions, check.DeepEquals, []string{
+		"stop and disable conflicting services dnsmasq, named",
+		`stop and disable the service of "etcd" (pid=1234) occupying port tcp/2379`,
+		"create directory /var/lib/data",
+	})
+}
+
+func (s *AutofixSuite) TestParsesUnitFromCgroup(c *check.C) {
+	unit, err := parseUnitFromCgroup(strings.NewReader(`11:memory:/system.slice/etcd.service
+1:name=systemd:/system.slice/etcd.service
+`), 1234)
+	c.Assert(err, check.IsNil)
+	c.Assert(unit, check.Equals, "etcd.service")
+
+	_, err = parseUnitFromCgroup(strings.NewReader("1:name=systemd:/user.slice/session-1.scope\n"), 1234)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *AutofixSuite) TestFixesAndRevertsDirectory(c *check.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "a", "b")
+	record := &Record{}
+	fixer := Fixer{Record: record, CreateDirectories: true}
+	probe := &agentpb.Probe{
+		Checker: "io-check(" + path + ")",
+		Error:   path + " does not exist",
+		Status:  agentpb.Probe_Failed,
+	}
+	fixed, unfixed := fixer.Fix(context.TODO(), []*agentpb.Probe{probe})
+	c.Assert(fixed, check.HasLen, 1)
+	c.Assert(unfixed, check.HasLen, 0)
+	c.Assert(record.Changes, check.DeepEquals, []Change{
+		{Kind: ChangeDirectory, Name: filepath.Join(dir, "a")},
+		{Kind: ChangeDirectory, Name: path},
+	})
+	_, err := os.Stat(path)
+	c.Assert(err, check.IsNil)
+
+	recordPath := filepath.Join(dir, "record", "autofix.json")
+	c.Assert(WriteRecord(recordPath, *record), check.IsNil)
+	read, err := ReadRecord(recordPath)
+	c.Assert(err, check.IsNil)
+	c.Assert(read, check.DeepEquals, record)
+
+	remaining, err := Revert(context.TODO(), *read, utils.DiscardProgress)
+	c.Assert(err, check.IsNil)
+	c.Assert(remaining.Changes, check.HasLen, 0)
+	_, err = os.Stat(filepath.Join(dir, "a"))
+	c.Assert(os.IsNotExist(err), check.Equals, true)
+
+	c.Assert(WriteRecord(recordPath, *remaining), check.IsNil)
+	read, err = ReadRecord(recordPath)
+	c.Assert(err, check.IsNil)
+	c.Assert(read.Changes, check.HasLen, 0)
+}
+
+func (s *AutofixSuite) TestDetectsModulesInUse(c *check.C) {
+	dir := c.MkDir()
+	defer func(path string) { sysModulePath = path }(sysModulePath)
+	sysModulePath = dir
+	c.Assert(os.MkdirAll(filepath.Join(dir, "br_netfilter"), defaults.SharedDirMask), check.IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "br_netfilter", "refcnt"), []byte("0\n"), defaults.SharedReadMask), check.IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(dir, "overlay"), defaults.SharedDirMask), check.IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "overlay", "refcnt"), []byte("3\n"), defaults.SharedReadMask), check.IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(dir, "ip_tables"), defaults.SharedDirMask), check.IsNil)
+
+	for module, inUse := range map[string]bool{
+		"br_netfilter": false,
+		"br-netfilter": false,
+		"overlay":      true,
+		"ip_tables":    true,
+		"ebtables":     false,
+	} {
+		result, err := moduleInUse(module)
+		c.Assert(err, check.IsNil)
+		c.Assert(result, check.Equals, inUse, check.Commentf(module))
+	}
+}
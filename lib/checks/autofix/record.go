@@ -0,0 +1,218 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autofix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// Record lists the changes made to the host by the fixes
+type Record struct {
+	// Changes lists the changes in the order they were made
+	Changes []Change `json:"changes"`
+}
+
+// Change describes a single change made to the host
+type Change struct {
+	// Kind is the kind of the change
+	Kind string `json:"kind"`
+	// Name identifies the changed object: kernel module, kernel parameter,
+	// systemd service or directory
+	Name string `json:"name"`
+	// Value is the new value of the kernel parameter
+	Value string `json:"value,omitempty"`
+	// PreviousValue is the value of the kernel parameter before the change
+	PreviousValue string `json:"previous_value,omitempty"`
+	// ConfigFile is the file the kernel parameter has been persisted to
+	ConfigFile string `json:"config_file,omitempty"`
+	// Enabled specifies whether the stopped service was enabled
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// String returns a textual representation of this change
+func (r Change) String() string {
+	switch r.Kind {
+	case ChangeKernelModule:
+		return fmt.Sprintf("loaded kernel module %v", r.Name)
+	case ChangeSysctl:
+		return fmt.Sprintf("set kernel parameter %v=%v (was %v)", r.Name, r.Value, r.PreviousValue)
+	case ChangeService:
+		return fmt.Sprintf("stopped service %v", r.Name)
+	case ChangeDirectory:
+		return fmt.Sprintf("created directory %v", r.Name)
+	}
+	return fmt.Sprintf("%v %v", r.Kind, r.Name)
+}
+
+// ReadRecord reads the record of changes from the file at the specified path.
+// Returns an empty record if the file does not exist
+func ReadRecord(path string) (*Record, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Record{}, nil
+		}
+		return nil, trace.ConvertSystemError(err)
+	}
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, trace.Wrap(err, "failed to parse %v", path)
+	}
+	return &record, nil
+}
+
+// WriteRecord writes the record of changes to the file at the specified path.
+// The file is removed if the record is empty
+func WriteRecord(path string, record Record) error {
+	if len(record.Changes) == 0 {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return trace.ConvertSystemError(err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), defaults.SharedDirMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.ConvertSystemError(ioutil.WriteFile(path, data, defaults.SharedReadMask))
+}
+
+// Revert reverts the changes in the record in the reverse order.
+// Returns the record with the changes that could not be reverted
+func Revert(ctx context.Context, record Record, progress utils.Progress) (*Record, error) {
+	var failed []Change
+	var errors []error
+	for i := len(record.Changes) - 1; i >= 0; i-- {
+		change := record.Changes[i]
+		if err := revert(ctx, change, progress); err != nil {
+			errors = append(errors, trace.Wrap(err, "failed to revert: %v", change))
+			failed = append([]Change{change}, failed...)
+			continue
+		}
+		progress.PrintInfo("Reverted: %v", change)
+	}
+	return &Record{Changes: failed}, trace.NewAggregate(errors...)
+}
+
+func revert(ctx context.Context, change Change, progress utils.Progress) error {
+	switch change.Kind {
+	case ChangeKernelModule:
+		inUse, err := moduleInUse(change.Name)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if inUse {
+			progress.PrintInfo("Kernel module %v is in use, leaving it loaded", change.Name)
+			return nil
+		}
+		out, err := utils.RunCommand(ctx, nil, "modprobe", "-r", change.Name)
+		if err != nil {
+			return trace.Wrap(err, "failed to unload kernel module %v: %s", change.Name, out)
+		}
+	case ChangeSysctl:
+		if change.PreviousValue != "" {
+			param := fmt.Sprintf("%v=%v", change.Name, change.PreviousValue)
+			out, err := utils.RunCommand(ctx, nil, "sysctl", "-w", param)
+			if err != nil {
+				return trace.Wrap(err, "failed to set kernel parameter %v: %s", param, out)
+			}
+		}
+		if change.ConfigFile != "" {
+			err := utils.RemoveLineFromFile(change.ConfigFile,
+				fmt.Sprintf("%v=%v", change.Name, change.Value))
+			if err != nil && !trace.IsNotFound(err) {
+				return trace.Wrap(err)
+			}
+		}
+	case ChangeService:
+		if change.Enabled {
+			out, err := utils.RunCommand(ctx, nil, "systemctl", "enable", change.Name)
+			if err != nil {
+				return trace.Wrap(err, "failed to enable %v: %s", change.Name, out)
+			}
+		}
+		out, err := utils.RunCommand(ctx, nil, "systemctl", "start", change.Name)
+		if err != nil {
+			return trace.Wrap(err, "failed to start %v: %s", change.Name, out)
+		}
+	case ChangeDirectory:
+		// only remove the directory if it is still empty
+		if err := os.Remove(change.Name); err != nil && !os.IsNotExist(err) {
+			return trace.ConvertSystemError(err)
+		}
+	default:
+		return trace.BadParameter("unknown change kind %q", change.Kind)
+	}
+	return nil
+}
+
+// moduleInUse returns true if the specified kernel module is used
+// by other modules or processes, or is built into the kernel and
+// cannot be unloaded
+func moduleInUse(name string) (bool, error) {
+	dir := filepath.Join(sysModulePath, strings.Replace(name, "-", "_", -1))
+	if _, err := os.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			// the module is not loaded
+			return false, nil
+		}
+		return false, trace.ConvertSystemError(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "refcnt"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			// builtin modules do not have a reference counter
+			return true, nil
+		}
+		return false, trace.ConvertSystemError(err)
+	}
+	refs, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	return refs != 0, nil
+}
+
+// sysModulePath is the directory with the loaded kernel modules
+var sysModulePath = "/sys/module"
+
+const (
+	// ChangeKernelModule is the change that loaded a kernel module
+	ChangeKernelModule = "kernel-module"
+	// ChangeSysctl is the change that set a kernel parameter
+	ChangeSysctl = "sysctl"
+	// ChangeService is the change that stopped a systemd service
+	ChangeService = "service"
+	// ChangeDirectory is the change that created a directory
+	ChangeDirectory = "directory"
+)
@@ -17,8 +17,13 @@ limitations under the License.
 package autofix
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/utils"
@@ -26,31 +31,232 @@ import (
 	"github.com/gravitational/trace"
 )
 
+// String describes the fix.
+// Implements fix
+func (r moduleFix) String() string {
+	return fmt.Sprintf("load kernel module %v", r.name)
+}
+
+// apply loads the kernel module.
+// Implements fix
+func (r moduleFix) apply(ctx context.Context, progress utils.Progress) ([]Change, error) {
+	name, err := modprobe(ctx, r.name, r.altNames, progress)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return []Change{{Kind: ChangeKernelModule, Name: name}}, nil
+}
+
+// moduleFix loads a missing kernel module
+type moduleFix struct {
+	name     string
+	altNames []string
+}
+
+// String describes the fix.
+// Implements fix
+func (r sysctlFix) String() string {
+	return fmt.Sprintf("set kernel parameter %v=%v", r.name, r.value)
+}
+
+// apply sets the kernel parameter.
+// Implements fix
+func (r sysctlFix) apply(ctx context.Context, progress utils.Progress) ([]Change, error) {
+	previous, err := utils.RunCommand(ctx, nil, "sysctl", "-n", r.name)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to query kernel parameter %v: %s", r.name, previous)
+	}
+	persisted, err := setSysctlParameter(ctx, r.name, r.value, progress)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	change := Change{
+		Kind:          ChangeSysctl,
+		Name:          r.name,
+		Value:         r.value,
+		PreviousValue: strings.TrimSpace(string(previous)),
+	}
+	if persisted {
+		change.ConfigFile = defaults.SysctlPath
+	}
+	return []Change{change}, nil
+}
+
+// sysctlFix sets a kernel parameter to the required value
+type sysctlFix struct {
+	name  string
+	value string
+}
+
+// String describes the fix.
+// Implements fix
+func (r serviceFix) String() string {
+	return fmt.Sprintf("stop and disable conflicting services %v", strings.Join(r.programs, ", "))
+}
+
+// apply stops the services of the conflicting programs.
+// Implements fix
+func (r serviceFix) apply(ctx context.Context, progress utils.Progress) (changes []Change, err error) {
+	for _, program := range r.programs {
+		change, err := stopService(ctx, fmt.Sprintf("%v.service", program), progress)
+		if err != nil {
+			return changes, trace.Wrap(err)
+		}
+		changes = append(changes, *change)
+	}
+	return changes, nil
+}
+
+// serviceFix stops the services of the conflicting programs
+type serviceFix struct {
+	programs []string
+}
+
+// String describes the fix.
+// Implements fix
+func (r portFix) String() string {
+	return fmt.Sprintf("stop and disable the service of %q (pid=%v) occupying port %v",
+		r.program, r.pid, r.port)
+}
+
+// apply stops the service of the program occupying the port.
+// Implements fix
+func (r portFix) apply(ctx context.Context, progress utils.Progress) ([]Change, error) {
+	unit, err := unitForPID(r.pid)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	change, err := stopService(ctx, unit, progress)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return []Change{*change}, nil
+}
+
+// portFix stops the service of the program occupying a required port
+type portFix struct {
+	program string
+	pid     int
+	port    string
+}
+
+// String describes the fix.
+// Implements fix
+func (r directoryFix) String() string {
+	return fmt.Sprintf("create directory %v", r.path)
+}
+
+// apply creates the missing directory and its missing parents.
+// Implements fix
+func (r directoryFix) apply(ctx context.Context, progress utils.Progress) (changes []Change, err error) {
+	var missing []string
+	for dir := filepath.Clean(r.path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return nil, trace.ConvertSystemError(err)
+		}
+		missing = append([]string{dir}, missing...)
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	for _, dir := range missing {
+		if err := os.Mkdir(dir, defaults.SharedDirMask); err != nil {
+			return changes, trace.ConvertSystemError(err)
+		}
+		changes = append(changes, Change{Kind: ChangeDirectory, Name: dir})
+	}
+	progress.PrintInfo("Auto-created directory: %v", r.path)
+	return changes, nil
+}
+
+// directoryFix creates a missing directory
+type directoryFix struct {
+	path string
+}
+
 // modprobe loads a kernel module by the provided name or, if that fails, by
-// trying provided alternative names
-func modprobe(ctx context.Context, name string, altNames []string, progress utils.Progress) error {
+// trying provided alternative names.
+// Returns the name of the loaded module
+func modprobe(ctx context.Context, name string, altNames []string, progress utils.Progress) (string, error) {
 	var errors []string
 	for _, n := range append([]string{name}, altNames...) {
 		out, err := utils.RunCommand(ctx, nil, "modprobe", n)
 		if err == nil {
 			progress.PrintInfo("Auto-loaded kernel module: %v", n)
-			return nil
+			return n, nil
 		}
 		errors = append(errors, string(out))
 	}
-	return trace.BadParameter("failed to enable kernel module %v(%v): %s", name, altNames, errors)
+	return "", trace.BadParameter("failed to enable kernel module %v(%v): %s", name, altNames, errors)
 }
 
 // setSysctlParameter sets the specified kernel parameter and makes sure it
-// persists across reboots
-func setSysctlParameter(ctx context.Context, name, value string, progress utils.Progress) error {
+// persists across reboots.
+// Returns true if the parameter has been added to the persistent configuration
+func setSysctlParameter(ctx context.Context, name, value string, progress utils.Progress) (persisted bool, err error) {
 	out, err := utils.RunCommand(ctx, nil, "sysctl", "-w", fmt.Sprintf("%v=%v", name, value))
 	if err != nil {
-		return trace.Wrap(err, "failed to set kernel parameter %v=%v: %s", name, value, out)
+		return false, trace.Wrap(err, "failed to set kernel parameter %v=%v: %s", name, value, out)
 	}
 	progress.PrintInfo("Auto-set kernel parameter: %v=%v", name, value)
-	if err := utils.EnsureLineInFile(defaults.SysctlPath, fmt.Sprintf("%v=%v", name, value)); err != nil && !trace.IsAlreadyExists(err) {
+	err = utils.EnsureLineInFile(defaults.SysctlPath, fmt.Sprintf("%v=%v", name, value))
+	if err != nil && !trace.IsAlreadyExists(err) {
 		progress.PrintWarn(err, "Could not set up kernel parameter %v=%v to persist across reboots", name, value)
 	}
-	return nil
+	return err == nil, nil
+}
+
+// stopService stops and disables the specified active systemd service
+func stopService(ctx context.Context, unit string, progress utils.Progress) (*Change, error) {
+	if _, err := utils.RunCommand(ctx, nil, "systemctl", "is-active", "--quiet", unit); err != nil {
+		return nil, trace.NotFound("%v is not an active systemd service", unit)
+	}
+	_, err := utils.RunCommand(ctx, nil, "systemctl", "is-enabled", "--quiet", unit)
+	enabled := err == nil
+	if out, err := utils.RunCommand(ctx, nil, "systemctl", "stop", unit); err != nil {
+		return nil, trace.Wrap(err, "failed to stop %v: %s", unit, out)
+	}
+	change := &Change{Kind: ChangeService, Name: unit, Enabled: enabled}
+	if enabled {
+		if out, err := utils.RunCommand(ctx, nil, "systemctl", "disable", unit); err != nil {
+			return change, trace.Wrap(err, "failed to disable %v: %s", unit, out)
+		}
+	}
+	progress.PrintInfo("Auto-stopped service: %v", unit)
+	return change, nil
+}
+
+// unitForPID returns the name of the systemd service the process with the
+// specified PID belongs to
+func unitForPID(pid int) (string, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%v/cgroup", pid))
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	return parseUnitFromCgroup(f, pid)
+}
+
+// parseUnitFromCgroup returns the name of the systemd service from the
+// contents of /proc/<pid>/cgroup
+func parseUnitFromCgroup(f io.Reader, pid int) (string, error) {
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Lines are formatted as <id>:<controllers>:<path>, e.g.
+		// 1:name=systemd:/system.slice/dnsmasq.service
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		unit := filepath.Base(parts[2])
+		if strings.HasSuffix(unit, ".service") {
+			return unit, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", trace.Wrap(err)
+	}
+	return "", trace.NotFound("process %v does not belong to a systemd service", pid)
 }
@@ -67,39 +67,46 @@ func ValidateManifest(
 	dockerConfig storage.DockerConfig,
 	stateDir string,
 ) (failedProbes []*agentpb.Probe, err error) {
+	var probes health.Probes
+	err = CheckManifest(manifest, profile, dockerConfig, stateDir, &probes)
+	return probes.GetFailed(), trace.Wrap(err)
+}
+
+// CheckManifest verifies the specified manifest against the host environment
+// and reports all health probes to reporter
+func CheckManifest(
+	manifest schema.Manifest,
+	profile schema.NodeProfile,
+	dockerConfig storage.DockerConfig,
+	stateDir string,
+	reporter health.Reporter,
+) error {
 	var errors []error
-	failed, err := schema.ValidateRequirements(profile.Requirements, stateDir)
+	err := schema.CheckRequirements(profile.Requirements, stateDir, reporter)
 	if err != nil {
 		errors = append(errors, trace.Wrap(err,
 			"error validating profile requirements, see syslog for details"))
 	}
-	failedProbes = append(failedProbes, failed...)
 
 	dockerSchema := schema.Docker{StorageDriver: dockerConfig.StorageDriver}
-	failed, err = schema.ValidateDocker(dockerSchema, stateDir)
-	if err != nil {
-		errors = append(errors, trace.Wrap(err,
-			"error validating docker requirements, see syslog for details"))
-	}
-	failedProbes = append(failedProbes, failed...)
-
-	failedProbes = append(failedProbes, schema.ValidateKubelet(profile, manifest)...)
-	return failedProbes, trace.NewAggregate(errors...)
+	schema.CheckDocker(dockerSchema, stateDir, reporter)
+	schema.CheckKubelet(profile, manifest, reporter)
+	CheckCustom(context.TODO(), profile.Requirements.CustomChecks, reporter)
+	return trace.NewAggregate(errors...)
 }
 
 // RunBasicChecks executes a set of additional health checks.
 // Returns list of failed health probes.
 func RunBasicChecks(ctx context.Context, options *validationpb.ValidateOptions) (failed []*agentpb.Probe) {
 	var reporter health.Probes
-	basicCheckers(options).Check(ctx, &reporter)
-
-	for _, p := range reporter {
-		if p.Status == agentpb.Probe_Failed {
-			failed = append(failed, p)
-		}
-	}
+	CheckBasic(ctx, options, &reporter)
+	return reporter.GetFailed()
+}
 
-	return failed
+// CheckBasic executes a set of additional health checks
+// and reports all health probes to reporter
+func CheckBasic(ctx context.Context, options *validationpb.ValidateOptions, reporter health.Reporter) {
+	basicCheckers(options).Check(ctx, reporter)
 }
 
 // LocalChecksRequest describes a request to run local pre-flight checks
@@ -137,6 +144,10 @@ type LocalChecksResult struct {
 	Fixed []*agentpb.Probe
 	// Fixable is a list of probes that can be attempted to auto-fix
 	Fixable []*agentpb.Probe
+	// Passed is a list of probes that have succeeded
+	Passed []*agentpb.Probe
+	// Warnings is a list of failed probes that do not fail the validation
+	Warnings []*agentpb.Probe
 }
 
 // GetFailed returns a list of all failed probes
@@ -170,29 +181,36 @@ func ValidateLocal(ctx context.Context, req LocalChecksRequest) (*LocalChecksRes
 
 	dockerConfig := DockerConfigFromSchemaValue(req.Manifest.SystemDocker())
 	OverrideDockerConfig(&dockerConfig, req.Docker)
-	failedProbes, err := ValidateManifest(req.Manifest, *profile, dockerConfig, stateDir)
+	var probes health.Probes
+	err = CheckManifest(req.Manifest, *profile, dockerConfig, stateDir, &probes)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
-	failedProbes = append(failedProbes, RunBasicChecks(ctx, req.Options)...)
+	CheckBasic(ctx, req.Options, &probes)
+	passed := passedProbes(probes)
+	failedProbes, warnings := SplitWarnings(probes.GetFailed())
 	if len(failedProbes) == 0 {
-		return &LocalChecksResult{}, nil
+		return &LocalChecksResult{Passed: passed, Warnings: warnings}, nil
 	}
 
 	if !req.AutoFix {
 		failed, fixable := autofix.GetFixable(failedProbes)
 		return &LocalChecksResult{
-			Failed:  failed,
-			Fixable: fixable,
+			Failed:   failed,
+			Fixable:  fixable,
+			Passed:   passed,
+			Warnings: warnings,
 		}, nil
 	}
 
 	// try to auto-fix some of the issues
 	fixed, unfixed := autofix.Fix(ctx, failedProbes, req.Progress)
 	return &LocalChecksResult{
-		Failed: unfixed,
-		Fixed:  fixed,
+		Failed:   unfixed,
+		Fixed:    fixed,
+		Passed:   passed,
+		Warnings: warnings,
 	}, nil
 }
 
@@ -207,6 +225,9 @@ func RunLocalChecks(ctx context.Context, req LocalChecksRequest) error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	for _, probe := range result.Warnings {
+		req.Progress.PrintSubWarn(formatProbe(*probe))
+	}
 	if len(result.GetFailed()) != 0 {
 		return trace.BadParameter(fmt.Sprintf("The following pre-flight checks failed:\n%v",
 			FormatFailedChecks(result.GetFailed())))
@@ -221,7 +242,11 @@ func FormatFailedChecks(failed []*agentpb.Probe) string {
 	}
 	var buf bytes.Buffer
 	for _, p := range failed {
-		fmt.Fprintf(&buf, "\t[%v] %s\n", constants.FailureMark, formatProbe(*p))
+		mark := constants.FailureMark
+		if p.Severity == agentpb.Probe_Warning {
+			mark = constants.WarnMark
+		}
+		fmt.Fprintf(&buf, "\t[%v] %s\n", mark, formatProbe(*p))
 	}
 	return buf.String()
 }
@@ -256,6 +281,9 @@ func DockerConfigFromSchemaValue(dockerSchema schema.Docker) (config storage.Doc
 type Checker interface {
 	// Run runs a full set of checks on the nodes configured in the checker.
 	Run(ctx context.Context) error
+	// Check runs a full set of checks on the nodes configured in the checker
+	// and returns the results of both failed and passed checks.
+	Check(ctx context.Context) Results
 	// CheckNode executes single-node checks (such as CPU/RAM requirements,
 	// disk space, etc) for the provided server.
 	CheckNode(ctx context.Context, server Server) []*agentpb.Probe
@@ -325,32 +353,45 @@ type Server struct {
 
 // Run runs a full set of checks on the servers specified in r.servers
 func (r *checker) Run(ctx context.Context) error {
+	results := r.Check(ctx)
+	if warnings := results.Warnings(); len(warnings) != 0 {
+		log.Warnf("The following checks produced warnings:\n%v", warnings.Format())
+	}
+	return trace.Wrap(results.Error())
+}
+
+// Check runs a full set of checks on the servers specified in r.servers
+// and returns the results of both failed and passed checks
+func (r *checker) Check(ctx context.Context) (results Results) {
 	if ifTestsDisabled() {
 		log.Infof("Skipping checks due to %q set.",
 			constants.PreflightChecksOffEnvVar)
 		return nil
 	}
 
-	var failed []*agentpb.Probe
-
 	// check each server against its profile
 	for _, server := range r.Servers {
-		failed = append(failed, r.CheckNode(ctx, server)...)
+		results = append(results, NewResults(server.String(), r.checkNode(ctx, server))...)
 	}
 
 	// run checks that take all servers into account
-	failed = append(failed, r.CheckNodes(ctx, r.Servers)...)
-
-	if len(failed) != 0 {
-		return trace.BadParameter("The following checks failed:\n%v",
-			FormatFailedChecks(failed))
-	}
-
-	return nil
+	results = append(results, NewResults("", r.checkNodes(ctx, r.Servers))...)
+	return results
 }
 
 // CheckNode executes checks for the provided individual server.
 func (r *checker) CheckNode(ctx context.Context, server Server) (failed []*agentpb.Probe) {
+	return failedProbes(r.checkNode(ctx, server))
+}
+
+// CheckNodes executes checks that take all provided servers into account.
+func (r *checker) CheckNodes(ctx context.Context, servers []Server) (failed []*agentpb.Probe) {
+	return failedProbes(r.checkNodes(ctx, servers))
+}
+
+// checkNode executes checks for the provided individual server
+// and returns the probes of both failed and passed checks
+func (r *checker) checkNode(ctx context.Context, server Server) (probes []*agentpb.Probe) {
 	if ifTestsDisabled() {
 		log.Infof("Skipping single-node checks due to %q set.",
 			constants.PreflightChecksOffEnvVar)
@@ -368,75 +409,58 @@ func (r *checker) CheckNode(ctx context.Context, server Server) (failed []*agent
 	})
 	if err != nil {
 		log.WithError(err).Warn("Failed to validate remote node.")
-		failed = append(failed, &agentpb.Probe{
-			Detail: err.Error(),
-			Error:  fmt.Sprintf("failed to validate node %v", server),
-		})
+		err = trace.Wrap(err, "failed to validate node %v", server)
+	}
+	probes = append(probes, failed...)
+	if err != nil || len(failed) == 0 {
+		// the remote node reports only the failed probes
+		probes = append(probes, newProbe(nodeCheckerID, err, fmt.Sprintf("failed to validate node %v", server)))
 	}
 
 	err = checkServerProfile(server, requirements)
 	if err != nil {
 		log.WithError(err).Warn("Failed to validate profile requirements.")
-		failed = append(failed, &agentpb.Probe{
-			Detail: err.Error(),
-			Error:  "failed to validate profile requirements",
-		})
 	}
+	probes = append(probes, newProbe(profileCheckerID, err, "failed to validate profile requirements"))
 
 	dockerConfig := r.Manifest.SystemDocker()
 	if r.TestDockerDevice {
 		err = checkDockerDevice(server, dockerConfig)
 		if err != nil {
 			log.WithError(err).Warn("Failed to validate docker device.")
-			failed = append(failed, &agentpb.Probe{
-				Detail: err.Error(),
-				Error:  "failed to validate docker device",
-			})
 		}
+		probes = append(probes, newProbe(dockerDeviceCheckerID, err, "failed to validate docker device"))
 	}
 
 	err = checkSystemPackages(server, dockerConfig)
 	if err != nil {
 		log.WithError(err).Warn("Failed to validate system packages.")
-		failed = append(failed, &agentpb.Probe{
-			Detail: err.Error(),
-			Error:  "failed to validate system packages",
-		})
 	}
+	probes = append(probes, newProbe(systemPackagesCheckerID, err, "failed to validate system packages"))
 
 	err = r.checkTempDir(ctx, server)
 	if err != nil {
 		log.WithError(err).Warn("Failed to validate temporary directory.")
-		failed = append(failed, &agentpb.Probe{
-			Detail: err.Error(),
-			Error:  "failed to validate temporary directory",
-		})
 	}
+	probes = append(probes, newProbe(tempDirCheckerID, err, "failed to validate temporary directory"))
 
 	if server.IsMaster() && r.TestEtcdDisk {
 		err = r.checkEtcdDisk(ctx, server)
-		if err != nil {
-			failed = append(failed, &agentpb.Probe{
-				Detail: err.Error(),
-				Error:  "failed to validate etcd disk requirements",
-			})
-		}
+		probes = append(probes, newProbe(etcdDiskCheckerID, err, "failed to validate etcd disk requirements"))
 	}
 
 	err = r.checkDisks(ctx, server)
 	if err != nil {
 		log.WithError(err).Warn("Failed to validate disk requirements.")
-		failed = append(failed, &agentpb.Probe{
-			Detail: err.Error(),
-			Error:  "failed to validate disk requirements",
-		})
 	}
+	probes = append(probes, newProbe(diskCheckerID, err, "failed to validate disk requirements"))
 
-	return failed
+	return probes
 }
 
-// CheckNodes executes checks that take all provided servers into account.
-func (r *checker) CheckNodes(ctx context.Context, servers []Server) (failed []*agentpb.Probe) {
+// checkNodes executes checks that take all provided servers into account
+// and returns the probes of both failed and passed checks
+func (r *checker) checkNodes(ctx context.Context, servers []Server) (probes []*agentpb.Probe) {
 	if ifTestsDisabled() {
 		log.Infof("Skipping multi-node checks due to %q set.",
 			constants.PreflightChecksOffEnvVar)
@@ -446,46 +470,95 @@ func (r *checker) CheckNodes(ctx context.Context, servers []Server) (failed []*a
 	err := checkSameOS(servers)
 	if err != nil {
 		log.WithError(err).Warn("Failed to validate same OS requirements.")
-		failed = append(failed, &agentpb.Probe{
-			Detail: err.Error(),
-			Error:  "failed to validate same OS requirement",
-		})
 	}
+	probes = append(probes, newProbe(sameOSCheckerID, err, "failed to validate same OS requirement"))
 
 	err = checkTime(time.Now().UTC(), servers)
 	if err != nil {
 		log.WithError(err).Warn("Failed to validate time drift requirements.")
-		failed = append(failed, &agentpb.Probe{
-			Detail: err.Error(),
-			Error:  "failed to validate time drift requirement",
-		})
 	}
+	probes = append(probes, newProbe(timeDriftCheckerID, err, "failed to validate time drift requirement"))
 
 	if r.TestPorts {
 		err = r.checkPorts(ctx, servers)
 		if err != nil {
 			log.WithError(err).Warn("Failed to validate port requirements.")
-			failed = append(failed, &agentpb.Probe{
-				Detail: err.Error(),
-				Error:  "failed to validate port requirements",
-			})
 		}
+		probes = append(probes, newProbe(portsCheckerID, err, "failed to validate port requirements"))
 	}
 
 	if r.TestBandwidth {
 		err = r.checkBandwidth(ctx, servers)
 		if err != nil {
 			log.WithError(err).Warn("Failed to validate bandwidth requirements.")
-			failed = append(failed, &agentpb.Probe{
-				Detail: err.Error(),
-				Error:  "failed to validate network bandwidth requirements",
-			})
 		}
+		probes = append(probes, newProbe(bandwidthCheckerID, err, "failed to validate network bandwidth requirements"))
+	}
+
+	return probes
+}
+
+// newProbe returns the probe for the check with the specified name:
+// a failed probe with the given message if err is not nil,
+// or a success probe otherwise
+func newProbe(checker string, err error, message string) *agentpb.Probe {
+	if err == nil {
+		return monitoring.NewSuccessProbe(checker)
+	}
+	return &agentpb.Probe{
+		Checker:  checker,
+		Status:   agentpb.Probe_Failed,
+		Severity: agentpb.Probe_Critical,
+		Detail:   err.Error(),
+		Error:    message,
 	}
+}
 
+// failedProbes returns the failed probes from the specified list
+func failedProbes(probes []*agentpb.Probe) (failed []*agentpb.Probe) {
+	for _, probe := range probes {
+		if probe.Status == agentpb.Probe_Failed {
+			failed = append(failed, probe)
+		}
+	}
 	return failed
 }
 
+// passedProbes returns the probes that have not failed from the specified list
+func passedProbes(probes []*agentpb.Probe) (passed []*agentpb.Probe) {
+	for _, probe := range probes {
+		if probe.Status != agentpb.Probe_Failed {
+			passed = append(passed, probe)
+		}
+	}
+	return passed
+}
+
+const (
+	// nodeCheckerID is the name of the check validating the node against its profile
+	nodeCheckerID = "node"
+	// profileCheckerID is the name of the check of the profile hardware requirements
+	profileCheckerID = "profile"
+	// dockerDeviceCheckerID is the name of the check of the docker device
+	dockerDeviceCheckerID = "docker-device"
+	// systemPackagesCheckerID is the name of the check of the system packages
+	systemPackagesCheckerID = "system-packages"
+	// tempDirCheckerID is the name of the check of the temporary directory
+	tempDirCheckerID = "temp-dir"
+	// etcdDiskCheckerID is the name of the check of the etcd disk performance
+	etcdDiskCheckerID = "etcd-disk"
+	// diskCheckerID is the name of the check of the disk performance
+	diskCheckerID = "disk"
+	// sameOSCheckerID is the name of the check that all nodes run the same OS
+	sameOSCheckerID = "same-os"
+	// timeDriftCheckerID is the name of the check of the time drift between nodes
+	timeDriftCheckerID = "time-drift"
+	// portsCheckerID is the name of the check of the connectivity between nodes
+	portsCheckerID = "ports"
+	// bandwidthCheckerID is the name of the check of the network bandwidth between nodes
+	bandwidthCheckerID = "bandwidth"
+)
+
 // checkDisks verifies that disk performance satisfies the profile requirements.
 func (r *checker) checkDisks(ctx context.Context, server Server) error {
 	requirements := r.Requirements[server.Server.Role]
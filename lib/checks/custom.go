@@ -0,0 +1,151 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"syscall"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/schema"
+
+	"github.com/gravitational/satellite/agent/health"
+	"github.com/gravitational/satellite/agent/proto/agentpb"
+	"github.com/gravitational/satellite/monitoring"
+	"github.com/gravitational/trace"
+)
+
+// RunCustomChecks executes the specified custom checks on the local node.
+// Returns the list of failed probes, failed checks with warn severity
+// are returned as probes with warning severity
+func RunCustomChecks(ctx context.Context, checks []schema.CustomCheck) (failed []*agentpb.Probe) {
+	var reporter health.Probes
+	CheckCustom(ctx, checks, &reporter)
+	return reporter.GetFailed()
+}
+
+// CheckCustom executes the specified custom checks on the local node
+// and reports all probes to reporter
+func CheckCustom(ctx context.Context, checks []schema.CustomCheck, reporter health.Reporter) {
+	if len(checks) == 0 {
+		return
+	}
+	checkers := make([]health.Checker, 0, len(checks))
+	for _, check := range checks {
+		checkers = append(checkers, NewCustomChecker(check))
+	}
+	monitoring.NewCompositeChecker("custom checks", checkers).Check(ctx, reporter)
+}
+
+// NewCustomChecker returns a checker for the specified custom check
+func NewCustomChecker(check schema.CustomCheck) health.Checker {
+	return &customChecker{CustomCheck: check}
+}
+
+// Name returns the name of this checker.
+// Implements health.Checker
+func (r *customChecker) Name() string {
+	return fmt.Sprintf("%v:%v", customCheckerID, r.GetName())
+}
+
+// Check runs the custom check and reports the outcome.
+// Implements health.Checker
+func (r *customChecker) Check(ctx context.Context, reporter health.Reporter) {
+	err := r.check(ctx)
+	if err == nil {
+		reporter.Add(monitoring.NewSuccessProbe(r.Name()))
+		return
+	}
+	log.WithError(err).Warnf("Custom check %q failed.", r.GetName())
+	probe := &agentpb.Probe{
+		Checker:  r.Name(),
+		Status:   agentpb.Probe_Failed,
+		Severity: agentpb.Probe_Critical,
+		Error:    fmt.Sprintf("custom check %q failed: %v", r.GetName(), trace.UserMessage(err)),
+		Detail:   r.Remediation,
+	}
+	if r.IsWarning() {
+		probe.Severity = agentpb.Probe_Warning
+	}
+	reporter.Add(probe)
+}
+
+func (r *customChecker) check(ctx context.Context) error {
+	if err := r.CustomCheck.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaults.CustomCheckTimeout)
+	defer cancel()
+	args := r.Command
+	if r.Script != "" {
+		f, err := ioutil.TempFile("", "check")
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		defer os.Remove(f.Name())
+		_, err = f.WriteString(r.Script)
+		f.Close()
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		args = []string{"bash", f.Name()}
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	out, err := cmd.CombinedOutput()
+	exitCode := 0
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok || ctx.Err() != nil {
+			return trace.BadParameter("failed to run %v: %v", args, err)
+		}
+		exitCode = exitErr.Sys().(syscall.WaitStatus).ExitStatus()
+	}
+	output := strings.TrimSpace(string(out))
+	if exitCode != r.ExitCode {
+		return trace.BadParameter("exit code %v, expected %v: %s", exitCode, r.ExitCode, output)
+	}
+	if r.OutputPattern != "" && !regexp.MustCompile(r.OutputPattern).MatchString(output) {
+		return trace.BadParameter("output %q does not match %q", output, r.OutputPattern)
+	}
+	return nil
+}
+
+// customChecker runs a custom check defined in the application manifest
+type customChecker struct {
+	schema.CustomCheck
+}
+
+// customCheckerID is the prefix of the custom checker names
+const customCheckerID = "custom-check"
+
+// SplitWarnings splits the specified failed probes into failures and warnings
+func SplitWarnings(probes []*agentpb.Probe) (failed, warnings []*agentpb.Probe) {
+	for _, probe := range probes {
+		if probe.Severity == agentpb.Probe_Warning {
+			warnings = append(warnings, probe)
+		} else {
+			failed = append(failed, probe)
+		}
+	}
+	return failed, warnings
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checks
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/schema"
+
+	"github.com/gravitational/satellite/agent/proto/agentpb"
+	"gopkg.in/check.v1"
+)
+
+func (s *ChecksSuite) TestCustomChecks(c *check.C) {
+	failed := RunCustomChecks(context.TODO(), []schema.CustomCheck{
+		{Name: "command", Command: []string{"echo", "net.ipv4.ip_forward = 1"}, OutputPattern: `= 1$`},
+		{Name: "script", Script: "exit 3", ExitCode: 3},
+		{Name: "exit-code", Command: []string{"false"}, Remediation: "Fix it."},
+		{Name: "pattern", Script: "echo disabled", OutputPattern: "enabled$", Severity: schema.CheckSeverityWarn},
+	})
+	c.Assert(failed, check.HasLen, 2)
+	c.Assert(failed[0].Checker, check.Equals, "custom-check:exit-code")
+	c.Assert(failed[0].Error, check.Equals, `custom check "exit-code" failed: exit code 1, expected 0: `)
+	c.Assert(failed[0].Detail, check.Equals, "Fix it.")
+	c.Assert(failed[0].Severity, check.Equals, agentpb.Probe_Critical)
+	c.Assert(failed[1].Checker, check.Equals, "custom-check:pattern")
+	c.Assert(failed[1].Severity, check.Equals, agentpb.Probe_Warning)
+
+	failures, warnings := SplitWarnings(failed)
+	c.Assert(failures, check.DeepEquals, failed[:1])
+	c.Assert(warnings, check.DeepEquals, failed[1:])
+	c.Assert(FormatFailedChecks(warnings), check.Equals,
+		"\t[!] custom check \"pattern\" failed: output \"disabled\" does not match \"enabled$\"\n")
+}
@@ -0,0 +1,486 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	validationpb "github.com/gravitational/gravity/lib/network/validation/proto"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+)
+
+// NetworkTestConfig describes the network test between the cluster nodes
+type NetworkTestConfig struct {
+	// Remote executes the tests on the nodes
+	Remote Remote
+	// Servers lists the cluster nodes to test
+	Servers []storage.Server
+	// PingCount is the number of packets sent to each node to measure
+	// latency and packet loss
+	PingCount int
+	// TestBandwidth enables the bandwidth test for every pair of nodes
+	TestBandwidth bool
+	// BandwidthTestDuration is the duration of the bandwidth test
+	// for each pair of nodes
+	BandwidthTestDuration time.Duration
+	// Clock is used to timestamp the results
+	Clock clockwork.Clock
+}
+
+// CheckAndSetDefaults validates the config and sets defaults
+func (r *NetworkTestConfig) CheckAndSetDefaults() error {
+	if r.Remote == nil {
+		return trace.BadParameter("missing Remote")
+	}
+	if len(r.Servers) < 2 {
+		return trace.BadParameter("at least two nodes are required for the network test")
+	}
+	if r.PingCount == 0 {
+		r.PingCount = defaults.NetworkTestPingCount
+	}
+	if r.BandwidthTestDuration == 0 {
+		r.BandwidthTestDuration = defaults.BandwidthTestDuration
+	}
+	if r.Clock == nil {
+		r.Clock = clockwork.NewRealClock()
+	}
+	return nil
+}
+
+// NetworkMatrix is the outcome of the network test between every pair of nodes
+type NetworkMatrix struct {
+	// Created is the time the test has been executed
+	Created time.Time `json:"created"`
+	// Nodes lists the tested nodes
+	Nodes []NetworkNode `json:"nodes"`
+	// Results lists the results for every pair of nodes on every network
+	Results []NetworkResult `json:"results"`
+}
+
+// NetworkNode describes a tested node
+type NetworkNode struct {
+	// Hostname is the node hostname
+	Hostname string `json:"hostname"`
+	// AdvertiseIP is the node address on the host network
+	AdvertiseIP string `json:"advertise_ip"`
+	// OverlayIP is the node address on the overlay network
+	OverlayIP string `json:"overlay_ip,omitempty"`
+}
+
+// NetworkResult is the outcome of the network test from one node to another
+type NetworkResult struct {
+	// From is the advertise address of the node the test has been executed on
+	From string `json:"from"`
+	// To is the advertise address of the tested node
+	To string `json:"to"`
+	// Network is the tested network: host or overlay
+	Network string `json:"network"`
+	// Latency is the average round-trip time
+	Latency time.Duration `json:"latency"`
+	// PacketLoss is the percentage of lost packets
+	PacketLoss float64 `json:"packet_loss"`
+	// MTU is the largest packet size that can be sent without fragmentation
+	MTU int `json:"mtu,omitempty"`
+	// Bandwidth is the bandwidth between the nodes in bytes per second
+	Bandwidth uint64 `json:"bandwidth,omitempty"`
+	// Error describes the test failure
+	Error string `json:"error,omitempty"`
+}
+
+// IsFailed returns true if the nodes could not reach each other reliably
+func (r NetworkResult) IsFailed() bool {
+	return r.Error != "" || r.PacketLoss > 0
+}
+
+// Failed returns the failed results
+func (r NetworkMatrix) Failed() (failed []NetworkResult) {
+	for _, result := range r.Results {
+		if result.IsFailed() {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Get returns the result of the test from one node to another on the specified network
+func (r NetworkMatrix) Get(from, to, network string) (*NetworkResult, bool) {
+	for _, result := range r.Results {
+		if result.From == from && result.To == to && result.Network == network {
+			return &result, true
+		}
+	}
+	return nil, false
+}
+
+// TestNetwork measures latency, packet loss, MTU and optionally bandwidth
+// between every pair of nodes over the host and the overlay networks
+func TestNetwork(ctx context.Context, config NetworkTestConfig) (*NetworkMatrix, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	tester := &networkTester{NetworkTestConfig: config}
+	matrix := &NetworkMatrix{Created: config.Clock.Now().UTC()}
+	for _, server := range config.Servers {
+		node := NetworkNode{Hostname: server.Hostname, AdvertiseIP: server.AdvertiseIP}
+		overlayIP, err := tester.overlayIP(ctx, server.AdvertiseIP)
+		if err != nil {
+			log.WithError(err).Warnf("Failed to determine overlay address of %v.", server.AdvertiseIP)
+		}
+		node.OverlayIP = overlayIP
+		matrix.Nodes = append(matrix.Nodes, node)
+	}
+	// every node tests its peers in parallel with the other nodes
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, from := range matrix.Nodes {
+		wg.Add(1)
+		go func(from NetworkNode) {
+			defer wg.Done()
+			results := tester.testPeers(ctx, from, matrix.Nodes)
+			mu.Lock()
+			matrix.Results = append(matrix.Results, results...)
+			mu.Unlock()
+		}(from)
+	}
+	wg.Wait()
+	if config.TestBandwidth {
+		tester.testBandwidth(ctx, matrix)
+	}
+	sort.Slice(matrix.Results, func(i, j int) bool {
+		a, b := matrix.Results[i], matrix.Results[j]
+		if a.Network != b.Network {
+			return a.Network == NetworkHost
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return matrix, nil
+}
+
+func (r *networkTester) testPeers(ctx context.Context, from NetworkNode, nodes []NetworkNode) (results []NetworkResult) {
+	for _, to := range nodes {
+		if to.AdvertiseIP == from.AdvertiseIP {
+			continue
+		}
+		results = append(results, r.testPeer(ctx, from.AdvertiseIP, to.AdvertiseIP, to.AdvertiseIP, NetworkHost))
+		result := NetworkResult{From: from.AdvertiseIP, To: to.AdvertiseIP, Network: NetworkOverlay}
+		switch {
+		case from.OverlayIP == "":
+			result.Error = fmt.Sprintf("no overlay address on %v", from.AdvertiseIP)
+		case to.OverlayIP == "":
+			result.Error = fmt.Sprintf("no overlay address on %v", to.AdvertiseIP)
+		default:
+			result = r.testPeer(ctx, from.AdvertiseIP, to.AdvertiseIP, to.OverlayIP, NetworkOverlay)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// testPeer measures latency, packet loss and MTU from the node at from
+// to the node at to using the specified address of the latter
+func (r *networkTester) testPeer(ctx context.Context, from, to, addr, network string) NetworkResult {
+	result := NetworkResult{From: from, To: to, Network: network}
+	var out bytes.Buffer
+	err := r.Remote.Exec(ctx, from, []string{"ping", "-q", "-n",
+		"-c", strconv.Itoa(r.PingCount),
+		"-i", defaults.NetworkTestPingInterval,
+		"-W", "1", addr}, &out)
+	stats, parseErr := parsePingOutput(out.String())
+	if parseErr != nil {
+		result.Error = trace.UserMessage(trace.NewAggregate(err, parseErr))
+		return result
+	}
+	result.Latency = stats.latency
+	result.PacketLoss = stats.loss
+	if stats.loss == 100 {
+		result.Error = fmt.Sprintf("%v is unreachable from %v", addr, from)
+		return result
+	}
+	result.MTU, err = r.pathMTU(ctx, from, addr)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to determine path MTU from %v to %v.", from, addr)
+	}
+	return result
+}
+
+// pathMTU returns the largest packet size that can be sent from the node
+// at from to addr without fragmentation.
+// It starts with the MTU of the outgoing interface and searches for
+// a smaller size if packets of that size do not get through
+func (r *networkTester) pathMTU(ctx context.Context, from, addr string) (int, error) {
+	var out bytes.Buffer
+	err := r.Remote.Exec(ctx, from, []string{"ip", "-o", "route", "get", addr}, &out)
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	match := routeDeviceRegexp.FindStringSubmatch(out.String())
+	if len(match) != 2 {
+		return 0, trace.BadParameter("unexpected route output: %q", out.String())
+	}
+	out.Reset()
+	err = r.Remote.Exec(ctx, from, []string{"cat", fmt.Sprintf("/sys/class/net/%v/mtu", match[1])}, &out)
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	mtu, err := strconv.Atoi(strings.TrimSpace(out.String()))
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	if r.fits(ctx, from, addr, mtu) {
+		return mtu, nil
+	}
+	low, high := defaults.NetworkTestMinMTU, mtu-1
+	if !r.fits(ctx, from, addr, low) {
+		return 0, trace.BadParameter("packets of %v bytes do not get through", low)
+	}
+	for low < high {
+		mid := (low + high + 1) / 2
+		if r.fits(ctx, from, addr, mid) {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return low, nil
+}
+
+// fits returns true if a packet of the specified size can be sent from
+// the node at from to addr with fragmentation prohibited
+func (r *networkTester) fits(ctx context.Context, from, addr string, size int) bool {
+	err := r.Remote.Exec(ctx, from, []string{"ping", "-q", "-n", "-c", "1", "-W", "1",
+		"-M", "do", "-s", strconv.Itoa(size - ipICMPHeaderSize), addr}, ioutil.Discard)
+	return err == nil
+}
+
+// testBandwidth measures bandwidth between every pair of nodes.
+// Pairs are tested one after another as the test saturates the links
+func (r *networkTester) testBandwidth(ctx context.Context, matrix *NetworkMatrix) {
+	for i, a := range matrix.Nodes {
+		for _, b := range matrix.Nodes[i+1:] {
+			r.testPairBandwidth(ctx, matrix, a, b, a.AdvertiseIP, b.AdvertiseIP, NetworkHost)
+			if a.OverlayIP != "" && b.OverlayIP != "" {
+				r.testPairBandwidth(ctx, matrix, a, b, a.OverlayIP, b.OverlayIP, NetworkOverlay)
+			}
+		}
+	}
+}
+
+func (r *networkTester) testPairBandwidth(ctx context.Context, matrix *NetworkMatrix, a, b NetworkNode, addrA, addrB, network string) {
+	game := PingPongGame{
+		a.AdvertiseIP: PingPongRequest{
+			Duration: r.BandwidthTestDuration,
+			Listen:   []validationpb.Addr{{Addr: addrA}},
+			Ping:     []validationpb.Addr{{Addr: addrB}},
+			Mode:     ModeBandwidth,
+		},
+		b.AdvertiseIP: PingPongRequest{
+			Duration: r.BandwidthTestDuration,
+			Listen:   []validationpb.Addr{{Addr: addrB}},
+			Ping:     []validationpb.Addr{{Addr: addrA}},
+			Mode:     ModeBandwidth,
+		},
+	}
+	var bandwidth uint64
+	var errMessage string
+	resp, err := r.Remote.CheckBandwidth(ctx, game)
+	if err == nil && len(resp.Failures()) != 0 {
+		err = trace.BadParameter("%v", strings.Join(resp.Failures(), ", "))
+	}
+	if err != nil {
+		log.WithError(err).Warnf("Failed to test bandwidth between %v and %v.", addrA, addrB)
+		errMessage = fmt.Sprintf("bandwidth test failed: %v", trace.UserMessage(err))
+	} else {
+		// both nodes send and receive, report the slower side
+		bandwidth = resp[a.AdvertiseIP].BandwidthResult
+		if resp[b.AdvertiseIP].BandwidthResult < bandwidth {
+			bandwidth = resp[b.AdvertiseIP].BandwidthResult
+		}
+	}
+	for i, result := range matrix.Results {
+		if result.Network != network {
+			continue
+		}
+		if (result.From == a.AdvertiseIP && result.To == b.AdvertiseIP) ||
+			(result.From == b.AdvertiseIP && result.To == a.AdvertiseIP) {
+			matrix.Results[i].Bandwidth = bandwidth
+			if errMessage != "" && result.Error == "" {
+				matrix.Results[i].Error = errMessage
+			}
+		}
+	}
+}
+
+// overlayIP returns the address of the node on the overlay network
+func (r *networkTester) overlayIP(ctx context.Context, addr string) (string, error) {
+	var out bytes.Buffer
+	err := r.Remote.Exec(ctx, addr, []string{"ip", "-4", "-o", "addr", "show", "dev",
+		defaults.OverlayNetworkInterface}, &out)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	match := interfaceAddrRegexp.FindStringSubmatch(out.String())
+	if len(match) != 2 {
+		return "", trace.NotFound("no address on %v", defaults.OverlayNetworkInterface)
+	}
+	return match[1], nil
+}
+
+type networkTester struct {
+	NetworkTestConfig
+}
+
+// parsePingOutput parses the packet loss and the average round-trip time
+// from the ping summary
+func parsePingOutput(output string) (*pingStats, error) {
+	match := packetLossRegexp.FindStringSubmatch(output)
+	if len(match) != 2 {
+		return nil, trace.BadParameter("unexpected ping output: %q", output)
+	}
+	loss, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	stats := &pingStats{loss: loss}
+	match = roundTripRegexp.FindStringSubmatch(output)
+	if len(match) == 2 {
+		avg, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		stats.latency = time.Duration(avg * float64(time.Millisecond))
+	}
+	return stats, nil
+}
+
+type pingStats struct {
+	loss    float64
+	latency time.Duration
+}
+
+// SaveNetworkMatrix saves the network test results in the specified directory
+// keeping at most the specified number of the most recent results
+func SaveNetworkMatrix(dir string, matrix NetworkMatrix, retain int) error {
+	if err := os.MkdirAll(dir, defaults.SharedDirMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	data, err := json.Marshal(matrix)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	name := fmt.Sprintf("%v.json", matrix.Created.UTC().Format(networkMatrixTimeFormat))
+	err = ioutil.WriteFile(filepath.Join(dir, name), data, defaults.SharedReadMask)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	names, err := networkMatrixFiles(dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for len(names) > retain {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// GetNetworkMatrices returns the network test results saved in the specified
+// directory, the most recent last
+func GetNetworkMatrices(dir string) (matrices []NetworkMatrix, err error) {
+	names, err := networkMatrixFiles(dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+		var matrix NetworkMatrix
+		if err := json.Unmarshal(data, &matrix); err != nil {
+			return nil, trace.Wrap(err, "failed to parse %v", name)
+		}
+		matrices = append(matrices, matrix)
+	}
+	return matrices, nil
+}
+
+// networkMatrixFiles returns the names of the result files in the directory
+// in chronological order
+func networkMatrixFiles(dir string) (names []string, err error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, trace.ConvertSystemError(err)
+	}
+	for _, file := range files {
+		if !file.IsDir() && filepath.Ext(file.Name()) == ".json" {
+			names = append(names, file.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+const (
+	// NetworkHost is the host network
+	NetworkHost = "host"
+	// NetworkOverlay is the overlay network
+	NetworkOverlay = "overlay"
+
+	// ipICMPHeaderSize is the size of IP and ICMP headers
+	// that are not counted in the ping packet size
+	ipICMPHeaderSize = 28
+	// networkMatrixTimeFormat is the sortable timestamp format
+	// of the result file names
+	networkMatrixTimeFormat = "20060102T150405.000Z"
+)
+
+var (
+	// packetLossRegexp matches the packet loss in the ping summary, e.g.
+	// 5 packets transmitted, 5 received, 0% packet loss, time 802ms
+	packetLossRegexp = regexp.MustCompile(`([\d.]+)% packet loss`)
+	// roundTripRegexp matches the average round-trip time in the ping summary, e.g.
+	// rtt min/avg/max/mdev = 0.045/0.060/0.078/0.011 ms
+	roundTripRegexp = regexp.MustCompile(`= [\d.]+/([\d.]+)/[\d.]+/[\d.]+ ms`)
+	// routeDeviceRegexp matches the outgoing interface in the route output
+	routeDeviceRegexp = regexp.MustCompile(`\sdev\s+(\S+)`)
+	// interfaceAddrRegexp matches the IPv4 address in the interface address output
+	interfaceAddrRegexp = regexp.MustCompile(`\sinet\s+([\d.]+)/`)
+)
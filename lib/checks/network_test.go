@@ -0,0 +1,141 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"gopkg.in/check.v1"
+)
+
+func (s *ChecksSuite) TestParsesPingOutput(c *check.C) {
+	stats, err := parsePingOutput(`PING 10.0.0.2 (10.0.0.2) 56(84) bytes of data.
+
+--- 10.0.0.2 ping statistics ---
+10 packets transmitted, 9 received, 10% packet loss, time 1805ms
+rtt min/avg/max/mdev = 0.312/0.450/0.812/0.101 ms
+`)
+	c.Assert(err, check.IsNil)
+	c.Assert(*stats, check.Equals, pingStats{loss: 10, latency: 450 * time.Microsecond})
+
+	stats, err = parsePingOutput("10 packets transmitted, 0 received, 100% packet loss, time 9000ms\n")
+	c.Assert(err, check.IsNil)
+	c.Assert(*stats, check.Equals, pingStats{loss: 100})
+
+	_, err = parsePingOutput("ping: unknown host")
+	c.Assert(err, check.NotNil)
+}
+
+func (s *ChecksSuite) TestNetworkMatrix(c *check.C) {
+	remote := &networkRemote{
+		overlayIPs: map[string]string{"10.0.0.1": "10.244.1.0", "10.0.0.2": "10.244.2.0"},
+		pathMTU:    1450,
+	}
+	clock := clockwork.NewFakeClock()
+	matrix, err := TestNetwork(context.TODO(), NetworkTestConfig{
+		Remote: remote,
+		Servers: []storage.Server{
+			{Hostname: "node-1", AdvertiseIP: "10.0.0.1"},
+			{Hostname: "node-2", AdvertiseIP: "10.0.0.2"},
+			{Hostname: "node-3", AdvertiseIP: "10.0.0.3"},
+		},
+		Clock: clock,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(matrix.Created, check.Equals, clock.Now().UTC())
+	c.Assert(matrix.Nodes, check.DeepEquals, []NetworkNode{
+		{Hostname: "node-1", AdvertiseIP: "10.0.0.1", OverlayIP: "10.244.1.0"},
+		{Hostname: "node-2", AdvertiseIP: "10.0.0.2", OverlayIP: "10.244.2.0"},
+		{Hostname: "node-3", AdvertiseIP: "10.0.0.3"},
+	})
+	c.Assert(matrix.Results, check.HasLen, 12)
+	result, ok := matrix.Get("10.0.0.1", "10.0.0.2", NetworkHost)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(*result, check.DeepEquals, NetworkResult{
+		From:    "10.0.0.1",
+		To:      "10.0.0.2",
+		Network: NetworkHost,
+		Latency: 500 * time.Microsecond,
+		MTU:     1450,
+	})
+	result, ok = matrix.Get("10.0.0.2", "10.0.0.1", NetworkOverlay)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(result.IsFailed(), check.Equals, false)
+	c.Assert(matrix.Failed(), check.DeepEquals, []NetworkResult{
+		{From: "10.0.0.1", To: "10.0.0.3", Network: NetworkOverlay, Error: "no overlay address on 10.0.0.3"},
+		{From: "10.0.0.2", To: "10.0.0.3", Network: NetworkOverlay, Error: "no overlay address on 10.0.0.3"},
+		{From: "10.0.0.3", To: "10.0.0.1", Network: NetworkOverlay, Error: "no overlay address on 10.0.0.3"},
+		{From: "10.0.0.3", To: "10.0.0.2", Network: NetworkOverlay, Error: "no overlay address on 10.0.0.3"},
+	})
+
+	dir := c.MkDir()
+	for i := 0; i < 3; i++ {
+		matrix.Created = matrix.Created.Add(time.Minute)
+		c.Assert(SaveNetworkMatrix(dir, *matrix, 2), check.IsNil)
+	}
+	matrices, err := GetNetworkMatrices(dir)
+	c.Assert(err, check.IsNil)
+	c.Assert(matrices, check.HasLen, 2)
+	c.Assert(matrices[1].Created.Equal(matrix.Created), check.Equals, true)
+	c.Assert(matrices[0].Created.Equal(matrix.Created.Add(-time.Minute)), check.Equals, true)
+}
+
+// networkRemote simulates the commands executed by the network test
+type networkRemote struct {
+	Remote
+	overlayIPs map[string]string
+	pathMTU    int
+}
+
+func (r *networkRemote) Exec(ctx context.Context, addr string, command []string, out io.Writer) error {
+	args := strings.Join(command, " ")
+	switch {
+	case strings.HasPrefix(args, "ip -4 -o addr show"):
+		ip, ok := r.overlayIPs[addr]
+		if !ok {
+			return trace.NotFound("device not found")
+		}
+		fmt.Fprintf(out, "5: flannel.1    inet %v/32 scope global flannel.1\n", ip)
+	case strings.HasPrefix(args, "ip -o route get"):
+		fmt.Fprintf(out, "%v dev eth0 src %v uid 0\n", command[len(command)-1], addr)
+	case strings.HasPrefix(args, "cat /sys/class/net/eth0/mtu"):
+		fmt.Fprintln(out, "1500")
+	case strings.Contains(args, "-M do"):
+		size, err := strconv.Atoi(command[len(command)-2])
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if size+ipICMPHeaderSize > r.pathMTU {
+			return trace.BadParameter("message too long")
+		}
+	case strings.HasPrefix(args, "ping"):
+		fmt.Fprintln(out, "10 packets transmitted, 10 received, 0% packet loss, time 1805ms")
+		fmt.Fprintln(out, "rtt min/avg/max/mdev = 0.312/0.500/0.812/0.101 ms")
+	default:
+		return trace.BadParameter("unexpected command %q", args)
+	}
+	return nil
+}
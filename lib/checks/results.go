@@ -0,0 +1,276 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checks
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/constants"
+
+	"github.com/gravitational/satellite/agent/proto/agentpb"
+	"github.com/gravitational/trace"
+)
+
+// Result is the machine-readable outcome of a single check
+type Result struct {
+	// Node identifies the node the check has been executed on.
+	// Empty for the checks that take all nodes into account
+	Node string `json:"node,omitempty"`
+	// Checker is the name of the check
+	Checker string `json:"checker"`
+	// Status is the check status: passed, failed or fixed
+	Status string `json:"status"`
+	// Severity is the check severity: critical or warning
+	Severity string `json:"severity"`
+	// Error describes the failure
+	Error string `json:"error,omitempty"`
+	// Detail provides additional details about the failure
+	Detail string `json:"detail,omitempty"`
+	// Remediation describes how to fix the failure
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// NewResult returns the result for the specified probe executed on the node.
+// Only the probes reported as running are considered passed
+func NewResult(node string, probe agentpb.Probe) Result {
+	result := Result{
+		Node:     node,
+		Checker:  probe.Checker,
+		Status:   ResultFailed,
+		Severity: SeverityCritical,
+		Error:    probe.Error,
+		Detail:   probe.Detail,
+	}
+	if probe.Status == agentpb.Probe_Running {
+		result.Status = ResultPassed
+	}
+	if probe.Severity == agentpb.Probe_Warning {
+		result.Severity = SeverityWarning
+	}
+	if strings.HasPrefix(probe.Checker, customCheckerID+":") {
+		// custom checks report remediation as the probe detail
+		result.Remediation = probe.Detail
+	}
+	return result
+}
+
+// NewResults returns the results for the specified probes
+// executed on the node
+func NewResults(node string, probes []*agentpb.Probe) (results Results) {
+	for _, probe := range probes {
+		results = append(results, NewResult(node, *probe))
+	}
+	return results
+}
+
+// String returns a textual representation of this result
+func (r Result) String() string {
+	var buf bytes.Buffer
+	switch {
+	case r.Error != "" && r.Detail != "":
+		fmt.Fprintf(&buf, "%s (%s)", r.Error, r.Detail)
+	case r.Error != "":
+		buf.WriteString(r.Error)
+	case r.Detail != "":
+		buf.WriteString(r.Detail)
+	default:
+		buf.WriteString(r.Checker)
+	}
+	if r.Node != "" {
+		fmt.Fprintf(&buf, " on %v", r.Node)
+	}
+	return buf.String()
+}
+
+// IsFailed returns true if this result is a critical failure
+func (r Result) IsFailed() bool {
+	return r.Status == ResultFailed && r.Severity == SeverityCritical
+}
+
+// Results is a list of check results
+type Results []Result
+
+// Failed returns the critical failures
+func (r Results) Failed() (failed Results) {
+	for _, result := range r {
+		if result.IsFailed() {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Warnings returns the failures with warning severity
+func (r Results) Warnings() (warnings Results) {
+	for _, result := range r {
+		if result.Status == ResultFailed && result.Severity == SeverityWarning {
+			warnings = append(warnings, result)
+		}
+	}
+	return warnings
+}
+
+// Error returns an error listing the critical failures
+// or nil if there are none
+func (r Results) Error() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	return trace.BadParameter("The following checks failed:\n%v", failed.Format())
+}
+
+// Format returns the results formatted as a list
+func (r Results) Format() string {
+	var buf bytes.Buffer
+	for _, result := range r {
+		mark := constants.FailureMark
+		switch {
+		case result.Status == ResultPassed, result.Status == ResultFixed:
+			mark = constants.SuccessMark
+		case result.Severity == SeverityWarning:
+			mark = constants.WarnMark
+		}
+		fmt.Fprintf(&buf, "\t[%v] %s\n", mark, result)
+	}
+	return buf.String()
+}
+
+// Write outputs the results to w in the specified format.
+// Supported formats are json and junit
+func (r Results) Write(w io.Writer, format constants.Format) error {
+	switch format {
+	case constants.EncodingJSON:
+		results := r
+		if results == nil {
+			results = Results{}
+		}
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return trace.ConvertSystemError(err)
+	case constants.EncodingJUnit:
+		return trace.Wrap(r.writeJUnit(w))
+	}
+	return trace.BadParameter("unsupported output format %q, supported are %v",
+		format, ResultFormats)
+}
+
+// writeJUnit outputs the results as a JUnit XML report with a test suite
+// per node. Failures with warning severity and fixed failures are reported
+// as passed test cases with the details in the test case output
+func (r Results) writeJUnit(w io.Writer) error {
+	report := junitTestSuites{}
+	suites := make(map[string]int)
+	for _, result := range r {
+		node := result.Node
+		if node == "" {
+			node = junitClusterSuite
+		}
+		i, ok := suites[node]
+		if !ok {
+			i = len(report.Suites)
+			suites[node] = i
+			report.Suites = append(report.Suites, junitTestSuite{Name: node})
+		}
+		suite := &report.Suites[i]
+		testCase := junitTestCase{ClassName: node, Name: result.Checker}
+		if result.IsFailed() {
+			testCase.Failure = &junitFailure{
+				Message: result.Error,
+				Type:    result.Severity,
+				Text:    formatJUnitText(result),
+			}
+			suite.Failures++
+		} else {
+			testCase.SystemOut = fmt.Sprintf("%v: %v", result.Status, formatJUnitText(result))
+		}
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return trace.Wrap(err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return trace.ConvertSystemError(err)
+}
+
+func formatJUnitText(result Result) string {
+	text := result.String()
+	if result.Remediation != "" && result.Remediation != result.Detail {
+		text = fmt.Sprintf("%v\nRemediation: %v", text, result.Remediation)
+	}
+	return text
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+const (
+	// ResultPassed is the status of a passed check
+	ResultPassed = "passed"
+	// ResultFailed is the status of a failed check
+	ResultFailed = "failed"
+	// ResultFixed is the status of a failed check that has been fixed
+	ResultFixed = "fixed"
+
+	// SeverityCritical is the severity of a failure that fails the validation
+	SeverityCritical = "critical"
+	// SeverityWarning is the severity of a failure that does not fail the validation
+	SeverityWarning = "warning"
+
+	// junitClusterSuite is the name of the JUnit test suite
+	// for the checks that take all nodes into account
+	junitClusterSuite = "cluster"
+)
+
+// ResultFormats lists the supported machine-readable result formats
+var ResultFormats = []constants.Format{constants.EncodingJSON, constants.EncodingJUnit}
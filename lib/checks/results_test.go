@@ -0,0 +1,79 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checks
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gravitational/gravity/lib/constants"
+
+	"github.com/gravitational/satellite/agent/proto/agentpb"
+	"gopkg.in/check.v1"
+)
+
+func (s *ChecksSuite) TestFormatsResults(c *check.C) {
+	results := NewResults("node-1", []*agentpb.Probe{
+		{Checker: "port-checker", Error: "port 2379 is occupied", Detail: "etcd"},
+		{Checker: "custom-check:swap", Error: "swap is on", Detail: "Run swapoff -a.", Severity: agentpb.Probe_Warning},
+		{Checker: "disk", Status: agentpb.Probe_Running},
+	})
+	results = append(results, NewResults("", []*agentpb.Probe{{Checker: "time-drift", Error: "time drift"}})...)
+	c.Assert(results, check.DeepEquals, Results{
+		{Node: "node-1", Checker: "port-checker", Status: ResultFailed, Severity: SeverityCritical, Error: "port 2379 is occupied", Detail: "etcd"},
+		{Node: "node-1", Checker: "custom-check:swap", Status: ResultFailed, Severity: SeverityWarning, Error: "swap is on", Detail: "Run swapoff -a.", Remediation: "Run swapoff -a."},
+		{Node: "node-1", Checker: "disk", Status: ResultPassed, Severity: SeverityCritical},
+		{Checker: "time-drift", Status: ResultFailed, Severity: SeverityCritical, Error: "time drift"},
+	})
+	c.Assert(results.Failed(), check.DeepEquals, Results{results[0], results[3]})
+	c.Assert(results.Warnings(), check.DeepEquals, Results{results[1]})
+	c.Assert(results.Error(), check.ErrorMatches, `The following checks failed:
+	\[×\] port 2379 is occupied \(etcd\) on node-1
+	\[×\] time drift
+`)
+
+	var buf bytes.Buffer
+	c.Assert(results.Write(&buf, constants.EncodingJSON), check.IsNil)
+	var decoded Results
+	c.Assert(json.Unmarshal(buf.Bytes(), &decoded), check.IsNil)
+	c.Assert(decoded, check.DeepEquals, results)
+
+	buf.Reset()
+	c.Assert(results.Write(&buf, constants.EncodingJUnit), check.IsNil)
+	c.Assert(buf.String(), check.Equals, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="node-1" tests="3" failures="1">
+    <testcase classname="node-1" name="port-checker">
+      <failure message="port 2379 is occupied" type="critical">port 2379 is occupied (etcd) on node-1</failure>
+    </testcase>
+    <testcase classname="node-1" name="custom-check:swap">
+      <system-out>failed: swap is on (Run swapoff -a.) on node-1</system-out>
+    </testcase>
+    <testcase classname="node-1" name="disk">
+      <system-out>passed: disk on node-1</system-out>
+    </testcase>
+  </testsuite>
+  <testsuite name="cluster" tests="1" failures="1">
+    <testcase classname="cluster" name="time-drift">
+      <failure message="time drift" type="critical">time drift</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`)
+
+	c.Assert(results.Write(&buf, constants.EncodingYAML), check.NotNil)
+}
@@ -106,6 +106,9 @@ const (
 	// ETCDBackend defines storage backend as Etcd
 	ETCDBackend = "etcd"
 
+	// PostgresBackend defines storage backend as PostgreSQL
+	PostgresBackend = "postgres"
+
 	// WebAssetsPackage names the web assets package
 	WebAssetsPackage = "web-assets"
 
@@ -359,6 +362,10 @@ const (
 	// EnvPodNamespace is environment variable with the pod namespace
 	EnvPodNamespace = "POD_NAMESPACE"
 
+	// EnvRestoreVerify is environment variable set for the verifyRestore hook
+	// when it is run to verify a backup
+	EnvRestoreVerify = "GRAVITY_RESTORE_VERIFY"
+
 	// EnvCloudProvider sets cloud provider name
 	EnvCloudProvider = "CLOUD_PROVIDER"
 
@@ -737,6 +744,8 @@ var (
 	EncodingShort Format = "short"
 	// EncodingYAML is for the YAML encoding format
 	EncodingYAML Format = "yaml"
+	// EncodingJUnit is for the JUnit XML report format
+	EncodingJUnit Format = "junit"
 	// OutputFormats is a list of recognized output formats for gravity CLI commands
 	OutputFormats = []Format{
 		EncodingText,
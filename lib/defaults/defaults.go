@@ -91,6 +91,32 @@ const (
 	// PhaseTimeout is the default phase execution timeout
 	PhaseTimeout = "1h"
 
+	// DryRunTimeout is the max allowed time to describe the actions of an operation plan
+	DryRunTimeout = 5 * time.Minute
+
+	// PhaseLogMaxEntries is the maximum number of log entries captured
+	// during a single phase execution attempt
+	PhaseLogMaxEntries = 1000
+
+	// PhaseLogMaxMessageSize is the maximum size of a captured phase log message.
+	// Longer messages are truncated
+	PhaseLogMaxMessageSize = 4096
+
+	// PhaseLogMaxRecords is the maximum number of phase execution attempt
+	// logs kept for an operation. The oldest logs are removed first
+	PhaseLogMaxRecords = 100
+
+	// PhaseRetryAttempts is the default maximum number of attempts to execute
+	// a phase with a retry policy
+	PhaseRetryAttempts = 3
+	// PhaseRetryInitialInterval is the default delay before the first retry of a failed phase
+	PhaseRetryInitialInterval = 10 * time.Second
+	// PhaseRetryMaxInterval is the default maximum delay between retries of a failed phase
+	PhaseRetryMaxInterval = 2 * time.Minute
+	// PhaseRetryMultiplier is the default factor the delay between retries
+	// of a failed phase grows by after each attempt
+	PhaseRetryMultiplier = 2.0
+
 	// UpdateTimeout is the max allowed time for system update
 	UpdateTimeout = 30 * time.Minute
 
@@ -148,6 +174,29 @@ const (
 	// HookJobDeadline sets the default limit on the hook job running time
 	HookJobDeadline = 20 * time.Minute
 
+	// HookRetryBackoff is the default delay before the first retry
+	// of a failed hook
+	HookRetryBackoff = 10 * time.Second
+
+	// HookMaxRetries is the maximum number of retries a hook can request
+	HookMaxRetries = 10
+
+	// HookRunLogsTimeout is the maximum time to collect the logs
+	// of a completed hook job for the hook run history
+	HookRunLogsTimeout = 30 * time.Second
+
+	// HookRunLogsMaxSize is the maximum size of the hook job logs
+	// kept in the hook run history. Only the tail is kept for larger logs
+	HookRunLogsMaxSize = 256 * 1024
+
+	// HookRunsRetain is the number of the most recent hook runs
+	// of each hook type kept in the hook run history
+	HookRunsRetain = 100
+
+	// ResourceRevisionsRetain is the number of the most recent revisions
+	// of each resource kept in the cluster resource history
+	ResourceRevisionsRetain = 50
+
 	// CertTTL is Teleport's SSH cert default TTL
 	CertTTL = 10 * time.Hour
 
@@ -199,6 +248,10 @@ const (
 	// request during the preflight test
 	AgentValidationTimeout = 1 * time.Minute
 
+	// CustomCheckTimeout specifies the maximum amount of time for a single
+	// custom preflight check defined in the application manifest
+	CustomCheckTimeout = 30 * time.Second
+
 	// AgentHealthCheckTimeout specifies the maximum amount of time for a health check
 	AgentHealthCheckTimeout = 5 * time.Second
 
@@ -387,6 +440,10 @@ const (
 	// LocalDir is the gravity subdirectory where local data is stored
 	LocalDir = "local"
 
+	// AutofixRecordFile is the name of the file in the local state directory
+	// with the record of changes made by gravity check --fix
+	AutofixRecordFile = "autofix.json"
+
 	// SiteDir is the gravity subdirectory where cluster data is stored
 	SiteDir = "site"
 
@@ -397,6 +454,10 @@ const (
 	// unpacked package archives
 	UnpackedDir = "unpacked"
 
+	// UploadsDir is the default name of the directory with
+	// incomplete package uploads
+	UploadsDir = "uploads"
+
 	// PackagesDir is the place where we put all local packages
 	PackagesDir = "packages"
 
@@ -439,6 +500,46 @@ const (
 	// PlanetShareDir is the in-planet share directory
 	PlanetShareDir = "/ext/share"
 
+	// DedupAvgChunkSize is the target average size of a chunk
+	// in the deduplicating package storage
+	DedupAvgChunkSize = 1 << 20
+
+	// DedupMaxTransferChunkSize is the maximum size of a chunk accepted
+	// during a chunked package transfer
+	DedupMaxTransferChunkSize = 64 << 20
+
+	// DedupTransferBatchSize is the maximum number of chunks requested
+	// at once during a chunked package transfer
+	DedupTransferBatchSize = 32
+
+	// DedupOrphanChunkTTL is the age after which a chunk not referenced
+	// by any BLOB is removed from the deduplicating package storage
+	DedupOrphanChunkTTL = 24 * time.Hour
+
+	// DedupOrphanChunkSweepInterval is how often the deduplicating package
+	// storage is checked for orphan chunks
+	DedupOrphanChunkSweepInterval = time.Hour
+
+	// DedupTransferAttempts is the number of attempts to resume
+	// an interrupted chunked package transfer
+	DedupTransferAttempts = 20
+
+	// UploadChunkSize is the size of a chunk sent during
+	// a resumable package upload
+	UploadChunkSize = 8 << 20
+
+	// UploadMaxChunkSize is the maximum size of a chunk accepted
+	// during a resumable package upload
+	UploadMaxChunkSize = 64 << 20
+
+	// UploadSessionTTL defines how long an inactive package upload
+	// session is kept before it is removed
+	UploadSessionTTL = 24 * time.Hour
+
+	// UploadAttempts is the number of attempts to resume
+	// an interrupted package upload
+	UploadAttempts = 20
+
 	// SharedDirMask is a mask for shared directories
 	SharedDirMask = 0755
 
@@ -811,6 +912,22 @@ const (
 	BandwidthTestDuration = 20 * time.Second
 	// BandwidthTestMaxServers is the maximum amount of servers participating in the bandwidth test
 	BandwidthTestMaxServers = 3
+
+	// NetworkTestPingCount is the number of packets sent to each node
+	// by the network test
+	NetworkTestPingCount = 10
+	// NetworkTestPingInterval is the interval between packets in seconds
+	// sent by the network test
+	NetworkTestPingInterval = "0.2"
+	// NetworkTestMinMTU is the smallest MTU the network test checks for
+	NetworkTestMinMTU = 576
+	// NetworkTestRetain is the number of the most recent network test results kept
+	NetworkTestRetain = 100
+	// NetworkTestDir is the name of the directory in the local state directory
+	// with the network test results
+	NetworkTestDir = "network-checks"
+	// OverlayNetworkInterface is the name of the overlay network interface
+	OverlayNetworkInterface = "flannel.1"
 	// BandwidthMaxSpeedBytes is the theoretical upper bound on the amount of types transferred per
 	// second during bandwidth test, which is used in HDR histogram
 	BandwidthMaxSpeedBytes = 100000000000 // 100GB
@@ -870,6 +987,32 @@ const (
 	EtcdLocalAddr = "https://127.0.0.1:2379"
 	// EtcdKey is the key under which gravity data is stored in etcd
 	EtcdKey = "/gravity/local"
+
+	// PostgresKey is the key under which gravity data is stored in PostgreSQL
+	PostgresKey = "gravity"
+	// PostgresMaxOpenConns is the default maximum number of open PostgreSQL connections
+	PostgresMaxOpenConns = 20
+	// PostgresTxAttempts is the number of attempts to execute a PostgreSQL
+	// transaction aborted due to a deadlock or a serialization failure
+	PostgresTxAttempts = 3
+	// PostgresExpiryInterval is the interval expired keys are removed
+	// from PostgreSQL with
+	PostgresExpiryInterval = time.Minute
+	// WatchPollInterval is the interval the storage backends without
+	// native watch support are polled for changes with
+	WatchPollInterval = time.Second
+	// WatchBufferSize is the size of the storage watcher event buffer
+	WatchBufferSize = 64
+
+	// BackupMinInterval is the minimum interval between scheduled backups
+	BackupMinInterval = 10 * time.Minute
+	// BackupCheckInterval is how often backup policies are checked for due backups
+	BackupCheckInterval = time.Minute
+	// BackupHookStateDir is the host directory scheduled backups are collected in
+	BackupHookStateDir = "/var/state/backup"
+	// BackupEncryptionChunkSize is the size of the independently sealed
+	// chunks of encrypted backup archives
+	BackupEncryptionChunkSize = 64 * 1024
 	// EtcdKeyFilename is the etcd private key filename
 	EtcdKeyFilename = "etcd.key"
 	// EtcdCertFilename is the etcd certificate filename
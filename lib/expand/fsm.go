@@ -64,6 +64,9 @@ type FSMConfig struct {
 	DebugMode bool
 	// Insecure turns on FSM insecure mode
 	Insecure bool
+	// ParallelPhases is the maximum number of independent plan phases
+	// executed concurrently. Phases are executed in plan order if unset
+	ParallelPhases int
 }
 
 // CheckAndSetDefaults validates expand FSM configuration and sets defaults
@@ -117,9 +120,10 @@ func NewFSM(config FSMConfig) (*fsm.FSM, error) {
 		FieldLogger: logger,
 	}
 	fsm, err := fsm.New(fsm.Config{
-		Engine: engine,
-		Runner: config.Runner,
-		Logger: logger,
+		Engine:         engine,
+		Runner:         config.Runner,
+		Logger:         logger,
+		ParallelPhases: config.ParallelPhases,
 	})
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -139,9 +143,12 @@ type fsmEngine struct {
 // GetExecutor returns a new executor based on the provided parameters
 func (e *fsmEngine) GetExecutor(p fsm.ExecutorParams, remote fsm.Remote) (fsm.PhaseExecutor, error) {
 	logger := &fsm.Logger{
-		FieldLogger: logrus.WithField(constants.FieldPhase, p.Phase.ID),
-		Key:         p.Key(),
-		Operator:    e.Operator,
+		FieldLogger: logrus.WithFields(logrus.Fields{
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
+		}),
+		Key:      p.Key(),
+		Operator: e.Operator,
 	}
 	executor, err := e.Spec(p, remote)
 	if err != nil {
@@ -162,6 +169,7 @@ func (e *fsmEngine) ChangePhaseState(ctx context.Context, change fsm.StateChange
 		NewState:    change.State,
 		Error:       utils.ToRawTrace(change.Error),
 		Created:     time.Now().UTC(),
+		Node:        change.Node,
 	}
 	_, err := e.JoinBackend.CreateOperationPlanChange(planChange)
 	if err != nil {
@@ -175,6 +183,11 @@ func (e *fsmEngine) ChangePhaseState(ctx context.Context, change fsm.StateChange
 	return nil
 }
 
+// RecordPhaseLog saves the log entries captured during phase execution
+func (e *fsmEngine) RecordPhaseLog(ctx context.Context, log storage.PhaseLog) error {
+	return trace.Wrap(fsm.RecordPhaseLog(e.JoinBackend, log))
+}
+
 // GetPlan returns the up-to-date operation plan
 func (e *fsmEngine) GetPlan() (*storage.OperationPlan, error) {
 	return fsm.GetOperationPlan(e.JoinBackend, e.OperationKey)
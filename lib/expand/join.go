@@ -292,6 +292,9 @@ type PeerConfig struct {
 	// SkipWizard specifies to the peer agents that the peer is not a wizard
 	// and attempts to contact the wizard should be skipped
 	SkipWizard bool
+	// ParallelPhases is the maximum number of independent plan phases
+	// to execute concurrently
+	ParallelPhases int
 }
 
 // CheckAndSetDefaults checks the parameters and autodetects some defaults
@@ -507,6 +510,10 @@ func (p *Peer) executeConcurrentStep(req *installpb.ExecuteRequest, stream insta
 }
 
 func (p *Peer) executePhase(ctx context.Context, opCtx operationContext, phase installpb.Phase, disp dispatcher.EventDispatcher) (dispatcher.Status, error) {
+	if phase.DryRun {
+		err := p.dryRunPhase(ctx, opCtx, phase, disp)
+		return dispatcher.StatusUnknown, trace.Wrap(err)
+	}
 	if phase.IsResume() && !opCtx.isExpand() {
 		return p.agentLoop(opCtx)
 	}
@@ -535,6 +542,23 @@ func (p *Peer) resumeExpandOperation(ctx context.Context, opCtx operationContext
 	return install.ExecuteOperation(ctx, machine, progressReporter, p.FieldLogger)
 }
 
+func (p *Peer) dryRunPhase(ctx context.Context, opCtx operationContext, phase installpb.Phase, disp dispatcher.EventDispatcher) error {
+	if !opCtx.isExpand() {
+		return trace.BadParameter("this node is joining an install operation, " +
+			"use the installer node to describe the operation plan")
+	}
+	machine, err := p.getFSM(opCtx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	progressReporter := dispatcher.NewProgressReporter(ctx, disp, phaseTitle(phase))
+	defer progressReporter.Stop()
+	return trace.Wrap(install.DryRunOperation(ctx, machine, fsm.Params{
+		PhaseID: phase.ID,
+		Force:   phase.Force,
+	}, progressReporter))
+}
+
 func (p *Peer) executeSinglePhase(ctx context.Context, opCtx operationContext, phase installpb.Phase, disp dispatcher.EventDispatcher) error {
 	machine, err := p.getFSM(opCtx)
 	if err != nil {
@@ -1090,22 +1114,24 @@ func (p *Peer) newInstallFSM(ctx operationContext) (*fsm.FSM, error) {
 		LocalApps:          p.LocalApps,
 		LocalPackages:      p.LocalPackages,
 		Insecure:           p.Insecure,
+		ParallelPhases:     p.ParallelPhases,
 	})
 }
 
 func (p *Peer) newJoinFSM(ctx operationContext) (*fsm.FSM, error) {
 	return NewFSM(FSMConfig{
-		Operator:      ctx.Operator,
-		OperationKey:  ctx.Operation.Key(),
-		Apps:          ctx.Apps,
-		Packages:      ctx.Packages,
-		LocalBackend:  p.LocalBackend,
-		LocalApps:     p.LocalApps,
-		LocalPackages: p.LocalPackages,
-		JoinBackend:   p.JoinBackend,
-		Credentials:   ctx.Creds.Client,
-		DebugMode:     p.DebugMode,
-		Insecure:      p.Insecure,
+		Operator:       ctx.Operator,
+		OperationKey:   ctx.Operation.Key(),
+		Apps:           ctx.Apps,
+		Packages:       ctx.Packages,
+		LocalBackend:   p.LocalBackend,
+		LocalApps:      p.LocalApps,
+		LocalPackages:  p.LocalPackages,
+		JoinBackend:    p.JoinBackend,
+		Credentials:    ctx.Creds.Client,
+		DebugMode:      p.DebugMode,
+		Insecure:       p.Insecure,
+		ParallelPhases: p.ParallelPhases,
 	})
 }
 
@@ -1241,6 +1267,9 @@ func isTerminalError(err error) bool {
 }
 
 func phaseTitle(phase installpb.Phase) string {
+	if phase.DryRun {
+		return fmt.Sprintf("Describing phase %v", phase.ID)
+	}
 	if phase.IsResume() {
 		return "Resuming operation"
 	}
@@ -39,7 +39,8 @@ import (
 func NewAgentStart(p fsm.ExecutorParams, operator ops.Operator) (*agentStartExecutor, error) {
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
-			constants.FieldPhase: p.Phase.ID,
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 		}),
 		Key:      opKey(p.Plan),
 		Operator: operator,
@@ -106,6 +107,12 @@ func (p *agentStartExecutor) getProxyClient(ctx context.Context) (*client.ProxyC
 	return proxyClient, nil
 }
 
+// DryRun describes the agent this phase would deploy
+func (p *agentStartExecutor) DryRun(context.Context) ([]fsm.Action, error) {
+	return []fsm.Action{fsm.NewAction(fsm.ActionRestartService,
+		"start RPC agent on master node %v", p.Master.AdvertiseIP)}, nil
+}
+
 // Rollback is no-op for this phase
 func (*agentStartExecutor) Rollback(ctx context.Context) error {
 	return nil
@@ -125,7 +132,8 @@ func (*agentStartExecutor) PostCheck(ctx context.Context) error {
 func NewAgentStop(p fsm.ExecutorParams, operator ops.Operator, packages pack.PackageService) (*agentStopExecutor, error) {
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
-			constants.FieldPhase: p.Phase.ID,
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 		}),
 		Key:      opKey(p.Plan),
 		Operator: operator,
@@ -166,6 +174,12 @@ func (p *agentStopExecutor) Execute(ctx context.Context) error {
 	return nil
 }
 
+// DryRun describes the agent this phase would stop
+func (p *agentStopExecutor) DryRun(context.Context) ([]fsm.Action, error) {
+	return []fsm.Action{fsm.NewAction(fsm.ActionRestartService,
+		"stop RPC agent on master node %v", p.Master.AdvertiseIP)}, nil
+}
+
 // Rollback is no-op for this phase
 func (*agentStopExecutor) Rollback(ctx context.Context) error {
 	return nil
@@ -32,9 +32,12 @@ import (
 // NewChecks returns executor that executes preflight checks on the joining node.
 func NewChecks(p fsm.ExecutorParams, operator ops.Operator, runner rpc.AgentRepository) (*checksExecutor, error) {
 	logger := &fsm.Logger{
-		FieldLogger: logrus.WithField(constants.FieldPhase, p.Phase.ID),
-		Key:         opKey(p.Plan),
-		Operator:    operator,
+		FieldLogger: logrus.WithFields(logrus.Fields{
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
+		}),
+		Key:      opKey(p.Plan),
+		Operator: operator,
 	}
 	return &checksExecutor{
 		FieldLogger:    logger,
@@ -90,6 +93,11 @@ func (p *checksExecutor) Execute(ctx context.Context) error {
 	// the OS check, time drift check, etc).
 	failed := checker.CheckNode(ctx, *node)
 	failed = append(failed, checker.CheckNodes(ctx, []checks.Server{*master, *node})...)
+	failed, warnings := checks.SplitWarnings(failed)
+	if len(warnings) != 0 {
+		p.Warnf("The following checks produced warnings:\n%v",
+			checks.FormatFailedChecks(warnings))
+	}
 	if len(failed) != 0 {
 		return trace.BadParameter("The following checks failed:\n%v",
 			checks.FormatFailedChecks(failed))
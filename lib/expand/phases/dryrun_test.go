@@ -0,0 +1,68 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"gopkg.in/check.v1"
+)
+
+func TestPhases(t *testing.T) { check.TestingT(t) }
+
+type DryRunSuite struct{}
+
+var _ = check.Suite(&DryRunSuite{})
+
+func (s *DryRunSuite) TestDescribesExpandPhases(c *check.C) {
+	master := storage.Server{AdvertiseIP: "10.0.0.1", Hostname: "master", ClusterRole: "master"}
+	p := fsm.ExecutorParams{
+		Plan: storage.OperationPlan{OperationID: "1", ClusterName: "example.com"},
+		Phase: storage.OperationPhase{
+			ID:   "/startAgent",
+			Data: &storage.OperationPhaseData{Server: &master},
+		},
+	}
+	// operator is not used to describe the phases
+	var operator ops.Operator
+
+	start, err := NewAgentStart(p, operator)
+	c.Assert(err, check.IsNil)
+	backup, err := NewEtcdBackup(p, operator, nil)
+	c.Assert(err, check.IsNil)
+	elect, err := NewElect(p, operator)
+	c.Assert(err, check.IsNil)
+
+	var actions []fsm.Action
+	for _, executor := range []fsm.PhaseExecutor{start, backup, elect} {
+		dryRunner, ok := executor.(fsm.DryRunner)
+		c.Assert(ok, check.Equals, true)
+		executorActions, err := dryRunner.DryRun(context.TODO())
+		c.Assert(err, check.IsNil)
+		actions = append(actions, executorActions...)
+	}
+	c.Assert(actions, check.DeepEquals, []fsm.Action{
+		{Kind: fsm.ActionRestartService, Description: "start RPC agent on master node 10.0.0.1"},
+		{Kind: fsm.ActionBackup, Description: "back up etcd data to " + getBackupPath("1")},
+		{Kind: fsm.ActionUpdateObject, Description: "enable leader election on 10.0.0.1"},
+	})
+}
@@ -32,6 +32,7 @@ func NewElect(p fsm.ExecutorParams, operator ops.Operator) (fsm.PhaseExecutor, e
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
 			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 			constants.FieldAdvertiseIP: p.Phase.Data.Server.AdvertiseIP,
 			constants.FieldHostname:    p.Phase.Data.Server.Hostname,
 		}),
@@ -67,6 +68,16 @@ func (p *electExecutor) Execute(ctx context.Context) (err error) {
 	return nil
 }
 
+// DryRun describes the leader election change this phase would make
+func (p *electExecutor) DryRun(context.Context) ([]fsm.Action, error) {
+	if p.Phase.Data.Server.IsMaster() {
+		return []fsm.Action{fsm.NewAction(fsm.ActionUpdateObject,
+			"enable leader election on %v", p.Phase.Data.Server.AdvertiseIP)}, nil
+	}
+	return []fsm.Action{fsm.NewAction(fsm.ActionUpdateObject,
+		"pause leader election on %v", p.Phase.Data.Server.AdvertiseIP)}, nil
+}
+
 // Rollback is no-op for this phase
 func (*electExecutor) Rollback(ctx context.Context) error {
 	return nil
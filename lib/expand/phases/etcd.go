@@ -66,7 +66,8 @@ func NewEtcd(p fsm.ExecutorParams, operator ops.Operator, runner rpc.AgentReposi
 	}
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
-			constants.FieldPhase: p.Phase.ID,
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 		}),
 		Key:      opKey(p.Plan),
 		Operator: operator,
@@ -106,6 +107,12 @@ func (p *etcdExecutor) Execute(ctx context.Context) error {
 	return nil
 }
 
+// DryRun describes the etcd member this phase would add
+func (p *etcdExecutor) DryRun(context.Context) ([]fsm.Action, error) {
+	return []fsm.Action{fsm.NewAction(fsm.ActionAddEtcdMember,
+		"add etcd member https://%v:%v", p.Phase.Data.Server.AdvertiseIP, defaults.EtcdPeerPort)}, nil
+}
+
 // Rollback removes the joined node from the cluster's etcd cluster
 func (p *etcdExecutor) Rollback(ctx context.Context) error {
 	p.Progress.NextStep("Restoring etcd data")
@@ -204,7 +211,8 @@ func (*etcdExecutor) PostCheck(ctx context.Context) error {
 func NewEtcdBackup(p fsm.ExecutorParams, operator ops.Operator, runner rpc.AgentRepository) (*etcdBackupExecutor, error) {
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
-			constants.FieldPhase: p.Phase.ID,
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 		}),
 		Key:      opKey(p.Plan),
 		Operator: operator,
@@ -255,6 +263,12 @@ func (p *etcdBackupExecutor) backupEtcd(ctx context.Context, agent rpcclient.Cli
 	return nil
 }
 
+// DryRun describes the etcd backup this phase would take
+func (p *etcdBackupExecutor) DryRun(context.Context) ([]fsm.Action, error) {
+	return []fsm.Action{fsm.NewAction(fsm.ActionBackup,
+		"back up etcd data to %v", getBackupPath(p.Plan.OperationID))}, nil
+}
+
 // Rollback is no-op for this phase
 func (*etcdBackupExecutor) Rollback(ctx context.Context) error {
 	return nil
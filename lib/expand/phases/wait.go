@@ -38,7 +38,8 @@ import (
 func NewWaitPlanet(p fsm.ExecutorParams, operator ops.Operator) (*waitPlanetExecutor, error) {
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
-			constants.FieldPhase: p.Phase.ID,
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 		}),
 		Key:      opKey(p.Plan),
 		Operator: operator,
@@ -87,6 +88,12 @@ func (p *waitPlanetExecutor) Execute(ctx context.Context) error {
 	return nil
 }
 
+// DryRun describes the condition this phase would wait for
+func (p *waitPlanetExecutor) DryRun(context.Context) ([]fsm.Action, error) {
+	return []fsm.Action{fsm.NewAction(fsm.ActionWait,
+		"wait for planet to become healthy on %v", p.Phase.Data.Server.AdvertiseIP)}, nil
+}
+
 // Rollback is no-op for this phase
 func (*waitPlanetExecutor) Rollback(ctx context.Context) error {
 	return nil
@@ -110,7 +117,8 @@ func NewWaitK8s(p fsm.ExecutorParams, operator ops.Operator) (*waitK8sExecutor,
 	}
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
-			constants.FieldPhase: p.Phase.ID,
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 		}),
 		Key:      opKey(p.Plan),
 		Operator: operator,
@@ -148,6 +156,12 @@ func (p *waitK8sExecutor) Execute(ctx context.Context) error {
 	return nil
 }
 
+// DryRun describes the condition this phase would wait for
+func (p *waitK8sExecutor) DryRun(context.Context) ([]fsm.Action, error) {
+	return []fsm.Action{fsm.NewAction(fsm.ActionWait,
+		"wait for Kubernetes node %v to register", p.Phase.Data.Server.KubeNodeID())}, nil
+}
+
 // Rollback is no-op for this phase
 func (*waitK8sExecutor) Rollback(ctx context.Context) error {
 	return nil
@@ -0,0 +1,188 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// DryRunner is implemented by phase executors that can describe
+// the actions they perform without executing them
+type DryRunner interface {
+	// DryRun returns the list of actions the phase would perform.
+	// It must not have side effects
+	DryRun(context.Context) ([]Action, error)
+}
+
+// Action describes a single action performed by an operation phase
+type Action struct {
+	// Phase is the ID of the phase that performs the action
+	Phase string `json:"phase"`
+	// Node is the name of the node the action is performed on
+	Node string `json:"node,omitempty"`
+	// Kind specifies the kind of the action
+	Kind string `json:"kind"`
+	// Description is the human-readable description of the action
+	Description string `json:"description"`
+}
+
+// String returns a textual representation of this action
+func (r Action) String() string {
+	node := r.Node
+	if node == "" {
+		node = "-"
+	}
+	return fmt.Sprintf("%v on %v: %v (%v)", r.Phase, node, r.Description, r.Kind)
+}
+
+// NewAction returns a new action of the specified kind with the description
+// given with format and args
+func NewAction(kind, format string, args ...interface{}) Action {
+	return Action{
+		Kind:        kind,
+		Description: fmt.Sprintf(format, args...),
+	}
+}
+
+const (
+	// ActionExecute is a generic action of executing a phase that
+	// cannot describe its actions in detail
+	ActionExecute = "execute"
+	// ActionPullPackage describes pulling a package
+	ActionPullPackage = "pull-package"
+	// ActionInstallPackage describes installing or updating a system package
+	ActionInstallPackage = "install-package"
+	// ActionRestartService describes (re)starting a systemd unit
+	ActionRestartService = "restart-service"
+	// ActionUpdateObject describes creating or updating a Kubernetes or cluster object
+	ActionUpdateObject = "update-object"
+	// ActionDrainNode describes draining a Kubernetes node
+	ActionDrainNode = "drain-node"
+	// ActionRunHook describes running an application hook
+	ActionRunHook = "run-hook"
+	// ActionAddEtcdMember describes adding a member to the etcd cluster
+	ActionAddEtcdMember = "add-etcd-member"
+	// ActionBackup describes backing up data
+	ActionBackup = "backup"
+	// ActionWait describes waiting for a condition to be met
+	ActionWait = "wait"
+)
+
+// DryRun returns the actions the specified phase would perform without
+// executing it.
+// If the phase is the root phase, the actions of the whole plan are returned.
+// Phases that have already completed are skipped unless p.Force is set
+func (f *FSM) DryRun(ctx context.Context, p Params) ([]Action, error) {
+	err := p.CheckAndSetDefaults()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	plan, err := f.GetPlan()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	phases := plan.Phases
+	if !p.IsResume() {
+		phase, err := FindPhase(plan, p.PhaseID)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		phases = []storage.OperationPhase{*phase}
+	}
+	var actions []Action
+	for _, phase := range phases {
+		phaseActions, err := f.dryRunPhase(ctx, p, *plan, phase)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		actions = append(actions, phaseActions...)
+	}
+	return actions, nil
+}
+
+func (f *FSM) dryRunPhase(ctx context.Context, p Params, plan storage.OperationPlan, phase storage.OperationPhase) (actions []Action, err error) {
+	if phase.IsCompleted() && !p.Force {
+		return nil, nil
+	}
+	if phase.Executor == "" && len(phase.Phases) != 0 {
+		for _, subphase := range phase.Phases {
+			subphaseActions, err := f.dryRunPhase(ctx, p, plan, subphase)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			actions = append(actions, subphaseActions...)
+		}
+		return actions, nil
+	}
+	executor, err := f.GetExecutor(ExecutorParams{
+		Plan:     plan,
+		Phase:    phase,
+		Progress: p.Progress,
+	}, f)
+	if err != nil {
+		f.WithFields(logrus.Fields{
+			logrus.ErrorKey: err,
+			"phase":         phase.ID,
+		}).Warn("Failed to create phase executor.")
+	}
+	dryRunner, ok := executor.(DryRunner)
+	if err != nil || !ok {
+		return []Action{{
+			Phase:       phase.ID,
+			Node:        dryRunNode(phase),
+			Kind:        ActionExecute,
+			Description: phase.Description,
+		}}, nil
+	}
+	actions, err = dryRunner.DryRun(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to describe phase %q", phase.ID)
+	}
+	for i := range actions {
+		if actions[i].Phase == "" {
+			actions[i].Phase = phase.ID
+		}
+		if actions[i].Node == "" {
+			actions[i].Node = dryRunNode(phase)
+		}
+	}
+	return actions, nil
+}
+
+// dryRunNode returns the name of the node the phase is executed on
+func dryRunNode(phase storage.OperationPhase) string {
+	if phase.Data == nil {
+		return ""
+	}
+	server := phase.Data.ExecServer
+	if server == nil {
+		server = phase.Data.Server
+	}
+	if server == nil {
+		return ""
+	}
+	if server.Hostname != "" {
+		return server.Hostname
+	}
+	return server.AdvertiseIP
+}
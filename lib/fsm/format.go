@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -80,7 +81,7 @@ func printPhase(w io.Writer, phase storage.OperationPhase, indent int) {
 		marker,
 		formatName(phase.ID),
 		phase.Description,
-		formatState(phase.GetState()),
+		formatPhaseState(phase),
 		formatNode(phase),
 		formatRequires(phase.Requires),
 		formatTimestamp(phase.GetLastUpdateTime()))
@@ -114,13 +115,81 @@ func printPhaseShort(w io.Writer, phase storage.OperationPhase, indent int) {
 		strings.Repeat("  ", indent),
 		marker,
 		formatName(phase.ID),
-		formatState(phase.GetState()),
+		formatPhaseState(phase),
 		formatTimestamp(phase.GetLastUpdateTime()))
 	for _, subPhase := range phase.Phases {
 		printPhaseShort(w, subPhase, indent+1)
 	}
 }
 
+// FormatDryRunText formats the actions recorded during a plan dry-run as text
+func FormatDryRunText(w io.Writer, actions []Action) {
+	if len(actions) == 0 {
+		fmt.Fprintln(w, "No actions would be performed.")
+		return
+	}
+	var t tabwriter.Writer
+	t.Init(w, 0, 10, 5, ' ', 0)
+	common.PrintTableHeader(&t, []string{"Phase", "Node", "Action", "Description"})
+	for _, action := range actions {
+		node := action.Node
+		if node == "" {
+			node = "-"
+		}
+		fmt.Fprintf(&t, "%v\t%v\t%v\t%v\n", action.Phase, node, action.Kind, action.Description)
+	}
+	t.Flush()
+}
+
+// FormatOperationPlanTiming formats the execution timing of the provided
+// operation plan as text.
+// Phases without subphases are listed from the slowest to the fastest
+func FormatOperationPlanTiming(w io.Writer, plan storage.OperationPlan) {
+	phases := FlattenPlan(&plan)
+	var leaves []storage.OperationPhase
+	for _, phase := range phases {
+		if !phase.HasSubphases() {
+			leaves = append(leaves, *phase)
+		}
+	}
+	sort.SliceStable(leaves, func(i, j int) bool {
+		return leaves[i].Duration() > leaves[j].Duration()
+	})
+	var t tabwriter.Writer
+	t.Init(w, 0, 10, 5, ' ', 0)
+	common.PrintTableHeader(&t, []string{"Phase", "State", "Node", "Attempts", "Started", "Duration"})
+	for _, phase := range leaves {
+		fmt.Fprintf(&t, "%v\t%v\t%v\t%v\t%v\t%v\n",
+			phase.ID,
+			formatState(phase.GetState()),
+			formatValue(phase.Node),
+			phase.Attempts,
+			formatTimestampSeconds(phase.GetStarted()),
+			formatDuration(phase.Duration()))
+	}
+	t.Flush()
+	var total storage.OperationPhase
+	total.Phases = plan.Phases
+	fmt.Fprintf(w, "\nTotal: %v\n", formatDuration(total.Duration()))
+}
+
+// FormatPhaseLogText formats the provided phase log as text
+func FormatPhaseLogText(w io.Writer, log storage.PhaseLog) {
+	if len(log) == 0 {
+		fmt.Fprintln(w, "No log entries recorded.")
+		return
+	}
+	for _, entry := range log {
+		fmt.Fprintf(w, "%v %-7v [%v] %v (attempt %v): %v\n",
+			entry.Created.Format(constants.HumanDateFormatMilli),
+			strings.ToUpper(entry.Level),
+			formatValue(entry.Node),
+			entry.PhaseID,
+			entry.Attempt,
+			entry.Message)
+	}
+}
+
 func formatNode(phase storage.OperationPhase) string {
 	if phase.Data == nil || phase.Data.ExecServer == nil {
 		return "-"
@@ -147,6 +216,37 @@ func formatTimestamp(t time.Time) string {
 	return t.Format(constants.HumanDateFormat)
 }
 
+func formatTimestampSeconds(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(constants.HumanDateFormatSeconds)
+}
+
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(time.Second).String()
+}
+
+func formatValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// formatPhaseState formats the state of the phase along with the number
+// of attempts if the phase has been executed more than once
+func formatPhaseState(phase storage.OperationPhase) string {
+	state := formatState(phase.GetState())
+	if len(phase.Phases) == 0 && phase.Attempts > 1 {
+		return fmt.Sprintf("%v (%v attempts)", state, phase.Attempts)
+	}
+	return state
+}
+
 func formatState(state string) string {
 	switch state {
 	case storage.OperationPhaseStateUnstarted:
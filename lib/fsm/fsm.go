@@ -19,7 +19,9 @@ package fsm
 import (
 	"context"
 	"fmt"
+	"os"
 	"path"
+	"sync"
 
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/rpc"
@@ -49,6 +51,14 @@ type Engine interface {
 	Complete(error) error
 }
 
+// RemoteRollbacker is implemented by engines that can roll back
+// a phase on a remote server
+type RemoteRollbacker interface {
+	// RunRollbackCommand rolls back the phase specified by params on the specified
+	// server using the provided runner
+	RunRollbackCommand(context.Context, rpc.RemoteRunner, storage.Server, Params) error
+}
+
 // ExecutorParams combines parameters needed for creating a new executor
 type ExecutorParams struct {
 	// Plan is the operation plan
@@ -113,6 +123,11 @@ type FSM struct {
 	preExecFn PhaseHookFn
 	// postExecFn is called after phase execution if set
 	postExecFn PhaseHookFn
+	// slots limits the number of concurrently executing leaf phases
+	slots     chan struct{}
+	slotsOnce sync.Once
+	// hostname is the name of the node this FSM executes phases on
+	hostname string
 }
 
 // PhaseHookFn defines the phase hook function
@@ -128,6 +143,11 @@ type Config struct {
 	Insecure bool
 	// Logger allows to override default logger
 	Logger logrus.FieldLogger
+	// ParallelPhases is the maximum number of phases executed concurrently.
+	// If set, phases are scheduled based on their requirements and
+	// independent phases are executed in parallel. Otherwise, phases
+	// are executed in plan order unless marked as parallel
+	ParallelPhases int
 }
 
 // CheckAndSetDefaults makes sure the config is valid and sets some defaults
@@ -147,9 +167,14 @@ func New(config Config) (*FSM, error) {
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		config.Logger.WithError(err).Warn("Failed to determine hostname.")
+	}
 	return &FSM{
 		Config:      config,
 		FieldLogger: config.Logger,
+		hostname:    hostname,
 	}, nil
 }
 
@@ -159,6 +184,12 @@ func (f *FSM) ExecutePlan(ctx context.Context, progress utils.Progress) error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	if f.ParallelPhases > 0 {
+		return trace.Wrap(f.executeGraph(ctx, Params{
+			Progress: progress,
+			Resume:   true,
+		}, plan.Phases))
+	}
 	for _, phase := range plan.Phases {
 		f.Debugf("Executing phase %q.", phase.ID)
 		err := f.ExecutePhase(ctx, Params{
@@ -199,6 +230,13 @@ func (f *FSM) ExecutePhase(ctx context.Context, p Params) error {
 	if err != nil && !p.Force {
 		return trace.Wrap(err)
 	}
+	if !phase.HasSubphases() {
+		release, err := f.acquirePhaseSlot(ctx)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		defer release()
+	}
 	if f.preExecFn != nil {
 		if err := f.preExecFn(ctx, p); err != nil {
 			return trace.Wrap(err)
@@ -256,6 +294,10 @@ func (f *FSM) RollbackPhase(ctx context.Context, p Params) error {
 			if err != nil {
 				return trace.Wrap(err)
 			}
+			rollbacker, ok := f.Engine.(RemoteRollbacker)
+			if execWhere == CanRunRemotely && ok {
+				return trace.Wrap(f.rollbackPhaseRemotely(ctx, p, *phase, *execServer, rollbacker))
+			}
 			if execWhere != CanRunLocally {
 				return trace.BadParameter("rollback phase %v must be run from server %v", p.PhaseID, execServer.Hostname)
 			}
@@ -291,6 +333,9 @@ func (f *FSM) ChangePhaseState(ctx context.Context, change StateChange) error {
 	if _, err := FindPhase(plan, change.Phase); err != nil {
 		return trace.Wrap(err)
 	}
+	if change.Node == "" {
+		change.Node = f.hostname
+	}
 	return f.Engine.ChangePhaseState(ctx, change)
 }
 
@@ -392,6 +437,9 @@ func (f *FSM) executePhaseLocally(ctx context.Context, p Params, phase storage.O
 }
 
 func (f *FSM) executeSubphasesSequentially(ctx context.Context, p Params, phase storage.OperationPhase) error {
+	if f.ParallelPhases > 0 {
+		return trace.Wrap(f.executeGraph(ctx, p, phase.Phases))
+	}
 	for _, subphase := range phase.Phases {
 		p.PhaseID = subphase.ID
 		err := f.ExecutePhase(ctx, p)
@@ -440,47 +488,83 @@ func (f *FSM) executeOnePhase(ctx context.Context, p Params, phase storage.Opera
 		return trace.Wrap(err)
 	}
 
+	policy, retry := retryPolicy(executor)
+	attempt := 1
+	var stopCapture func(context.Context)
+	for {
+		stopCapture, err = f.executeAttempt(ctx, *plan, phase, executor, phase.Attempts+attempt)
+		if err == nil {
+			break
+		}
+		if !retry {
+			return trace.Wrap(err)
+		}
+		interval, ok := policy.NextInterval(attempt, err)
+		if !ok {
+			return trace.Wrap(err)
+		}
+		executor.Warnf("Phase %v failed on attempt %v of %v, will retry in %v.",
+			phase.ID, attempt, policy.MaxAttempts, interval)
+		if errWait := waitRetry(ctx, interval); errWait != nil {
+			return trace.Wrap(err)
+		}
+		attempt++
+	}
+	defer stopCapture(ctx)
+
+	err = executor.PostCheck(ctx)
+	if err != nil {
+		executor.Errorf("Phase postcheck failed: %v.", err)
+		return trace.Wrap(err)
+	}
+
+	stopCapture(ctx)
 	err = f.ChangePhaseState(ctx,
 		StateChange{
 			Phase: phase.ID,
-			State: storage.OperationPhaseStateInProgress,
+			State: storage.OperationPhaseStateCompleted,
 		})
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
+	return nil
+}
+
+// executeAttempt marks the phase as in progress and executes it.
+// If the execution fails, the phase is marked as failed.
+// Upon success, returns the function that stops capturing the phase log
+func (f *FSM) executeAttempt(ctx context.Context, plan storage.OperationPlan, phase storage.OperationPhase, executor PhaseExecutor, attempt int) (stopCapture func(context.Context), err error) {
+	err = f.ChangePhaseState(ctx,
+		StateChange{
+			Phase: phase.ID,
+			State: storage.OperationPhaseStateInProgress,
+		})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	// Captured log is recorded before the final state change
+	// so it is synchronized along with the state change
+	stopCapture = f.capturePhaseLog(plan, phase, attempt)
+
 	executor.Infof("Executing phase: %v.", phase.ID)
 
 	err = executor.Execute(ctx)
 	if err != nil {
 		executor.Errorf("Phase execution failed: %v.", err)
+		stopCapture(ctx)
 		if err := f.ChangePhaseState(ctx,
 			StateChange{
 				Phase: phase.ID,
 				State: storage.OperationPhaseStateFailed,
 				Error: trace.Wrap(err),
 			}); err != nil {
-			return trace.Wrap(err)
+			return nil, trace.Wrap(err)
 		}
-		return trace.Wrap(err)
-	}
-
-	err = executor.PostCheck(ctx)
-	if err != nil {
-		executor.Errorf("Phase postcheck failed: %v.", err)
-		return trace.Wrap(err)
-	}
-
-	err = f.ChangePhaseState(ctx,
-		StateChange{
-			Phase: phase.ID,
-			State: storage.OperationPhaseStateCompleted,
-		})
-	if err != nil {
-		return trace.Wrap(err)
+		return nil, trace.Wrap(err)
 	}
-
-	return nil
+	return stopCapture, nil
 }
 
 func (f *FSM) rollbackPhase(ctx context.Context, p Params, phase storage.OperationPhase) error {
@@ -532,6 +616,21 @@ func (f *FSM) rollbackPhase(ctx context.Context, p Params, phase storage.Operati
 	return nil
 }
 
+// rollbackPhaseRemotely rolls back the specified operation phase on the specified server
+func (f *FSM) rollbackPhaseRemotely(ctx context.Context, p Params, phase storage.OperationPhase, server storage.Server, rollbacker RemoteRollbacker) error {
+	p.Progress.NextStep("Rolling back %q on remote node %v", phase.ID, server.Hostname)
+	err := rollbacker.RunRollbackCommand(ctx, f.Runner, server, p)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// mark the phase rolled back in the local database as well since
+	// the changes made remotely might not have been synchronized back
+	return trace.Wrap(f.ChangePhaseState(ctx, StateChange{
+		Phase: phase.ID,
+		State: storage.OperationPhaseStateRolledBack,
+	}))
+}
+
 // prerequisitesComplete checks if specified phase can be executed in the
 // provided plan
 func (f *FSM) prerequisitesComplete(phaseID string) error {
@@ -566,6 +665,8 @@ type StateChange struct {
 	State string
 	// Error is the error that happened during phase execution
 	Error trace.Error
+	// Node is the name of the node the state change is made on
+	Node string
 }
 
 // Check verifies that state change is valid.
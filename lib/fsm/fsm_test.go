@@ -0,0 +1,551 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/rpc"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/check.v1"
+)
+
+func TestFSM(t *testing.T) { check.TestingT(t) }
+
+type ScheduleSuite struct{}
+
+var _ = check.Suite(&ScheduleSuite{})
+
+func (s *ScheduleSuite) TestExecutesIndependentPhasesConcurrently(c *check.C) {
+	engine := newTestEngine(storage.OperationPlan{
+		Phases: []storage.OperationPhase{
+			{ID: "/a"},
+			{ID: "/b"},
+			{ID: "/c"},
+			{ID: "/d"},
+		},
+	})
+	fsm := newTestFSM(c, engine, 2)
+
+	err := fsm.ExecutePlan(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+
+	c.Assert(engine.maxRunning, check.Equals, 2)
+	engine.checkCompleted(c, "/a", "/b", "/c", "/d")
+}
+
+func (s *ScheduleSuite) TestExecutesPhasesInOrderOfRequirements(c *check.C) {
+	engine := newTestEngine(storage.OperationPlan{
+		Phases: []storage.OperationPhase{
+			{ID: "/a"},
+			{ID: "/b", Requires: []string{"/a"}},
+			{ID: "/c"},
+			{ID: "/d", Phases: []storage.OperationPhase{
+				{ID: "/d/b", Requires: []string{"/b"}},
+				{ID: "/d/c", Requires: []string{"/c/sub"}},
+			}},
+		},
+	})
+	fsm := newTestFSM(c, engine, 4)
+
+	err := fsm.ExecutePlan(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+
+	engine.checkCompleted(c, "/a", "/b", "/c", "/d/b", "/d/c")
+	engine.checkOrder(c, "/a", "/b")
+	engine.checkOrder(c, "/b", "/d/b")
+	engine.checkOrder(c, "/c", "/d/b")
+}
+
+func (s *ScheduleSuite) TestStopsSchedulingAfterFailure(c *check.C) {
+	engine := newTestEngine(storage.OperationPlan{
+		Phases: []storage.OperationPhase{
+			{ID: "/a"},
+			{ID: "/b", Requires: []string{"/a"}},
+			{ID: "/c", Requires: []string{"/b"}},
+		},
+	})
+	engine.failing["/b"] = true
+	fsm := newTestFSM(c, engine, 2)
+
+	err := fsm.ExecutePlan(context.TODO(), nil)
+	c.Assert(err, check.NotNil)
+
+	c.Assert(engine.states["/a"], check.Equals, storage.OperationPhaseStateCompleted)
+	c.Assert(engine.states["/b"], check.Equals, storage.OperationPhaseStateFailed)
+	c.Assert(engine.states["/c"], check.Equals, "")
+}
+
+func (s *ScheduleSuite) TestFailsOnCircularRequirements(c *check.C) {
+	engine := newTestEngine(storage.OperationPlan{
+		Phases: []storage.OperationPhase{
+			{ID: "/a"},
+			{ID: "/b", Requires: []string{"/c"}},
+			{ID: "/c", Requires: []string{"/b"}},
+		},
+	})
+	fsm := newTestFSM(c, engine, 2)
+
+	err := fsm.ExecutePlan(context.TODO(), nil)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf("%v", err))
+	c.Assert(engine.states["/a"], check.Equals, storage.OperationPhaseStateCompleted)
+}
+
+func (s *ScheduleSuite) TestSiblingDependencies(c *check.C) {
+	phases := []storage.OperationPhase{
+		{ID: "/masters", Phases: []storage.OperationPhase{
+			{ID: "/masters/node-1"},
+		}},
+		{ID: "/nodes", Phases: []storage.OperationPhase{
+			{ID: "/nodes/node-2", Requires: []string{"/masters/node-1"}},
+		}},
+		{ID: "/mastersfinal", Requires: []string{"/init"}},
+	}
+	c.Assert(siblingDependencies(phases), check.DeepEquals, [][]int{nil, {0}, nil})
+}
+
+type DryRunSuite struct{}
+
+var _ = check.Suite(&DryRunSuite{})
+
+func (s *DryRunSuite) TestDescribesPlanWithoutExecuting(c *check.C) {
+	engine := newTestEngine(storage.OperationPlan{
+		Phases: []storage.OperationPhase{
+			{ID: "/init", Description: "Initialize operation"},
+			{ID: "/drain", Description: "Drain node-1", Data: &storage.OperationPhaseData{
+				Server: &storage.Server{Hostname: "node-1", AdvertiseIP: "192.168.1.1"},
+			}},
+			{ID: "/masters", Phases: []storage.OperationPhase{
+				{ID: "/masters/node-1", Description: "Update node-1"},
+			}},
+		},
+	})
+	engine.states["/init"] = storage.OperationPhaseStateCompleted
+	engine.actions["/drain"] = []Action{NewAction(ActionDrainNode, "drain node %v", "node-1")}
+	fsm := newTestFSM(c, engine, 0)
+
+	actions, err := fsm.DryRun(context.TODO(), Params{PhaseID: RootPhase})
+	c.Assert(err, check.IsNil)
+	c.Assert(actions, check.DeepEquals, []Action{
+		{Phase: "/drain", Node: "node-1", Kind: ActionDrainNode, Description: "drain node node-1"},
+		{Phase: "/masters/node-1", Kind: ActionExecute, Description: "Update node-1"},
+	})
+	c.Assert(engine.completed, check.HasLen, 0)
+	c.Assert(engine.executed, check.HasLen, 0)
+}
+
+func (s *DryRunSuite) TestDescribesCompletedPhaseWithForce(c *check.C) {
+	engine := newTestEngine(storage.OperationPlan{
+		Phases: []storage.OperationPhase{
+			{ID: "/init", Description: "Initialize operation"},
+			{ID: "/update", Description: "Update node"},
+		},
+	})
+	engine.states["/init"] = storage.OperationPhaseStateCompleted
+	fsm := newTestFSM(c, engine, 0)
+
+	actions, err := fsm.DryRun(context.TODO(), Params{PhaseID: "/init"})
+	c.Assert(err, check.IsNil)
+	c.Assert(actions, check.HasLen, 0)
+
+	actions, err = fsm.DryRun(context.TODO(), Params{PhaseID: "/init", Force: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(actions, check.DeepEquals, []Action{
+		{Phase: "/init", Kind: ActionExecute, Description: "Initialize operation"},
+	})
+}
+
+type PhaseLogSuite struct{}
+
+var _ = check.Suite(&PhaseLogSuite{})
+
+func (s *PhaseLogSuite) TestCapturesPhaseLog(c *check.C) {
+	engine := newTestEngine(storage.OperationPlan{
+		ClusterName: "example.com",
+		OperationID: "1",
+		Phases: []storage.OperationPhase{
+			{ID: "/a"},
+			{ID: "/b"},
+		},
+	})
+	fsm := newTestFSM(c, engine, 0)
+
+	err := fsm.ExecutePlan(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+
+	log := engine.log.ForPhase("/a")
+	c.Assert(log, check.HasLen, 1)
+	c.Assert(log[0].Message, check.Equals, "Executing phase: /a.")
+	c.Assert(log[0].ClusterName, check.Equals, "example.com")
+	c.Assert(log[0].OperationID, check.Equals, "1")
+	c.Assert(log[0].Node, check.Equals, fsm.hostname)
+	c.Assert(log[0].Attempt, check.Equals, 1)
+	c.Assert(engine.log.ForPhase("/b"), check.HasLen, 1)
+	c.Assert(engine.nodes["/a"], check.Equals, fsm.hostname)
+}
+
+func (s *PhaseLogSuite) TestSeparatesPhaseLogsByOperation(c *check.C) {
+	phase := storage.OperationPhase{ID: "/a"}
+	var engines []*testEngine
+	var stops []func(context.Context)
+	// both operations capture the log of the phase with the same ID at the same time
+	for _, operationID := range []string{"1", "2"} {
+		engine := newTestEngine(storage.OperationPlan{
+			ClusterName: "example.com",
+			OperationID: operationID,
+			Phases:      []storage.OperationPhase{phase},
+		})
+		fsm := newTestFSM(c, engine, 0)
+		engines = append(engines, engine)
+		stops = append(stops, fsm.capturePhaseLog(engine.plan, phase, 1))
+	}
+	for _, engine := range engines {
+		logrus.WithFields(logrus.Fields{
+			constants.FieldPhase:       phase.ID,
+			constants.FieldOperationID: engine.plan.OperationID,
+		}).Infof("Operation %v.", engine.plan.OperationID)
+	}
+	for _, stop := range stops {
+		stop(context.TODO())
+	}
+
+	for _, engine := range engines {
+		log := engine.log.ForPhase(phase.ID)
+		c.Assert(log, check.HasLen, 1)
+		c.Assert(log[0].OperationID, check.Equals, engine.plan.OperationID)
+		c.Assert(log[0].Message, check.Equals, fmt.Sprintf("Operation %v.", engine.plan.OperationID))
+	}
+}
+
+func (s *PhaseLogSuite) TestResolvesPhaseTiming(c *check.C) {
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	plan := storage.OperationPlan{
+		Phases: []storage.OperationPhase{
+			{ID: "/a"},
+			{ID: "/b", Phases: []storage.OperationPhase{
+				{ID: "/b/1"},
+				{ID: "/b/2"},
+			}},
+		},
+	}
+	changelog := storage.PlanChangelog{
+		{PhaseID: "/a", NewState: storage.OperationPhaseStateInProgress, Node: "node-1", Created: start},
+		{PhaseID: "/a", NewState: storage.OperationPhaseStateFailed, Node: "node-1", Created: start.Add(time.Minute)},
+		{PhaseID: "/a", NewState: storage.OperationPhaseStateCompleted, Node: "node-2", Created: start.Add(5 * time.Minute)},
+		{PhaseID: "/a", NewState: storage.OperationPhaseStateInProgress, Node: "node-2", Created: start.Add(3 * time.Minute)},
+		{PhaseID: "/b/1", NewState: storage.OperationPhaseStateInProgress, Created: start.Add(5 * time.Minute)},
+		{PhaseID: "/b/1", NewState: storage.OperationPhaseStateCompleted, Created: start.Add(6 * time.Minute)},
+		{PhaseID: "/b/2", NewState: storage.OperationPhaseStateInProgress, Created: start.Add(6 * time.Minute)},
+	}
+
+	resolved := ResolvePlan(plan, changelog)
+	a := resolved.Phases[0]
+	c.Assert(a.Attempts, check.Equals, 2)
+	c.Assert(a.Node, check.Equals, "node-2")
+	c.Assert(a.Started, check.Equals, start.Add(3*time.Minute))
+	c.Assert(a.Duration(), check.Equals, 2*time.Minute)
+
+	b := resolved.Phases[1]
+	c.Assert(b.GetStarted(), check.Equals, start.Add(5*time.Minute))
+	c.Assert(b.GetFinished().IsZero(), check.Equals, true)
+	c.Assert(b.Duration(), check.Equals, time.Duration(0))
+	c.Assert(b.Phases[0].Duration(), check.Equals, time.Minute)
+}
+
+type RetrySuite struct{}
+
+var _ = check.Suite(&RetrySuite{})
+
+func (s *RetrySuite) TestRetriesTransientFailures(c *check.C) {
+	engine := newTestEngine(storage.OperationPlan{
+		Phases: []storage.OperationPhase{{ID: "/a"}},
+	})
+	engine.policies["/a"] = RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond}
+	engine.transient["/a"] = 2
+	fsm := newTestFSM(c, engine, 0)
+
+	err := fsm.ExecutePlan(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	engine.checkCompleted(c, "/a")
+	c.Assert(engine.executed, check.DeepEquals, []string{"/a", "/a", "/a"})
+	c.Assert(engine.changes["/a"], check.DeepEquals, []string{
+		storage.OperationPhaseStateInProgress, storage.OperationPhaseStateFailed,
+		storage.OperationPhaseStateInProgress, storage.OperationPhaseStateFailed,
+		storage.OperationPhaseStateInProgress, storage.OperationPhaseStateCompleted,
+	})
+	log := engine.log.ForPhase("/a")
+	c.Assert(log[len(log)-1].Attempt, check.Equals, 3)
+}
+
+func (s *RetrySuite) TestGivesUpAfterMaxAttempts(c *check.C) {
+	engine := newTestEngine(storage.OperationPlan{
+		Phases: []storage.OperationPhase{{ID: "/a"}},
+	})
+	engine.policies["/a"] = RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond}
+	engine.transient["/a"] = 5
+	fsm := newTestFSM(c, engine, 0)
+
+	err := fsm.ExecutePlan(context.TODO(), nil)
+	c.Assert(trace.IsConnectionProblem(err), check.Equals, true)
+	c.Assert(engine.executed, check.DeepEquals, []string{"/a", "/a"})
+	c.Assert(engine.states["/a"], check.Equals, storage.OperationPhaseStateFailed)
+}
+
+func (s *RetrySuite) TestDoesNotRetryPermanentFailures(c *check.C) {
+	engine := newTestEngine(storage.OperationPlan{
+		Phases: []storage.OperationPhase{{ID: "/a"}, {ID: "/b"}},
+	})
+	engine.policies["/a"] = RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond}
+	engine.failing["/a"] = true
+	engine.transient["/b"] = 1
+	fsm := newTestFSM(c, engine, 0)
+
+	err := fsm.ExecutePhase(context.TODO(), Params{PhaseID: "/a"})
+	c.Assert(err, check.NotNil)
+	// phase without a retry policy is not retried either
+	err = fsm.ExecutePhase(context.TODO(), Params{PhaseID: "/b"})
+	c.Assert(err, check.NotNil)
+	c.Assert(engine.executed, check.DeepEquals, []string{"/a", "/b"})
+}
+
+func (s *RetrySuite) TestRetryIntervals(c *check.C) {
+	policy := RetryPolicy{
+		MaxAttempts:     5,
+		InitialInterval: time.Second,
+		MaxInterval:     3 * time.Second,
+	}
+	c.Assert(policy.CheckAndSetDefaults(), check.IsNil)
+	transient := trace.ConnectionProblem(nil, "connection refused")
+
+	var intervals []time.Duration
+	for attempt := 1; ; attempt++ {
+		interval, ok := policy.NextInterval(attempt, transient)
+		if !ok {
+			break
+		}
+		intervals = append(intervals, interval)
+	}
+	c.Assert(intervals, check.DeepEquals, []time.Duration{
+		time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second,
+	})
+
+	_, ok := policy.NextInterval(1, trace.BadParameter("bad parameter"))
+	c.Assert(ok, check.Equals, false)
+	_, ok = policy.NextInterval(1, context.Canceled)
+	c.Assert(ok, check.Equals, false)
+}
+
+func newTestFSM(c *check.C, engine *testEngine, parallelPhases int) *FSM {
+	fsm, err := New(Config{
+		Engine:         engine,
+		ParallelPhases: parallelPhases,
+	})
+	c.Assert(err, check.IsNil)
+	return fsm
+}
+
+func newTestEngine(plan storage.OperationPlan) *testEngine {
+	return &testEngine{
+		plan:      plan,
+		states:    make(map[string]string),
+		failing:   make(map[string]bool),
+		actions:   make(map[string][]Action),
+		nodes:     make(map[string]string),
+		changes:   make(map[string][]string),
+		transient: make(map[string]int),
+		policies:  make(map[string]RetryPolicy),
+	}
+}
+
+// testEngine is an in-memory engine that records the order
+// and concurrency of phase execution
+type testEngine struct {
+	sync.Mutex
+	plan    storage.OperationPlan
+	states  map[string]string
+	failing map[string]bool
+	actions map[string][]Action
+	nodes   map[string]string
+	changes map[string][]string
+	// transient maps phases to the number of times they fail
+	// with a transient error before succeeding
+	transient  map[string]int
+	policies   map[string]RetryPolicy
+	log        storage.PhaseLog
+	completed  []string
+	executed   []string
+	running    int
+	maxRunning int
+}
+
+func (e *testEngine) GetExecutor(p ExecutorParams, remote Remote) (PhaseExecutor, error) {
+	executor := &testExecutor{
+		FieldLogger: logrus.WithFields(logrus.Fields{
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
+		}),
+		engine: e,
+		phase:  p.Phase.ID,
+	}
+	if policy, ok := e.policies[p.Phase.ID]; ok {
+		return &testRetryExecutor{
+			testExecutor: executor,
+			policy:       policy,
+		}, nil
+	}
+	if actions, ok := e.actions[p.Phase.ID]; ok {
+		return &testDryRunExecutor{
+			testExecutor: executor,
+			actions:      actions,
+		}, nil
+	}
+	return executor, nil
+}
+
+func (e *testEngine) ChangePhaseState(ctx context.Context, change StateChange) error {
+	e.Lock()
+	defer e.Unlock()
+	e.states[change.Phase] = change.State
+	e.nodes[change.Phase] = change.Node
+	e.changes[change.Phase] = append(e.changes[change.Phase], change.State)
+	if change.State == storage.OperationPhaseStateCompleted {
+		e.completed = append(e.completed, change.Phase)
+	}
+	return nil
+}
+
+func (e *testEngine) GetPlan() (*storage.OperationPlan, error) {
+	e.Lock()
+	defer e.Unlock()
+	plan := e.plan
+	plan.Phases = e.resolvePhases(e.plan.Phases)
+	return &plan, nil
+}
+
+func (e *testEngine) RecordPhaseLog(ctx context.Context, log storage.PhaseLog) error {
+	e.Lock()
+	defer e.Unlock()
+	e.log = append(e.log, log...)
+	return nil
+}
+
+func (e *testEngine) RunCommand(context.Context, rpc.RemoteRunner, storage.Server, Params) error {
+	return trace.NotImplemented("remote execution is not supported")
+}
+
+func (e *testEngine) Complete(error) error {
+	return nil
+}
+
+func (e *testEngine) resolvePhases(phases []storage.OperationPhase) (result []storage.OperationPhase) {
+	for _, phase := range phases {
+		phase.State = e.states[phase.ID]
+		phase.Phases = e.resolvePhases(phase.Phases)
+		result = append(result, phase)
+	}
+	return result
+}
+
+func (e *testEngine) checkCompleted(c *check.C, phases ...string) {
+	for _, phase := range phases {
+		c.Assert(e.states[phase], check.Equals, storage.OperationPhaseStateCompleted,
+			check.Commentf("phase %v", phase))
+	}
+}
+
+// checkOrder verifies that the phase first has completed before the phase next
+func (e *testEngine) checkOrder(c *check.C, first, next string) {
+	index := func(phase string) int {
+		for i, completed := range e.completed {
+			if completed == phase {
+				return i
+			}
+		}
+		return -1
+	}
+	c.Assert(index(first) < index(next), check.Equals, true,
+		check.Commentf("expected %v to complete before %v: %v", first, next, e.completed))
+}
+
+type testExecutor struct {
+	logrus.FieldLogger
+	engine *testEngine
+	phase  string
+}
+
+func (e *testExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+func (e *testExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+func (e *testExecutor) Execute(context.Context) error {
+	e.engine.Lock()
+	e.engine.executed = append(e.engine.executed, e.phase)
+	e.engine.running++
+	if e.engine.running > e.engine.maxRunning {
+		e.engine.maxRunning = e.engine.running
+	}
+	e.engine.Unlock()
+	// give the concurrently scheduled phases a chance to overlap
+	time.Sleep(20 * time.Millisecond)
+	e.engine.Lock()
+	defer e.engine.Unlock()
+	e.engine.running--
+	if e.engine.failing[e.phase] {
+		return trace.BadParameter("phase %v failed", e.phase)
+	}
+	if e.engine.transient[e.phase] > 0 {
+		e.engine.transient[e.phase]--
+		return trace.ConnectionProblem(nil, "phase %v failed", e.phase)
+	}
+	return nil
+}
+
+func (e *testExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// testDryRunExecutor is a test executor that describes its actions
+type testDryRunExecutor struct {
+	*testExecutor
+	actions []Action
+}
+
+func (e *testDryRunExecutor) DryRun(context.Context) ([]Action, error) {
+	return e.actions, nil
+}
+
+// testRetryExecutor is a test executor with a retry policy
+type testRetryExecutor struct {
+	*testExecutor
+	policy RetryPolicy
+}
+
+func (e *testRetryExecutor) RetryPolicy() RetryPolicy {
+	return e.policy
+}
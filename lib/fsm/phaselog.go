@@ -0,0 +1,195 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/pborman/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// PhaseLogRecorder is implemented by engines that persist the log
+// entries captured during phase execution
+type PhaseLogRecorder interface {
+	// RecordPhaseLog saves the specified phase log entries
+	RecordPhaseLog(context.Context, storage.PhaseLog) error
+}
+
+// capturePhaseLog starts capturing the log entries the executor of the
+// specified phase logs with the operation and phase fields set during
+// the given attempt.
+// Returns the function that stops the capture and records the captured
+// entries if the engine supports it. The returned function is safe
+// to call multiple times
+func (f *FSM) capturePhaseLog(plan storage.OperationPlan, phase storage.OperationPhase, attempt int) (stop func(context.Context)) {
+	recorder, ok := f.Engine.(PhaseLogRecorder)
+	if !ok {
+		return func(context.Context) {}
+	}
+	phaseLogHookOnce.Do(func() {
+		logrus.AddHook(phaseLogs)
+	})
+	buffer := &phaseLogBuffer{
+		template: storage.PhaseLogEntry{
+			ClusterName: plan.ClusterName,
+			OperationID: plan.OperationID,
+			PhaseID:     phase.ID,
+			Node:        f.hostname,
+			Attempt:     attempt,
+		},
+	}
+	key := phaseLogKey{operationID: plan.OperationID, phaseID: phase.ID}
+	phaseLogs.add(key, buffer)
+	var once sync.Once
+	return func(ctx context.Context) {
+		once.Do(func() {
+			phaseLogs.remove(key)
+			err := recorder.RecordPhaseLog(ctx, buffer.entries())
+			if err != nil {
+				f.WithError(err).Warnf("Failed to record log of phase %v.", phase.ID)
+			}
+		})
+	}
+}
+
+// phaseLogHook is a logrus hook that captures the log entries
+// of the phases being executed
+type phaseLogHook struct {
+	sync.Mutex
+	// buffers maps the phases being executed to their logs.
+	// Phases are keyed by operation as plans of several operations
+	// might be executed by the same process
+	buffers map[phaseLogKey]*phaseLogBuffer
+}
+
+// phaseLogKey identifies a phase of a specific operation
+type phaseLogKey struct {
+	operationID string
+	phaseID     string
+}
+
+// Levels returns the log levels captured by this hook
+func (h *phaseLogHook) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.PanicLevel,
+		logrus.FatalLevel,
+		logrus.ErrorLevel,
+		logrus.WarnLevel,
+		logrus.InfoLevel,
+	}
+}
+
+// Fire adds the entry to the log of the phase it has been logged for.
+// Entries without both the operation and the phase fields are ignored.
+// It is invoked with the logger lock held so it must not log itself
+// or perform any blocking I/O
+func (h *phaseLogHook) Fire(entry *logrus.Entry) error {
+	operationID, ok := entry.Data[constants.FieldOperationID].(string)
+	if !ok {
+		return nil
+	}
+	phaseID, ok := entry.Data[constants.FieldPhase].(string)
+	if !ok {
+		return nil
+	}
+	h.Lock()
+	buffer := h.buffers[phaseLogKey{operationID: operationID, phaseID: phaseID}]
+	h.Unlock()
+	if buffer != nil {
+		buffer.add(entry)
+	}
+	return nil
+}
+
+func (h *phaseLogHook) add(key phaseLogKey, buffer *phaseLogBuffer) {
+	h.Lock()
+	defer h.Unlock()
+	h.buffers[key] = buffer
+}
+
+func (h *phaseLogHook) remove(key phaseLogKey) {
+	h.Lock()
+	defer h.Unlock()
+	delete(h.buffers, key)
+}
+
+// phaseLogBuffer accumulates the log entries of a single phase
+type phaseLogBuffer struct {
+	sync.Mutex
+	// template is the entry with the phase attributes set
+	template storage.PhaseLogEntry
+	log      storage.PhaseLog
+	// dropped is the number of entries dropped after the log
+	// has reached the maximum size
+	dropped int
+}
+
+func (b *phaseLogBuffer) add(entry *logrus.Entry) {
+	b.Lock()
+	defer b.Unlock()
+	if len(b.log) >= defaults.PhaseLogMaxEntries {
+		b.dropped++
+		return
+	}
+	message := entry.Message
+	if err, ok := entry.Data[logrus.ErrorKey]; ok {
+		message = fmt.Sprintf("%v error: %v", message, err)
+	}
+	if len(message) > defaults.PhaseLogMaxMessageSize {
+		message = message[:defaults.PhaseLogMaxMessageSize] + "..."
+	}
+	logEntry := b.template
+	logEntry.ID = uuid.New()
+	logEntry.Created = entry.Time.UTC()
+	logEntry.Level = entry.Level.String()
+	logEntry.Message = message
+	b.log = append(b.log, logEntry)
+}
+
+// entries returns the captured entries
+func (b *phaseLogBuffer) entries() storage.PhaseLog {
+	b.Lock()
+	defer b.Unlock()
+	if b.dropped == 0 || len(b.log) == 0 {
+		return b.log
+	}
+	last := b.log[len(b.log)-1]
+	last.ID = uuid.New()
+	last.Level = logrus.WarnLevel.String()
+	last.Message = fmt.Sprintf("%v more log entries have been omitted.", b.dropped)
+	return append(b.log, last)
+}
+
+var (
+	phaseLogs = &phaseLogHook{
+		buffers: make(map[phaseLogKey]*phaseLogBuffer),
+	}
+	phaseLogHookOnce sync.Once
+)
+
+// RecordPhaseLog saves the specified phase log entries in the backend
+func RecordPhaseLog(backend storage.Backend, log storage.PhaseLog) error {
+	return trace.Wrap(backend.CreateOperationPhaseLogEntries(log))
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsm
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// Retrier is implemented by phase executors that should be automatically
+// retried after failing with a retryable error
+type Retrier interface {
+	// RetryPolicy returns the policy to retry the phase with
+	RetryPolicy() RetryPolicy
+}
+
+// RetryPolicy defines how a failed phase is retried
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts to execute the phase,
+	// including the first one
+	MaxAttempts int
+	// InitialInterval is the delay before the first retry
+	InitialInterval time.Duration
+	// MaxInterval is the maximum delay between retries
+	MaxInterval time.Duration
+	// Multiplier is the factor the delay grows by after each retry
+	Multiplier float64
+	// Retryable lists the classes of errors the phase is retried on
+	Retryable []ErrorClass
+}
+
+// CheckAndSetDefaults validates the policy and sets defaults
+func (r *RetryPolicy) CheckAndSetDefaults() error {
+	if r.MaxAttempts < 0 {
+		return trace.BadParameter("max attempts cannot be negative")
+	}
+	if r.Multiplier != 0 && r.Multiplier < 1 {
+		return trace.BadParameter("multiplier cannot be less than 1")
+	}
+	if r.MaxAttempts == 0 {
+		r.MaxAttempts = defaults.PhaseRetryAttempts
+	}
+	if r.InitialInterval == 0 {
+		r.InitialInterval = defaults.PhaseRetryInitialInterval
+	}
+	if r.MaxInterval == 0 {
+		r.MaxInterval = defaults.PhaseRetryMaxInterval
+	}
+	if r.MaxInterval < r.InitialInterval {
+		r.MaxInterval = r.InitialInterval
+	}
+	if r.Multiplier == 0 {
+		r.Multiplier = defaults.PhaseRetryMultiplier
+	}
+	if len(r.Retryable) == 0 {
+		r.Retryable = []ErrorClass{ErrorClassTransient}
+	}
+	return nil
+}
+
+// NextInterval returns the delay before the next attempt after
+// the specified attempt (starting from 1) has failed with err.
+// Returns false if the phase should not be retried
+func (r RetryPolicy) NextInterval(attempt int, err error) (time.Duration, bool) {
+	if attempt >= r.MaxAttempts || !r.IsRetryable(err) {
+		return 0, false
+	}
+	interval := float64(r.InitialInterval)
+	for i := 1; i < attempt; i++ {
+		interval *= r.Multiplier
+		if interval >= float64(r.MaxInterval) {
+			return r.MaxInterval, true
+		}
+	}
+	return time.Duration(interval), true
+}
+
+// IsRetryable returns true if err belongs to one of the retryable
+// error classes of this policy
+func (r RetryPolicy) IsRetryable(err error) bool {
+	if err == nil || utils.IsAbortError(err) || utils.IsContextCancelledError(err) {
+		return false
+	}
+	for _, class := range r.Retryable {
+		if class.Matches(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorClass identifies a class of phase execution errors
+type ErrorClass string
+
+// Matches returns true if err belongs to this error class
+func (r ErrorClass) Matches(err error) bool {
+	switch r {
+	case ErrorClassTransient:
+		return utils.IsTransientClusterError(err) || utils.IsKubeAuthError(err)
+	case ErrorClassNetwork:
+		return trace.IsConnectionProblem(err) ||
+			utils.IsNetworkError(err) ||
+			utils.IsConnectionResetError(err) ||
+			utils.IsConnectionRefusedError(err)
+	case ErrorClassAny:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	// ErrorClassTransient describes transient cluster errors: etcd
+	// leader elections, API server restarts and connection failures
+	ErrorClassTransient ErrorClass = "transient"
+	// ErrorClassNetwork describes network errors, e.g. when the registry
+	// or a remote agent is temporarily unreachable
+	ErrorClassNetwork ErrorClass = "network"
+	// ErrorClassAny matches any error
+	ErrorClassAny ErrorClass = "any"
+)
+
+// TransientRetryPolicy returns the default policy for phases
+// that talk to the cluster and can fail with transient errors
+func TransientRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Retryable: []ErrorClass{ErrorClassTransient, ErrorClassNetwork},
+	}
+}
+
+// retryPolicy returns the retry policy of the specified executor.
+// Returns false if the executor should not be retried
+func retryPolicy(executor PhaseExecutor) (*RetryPolicy, bool) {
+	retrier, ok := executor.(Retrier)
+	if !ok {
+		return nil, false
+	}
+	policy := retrier.RetryPolicy()
+	if err := policy.CheckAndSetDefaults(); err != nil {
+		executor.Warnf("Invalid retry policy, will not retry: %v.", err)
+		return nil, false
+	}
+	return &policy, true
+}
+
+// waitRetry blocks for the specified interval or until the context is cancelled
+func waitRetry(ctx context.Context, interval time.Duration) error {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return trace.Wrap(ctx.Err())
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// executeGraph executes the specified sibling phases in the order defined
+// by their requirements: a phase is started as soon as all sibling phases
+// it (or any of its subphases) requires have completed, so independent
+// phases are executed concurrently.
+// The number of concurrently executing leaf phases is limited by
+// the ParallelPhases setting.
+// After a phase fails, no new phases are started and the error is returned
+// once the phases that are already running have finished
+func (f *FSM) executeGraph(ctx context.Context, p Params, phases []storage.OperationPhase) error {
+	deps := siblingDependencies(phases)
+	started := make([]bool, len(phases))
+	done := make([]bool, len(phases))
+	ready := func(i int) bool {
+		for _, dep := range deps[i] {
+			if !done[dep] {
+				return false
+			}
+		}
+		return true
+	}
+	type result struct {
+		index int
+		err   error
+	}
+	resultsCh := make(chan result, len(phases))
+	var running int
+	var errors []error
+	for {
+		for i := range phases {
+			if len(errors) != 0 || started[i] || !ready(i) {
+				continue
+			}
+			started[i] = true
+			running++
+			go func(p Params, i int) {
+				p.PhaseID = phases[i].ID
+				f.Debugf("Executing phase %q.", p.PhaseID)
+				err := f.ExecutePhase(ctx, p)
+				if err != nil {
+					logrus.WithFields(logrus.Fields{
+						logrus.ErrorKey: err,
+						"phase":         p.PhaseID,
+					}).Warn("Failed to execute phase.")
+				}
+				resultsCh <- result{index: i, err: trace.Wrap(err, "failed to execute phase %q", p.PhaseID)}
+			}(p, i)
+		}
+		if running == 0 {
+			break
+		}
+		r := <-resultsCh
+		running--
+		if r.err != nil {
+			errors = append(errors, r.err)
+			continue
+		}
+		done[r.index] = true
+	}
+	if len(errors) != 0 {
+		return trace.NewAggregate(errors...)
+	}
+	var blocked []string
+	for i, phase := range phases {
+		if !done[i] {
+			blocked = append(blocked, phase.ID)
+		}
+	}
+	if len(blocked) != 0 {
+		return trace.BadParameter("phases %v have circular requirements", blocked)
+	}
+	return nil
+}
+
+// siblingDependencies returns the indexes of the phases each of the
+// specified sibling phases depends on.
+// A phase depends on a sibling if the phase or any of its subphases
+// requires the sibling or any of the sibling's subphases.
+// Requirements on phases outside of the siblings are ignored as they
+// are satisfied by the order the parent phases are executed in
+func siblingDependencies(phases []storage.OperationPhase) [][]int {
+	deps := make([][]int, len(phases))
+	for i := range phases {
+		for _, required := range requirements(phases[i]) {
+			for j, sibling := range phases {
+				if i == j {
+					continue
+				}
+				if required == sibling.ID || strings.HasPrefix(required, sibling.ID+"/") {
+					deps[i] = append(deps[i], j)
+				}
+			}
+		}
+	}
+	return deps
+}
+
+// requirements returns requirements of the phase and all its subphases
+func requirements(phase storage.OperationPhase) (result []string) {
+	result = append(result, phase.Requires...)
+	for _, subphase := range phase.Phases {
+		result = append(result, requirements(subphase)...)
+	}
+	return result
+}
+
+// acquirePhaseSlot blocks until the number of concurrently executing
+// leaf phases drops below the ParallelPhases limit.
+// Returns the function that releases the acquired slot
+func (f *FSM) acquirePhaseSlot(ctx context.Context) (release func(), err error) {
+	if f.ParallelPhases <= 0 {
+		return func() {}, nil
+	}
+	f.slotsOnce.Do(func() {
+		f.slots = make(chan struct{}, f.ParallelPhases)
+	})
+	select {
+	case f.slots <- struct{}{}:
+		return func() { <-f.slots }, nil
+	case <-ctx.Done():
+		return nil, trace.Wrap(ctx.Err())
+	}
+}
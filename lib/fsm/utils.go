@@ -17,6 +17,9 @@ limitations under the License.
 package fsm
 
 import (
+	"sort"
+	"time"
+
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
@@ -140,10 +143,39 @@ func ResolvePlan(plan storage.OperationPlan, changelog storage.PlanChangelog) *s
 			allPhases[i].Updated = latest.Created
 			allPhases[i].Error = latest.Error
 		}
+		resolveTiming(allPhases[i], changelog)
 	}
 	return &plan
 }
 
+// resolveTiming sets the execution timing attributes of the specified
+// phase from the changelog.
+// A phase attempt starts with the transition into the in-progress state
+// and finishes with the first transition into any other state that follows
+func resolveTiming(phase *storage.OperationPhase, changelog storage.PlanChangelog) {
+	var changes storage.PlanChangelog
+	for _, change := range changelog {
+		if change.PhaseID == phase.ID {
+			changes = append(changes, change)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Created.Before(changes[j].Created)
+	})
+	for _, change := range changes {
+		if change.NewState == storage.OperationPhaseStateInProgress {
+			phase.Attempts++
+			phase.Started = change.Created
+			phase.Finished = time.Time{}
+			phase.Node = change.Node
+			continue
+		}
+		if !phase.Started.IsZero() && phase.Finished.IsZero() {
+			phase.Finished = change.Created
+		}
+	}
+}
+
 // DiffChangelog returns a list of changelog entries from "local" that are missing from "remote"
 func DiffChangelog(local, remote storage.PlanChangelog) []storage.PlanChange {
 	remoteEntries := make(map[string]struct{})
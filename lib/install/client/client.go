@@ -64,9 +64,10 @@ func (r *Client) ExecutePhase(ctx context.Context, phase Phase) error {
 	r.WithField("phase", phase).Info("Execute.")
 	return r.execute(ctx, &installpb.ExecuteRequest{
 		Phase: &installpb.Phase{
-			Key:   installpb.KeyToProto(phase.Key),
-			ID:    phase.ID,
-			Force: phase.Force,
+			Key:    installpb.KeyToProto(phase.Key),
+			ID:     phase.ID,
+			Force:  phase.Force,
+			DryRun: phase.DryRun,
 		},
 	})
 }
@@ -201,6 +202,9 @@ type Phase struct {
 	Force bool
 	// Key identifies the active operation
 	Key ops.SiteOperationKey
+	// DryRun specifies whether the phase should only report the actions
+	// it would perform without executing them
+	DryRun bool
 }
 
 func (r *Client) execute(ctx context.Context, req *installpb.ExecuteRequest) error {
@@ -56,6 +56,7 @@ func NewFSMConfig(operator ops.Operator, operationKey ops.SiteOperationKey, conf
 		Insecure:           config.Insecure,
 		UserLogFile:        config.UserLogFile,
 		ReportProgress:     true,
+		ParallelPhases:     config.ParallelPhases,
 	}
 	fsmConfig.Spec = FSMSpec(fsmConfig)
 	return fsmConfig
@@ -160,6 +161,9 @@ type Config struct {
 	LocalAgent bool
 	// Values are helm values in marshaled yaml format
 	Values []byte
+	// ParallelPhases is the maximum number of independent plan phases
+	// to execute concurrently
+	ParallelPhases int
 }
 
 // checkAndSetDefaults checks the parameters and autodetects some defaults
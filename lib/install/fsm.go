@@ -76,6 +76,9 @@ type FSMConfig struct {
 	ReportProgress bool
 	// DNSConfig specifies the DNS configuration to use
 	DNSConfig storage.DNSConfig
+	// ParallelPhases is the maximum number of independent plan phases
+	// executed concurrently. Phases are executed in plan order if unset
+	ParallelPhases int
 }
 
 // Check validates install FSM config and sets some defaults
@@ -142,10 +145,11 @@ func NewFSM(config FSMConfig) (*fsm.FSM, error) {
 	}
 	runner := fsm.NewAgentRunner(config.Credentials)
 	fsm, err := fsm.New(fsm.Config{
-		Engine:   engine,
-		Runner:   runner,
-		Insecure: config.Insecure,
-		Logger:   logger,
+		Engine:         engine,
+		Runner:         runner,
+		Insecure:       config.Insecure,
+		Logger:         logger,
+		ParallelPhases: config.ParallelPhases,
 	})
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -216,6 +220,7 @@ func (f *fsmEngine) ChangePhaseState(ctx context.Context, change fsm.StateChange
 			NewState:    change.State,
 			Error:       utils.ToRawTrace(change.Error),
 			Created:     time.Now().UTC(),
+			Node:        change.Node,
 		})
 	if err != nil {
 		return trace.Wrap(err)
@@ -224,13 +229,21 @@ func (f *fsmEngine) ChangePhaseState(ctx context.Context, change fsm.StateChange
 	return nil
 }
 
+// RecordPhaseLog saves the log entries captured during phase execution
+func (f *fsmEngine) RecordPhaseLog(ctx context.Context, log storage.PhaseLog) error {
+	return trace.Wrap(fsm.RecordPhaseLog(f.LocalBackend, log))
+}
+
 // GetExecutor returns the appropriate install phase executor based on the
 // provided parameters
 func (f *fsmEngine) GetExecutor(p fsm.ExecutorParams, remote fsm.Remote) (fsm.PhaseExecutor, error) {
 	logger := &fsm.Logger{
-		FieldLogger: logrus.WithField(constants.FieldPhase, p.Phase.ID),
-		Key:         p.Key(),
-		Operator:    f.Operator,
+		FieldLogger: logrus.WithFields(logrus.Fields{
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
+		}),
+		Key:      p.Key(),
+		Operator: f.Operator,
 	}
 	executor, err := f.Spec(p, remote)
 	if err != nil {
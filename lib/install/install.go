@@ -251,6 +251,9 @@ func (i *Installer) maybeStartAgent() error {
 func (i *Installer) execute(req *installpb.ExecuteRequest) (dispatcher.Status, error) {
 	i.WithField("req", req).Info("Execute.")
 	existingOperation, _ := ops.GetWizardOperation(i.config.Operator)
+	if req.Phase != nil && req.Phase.DryRun && existingOperation == nil {
+		return dispatcher.StatusUnknown, trace.NotFound("no install operation found")
+	}
 	if req.HasSpecificPhase() || existingOperation != nil {
 		// FIXME(dmitri): for now, fallback to installing via state machine even for
 		// requests with empty phase (i.e. execute from scratch type) if there's an existing
@@ -277,6 +280,13 @@ func (i *Installer) executePhase(phase installpb.Phase) (dispatcher.Status, erro
 	}
 	progressReporter := dispatcher.NewProgressReporter(i.ctx, i.dispatcher, phaseTitle(phase))
 	defer progressReporter.Stop()
+	if phase.DryRun {
+		err := DryRunOperation(i.ctx, machine, fsm.Params{
+			PhaseID: phase.ID,
+			Force:   phase.Force,
+		}, progressReporter)
+		return dispatcher.StatusUnknown, trace.Wrap(err)
+	}
 	if phase.IsResume() {
 		err := ExecuteOperation(i.ctx, machine,
 			progressReporter,
@@ -391,6 +401,9 @@ func (i *Installer) stop() {
 }
 
 func phaseTitle(phase installpb.Phase) string {
+	if phase.DryRun {
+		return fmt.Sprintf("Describing phase %v", phase.ID)
+	}
 	if phase.IsResume() {
 		return "Resuming operation"
 	}
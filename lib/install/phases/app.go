@@ -52,7 +52,8 @@ func NewHook(p fsm.ExecutorParams, operator ops.Operator, apps app.Applications,
 
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
-			constants.FieldPhase: p.Phase.ID,
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 		}),
 		Key:      opKey(p.Plan),
 		Operator: operator,
@@ -92,6 +93,26 @@ func (p *hookExecutor) Execute(ctx context.Context) error {
 	return nil
 }
 
+// DryRun describes the application hooks this phase would run
+func (p *hookExecutor) DryRun(context.Context) (actions []fsm.Action, err error) {
+	locator := *p.Phase.Data.Package
+	for _, hook := range p.Hooks {
+		_, err := app.CheckHasAppHook(p.Apps, app.HookRunRequest{
+			Application: locator,
+			Hook:        hook,
+		})
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		actions = append(actions, fsm.NewAction(fsm.ActionRunHook,
+			"run %v hook for %v:%v", hook, locator.Name, locator.Version))
+	}
+	return actions, nil
+}
+
 // runHooks runs specified app hooks
 func (p *hookExecutor) runHooks(ctx context.Context, hooks ...schema.HookType) error {
 	for _, hook := range hooks {
@@ -132,6 +153,11 @@ func (p *hookExecutor) runHooks(ctx context.Context, hooks ...schema.HookType) e
 			}
 		}()
 		_, err = app.StreamAppHook(ctx, p.Apps, req, writer)
+		if err != nil && app.HookFailurePolicy(err) == schema.HookFailureContinue {
+			p.Warnf("Ignoring failure of %v %s hook as requested by its failure policy: %v.",
+				locator, hook, err)
+			continue
+		}
 		if err != nil {
 			return trace.Wrap(err, "%v %s hook failed", locator, hook)
 		}
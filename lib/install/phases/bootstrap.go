@@ -76,6 +76,7 @@ func NewBootstrap(p fsm.ExecutorParams, operator ops.Operator, apps app.Applicat
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
 			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 			constants.FieldAdvertiseIP: p.Phase.Data.Server.AdvertiseIP,
 			constants.FieldHostname:    p.Phase.Data.Server.Hostname,
 		}),
@@ -31,9 +31,12 @@ import (
 // NewChecks creates a new preflight checks executor
 func NewChecks(p fsm.ExecutorParams, operator ops.Operator, key ops.SiteOperationKey) (fsm.PhaseExecutor, error) {
 	logger := &fsm.Logger{
-		FieldLogger: log.WithField(constants.FieldPhase, p.Phase.ID),
-		Key:         opKey(p.Plan),
-		Operator:    operator,
+		FieldLogger: log.WithFields(log.Fields{
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
+		}),
+		Key:      opKey(p.Plan),
+		Operator: operator,
 	}
 	return &checksExecutor{
 		FieldLogger:    logger,
@@ -64,10 +67,13 @@ func (r *checksExecutor) Execute(ctx context.Context) error {
 		OperationID: r.key.OperationID,
 		Servers:     r.servers,
 	}
-	err := r.operator.ValidateServers(ctx, req)
+	resp, err := r.operator.ValidateServers(ctx, req)
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	if warnings := resp.Probes.Warnings(); len(warnings) != 0 {
+		r.Warnf("The following checks produced warnings:\n%v", warnings.Format())
+	}
 	return nil
 }
 
@@ -32,7 +32,8 @@ import (
 func NewConfigure(p fsm.ExecutorParams, operator ops.Operator) (*configureExecutor, error) {
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
-			constants.FieldPhase: p.Phase.ID,
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 		}),
 		Key:      opKey(p.Plan),
 		Operator: operator,
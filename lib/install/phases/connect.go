@@ -50,7 +50,8 @@ func NewConnectInstaller(p fsm.ExecutorParams, operator ops.Operator) (*connectE
 	}
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
-			constants.FieldPhase: p.Phase.ID,
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 		}),
 		Key:      p.Key(),
 		Operator: operator,
@@ -51,9 +51,12 @@ type corednsExecutor struct {
 // NewCorednsPhase creates a new coredns phase executor
 func NewCorednsPhase(p fsm.ExecutorParams, operator ops.Operator, client *kubernetes.Clientset) (fsm.PhaseExecutor, error) {
 	logger := &fsm.Logger{
-		FieldLogger: log.WithField(constants.FieldPhase, p.Phase.ID),
-		Key:         opKey(p.Plan),
-		Operator:    operator,
+		FieldLogger: log.WithFields(log.Fields{
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
+		}),
+		Key:      opKey(p.Plan),
+		Operator: operator,
 	}
 
 	cluster, err := operator.GetSite(ops.SiteKey{
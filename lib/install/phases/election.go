@@ -36,6 +36,7 @@ func NewEnableElectionPhase(p fsm.ExecutorParams, operator ops.Operator) (fsm.Ph
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
 			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 			constants.FieldAdvertiseIP: p.Phase.Data.Server.AdvertiseIP,
 			constants.FieldHostname:    p.Phase.Data.Server.Hostname,
 		}),
@@ -57,6 +57,7 @@ func NewExport(p fsm.ExecutorParams, operator ops.Operator, packages pack.Packag
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
 			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 			constants.FieldAdvertiseIP: p.Phase.Data.Server.AdvertiseIP,
 			constants.FieldHostname:    p.Phase.Data.Server.Hostname,
 		}),
@@ -36,9 +36,12 @@ import (
 // NewInit returns executor that prepares the node for the operation.
 func NewInit(p fsm.ExecutorParams, operator ops.Operator, apps app.Applications, packages pack.PackageService) (*initExecutor, error) {
 	logger := &fsm.Logger{
-		FieldLogger: logrus.WithField(constants.FieldPhase, p.Phase.ID),
-		Key:         opKey(p.Plan),
-		Operator:    operator,
+		FieldLogger: logrus.WithFields(logrus.Fields{
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
+		}),
+		Key:      opKey(p.Plan),
+		Operator: operator,
 	}
 	app, err := apps.GetApp(*p.Phase.Data.Package)
 	if err != nil {
@@ -51,9 +51,12 @@ type registerNodeExecutor struct {
 // NewRegisterNodePhase creates a new registerNodes phase executor
 func NewRegisterNodePhase(p fsm.ExecutorParams, operator ops.Operator, apps app.Applications, client *kubernetes.Clientset) (fsm.PhaseExecutor, error) {
 	logger := &fsm.Logger{
-		FieldLogger: log.WithField(constants.FieldPhase, p.Phase.ID),
-		Key:         opKey(p.Plan),
-		Operator:    operator,
+		FieldLogger: log.WithFields(log.Fields{
+			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
+		}),
+		Key:      opKey(p.Plan),
+		Operator: operator,
 	}
 
 	logrus.Info("NewRegisterNodePhase AppLocator: ", p.Phase.Data.Package)
@@ -117,6 +120,12 @@ func (r *registerNodeExecutor) Execute(ctx context.Context) error {
 	return trace.Wrap(err)
 }
 
+// DryRun describes the Kubernetes node this phase would register
+func (r *registerNodeExecutor) DryRun(context.Context) ([]fsm.Action, error) {
+	return []fsm.Action{fsm.NewAction(fsm.ActionUpdateObject,
+		"register Kubernetes node %v", r.Phase.Data.Server.KubeNodeID())}, nil
+}
+
 // Rollback is a noop for this executor
 func (r *registerNodeExecutor) Rollback(context.Context) error {
 	return nil
@@ -64,6 +64,7 @@ func NewPull(p fsm.ExecutorParams, operator ops.Operator, wizardPack, localPack
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
 			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 			constants.FieldAdvertiseIP: p.Phase.Data.Server.AdvertiseIP,
 			constants.FieldHostname:    p.Phase.Data.Server.Hostname,
 		}),
@@ -138,6 +139,36 @@ func (p *pullExecutor) Execute(ctx context.Context) error {
 	return nil
 }
 
+// DryRun describes the application and the configured packages
+// this phase would pull to the node
+func (p *pullExecutor) DryRun(context.Context) ([]fsm.Action, error) {
+	actions := []fsm.Action{fsm.NewAction(fsm.ActionPullPackage,
+		"pull application %v", *p.Phase.Data.Package)}
+	var envelopes []pack.PackageEnvelope
+	var err error
+	if p.Phase.Data.Server.ClusterRole == string(schema.ServiceRoleMaster) {
+		envelopes, err = p.collectMasterPackages()
+	} else {
+		envelopes, err = p.collectNodePackages()
+	}
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, e := range envelopes {
+		actions = append(actions, fsm.NewAction(fsm.ActionPullPackage,
+			"pull package %v", e.Locator))
+	}
+	return actions, nil
+}
+
+// RetryPolicy retries the phase if the packages cannot be pulled
+// due to network errors
+func (p *pullExecutor) RetryPolicy() fsm.RetryPolicy {
+	return fsm.RetryPolicy{
+		Retryable: []fsm.ErrorClass{fsm.ErrorClassNetwork},
+	}
+}
+
 func (p *pullExecutor) pullUserApplication() error {
 	p.Progress.NextStep("Pulling user application")
 	p.Info("Pulling user application.")
@@ -35,6 +35,7 @@ func NewSystem(p fsm.ExecutorParams, operator ops.Operator, remote fsm.Remote) (
 	logger := &fsm.Logger{
 		FieldLogger: logrus.WithFields(logrus.Fields{
 			constants.FieldPhase:       p.Phase.ID,
+			constants.FieldOperationID: p.Plan.OperationID,
 			constants.FieldAdvertiseIP: p.Phase.Data.Server.AdvertiseIP,
 			constants.FieldHostname:    p.Phase.Data.Server.Hostname,
 		}),
@@ -82,6 +83,16 @@ func (p *systemExecutor) Execute(ctx context.Context) error {
 	return trace.Wrap(err, "failed to install system service: %v", output)
 }
 
+// DryRun describes the system service this phase would install
+func (p *systemExecutor) DryRun(context.Context) ([]fsm.Action, error) {
+	locator := p.Phase.Data.Package
+	return []fsm.Action{
+		fsm.NewAction(fsm.ActionInstallPackage, "install system package %v", locator),
+		fsm.NewAction(fsm.ActionRestartService, "start system service for %v:%v",
+			locator.Name, locator.Version),
+	}, nil
+}
+
 // Rollback is no-op for this phase
 func (*systemExecutor) Rollback(ctx context.Context) error {
 	return nil
@@ -73,7 +73,10 @@ type Phase struct {
 	Rollback bool `protobuf:"varint,3,opt,name=rollback,proto3" json:"rollback,omitempty"`
 	// Force specifies whether the phase execution/rollback should be rerun
 	// regardless of phase state
-	Force                bool     `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"`
+	Force bool `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"`
+	// DryRun specifies whether the phase should only report the actions
+	// it would perform without executing them
+	DryRun               bool     `protobuf:"varint,5,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -131,6 +134,13 @@ func (m *Phase) GetForce() bool {
 	return false
 }
 
+func (m *Phase) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
 // ExecuteRequest describes a request to execute install operation
 type ExecuteRequest struct {
 	// Phase optionally specifies the configuration for executing or rolling
@@ -556,51 +566,52 @@ func init() {
 func init() { proto.RegisterFile("installer.proto", fileDescriptor_675879a591bd3155) }
 
 var fileDescriptor_675879a591bd3155 = []byte{
-	// 697 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x4e, 0xdb, 0x4a,
-	0x14, 0xc6, 0x09, 0x0e, 0xf1, 0x09, 0x90, 0x30, 0x5c, 0x71, 0x73, 0xcd, 0x6d, 0x48, 0xbd, 0xa8,
-	0x52, 0xa9, 0x0a, 0x6d, 0xba, 0x41, 0x55, 0x55, 0x29, 0x24, 0x11, 0x8a, 0xa0, 0x49, 0x34, 0x29,
-	0xea, 0x12, 0x39, 0xf6, 0xc1, 0x44, 0x38, 0x1e, 0xd7, 0x1e, 0x0b, 0x90, 0xfa, 0x00, 0x88, 0x75,
-	0xb7, 0xac, 0xca, 0xa2, 0xfb, 0xee, 0xfa, 0x00, 0x7d, 0x0c, 0x16, 0x3c, 0x49, 0x65, 0x8f, 0x93,
-	0x38, 0xa9, 0x42, 0xd5, 0x9d, 0xcf, 0xcf, 0x77, 0xbe, 0xf3, 0xe3, 0x6f, 0x20, 0x3f, 0x74, 0x7c,
-	0xae, 0xdb, 0x36, 0x7a, 0x55, 0xd7, 0x63, 0x9c, 0x11, 0x65, 0xe2, 0x50, 0xb7, 0x2d, 0xc6, 0x2c,
-	0x1b, 0x77, 0xa3, 0xc0, 0x20, 0x38, 0xdd, 0xc5, 0x91, 0xcb, 0xaf, 0x44, 0x9e, 0x0a, 0x16, 0xb3,
-	0x98, 0xf8, 0xd6, 0x3e, 0x83, 0xdc, 0x3b, 0xd3, 0x7d, 0x24, 0x5b, 0x90, 0x1a, 0x9a, 0x45, 0xa9,
-	0x2c, 0x55, 0x94, 0xfd, 0xcc, 0xc3, 0xfd, 0x4e, 0xaa, 0xdd, 0xa4, 0xa9, 0xa1, 0x49, 0x9e, 0x43,
-	0xfa, 0x1c, 0xaf, 0x8a, 0xa9, 0xb2, 0x54, 0xc9, 0xd5, 0xfe, 0xad, 0x4e, 0x39, 0xbb, 0x2e, 0x7a,
-	0x3a, 0x1f, 0x32, 0xe7, 0x10, 0xaf, 0x68, 0x98, 0x43, 0x54, 0xc8, 0x7a, 0xcc, 0xb6, 0x07, 0xba,
-	0x71, 0x5e, 0x4c, 0x97, 0xa5, 0x4a, 0x96, 0x4e, 0x6c, 0xf2, 0x0f, 0xc8, 0xa7, 0xcc, 0x33, 0xb0,
-	0xb8, 0x1c, 0x05, 0x84, 0xa1, 0xed, 0xc1, 0x7a, 0xeb, 0x12, 0x8d, 0x80, 0x23, 0xc5, 0x4f, 0x01,
-	0xfa, 0x9c, 0x3c, 0x03, 0xd9, 0x0d, 0xfb, 0x89, 0x3a, 0xc9, 0xd5, 0x0a, 0x09, 0xc2, 0xa8, 0x4f,
-	0x2a, 0xc2, 0x5a, 0x17, 0xf2, 0x7d, 0xe4, 0x7d, 0xae, 0xff, 0x35, 0x34, 0x6c, 0xc5, 0x0f, 0x71,
-	0xd1, 0x4c, 0x0a, 0x15, 0x86, 0xf6, 0x16, 0xf2, 0x0d, 0x36, 0x72, 0x6d, 0x9c, 0x16, 0x8c, 0x47,
-	0x97, 0xfe, 0x3c, 0xba, 0xb6, 0x0e, 0xab, 0xf5, 0x01, 0xf3, 0x78, 0x0c, 0xd5, 0x0e, 0x21, 0xdf,
-	0x3f, 0x0b, 0xb8, 0xc9, 0x2e, 0x9c, 0x71, 0xb5, 0xff, 0x41, 0x31, 0x62, 0x02, 0xb1, 0xe7, 0x2c,
-	0x9d, 0x3a, 0xc2, 0xdd, 0xe1, 0xe5, 0x90, 0x37, 0x98, 0x29, 0xfa, 0x92, 0xe9, 0xc4, 0xd6, 0x2a,
-	0x40, 0x9a, 0x38, 0x08, 0x2c, 0x8a, 0xee, 0x94, 0x82, 0x10, 0x58, 0x76, 0x75, 0x7e, 0x26, 0x4e,
-	0x46, 0xa3, 0x6f, 0xed, 0x67, 0x0a, 0x0a, 0x3d, 0x8f, 0x59, 0x1e, 0xfa, 0x3e, 0x45, 0xdf, 0x65,
-	0x8e, 0x8f, 0xa4, 0x08, 0x2b, 0x23, 0xf4, 0x7d, 0xdd, 0xc2, 0x38, 0x77, 0x6c, 0x92, 0x37, 0x90,
-	0x09, 0x87, 0x0f, 0xfc, 0x88, 0x72, 0xbd, 0xa6, 0x25, 0x57, 0x36, 0x57, 0xa6, 0xda, 0x8f, 0x32,
-	0x69, 0x8c, 0x08, 0xb7, 0x8d, 0x9e, 0xc7, 0xbc, 0xe8, 0xd2, 0xb3, 0xdb, 0x6e, 0x85, 0x7e, 0x2a,
-	0xc2, 0xda, 0x77, 0x09, 0x32, 0x02, 0x4a, 0x4a, 0xb0, 0x72, 0xdc, 0x39, 0xec, 0x74, 0x3f, 0x76,
-	0x0a, 0x4b, 0xea, 0xc6, 0xcd, 0x6d, 0x79, 0x4d, 0x04, 0x8e, 0x9d, 0x73, 0x87, 0x5d, 0x38, 0x44,
-	0x03, 0xa5, 0xd1, 0x7d, 0xdf, 0x3b, 0x6a, 0x7d, 0x68, 0x35, 0x0b, 0x92, 0xba, 0x79, 0x73, 0x5b,
-	0xce, 0x8b, 0x8c, 0xc6, 0x64, 0x4f, 0xaf, 0x60, 0x63, 0x92, 0x73, 0xd2, 0x6b, 0x75, 0x9a, 0xed,
-	0xce, 0x41, 0x21, 0xa5, 0xaa, 0x37, 0xb7, 0xe5, 0xad, 0xb9, 0xdc, 0x1e, 0x3a, 0xe6, 0xd0, 0xb1,
-	0x42, 0xda, 0xfa, 0x7e, 0x97, 0x86, 0x45, 0xd3, 0x49, 0xda, 0xe8, 0x60, 0x68, 0xaa, 0xe4, 0xfa,
-	0x6b, 0x69, 0xe9, 0xdb, 0x5d, 0x69, 0xe9, 0xc7, 0x5d, 0x29, 0x6e, 0x55, 0x7b, 0x0a, 0x72, 0x34,
-	0xc5, 0xe2, 0xe5, 0x69, 0xd7, 0x12, 0xac, 0x26, 0x7f, 0x04, 0xf2, 0x02, 0x40, 0x37, 0x0c, 0x16,
-	0x38, 0xfc, 0x64, 0xa2, 0xa4, 0xb5, 0x87, 0xfb, 0x1d, 0xa5, 0x2e, 0xbc, 0xed, 0x26, 0x55, 0xe2,
-	0x84, 0xb6, 0x49, 0x6a, 0xb0, 0x6a, 0xd8, 0x81, 0xcf, 0xd1, 0x3b, 0x71, 0xf4, 0x51, 0xfc, 0x33,
-	0xee, 0xe7, 0x1f, 0xee, 0x77, 0x72, 0x0d, 0xe1, 0xef, 0xe8, 0x23, 0xa4, 0x39, 0x63, 0x6a, 0xc4,
-	0x1a, 0x4d, 0xcf, 0x6b, 0xb4, 0xf6, 0x25, 0x0d, 0x72, 0xdd, 0x42, 0x87, 0x93, 0x06, 0xac, 0xc4,
-	0x82, 0x22, 0xff, 0x25, 0x2f, 0x32, 0x23, 0x32, 0x75, 0xfb, 0x91, 0x3b, 0xbf, 0x94, 0xc8, 0x3b,
-	0xc8, 0x8e, 0x97, 0x48, 0xd4, 0x44, 0xea, 0x9c, 0x3e, 0xd4, 0xad, 0xaa, 0x78, 0x65, 0xaa, 0xe3,
-	0x57, 0xa6, 0xda, 0x0a, 0x5f, 0x99, 0x10, 0x3f, 0xd6, 0xe6, 0x0c, 0x7e, 0x4e, 0xb0, 0x0b, 0xf1,
-	0x7b, 0x20, 0x47, 0xb7, 0x21, 0x49, 0xcd, 0x25, 0xe5, 0xf5, 0x28, 0x73, 0x2c, 0xbb, 0x59, 0xe6,
-	0x59, 0x2d, 0x2e, 0xc4, 0x1f, 0xc1, 0xe6, 0x01, 0x3a, 0xe1, 0x4d, 0x31, 0xa1, 0x38, 0xf2, 0x24,
-	0x51, 0xea, 0x77, 0x25, 0x2e, 0xaa, 0x36, 0xc8, 0x44, 0xf6, 0xeb, 0x5f, 0x01, 0x00, 0x00, 0xff,
-	0xff, 0x3d, 0x71, 0x20, 0x38, 0xa9, 0x05, 0x00, 0x00,
+	// 713 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0x4d, 0x6f, 0xda, 0x4c,
+	0x10, 0x8e, 0x21, 0xe6, 0x63, 0x48, 0x02, 0xd9, 0xbc, 0x4a, 0x78, 0x9d, 0xf7, 0x25, 0xd4, 0x87,
+	0x8a, 0x4a, 0x15, 0x69, 0xe9, 0x25, 0xaa, 0xaa, 0x4a, 0x04, 0x50, 0x84, 0x92, 0x02, 0x5a, 0x1a,
+	0xf5, 0x88, 0x8c, 0x3d, 0x71, 0x50, 0xc0, 0xeb, 0xda, 0x6b, 0x25, 0xfc, 0x83, 0x28, 0xe7, 0xf6,
+	0x98, 0x53, 0x73, 0xe8, 0xbd, 0xb7, 0xfe, 0x80, 0xfe, 0x8c, 0x1c, 0xf2, 0x4b, 0x2a, 0x7b, 0x0d,
+	0x18, 0x22, 0x52, 0xf5, 0xe6, 0x99, 0x7d, 0x9e, 0x99, 0xd9, 0x67, 0xfc, 0x2c, 0x64, 0x07, 0x96,
+	0xcb, 0xb5, 0xe1, 0x10, 0x9d, 0xb2, 0xed, 0x30, 0xce, 0x48, 0x7a, 0x9a, 0x50, 0x76, 0x4d, 0xc6,
+	0xcc, 0x21, 0xee, 0x07, 0x07, 0x7d, 0xef, 0x6c, 0x1f, 0x47, 0x36, 0x1f, 0x0b, 0x9c, 0x02, 0x26,
+	0x33, 0x99, 0xf8, 0x56, 0xbf, 0x4a, 0x20, 0x77, 0xce, 0x35, 0x17, 0xc9, 0x36, 0xc4, 0x06, 0x46,
+	0x5e, 0x2a, 0x4a, 0xa5, 0xf4, 0x61, 0xe2, 0xe1, 0x7e, 0x2f, 0xd6, 0xac, 0xd3, 0xd8, 0xc0, 0x20,
+	0x2f, 0x20, 0x7e, 0x81, 0xe3, 0x7c, 0xac, 0x28, 0x95, 0x32, 0x95, 0x9d, 0xf2, 0xac, 0x69, 0xdb,
+	0x46, 0x47, 0xe3, 0x03, 0x66, 0x1d, 0xe3, 0x98, 0xfa, 0x18, 0xa2, 0x40, 0xca, 0x61, 0xc3, 0x61,
+	0x5f, 0xd3, 0x2f, 0xf2, 0xf1, 0xa2, 0x54, 0x4a, 0xd1, 0x69, 0x4c, 0xfe, 0x01, 0xf9, 0x8c, 0x39,
+	0x3a, 0xe6, 0x57, 0x83, 0x03, 0x11, 0x90, 0x1d, 0x48, 0x1a, 0xce, 0xb8, 0xe7, 0x78, 0x56, 0x5e,
+	0x0e, 0xf2, 0x09, 0xc3, 0x19, 0x53, 0xcf, 0x52, 0x0f, 0x60, 0xa3, 0x71, 0x85, 0xba, 0xc7, 0x91,
+	0xe2, 0x67, 0x0f, 0x5d, 0x4e, 0x9e, 0x83, 0x6c, 0xfb, 0x83, 0x06, 0x23, 0x66, 0x2a, 0xb9, 0xc8,
+	0x24, 0xc1, 0x05, 0xa8, 0x38, 0x56, 0xdb, 0x90, 0xed, 0x22, 0xef, 0x72, 0xed, 0xaf, 0xa9, 0xfe,
+	0x8c, 0xae, 0xcf, 0x0b, 0x2e, 0x9b, 0xa6, 0x22, 0x50, 0xdf, 0x41, 0xb6, 0xc6, 0x46, 0xf6, 0x10,
+	0x67, 0x05, 0x43, 0x4d, 0xa4, 0x3f, 0x6b, 0xa2, 0x6e, 0xc0, 0x5a, 0xb5, 0xcf, 0x1c, 0x1e, 0x52,
+	0xd5, 0x63, 0xc8, 0x76, 0xcf, 0x3d, 0x6e, 0xb0, 0x4b, 0x6b, 0x52, 0xed, 0x3f, 0x48, 0xeb, 0x61,
+	0x03, 0xb1, 0x80, 0x14, 0x9d, 0x25, 0x7c, 0x51, 0xf1, 0x6a, 0xc0, 0x6b, 0xcc, 0x10, 0x73, 0xc9,
+	0x74, 0x1a, 0xab, 0x25, 0x20, 0x75, 0xec, 0x7b, 0x26, 0x45, 0x7b, 0xd6, 0x82, 0x10, 0x58, 0xb5,
+	0x35, 0x7e, 0x2e, 0x76, 0x49, 0x83, 0x6f, 0xf5, 0x57, 0x0c, 0x72, 0x1d, 0x87, 0x99, 0x0e, 0xba,
+	0x2e, 0x45, 0xd7, 0x66, 0x96, 0x8b, 0x24, 0x0f, 0xc9, 0x11, 0xba, 0xae, 0x66, 0x62, 0x88, 0x9d,
+	0x84, 0xe4, 0x2d, 0x24, 0xfc, 0xcb, 0x7b, 0x6e, 0xd0, 0x72, 0xa3, 0xa2, 0x46, 0x25, 0x5b, 0x28,
+	0x53, 0xee, 0x06, 0x48, 0x1a, 0x32, 0x7c, 0xb5, 0xd1, 0x71, 0x98, 0x93, 0x8f, 0x3f, 0x52, 0xbb,
+	0xe1, 0xe7, 0xa9, 0x38, 0x56, 0x7f, 0x48, 0x90, 0x10, 0x54, 0x52, 0x80, 0xe4, 0x69, 0xeb, 0xb8,
+	0xd5, 0xfe, 0xd4, 0xca, 0xad, 0x28, 0x9b, 0x37, 0xb7, 0xc5, 0x75, 0x71, 0x70, 0x6a, 0x5d, 0x58,
+	0xec, 0xd2, 0x22, 0x2a, 0xa4, 0x6b, 0xed, 0x0f, 0x9d, 0x93, 0xc6, 0xc7, 0x46, 0x3d, 0x27, 0x29,
+	0x5b, 0x37, 0xb7, 0xc5, 0xac, 0x40, 0xd4, 0xa6, 0x3a, 0xbd, 0x86, 0xcd, 0x29, 0xa6, 0xd7, 0x69,
+	0xb4, 0xea, 0xcd, 0xd6, 0x51, 0x2e, 0xa6, 0x28, 0x37, 0xb7, 0xc5, 0xed, 0x05, 0x6c, 0x07, 0x2d,
+	0x63, 0x60, 0x99, 0x7e, 0xdb, 0xea, 0x61, 0x9b, 0xfa, 0x45, 0xe3, 0xd1, 0xb6, 0xc1, 0xc2, 0xd0,
+	0x50, 0xc8, 0xf5, 0xb7, 0xc2, 0xca, 0xf7, 0xbb, 0xc2, 0xca, 0xcf, 0xbb, 0x42, 0x38, 0xaa, 0xfa,
+	0x0c, 0xe4, 0xe0, 0x16, 0xcb, 0xc5, 0x53, 0xaf, 0x25, 0x58, 0x8b, 0xfe, 0x08, 0xe4, 0x25, 0x80,
+	0xa6, 0xeb, 0xcc, 0xb3, 0x78, 0x6f, 0x6a, 0xb1, 0xf5, 0x87, 0xfb, 0xbd, 0x74, 0x55, 0x64, 0x9b,
+	0x75, 0x9a, 0x0e, 0x01, 0x4d, 0x83, 0x54, 0x60, 0x4d, 0x1f, 0x7a, 0x2e, 0x47, 0xa7, 0x67, 0x69,
+	0xa3, 0xf0, 0x67, 0x3c, 0xcc, 0x3e, 0xdc, 0xef, 0x65, 0x6a, 0x22, 0xdf, 0xd2, 0x46, 0x48, 0x33,
+	0xfa, 0x2c, 0x08, 0xcd, 0x1b, 0x5f, 0x34, 0x6f, 0xe5, 0x4b, 0x1c, 0xe4, 0xaa, 0x89, 0x16, 0x27,
+	0x35, 0x48, 0x86, 0x86, 0x22, 0xff, 0x46, 0x37, 0x32, 0x67, 0x32, 0x65, 0xf7, 0x89, 0x3d, 0xbf,
+	0x92, 0xc8, 0x7b, 0x48, 0x4d, 0x44, 0x24, 0x4a, 0x04, 0xba, 0xe0, 0x0f, 0x65, 0xbb, 0x2c, 0xde,
+	0x9f, 0xf2, 0xe4, 0xfd, 0x29, 0x37, 0xfc, 0xf7, 0xc7, 0xe7, 0x4f, 0xbc, 0x39, 0xc7, 0x5f, 0x30,
+	0xec, 0x52, 0xfe, 0x01, 0xc8, 0xc1, 0x6e, 0x48, 0xd4, 0x73, 0x51, 0x7b, 0x3d, 0xd9, 0x39, 0xb4,
+	0xdd, 0x7c, 0xe7, 0x79, 0x2f, 0x2e, 0xe5, 0x9f, 0xc0, 0xd6, 0x11, 0x5a, 0xfe, 0x4e, 0x31, 0xe2,
+	0x38, 0xf2, 0x7f, 0xa4, 0xd4, 0x63, 0x27, 0x2e, 0xab, 0xd6, 0x4f, 0x04, 0xf1, 0x9b, 0xdf, 0x03,
+	0x00, 0x30, 0x7f, 0xd4, 0x45, 0xc3, 0x05, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -67,9 +67,11 @@ func KeyToProto(key ops.SiteOperationKey) *OperationKey {
 	}
 }
 
-// HasSpecificPhase determines if this request is for a specific phase (other than root)
+// HasSpecificPhase determines if this request is for a specific phase (other than root).
+// Dry-run requests are always handled as specific phase requests since they
+// do not advance the operation
 func (r *ExecuteRequest) HasSpecificPhase() bool {
-	return r.Phase != nil && !r.Phase.IsResume()
+	return r.Phase != nil && (!r.Phase.IsResume() || r.Phase.DryRun)
 }
 
 // OperationKey returns operation key from request.
@@ -16,6 +16,7 @@ limitations under the License.
 package install
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -321,6 +322,19 @@ func ExecuteOperation(ctx context.Context, machine *fsm.FSM, progress utils.Prog
 	return trace.Wrap(err)
 }
 
+// DryRunOperation outputs the actions the phase specified with params
+// would perform to progress without executing them
+func DryRunOperation(ctx context.Context, machine *fsm.FSM, params fsm.Params, progress utils.Progress) error {
+	actions, err := machine.DryRun(ctx, params)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var buf bytes.Buffer
+	fsm.FormatDryRunText(&buf, actions)
+	progress.Print("%v", strings.TrimSuffix(buf.String(), "\n"))
+	return nil
+}
+
 // Run runs progress loop for the specified operation until the operation
 // is complete or context is cancelled.
 func (r ProgressPoller) Run(ctx context.Context) error {
@@ -27,7 +27,7 @@ import (
 	appclient "github.com/gravitational/gravity/lib/app/client"
 	appservice "github.com/gravitational/gravity/lib/app/service"
 	"github.com/gravitational/gravity/lib/blob"
-	"github.com/gravitational/gravity/lib/blob/fs"
+	"github.com/gravitational/gravity/lib/blob/dedup"
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/docker"
@@ -174,7 +174,7 @@ func (env *LocalEnvironment) init() error {
 		env.DNS = DNSConfig(*dns)
 	}
 
-	env.Objects, err = fs.New(filepath.Join(env.StateDir, defaults.PackagesDir))
+	env.Objects, err = dedup.New(dedup.Config{Path: filepath.Join(env.StateDir, defaults.PackagesDir)})
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -149,6 +149,7 @@ func (_ *Server) Validate(ctx context.Context, req *pb.ValidateRequest) (resp *p
 	} else {
 		failedProbes, err = validateManifest(*profile, manifest, stateDir)
 		failedProbes = append(failedProbes, runLocalChecks(ctx)...)
+		failedProbes = append(failedProbes, checks.RunCustomChecks(ctx, profile.Requirements.CustomChecks)...)
 	}
 
 	return &pb.ValidateResponse{Failed: failedProbes}, trace.Wrap(err)
@@ -36,20 +36,21 @@ import (
 // agentService is the access point to the agent cluster for running remote
 // commands.
 // manifest specifies the application manifest with requirements.
+// Returns the results of the failed checks
 func CheckServers(ctx context.Context,
 	opKey SiteOperationKey,
 	infos checks.ServerInfos,
 	servers []storage.Server,
 	agentService AgentService,
 	manifest schema.Manifest,
-) error {
+) (checks.Results, error) {
 	nodes, err := mergeServers(infos, servers)
 	if err != nil {
-		return trace.Wrap(err)
+		return nil, trace.Wrap(err)
 	}
 	requirements, err := checks.RequirementsFromManifest(manifest)
 	if err != nil {
-		return trace.Wrap(err)
+		return nil, trace.Wrap(err)
 	}
 	c, err := checks.New(checks.Config{
 		Remote:       &remoteCommands{key: opKey, AgentService: agentService},
@@ -64,9 +65,9 @@ func CheckServers(ctx context.Context,
 		},
 	})
 	if err != nil {
-		return trace.Wrap(err)
+		return nil, trace.Wrap(err)
 	}
-	return trace.Wrap(c.Run(ctx))
+	return c.Check(ctx), nil
 }
 
 // FormatValidationError formats validation error as a human-readable text
@@ -239,6 +239,21 @@ var (
 		Name: PersistentStorageUpdatedEvent,
 		Code: PersistentStorageUpdatedCode,
 	}
+	// BackupPolicyCreated is emitted when a backup policy is created/updated.
+	BackupPolicyCreated = events.Event{
+		Name: BackupPolicyCreatedEvent,
+		Code: BackupPolicyCreatedCode,
+	}
+	// BackupPolicyDeleted is emitted when a backup policy is deleted.
+	BackupPolicyDeleted = events.Event{
+		Name: BackupPolicyDeletedEvent,
+		Code: BackupPolicyDeletedCode,
+	}
+	// BackupDeleted is emitted when a stored backup is deleted.
+	BackupDeleted = events.Event{
+		Name: BackupDeletedEvent,
+		Code: BackupDeletedCode,
+	}
 	// ClusterUnhealthy is emitted when cluster becomes unhealthy.
 	ClusterUnhealthy = events.Event{
 		Name: ClusterDegradedEvent,
@@ -375,6 +390,12 @@ const (
 	UserInviteCreatedCode = "G1010I"
 	// PersistentStorageUpdatedCode is the persistent storage updated event code.
 	PersistentStorageUpdatedCode = "G1011I"
+	// BackupPolicyCreatedCode is the backup policy created event code.
+	BackupPolicyCreatedCode = "G1012I"
+	// BackupPolicyDeletedCode is the backup policy deleted event code.
+	BackupPolicyDeletedCode = "G2012I"
+	// BackupDeletedCode is the backup deleted event code.
+	BackupDeletedCode = "G2013I"
 	// ClusterUnhealthyCode is the cluster goes unhealthy event code.
 	ClusterUnhealthyCode = "G3000W"
 	// ClusterHealthyCode is the cluster goes healthy event code.
@@ -446,6 +467,12 @@ const (
 	InviteCreatedEvent = "invite.created"
 	// PersistentStorageUpdatedEvent fires when persistent storage configuration is updated.
 	PersistentStorageUpdatedEvent = "persistentstorage.updated"
+	// BackupPolicyCreatedEvent fires when a backup policy is created/updated.
+	BackupPolicyCreatedEvent = "backuppolicy.created"
+	// BackupPolicyDeletedEvent fires when a backup policy is deleted.
+	BackupPolicyDeletedEvent = "backuppolicy.deleted"
+	// BackupDeletedEvent fires when a stored backup is deleted.
+	BackupDeletedEvent = "backup.deleted"
 
 	// ClusterDegradedEvent fires when cluster health check fails.
 	ClusterDegradedEvent = "cluster.degraded"
@@ -413,9 +413,9 @@ func (o *OperatorACL) CheckSiteStatus(ctx context.Context, key SiteKey) error {
 }
 
 // ValidateServers runs pre-installation checks
-func (o *OperatorACL) ValidateServers(ctx context.Context, req ValidateServersRequest) error {
+func (o *OperatorACL) ValidateServers(ctx context.Context, req ValidateServersRequest) (*ValidateServersResponse, error) {
 	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
-		return trace.Wrap(err)
+		return nil, trace.Wrap(err)
 	}
 	return o.operator.ValidateServers(ctx, req)
 }
@@ -564,6 +564,45 @@ func (o *OperatorACL) GetSiteOperationProgress(key SiteOperationKey) (*ProgressE
 	return o.operator.GetSiteOperationProgress(key)
 }
 
+// WatchEvents returns a watcher that streams the changes of the cluster state.
+// Each watched kind requires read access to the corresponding resource.
+// If no kinds are specified, only the kinds the user can read are watched
+func (o *OperatorACL) WatchEvents(ctx context.Context, key SiteKey, filter storage.WatchFilter) (storage.Watcher, error) {
+	if err := filter.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	kinds := filter.Kinds
+	if len(kinds) == 0 {
+		kinds = storage.WatchKinds
+	}
+	var allowed []string
+	for _, kind := range kinds {
+		err := o.ClusterAction(key.SiteDomain, watchResourceKind(kind), teleservices.VerbRead)
+		if err == nil {
+			allowed = append(allowed, kind)
+			continue
+		}
+		if len(filter.Kinds) != 0 || !trace.IsAccessDenied(err) {
+			return nil, trace.Wrap(err)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil, trace.AccessDenied("access denied to watch events of cluster %v", key.SiteDomain)
+	}
+	filter.Kinds = allowed
+	return o.operator.WatchEvents(ctx, key, filter)
+}
+
+// watchResourceKind returns the resource kind that guards
+// the access to the events of the specified watch kind
+func watchResourceKind(kind string) string {
+	switch kind {
+	case storage.KindOperation, storage.KindProgressEntry:
+		return storage.KindCluster
+	}
+	return kind
+}
+
 func (o *OperatorACL) CreateProgressEntry(key SiteOperationKey, entry ProgressEntry) error {
 	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
 		return trace.Wrap(err)
@@ -571,11 +610,11 @@ func (o *OperatorACL) CreateProgressEntry(key SiteOperationKey, entry ProgressEn
 	return o.operator.CreateProgressEntry(key, entry)
 }
 
-func (o *OperatorACL) GetSiteReport(key SiteKey) (io.ReadCloser, error) {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+func (o *OperatorACL) GetSiteReport(req GetClusterReportRequest) (io.ReadCloser, error) {
+	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
 		return nil, trace.Wrap(err)
 	}
-	return o.operator.GetSiteReport(key)
+	return o.operator.GetSiteReport(req)
 }
 
 func (o *OperatorACL) ValidateDomainName(domainName string) error {
@@ -837,6 +876,14 @@ func (o *OperatorACL) UpdatePersistentStorage(ctx context.Context, req UpdatePer
 	return o.operator.UpdatePersistentStorage(ctx, req)
 }
 
+// GetResourceRevisions returns the recorded revisions of cluster resources
+func (o *OperatorACL) GetResourceRevisions(ctx context.Context, req GetResourceRevisionsRequest) ([]storage.ResourceRevision, error) {
+	if err := o.ClusterAction(req.SiteDomain, req.Kind, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetResourceRevisions(ctx, req)
+}
+
 func (o *OperatorACL) GetApplicationEndpoints(key SiteKey) ([]Endpoint, error) {
 	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
 		return nil, trace.Wrap(err)
@@ -1125,3 +1172,59 @@ func (o *OperatorACL) CreateUserReset(ctx context.Context, req CreateUserResetRe
 	}
 	return o.operator.CreateUserReset(ctx, req)
 }
+
+// GetBackupPolicies returns the cluster backup policies
+func (o *OperatorACL) GetBackupPolicies(key SiteKey, withSecrets bool) ([]storage.BackupPolicy, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindBackupPolicy, teleservices.VerbList); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if withSecrets {
+		if err := o.ClusterAction(key.SiteDomain, storage.KindBackupPolicy, teleservices.VerbRead); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	return o.operator.GetBackupPolicies(key, withSecrets)
+}
+
+// UpsertBackupPolicy creates or updates the backup policy
+func (o *OperatorACL) UpsertBackupPolicy(ctx context.Context, key SiteKey, policy storage.BackupPolicy) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindBackupPolicy, teleservices.VerbCreate); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := o.ClusterAction(key.SiteDomain, storage.KindBackupPolicy, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.UpsertBackupPolicy(ctx, key, policy)
+}
+
+// DeleteBackupPolicy deletes the backup policy by name
+func (o *OperatorACL) DeleteBackupPolicy(ctx context.Context, key SiteKey, name string) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindBackupPolicy, teleservices.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.DeleteBackupPolicy(ctx, key, name)
+}
+
+// ListBackups returns the backups taken by all backup policies
+func (o *OperatorACL) ListBackups(ctx context.Context, key SiteKey) ([]storage.Backup, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindBackupPolicy, teleservices.VerbList); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.ListBackups(ctx, key)
+}
+
+// GetBackupReader returns the reader of the backup archive
+func (o *OperatorACL) GetBackupReader(ctx context.Context, key SiteKey, name string) (io.ReadCloser, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindBackupPolicy, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetBackupReader(ctx, key, name)
+}
+
+// DeleteBackup deletes the stored backup by name
+func (o *OperatorACL) DeleteBackup(ctx context.Context, key SiteKey, name string) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindBackupPolicy, teleservices.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.DeleteBackup(ctx, key, name)
+}
@@ -119,6 +119,7 @@ type Operator interface {
 	Operations
 	Validation
 	LogForwarders
+	Backups
 	Monitoring
 	SMTP
 	Endpoints
@@ -131,6 +132,7 @@ type Operator interface {
 	RuntimeEnvironment
 	ClusterConfiguration
 	PersistentStorage
+	ResourceHistory
 	Audit
 }
 
@@ -415,7 +417,7 @@ type Sites interface {
 	CompleteFinalInstallStep(CompleteFinalInstallStepRequest) error
 
 	// GetSiteReport returns a tarball that contains all debugging information gathered for the site
-	GetSiteReport(SiteKey) (io.ReadCloser, error)
+	GetSiteReport(GetClusterReportRequest) (io.ReadCloser, error)
 
 	// SignTLSKey signs X509 Public Key with X509 certificate authority of this site
 	SignTLSKey(TLSSignRequest) (*TLSSignResponse, error)
@@ -630,6 +632,21 @@ type UpdatePersistentStorageRequest struct {
 	Resource storage.PersistentStorage
 }
 
+// ResourceHistory provides access to the history of changes to cluster resources
+type ResourceHistory interface {
+	// GetResourceRevisions returns the recorded revisions of cluster resources,
+	// most recent first
+	GetResourceRevisions(context.Context, GetResourceRevisionsRequest) ([]storage.ResourceRevision, error)
+}
+
+// GetResourceRevisionsRequest is a request to retrieve the revisions of cluster resources
+type GetResourceRevisionsRequest struct {
+	// SiteKey identifies the cluster.
+	SiteKey `json:"site_key"`
+	// Filter selects the revisions to return
+	storage.ResourceRevisionFilter `json:"filter"`
+}
+
 // ClusterCertificate represents the cluster certificate
 type ClusterCertificate struct {
 	// Certificate is the cluster certificate
@@ -776,6 +793,13 @@ type Operations interface {
 	// process to get the progress report
 	GetSiteOperationProgress(SiteOperationKey) (*ProgressEntry, error)
 
+	// WatchEvents returns a watcher that streams the changes of the cluster,
+	// its operations, progress entries and configuration matching the filter
+	//
+	// This method is used to follow operations in real time instead of
+	// polling for the operation state and progress
+	WatchEvents(context.Context, SiteKey, storage.WatchFilter) (storage.Watcher, error)
+
 	// CreateProgressEntry creates a new progress entry for the specified
 	// operation
 	CreateProgressEntry(SiteOperationKey, ProgressEntry) error
@@ -1806,7 +1830,7 @@ type Validation interface {
 	// ValidateDomainName validates that the chosen domain name is unique
 	ValidateDomainName(domainName string) error
 	// ValidateServers runs pre-installation checks
-	ValidateServers(context.Context, ValidateServersRequest) error
+	ValidateServers(context.Context, ValidateServersRequest) (*ValidateServersResponse, error)
 	// ValidateRemoteAccess verifies that the cluster nodes are accessible remotely
 	ValidateRemoteAccess(ValidateRemoteAccessRequest) (*ValidateRemoteAccessResponse, error)
 }
@@ -1837,6 +1861,13 @@ func (r ValidateServersRequest) Check() error {
 	return nil
 }
 
+// ValidateServersResponse describes the outcome of pre-installation checks
+type ValidateServersResponse struct {
+	// Probes lists the results of all checks, including passed checks
+	// and failures with warning severity
+	Probes checks.Results `json:"probes"`
+}
+
 // SiteKey returns a site key from this request
 func (r ValidateServersRequest) SiteKey() SiteKey {
 	return SiteKey{
@@ -1919,6 +1950,24 @@ type LogForwarders interface {
 	DeleteLogForwarder(ctx context.Context, key SiteKey, name string) error
 }
 
+// Backups defines the interface to manage cluster backup policies
+// and the backups they take
+type Backups interface {
+	// GetBackupPolicies returns the cluster backup policies.
+	// Returned policies exclude secrets unless withSecrets is true
+	GetBackupPolicies(key SiteKey, withSecrets bool) ([]storage.BackupPolicy, error)
+	// UpsertBackupPolicy creates or updates the backup policy
+	UpsertBackupPolicy(context.Context, SiteKey, storage.BackupPolicy) error
+	// DeleteBackupPolicy deletes the backup policy by name
+	DeleteBackupPolicy(ctx context.Context, key SiteKey, name string) error
+	// ListBackups returns the backups taken by all backup policies
+	ListBackups(context.Context, SiteKey) ([]storage.Backup, error)
+	// GetBackupReader returns the reader of the backup archive
+	GetBackupReader(ctx context.Context, key SiteKey, name string) (io.ReadCloser, error)
+	// DeleteBackup deletes the stored backup by name
+	DeleteBackup(ctx context.Context, key SiteKey, name string) error
+}
+
 // SMTP defines the interface to manage cluster SMTP configuration
 type SMTP interface {
 	// GetSMTPConfig returns the cluster SMTP configuration
@@ -2117,3 +2166,26 @@ type Audit interface {
 	// EmitAuditEvent saves the provided event in the audit log.
 	EmitAuditEvent(context.Context, AuditEventRequest) error
 }
+
+// GetClusterReportRequest specifies which diagnostics are collected
+// into the cluster report
+type GetClusterReportRequest struct {
+	// SiteKey identifies the cluster
+	SiteKey
+	// Profile names the report profile. The full profile is used if unspecified
+	Profile string `json:"profile,omitempty"`
+	// Filters overrides the collectors of the profile
+	Filters []string `json:"filters,omitempty"`
+	// Since overrides the start of the period of the collected logs
+	Since time.Time `json:"since,omitempty"`
+	// Until limits the collected logs to the entries before the specified time
+	Until time.Time `json:"until,omitempty"`
+	// MaxSize limits the total size of the report in bytes
+	MaxSize int64 `json:"max_size,omitempty"`
+	// Namespaces overrides the Kubernetes namespaces to collect diagnostics for
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Nodes overrides the nodes to collect diagnostics from
+	Nodes []string `json:"nodes,omitempty"`
+	// RedactIPs specifies whether IP addresses are scrubbed from the report
+	RedactIPs bool `json:"redact_ips,omitempty"`
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// newStreamWatcher returns a watcher that decodes the events
+// from the specified stream until the stream is exhausted,
+// the context expires or the watcher is closed
+func newStreamWatcher(ctx context.Context, stream io.ReadCloser) *streamWatcher {
+	w := &streamWatcher{
+		stream:  stream,
+		eventsC: make(chan storage.Event),
+		doneC:   make(chan struct{}),
+		closeC:  make(chan struct{}),
+	}
+	go w.receive()
+	go func() {
+		select {
+		case <-ctx.Done():
+			w.Close()
+		case <-w.doneC:
+		}
+	}()
+	return w
+}
+
+type streamWatcher struct {
+	stream    io.ReadCloser
+	eventsC   chan storage.Event
+	doneC     chan struct{}
+	closeC    chan struct{}
+	closeOnce sync.Once
+	mu        sync.Mutex
+	err       error
+}
+
+// Events returns the channel with the change events
+func (w *streamWatcher) Events() <-chan storage.Event {
+	return w.eventsC
+}
+
+// Done returns the channel that is closed when the watcher is closed
+func (w *streamWatcher) Done() <-chan struct{} {
+	return w.doneC
+}
+
+// Error returns the error the watcher has been closed with, if any
+func (w *streamWatcher) Error() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close closes the event stream and waits for the watcher to stop
+func (w *streamWatcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.closeC)
+		w.stream.Close()
+	})
+	<-w.doneC
+	return nil
+}
+
+func (w *streamWatcher) receive() {
+	var err error
+	defer func() {
+		w.mu.Lock()
+		w.err = err
+		w.mu.Unlock()
+		close(w.doneC)
+	}()
+	decoder := json.NewDecoder(w.stream)
+	for {
+		var event storage.Event
+		if err = decoder.Decode(&event); err != nil {
+			select {
+			case <-w.closeC:
+				err = nil
+			default:
+				if err == io.EOF {
+					err = trace.ConnectionProblem(nil, "event stream closed by the server")
+				} else {
+					err = trace.Wrap(err)
+				}
+			}
+			return
+		}
+		select {
+		case w.eventsC <- event:
+		case <-w.closeC:
+			err = nil
+			return
+		}
+	}
+}
@@ -667,6 +667,20 @@ func (c *Client) GetSiteOperationProgress(key ops.SiteOperationKey) (*ops.Progre
 	return &progressEntry, nil
 }
 
+// WatchEvents returns a watcher that streams the changes of the cluster state
+func (c *Client) WatchEvents(ctx context.Context, key ops.SiteKey, filter storage.WatchFilter) (storage.Watcher, error) {
+	values := url.Values{"kind": filter.Kinds}
+	if filter.OperationID != "" {
+		values.Set("operation_id", filter.OperationID)
+	}
+	endpoint := c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "events")
+	stream, err := httplib.SetupWebsocketClient(ctx, &c.Client, fmt.Sprintf("%v?%v", endpoint, values.Encode()), c.dialer)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return newStreamWatcher(ctx, stream), nil
+}
+
 func (c *Client) CreateProgressEntry(key ops.SiteOperationKey, entry ops.ProgressEntry) error {
 	_, err := c.PostJSON(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "operations", "common", key.OperationID, "progress"), entry)
 	if err != nil {
@@ -683,8 +697,26 @@ func (c *Client) GetSiteOperationCrashReport(key ops.SiteOperationKey) (io.ReadC
 	return file.Body(), nil
 }
 
-func (c *Client) GetSiteReport(key ops.SiteKey) (io.ReadCloser, error) {
-	file, err := c.GetFile(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "report"), url.Values{})
+func (c *Client) GetSiteReport(req ops.GetClusterReportRequest) (io.ReadCloser, error) {
+	query := url.Values{
+		"profile":   []string{req.Profile},
+		"filter":    req.Filters,
+		"namespace": req.Namespaces,
+		"node":      req.Nodes,
+	}
+	if !req.Since.IsZero() {
+		query.Set("since", req.Since.UTC().Format(time.RFC3339))
+	}
+	if !req.Until.IsZero() {
+		query.Set("until", req.Until.UTC().Format(time.RFC3339))
+	}
+	if req.MaxSize != 0 {
+		query.Set("max_size", strconv.FormatInt(req.MaxSize, 10))
+	}
+	if req.RedactIPs {
+		query.Set("redact_ips", "true")
+	}
+	file, err := c.GetFile(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "report"), query)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -1214,6 +1246,26 @@ func (c *Client) UpdatePersistentStorage(ctx context.Context, req ops.UpdatePers
 	return nil
 }
 
+// GetResourceRevisions returns the recorded revisions of cluster resources
+func (c *Client) GetResourceRevisions(ctx context.Context, req ops.GetResourceRevisionsRequest) ([]storage.ResourceRevision, error) {
+	query := url.Values{
+		"kind":  []string{req.Kind},
+		"limit": []string{strconv.Itoa(req.Limit)},
+	}
+	if req.Name != nil {
+		query.Set("name", *req.Name)
+	}
+	response, err := c.Get(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "resources", "revisions"), query)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var revisions []storage.ResourceRevision
+	if err := json.Unmarshal(response.Bytes(), &revisions); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return revisions, nil
+}
+
 func (c *Client) GetApplicationEndpoints(key ops.SiteKey) ([]ops.Endpoint, error) {
 	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "endpoints"), url.Values{})
 	if err != nil {
@@ -1227,13 +1279,17 @@ func (c *Client) GetApplicationEndpoints(key ops.SiteKey) ([]ops.Endpoint, error
 }
 
 // ValidateServers runs pre-installation checks
-func (c *Client) ValidateServers(ctx context.Context, req ops.ValidateServersRequest) error {
-	_, err := c.PostJSONWithContext(ctx, c.Endpoint(
+func (c *Client) ValidateServers(ctx context.Context, req ops.ValidateServersRequest) (*ops.ValidateServersResponse, error) {
+	out, err := c.PostJSONWithContext(ctx, c.Endpoint(
 		"accounts", req.AccountID, "sites", req.SiteDomain, "prechecks"), req)
 	if err != nil {
-		return trace.Wrap(err)
+		return nil, trace.Wrap(err)
 	}
-	return nil
+	var resp ops.ValidateServersResponse
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &resp, nil
 }
 
 func (c *Client) GetAppInstaller(req ops.AppInstallerRequest) (io.ReadCloser, error) {
@@ -1664,3 +1720,72 @@ func (c *Client) LocalClusterKey() (ops.SiteKey, error) {
 	}
 	return siteKey, nil
 }
+
+// GetBackupPolicies returns the cluster backup policies
+//
+// Returned policies exclude secrets unless withSecrets is true.
+func (c *Client) GetBackupPolicies(key ops.SiteKey, withSecrets bool) ([]storage.BackupPolicy, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "backuppolicies"),
+		url.Values{constants.WithSecretsParam: []string{fmt.Sprintf("%t", withSecrets)}})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(out.Bytes(), &items); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	policies := make([]storage.BackupPolicy, len(items))
+	for i, raw := range items {
+		policy, err := storage.UnmarshalBackupPolicy(raw)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		policies[i] = policy
+	}
+	return policies, nil
+}
+
+// UpsertBackupPolicy creates or updates the backup policy
+func (c *Client) UpsertBackupPolicy(ctx context.Context, key ops.SiteKey, policy storage.BackupPolicy) error {
+	bytes, err := storage.MarshalBackupPolicy(policy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = c.PutJSON(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "backuppolicies"),
+		&UpsertResourceRawReq{Resource: bytes})
+	return trace.Wrap(err)
+}
+
+// DeleteBackupPolicy deletes the backup policy by name
+func (c *Client) DeleteBackupPolicy(ctx context.Context, key ops.SiteKey, name string) error {
+	_, err := c.Delete(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "backuppolicies", name))
+	return trace.Wrap(err)
+}
+
+// ListBackups returns the backups taken by all backup policies
+func (c *Client) ListBackups(ctx context.Context, key ops.SiteKey) ([]storage.Backup, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "backups"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var backups []storage.Backup
+	if err := json.Unmarshal(out.Bytes(), &backups); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return backups, nil
+}
+
+// GetBackupReader returns the reader of the backup archive
+func (c *Client) GetBackupReader(ctx context.Context, key ops.SiteKey, name string) (io.ReadCloser, error) {
+	file, err := c.GetFile(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "backups", name), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return file.Body(), nil
+}
+
+// DeleteBackup deletes the stored backup by name
+func (c *Client) DeleteBackup(ctx context.Context, key ops.SiteKey, name string) error {
+	_, err := c.Delete(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "backups", name))
+	return trace.Wrap(err)
+}
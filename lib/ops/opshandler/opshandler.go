@@ -23,6 +23,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -35,6 +36,7 @@ import (
 	"github.com/gravitational/gravity/lib/ops/events"
 	"github.com/gravitational/gravity/lib/ops/opsclient"
 	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/pack/webpack"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/users"
@@ -235,6 +237,7 @@ func NewWebHandler(cfg WebHandlerConfig) (*WebHandler, error) {
 	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/logs", h.needsAuth(h.streamOperationLogs))
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/progress", h.needsAuth(h.getSiteOperationProgress))
 	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/progress", h.needsAuth(h.createProgressEntry))
+	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/events", h.needsAuth(h.watchEvents))
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/crash-report", h.needsAuth(h.getSiteOperationCrashReport))
 	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/complete", h.needsAuth(h.completeSiteOperation))
 	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/plan", h.needsAuth(h.createOperationPlan))
@@ -254,6 +257,14 @@ func NewWebHandler(cfg WebHandlerConfig) (*WebHandler, error) {
 	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/smtp", h.needsAuth(h.updateSMTPConfig))
 	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/smtp", h.needsAuth(h.deleteSMTPConfig))
 
+	// backups
+	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/backuppolicies", h.needsAuth(h.getBackupPolicies))
+	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/backuppolicies", h.needsAuth(h.upsertBackupPolicy))
+	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/backuppolicies/:name", h.needsAuth(h.deleteBackupPolicy))
+	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/backups", h.needsAuth(h.listBackups))
+	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/backups/:name", h.needsAuth(h.getBackup))
+	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/backups/:name", h.needsAuth(h.deleteBackup))
+
 	// monitoring
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/monitoring/alerts", h.needsAuth(h.getAlerts))
 	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/monitoring/alerts/:name", h.needsAuth(h.updateAlert))
@@ -277,6 +288,9 @@ func NewWebHandler(cfg WebHandlerConfig) (*WebHandler, error) {
 	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/persistentstorage", h.needsAuth(h.getPersistentStorage))
 	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/persistentstorage", h.needsAuth(h.updatePersistentStorage))
 
+	// resource history
+	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/resources/revisions", h.needsAuth(h.getResourceRevisions))
+
 	// validation
 	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/validation/remoteaccess", h.needsAuth(h.validateRemoteAccess))
 
@@ -286,6 +300,13 @@ func NewWebHandler(cfg WebHandlerConfig) (*WebHandler, error) {
 	// app installer
 	h.GET("/portal/v1/accounts/:account_id/apps/:repository_id/:package_name/:version/installer", h.needsAuth(h.getAppInstaller))
 
+	// resumable package uploads
+	h.POST("/portal/v1/accounts/:account_id/uploads", h.needsAuth(h.withPackages(webpack.StartUpload)))
+	h.GET("/portal/v1/accounts/:account_id/uploads/:upload_id", h.needsAuth(h.withPackages(webpack.GetUpload)))
+	h.POST("/portal/v1/accounts/:account_id/uploads/:upload_id/chunks", h.needsAuth(h.withPackages(webpack.AppendUpload)))
+	h.POST("/portal/v1/accounts/:account_id/uploads/:upload_id/commit", h.needsAuth(h.withPackages(webpack.CommitUpload)))
+	h.DELETE("/portal/v1/accounts/:account_id/uploads/:upload_id", h.needsAuth(h.withPackages(webpack.AbortUpload)))
+
 	// web helpers - special functions for the UI
 	h.GET("/portal/v1/webhelpers/accounts/:account_id/sites/:site_domain/operations/last/:operation_type", h.needsAuth(h.getLastOperation))
 
@@ -1039,10 +1060,15 @@ func (h *WebHandler) activateSite(w http.ResponseWriter, r *http.Request, p http
 
 /* getSiteReport returns a tarball with collected information about the site
 
-   GET /portal/v1/accounts/:account_id/sites/:site_domain/report
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/report?profile=<profile>&filter=<collector>&since=<time>&until=<time>&max_size=<bytes>&namespace=<namespace>&node=<node>&redact_ips=<bool>
 */
 func (h *WebHandler) getSiteReport(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
-	report, err := context.Operator.GetSiteReport(siteKey(p))
+	req, err := parseClusterReportRequest(r.URL.Query())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	req.SiteKey = siteKey(p)
+	report, err := context.Operator.GetSiteReport(*req)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -1057,6 +1083,43 @@ func (h *WebHandler) getSiteReport(w http.ResponseWriter, r *http.Request, p htt
 	return trace.Wrap(err)
 }
 
+// parseClusterReportRequest parses the cluster report request from the URL query
+func parseClusterReportRequest(query url.Values) (*ops.GetClusterReportRequest, error) {
+	req := ops.GetClusterReportRequest{
+		Profile:    query.Get("profile"),
+		Filters:    query["filter"],
+		Namespaces: query["namespace"],
+		Nodes:      query["node"],
+	}
+	if since := query.Get("since"); since != "" {
+		var err error
+		req.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			return nil, trace.BadParameter("invalid start of the log period %q: %v", since, err)
+		}
+	}
+	if until := query.Get("until"); until != "" {
+		var err error
+		req.Until, err = time.Parse(time.RFC3339, until)
+		if err != nil {
+			return nil, trace.BadParameter("invalid end of the log period %q: %v", until, err)
+		}
+	}
+	if maxSize := query.Get("max_size"); maxSize != "" {
+		var err error
+		req.MaxSize, err = strconv.ParseInt(maxSize, 10, 64)
+		if err != nil {
+			return nil, trace.BadParameter("invalid report size limit %q: %v", maxSize, err)
+		}
+	}
+	redactIPs, _, err := telehttplib.ParseBool(query, "redact_ips")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	req.RedactIPs = redactIPs
+	return &req, nil
+}
+
 /*  completeFinalInstallStep marks the site as having completed the last installation step
 
     POST /portal/v1/accounts/:account_id/sites/:site_domain/complete
@@ -1216,6 +1279,14 @@ func (h *WebHandler) signSSHKey(w http.ResponseWriter, r *http.Request, p httpro
 /*  validateServers runs a pre-installation checks for a site
 
     POST /portal/v1/accounts/:account_id/sites/:site_domain/prechecks
+
+    Success response:
+
+    {
+       "probes": [{"node": "node-1/10.0.0.1", "checker": "port-checker", "status": "passed", "severity": "critical", ...}]
+    }
+
+    Failed checks are reported as an error
 */
 func (h *WebHandler) validateServers(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
 	d := json.NewDecoder(r.Body)
@@ -1223,11 +1294,11 @@ func (h *WebHandler) validateServers(w http.ResponseWriter, r *http.Request, p h
 	if err := d.Decode(&req); err != nil {
 		return trace.BadParameter(err.Error())
 	}
-	err := context.Operator.ValidateServers(context.Context, req)
+	resp, err := context.Operator.ValidateServers(context.Context, req)
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("ok"))
+	roundtrip.ReplyJSON(w, http.StatusOK, resp)
 	return nil
 }
 
@@ -1844,7 +1915,7 @@ func (h *WebHandler) streamOperationLogs(w http.ResponseWriter, r *http.Request,
 
 */
 func (h *WebHandler) getSiteOperationCrashReport(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
-	report, err := context.Operator.GetSiteReport(siteKey(p))
+	report, err := context.Operator.GetSiteReport(ops.GetClusterReportRequest{SiteKey: siteKey(p)})
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -1877,6 +1948,57 @@ func (h *WebHandler) getSiteOperationProgress(w http.ResponseWriter, r *http.Req
 	return nil
 }
 
+/*watchEvents is a web socket method that streams the changes of the cluster,
+  its operations, progress entries and configuration, one JSON-encoded event per line
+
+  GET /portal/v1/accounts/:account_id/sites/:site_domain/events?kind=operation&kind=progress&operation_id=<operation-id>
+
+Event:
+
+  {
+	"type": "one of 'put' or 'delete'",
+	"kind": "one of 'cluster', 'operation', 'progress' or 'cluster_config'",
+	"operation": {...}
+  }
+*/
+func (h *WebHandler) watchEvents(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	filter := storage.WatchFilter{
+		Kinds:       r.URL.Query()["kind"],
+		OperationID: r.URL.Query().Get("operation_id"),
+	}
+	watcher, err := context.Operator.WatchEvents(r.Context(), siteKey(p), filter)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer watcher.Close()
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(streamEvents(watcher, writer))
+	}()
+	ws := &httplib.WebSocketReader{
+		Reader: reader,
+	}
+	defer ws.Close()
+	ws.Handler().ServeHTTP(w, r)
+	return nil
+}
+
+// streamEvents writes the events received by the watcher to w
+// until the watcher is closed
+func streamEvents(watcher storage.Watcher, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case event := <-watcher.Events():
+			if err := encoder.Encode(event); err != nil {
+				return trace.Wrap(err)
+			}
+		case <-watcher.Done():
+			return trace.Wrap(watcher.Error())
+		}
+	}
+}
+
 /* createProgressEntry creates a new operation progress entry
 
    POST /portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/progress
@@ -2185,6 +2307,140 @@ func (h *WebHandler) deleteSMTPConfig(w http.ResponseWriter, r *http.Request, p
 	return nil
 }
 
+/* getBackupPolicies returns the cluster backup policies
+
+     GET /portal/v1/accounts/:account_id/sites/:site_domain/backuppolicies?with_secrets=<true|false>
+
+   Success Response:
+
+     []storage.BackupPolicy
+*/
+func (h *WebHandler) getBackupPolicies(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var withSecrets bool
+	var err error
+	if r.URL.Query().Get(constants.WithSecretsParam) != "" {
+		withSecrets, _, err = telehttplib.ParseBool(r.URL.Query(), constants.WithSecretsParam)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	policies, err := context.Operator.GetBackupPolicies(siteKey(p), withSecrets)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	items := make([]json.RawMessage, len(policies))
+	for i, policy := range policies {
+		bytes, err := storage.MarshalBackupPolicy(policy)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		items[i] = bytes
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, items)
+	return nil
+}
+
+/* upsertBackupPolicy creates or updates the backup policy
+
+     PUT /portal/v1/accounts/:account_id/sites/:site_domain/backuppolicies
+
+   Success Response:
+
+     {
+       "message": "backup policy updated"
+     }
+*/
+func (h *WebHandler) upsertBackupPolicy(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req opsclient.UpsertResourceRawReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	policy, err := storage.UnmarshalBackupPolicy(req.Resource)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if req.TTL != 0 {
+		policy.SetTTL(clockwork.NewRealClock(), req.TTL)
+	}
+	err = context.Operator.UpsertBackupPolicy(r.Context(), siteKey(p), policy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("backup policy updated"))
+	return nil
+}
+
+/* deleteBackupPolicy deletes the backup policy
+
+     DELETE /portal/v1/accounts/:account_id/sites/:site_domain/backuppolicies/:name
+
+   Success Response:
+
+     {
+       "message": "backup policy deleted"
+     }
+*/
+func (h *WebHandler) deleteBackupPolicy(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.DeleteBackupPolicy(r.Context(), siteKey(p), p.ByName("name"))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("backup policy deleted"))
+	return nil
+}
+
+/* listBackups returns the backups taken by all backup policies
+
+     GET /portal/v1/accounts/:account_id/sites/:site_domain/backups
+
+   Success Response:
+
+     []storage.Backup
+*/
+func (h *WebHandler) listBackups(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	backups, err := context.Operator.ListBackups(r.Context(), siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, backups)
+	return nil
+}
+
+/* getBackup returns the backup archive
+
+     GET /portal/v1/accounts/:account_id/sites/:site_domain/backups/:name
+*/
+func (h *WebHandler) getBackup(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	name := p.ByName("name")
+	reader, err := context.Operator.GetBackupReader(r.Context(), siteKey(p), name)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v.tar.gz", name))
+	_, err = io.Copy(w, reader)
+	return trace.Wrap(err)
+}
+
+/* deleteBackup deletes the stored backup
+
+     DELETE /portal/v1/accounts/:account_id/sites/:site_domain/backups/:name
+
+   Success Response:
+
+     {
+       "message": "backup deleted"
+     }
+*/
+func (h *WebHandler) deleteBackup(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.DeleteBackup(r.Context(), siteKey(p), p.ByName("name"))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("backup deleted"))
+	return nil
+}
+
 /* getApplicationEndpoints returns application endpoints for a deployed cluster
 
      GET /portal/v1/accounts/:account_id/sites/:site_domain/endpoints
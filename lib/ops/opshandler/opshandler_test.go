@@ -135,6 +135,14 @@ func (s *OpsHandlerSuite) TestInstallInstructions(c *C) {
 	s.suite.InstallInstructions(c)
 }
 
+func (s *OpsHandlerSuite) TestWatchEvents(c *C) {
+	s.suite.WatchEvents(c)
+}
+
+func (s *OpsHandlerSuite) TestBackupPolicies(c *C) {
+	s.suite.BackupPolicies(c)
+}
+
 func (s *OpsHandlerSuite) TestGithubConnector(c *C) {
 	key := ops.SiteKey{AccountID: "a", SiteDomain: "b"}
 
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/ops"
@@ -361,6 +362,40 @@ func (h *WebHandler) updatePersistentStorage(w http.ResponseWriter, r *http.Requ
 	return nil
 }
 
+/* getResourceRevisions returns the recorded revisions of cluster resources
+
+     GET /portal/v1/accounts/:account_id/sites/:site_domain/resources/revisions?kind=<kind>&name=<name>&limit=<limit>
+
+   Success response:
+
+     []storage.ResourceRevision
+*/
+func (h *WebHandler) getResourceRevisions(w http.ResponseWriter, r *http.Request, p httprouter.Params, ctx *HandlerContext) error {
+	query := r.URL.Query()
+	req := ops.GetResourceRevisionsRequest{
+		SiteKey: siteKey(p),
+		ResourceRevisionFilter: storage.ResourceRevisionFilter{
+			Kind: query.Get("kind"),
+		},
+	}
+	if names, ok := query["name"]; ok && len(names) != 0 {
+		req.Name = &names[0]
+	}
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		req.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return trace.BadParameter("invalid limit %q", limit)
+		}
+	}
+	revisions, err := ctx.Operator.GetResourceRevisions(r.Context(), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, revisions)
+	return nil
+}
+
 func rawMessage(w http.ResponseWriter, data []byte, err error) error {
 	if err != nil {
 		return trace.Wrap(err)
@@ -0,0 +1,35 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opshandler
+
+import (
+	"net/http"
+
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/pack/webpack"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// withPackages adapts the package service handler fn to the ops handler
+// by passing it the package service bound to the authenticated user
+func (h *WebHandler) withPackages(fn webpack.PackageHandler) ServiceHandle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+		packages := pack.PackagesWithACL(h.cfg.Packages, h.cfg.Users, context.User, context.Checker)
+		return fn(w, r, p, packages)
+	}
+}
@@ -400,6 +400,15 @@ func (r *Router) SiteExpandOperationStart(key ops.SiteOperationKey) error {
 	return client.SiteExpandOperationStart(key)
 }
 
+// WatchEvents returns a watcher that streams the changes of the cluster state
+func (r *Router) WatchEvents(ctx context.Context, key ops.SiteKey, filter storage.WatchFilter) (storage.Watcher, error) {
+	client, err := r.PickOperationClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.WatchEvents(ctx, key, filter)
+}
+
 func (r *Router) GetSiteOperationProgress(key ops.SiteOperationKey) (*ops.ProgressEntry, error) {
 	client, err := r.PickOperationClient(key.SiteDomain)
 	if err != nil {
@@ -416,19 +425,19 @@ func (r *Router) CreateProgressEntry(key ops.SiteOperationKey, entry ops.Progres
 	return client.CreateProgressEntry(key, entry)
 }
 
-func (r *Router) GetSiteReport(key ops.SiteKey) (io.ReadCloser, error) {
-	client, err := r.PickClient(key.SiteDomain)
+func (r *Router) GetSiteReport(req ops.GetClusterReportRequest) (io.ReadCloser, error) {
+	client, err := r.PickClient(req.SiteDomain)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	return client.GetSiteReport(key)
+	return client.GetSiteReport(req)
 }
 
 // ValidateServers runs pre-installation checks
-func (r *Router) ValidateServers(ctx context.Context, req ops.ValidateServersRequest) error {
+func (r *Router) ValidateServers(ctx context.Context, req ops.ValidateServersRequest) (*ops.ValidateServersResponse, error) {
 	client, err := r.WizardClient(req.SiteDomain)
 	if err != nil {
-		return trace.Wrap(err)
+		return nil, trace.Wrap(err)
 	}
 	return client.ValidateServers(ctx, req)
 }
@@ -712,6 +721,15 @@ func (r *Router) UpdatePersistentStorage(ctx context.Context, req ops.UpdatePers
 	return client.UpdatePersistentStorage(ctx, req)
 }
 
+// GetResourceRevisions returns the recorded revisions of cluster resources
+func (r *Router) GetResourceRevisions(ctx context.Context, req ops.GetResourceRevisionsRequest) ([]storage.ResourceRevision, error) {
+	client, err := r.PickClient(req.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetResourceRevisions(ctx, req)
+}
+
 func (r *Router) GetApplicationEndpoints(key ops.SiteKey) ([]ops.Endpoint, error) {
 	client, err := r.RemoteClient(key.SiteDomain)
 	if err != nil {
@@ -944,3 +962,57 @@ func (r *Router) CreateUserReset(ctx context.Context, req ops.CreateUserResetReq
 	}
 	return client.CreateUserReset(ctx, req)
 }
+
+// GetBackupPolicies returns the cluster backup policies
+func (r *Router) GetBackupPolicies(key ops.SiteKey, withSecrets bool) ([]storage.BackupPolicy, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetBackupPolicies(key, withSecrets)
+}
+
+// UpsertBackupPolicy creates or updates the backup policy
+func (r *Router) UpsertBackupPolicy(ctx context.Context, key ops.SiteKey, policy storage.BackupPolicy) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.UpsertBackupPolicy(ctx, key, policy)
+}
+
+// DeleteBackupPolicy deletes the backup policy by name
+func (r *Router) DeleteBackupPolicy(ctx context.Context, key ops.SiteKey, name string) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.DeleteBackupPolicy(ctx, key, name)
+}
+
+// ListBackups returns the backups taken by all backup policies
+func (r *Router) ListBackups(ctx context.Context, key ops.SiteKey) ([]storage.Backup, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.ListBackups(ctx, key)
+}
+
+// GetBackupReader returns the reader of the backup archive
+func (r *Router) GetBackupReader(ctx context.Context, key ops.SiteKey, name string) (io.ReadCloser, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetBackupReader(ctx, key, name)
+}
+
+// DeleteBackup deletes the stored backup by name
+func (r *Router) DeleteBackup(ctx context.Context, key ops.SiteKey, name string) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.DeleteBackup(ctx, key, name)
+}
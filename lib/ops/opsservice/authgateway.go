@@ -53,6 +53,7 @@ func (o *Operator) UpsertAuthGateway(ctx context.Context, key ops.SiteKey, gw st
 		return trace.Wrap(err)
 	}
 	events.Emit(ctx, o, events.AuthGatewayUpdated)
+	o.recordResourceRevision(ctx, storage.KindAuthGateway, "", gw, false)
 	return nil
 }
 
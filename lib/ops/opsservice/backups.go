@@ -0,0 +1,127 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+	"io"
+
+	"github.com/gravitational/gravity/lib/backup"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// GetBackupPolicies returns the cluster backup policies.
+//
+// Returned policies exclude encryption keys and object storage secret
+// access keys unless withSecrets is true.
+func (o *Operator) GetBackupPolicies(key ops.SiteKey, withSecrets bool) ([]storage.BackupPolicy, error) {
+	if _, err := o.backend().GetSite(key.SiteDomain); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	policies, err := o.backend().GetBackupPolicies()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if !withSecrets {
+		for i, policy := range policies {
+			policies[i] = policy.WithoutSecrets()
+		}
+	}
+	return policies, nil
+}
+
+// UpsertBackupPolicy creates or updates the backup policy
+func (o *Operator) UpsertBackupPolicy(ctx context.Context, key ops.SiteKey, policy storage.BackupPolicy) error {
+	if _, err := o.backend().GetSite(key.SiteDomain); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := storage.CheckBackupPolicySecrets(policy); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := o.backend().UpsertBackupPolicy(policy); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.BackupPolicyCreated, events.Fields{
+		events.FieldName: policy.GetName(),
+	})
+	return nil
+}
+
+// DeleteBackupPolicy deletes the backup policy by name.
+// The backups taken by the policy are kept
+func (o *Operator) DeleteBackupPolicy(ctx context.Context, key ops.SiteKey, name string) error {
+	if _, err := o.backend().GetSite(key.SiteDomain); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := o.backend().DeleteBackupPolicy(name); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.BackupPolicyDeleted, events.Fields{
+		events.FieldName: name,
+	})
+	return nil
+}
+
+// ListBackups returns the backups taken by all backup policies
+func (o *Operator) ListBackups(ctx context.Context, key ops.SiteKey) ([]storage.Backup, error) {
+	if _, err := o.backend().GetSite(key.SiteDomain); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	backups, err := backup.List(ctx, o.backend())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return backups, nil
+}
+
+// GetBackupReader returns the reader of the backup archive
+func (o *Operator) GetBackupReader(ctx context.Context, key ops.SiteKey, name string) (io.ReadCloser, error) {
+	if _, err := o.backend().GetSite(key.SiteDomain); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	_, store, err := backup.Find(ctx, o.backend(), name)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	reader, err := store.Open(ctx, name)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return reader, nil
+}
+
+// DeleteBackup deletes the stored backup by name
+func (o *Operator) DeleteBackup(ctx context.Context, key ops.SiteKey, name string) error {
+	if _, err := o.backend().GetSite(key.SiteDomain); err != nil {
+		return trace.Wrap(err)
+	}
+	_, store, err := backup.Find(ctx, o.backend(), name)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := store.Delete(ctx, name); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.BackupDeleted, events.Fields{
+		events.FieldName: name,
+	})
+	return nil
+}
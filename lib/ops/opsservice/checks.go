@@ -25,30 +25,36 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-// ValidateServers runs preflight checks before the installation
-func (o *Operator) ValidateServers(ctx context.Context, req ops.ValidateServersRequest) error {
+// ValidateServers runs preflight checks before the installation.
+// Returns an error if any of the checks has failed critically,
+// otherwise the results of all checks are reported in the response
+func (o *Operator) ValidateServers(ctx context.Context, req ops.ValidateServersRequest) (*ops.ValidateServersResponse, error) {
 	log.Infof("Validating servers: %#v.", req)
 
 	op, err := o.GetSiteOperation(req.OperationKey())
 	if err != nil {
-		return trace.Wrap(err)
+		return nil, trace.Wrap(err)
 	}
 
 	cluster, err := o.openSite(req.SiteKey())
 	if err != nil {
-		return trace.Wrap(err)
+		return nil, trace.Wrap(err)
 	}
 
 	infos, err := cluster.agentService().GetServerInfos(ctx, op.Key())
 	if err != nil {
-		return trace.Wrap(err)
+		return nil, trace.Wrap(err)
 	}
 
-	err = ops.CheckServers(ctx, op.Key(), infos, req.Servers,
+	results, err := ops.CheckServers(ctx, op.Key(), infos, req.Servers,
 		cluster.agentService(), cluster.app.Manifest)
 	if err != nil {
-		return trace.Wrap(ops.FormatValidationError(err))
+		return nil, trace.Wrap(ops.FormatValidationError(err))
 	}
 
-	return nil
+	if err := results.Error(); err != nil {
+		return nil, trace.Wrap(ops.FormatValidationError(err))
+	}
+
+	return &ops.ValidateServersResponse{Probes: results}, nil
 }
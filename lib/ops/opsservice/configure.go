@@ -974,7 +974,13 @@ func (s *site) getPlanetConfig(config planetConfig) (args []string, err error) {
 	}
 	args = append(args, dockerArgs...)
 
-	etcdArgs := manifest.EtcdArgs(*profile)
+	var etcdArgs []string
+	etcdArgs = append(etcdArgs, manifest.EtcdArgs(*profile)...)
+	if config.config != nil {
+		if etcd := config.config.GetComponentConfigs().Etcd; etcd != nil {
+			etcdArgs = append(etcdArgs, etcd.ExtraArgs...)
+		}
+	}
 	if len(etcdArgs) != 0 {
 		args = append(args, fmt.Sprintf("--etcd-options=%v", strings.Join(etcdArgs, " ")))
 	}
@@ -983,6 +989,11 @@ func (s *site) getPlanetConfig(config planetConfig) (args []string, err error) {
 	if len(manifest.KubeletArgs(*profile)) != 0 {
 		kubeletArgs = append(kubeletArgs, manifest.KubeletArgs(*profile)...)
 	}
+	if config.config != nil {
+		if kubelet := config.config.GetKubeletConfig(); kubelet != nil {
+			kubeletArgs = append(kubeletArgs, kubelet.ExtraArgs...)
+		}
+	}
 
 	if len(kubeletArgs) > 0 {
 		args = append(args, fmt.Sprintf("--kubelet-options=%v", strings.Join(kubeletArgs, " ")))
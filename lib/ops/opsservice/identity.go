@@ -64,6 +64,7 @@ func (o *Operator) UpsertClusterAuthPreference(ctx context.Context, key ops.Site
 		return trace.Wrap(err)
 	}
 	events.Emit(ctx, o, events.AuthPreferenceUpdated)
+	o.recordResourceRevision(ctx, teleservices.KindClusterAuthPreference, "", auth, false)
 	return nil
 }
 
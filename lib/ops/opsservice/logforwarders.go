@@ -80,6 +80,10 @@ func (o *Operator) CreateLogForwarder(ctx context.Context, key ops.SiteKey, forw
 			"this operator does not support log forwarders management")
 	}
 
+	if err := forwarder.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+
 	err := o.cfg.LogForwarders.Create(forwarder)
 	if err != nil {
 		return trace.Wrap(err)
@@ -88,6 +92,7 @@ func (o *Operator) CreateLogForwarder(ctx context.Context, key ops.SiteKey, forw
 	events.Emit(ctx, o, events.LogForwarderCreated, events.Fields{
 		events.FieldName: forwarder.GetName(),
 	})
+	o.recordResourceRevision(ctx, storage.KindLogForwarder, forwarder.GetName(), forwarder, false)
 
 	err = o.cfg.LogForwarders.Reload()
 	if err != nil {
@@ -104,6 +109,10 @@ func (o *Operator) UpdateLogForwarder(ctx context.Context, key ops.SiteKey, forw
 			"this operator does not support log forwarders management")
 	}
 
+	if err := forwarder.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+
 	err := o.cfg.LogForwarders.Update(forwarder)
 	if err != nil {
 		return trace.Wrap(err)
@@ -112,6 +121,7 @@ func (o *Operator) UpdateLogForwarder(ctx context.Context, key ops.SiteKey, forw
 	events.Emit(ctx, o, events.LogForwarderCreated, events.Fields{
 		events.FieldName: forwarder.GetName(),
 	})
+	o.recordResourceRevision(ctx, storage.KindLogForwarder, forwarder.GetName(), forwarder, false)
 
 	err = o.cfg.LogForwarders.Reload()
 	if err != nil {
@@ -128,6 +138,7 @@ func (o *Operator) DeleteLogForwarder(ctx context.Context, key ops.SiteKey, name
 			"this operator does not support log forwarders management")
 	}
 
+	previous := o.getLogForwarder(name)
 	err := o.cfg.LogForwarders.Delete(name)
 	if err != nil {
 		return trace.Wrap(err)
@@ -136,6 +147,7 @@ func (o *Operator) DeleteLogForwarder(ctx context.Context, key ops.SiteKey, name
 	events.Emit(ctx, o, events.LogForwarderDeleted, events.Fields{
 		events.FieldName: name,
 	})
+	o.recordResourceRevision(ctx, storage.KindLogForwarder, name, previous, true)
 
 	err = o.cfg.LogForwarders.Reload()
 	if err != nil {
@@ -145,6 +157,22 @@ func (o *Operator) DeleteLogForwarder(ctx context.Context, key ops.SiteKey, name
 	return nil
 }
 
+// getLogForwarder returns the log forwarder with the specified name
+// or nil if it cannot be found
+func (o *Operator) getLogForwarder(name string) storage.LogForwarder {
+	forwarders, err := o.cfg.LogForwarders.Get()
+	if err != nil {
+		o.WithError(err).Warn("Failed to query log forwarders.")
+		return nil
+	}
+	for _, forwarder := range forwarders {
+		if forwarder.GetName() == name {
+			return forwarder
+		}
+	}
+	return nil
+}
+
 // LogForwarderControl defines methods for managing log forwarders in Kubernetes,
 // mostly so implementation can be substituted in tests w/o Kubernetes
 type LogForwardersControl interface {
@@ -18,6 +18,7 @@ package opsservice
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/gravitational/gravity/lib/constants"
@@ -167,6 +168,7 @@ func (o *Operator) UpdateAlert(ctx context.Context, key ops.SiteKey, alert stora
 	events.Emit(ctx, o, events.AlertCreated, events.Fields{
 		events.FieldName: alert.GetName(),
 	})
+	o.recordResourceRevision(ctx, storage.KindAlert, alert.GetName(), alert, false)
 	return nil
 }
 
@@ -207,6 +209,8 @@ func (o *Operator) DeleteAlert(ctx context.Context, key ops.SiteKey, name string
 	events.Emit(ctx, o, events.AlertDeleted, events.Fields{
 		events.FieldName: name,
 	})
+	o.recordResourceRevision(ctx, storage.KindAlert, name,
+		rawResource(alert.Data[constants.ResourceSpecKey]), true)
 	return nil
 
 }
@@ -257,6 +261,7 @@ func (o *Operator) UpdateAlertTarget(ctx context.Context, key ops.SiteKey, targe
 	}
 
 	events.Emit(ctx, o, events.AlertTargetCreated)
+	o.recordResourceRevision(ctx, storage.KindAlertTarget, "", target, false)
 	return nil
 
 }
@@ -268,6 +273,12 @@ func (o *Operator) DeleteAlertTarget(ctx context.Context, key ops.SiteKey) error
 		return trace.Wrap(err)
 	}
 
+	previous, err := getConfigMap(client.CoreV1().ConfigMaps(defaults.MonitoringNamespace),
+		constants.AlertTargetConfigMap)
+	if err != nil && !trace.IsNotFound(err) {
+		o.WithError(err).Warn("Failed to query alert target.")
+	}
+
 	err = rigging.ConvertError(client.CoreV1().ConfigMaps(defaults.MonitoringNamespace).Delete(constants.AlertTargetConfigMap, nil))
 	if err != nil {
 		if trace.IsNotFound(err) {
@@ -277,9 +288,19 @@ func (o *Operator) DeleteAlertTarget(ctx context.Context, key ops.SiteKey) error
 	}
 
 	events.Emit(ctx, o, events.AlertTargetDeleted)
+	o.recordResourceRevision(ctx, storage.KindAlertTarget, "", rawResource(previous), true)
 	return nil
 }
 
+// rawResource returns the resource stored in a config map
+// or nil if the resource is empty
+func rawResource(data string) interface{} {
+	if data == "" {
+		return nil
+	}
+	return json.RawMessage(data)
+}
+
 func getConfigMap(client corev1.ConfigMapInterface, name string) (string, error) {
 	config, err := client.Get(name, metav1.GetOptions{})
 	if err != nil {
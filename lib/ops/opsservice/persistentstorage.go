@@ -68,6 +68,7 @@ func (o *Operator) UpdatePersistentStorage(ctx context.Context, req ops.UpdatePe
 		return trace.Wrap(err)
 	}
 	events.Emit(ctx, o, events.PersistentStorageUpdated)
+	o.recordResourceRevision(ctx, storage.KindPersistentStorage, "", req.Resource, false)
 	err = o.cfg.OpenEBS.RestartNDM()
 	if err != nil {
 		return trace.Wrap(err)
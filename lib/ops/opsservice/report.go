@@ -26,6 +26,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/archive"
@@ -41,22 +43,60 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func (s *site) getClusterReport() (io.ReadCloser, error) {
+// newReportProfile returns the report profile for the specified request.
+// The log period of the profile is resolved relative to now
+func newReportProfile(req ops.GetClusterReportRequest, now time.Time) (*report.Profile, error) {
+	profile, err := report.GetProfile(req.Profile)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if len(req.Filters) != 0 {
+		profile.Filters = req.Filters
+	}
+	if !req.Since.IsZero() {
+		profile.TimeRange.Since = req.Since
+	}
+	if !req.Until.IsZero() {
+		profile.TimeRange.Until = req.Until
+	}
+	if req.MaxSize != 0 {
+		profile.MaxSize = req.MaxSize
+	}
+	if len(req.Namespaces) != 0 {
+		profile.Namespaces = req.Namespaces
+	}
+	if len(req.Nodes) != 0 {
+		profile.Nodes = req.Nodes
+	}
+	if req.RedactIPs {
+		profile.RedactIPs = true
+	}
+	if err := profile.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	profile.TimeRange = profile.LogRange(now)
+	return profile, nil
+}
+
+func (s *site) getClusterReport(profile report.Profile) (io.ReadCloser, error) {
 	op, err := storage.GetLastOperationForCluster(s.backend(), s.domainName)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
-	s.WithField("op", op).Info("Capture debug report for operation.")
+	s.WithFields(log.Fields{
+		"op":      op,
+		"profile": profile.Name,
+	}).Info("Capture debug report for operation.")
 	switch {
 	case isActiveInstallOperation((ops.SiteOperation)(*op)):
-		return s.getClusterInstallReport((ops.SiteOperation)(*op))
+		return s.getClusterInstallReport((ops.SiteOperation)(*op), profile)
 	default:
-		return s.getClusterGenericReport()
+		return s.getClusterGenericReport(profile)
 	}
 }
 
-func (s *site) getClusterInstallReport(op ops.SiteOperation) (io.ReadCloser, error) {
+func (s *site) getClusterInstallReport(op ops.SiteOperation, profile report.Profile) (io.ReadCloser, error) {
 	ctx, err := s.newOperationContext(op)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -78,10 +118,10 @@ func (s *site) getClusterInstallReport(op ops.SiteOperation) (io.ReadCloser, err
 	}
 
 	runner := s.agentRunner(ctx)
-	return s.getReport(runner, remoteServers, master)
+	return s.getReport(runner, remoteServers, master, profile)
 }
 
-func (s *site) getClusterGenericReport() (io.ReadCloser, error) {
+func (s *site) getClusterGenericReport(profile report.Profile) (io.ReadCloser, error) {
 	const noRetry = 1
 	servers, err := s.getTeleportServersWithTimeout(
 		nil,
@@ -112,23 +152,32 @@ func (s *site) getClusterGenericReport() (io.ReadCloser, error) {
 		remoteServers = append(remoteServers, teleportServer)
 	}
 
-	return s.getReport(teleportRunner, remoteServers, master)
+	return s.getReport(teleportRunner, remoteServers, master, profile)
 }
 
-func (s *site) getReport(runner remoteRunner, servers []remoteServer, master remoteServer) (io.ReadCloser, error) {
-	dir, err := ioutil.TempDir("", "report")
+func (s *site) getReport(runner remoteRunner, servers []remoteServer, master remoteServer, profile report.Profile) (io.ReadCloser, error) {
+	nodes, err := filterReportServers(servers, profile)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
-	err = runCollectors(*s, dir)
+	dir, err := ioutil.TempDir("", "report")
 	if err != nil {
-		// Intermediate steps in diagnostics collection are not fatal
-		// to collect all possible pieces in best-effort
-		log.Errorf("failed to run cluster collectors: %v", trace.DebugReport(err))
+		return nil, trace.Wrap(err)
 	}
 
-	collectOperationsLogs(*s, dir)
+	if profile.HasFilter(report.FilterCluster) {
+		err = runCollectors(*s, dir)
+		if err != nil {
+			// Intermediate steps in diagnostics collection are not fatal
+			// to collect all possible pieces in best-effort
+			log.Errorf("failed to run cluster collectors: %v", trace.DebugReport(err))
+		}
+	}
+
+	if profile.HasFilter(report.FilterOperations) {
+		collectOperationsLogs(*s, dir)
+	}
 
 	if len(servers) > 0 {
 		// Use the first master server to collect kubernetes diagnostics
@@ -139,14 +188,49 @@ func (s *site) getReport(runner remoteRunner, servers []remoteServer, master rem
 		}
 		serverRunner := &serverRunner{server: server, runner: runner}
 		reportWriter := getReportWriterForServer(dir, server)
-		if err := s.collectKubernetesInfo(reportWriter, serverRunner); err != nil {
-			log.WithError(err).Error("Failed to collect Kubernetes info.")
+		maxSize := nodeReportMaxSize(profile, len(nodes), s.app.Manifest.ReportCollectors())
+		if profile.HasFilter(report.FilterKubernetes) {
+			if err := s.collectKubernetesInfo(reportWriter, serverRunner, profile, maxSize); err != nil {
+				log.WithError(err).Error("Failed to collect Kubernetes info.")
+			}
+		}
+		if profile.HasFilter(report.FilterEtcd) {
+			err := s.collectEtcdInfo(reportWriter, serverRunner, report.FilterEtcd, "etcd-logs.tar.gz", profile, maxSize)
+			if err != nil {
+				log.WithError(err).Error("Failed to collect etcd logs.")
+			}
 		}
-		if err := s.collectEtcdBackup(reportWriter, serverRunner); err != nil {
-			log.WithError(err).Error("Failed to collect etcd backup.")
+		if profile.HasFilter(report.FilterEtcdBackup) {
+			err := s.collectEtcdInfo(reportWriter, serverRunner, report.FilterEtcdBackup, "etcd-backup.json.tar.gz", profile, maxSize)
+			if err != nil {
+				log.WithError(err).Error("Failed to collect etcd backup.")
+			}
 		}
-		if err := s.collectDebugInfoFromServers(dir, servers, runner); err != nil {
-			log.WithError(err).Error("Failed to collect diagnostics from some nodes.")
+		if profile.HasFilter(report.FilterSystem) {
+			if err := s.collectDebugInfoFromServers(dir, nodes, runner, profile, maxSize); err != nil {
+				log.WithError(err).Error("Failed to collect diagnostics from some nodes.")
+			}
+		}
+		if profile.HasFilter(report.FilterApplication) {
+			if err := s.collectApplicationInfo(dir, server, nodes, runner, maxSize); err != nil {
+				log.WithError(err).Error("Failed to run application collectors.")
+			}
+		}
+	}
+
+	redactor := report.NewRedactor(report.RedactConfig{IPs: profile.RedactIPs})
+	removed, err := redactor.RedactDir(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, trace.Wrap(err)
+	}
+	if len(removed) != 0 {
+		log.WithField("files", removed).Warn("Some diagnostics could not be redacted and have been removed.")
+		err = ioutil.WriteFile(filepath.Join(dir, report.RemovedFilesIndex),
+			[]byte(strings.Join(removed, "\n")+"\n"), defaults.SharedReadWriteMask)
+		if err != nil {
+			os.RemoveAll(dir)
+			return nil, trace.ConvertSystemError(err)
 		}
 	}
 
@@ -175,7 +259,7 @@ func (s *site) getReport(runner remoteRunner, servers []remoteServer, master rem
 //
 //   <server-name>-<resource>
 //
-func (s *site) collectDebugInfoFromServers(dir string, servers []remoteServer, runner remoteRunner) error {
+func (s *site) collectDebugInfoFromServers(dir string, servers []remoteServer, runner remoteRunner, profile report.Profile, maxSize int64) error {
 	err := s.executeOnServers(context.TODO(), servers, func(c context.Context, server remoteServer) error {
 		log.Debugf("collectDebugInfo for %v", server)
 		r := &serverRunner{
@@ -183,7 +267,7 @@ func (s *site) collectDebugInfoFromServers(dir string, servers []remoteServer, r
 			runner: runner,
 		}
 		reportWriter := getReportWriterForServer(dir, server)
-		err := s.collectDebugInfo(reportWriter, r)
+		err := s.collectDebugInfo(reportWriter, r, profile, maxSize)
 		return trace.Wrap(err)
 	})
 	if err != nil {
@@ -192,51 +276,168 @@ func (s *site) collectDebugInfoFromServers(dir string, servers []remoteServer, r
 	return nil
 }
 
-func (s *site) collectDebugInfo(reportWriter report.FileWriter, runner *serverRunner) error {
+func (s *site) collectDebugInfo(reportWriter report.FileWriter, runner *serverRunner, profile report.Profile, maxSize int64) error {
 	w, err := reportWriter.NewWriter("debug-logs.tar.gz")
 	if err != nil {
 		return trace.Wrap(err)
 	}
 	defer w.Close()
 
-	err = runner.RunStream(w, s.gravityCommand("system", "report",
-		fmt.Sprintf("--filter=%v", report.FilterSystem),
-		"--compressed")...)
+	err = runner.RunStream(w, s.gravityCommand(systemReportArgs(report.FilterSystem, profile, maxSize)...)...)
 	if err != nil {
 		return trace.Wrap(err, "failed to collect diagnostics")
 	}
 	return nil
 }
 
-func (s *site) collectKubernetesInfo(reportWriter report.FileWriter, runner *serverRunner) error {
+func (s *site) collectKubernetesInfo(reportWriter report.FileWriter, runner *serverRunner, profile report.Profile, maxSize int64) error {
 	w, err := reportWriter.NewWriter("k8s-logs.tar.gz")
 	if err != nil {
 		return trace.Wrap(err)
 	}
 	defer w.Close()
 
-	err = runner.RunStream(w, s.gravityCommand("system", "report",
-		fmt.Sprintf("--filter=%v", report.FilterKubernetes), "--compressed")...)
+	err = runner.RunStream(w, s.gravityCommand(systemReportArgs(report.FilterKubernetes, profile, maxSize)...)...)
 	if err != nil {
 		return trace.Wrap(err, "failed to collect kubernetes diagnostics")
 	}
 	return nil
 }
 
-func (s *site) collectEtcdBackup(reportWriter report.FileWriter, runner *serverRunner) error {
-	w, err := reportWriter.NewWriter("etcd-backup.json.tar.gz")
+// collectEtcdInfo runs the node report with the specified etcd collector
+// and saves it under the specified name
+func (s *site) collectEtcdInfo(reportWriter report.FileWriter, runner *serverRunner, filter, name string, profile report.Profile, maxSize int64) error {
+	w, err := reportWriter.NewWriter(name)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 	defer w.Close()
-	err = runner.RunStream(w, s.gravityCommand("system", "report", fmt.Sprintf(
-		"--filter=%v", report.FilterEtcd), "--compressed")...)
+	err = runner.RunStream(w, s.gravityCommand(systemReportArgs(filter, profile, maxSize)...)...)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 	return nil
 }
 
+// collectApplicationInfo runs the application-defined collectors.
+// The collectors are run on the specified master node unless they
+// are configured to run on all nodes.
+// maxSize limits the size of the output of each collector run if not 0
+func (s *site) collectApplicationInfo(dir string, master remoteServer, servers []remoteServer, runner remoteRunner, maxSize int64) error {
+	var errors []error
+	var truncated []string
+	for _, collector := range s.app.Manifest.ReportCollectors() {
+		targets := []remoteServer{master}
+		if collector.AllNodes {
+			targets = servers
+		}
+		for _, server := range targets {
+			reportWriter := report.NewBudgetFileWriter(getReportWriterForServer(dir, server), maxSize)
+			err := s.runApplicationCollector(reportWriter,
+				&serverRunner{server: server, runner: runner}, collector)
+			if err != nil {
+				errors = append(errors, trace.Wrap(err, "collector %v failed on %v",
+					collector.Name, server.HostName()))
+			}
+			for _, name := range reportWriter.Truncated() {
+				truncated = append(truncated, fmt.Sprintf("%s-%s", server.HostName(), name))
+			}
+		}
+	}
+	if len(truncated) != 0 {
+		log.WithField("files", truncated).Warn("Some application diagnostics have been truncated to fit the report size limit.")
+		err := ioutil.WriteFile(filepath.Join(dir, report.TruncatedFilesIndex),
+			[]byte(strings.Join(truncated, "\n")+"\n"), defaults.SharedReadWriteMask)
+		if err != nil {
+			errors = append(errors, trace.ConvertSystemError(err))
+		}
+	}
+	return trace.NewAggregate(errors...)
+}
+
+func (s *site) runApplicationCollector(reportWriter report.FileWriter, runner *serverRunner, collector schema.ReportCollector) error {
+	w, err := reportWriter.NewWriter(fmt.Sprintf("app-%v", filepath.Base(collector.Name)))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer w.Close()
+	return trace.Wrap(runner.RunStream(w, s.planetEnterCommand(collector.Command...)...))
+}
+
+// systemReportArgs returns the arguments of the node report command
+// for the specified collector.
+// maxSize limits the size of the node report if not 0
+func systemReportArgs(filter string, profile report.Profile, maxSize int64) []string {
+	args := []string{"system", "report", fmt.Sprintf("--filter=%v", filter), "--compressed"}
+	args = append(args, profile.TimeRange.Args()...)
+	if maxSize != 0 {
+		args = append(args, fmt.Sprintf("--max-size=%v", maxSize))
+	}
+	for _, namespace := range profile.Namespaces {
+		args = append(args, fmt.Sprintf("--namespace=%v", namespace))
+	}
+	return args
+}
+
+// nodeReportMaxSize returns the size limit of a single node report.
+// The report size budget of the profile is split evenly between the
+// system reports of the nodes, the Kubernetes and etcd reports and
+// the runs of the application collectors
+func nodeReportMaxSize(profile report.Profile, nodes int, collectors []schema.ReportCollector) int64 {
+	if profile.MaxSize == 0 {
+		return 0
+	}
+	var parts int64
+	if profile.HasFilter(report.FilterSystem) {
+		parts += int64(nodes)
+	}
+	if profile.HasFilter(report.FilterKubernetes) {
+		parts++
+	}
+	if profile.HasFilter(report.FilterEtcd) {
+		parts++
+	}
+	if profile.HasFilter(report.FilterEtcdBackup) {
+		parts++
+	}
+	if profile.HasFilter(report.FilterApplication) {
+		for _, collector := range collectors {
+			if collector.AllNodes {
+				parts += int64(nodes)
+			} else {
+				parts++
+			}
+		}
+	}
+	if parts == 0 {
+		return 0
+	}
+	maxSize := profile.MaxSize / parts
+	if maxSize == 0 {
+		// the budget is too small to be split, still pass a limit
+		// so the nodes do not fall back to unlimited reports
+		maxSize = 1
+	}
+	return maxSize
+}
+
+// filterReportServers returns the servers selected by the report profile
+func filterReportServers(servers []remoteServer, profile report.Profile) ([]remoteServer, error) {
+	if len(profile.Nodes) == 0 {
+		return servers, nil
+	}
+	var result []remoteServer
+	for _, server := range servers {
+		if profile.HasNode(server.HostName(), server.Address()) {
+			result = append(result, server)
+		}
+	}
+	if len(result) == 0 {
+		return nil, trace.NotFound("no cluster nodes match %v", profile.Nodes)
+	}
+	return result, nil
+}
+
 func runCollectors(site site, dir string) error {
 	storageSite, err := site.service.cfg.Backend.GetSite(site.domainName)
 	if err != nil {
@@ -246,6 +447,7 @@ func runCollectors(site site, dir string) error {
 	collectors := []collectorFn{
 		collectSiteInfo(*storageSite),
 		collectDumpHook,
+		collectHookRuns,
 	}
 	reportWriter := report.NewFileWriter(dir)
 
@@ -321,6 +523,52 @@ func collectDumpHook(reportWriter report.FileWriter, site site) error {
 	return trace.Wrap(err)
 }
 
+// collectHookRuns saves the application hook run history
+// along with the logs of each hook run
+func collectHookRuns(reportWriter report.FileWriter, site site) error {
+	runs, err := site.backend().GetHookRuns(storage.HookRunFilter{})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(runs) == 0 {
+		return nil
+	}
+	var errors []error
+	summary := make([]storage.HookRun, 0, len(runs))
+	for _, run := range runs {
+		if err := collectHookRunLogs(reportWriter, run); err != nil {
+			errors = append(errors, trace.Wrap(err))
+		}
+		// logs are saved in separate files
+		run.Logs = ""
+		summary = append(summary, run)
+	}
+	w, err := reportWriter.NewWriter(hookRunsFilename)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer w.Close()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(summary); err != nil {
+		errors = append(errors, trace.Wrap(err))
+	}
+	return trace.NewAggregate(errors...)
+}
+
+func collectHookRunLogs(reportWriter report.FileWriter, run storage.HookRun) error {
+	if run.Logs == "" {
+		return nil
+	}
+	w, err := reportWriter.NewWriter(fmt.Sprintf(hookRunLogsFilename, run.Hook, run.ID))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer w.Close()
+	_, err = io.WriteString(w, run.Logs)
+	return trace.Wrap(err)
+}
+
 // collectOperationLogs streams logs of the specified operation using the specified writer
 func collectOperationLogs(site site, operation ops.SiteOperation, reportWriter report.FileWriter) error {
 	w, err := reportWriter.NewWriter(fmt.Sprintf(opLogsFilename, operation.Type, operation.ID))
@@ -357,6 +605,12 @@ const (
 	siteInfoFilename = "site.json"
 	// dumpHookFilename is the name of the file with dump hook output
 	dumpHookFilename = "dump-hook"
+	// hookRunsFilename is the name of the file with the application
+	// hook run history
+	hookRunsFilename = "hook-runs.json"
+	// hookRunLogsFilename defines the file pattern that stores the logs
+	// of a particular application hook run
+	hookRunLogsFilename = "hook-%v.%v.log"
 	// opLogsFilename defines the file pattern that stores operation log for a particular
 	// cluster operation
 	opLogsFilename = "%v.%v"
@@ -20,8 +20,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"time"
 
 	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/report"
+	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
 
@@ -55,3 +59,36 @@ type bufferWriter struct {
 func (b *bufferWriter) NewWriter(name string) (io.WriteCloser, error) {
 	return utils.NopWriteCloser(&b.Buffer), nil
 }
+
+func (s *ReportSuite) TestSystemReportArgs(c *check.C) {
+	now := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	profile, err := newReportProfile(ops.GetClusterReportRequest{
+		Profile: report.ProfileMinimal,
+		Until:   now.Add(-time.Hour),
+		MaxSize: 1000,
+	}, now)
+	c.Assert(err, check.IsNil)
+	c.Assert(systemReportArgs(report.FilterSystem, *profile, nodeReportMaxSize(*profile, 3, nil)), check.DeepEquals, []string{
+		"system", "report", "--filter=system", "--compressed",
+		"--since=2018-09-30T12:00:00Z", "--until=2018-10-01T11:00:00Z", "--max-size=250",
+	})
+
+	// application collectors share the budget: one run on a master
+	// and one run on each of the 3 nodes
+	profile, err = newReportProfile(ops.GetClusterReportRequest{
+		Profile: report.ProfileKubernetes,
+		MaxSize: 1000,
+	}, now)
+	c.Assert(err, check.IsNil)
+	collectors := []schema.ReportCollector{
+		{Name: "master", Command: []string{"true"}},
+		{Name: "nodes", Command: []string{"true"}, AllNodes: true},
+	}
+	c.Assert(nodeReportMaxSize(*profile, 3, collectors), check.Equals, int64(200))
+
+	_, err = newReportProfile(ops.GetClusterReportRequest{
+		Since: now,
+		Until: now.Add(-time.Hour),
+	}, now)
+	c.Assert(err, check.NotNil)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// recordResourceRevision records a change to a cluster resource in the
+// cluster resource history. The change is recorded as a creation unless the
+// previous revision of the resource exists and is not a deletion.
+// The user making the change is taken from the context.
+//
+// Resources with secrets (users, tokens, TLS key pairs, auth connectors,
+// SMTP configuration and backup policies) are not recorded since the history
+// would expose the secrets.
+//
+// Failure to record the change is not fatal since the change has already been made
+func (o *Operator) recordResourceRevision(ctx context.Context, kind, name string, resource interface{}, deleted bool) {
+	var data []byte
+	if resource != nil {
+		var err error
+		data, err = json.Marshal(resource)
+		if err != nil {
+			o.WithError(err).Warnf("Failed to marshal %v %q.", kind, name)
+			return
+		}
+	}
+	revision, err := o.createResourceRevision(storage.ResourceRevision{
+		Kind:     kind,
+		Name:     name,
+		Action:   storage.ResourceDeleted,
+		Author:   storage.UserFromContext(ctx),
+		Created:  o.clock().UtcNow(),
+		Resource: data,
+	}, deleted)
+	if err != nil {
+		o.WithError(err).Warnf("Failed to record revision of %v %q.", kind, name)
+		return
+	}
+	o.Infof("Recorded %v.", revision)
+	if err := o.pruneResourceRevisions(kind, name); err != nil {
+		o.WithError(err).Warnf("Failed to prune revisions of %v %q.", kind, name)
+	}
+}
+
+// recordOperationResourceRevision records the revision of the resource
+// applied by the specified completed operation.
+// Cluster configuration and runtime environment are applied by operations
+// which can fail or be rolled back, so their revisions are only recorded once
+// the operation has completed successfully.
+// The change is attributed to the user who created the operation
+func (o *Operator) recordOperationResourceRevision(operation ops.SiteOperation) {
+	ctx := context.WithValue(context.TODO(), constants.UserContext, operation.CreatedBy)
+	switch {
+	case operation.Type == ops.OperationUpdateConfig && operation.UpdateConfig != nil:
+		o.recordResourceRevision(ctx, storage.KindClusterConfiguration, "",
+			rawResource(string(operation.UpdateConfig.Config)), false)
+	case operation.Type == ops.OperationUpdateRuntimeEnviron && operation.UpdateEnviron != nil:
+		o.recordResourceRevision(ctx, storage.KindRuntimeEnvironment, "",
+			storage.NewEnvironment(operation.UpdateEnviron.Env), false)
+	}
+}
+
+func (o *Operator) createResourceRevision(revision storage.ResourceRevision, deleted bool) (*storage.ResourceRevision, error) {
+	if !deleted {
+		previous, err := o.backend().GetResourceRevisions(storage.ResourceRevisionFilter{
+			Kind:  revision.Kind,
+			Name:  &revision.Name,
+			Limit: 1,
+		})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		revision.Action = storage.ResourceCreated
+		if len(previous) != 0 && previous[0].Action != storage.ResourceDeleted {
+			revision.Action = storage.ResourceUpdated
+		}
+	}
+	return o.backend().CreateResourceRevision(revision)
+}
+
+// pruneResourceRevisions removes all but the most recent revisions
+// of the specified resource from the resource history
+func (o *Operator) pruneResourceRevisions(kind, name string) error {
+	revisions, err := o.backend().GetResourceRevisions(storage.ResourceRevisionFilter{
+		Kind: kind,
+		Name: &name,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(revisions) <= defaults.ResourceRevisionsRetain {
+		return nil
+	}
+	for _, revision := range revisions[defaults.ResourceRevisionsRetain:] {
+		err := o.backend().DeleteResourceRevision(kind, name, revision.Revision)
+		if err != nil && !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// GetResourceRevisions returns the recorded revisions of cluster resources,
+// most recent first
+func (o *Operator) GetResourceRevisions(ctx context.Context, req ops.GetResourceRevisionsRequest) ([]storage.ResourceRevision, error) {
+	revisions, err := o.backend().GetResourceRevisions(req.ResourceRevisionFilter)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return revisions, nil
+}
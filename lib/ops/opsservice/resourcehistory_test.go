@@ -0,0 +1,128 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/suite"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"gopkg.in/check.v1"
+)
+
+type ResourceHistorySuite struct {
+	operator *Operator
+	cluster  *ops.Site
+}
+
+var _ = check.Suite(&ResourceHistorySuite{})
+
+func (s *ResourceHistorySuite) SetUpTest(c *check.C) {
+	services := SetupTestServices(c)
+	s.operator = services.Operator
+
+	suite := &suite.OpsSuite{}
+	app, err := suite.SetUpTestPackage(services.Apps, services.Packages, c)
+	c.Assert(err, check.IsNil)
+
+	account, err := s.operator.CreateAccount(ops.NewAccountRequest{
+		Org: "history.test",
+	})
+	c.Assert(err, check.IsNil)
+
+	s.cluster, err = s.operator.CreateSite(ops.NewSiteRequest{
+		AccountID:  account.ID,
+		AppPackage: app.String(),
+		Provider:   schema.ProvisionerOnPrem,
+		DomainName: "history.test",
+	})
+	c.Assert(err, check.IsNil)
+
+	// update operations require an installed cluster
+	group := s.operator.getOperationGroup(s.cluster.Key())
+	key, err := group.createSiteOperation(ops.SiteOperation{
+		AccountID:  s.cluster.AccountID,
+		SiteDomain: s.cluster.Domain,
+		Type:       ops.OperationInstall,
+		State:      ops.OperationStateInstallInitiated,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(ops.CompleteOperation(*key, s.operator), check.IsNil)
+}
+
+// Makes sure the cluster configuration revision is only recorded once the update operation completes
+func (s *ResourceHistorySuite) TestRecordsConfigRevisionOnCompletion(c *check.C) {
+	cluster, err := s.operator.openSite(s.cluster.Key())
+	c.Assert(err, check.IsNil)
+	ctx := context.WithValue(context.TODO(), constants.UserContext, "alice@example.com")
+
+	key, err := cluster.createUpdateConfigOperation(ctx, ops.CreateUpdateConfigOperationRequest{
+		ClusterKey: s.cluster.Key(),
+		Config:     []byte(`{"kind":"ClusterConfiguration"}`),
+	}, nil)
+	c.Assert(err, check.IsNil)
+	s.assertRevisions(c, storage.KindClusterConfiguration, 0)
+
+	c.Assert(ops.FailOperation(*key, s.operator, ""), check.IsNil)
+	s.assertRevisions(c, storage.KindClusterConfiguration, 0)
+	// a failed operation leaves the cluster in the operation state until it is rolled back
+	c.Assert(cluster.setSiteState(ops.SiteStateActive), check.IsNil)
+
+	key, err = cluster.createUpdateConfigOperation(ctx, ops.CreateUpdateConfigOperationRequest{
+		ClusterKey: s.cluster.Key(),
+		Config:     []byte(`{"kind":"ClusterConfiguration"}`),
+	}, nil)
+	c.Assert(err, check.IsNil)
+	s.assertRevisions(c, storage.KindClusterConfiguration, 0)
+
+	c.Assert(ops.CompleteOperation(*key, s.operator), check.IsNil)
+	revisions := s.assertRevisions(c, storage.KindClusterConfiguration, 1)
+	c.Assert(revisions[0].Author, check.Equals, "alice@example.com")
+	c.Assert(string(revisions[0].Resource), check.Equals, `{"kind":"ClusterConfiguration"}`)
+}
+
+// Makes sure the runtime environment revision is only recorded once the update operation completes
+func (s *ResourceHistorySuite) TestRecordsEnvironmentRevisionOnCompletion(c *check.C) {
+	cluster, err := s.operator.openSite(s.cluster.Key())
+	c.Assert(err, check.IsNil)
+	ctx := context.WithValue(context.TODO(), constants.UserContext, "alice@example.com")
+
+	key, err := cluster.createUpdateEnvarsOperation(ctx, ops.CreateUpdateEnvarsOperationRequest{
+		ClusterKey: s.cluster.Key(),
+		Env:        map[string]string{"HTTP_PROXY": "example.com:8080"},
+	}, nil)
+	c.Assert(err, check.IsNil)
+	s.assertRevisions(c, storage.KindRuntimeEnvironment, 0)
+
+	c.Assert(ops.CompleteOperation(*key, s.operator), check.IsNil)
+	revisions := s.assertRevisions(c, storage.KindRuntimeEnvironment, 1)
+	c.Assert(revisions[0].Author, check.Equals, "alice@example.com")
+}
+
+func (s *ResourceHistorySuite) assertRevisions(c *check.C, kind string, count int) []storage.ResourceRevision {
+	revisions, err := s.operator.GetResourceRevisions(context.TODO(), ops.GetResourceRevisionsRequest{
+		SiteKey:                s.cluster.Key(),
+		ResourceRevisionFilter: storage.ResourceRevisionFilter{Kind: kind},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(revisions, check.HasLen, count)
+	return revisions
+}
@@ -1034,13 +1034,16 @@ func (o *Operator) SetOperationState(key ops.SiteOperationKey, req ops.SetOperat
 	}
 	// change the state without "compare" part just to take leverage of
 	// the operation group locking to ensure atomicity
-	_, err = site.compareAndSwapOperationState(swap{
+	operation, err := site.compareAndSwapOperationState(swap{
 		key:        key,
 		newOpState: req.State,
 	})
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	if operation.IsCompleted() {
+		o.recordOperationResourceRevision(*operation)
+	}
 	if req.Progress != nil {
 		err := o.CreateProgressEntry(key, *req.Progress)
 		if err != nil {
@@ -1116,13 +1119,18 @@ func (o *Operator) CreateLogEntry(key ops.SiteOperationKey, entry ops.LogEntry)
 	return site.createLogEntry(key, entry)
 }
 
-func (o *Operator) GetSiteReport(key ops.SiteKey) (io.ReadCloser, error) {
-	cluster, err := o.openSite(key)
+func (o *Operator) GetSiteReport(req ops.GetClusterReportRequest) (io.ReadCloser, error) {
+	cluster, err := o.openSite(req.SiteKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	profile, err := newReportProfile(req, o.clock().UtcNow())
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
-	return cluster.getClusterReport()
+	return cluster.getClusterReport(*profile)
 }
 
 func (o *Operator) GetSiteOperationProgress(key ops.SiteOperationKey) (*ops.ProgressEntry, error) {
@@ -1137,6 +1145,19 @@ func (o *Operator) GetSiteOperationProgress(key ops.SiteOperationKey) (*ops.Prog
 	return &progressEntry, nil
 }
 
+// WatchEvents returns a watcher that streams the changes of the cluster state
+func (o *Operator) WatchEvents(ctx context.Context, key ops.SiteKey, filter storage.WatchFilter) (storage.Watcher, error) {
+	if _, err := o.backend().GetSite(key.SiteDomain); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	filter.SiteDomain = key.SiteDomain
+	watcher, err := o.backend().NewWatcher(ctx, filter)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return watcher, nil
+}
+
 func (o *Operator) CreateProgressEntry(key ops.SiteOperationKey, entry ops.ProgressEntry) error {
 	_, err := o.backend().CreateProgressEntry(storage.ProgressEntry(entry))
 	if err != nil {
@@ -278,7 +278,7 @@ func (s *site) shrinkOperationStart(ctx *operationContext) (err error) {
 		})
 
 		if err = s.runHook(ctx, schema.HookNodeRemoving); err != nil {
-			if !force {
+			if !force && s.hookFailurePolicy(schema.HookNodeRemoving) != schema.HookFailureContinue {
 				return trace.Wrap(err, "failed to run %v hook", schema.HookNodeRemoving)
 			}
 			ctx.Warningf("failed to run %v hook, force continue: %v", schema.HookNodeRemoving, trace.DebugReport(err))
@@ -361,7 +361,7 @@ func (s *site) shrinkOperationStart(ctx *operationContext) (err error) {
 		})
 
 		if err = s.runHook(ctx, schema.HookNodeRemoved); err != nil {
-			if !force {
+			if !force && s.hookFailurePolicy(schema.HookNodeRemoved) != schema.HookFailureContinue {
 				return trace.Wrap(err, "failed to run %v hook", schema.HookNodeRemoved)
 			}
 			ctx.Warningf("failed to run %v hook, force continue: %v", schema.HookNodeRemoved, trace.DebugReport(err))
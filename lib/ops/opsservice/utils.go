@@ -83,6 +83,16 @@ func (s *site) runHook(ctx *operationContext, hook schema.HookType) error {
 	return s.runPackageHook(ctx, s.app.Package, hook)
 }
 
+// hookFailurePolicy returns the failure policy of the specified hook
+// of the application currently installed on the site
+func (s *site) hookFailurePolicy(hookType schema.HookType) schema.HookFailurePolicy {
+	hook, err := schema.HookFromString(hookType, s.app.Manifest)
+	if err != nil || hook == nil {
+		return schema.HookFailureAbort
+	}
+	return hook.GetFailurePolicy()
+}
+
 // runOnMaster executes the provided command on the master server.
 func (s *site) runOnMaster(ctx *operationContext, command []string) ([]byte, error) {
 	runner, err := s.getMasterRunner(ctx)
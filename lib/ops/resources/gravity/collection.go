@@ -329,6 +329,71 @@ func (c *logForwardersCollection) WriteYAML(w io.Writer) error {
 	return utils.WriteYAML(c, w)
 }
 
+type backupPolicyCollection struct {
+	policies []storage.BackupPolicy
+}
+
+// Resources returns the resources collection in the generic format
+func (c *backupPolicyCollection) Resources() (resources []teleservices.UnknownResource, err error) {
+	for _, item := range c.policies {
+		resource, err := utils.ToUnknownResource(item)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		resources = append(resources, *resource)
+	}
+	return resources, nil
+}
+
+// WriteText serializes collection in human-friendly text format
+func (c *backupPolicyCollection) WriteText(w io.Writer) error {
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Name", "Interval", "Retention", "Destination", "Encrypted"})
+	for _, policy := range c.policies {
+		retention := policy.GetRetention()
+		fmt.Fprintf(t, "%v\t%v\t%v\t%v\t%v\n",
+			policy.GetName(),
+			policy.GetInterval(),
+			formatRetention(retention),
+			policy.GetDestination(),
+			policy.GetEncryptionKey() != "")
+	}
+	_, err := io.WriteString(w, t.String())
+	return trace.Wrap(err)
+}
+
+// WriteJSON serializes collection into JSON format
+func (c *backupPolicyCollection) WriteJSON(w io.Writer) error {
+	return utils.WriteJSON(c, w)
+}
+
+func (c *backupPolicyCollection) ToMarshal() interface{} {
+	if len(c.policies) == 1 {
+		return c.policies[0]
+	}
+	return c.policies
+}
+
+// WriteYAML serializes collection into YAML format
+func (c *backupPolicyCollection) WriteYAML(w io.Writer) error {
+	return utils.WriteYAML(c, w)
+}
+
+// formatRetention returns the human-friendly description of the backup retention
+func formatRetention(retention storage.BackupRetention) string {
+	var parts []string
+	if retention.Count > 0 {
+		parts = append(parts, fmt.Sprintf("%v backups", retention.Count))
+	}
+	if retention.MaxAge.Duration > 0 {
+		parts = append(parts, fmt.Sprintf("%v", retention.MaxAge.Duration))
+	}
+	if len(parts) == 0 {
+		return "unlimited"
+	}
+	return strings.Join(parts, ", ")
+}
+
 type tlsKeyPairCollection struct {
 	keyPairs []storage.TLSKeyPair
 }
@@ -662,6 +727,10 @@ func (r configCollection) WriteText(w io.Writer) error {
 		common.PrintCustomTableHeader(t, []string{"Kubelet"}, "-")
 		fmt.Fprintf(t, "%v\n", string(config.Config))
 	}
+	if config := r.GetComponentConfigs().Etcd; config != nil {
+		common.PrintCustomTableHeader(t, []string{"Etcd"}, "-")
+		fmt.Fprintf(t, "Extra Args:\t%v\n", strings.Join(config.ExtraArgs, " "))
+	}
 	if config := r.GetGlobalConfig(); config != nil {
 		displayCloudConfig := config.CloudProvider != "" || config.CloudConfig != ""
 		if displayCloudConfig {
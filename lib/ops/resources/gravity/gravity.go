@@ -18,6 +18,7 @@ package gravity
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/gravitational/gravity/lib/localenv"
 	"github.com/gravitational/gravity/lib/modules"
@@ -191,6 +192,26 @@ func (r *Resources) Create(ctx context.Context, req resources.CreateRequest) err
 			return trace.Wrap(err)
 		}
 		r.Println("Updated cluster SMTP configuration")
+	case storage.KindBackupPolicy:
+		policy, err := storage.UnmarshalBackupPolicy(req.Resource.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !req.Upsert {
+			policies, err := r.Operator.GetBackupPolicies(req.SiteKey, false)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			for _, existing := range policies {
+				if existing.GetName() == policy.GetName() {
+					return trace.AlreadyExists("backup policy %q already exists", policy.GetName())
+				}
+			}
+		}
+		if err := r.Operator.UpsertBackupPolicy(ctx, req.SiteKey, policy); err != nil {
+			return trace.Wrap(err)
+		}
+		r.Printf("Created backup policy %q\n", policy.GetName())
 	case storage.KindAlert:
 		alert, err := storage.UnmarshalAlert(req.Resource.Raw)
 		if err != nil {
@@ -370,6 +391,21 @@ func (r *Resources) GetCollection(req resources.ListRequest) (resources.Collecti
 			return nil, trace.Wrap(err)
 		}
 		return smtpConfigCollection{config}, nil
+	case storage.KindBackupPolicy:
+		policies, err := r.Operator.GetBackupPolicies(req.SiteKey, req.WithSecrets)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		var filtered []storage.BackupPolicy
+		for _, policy := range policies {
+			if req.Name == "" || policy.GetName() == req.Name {
+				filtered = append(filtered, policy)
+			}
+		}
+		if req.Name != "" && len(filtered) == 0 {
+			return nil, trace.NotFound("backup policy %q not found", req.Name)
+		}
+		return &backupPolicyCollection{policies: filtered}, nil
 	case storage.KindAlert:
 		alerts, err := r.Operator.GetAlerts(req.SiteKey)
 		if err != nil {
@@ -466,6 +502,14 @@ func (r *Resources) Remove(ctx context.Context, req resources.RemoveRequest) err
 			return trace.Wrap(err)
 		}
 		r.Printf("Log forwarder %q has been deleted\n", req.Name)
+	case storage.KindBackupPolicy:
+		if err := r.Operator.DeleteBackupPolicy(ctx, req.SiteKey, req.Name); err != nil {
+			if trace.IsNotFound(err) && req.Force {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		r.Printf("Backup policy %q has been deleted\n", req.Name)
 	case storage.KindTLSKeyPair:
 		if err := r.Operator.DeleteClusterCertificate(ctx, req.SiteKey); err != nil {
 			if trace.IsNotFound(err) && req.Force {
@@ -534,39 +578,75 @@ type ClusterOperationHandler interface {
 // Validate checks whether the specified resource
 // represents a valid resource.
 func Validate(resource storage.UnknownResource) (err error) {
+	_, err = unmarshal(resource)
+	return trace.Wrap(err)
+}
+
+// Normalize returns the specified resource with all defaults set
+// encoded as JSON.
+// Implements resources.Normalizer
+func (r *Resources) Normalize(resource storage.UnknownResource) ([]byte, error) {
+	return Normalize(resource)
+}
+
+// Normalize returns the specified resource with all defaults set
+// encoded as JSON
+func Normalize(resource storage.UnknownResource) ([]byte, error) {
+	value, err := unmarshal(resource)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if defaulter, ok := value.(interface {
+		CheckAndSetDefaults() error
+	}); ok {
+		if err := defaulter.CheckAndSetDefaults(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return data, nil
+}
+
+// unmarshal unmarshals the specified resource with the marshaler of its kind
+func unmarshal(resource storage.UnknownResource) (value interface{}, err error) {
 	switch resource.Kind {
 	case teleservices.KindGithubConnector:
-		_, err = teleservices.GetGithubConnectorMarshaler().Unmarshal(resource.Raw)
+		value, err = teleservices.GetGithubConnectorMarshaler().Unmarshal(resource.Raw)
 	case teleservices.KindUser:
-		_, err = teleservices.GetUserMarshaler().UnmarshalUser(resource.Raw)
+		value, err = teleservices.GetUserMarshaler().UnmarshalUser(resource.Raw)
 	case storage.KindToken:
-		_, err = storage.GetTokenMarshaler().UnmarshalToken(resource.Raw)
+		value, err = storage.GetTokenMarshaler().UnmarshalToken(resource.Raw)
 	case storage.KindLogForwarder:
-		_, err = storage.GetLogForwarderMarshaler().Unmarshal(resource.Raw)
+		value, err = storage.GetLogForwarderMarshaler().Unmarshal(resource.Raw)
 	case storage.KindTLSKeyPair:
-		_, err = storage.UnmarshalTLSKeyPair(resource.Raw)
+		value, err = storage.UnmarshalTLSKeyPair(resource.Raw)
 	case teleservices.KindClusterAuthPreference:
-		_, err = teleservices.GetAuthPreferenceMarshaler().Unmarshal(resource.Raw)
+		value, err = teleservices.GetAuthPreferenceMarshaler().Unmarshal(resource.Raw)
 	case storage.KindSMTPConfig:
-		_, err = storage.UnmarshalSMTPConfig(resource.Raw)
+		value, err = storage.UnmarshalSMTPConfig(resource.Raw)
+	case storage.KindBackupPolicy:
+		value, err = storage.UnmarshalBackupPolicy(resource.Raw)
 	case storage.KindAlert:
-		_, err = storage.UnmarshalAlert(resource.Raw)
+		value, err = storage.UnmarshalAlert(resource.Raw)
 	case storage.KindAlertTarget:
-		_, err = storage.UnmarshalAlertTarget(resource.Raw)
+		value, err = storage.UnmarshalAlertTarget(resource.Raw)
 	case storage.KindAuthGateway:
-		_, err = storage.UnmarshalAuthGateway(resource.Raw)
+		value, err = storage.UnmarshalAuthGateway(resource.Raw)
 	case storage.KindRuntimeEnvironment:
-		_, err = storage.UnmarshalEnvironmentVariables(resource.Raw)
+		value, err = storage.UnmarshalEnvironmentVariables(resource.Raw)
 	case storage.KindClusterConfiguration:
-		_, err = clusterconfig.Unmarshal(resource.Raw)
+		value, err = clusterconfig.Unmarshal(resource.Raw)
 	case storage.KindPersistentStorage:
-		_, err = storage.UnmarshalPersistentStorage(resource.Raw)
+		value, err = storage.UnmarshalPersistentStorage(resource.Raw)
 	default:
-		return trace.NotImplemented("unsupported resource %q, supported are: %v",
+		return nil, trace.NotImplemented("unsupported resource %q, supported are: %v",
 			resource.Kind, modules.GetResources().SupportedResources())
 	}
 	if err != nil {
-		return trace.Wrap(err)
+		return nil, trace.Wrap(err)
 	}
-	return nil
+	return value, nil
 }
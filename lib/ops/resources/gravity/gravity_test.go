@@ -18,6 +18,7 @@ package gravity
 
 import (
 	"context"
+	"fmt"
 	"net/http/httptest"
 	"testing"
 
@@ -126,12 +127,77 @@ func (s *GravityResourcesSuite) TestToken(c *check.C) {
 	c.Assert(err, check.FitsTypeOf, trace.NotFound(""))
 }
 
+func (s *GravityResourcesSuite) TestRecordsRevisions(c *check.C) {
+	authPreference, err := teleservices.NewAuthPreference(teleservices.AuthPreferenceSpecV2{
+		Type:         "local",
+		SecondFactor: "off",
+	})
+	c.Assert(err, check.IsNil)
+	err = s.r.Create(context.TODO(), resources.CreateRequest{SiteKey: s.cluster.Key(), Resource: authPreferenceToUnknown(c, authPreference), Upsert: true})
+	c.Assert(err, check.IsNil)
+
+	authPreference.SetSecondFactor("otp")
+	err = s.r.Create(context.TODO(), resources.CreateRequest{SiteKey: s.cluster.Key(), Resource: authPreferenceToUnknown(c, authPreference), Upsert: true})
+	c.Assert(err, check.IsNil)
+
+	name := ""
+	revisions, err := s.r.Operator.GetResourceRevisions(context.TODO(), ops.GetResourceRevisionsRequest{
+		SiteKey: s.cluster.Key(),
+		ResourceRevisionFilter: storage.ResourceRevisionFilter{
+			Kind: teleservices.KindClusterAuthPreference,
+			Name: &name,
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(revisions, check.HasLen, 2)
+	var actions []string
+	for _, revision := range revisions {
+		actions = append(actions, fmt.Sprintf("%v:%v", revision.Revision, revision.Action))
+		c.Assert(revision.Author, check.Equals, s.s.Creds.Email)
+	}
+	c.Assert(actions, check.DeepEquals, []string{"2:updated", "1:created"})
+	recorded, err := teleservices.GetAuthPreferenceMarshaler().Unmarshal(revisions[0].Resource)
+	c.Assert(err, check.IsNil)
+	c.Assert(recorded.GetSecondFactor(), check.Equals, "otp")
+}
+
+func (s *GravityResourcesSuite) TestDoesNotRecordSecrets(c *check.C) {
+	connector := teleservices.NewGithubConnector("github-history", teleservices.GithubConnectorSpecV3{
+		RedirectURL:   githubConnector.GetRedirectURL(),
+		ClientID:      githubConnector.GetClientID(),
+		ClientSecret:  githubConnector.GetClientSecret(),
+		TeamsToLogins: githubConnector.GetTeamsToLogins(),
+	})
+	err := s.r.Create(context.TODO(), resources.CreateRequest{SiteKey: s.cluster.Key(), Resource: toUnknown(c, connector)})
+	c.Assert(err, check.IsNil)
+
+	err = s.r.Remove(context.TODO(), resources.RemoveRequest{SiteKey: s.cluster.Key(), Kind: teleservices.KindGithubConnector, Name: "github-history"})
+	c.Assert(err, check.IsNil)
+
+	revisions, err := s.r.Operator.GetResourceRevisions(context.TODO(), ops.GetResourceRevisionsRequest{
+		SiteKey: s.cluster.Key(),
+		ResourceRevisionFilter: storage.ResourceRevisionFilter{
+			Kind: teleservices.KindGithubConnector,
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(revisions, check.HasLen, 0)
+}
+
 func toUnknown(c *check.C, resource teleservices.Resource) teleservices.UnknownResource {
 	unknown, err := utils.ToUnknownResource(resource)
 	c.Assert(err, check.IsNil)
 	return *unknown
 }
 
+func authPreferenceToUnknown(c *check.C, authPreference teleservices.AuthPreference) teleservices.UnknownResource {
+	data, err := teleservices.GetAuthPreferenceMarshaler().Marshal(authPreference)
+	c.Assert(err, check.IsNil)
+	var unknown teleservices.UnknownResource
+	c.Assert(unknown.UnmarshalJSON(data), check.IsNil)
+	return unknown
+}
+
 var (
 	githubConnector = teleservices.NewGithubConnector("github", teleservices.GithubConnectorSpecV3{
 		RedirectURL:  "https://ops.example.com/portalapi/v1/github/callback",
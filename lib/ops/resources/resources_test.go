@@ -74,12 +74,27 @@ type testResources struct {
 }
 
 func (r *testResources) Create(ctx context.Context, req CreateRequest) error {
+	for i, resource := range r.resources {
+		if resource.Kind == req.Resource.Kind && resource.Metadata.Name == req.Resource.Metadata.Name {
+			r.resources[i] = req.Resource
+			return nil
+		}
+	}
 	r.resources = append(r.resources, req.Resource)
 	return nil
 }
 
 func (r *testResources) GetCollection(req ListRequest) (Collection, error) {
-	return testCollection(r.resources), nil
+	if req.Kind == "" {
+		return testCollection(r.resources), nil
+	}
+	var filtered testCollection
+	for _, resource := range r.resources {
+		if resource.Kind == req.Kind {
+			filtered = append(filtered, resource)
+		}
+	}
+	return filtered, nil
 }
 
 func (r *testResources) Remove(ctx context.Context, req RemoveRequest) error {
@@ -0,0 +1,601 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/modules"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/ghodss/yaml"
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+	"github.com/pmezard/go-difflib/difflib"
+	log "github.com/sirupsen/logrus"
+)
+
+// KindOrder defines the order in which resources of different kinds
+// are created when several resources are applied at once.
+//
+// Resources other resources depend on go first (e.g. auth connectors
+// before users, users before their tokens), while the resources that
+// trigger cluster operations go last.
+// Resources are removed in the reverse order
+var KindOrder = []string{
+	teleservices.KindClusterAuthPreference,
+	teleservices.KindGithubConnector,
+	teleservices.KindAuthConnector,
+	teleservices.KindUser,
+	storage.KindToken,
+	storage.KindTLSKeyPair,
+	storage.KindAuthGateway,
+	storage.KindSMTPConfig,
+	storage.KindAlertTarget,
+	storage.KindAlert,
+	storage.KindLogForwarder,
+	storage.KindBackupPolicy,
+	storage.KindPersistentStorage,
+	storage.KindRuntimeEnvironment,
+	storage.KindClusterConfiguration,
+}
+
+const (
+	// ManagedByLabel is the label set on resources created or updated
+	// with Apply. Only resources with this label are pruned
+	ManagedByLabel = "gravitational.io/managed-by"
+	// ManagedByApply is the value of ManagedByLabel set by Apply
+	ManagedByApply = "gravity-resource-apply"
+)
+
+// Normalizer brings resources to their canonical form
+type Normalizer interface {
+	// Normalize returns the specified resource with all defaults set
+	// encoded as JSON
+	Normalize(storage.UnknownResource) ([]byte, error)
+}
+
+// ReadPath reads resources from the specified path.
+//
+// If path is a directory, resources are read from all YAML and JSON
+// files found in it and its subdirectories, in lexical order
+func ReadPath(path string) (resources []storage.UnknownResource, err error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	var paths []string
+	if !fi.IsDir() {
+		paths = append(paths, path)
+	} else {
+		err = filepath.Walk(path, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return trace.ConvertSystemError(err)
+			}
+			if fi.IsDir() {
+				return nil
+			}
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".yaml", ".yml", ".json":
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+		err = ForEach(f, func(resource storage.UnknownResource) error {
+			if isKubernetesResource(resource) {
+				return trace.BadParameter("%v: resource %v/%v is missing version",
+					path, resource.Kind, resource.Metadata.Name)
+			}
+			resources = append(resources, resource)
+			return nil
+		})
+		f.Close()
+		if err != nil {
+			return nil, trace.Wrap(err, "failed to read resources from %v", path)
+		}
+	}
+	return resources, nil
+}
+
+// SyncRequest describes a request to synchronize cluster resources
+// with a set of declared resources
+type SyncRequest struct {
+	// SiteKey is the key of the cluster to route request to.
+	ops.SiteKey
+	// Resources is the list of declared resources
+	Resources []storage.UnknownResource
+	// Prune is whether to remove resources that are not declared.
+	// Only resources previously created or updated with Apply are removed
+	Prune bool
+	// PruneKinds optionally limits the resource kinds to prune.
+	// If unspecified, only kinds of declared resources are pruned
+	PruneKinds []string
+	// Owner is the user to manage resources for
+	Owner string
+	// Manual defines whether the operation should operate
+	// in manual mode.
+	// This attribute is operation-specific
+	Manual bool
+	// Confirmed defines whether the operation has been explicitly approved.
+	// This attribute is operation-specific
+	Confirmed bool
+}
+
+// Check validates the request and converts all kinds to canonical form
+func (r *SyncRequest) Check() error {
+	supported := modules.GetResources().SupportedResources()
+	seen := make(map[string]struct{})
+	for i, resource := range r.Resources {
+		kind := modules.GetResources().CanonicalKind(resource.Kind)
+		if !utils.StringInSlice(supported, kind) {
+			return trace.BadParameter("unsupported resource %q, supported are: %v",
+				resource.Kind, supported)
+		}
+		r.Resources[i].Kind = kind
+		key := resourceKey(kind, resource.Metadata.Name)
+		if _, ok := seen[key]; ok {
+			return trace.BadParameter("resource %v is declared more than once", key)
+		}
+		seen[key] = struct{}{}
+	}
+	removable := modules.GetResources().SupportedResourcesToRemove()
+	for i, kind := range r.PruneKinds {
+		canonical := modules.GetResources().CanonicalKind(kind)
+		if !utils.StringInSlice(removable, canonical) {
+			return trace.BadParameter("resource %q cannot be pruned, supported are: %v",
+				kind, removable)
+		}
+		r.PruneKinds[i] = canonical
+	}
+	return nil
+}
+
+// ChangeAction defines the action performed on a resource
+type ChangeAction string
+
+const (
+	// ChangeCreate means the resource will be created
+	ChangeCreate ChangeAction = "create"
+	// ChangeUpdate means the resource will be updated
+	ChangeUpdate ChangeAction = "update"
+	// ChangeDelete means the resource will be removed
+	ChangeDelete ChangeAction = "delete"
+)
+
+// Change describes a single difference between declared
+// and cluster resources
+type Change struct {
+	// Action is the action to perform
+	Action ChangeAction
+	// Kind is the resource kind
+	Kind string
+	// Name is the resource name
+	Name string
+	// Resource is the declared resource, empty for removals
+	Resource storage.UnknownResource
+	// Diff is the unified diff between the cluster and declared resource
+	Diff string
+}
+
+// String returns the change string representation
+func (c Change) String() string {
+	if c.Name == "" {
+		return fmt.Sprintf("%v %v", c.Action, c.Kind)
+	}
+	return fmt.Sprintf("%v %v/%v", c.Action, c.Kind, c.Name)
+}
+
+// Changes is a list of resource changes in the order they are applied
+type Changes []Change
+
+// IsEmpty returns true if there are no changes
+func (r Changes) IsEmpty() bool {
+	return len(r) == 0
+}
+
+// WriteText outputs the changes in human-friendly text format
+func (r Changes) WriteText(w io.Writer) error {
+	var b bytes.Buffer
+	if r.IsEmpty() {
+		b.WriteString("No changes.\n")
+	}
+	for _, change := range r {
+		switch change.Action {
+		case ChangeCreate:
+			b.WriteString("+ ")
+		case ChangeUpdate:
+			b.WriteString("~ ")
+		case ChangeDelete:
+			b.WriteString("- ")
+		}
+		fmt.Fprintf(&b, "%v\n", change)
+		if change.Diff != "" {
+			for _, line := range strings.SplitAfter(change.Diff, "\n") {
+				if line != "" {
+					fmt.Fprintf(&b, "    %v", line)
+				}
+			}
+		}
+	}
+	_, err := w.Write(b.Bytes())
+	return trace.Wrap(err)
+}
+
+// Diff computes the changes required to bring cluster resources
+// in sync with the resources declared in req
+func (r *ResourceControl) Diff(req SyncRequest) (Changes, error) {
+	if err := req.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	declared := make(map[string][]storage.UnknownResource)
+	for _, resource := range req.Resources {
+		declared[resource.Kind] = append(declared[resource.Kind], resource)
+	}
+	pruneKinds := req.PruneKinds
+	if len(pruneKinds) == 0 {
+		for kind := range declared {
+			pruneKinds = append(pruneKinds, kind)
+		}
+	}
+	kinds := make(map[string]struct{})
+	for kind := range declared {
+		kinds[kind] = struct{}{}
+	}
+	if req.Prune {
+		for _, kind := range pruneKinds {
+			kinds[kind] = struct{}{}
+		}
+	}
+	var updates, removals Changes
+	for _, kind := range sortKinds(kinds) {
+		existing, err := r.getExisting(req, kind)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, resource := range declared[kind] {
+			key := resourceKey(kind, resource.Metadata.Name)
+			change, err := r.diffResource(existing[key], resource)
+			if err != nil {
+				return nil, trace.Wrap(err, "failed to compare %v", key)
+			}
+			delete(existing, key)
+			if change != nil {
+				updates = append(updates, *change)
+			}
+		}
+		if !req.Prune || !utils.StringInSlice(pruneKinds, kind) || IsSingletonKind(kind) {
+			continue
+		}
+		var names []string
+		for _, resource := range existing {
+			if isPrunable(kind, resource) {
+				names = append(names, resource.Metadata.Name)
+			}
+		}
+		sort.Strings(names)
+		var kindRemovals Changes
+		for _, name := range names {
+			kindRemovals = append(kindRemovals, Change{
+				Action: ChangeDelete,
+				Kind:   kind,
+				Name:   name,
+			})
+		}
+		removals = append(kindRemovals, removals...)
+	}
+	return append(updates, removals...), nil
+}
+
+// Apply applies the specified changes computed with Diff
+func (r *ResourceControl) Apply(ctx context.Context, changes Changes, req SyncRequest) error {
+	for _, change := range changes {
+		var err error
+		switch change.Action {
+		case ChangeCreate, ChangeUpdate:
+			err = r.upsert(ctx, change.Resource, req)
+		case ChangeDelete:
+			err = r.Resources.Remove(ctx, RemoveRequest{
+				SiteKey:   req.SiteKey,
+				Kind:      change.Kind,
+				Name:      change.Name,
+				Owner:     req.Owner,
+				Manual:    req.Manual,
+				Confirmed: req.Confirmed,
+			})
+		default:
+			err = trace.BadParameter("unknown action %q", change.Action)
+		}
+		if err != nil {
+			return trace.Wrap(err, "failed to %v", change)
+		}
+	}
+	return nil
+}
+
+// upsert creates or updates the specified resource labeled as managed by Apply
+func (r *ResourceControl) upsert(ctx context.Context, resource storage.UnknownResource, req SyncRequest) error {
+	managed, err := markManaged(resource)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return r.Resources.Create(ctx, CreateRequest{
+		SiteKey: req.SiteKey,
+		Resource: teleservices.UnknownResource{
+			ResourceHeader: managed.ResourceHeader,
+			Raw:            managed.Raw,
+		},
+		Upsert:    true,
+		Owner:     req.Owner,
+		Manual:    req.Manual,
+		Confirmed: req.Confirmed,
+	})
+}
+
+// getExisting returns cluster resources of the specified kind
+// indexed by resource key
+func (r *ResourceControl) getExisting(req SyncRequest, kind string) (map[string]teleservices.UnknownResource, error) {
+	collection, err := r.Resources.GetCollection(ListRequest{
+		SiteKey:     req.SiteKey,
+		Kind:        kind,
+		WithSecrets: true,
+		User:        req.Owner,
+	})
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	existing := make(map[string]teleservices.UnknownResource)
+	if collection == nil {
+		return existing, nil
+	}
+	resources, err := collection.Resources()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, resource := range resources {
+		existing[resourceKey(kind, resource.Metadata.Name)] = resource
+	}
+	return existing, nil
+}
+
+// diffResource compares the declared resource with its cluster counterpart.
+// Returns nil if the resources are equivalent
+func (r *ResourceControl) diffResource(existing teleservices.UnknownResource, declared storage.UnknownResource) (*Change, error) {
+	change := &Change{
+		Kind:     declared.Kind,
+		Name:     declared.Metadata.Name,
+		Resource: declared,
+	}
+	if len(existing.Raw) == 0 {
+		change.Action = ChangeCreate
+		return change, nil
+	}
+	before, err := r.normalize(storage.UnknownResource{
+		ResourceHeader: existing.ResourceHeader,
+		Raw:            existing.Raw,
+	})
+	if err != nil {
+		// compare as is if the cluster resource does not pass the current validation
+		log.Warnf("Failed to normalize %v: %v.", resourceKey(declared.Kind, declared.Metadata.Name), err)
+		before, err = normalizeResource(declared.Kind, existing.Raw)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	after, err := r.normalize(declared)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if before == after {
+		return nil, nil
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(before),
+		B:        difflib.SplitLines(after),
+		FromFile: "cluster",
+		ToFile:   "declared",
+		Context:  3,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	change.Action = ChangeUpdate
+	change.Diff = diff
+	return change, nil
+}
+
+// normalize returns the resource with defaults set by the resource
+// controller, if supported, in a form suitable for comparison
+func (r *ResourceControl) normalize(resource storage.UnknownResource) (string, error) {
+	data := resource.Raw
+	if normalizer, ok := r.Resources.(Normalizer); ok {
+		// the resource kind has already been converted to canonical form
+		var err error
+		data, err = normalizer.Normalize(resource)
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+	}
+	return normalizeResource(resource.Kind, data)
+}
+
+// normalizeResource returns the resource as YAML with sorted keys and
+// without the metadata attributes maintained by the cluster
+func normalizeResource(kind string, data []byte) (string, error) {
+	var resource map[string]interface{}
+	if err := json.Unmarshal(data, &resource); err != nil {
+		return "", trace.Wrap(err)
+	}
+	if metadata, ok := resource["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "id")
+		delete(metadata, "expires")
+		if metadata["namespace"] == "default" {
+			delete(metadata, "namespace")
+		}
+		if IsSingletonKind(kind) {
+			delete(metadata, "name")
+		}
+		if labels, ok := metadata["labels"].(map[string]interface{}); ok {
+			delete(labels, ManagedByLabel)
+			if len(labels) == 0 {
+				delete(metadata, "labels")
+			}
+		}
+		if len(metadata) == 0 {
+			delete(resource, "metadata")
+		}
+	}
+	resource["kind"] = kind
+	out, err := yaml.Marshal(resource)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return string(out), nil
+}
+
+// markManaged returns a copy of the resource labeled as managed by Apply.
+// Resources the cluster can only have one of are never pruned
+// and are returned unchanged
+func markManaged(resource storage.UnknownResource) (*storage.UnknownResource, error) {
+	if IsSingletonKind(resource.Kind) {
+		return &resource, nil
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(resource.Raw, &object); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	metadata, ok := object["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = make(map[string]interface{})
+		object["metadata"] = metadata
+	}
+	labels, ok := metadata["labels"].(map[string]interface{})
+	if !ok {
+		labels = make(map[string]interface{})
+		metadata["labels"] = labels
+	}
+	labels[ManagedByLabel] = ManagedByApply
+	raw, err := json.Marshal(object)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	resource.Raw = raw
+	if resource.Metadata.Labels == nil {
+		resource.Metadata.Labels = make(map[string]string)
+	} else {
+		copied := make(map[string]string, len(resource.Metadata.Labels)+1)
+		for k, v := range resource.Metadata.Labels {
+			copied[k] = v
+		}
+		resource.Metadata.Labels = copied
+	}
+	resource.Metadata.Labels[ManagedByLabel] = ManagedByApply
+	return &resource, nil
+}
+
+// isPrunable returns true if the cluster resource has been created by Apply
+// and can be removed when it is no longer declared.
+// Of all users, only regular users are ever pruned
+func isPrunable(kind string, resource teleservices.UnknownResource) bool {
+	var object struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			Type string `json:"type"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(resource.Raw, &object); err != nil {
+		return false
+	}
+	if object.Metadata.Labels[ManagedByLabel] != ManagedByApply {
+		return false
+	}
+	if kind == teleservices.KindUser && object.Spec.Type != storage.RegularUser {
+		return false
+	}
+	return true
+}
+
+// sortKinds returns the specified kinds sorted according to KindOrder.
+// Kinds not present in KindOrder go last in alphabetical order
+func sortKinds(kinds map[string]struct{}) (sorted []string) {
+	for kind := range kinds {
+		sorted = append(sorted, kind)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		pi, pj := kindPriority(sorted[i]), kindPriority(sorted[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+
+func kindPriority(kind string) int {
+	for i, k := range KindOrder {
+		if k == kind {
+			return i
+		}
+	}
+	return len(KindOrder)
+}
+
+func resourceKey(kind, name string) string {
+	if IsSingletonKind(kind) {
+		return kind
+	}
+	return fmt.Sprintf("%v/%v", kind, name)
+}
+
+// IsSingletonKind returns true if the cluster can have at most one
+// resource of the specified kind.
+// Singleton resources are matched regardless of their names and
+// are never pruned
+func IsSingletonKind(kind string) bool {
+	switch kind {
+	case teleservices.KindClusterAuthPreference,
+		storage.KindTLSKeyPair,
+		storage.KindAuthGateway,
+		storage.KindSMTPConfig,
+		storage.KindAlertTarget,
+		storage.KindRuntimeEnvironment,
+		storage.KindClusterConfiguration,
+		storage.KindPersistentStorage:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/check.v1"
+)
+
+type SyncSuite struct{}
+
+var _ = check.Suite(&SyncSuite{})
+
+func (s *SyncSuite) TestDiffAndApply(c *check.C) {
+	existing := &testResources{}
+	control := NewControl(existing)
+	err := control.Create(context.TODO(), strings.NewReader(clusterResources), CreateRequest{})
+	c.Assert(err, check.IsNil)
+
+	declared := mustRead(c, declaredResources)
+	req := SyncRequest{Resources: declared, Prune: true}
+	changes, err := control.Diff(req)
+	c.Assert(err, check.IsNil)
+	c.Assert(summarize(changes), check.DeepEquals, []string{
+		"update logforwarder/forwarder1",
+		"create logforwarder/forwarder3",
+		"create clusterconfiguration",
+		"delete logforwarder/forwarder2",
+	})
+	c.Assert(changes[0].Diff, check.Matches, "(?s).*-  address: 192.168.1.1:514\n\\+  address: 192.168.1.2:514\n.*")
+
+	err = control.Apply(context.TODO(), changes, req)
+	c.Assert(err, check.IsNil)
+
+	changes, err = control.Diff(SyncRequest{Resources: mustRead(c, declaredResources), Prune: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes.IsEmpty(), check.Equals, true, check.Commentf("%v", summarize(changes)))
+
+	var kinds []string
+	for _, resource := range existing.resources {
+		kinds = append(kinds, resource.Kind+"/"+resource.Metadata.Name)
+	}
+	c.Assert(kinds, check.DeepEquals, []string{
+		"logforwarder/forwarder1",
+		"alert/alert1",
+		"logforwarder/forwarder4",
+		"user/alice@example.com",
+		"user/agent@example.com",
+		"logforwarder/forwarder3",
+		"clusterconfiguration/",
+	}, check.Commentf("alerts are not declared and unmanaged resources should not be pruned"))
+	for _, resource := range existing.resources {
+		if resource.Kind == storage.KindLogForwarder && resource.Metadata.Name != "forwarder4" {
+			c.Assert(isPrunable(resource.Kind, resource), check.Equals, true,
+				check.Commentf("applied resources should be managed: %s", resource.Raw))
+		}
+		if resource.Kind == storage.KindClusterConfiguration {
+			c.Assert(resource.Metadata.Labels[ManagedByLabel], check.Equals, "")
+		}
+	}
+}
+
+func (s *SyncSuite) TestPrunesOnlyRegularUsers(c *check.C) {
+	control := NewControl(&testResources{})
+	err := control.Create(context.TODO(), strings.NewReader(clusterResources), CreateRequest{})
+	c.Assert(err, check.IsNil)
+
+	changes, err := control.Diff(SyncRequest{Prune: true, PruneKinds: []string{"users"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(summarize(changes), check.DeepEquals, []string{
+		"delete user/alice@example.com",
+	})
+}
+
+func (s *SyncSuite) TestComparesWithDefaults(c *check.C) {
+	existing := &normalizingResources{}
+	control := NewControl(existing)
+	err := control.Create(context.TODO(), strings.NewReader(`kind: logforwarder
+version: v2
+metadata:
+  name: forwarder1
+spec:
+  address: 192.168.1.1:514
+  protocol: tcp
+`), CreateRequest{})
+	c.Assert(err, check.IsNil)
+
+	changes, err := control.Diff(SyncRequest{Resources: mustRead(c, `kind: logforwarder
+version: v2
+metadata:
+  name: forwarder1
+spec:
+  address: 192.168.1.1:514
+`)})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes.IsEmpty(), check.Equals, true, check.Commentf("%v", summarize(changes)))
+}
+
+func (s *SyncSuite) TestPruneKinds(c *check.C) {
+	control := NewControl(&testResources{})
+	err := control.Create(context.TODO(), strings.NewReader(clusterResources), CreateRequest{})
+	c.Assert(err, check.IsNil)
+
+	changes, err := control.Diff(SyncRequest{
+		Prune:      true,
+		PruneKinds: []string{"alerts", storage.KindLogForwarder},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(summarize(changes), check.DeepEquals, []string{
+		"delete logforwarder/forwarder1",
+		"delete logforwarder/forwarder2",
+		"delete alert/alert1",
+	}, check.Commentf("unmanaged forwarder4 should not be pruned"))
+
+	_, err = control.Diff(SyncRequest{Prune: true, PruneKinds: []string{storage.KindAuthGateway}})
+	c.Assert(err, check.NotNil)
+}
+
+func (s *SyncSuite) TestRejectsDuplicates(c *check.C) {
+	control := NewControl(&testResources{})
+	_, err := control.Diff(SyncRequest{Resources: mustRead(c, declaredResources+"---"+declaredResources)})
+	c.Assert(err, check.NotNil)
+}
+
+func (s *SyncSuite) TestReadsDirectory(c *check.C) {
+	dir := c.MkDir()
+	c.Assert(os.MkdirAll(filepath.Join(dir, "nested"), 0755), check.IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "b.yaml"), []byte(clusterResources), 0644), check.IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "nested", "a.yml"), []byte(declaredResources), 0644), check.IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("# resources"), 0644), check.IsNil)
+
+	resources, err := ReadPath(dir)
+	c.Assert(err, check.IsNil)
+	var names []string
+	for _, resource := range resources {
+		names = append(names, resource.Kind+"/"+resource.Metadata.Name)
+	}
+	c.Assert(names, check.DeepEquals, []string{
+		"logforwarder/forwarder1",
+		"logforwarder/forwarder2",
+		"alert/alert1",
+		"logforwarder/forwarder4",
+		"user/alice@example.com",
+		"user/agent@example.com",
+		"logforwarder/forwarder1",
+		"logforwarder/forwarder3",
+		"clusterconfiguration/",
+	})
+}
+
+func mustRead(c *check.C, data string) (resources []storage.UnknownResource) {
+	err := ForEach(strings.NewReader(data), func(resource storage.UnknownResource) error {
+		resources = append(resources, resource)
+		return nil
+	})
+	c.Assert(err, check.IsNil)
+	return resources
+}
+
+func summarize(changes Changes) (result []string) {
+	for _, change := range changes {
+		result = append(result, change.String())
+	}
+	return result
+}
+
+// normalizingResources sets the default log forwarder protocol
+type normalizingResources struct {
+	testResources
+}
+
+func (r *normalizingResources) Normalize(resource storage.UnknownResource) ([]byte, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal(resource.Raw, &object); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if spec, ok := object["spec"].(map[string]interface{}); ok && spec["protocol"] == nil {
+		spec["protocol"] = "tcp"
+	}
+	return json.Marshal(object)
+}
+
+const clusterResources = `kind: logforwarder
+version: v2
+metadata:
+  name: forwarder1
+  id: 1
+  labels:
+    gravitational.io/managed-by: gravity-resource-apply
+spec:
+  address: 192.168.1.1:514
+---
+kind: logforwarder
+version: v2
+metadata:
+  name: forwarder2
+  labels:
+    gravitational.io/managed-by: gravity-resource-apply
+spec:
+  address: 192.168.1.3:514
+---
+kind: alert
+version: v2
+metadata:
+  name: alert1
+  labels:
+    gravitational.io/managed-by: gravity-resource-apply
+spec:
+  alert_name: CPU
+  formula: high_cpu
+---
+kind: logforwarder
+version: v2
+metadata:
+  name: forwarder4
+spec:
+  address: 192.168.1.5:514
+---
+kind: user
+version: v2
+metadata:
+  name: alice@example.com
+  labels:
+    gravitational.io/managed-by: gravity-resource-apply
+spec:
+  type: regular
+---
+kind: user
+version: v2
+metadata:
+  name: agent@example.com
+  labels:
+    gravitational.io/managed-by: gravity-resource-apply
+spec:
+  type: agent
+`
+
+const declaredResources = `kind: logforwarder
+version: v2
+metadata:
+  name: forwarder1
+spec:
+  address: 192.168.1.2:514
+---
+kind: logforwarder
+version: v2
+metadata:
+  name: forwarder3
+spec:
+  address: 192.168.1.4:514
+---
+kind: clusterconfiguration
+version: v1
+spec:
+  global:
+    cloudProvider: aws
+`
@@ -25,6 +25,7 @@ import (
 	"io/ioutil"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gravitational/gravity/lib/app"
 	apptest "github.com/gravitational/gravity/lib/app/service/test"
@@ -38,6 +39,7 @@ import (
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/users"
 
+	teleservices "github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/trace"
 	"github.com/mailgun/timetools"
 	. "gopkg.in/check.v1"
@@ -153,7 +155,7 @@ func (s *OpsSuite) SitesCRUD(c *C) {
 	c.Assert(logStream.Close(), IsNil)
 
 	// download crashreport
-	reportStream, err := s.O.GetSiteReport(opKey.SiteKey())
+	reportStream, err := s.O.GetSiteReport(ops.GetClusterReportRequest{SiteKey: opKey.SiteKey()})
 	c.Assert(err, IsNil)
 	_, err = io.Copy(ioutil.Discard, reportStream)
 	c.Assert(err, IsNil)
@@ -170,6 +172,132 @@ func (s *OpsSuite) SitesCRUD(c *C) {
 
 }
 
+func (s *OpsSuite) WatchEvents(c *C) {
+	a, err := s.O.CreateAccount(ops.NewAccountRequest{
+		Org: "example.com",
+	})
+	c.Assert(err, IsNil)
+
+	site, err := s.O.CreateSite(ops.NewSiteRequest{
+		AppPackage: s.testApp.String(),
+		AccountID:  a.ID,
+		Provider:   schema.ProviderOnPrem,
+		DomainName: "example.com",
+	})
+	c.Assert(err, IsNil)
+	siteKey := ops.SiteKey{
+		SiteDomain: site.Domain,
+		AccountID:  a.ID,
+	}
+
+	_, err = s.O.WatchEvents(context.TODO(), siteKey, storage.WatchFilter{Kinds: []string{"unknown"}})
+	c.Assert(err, NotNil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	watcher, err := s.O.WatchEvents(ctx, siteKey, storage.WatchFilter{
+		Kinds: []string{storage.KindOperation, storage.KindProgressEntry},
+	})
+	c.Assert(err, IsNil)
+	defer watcher.Close()
+
+	opKey, err := s.O.CreateSiteInstallOperation(context.TODO(), ops.CreateSiteInstallOperationRequest{
+		AccountID:  a.ID,
+		SiteDomain: site.Domain,
+		Variables:  storage.OperationVariables{},
+	})
+	c.Assert(err, IsNil)
+
+	// the new operation is created along with its first progress entry
+	var operation, progress bool
+	for !operation || !progress {
+		select {
+		case event := <-watcher.Events():
+			c.Assert(event.Type, Equals, storage.EventTypePut)
+			switch event.Kind {
+			case storage.KindOperation:
+				c.Assert((*ops.SiteOperation)(event.Operation).Key(), Equals, *opKey)
+				operation = true
+			case storage.KindProgressEntry:
+				c.Assert(event.Progress.OperationID, Equals, opKey.OperationID)
+				progress = true
+			default:
+				c.Fatalf("unexpected event %#v", event)
+			}
+		case <-watcher.Done():
+			c.Fatalf("watcher closed: %v", watcher.Error())
+		}
+	}
+	c.Assert(watcher.Close(), IsNil)
+	c.Assert(watcher.Error(), IsNil)
+}
+
+func (s *OpsSuite) BackupPolicies(c *C) {
+	a, err := s.O.CreateAccount(ops.NewAccountRequest{
+		Org: "example.com",
+	})
+	c.Assert(err, IsNil)
+
+	site, err := s.O.CreateSite(ops.NewSiteRequest{
+		AppPackage: s.testApp.String(),
+		AccountID:  a.ID,
+		Provider:   schema.ProviderOnPrem,
+		DomainName: "example.com",
+	})
+	c.Assert(err, IsNil)
+	siteKey := ops.SiteKey{
+		SiteDomain: site.Domain,
+		AccountID:  a.ID,
+	}
+
+	policies, err := s.O.GetBackupPolicies(siteKey, false)
+	c.Assert(err, IsNil)
+	c.Assert(policies, HasLen, 0)
+
+	policy := storage.NewBackupPolicy("nightly", storage.BackupPolicySpecV2{
+		Schedule:      storage.BackupSchedule{Interval: teleservices.NewDuration(24 * time.Hour)},
+		Retention:     storage.BackupRetention{Count: 7},
+		Destination:   storage.BackupDestination{Path: c.MkDir()},
+		EncryptionKey: "secret",
+	})
+	c.Assert(s.O.UpsertBackupPolicy(context.TODO(), siteKey, policy), IsNil)
+
+	policies, err = s.O.GetBackupPolicies(siteKey, false)
+	c.Assert(err, IsNil)
+	c.Assert(policies, HasLen, 1)
+	c.Assert(policies[0].GetName(), Equals, "nightly")
+	c.Assert(policies[0].GetInterval(), Equals, 24*time.Hour)
+	c.Assert(policies[0].GetEncryptionKey(), Equals, "")
+
+	policies, err = s.O.GetBackupPolicies(siteKey, true)
+	c.Assert(err, IsNil)
+	c.Assert(policies, HasLen, 1)
+	c.Assert(policies[0].GetEncryptionKey(), Equals, "secret")
+
+	// policies retrieved without secrets cannot be applied as is
+	s3Policy := storage.NewBackupPolicy("s3", storage.BackupPolicySpecV2{
+		Schedule: storage.BackupSchedule{Interval: teleservices.NewDuration(24 * time.Hour)},
+		Destination: storage.BackupDestination{S3: &storage.S3Destination{
+			Bucket:          "backups",
+			AccessKeyID:     "access-key",
+			SecretAccessKey: "secret-key",
+		}},
+	})
+	err = s.O.UpsertBackupPolicy(context.TODO(), siteKey, s3Policy.WithoutSecrets())
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+
+	backups, err := s.O.ListBackups(context.TODO(), siteKey)
+	c.Assert(err, IsNil)
+	c.Assert(backups, HasLen, 0)
+
+	_, err = s.O.GetBackupReader(context.TODO(), siteKey, "nightly-20180101000000")
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+
+	c.Assert(s.O.DeleteBackupPolicy(context.TODO(), siteKey, "nightly"), IsNil)
+	err = s.O.DeleteBackupPolicy(context.TODO(), siteKey, "nightly")
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+}
+
 func (s *OpsSuite) InstallInstructions(c *C) {
 	a, err := s.O.CreateAccount(ops.NewAccountRequest{
 		Org: "example.com",
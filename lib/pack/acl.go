@@ -160,15 +160,132 @@ func (a *ACLService) UpdatePackageLabels(loc loc.Locator, addLabels map[string]s
 
 // Read package opens and returns package contents
 func (a *ACLService) ReadPackage(loc loc.Locator) (*PackageEnvelope, io.ReadCloser, error) {
-	if err := a.repoAction(loc.Repository, teleservices.VerbRead); err != nil {
+	if err := a.readPackageAction(loc); err != nil {
 		return nil, nil, trace.Wrap(err)
 	}
+	return a.packages.ReadPackage(loc)
+}
+
+// GetPackageChunks returns the list of chunks the package consists of
+func (a *ACLService) GetPackageChunks(loc loc.Locator) (*PackageChunks, error) {
+	if err := a.readPackageAction(loc); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	chunked, ok := a.packages.(ChunkedPackageService)
+	if !ok {
+		return nil, trace.NotImplemented("package service does not support chunked transfer")
+	}
+	return chunked.GetPackageChunks(loc)
+}
+
+// ReadPackageChunks returns a stream with contents of the requested package chunks
+func (a *ACLService) ReadPackageChunks(loc loc.Locator, hashes []string) (io.ReadCloser, error) {
+	if err := a.readPackageAction(loc); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	chunked, ok := a.packages.(ChunkedPackageService)
+	if !ok {
+		return nil, trace.NotImplemented("package service does not support chunked transfer")
+	}
+	return chunked.ReadPackageChunks(loc, hashes)
+}
+
+// StartUpload starts a new package upload
+func (a *ACLService) StartUpload(req UploadRequest) (*UploadSession, error) {
+	if err := a.uploadAction(req); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	uploads, err := a.uploads()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return uploads.StartUpload(req)
+}
+
+// GetUpload returns the state of the specified upload
+func (a *ACLService) GetUpload(id string) (*UploadSession, error) {
+	uploads, session, err := a.getUpload(id)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return uploads.GetUpload(session.ID)
+}
+
+// AppendUpload verifies the chunk read from data and appends it to the upload
+func (a *ACLService) AppendUpload(id string, chunk UploadChunk, data io.Reader) (*UploadSession, error) {
+	uploads, session, err := a.getUpload(id)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return uploads.AppendUpload(session.ID, chunk, data)
+}
+
+// CommitUpload creates the package from the completed upload
+func (a *ACLService) CommitUpload(id string) (*PackageEnvelope, error) {
+	uploads, session, err := a.getUpload(id)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return uploads.CommitUpload(session.ID)
+}
+
+// AbortUpload cancels the upload and discards the received data
+func (a *ACLService) AbortUpload(id string) error {
+	uploads, session, err := a.getUpload(id)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return uploads.AbortUpload(session.ID)
+}
+
+// getUpload returns the specified upload after checking access to it
+func (a *ACLService) getUpload(id string) (UploadService, *UploadSession, error) {
+	uploads, err := a.uploads()
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	session, err := uploads.GetUpload(id)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	if err := a.uploadAction(session.Request); err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	return uploads, session, nil
+}
+
+// uploadAction checks access to create the package described by req
+func (a *ACLService) uploadAction(req UploadRequest) error {
+	if err := a.repoAction(req.Package.Repository, teleservices.VerbCreate); err != nil {
+		return trace.Wrap(err)
+	}
+	if req.Upsert {
+		if err := a.repoAction(req.Package.Repository, teleservices.VerbUpdate); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+func (a *ACLService) uploads() (UploadService, error) {
+	uploads, ok := a.packages.(UploadService)
+	if !ok {
+		return nil, trace.NotImplemented("package service does not support resumable uploads")
+	}
+	return uploads, nil
+}
+
+// readPackageAction checks access to the contents of the specified package
+func (a *ACLService) readPackageAction(loc loc.Locator) error {
+	if err := a.repoAction(loc.Repository, teleservices.VerbRead); err != nil {
+		return trace.Wrap(err)
+	}
 	if loc.Name == constants.OpsCenterCAPackage {
 		if err := a.checker.CheckAccessToRule(a.repoContext(loc.Repository), teledefaults.Namespace, storage.KindRepository, storage.VerbReadSecrets, false); err != nil {
-			return nil, nil, trace.Wrap(err)
+			return trace.Wrap(err)
 		}
 	}
-	return a.packages.ReadPackage(loc)
+	return nil
 }
 
 // ReadPackageEnvelope returns package envelope
@@ -0,0 +1,190 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package localpack
+
+import (
+	"io"
+
+	"github.com/gravitational/gravity/lib/blob"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// GetPackageChunks returns the list of chunks the package consists of
+func (p *PackageServer) GetPackageChunks(loc loc.Locator) (*pack.PackageChunks, error) {
+	objects, err := p.chunkedObjects()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	envelope, err := p.ReadPackageEnvelope(loc)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	chunks, err := objects.GetBLOBChunks(envelope.SHA512)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &pack.PackageChunks{
+		Envelope: *envelope,
+		Chunks:   chunks,
+	}, nil
+}
+
+// ReadPackageChunks returns a stream with contents of the requested package
+// chunks concatenated in the order they were requested.
+// Only chunks that belong to the package can be requested
+func (p *PackageServer) ReadPackageChunks(loc loc.Locator, hashes []string) (io.ReadCloser, error) {
+	objects, err := p.chunkedObjects()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	chunks, err := p.GetPackageChunks(loc)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	packageChunks := utils.NewStringSet()
+	for _, chunk := range chunks.Chunks {
+		packageChunks.Add(chunk.Hash)
+	}
+	for _, hash := range hashes {
+		if !packageChunks.Has(hash) {
+			return nil, trace.NotFound("package %v has no chunk %v", loc, hash)
+		}
+	}
+	return &chunkStream{objects: objects, hashes: hashes}, nil
+}
+
+// GetMissingChunks returns the subset of chunks not yet present in the storage
+func (p *PackageServer) GetMissingChunks(chunks []blob.Chunk) ([]blob.Chunk, error) {
+	objects, err := p.chunkedObjects()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var missing []blob.Chunk
+	seen := utils.NewStringSet()
+	for _, chunk := range chunks {
+		if seen.Has(chunk.Hash) {
+			continue
+		}
+		seen.Add(chunk.Hash)
+		exists, err := objects.HasChunk(chunk.Hash)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if !exists {
+			missing = append(missing, chunk)
+		}
+	}
+	return missing, nil
+}
+
+// WriteChunk verifies and persists the chunk read from data
+func (p *PackageServer) WriteChunk(chunk blob.Chunk, data io.Reader) error {
+	objects, err := p.chunkedObjects()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return objects.WriteChunk(chunk, data)
+}
+
+// CreatePackageFromChunks creates a new package in existing repository
+// from the previously written chunks
+func (p *PackageServer) CreatePackageFromChunks(loc loc.Locator, chunks []blob.Chunk, options ...pack.PackageOption) (*pack.PackageEnvelope, error) {
+	objects, err := p.chunkedObjects()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	_, err = p.backend.GetRepository(loc.Repository)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	blobEnvelope, err := objects.WriteBLOBFromChunks(chunks)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return p.createPackage(loc, *blobEnvelope, options...)
+}
+
+// UpsertPackageFromChunks creates or updates the package from
+// the previously written chunks
+func (p *PackageServer) UpsertPackageFromChunks(loc loc.Locator, chunks []blob.Chunk, options ...pack.PackageOption) (*pack.PackageEnvelope, error) {
+	objects, err := p.chunkedObjects()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	blobEnvelope, err := objects.WriteBLOBFromChunks(chunks)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return p.upsertPackage(loc, *blobEnvelope, options...)
+}
+
+func (p *PackageServer) chunkedObjects() (blob.ChunkedObjects, error) {
+	objects, ok := p.cfg.Objects.(blob.ChunkedObjects)
+	if !ok {
+		return nil, trace.NotImplemented("package storage does not support chunked transfer")
+	}
+	return objects, nil
+}
+
+// chunkStream reads the specified chunks one after another
+// opening each chunk only when the previous one has been consumed
+type chunkStream struct {
+	objects blob.ChunkedObjects
+	hashes  []string
+	current io.ReadCloser
+}
+
+// Read reads data from the current chunk
+func (s *chunkStream) Read(p []byte) (int, error) {
+	for {
+		if s.current == nil {
+			if len(s.hashes) == 0 {
+				return 0, io.EOF
+			}
+			r, err := s.objects.OpenChunk(s.hashes[0])
+			if err != nil {
+				return 0, trace.Wrap(err)
+			}
+			s.current = r
+			s.hashes = s.hashes[1:]
+		}
+		n, err := s.current.Read(p)
+		if err == io.EOF {
+			s.current.Close()
+			s.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+// Close closes the stream
+func (s *chunkStream) Close() error {
+	if s.current != nil {
+		err := s.current.Close()
+		s.current = nil
+		return trace.Wrap(err)
+	}
+	return nil
+}
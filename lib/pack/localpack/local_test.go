@@ -18,6 +18,9 @@ package localpack
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -28,10 +31,12 @@ import (
 	"github.com/gravitational/gravity/lib/compare"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack"
 	"github.com/gravitational/gravity/lib/pack/suite"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/storage/keyval"
 
+	"github.com/gravitational/trace"
 	"github.com/mailgun/timetools"
 	log "github.com/sirupsen/logrus"
 	. "gopkg.in/check.v1"
@@ -147,3 +152,64 @@ func (s *LocalSuite) TestDoesnotDeleteCollidingBlobs(c *C) {
 	c.Assert(blobsBefore, compare.DeepEquals, []string{package1.SHA512})
 	c.Assert(blobsAfter, compare.DeepEquals, []string{package1.SHA512})
 }
+
+func (s *LocalSuite) TestResumableUpload(c *C) {
+	data := []byte("first chunk,second chunk")
+	locator := loc.MustParseLocator("gravitational.io/app:0.0.1")
+	c.Assert(s.server.UpsertRepository("gravitational.io", time.Time{}), IsNil)
+
+	session, err := s.server.StartUpload(pack.NewUploadRequest(locator, int64(len(data)), false,
+		pack.WithLabels(map[string]string{"key": "value"}),
+		pack.WithEncrypted(true), pack.WithCreatedBy("alice@example.com")))
+	c.Assert(err, IsNil)
+
+	session, err = s.server.AppendUpload(session.ID, uploadChunk(0, data[:12]), bytes.NewReader(data[:12]))
+	c.Assert(err, IsNil)
+	c.Assert(session.Offset, Equals, int64(12))
+
+	// a chunk received twice is rejected
+	_, err = s.server.AppendUpload(session.ID, uploadChunk(0, data[:12]), bytes.NewReader(data[:12]))
+	c.Assert(trace.IsCompareFailed(err), Equals, true, Commentf("%v", err))
+
+	// a corrupted chunk is rejected
+	chunk := uploadChunk(12, data[12:])
+	_, err = s.server.AppendUpload(session.ID, chunk, bytes.NewReader([]byte("second chunX")))
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+
+	// upload cannot be committed until all data is received
+	_, err = s.server.CommitUpload(session.ID)
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+
+	session, err = s.server.GetUpload(session.ID)
+	c.Assert(err, IsNil)
+	c.Assert(session.Offset, Equals, int64(12))
+	session, err = s.server.AppendUpload(session.ID, chunk, bytes.NewReader(data[12:]))
+	c.Assert(err, IsNil)
+	c.Assert(session.Complete(), Equals, true)
+
+	envelope, err := s.server.CommitUpload(session.ID)
+	c.Assert(err, IsNil)
+	c.Assert(envelope.Locator, Equals, locator)
+	c.Assert(envelope.RuntimeLabels, DeepEquals, map[string]string{"key": "value"})
+	c.Assert(envelope.Encrypted, Equals, true)
+	c.Assert(envelope.CreatedBy, Equals, "alice@example.com")
+
+	_, reader, err := s.server.ReadPackage(locator)
+	c.Assert(err, IsNil)
+	defer reader.Close()
+	received, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(string(received), Equals, string(data))
+
+	_, err = s.server.GetUpload(session.ID)
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+
+	// the package now exists
+	_, err = s.server.StartUpload(pack.NewUploadRequest(locator, int64(len(data)), false))
+	c.Assert(trace.IsAlreadyExists(err), Equals, true, Commentf("%v", err))
+}
+
+func uploadChunk(offset int64, data []byte) pack.UploadChunk {
+	checksum := sha256.Sum256(data)
+	return pack.UploadChunk{Offset: offset, SHA256: hex.EncodeToString(checksum[:])}
+}
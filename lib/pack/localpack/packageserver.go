@@ -19,7 +19,9 @@ package localpack
 import (
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gravitational/gravity/lib/blob"
@@ -52,6 +54,10 @@ type Config struct {
 
 	// UnpackedDir is the path for unpacked packages
 	UnpackedDir string
+
+	// UploadsDir is the path for incomplete package uploads,
+	// if omitted, a directory next to UnpackedDir will be used
+	UploadsDir string
 }
 
 // PackageServer manages BLOBs of data and their metadata as packages
@@ -59,6 +65,8 @@ type PackageServer struct {
 	cfg     Config
 	clock   timetools.TimeProvider
 	backend storage.Backend
+	// uploadsMu serializes updates to upload sessions
+	uploadsMu sync.Mutex
 }
 
 func New(cfg Config) (*PackageServer, error) {
@@ -74,6 +82,9 @@ func New(cfg Config) (*PackageServer, error) {
 	if err := os.MkdirAll(cfg.UnpackedDir, defaults.SharedDirMask); err != nil {
 		return nil, trace.Wrap(err)
 	}
+	if cfg.UploadsDir == "" {
+		cfg.UploadsDir = filepath.Join(filepath.Dir(cfg.UnpackedDir), defaults.UploadsDir)
+	}
 	if cfg.Clock == nil {
 		cfg.Clock = &timetools.RealTime{}
 	}
@@ -154,34 +165,15 @@ func (p *PackageServer) CreatePackage(loc loc.Locator, data io.Reader, options .
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	return p.createPackage(loc, *blobEnvelope, options...)
+}
 
-	pkg := storage.Package{
-		Repository: loc.Repository,
-		Name:       loc.Name,
-		Version:    loc.Version,
-		SHA512:     blobEnvelope.SHA512,
-		SizeBytes:  int(blobEnvelope.SizeBytes),
-		Created:    p.cfg.Clock.UtcNow(),
-	}
-	for _, option := range options {
-		option(&pkg)
-	}
-
-	envelope := &pack.PackageEnvelope{
-		Locator:       loc,
-		SHA512:        blobEnvelope.SHA512,
-		SizeBytes:     blobEnvelope.SizeBytes,
-		RuntimeLabels: pkg.RuntimeLabels,
-		Hidden:        pkg.Hidden,
-		Encrypted:     pkg.Encrypted,
-		Type:          pkg.Type,
-		Manifest:      pkg.Manifest,
-		Created:       pkg.Created,
-		CreatedBy:     pkg.CreatedBy,
-	}
+// createPackage creates a package for the BLOB that has already been written
+func (p *PackageServer) createPackage(loc loc.Locator, blobEnvelope blob.Envelope, options ...pack.PackageOption) (*pack.PackageEnvelope, error) {
+	pkg, envelope := p.newPackage(loc, blobEnvelope, options...)
 
 	// check that the repository exists
-	_, err = p.backend.GetRepository(loc.Repository)
+	_, err := p.backend.GetRepository(loc.Repository)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -201,33 +193,14 @@ func (p *PackageServer) UpsertPackage(loc loc.Locator, data io.Reader, options .
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	return p.upsertPackage(loc, *blobEnvelope, options...)
+}
 
-	pkg := storage.Package{
-		Repository: loc.Repository,
-		Name:       loc.Name,
-		Version:    loc.Version,
-		SHA512:     blobEnvelope.SHA512,
-		SizeBytes:  int(blobEnvelope.SizeBytes),
-		Created:    p.cfg.Clock.UtcNow(),
-	}
-	for _, option := range options {
-		option(&pkg)
-	}
-
-	envelope := &pack.PackageEnvelope{
-		Locator:       loc,
-		SHA512:        blobEnvelope.SHA512,
-		SizeBytes:     blobEnvelope.SizeBytes,
-		RuntimeLabels: pkg.RuntimeLabels,
-		Hidden:        pkg.Hidden,
-		Encrypted:     pkg.Encrypted,
-		Type:          pkg.Type,
-		Manifest:      pkg.Manifest,
-		Created:       pkg.Created,
-		CreatedBy:     pkg.CreatedBy,
-	}
+// upsertPackage upserts the package for the BLOB that has already been written
+func (p *PackageServer) upsertPackage(loc loc.Locator, blobEnvelope blob.Envelope, options ...pack.PackageOption) (*pack.PackageEnvelope, error) {
+	pkg, envelope := p.newPackage(loc, blobEnvelope, options...)
 
-	_, err = p.backend.CreateRepository(storage.NewRepository(loc.Repository))
+	_, err := p.backend.CreateRepository(storage.NewRepository(loc.Repository))
 	if err != nil {
 		if !trace.IsAlreadyExists(err) {
 			return nil, trace.Wrap(err)
@@ -241,6 +214,22 @@ func (p *PackageServer) UpsertPackage(loc loc.Locator, data io.Reader, options .
 	return envelope, nil
 }
 
+// newPackage returns package metadata for the specified BLOB
+func (p *PackageServer) newPackage(loc loc.Locator, blobEnvelope blob.Envelope, options ...pack.PackageOption) (storage.Package, *pack.PackageEnvelope) {
+	pkg := storage.Package{
+		Repository: loc.Repository,
+		Name:       loc.Name,
+		Version:    loc.Version,
+		SHA512:     blobEnvelope.SHA512,
+		SizeBytes:  int(blobEnvelope.SizeBytes),
+		Created:    p.cfg.Clock.UtcNow(),
+	}
+	for _, option := range options {
+		option(&pkg)
+	}
+	return pkg, newEnvelope(loc, &pkg)
+}
+
 func (p *PackageServer) processMetadata(locator loc.Locator) (loc.Locator, error) {
 	locatorPtr, err := pack.ProcessMetadata(p, &locator)
 	if err != nil {
@@ -0,0 +1,256 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package localpack
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/pack"
+
+	"github.com/gravitational/trace"
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// StartUpload starts a new package upload.
+// Each upload is kept in a separate directory with the session state
+// and the data received so far
+func (p *PackageServer) StartUpload(req pack.UploadRequest) (*pack.UploadSession, error) {
+	if err := req.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if _, err := p.backend.GetRepository(req.Package.Repository); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if !req.Upsert {
+		_, err := p.backend.GetPackage(req.Package.Repository, req.Package.Name, req.Package.Version)
+		if err == nil {
+			return nil, trace.AlreadyExists("package %v already exists", req.Package)
+		}
+		if !trace.IsNotFound(err) {
+			return nil, trace.Wrap(err)
+		}
+	}
+	p.removeExpiredUploads()
+	session := pack.UploadSession{
+		ID:      uuid.New(),
+		Request: req,
+		Created: p.cfg.Clock.UtcNow(),
+	}
+	p.uploadsMu.Lock()
+	defer p.uploadsMu.Unlock()
+	dir := p.uploadDir(session.ID)
+	if err := os.MkdirAll(dir, defaults.PrivateDirMask); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	f, err := os.OpenFile(p.uploadDataPath(session.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, defaults.PrivateFileMask)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	f.Close()
+	if err := p.writeUpload(session); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	log.Infof("Started upload %v of %v.", session.ID, req.Package)
+	return &session, nil
+}
+
+// GetUpload returns the state of the specified upload
+func (p *PackageServer) GetUpload(id string) (*pack.UploadSession, error) {
+	p.uploadsMu.Lock()
+	defer p.uploadsMu.Unlock()
+	return p.readUpload(id)
+}
+
+// AppendUpload verifies the chunk read from data and appends it to the upload
+func (p *PackageServer) AppendUpload(id string, chunk pack.UploadChunk, data io.Reader) (*pack.UploadSession, error) {
+	expected, err := hex.DecodeString(chunk.SHA256)
+	if err != nil || len(expected) != sha256.Size {
+		return nil, trace.BadParameter("invalid chunk checksum %q", chunk.SHA256)
+	}
+	// receive the chunk before locking the upload so slow
+	// clients do not block each other
+	buf, err := ioutil.ReadAll(io.LimitReader(data, defaults.UploadMaxChunkSize+1))
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if len(buf) > defaults.UploadMaxChunkSize {
+		return nil, trace.BadParameter("chunk exceeds maximum size of %v bytes", defaults.UploadMaxChunkSize)
+	}
+	actual := sha256.Sum256(buf)
+	if !bytes.Equal(actual[:], expected) {
+		return nil, trace.BadParameter("chunk checksum mismatch: expected %v, got %x", chunk.SHA256, actual)
+	}
+
+	p.uploadsMu.Lock()
+	defer p.uploadsMu.Unlock()
+	session, err := p.readUpload(id)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if chunk.Offset != session.Offset {
+		return nil, trace.CompareFailed("upload %v expects chunk at offset %v, got %v",
+			id, session.Offset, chunk.Offset)
+	}
+	if session.Offset+int64(len(buf)) > session.Request.SizeBytes {
+		return nil, trace.BadParameter("chunk exceeds declared package size of %v bytes",
+			session.Request.SizeBytes)
+	}
+	if err := p.writeUploadData(id, session.Offset, buf); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	session.Offset += int64(len(buf))
+	if err := p.writeUpload(*session); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return session, nil
+}
+
+// CommitUpload creates the package from the completed upload
+// and removes the upload
+func (p *PackageServer) CommitUpload(id string) (*pack.PackageEnvelope, error) {
+	session, err := p.GetUpload(id)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if !session.Complete() {
+		return nil, trace.BadParameter("upload %v is incomplete: received %v of %v bytes",
+			id, session.Offset, session.Request.SizeBytes)
+	}
+	f, err := os.Open(p.uploadDataPath(id))
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	var envelope *pack.PackageEnvelope
+	if session.Request.Upsert {
+		envelope, err = p.UpsertPackage(session.Request.Package, f, session.Request.Options()...)
+	} else {
+		envelope, err = p.CreatePackage(session.Request.Package, f, session.Request.Options()...)
+	}
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := p.AbortUpload(id); err != nil {
+		log.WithError(err).Warnf("Failed to remove upload %v.", id)
+	}
+	log.Infof("Completed upload %v of %v.", id, session.Request.Package)
+	return envelope, nil
+}
+
+// AbortUpload cancels the upload and discards the received data
+func (p *PackageServer) AbortUpload(id string) error {
+	p.uploadsMu.Lock()
+	defer p.uploadsMu.Unlock()
+	if _, err := p.readUpload(id); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.ConvertSystemError(os.RemoveAll(p.uploadDir(id)))
+}
+
+// removeExpiredUploads removes uploads that have not
+// been updated for longer than defaults.UploadSessionTTL
+func (p *PackageServer) removeExpiredUploads() {
+	p.uploadsMu.Lock()
+	defer p.uploadsMu.Unlock()
+	dirs, err := ioutil.ReadDir(p.cfg.UploadsDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.WithError(err).Warn("Failed to list uploads.")
+		}
+		return
+	}
+	now := p.cfg.Clock.UtcNow()
+	for _, dir := range dirs {
+		fi, err := os.Stat(p.uploadSessionPath(dir.Name()))
+		if err != nil && !os.IsNotExist(err) {
+			continue
+		}
+		if err == nil && now.Sub(fi.ModTime()) < defaults.UploadSessionTTL {
+			continue
+		}
+		log.Infof("Removing expired upload %v.", dir.Name())
+		if err := os.RemoveAll(filepath.Join(p.cfg.UploadsDir, dir.Name())); err != nil {
+			log.WithError(err).Warnf("Failed to remove upload %v.", dir.Name())
+		}
+	}
+}
+
+func (p *PackageServer) readUpload(id string) (*pack.UploadSession, error) {
+	if uuid.Parse(id) == nil {
+		return nil, trace.BadParameter("invalid upload ID %q", id)
+	}
+	data, err := ioutil.ReadFile(p.uploadSessionPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, trace.NotFound("upload %v not found", id)
+		}
+		return nil, trace.ConvertSystemError(err)
+	}
+	var session pack.UploadSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &session, nil
+}
+
+// writeUpload atomically replaces the upload state
+func (p *PackageServer) writeUpload(session pack.UploadSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	path := p.uploadSessionPath(session.ID)
+	if err := ioutil.WriteFile(path+".tmp", data, defaults.PrivateFileMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return trace.ConvertSystemError(os.Rename(path+".tmp", path))
+}
+
+// writeUploadData writes data at the specified offset of the upload
+// and makes sure it is persisted before the upload state is updated
+func (p *PackageServer) writeUploadData(id string, offset int64, data []byte) error {
+	f, err := os.OpenFile(p.uploadDataPath(id), os.O_WRONLY, defaults.PrivateFileMask)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteAt(data, offset); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return trace.ConvertSystemError(f.Sync())
+}
+
+func (p *PackageServer) uploadDir(id string) string {
+	return filepath.Join(p.cfg.UploadsDir, id)
+}
+
+func (p *PackageServer) uploadSessionPath(id string) string {
+	return filepath.Join(p.uploadDir(id), "session.json")
+}
+
+func (p *PackageServer) uploadDataPath(id string) string {
+	return filepath.Join(p.uploadDir(id), "data")
+}
@@ -22,10 +22,12 @@ import (
 	"io"
 	"time"
 
+	"github.com/gravitational/gravity/lib/blob"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/storage"
 
 	"github.com/dustin/go-humanize"
+	"github.com/gravitational/trace"
 )
 
 // LatestVersion is the meta version representing the latest version
@@ -186,6 +188,148 @@ type PackageService interface {
 	ReadPackageEnvelope(loc loc.Locator) (*PackageEnvelope, error)
 }
 
+// PackageChunks describes a package as an ordered list of
+// content-addressed chunks its data consists of
+type PackageChunks struct {
+	// Envelope is the package envelope
+	Envelope PackageEnvelope `json:"envelope"`
+	// Chunks lists package chunks in order
+	Chunks []blob.Chunk `json:"chunks"`
+}
+
+// ChunkedPackageService is implemented by package services that can
+// serve packages as sequences of chunks, so that a receiver only needs
+// to download the chunks it does not have yet
+type ChunkedPackageService interface {
+	// GetPackageChunks returns the list of chunks the package consists of
+	GetPackageChunks(loc loc.Locator) (*PackageChunks, error)
+	// ReadPackageChunks returns a stream with contents of the requested
+	// package chunks concatenated in the order they were requested
+	ReadPackageChunks(loc loc.Locator, hashes []string) (io.ReadCloser, error)
+}
+
+// ChunkedPackageWriter is implemented by package services that can
+// create packages from chunks received piecemeal
+type ChunkedPackageWriter interface {
+	// GetMissingChunks returns the subset of the specified chunks
+	// the service does not have yet
+	GetMissingChunks(chunks []blob.Chunk) ([]blob.Chunk, error)
+	// WriteChunk verifies and persists the chunk read from data
+	WriteChunk(chunk blob.Chunk, data io.Reader) error
+	// CreatePackageFromChunks creates the package from the previously written chunks
+	CreatePackageFromChunks(loc loc.Locator, chunks []blob.Chunk, options ...PackageOption) (*PackageEnvelope, error)
+	// UpsertPackageFromChunks creates or updates the package from the previously written chunks
+	UpsertPackageFromChunks(loc loc.Locator, chunks []blob.Chunk, options ...PackageOption) (*PackageEnvelope, error)
+}
+
+// UploadRequest describes a package to create from a resumable upload
+type UploadRequest struct {
+	// Package is the locator of the package to create
+	Package loc.Locator `json:"package"`
+	// SizeBytes is the total size of the package data
+	SizeBytes int64 `json:"size_bytes"`
+	// Upsert defines whether an existing package should be replaced
+	Upsert bool `json:"upsert,omitempty"`
+	// Labels is the package runtime labels
+	Labels map[string]string `json:"labels,omitempty"`
+	// Hidden defines whether the package is hidden
+	Hidden bool `json:"hidden,omitempty"`
+	// Type is the package type
+	Type string `json:"type,omitempty"`
+	// Manifest is the package manifest
+	Manifest []byte `json:"manifest,omitempty"`
+	// Encrypted defines whether the package data is encrypted
+	Encrypted bool `json:"encrypted,omitempty"`
+	// CreatedBy is the package creator
+	CreatedBy string `json:"created_by,omitempty"`
+}
+
+// NewUploadRequest returns a request to upload the package of the specified
+// size with the given options
+func NewUploadRequest(loc loc.Locator, sizeBytes int64, upsert bool, options ...PackageOption) UploadRequest {
+	var pkg storage.Package
+	for _, option := range options {
+		option(&pkg)
+	}
+	return UploadRequest{
+		Package:   loc,
+		SizeBytes: sizeBytes,
+		Upsert:    upsert,
+		Labels:    pkg.RuntimeLabels,
+		Hidden:    pkg.Hidden,
+		Type:      pkg.Type,
+		Manifest:  pkg.Manifest,
+		Encrypted: pkg.Encrypted,
+		CreatedBy: pkg.CreatedBy,
+	}
+}
+
+// Check validates the request
+func (r UploadRequest) Check() error {
+	if _, err := loc.NewLocator(r.Package.Repository, r.Package.Name, r.Package.Version); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.SizeBytes < 0 {
+		return trace.BadParameter("invalid package size %v", r.SizeBytes)
+	}
+	return nil
+}
+
+// Options returns the package options specified in the request
+func (r UploadRequest) Options() []PackageOption {
+	options := []PackageOption{WithLabels(r.Labels), WithHidden(r.Hidden), WithEncrypted(r.Encrypted)}
+	if len(r.Manifest) != 0 {
+		options = append(options, WithManifest(r.Type, r.Manifest))
+	}
+	if r.CreatedBy != "" {
+		options = append(options, WithCreatedBy(r.CreatedBy))
+	}
+	return options
+}
+
+// UploadSession describes the state of a resumable package upload
+type UploadSession struct {
+	// ID uniquely identifies the upload
+	ID string `json:"id"`
+	// Request is the request the upload was started with
+	Request UploadRequest `json:"request"`
+	// Offset is the number of bytes received so far
+	Offset int64 `json:"offset"`
+	// Created is the time the upload was started
+	Created time.Time `json:"created"`
+}
+
+// Complete returns true if all package data has been received
+func (s UploadSession) Complete() bool {
+	return s.Offset == s.Request.SizeBytes
+}
+
+// UploadChunk describes a piece of data appended to an upload
+type UploadChunk struct {
+	// Offset is the position of the chunk within the package data
+	Offset int64 `json:"offset"`
+	// SHA256 is the hex-encoded SHA-256 checksum of the chunk
+	SHA256 string `json:"sha256"`
+}
+
+// UploadService is implemented by package services that accept packages
+// in resumable uploads: package data is sent as a sequence of checksummed
+// chunks so an interrupted upload can be continued from the last chunk
+// the service has received instead of starting over
+type UploadService interface {
+	// StartUpload starts a new package upload
+	StartUpload(UploadRequest) (*UploadSession, error)
+	// GetUpload returns the state of the specified upload
+	GetUpload(id string) (*UploadSession, error)
+	// AppendUpload verifies the chunk read from data and appends it to the upload.
+	// Returns trace.CompareFailed if the chunk offset does not match the upload offset
+	AppendUpload(id string, chunk UploadChunk, data io.Reader) (*UploadSession, error)
+	// CommitUpload creates the package from the completed upload
+	CommitUpload(id string) (*PackageEnvelope, error)
+	// AbortUpload cancels the upload and discards the received data
+	AbortUpload(id string) error
+}
+
 // PackageSorter is a package sort helper,
 // is used to return deterministic results by lexicographically sorting
 // packages
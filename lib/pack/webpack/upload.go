@@ -0,0 +1,151 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webpack
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gravitational/gravity/lib/pack"
+
+	"github.com/gravitational/roundtrip"
+	"github.com/gravitational/trace"
+	"github.com/julienschmidt/httprouter"
+)
+
+// PackageHandler is an HTTP handler that operates on the package service
+// bound to the authenticated user
+type PackageHandler func(http.ResponseWriter, *http.Request, httprouter.Params, pack.PackageService) error
+
+// StartUpload starts a resumable package upload
+//
+//	POST /pack/v1/uploads
+//
+//	{
+//	  "package": {"repository": "example.com", "name": "app", "version": "1.0.0"},
+//	  "size_bytes": 1024,
+//	  "upsert": false,
+//	  "labels": {"key": "value"}
+//	}
+//
+// Success response is the upload state:
+//
+//	{
+//	  "id": "<upload id>",
+//	  "request": {...},
+//	  "offset": 0,
+//	  "created": "<timestamp>"
+//	}
+func StartUpload(w http.ResponseWriter, r *http.Request, p httprouter.Params, service pack.PackageService) error {
+	var req pack.UploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return trace.BadParameter("%v", err)
+	}
+	uploads, err := uploadService(service)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	session, err := uploads.StartUpload(req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, session)
+	return nil
+}
+
+// GetUpload returns the state of the upload
+//
+//	GET /pack/v1/uploads/:upload_id
+func GetUpload(w http.ResponseWriter, r *http.Request, p httprouter.Params, service pack.PackageService) error {
+	uploads, err := uploadService(service)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	session, err := uploads.GetUpload(p.ByName("upload_id"))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, session)
+	return nil
+}
+
+// AppendUpload appends the chunk sent in the request body to the upload
+//
+//	POST /pack/v1/uploads/:upload_id/chunks?offset=<offset>&sha256=<checksum>
+//
+// Success response is the updated upload state
+func AppendUpload(w http.ResponseWriter, r *http.Request, p httprouter.Params, service pack.PackageService) error {
+	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil {
+		return trace.BadParameter("invalid offset: %v", err)
+	}
+	uploads, err := uploadService(service)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	session, err := uploads.AppendUpload(p.ByName("upload_id"), pack.UploadChunk{
+		Offset: offset,
+		SHA256: r.URL.Query().Get("sha256"),
+	}, r.Body)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, session)
+	return nil
+}
+
+// CommitUpload creates the package from the completed upload
+//
+//	POST /pack/v1/uploads/:upload_id/commit
+//
+// Success response is the envelope of the created package
+func CommitUpload(w http.ResponseWriter, r *http.Request, p httprouter.Params, service pack.PackageService) error {
+	uploads, err := uploadService(service)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	envelope, err := uploads.CommitUpload(p.ByName("upload_id"))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, envelope)
+	return nil
+}
+
+// AbortUpload cancels the upload
+//
+//	DELETE /pack/v1/uploads/:upload_id
+func AbortUpload(w http.ResponseWriter, r *http.Request, p httprouter.Params, service pack.PackageService) error {
+	uploads, err := uploadService(service)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := uploads.AbortUpload(p.ByName("upload_id")); err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, map[string]string{"status": "ok", "message": "upload aborted"})
+	return nil
+}
+
+func uploadService(service pack.PackageService) (pack.UploadService, error) {
+	uploads, ok := service.(pack.UploadService)
+	if !ok {
+		return nil, trace.NotImplemented("package service does not support resumable uploads")
+	}
+	return uploads, nil
+}
@@ -17,12 +17,15 @@ limitations under the License.
 package webpack
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/gravitational/gravity/lib/loc"
@@ -239,6 +242,115 @@ func (c *Client) ReadPackageEnvelope(loc loc.Locator) (*pack.PackageEnvelope, er
 	return envelope, nil
 }
 
+// GetPackageChunks returns the list of chunks the package consists of
+func (c *Client) GetPackageChunks(loc loc.Locator) (*pack.PackageChunks, error) {
+	out, err := c.Get(
+		c.Endpoint("repositories", loc.Repository,
+			"packages", loc.Name, loc.Version, "chunks"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var chunks pack.PackageChunks
+	if err := json.Unmarshal(out.Bytes(), &chunks); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &chunks, nil
+}
+
+// ReadPackageChunks returns a stream with contents of the requested
+// package chunks concatenated in the order they were requested
+func (c *Client) ReadPackageChunks(loc loc.Locator, hashes []string) (io.ReadCloser, error) {
+	data, err := json.Marshal(chunksRequest{Hashes: hashes})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	endpoint := c.Endpoint("repositories", loc.Repository, "packages", loc.Name, loc.Version, "chunks")
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.SetAuthHeader(req.Header)
+	// the response body is streamed, so the request is not
+	// issued with RoundTrip which buffers the response
+	resp, err := c.HTTPClient().Do(req)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, trace.ReadError(resp.StatusCode, body)
+	}
+	return resp.Body, nil
+}
+
+// StartUpload starts a new package upload
+func (c *Client) StartUpload(req pack.UploadRequest) (*pack.UploadSession, error) {
+	out, err := telehttplib.ConvertResponse(c.PostJSON(context.TODO(), c.Endpoint("uploads"), req))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return unmarshalUploadSession(out)
+}
+
+// GetUpload returns the state of the specified upload
+func (c *Client) GetUpload(id string) (*pack.UploadSession, error) {
+	out, err := c.Get(c.Endpoint("uploads", id), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return unmarshalUploadSession(out)
+}
+
+// AppendUpload sends the chunk read from data to the upload
+func (c *Client) AppendUpload(id string, chunk pack.UploadChunk, data io.Reader) (*pack.UploadSession, error) {
+	endpoint := c.Endpoint("uploads", id, "chunks") + "?" + url.Values{
+		"offset": []string{strconv.FormatInt(chunk.Offset, 10)},
+		"sha256": []string{chunk.SHA256},
+	}.Encode()
+	out, err := telehttplib.ConvertResponse(c.RoundTrip(func() (*http.Response, error) {
+		req, err := http.NewRequest("POST", endpoint, data)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		req.Header.Set("Content-Type", "application/octet-stream")
+		c.SetAuthHeader(req.Header)
+		return c.HTTPClient().Do(req)
+	}))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return unmarshalUploadSession(out)
+}
+
+// CommitUpload creates the package from the completed upload
+func (c *Client) CommitUpload(id string) (*pack.PackageEnvelope, error) {
+	out, err := telehttplib.ConvertResponse(c.PostJSON(context.TODO(), c.Endpoint("uploads", id, "commit"), map[string]string{}))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var envelope pack.PackageEnvelope
+	if err := json.Unmarshal(out.Bytes(), &envelope); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &envelope, nil
+}
+
+// AbortUpload cancels the upload and discards the received data
+func (c *Client) AbortUpload(id string) error {
+	_, err := c.Delete(c.Endpoint("uploads", id))
+	return trace.Wrap(err)
+}
+
+func unmarshalUploadSession(out *roundtrip.Response) (*pack.UploadSession, error) {
+	var session pack.UploadSession
+	if err := json.Unmarshal(out.Bytes(), &session); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &session, nil
+}
+
 // PostForm is a generic method that issues http POST request to the server
 func (c *Client) PostForm(
 	endpoint string,
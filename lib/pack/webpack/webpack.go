@@ -95,9 +95,18 @@ func NewHandler(cfg Config) (*Server, error) {
 	h.GET("/pack/v1/repositories/:repository/packages/:package_name/:package_version/file", h.needsAuth(h.getPackageFile))
 	h.HEAD("/pack/v1/repositories/:repository/packages/:package_name/:package_version/file", h.needsAuth(h.getPackageFile))
 	h.GET("/pack/v1/repositories/:repository/packages/:package_name/:package_version/envelope", h.needsAuth(h.getPackageEnvelope))
+	h.GET("/pack/v1/repositories/:repository/packages/:package_name/:package_version/chunks", h.needsAuth(h.getPackageChunks))
+	h.POST("/pack/v1/repositories/:repository/packages/:package_name/:package_version/chunks", h.needsAuth(h.readPackageChunks))
 	h.POST("/pack/v1/repositories/:repository/packages/:package_name/:package_version", h.needsAuth(h.updatePackageLabels))
 	h.DELETE("/pack/v1/repositories/:repository/packages/:package_name/:package_version", h.needsAuth(h.deletePackage))
 
+	// resumable uploads
+	h.POST("/pack/v1/uploads", h.needsAuth(StartUpload))
+	h.GET("/pack/v1/uploads/:upload_id", h.needsAuth(GetUpload))
+	h.POST("/pack/v1/uploads/:upload_id/chunks", h.needsAuth(AppendUpload))
+	h.POST("/pack/v1/uploads/:upload_id/commit", h.needsAuth(CommitUpload))
+	h.DELETE("/pack/v1/uploads/:upload_id", h.needsAuth(AbortUpload))
+
 	return h, nil
 }
 
@@ -214,6 +223,52 @@ func (s *Server) getPackageFile(w http.ResponseWriter, r *http.Request, p httpro
 	return nil
 }
 
+func (s *Server) getPackageChunks(w http.ResponseWriter, r *http.Request, p httprouter.Params, service pack.PackageService) error {
+	loc, err := loc.NewLocator(p.ByName("repository"), p.ByName("package_name"), p.ByName("package_version"))
+	if err != nil {
+		return trace.BadParameter(err.Error())
+	}
+	chunked, ok := service.(pack.ChunkedPackageService)
+	if !ok {
+		return trace.NotImplemented("package service does not support chunked transfer")
+	}
+	chunks, err := chunked.GetPackageChunks(*loc)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, chunks)
+	return nil
+}
+
+// readPackageChunks streams contents of the requested package chunks
+func (s *Server) readPackageChunks(w http.ResponseWriter, r *http.Request, p httprouter.Params, service pack.PackageService) error {
+	loc, err := loc.NewLocator(p.ByName("repository"), p.ByName("package_name"), p.ByName("package_version"))
+	if err != nil {
+		return trace.BadParameter(err.Error())
+	}
+	var req chunksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return trace.BadParameter(err.Error())
+	}
+	chunked, ok := service.(pack.ChunkedPackageService)
+	if !ok {
+		return trace.NotImplemented("package service does not support chunked transfer")
+	}
+	reader, err := chunked.ReadPackageChunks(*loc, req.Hashes)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, reader); err != nil {
+		// the response has already been started, so the client will
+		// detect the incomplete stream and resume from the last chunk
+		log.WithError(err).Warnf("Failed to stream chunks of %v.", loc)
+	}
+	return nil
+}
+
 func (s *Server) createPackage(w http.ResponseWriter, r *http.Request, p httprouter.Params, service pack.PackageService) error {
 	var files form.Files
 	var labelsMap string
@@ -313,7 +368,7 @@ func (s *Server) updatePackageLabels(w http.ResponseWriter, r *http.Request, p h
 	return nil
 }
 
-func (s *Server) needsAuth(fn authHandle) httprouter.Handle {
+func (s *Server) needsAuth(fn PackageHandler) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		logger := log.WithFields(fields.FromRequest(r))
 
@@ -339,15 +394,18 @@ func (s *Server) needsAuth(fn authHandle) httprouter.Handle {
 	}
 }
 
-type authHandle func(
-	http.ResponseWriter, *http.Request, httprouter.Params, pack.PackageService) error
-
 type authContext struct {
 	UserName  string
 	AccountID string
 	SiteID    string
 }
 
+// chunksRequest lists package chunks to stream
+type chunksRequest struct {
+	// Hashes lists hashes of the requested chunks
+	Hashes []string `json:"hashes"`
+}
+
 type labels struct {
 	AddLabels    map[string]string `json:"add_labels"`
 	RemoveLabels []string          `json:"remove_labels"`
@@ -36,9 +36,11 @@ import (
 	apphandler "github.com/gravitational/gravity/lib/app/handler"
 	appservice "github.com/gravitational/gravity/lib/app/service"
 	"github.com/gravitational/gravity/lib/autoscale/aws"
+	"github.com/gravitational/gravity/lib/backup"
 	"github.com/gravitational/gravity/lib/blob"
 	blobclient "github.com/gravitational/gravity/lib/blob/client"
 	blobcluster "github.com/gravitational/gravity/lib/blob/cluster"
+	blobdedup "github.com/gravitational/gravity/lib/blob/dedup"
 	blobfs "github.com/gravitational/gravity/lib/blob/fs"
 	blobhandler "github.com/gravitational/gravity/lib/blob/handler"
 	"github.com/gravitational/gravity/lib/clients"
@@ -84,6 +86,7 @@ import (
 	teleweb "github.com/gravitational/teleport/lib/web"
 
 	"github.com/cloudflare/cfssl/csr"
+	"github.com/dustin/go-humanize"
 	"github.com/gravitational/license/authority"
 	"github.com/gravitational/roundtrip"
 	"github.com/gravitational/teleport"
@@ -215,7 +218,7 @@ func New(ctx context.Context, cfg processconfig.Config, tcfg telecfg.FileConfig)
 		return nil, trace.Wrap(err)
 	}
 
-	objects, err := blobfs.New(filepath.Join(cfg.DataDir, defaults.PackagesDir))
+	objects, err := blobdedup.New(blobdedup.Config{Path: filepath.Join(cfg.DataDir, defaults.PackagesDir)})
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -621,6 +624,58 @@ func (p *Process) runRegistrySynchronizer(ctx context.Context) {
 	}
 }
 
+// migrateLocalBLOBs converts legacy whole-file BLOBs in the local
+// package storage into deduplicated chunks
+func (p *Process) migrateLocalBLOBs(ctx context.Context) {
+	objects, ok := p.localObjects.(*blobdedup.Objects)
+	if !ok {
+		return
+	}
+	result, err := objects.Migrate()
+	if err != nil {
+		p.Errorf("Failed to migrate local BLOBs: %v.", trace.DebugReport(err))
+		return
+	}
+	if len(result.Migrated) == 0 {
+		return
+	}
+	stats, err := objects.GetDedupStats()
+	if err != nil {
+		p.Warnf("Failed to query BLOB storage stats: %v.", trace.DebugReport(err))
+		return
+	}
+	p.Infof("Migrated %v local BLOBs (%v), storage now takes %v with deduplication ratio %.2f.",
+		len(result.Migrated), humanize.Bytes(uint64(result.BytesBefore)),
+		humanize.Bytes(uint64(stats.PhysicalBytes)), stats.Ratio())
+}
+
+// sweepOrphanChunks periodically removes chunks not referenced by any BLOB
+// from the local package storage
+func (p *Process) sweepOrphanChunks(ctx context.Context) {
+	objects, ok := p.localObjects.(*blobdedup.Objects)
+	if !ok {
+		return
+	}
+	ticker := time.NewTicker(defaults.DedupOrphanChunkSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			result, err := objects.SweepOrphanChunks(defaults.DedupOrphanChunkTTL)
+			if err != nil {
+				p.Warnf("Failed to sweep orphan chunks: %v.", trace.DebugReport(err))
+				continue
+			}
+			if result.Chunks != 0 {
+				p.Infof("Removed %v orphan chunks (%v).", result.Chunks,
+					humanize.Bytes(uint64(result.BytesFreed)))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // runSiteStatusChecker periodically invokes app status hook; should be run in a goroutine
 func (p *Process) runSiteStatusChecker(ctx context.Context) {
 	p.Info("Starting cluster status checker.")
@@ -646,12 +701,29 @@ func (p *Process) runSiteStatusChecker(ctx context.Context) {
 	}
 }
 
+// runBackupController runs a service that backs up the cluster
+// according to the cluster backup policies
+func (p *Process) runBackupController(ctx context.Context) {
+	controller, err := backup.NewController(backup.Config{
+		Backend:     p.backend,
+		Apps:        p.applications,
+		Etcd:        p.cfg.ETCD,
+		NodeAddr:    os.Getenv(constants.EnvPodIP),
+		FieldLogger: p.WithField(trace.Component, "backup"),
+	})
+	if err != nil {
+		p.WithError(err).Warn("Failed to create backup controller.")
+		return
+	}
+	controller.Run(ctx)
+}
+
 // startElection starts leader election process and watches the changes
 func (p *Process) startElection() error {
 	// elect gravity site leader - all other sites will remain
 	// functional, but will not report OK to readiness probes,
 	// making sure that k8s will direct traffic to current leader
-	gravityLeaderKey := p.cfg.ETCD.Key + "/leader"
+	gravityLeaderKey := p.cfg.BackendKey() + "/leader"
 	err := p.leader.AddVoter(p.context, gravityLeaderKey, p.id, defaults.ElectionTerm)
 	if err != nil {
 		return trace.Wrap(err)
@@ -1317,9 +1389,19 @@ func (p *Process) initService(ctx context.Context) (err error) {
 		return trace.Wrap(err)
 	}
 
+	// convert packages written before the deduplicating storage
+	// was introduced, every process manages its own local storage
+	p.startService(p.migrateLocalBLOBs)
+
+	// remove chunks left behind by interrupted chunked transfers
+	p.startService(p.sweepOrphanChunks)
+
 	// site status checker executes status hook periodically
 	p.RegisterClusterService(p.runSiteStatusChecker)
 
+	// backup controller backs up the cluster according to backup policies
+	p.RegisterClusterService(p.runBackupController)
+
 	// a few services that are running only when gravity is started in
 	// local site mode
 	if p.inKubernetes() {
@@ -141,6 +141,9 @@ type Config struct {
 	// ETCD provides etcd config options
 	ETCD keyval.ETCDConfig `yaml:"etcd"`
 
+	// Postgres provides PostgreSQL config options
+	Postgres keyval.PostgresConfig `yaml:"postgres"`
+
 	// OpsCenter provides settings for OpsCenter
 	OpsCenter OpsCenterConfig `yaml:"ops"`
 
@@ -210,6 +213,10 @@ func (cfg *Config) CheckAndSetDefaults() error {
 			log.Errorf("error reading config: %#v", cfg.ETCD)
 			return trace.Wrap(err)
 		}
+	case constants.PostgresBackend:
+		if err := cfg.Postgres.CheckAndSetDefaults(); err != nil {
+			return trace.Wrap(err)
+		}
 	default:
 		return trace.BadParameter("unsupported backend type: %v", cfg.BackendType)
 	}
@@ -236,10 +243,21 @@ func (cfg Config) CreateBackend() (backend storage.Backend, err error) {
 	case constants.ETCDBackend:
 		log.Debug("using ETCD backend")
 		backend, err = keyval.NewETCD(cfg.ETCD)
+	case constants.PostgresBackend:
+		log.Debug("using PostgreSQL backend")
+		backend, err = keyval.NewPostgres(cfg.Postgres)
 	}
 	return backend, trace.Wrap(err)
 }
 
+// BackendKey returns the root key of the configured storage backend
+func (cfg Config) BackendKey() string {
+	if cfg.BackendType == constants.PostgresBackend {
+		return cfg.Postgres.Key
+	}
+	return cfg.ETCD.Key
+}
+
 func (cfg Config) ProcessID() string {
 	id := os.Getenv(constants.EnvPodIP)
 	if id == "" {
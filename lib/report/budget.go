@@ -0,0 +1,317 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// CollectWithBudget runs the collectors writing their output with reportWriter.
+// maxSize is the total size budget of the collected data. The budget is shared
+// between collectors: each collector gets an equal share of what is left by the
+// preceding collectors, and the output exceeding the share is dropped.
+// The budget is not limited if maxSize is 0.
+// Returns the names of the files that have been truncated
+func CollectWithBudget(ctx context.Context, collectors Collectors, reportWriter FileWriter, runner utils.CommandRunner, maxSize int64) (truncated []string, err error) {
+	var errors []error
+	remaining := maxSize
+	for i, collector := range collectors {
+		var limit int64
+		if maxSize != 0 {
+			limit = remaining / int64(len(collectors)-i)
+		}
+		w := NewBudgetFileWriter(reportWriter, limit)
+		if err := collector.Collect(ctx, w, runner); err != nil {
+			errors = append(errors, err)
+		}
+		remaining -= w.written
+		truncated = append(truncated, w.truncated...)
+	}
+	return truncated, trace.NewAggregate(errors...)
+}
+
+// NewBudgetFileWriter returns a new writer that limits the total size
+// of the files written with reportWriter to limit bytes.
+// The size is not limited if limit is 0
+func NewBudgetFileWriter(reportWriter FileWriter, limit int64) *BudgetFileWriter {
+	return &BudgetFileWriter{FileWriter: reportWriter, limit: limit}
+}
+
+// NewWriter returns a writer for the file with the specified name
+// that shares the budget of the collector.
+// Gzip-compressed files are truncated on tarball entry or line boundaries
+// and are recompressed so they remain valid.
+// Implements FileWriter
+func (r *BudgetFileWriter) NewWriter(name string) (io.WriteCloser, error) {
+	w, err := r.FileWriter.NewWriter(name)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	writer := &budgetWriter{WriteCloser: w, parent: r, name: name}
+	if r.limit == 0 || !isCompressed(name) {
+		return writer, nil
+	}
+	return newCompressedBudgetWriter(writer, isTarball(name)), nil
+}
+
+// Truncated returns the names of the files that exceeded the budget
+func (r *BudgetFileWriter) Truncated() []string {
+	return r.truncated
+}
+
+// BudgetFileWriter limits the total size of the files written by a collector
+type BudgetFileWriter struct {
+	FileWriter
+	// limit is the collector budget, 0 means unlimited
+	limit int64
+	// written is the number of bytes written by the collector
+	written int64
+	// truncated lists the files that exceeded the budget
+	truncated []string
+}
+
+// Write forwards the data to the underlying writer within the budget
+// and discards the rest so the collector command can run to completion.
+// Implements io.Writer
+func (r *budgetWriter) Write(p []byte) (int, error) {
+	if r.parent.limit == 0 {
+		n, err := r.WriteCloser.Write(p)
+		r.parent.written += int64(n)
+		return n, err
+	}
+	remaining := r.remaining()
+	data := p
+	if int64(len(data)) > remaining {
+		data = data[:remaining]
+		r.markTruncated()
+	}
+	if len(data) != 0 {
+		n, err := r.WriteCloser.Write(data)
+		r.parent.written += int64(n)
+		if err != nil {
+			return n, err
+		}
+	}
+	return len(p), nil
+}
+
+// remaining returns the number of bytes left in the budget
+func (r *budgetWriter) remaining() int64 {
+	remaining := r.parent.limit - r.parent.written
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+func (r *budgetWriter) markTruncated() {
+	if r.truncated {
+		return
+	}
+	r.truncated = true
+	r.parent.truncated = append(r.parent.truncated, r.name)
+}
+
+type budgetWriter struct {
+	io.WriteCloser
+	parent    *BudgetFileWriter
+	name      string
+	truncated bool
+}
+
+// newCompressedBudgetWriter returns a writer that decompresses the data
+// written to it and recompresses it into w within the budget
+func newCompressedBudgetWriter(w *budgetWriter, tarball bool) *compressedBudgetWriter {
+	reader, writer := io.Pipe()
+	r := &compressedBudgetWriter{
+		PipeWriter: writer,
+		w:          w,
+		doneC:      make(chan error, 1),
+	}
+	go func() {
+		var err error
+		if tarball {
+			err = recompressTarball(w, reader)
+		} else {
+			err = recompressLines(w, reader)
+		}
+		// drain the rest of the input so the collector can run to completion
+		io.Copy(ioutil.Discard, reader)
+		reader.Close()
+		r.doneC <- err
+	}()
+	return r
+}
+
+// Close waits for the compressed data to be written and closes the file.
+// Implements io.Closer
+func (r *compressedBudgetWriter) Close() error {
+	r.PipeWriter.Close()
+	err := <-r.doneC
+	return trace.NewAggregate(err, r.w.Close())
+}
+
+type compressedBudgetWriter struct {
+	*io.PipeWriter
+	w     *budgetWriter
+	doneC chan error
+}
+
+// recompressLines copies the gzip-compressed data from src to w
+// truncating it on a line boundary when the budget is exhausted
+func recompressLines(w *budgetWriter, src io.Reader) error {
+	gzReader, err := gzip.NewReader(src)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer gzReader.Close()
+	gzWriter := gzip.NewWriter(w)
+	buf := make([]byte, compressedChunkSize)
+	for {
+		n, err := io.ReadFull(gzReader, buf)
+		if n != 0 {
+			chunk := buf[:n]
+			available := w.remaining() - compressedTrailerSize - (compressedSize(int64(n)) - int64(n))
+			if int64(n) > available {
+				w.markTruncated()
+				chunk = truncateLines(chunk, available)
+			}
+			if len(chunk) != 0 {
+				if _, err := gzWriter.Write(chunk); err != nil {
+					return trace.Wrap(err)
+				}
+				if err := gzWriter.Flush(); err != nil {
+					return trace.Wrap(err)
+				}
+			}
+			if w.truncated {
+				break
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return trace.Wrap(gzWriter.Close())
+}
+
+// recompressTarball copies the gzip-compressed tarball from src to w
+// omitting the entries that do not fit into the budget
+func recompressTarball(w *budgetWriter, src io.Reader) error {
+	gzReader, err := gzip.NewReader(src)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer gzReader.Close()
+	gzWriter := gzip.NewWriter(w)
+	tarReader := tar.NewReader(gzReader)
+	tarWriter := tar.NewWriter(gzWriter)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		// account for the entry header, extended headers and padding
+		size := 3*tarBlockSize + header.Size + tarBlockSize - header.Size%tarBlockSize
+		if compressedSize(size)+compressedTrailerSize > w.remaining() {
+			w.markTruncated()
+			continue
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return trace.Wrap(err)
+		}
+		if _, err := io.Copy(tarWriter, tarReader); err != nil {
+			return trace.Wrap(err)
+		}
+		if err := tarWriter.Flush(); err != nil {
+			return trace.Wrap(err)
+		}
+		if err := gzWriter.Flush(); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(gzWriter.Close())
+}
+
+// truncateLines returns the longest prefix of data not exceeding size bytes
+// that ends on a line boundary
+func truncateLines(data []byte, size int64) []byte {
+	if size <= 0 {
+		return nil
+	}
+	if int64(len(data)) > size {
+		data = data[:size]
+	}
+	return data[:bytes.LastIndexByte(data, '\n')+1]
+}
+
+// compressedSize returns the upper bound of the size of size bytes of data
+// after compression and flush
+func compressedSize(size int64) int64 {
+	// deflate stores incompressible data in blocks of up to 64K
+	// with a 5-byte header, a flush adds an empty stored block
+	return size + 5*(size/(1<<16)+1) + 5
+}
+
+// isCompressed returns true if the file with the specified name
+// contains gzip-compressed data
+func isCompressed(name string) bool {
+	return strings.HasSuffix(name, ".gz") || strings.HasSuffix(name, ".tgz")
+}
+
+// isTarball returns true if the file with the specified name
+// contains a gzip-compressed tarball
+func isTarball(name string) bool {
+	return strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
+
+const (
+	// compressedChunkSize is the size of the chunks of the compressed files
+	// accounted for the budget
+	compressedChunkSize = 32 * 1024
+	// tarBlockSize is the size of the tarball block
+	tarBlockSize = 512
+	// compressedTrailerSize is the upper bound of the size of the data
+	// written when closing a compressed file: the end of archive marker
+	// of a tarball, the final deflate block and the gzip header and trailer
+	compressedTrailerSize = 2*tarBlockSize + 10 + 18
+)
@@ -20,18 +20,27 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/utils"
 	"github.com/gravitational/gravity/lib/utils/kubectl"
 
+	"github.com/gravitational/trace"
 	log "github.com/sirupsen/logrus"
 )
 
 // NewKubernetesCollector returns a list of collectors to fetch kubernetes-specific
 // diagnostics.
-func NewKubernetesCollector(ctx context.Context, runner utils.CommandRunner) Collectors {
+// namespaces optionally limits the diagnostics to the specified namespaces.
+// timeRange optionally limits the collected container logs to the specified period
+func NewKubernetesCollector(ctx context.Context, runner utils.CommandRunner, namespaces []string, timeRange TimeRange) Collectors {
 	runner = planetContextRunner{runner}
+	clusterInfoArgs := []string{"cluster-info", "dump", "--all-namespaces"}
+	if len(namespaces) != 0 {
+		clusterInfoArgs = []string{"cluster-info", "dump", "--namespaces", strings.Join(namespaces, ",")}
+	}
 	// general kubernetes info
 	commands := Collectors{
 		Cmd("k8s-nodes", utils.PlanetCommand(kubectl.Command("get", "nodes", "-o", "yaml"))...),
@@ -43,14 +52,18 @@ func NewKubernetesCollector(ctx context.Context, runner utils.CommandRunner) Col
 		Cmd("k8s-events", utils.PlanetCommand(kubectl.Command(
 			"get", "events", "--all-namespaces"))...),
 		Cmd("k8s-cluster-info-dump", utils.PlanetCommand(kubectl.Command(
-			"cluster-info", "dump", "--all-namespaces"))...),
+			clusterInfoArgs...))...),
 	}
 
-	namespaces, err := kubectl.GetNamespaces(ctx, runner)
-	if err != nil || len(namespaces) == 0 {
-		namespaces = defaults.UsedNamespaces
+	if len(namespaces) == 0 {
+		var err error
+		namespaces, err = kubectl.GetNamespaces(ctx, runner)
+		if err != nil || len(namespaces) == 0 {
+			namespaces = defaults.UsedNamespaces
+		}
 	}
 
+	logArgs := timeRange.KubectlLogsArgs()
 	for _, namespace := range namespaces {
 		for _, resourceType := range defaults.KubernetesReportResourceTypes {
 			name := fmt.Sprintf("k8s-%s-%s", namespace, resourceType)
@@ -76,15 +89,17 @@ func NewKubernetesCollector(ctx context.Context, runner utils.CommandRunner) Col
 			}
 			for _, container := range containers {
 				name := fmt.Sprintf("k8s-logs-%v-%v-%v", namespace, pod, container)
-				commands = append(commands, Cmd(name, utils.PlanetCommand(kubectl.Command("logs", pod,
-					"--namespace", namespace,
-					fmt.Sprintf("-c=%v", container)))...))
+				commands = append(commands, containerLogs(name, timeRange.Until,
+					utils.PlanetCommand(kubectl.Command(append([]string{"logs", pod,
+						"--namespace", namespace,
+						fmt.Sprintf("-c=%v", container)}, logArgs...)...))...))
 				// Also collect logs for the previous instance
 				// of the container if there's any.
 				name = fmt.Sprintf("%v-prev", name)
-				commands = append(commands, Cmd(name, utils.PlanetCommand(kubectl.Command("logs", pod,
-					"--namespace", namespace, "-p",
-					fmt.Sprintf("-c=%v", container)))...))
+				commands = append(commands, containerLogs(name, timeRange.Until,
+					utils.PlanetCommand(kubectl.Command(append([]string{"logs", pod,
+						"--namespace", namespace, "-p",
+						fmt.Sprintf("-c=%v", container)}, logArgs...)...))...))
 			}
 		}
 	}
@@ -92,6 +107,27 @@ func NewKubernetesCollector(ctx context.Context, runner utils.CommandRunner) Col
 	return commands
 }
 
+// containerLogs returns a collector for container logs.
+// If until is set, the log lines after until are dropped
+func containerLogs(name string, until time.Time, args ...string) Collector {
+	return CollectorFunc(func(ctx context.Context, reportWriter FileWriter, runner utils.CommandRunner) error {
+		w, err := reportWriter.NewWriter(name)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		defer w.Close()
+		if until.IsZero() {
+			return runner.RunStream(ctx, w, args...)
+		}
+		filter := NewUntilWriter(w, until)
+		err = runner.RunStream(ctx, filter, args...)
+		if errClose := filter.Close(); err == nil {
+			err = errClose
+		}
+		return trace.Wrap(err)
+	})
+}
+
 // RunStream executes the command specified with args in the context of the planet container
 // Implements utils.CommandRunner
 func (r planetContextRunner) RunStream(ctx context.Context, w io.Writer, args ...string) error {
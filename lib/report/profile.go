@@ -0,0 +1,141 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// Profile defines which diagnostics are collected into the cluster report
+type Profile struct {
+	// Name is the profile name
+	Name string
+	// Filters lists the collectors to run
+	Filters []string
+	// Period limits the collected logs to the specified period before now
+	// unless TimeRange specifies the start of the period explicitly.
+	// All logs are collected if unspecified
+	Period time.Duration
+	// TimeRange limits the collected logs to the specified period
+	TimeRange TimeRange
+	// MaxSize limits the total size of the report in bytes.
+	// The size is not limited if unspecified
+	MaxSize int64
+	// Namespaces limits Kubernetes diagnostics to the specified namespaces.
+	// All namespaces are collected if unspecified
+	Namespaces []string
+	// Nodes limits node diagnostics to the nodes with the specified
+	// hostnames or addresses. All nodes are collected if unspecified
+	Nodes []string
+	// RedactIPs specifies whether IP addresses are scrubbed from the report
+	RedactIPs bool
+}
+
+// Check validates this profile
+func (r Profile) Check() error {
+	for _, filter := range r.Filters {
+		if !utils.StringInSlice(AllFilters, filter) {
+			return trace.BadParameter("unknown collector %q, supported collectors are %v",
+				filter, AllFilters)
+		}
+	}
+	if r.Period < 0 {
+		return trace.BadParameter("log period can't be negative")
+	}
+	if r.MaxSize < 0 {
+		return trace.BadParameter("report size limit can't be negative")
+	}
+	return trace.Wrap(r.TimeRange.Check())
+}
+
+// LogRange returns the period to collect the logs for relative to now
+func (r Profile) LogRange(now time.Time) TimeRange {
+	timeRange := r.TimeRange
+	if timeRange.Since.IsZero() && r.Period != 0 {
+		timeRange.Since = now.Add(-r.Period)
+	}
+	return timeRange
+}
+
+// HasFilter returns true if the profile includes the specified collector
+func (r Profile) HasFilter(filter string) bool {
+	return utils.StringInSlice(r.Filters, filter)
+}
+
+// HasNode returns true if the node with the specified hostname or
+// address is included in the profile
+func (r Profile) HasNode(hostname, addr string) bool {
+	if len(r.Nodes) == 0 {
+		return true
+	}
+	return utils.StringInSlice(r.Nodes, hostname) || utils.StringInSlice(r.Nodes, addr)
+}
+
+// GetProfile returns a copy of the built-in profile with the specified name.
+// The full profile is returned if the name is empty
+func GetProfile(name string) (*Profile, error) {
+	if name == "" {
+		name = ProfileFull
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		return nil, trace.NotFound("unknown report profile %q, supported profiles are %v",
+			name, ProfileNames())
+	}
+	profile.Filters = append([]string(nil), profile.Filters...)
+	return &profile, nil
+}
+
+// ProfileNames returns the names of the built-in profiles
+func ProfileNames() (names []string) {
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var profiles = map[string]Profile{
+	ProfileFull: {
+		Name:    ProfileFull,
+		Filters: DefaultFilters,
+	},
+	ProfileMinimal: {
+		Name:    ProfileMinimal,
+		Filters: []string{FilterSystem, FilterKubernetes, FilterCluster, FilterOperations},
+		Period:  24 * time.Hour,
+	},
+	ProfileKubernetes: {
+		Name:    ProfileKubernetes,
+		Filters: []string{FilterKubernetes, FilterCluster, FilterApplication},
+	},
+}
+
+const (
+	// ProfileFull collects all available diagnostics
+	ProfileFull = "full"
+	// ProfileMinimal collects node and Kubernetes diagnostics
+	// with logs for the last day
+	ProfileMinimal = "minimal"
+	// ProfileKubernetes collects Kubernetes and application diagnostics only
+	ProfileKubernetes = "kubernetes"
+)
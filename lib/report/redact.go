@@ -0,0 +1,331 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// RedactConfig defines what is scrubbed from the collected diagnostics.
+// Certificates, private keys, tokens and passwords are always scrubbed
+type RedactConfig struct {
+	// IPs specifies whether IP addresses are scrubbed as well
+	IPs bool
+}
+
+// NewRedactor returns a new redactor with the specified configuration
+func NewRedactor(config RedactConfig) *Redactor {
+	rules := append([]redactRule{}, secretRules...)
+	if config.IPs {
+		rules = append(rules, ipRules...)
+	}
+	return &Redactor{rules: rules}
+}
+
+// Redactor scrubs sensitive information from the collected diagnostics
+type Redactor struct {
+	rules []redactRule
+}
+
+// RedactDir scrubs all files in dir in-place.
+// Files that cannot be scrubbed, for example binary files, are removed
+// so that no unscrubbed data ends up in the report.
+// Returns the paths of the removed files relative to dir
+func (r *Redactor) RedactDir(dir string) (removed []string, err error) {
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if err := r.RedactFile(path); err != nil {
+			log.WithError(err).Warnf("Failed to redact %v, removing.", path)
+			if err := os.Remove(path); err != nil {
+				return trace.ConvertSystemError(err)
+			}
+			relpath, _ := filepath.Rel(dir, path)
+			removed = append(removed, relpath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return removed, nil
+}
+
+// RedactFile scrubs the file at path in-place.
+// Compressed files and tarballs are scrubbed recursively
+func (r *Redactor) RedactFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	dst, err := ioutil.TempFile(filepath.Dir(path), ".redact")
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer func() {
+		dst.Close()
+		os.Remove(dst.Name())
+	}()
+	if err := r.Redact(dst, src); err != nil {
+		return trace.Wrap(err, "failed to redact %v", path)
+	}
+	if err := dst.Chmod(info.Mode()); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if err := dst.Close(); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return trace.ConvertSystemError(os.Rename(dst.Name(), path))
+}
+
+// Redact copies data from src to dst scrubbing sensitive information.
+// Gzip-compressed data and tarballs are scrubbed recursively.
+// Other binary data cannot be scrubbed and results in an error,
+// binary tarball entries are omitted
+func (r *Redactor) Redact(dst io.Writer, src io.Reader) error {
+	reader := bufio.NewReader(src)
+	header, err := reader.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return trace.Wrap(err)
+	}
+	switch {
+	case isGzip(header):
+		return trace.Wrap(r.redactGzip(dst, reader))
+	case isTar(header):
+		return trace.Wrap(r.redactTar(dst, reader))
+	case bytes.IndexByte(header, 0) != -1:
+		return trace.Wrap(errBinaryData)
+	default:
+		return trace.Wrap(r.redactText(dst, reader))
+	}
+}
+
+func (r *Redactor) redactGzip(dst io.Writer, src io.Reader) error {
+	gzReader, err := gzip.NewReader(src)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer gzReader.Close()
+	gzWriter := gzip.NewWriter(dst)
+	if err := r.Redact(gzWriter, gzReader); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(gzWriter.Close())
+}
+
+func (r *Redactor) redactTar(dst io.Writer, src io.Reader) error {
+	tarReader := tar.NewReader(src)
+	tarWriter := tar.NewWriter(dst)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return trace.Wrap(err)
+			}
+			continue
+		}
+		if err := r.redactTarEntry(tarWriter, header, tarReader); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return trace.Wrap(tarWriter.Close())
+}
+
+// redactTarEntry scrubs a single tarball entry.
+// The entry is buffered in a temporary file since the size of the
+// scrubbed data needs to be known before writing the entry header
+func (r *Redactor) redactTarEntry(tarWriter *tar.Writer, header *tar.Header, src io.Reader) error {
+	tmp, err := ioutil.TempFile("", "redact")
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+	if err := r.Redact(tmp, src); err != nil {
+		if trace.Unwrap(err) == errBinaryData {
+			// fail closed: binary entries are omitted
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	header.Size = size
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = io.Copy(tarWriter, tmp)
+	return trace.Wrap(err)
+}
+
+// redactText scrubs text data line by line.
+// Multi-line PEM blocks are replaced as a whole
+func (r *Redactor) redactText(dst io.Writer, src *bufio.Reader) error {
+	var inPEM bool
+	for {
+		line, err := src.ReadString('\n')
+		if len(line) != 0 {
+			var out string
+			out, inPEM = r.redactLine(line, inPEM)
+			if _, err := io.WriteString(dst, out); err != nil {
+				return trace.ConvertSystemError(err)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+}
+
+// redactLine scrubs a single line of text.
+// inPEM specifies whether the line is inside a multi-line PEM block.
+// Returns the scrubbed line and whether the next line is inside a PEM block
+func (r *Redactor) redactLine(line string, inPEM bool) (string, bool) {
+	if inPEM {
+		if pemEnd.MatchString(line) {
+			return line, false
+		}
+		return "", true
+	}
+	for _, rule := range r.rules {
+		line = rule.pattern.ReplaceAllString(line, rule.replacement)
+	}
+	if pemBegin.MatchString(line) && !pemEnd.MatchString(line) {
+		return line + redactedValue + "\n", true
+	}
+	return line, false
+}
+
+// redactRule replaces matches of the pattern with the replacement
+type redactRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+func isGzip(header []byte) bool {
+	return len(header) >= 2 && header[0] == 0x1f && header[1] == 0x8b
+}
+
+func isTar(header []byte) bool {
+	return len(header) >= tarMagicOffset+len(tarMagic) &&
+		string(header[tarMagicOffset:tarMagicOffset+len(tarMagic)]) == tarMagic
+}
+
+// secretRules lists rules to scrub certificates, keys, tokens and passwords
+var secretRules = []redactRule{
+	// PEM blocks on a single line, e.g. inside JSON strings
+	{
+		pattern:     regexp.MustCompile(`-----BEGIN ([A-Z0-9 ]+)-----.*?-----END [A-Z0-9 ]+-----`),
+		replacement: "-----BEGIN $1-----" + redactedValue + "-----END $1-----",
+	},
+	// base64-encoded PEM blocks, e.g. in kubeconfig or secrets
+	{
+		pattern:     regexp.MustCompile(`LS0tLS1CRUdJTi[A-Za-z0-9+/=]*`),
+		replacement: redactedValue,
+	},
+	// credentials in URLs
+	{
+		pattern:     regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+@`),
+		replacement: "${1}" + redactedValue + "@",
+	},
+	// bearer tokens
+	{
+		pattern:     regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+		replacement: "${1}" + redactedValue,
+	},
+	// JSON web tokens
+	{
+		pattern:     regexp.MustCompile(`eyJ[A-Za-z0-9_-]{4,}\.[A-Za-z0-9_-]{4,}\.[A-Za-z0-9_-]+`),
+		replacement: redactedValue,
+	},
+	// values of keys and flags that look like secrets
+	{
+		pattern: regexp.MustCompile(`(?i)([A-Za-z0-9_.-]*(?:password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|client[_-]?key|key[_-]?data|certificate[_-]?data)` +
+			`[A-Za-z0-9_-]*["']?\s*[:=]\s*["']?)([^\s"',}]+)`),
+		replacement: "${1}" + redactedValue,
+	},
+}
+
+// ipRules lists rules to scrub IP addresses
+var ipRules = []redactRule{
+	{
+		pattern:     regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`),
+		replacement: redactedIP,
+	},
+	{
+		pattern:     regexp.MustCompile(`\b(?:[0-9a-fA-F]{1,4}:){7}[0-9a-fA-F]{1,4}\b`),
+		replacement: redactedIP,
+	},
+}
+
+// errBinaryData is returned for binary data that cannot be scrubbed
+var errBinaryData = errors.New("binary data cannot be redacted")
+
+var (
+	pemBegin = regexp.MustCompile(`-----BEGIN [A-Z0-9 ]+-----`)
+	pemEnd   = regexp.MustCompile(`-----END [A-Z0-9 ]+-----`)
+)
+
+const (
+	// redactedValue replaces scrubbed secrets
+	redactedValue = "<redacted>"
+	// redactedIP replaces scrubbed IP addresses
+	redactedIP = "<redacted-ip>"
+	// sniffLen is the length of the header used to detect the data format
+	sniffLen = 512
+	// tarMagic is the magic of the tarball header
+	tarMagic = "ustar"
+	// tarMagicOffset is the offset of the magic in the tarball header
+	tarMagicOffset = 257
+)
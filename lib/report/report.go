@@ -22,8 +22,11 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/gravitational/gravity/lib/archive"
+	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/pack"
 	"github.com/gravitational/gravity/lib/utils"
 
@@ -42,11 +45,14 @@ func Collect(ctx context.Context, config Config, w io.Writer) error {
 	for _, filter := range teleutils.Deduplicate(config.Filters) {
 		switch filter {
 		case FilterSystem:
-			collectors = append(collectors, NewSystemCollector()...)
+			collectors = append(collectors, NewSystemCollector(config.TimeRange)...)
 			collectors = append(collectors, NewPackageCollector(config.Packages))
 		case FilterKubernetes:
-			collectors = append(collectors, NewKubernetesCollector(ctx, utils.Runner)...)
+			collectors = append(collectors, NewKubernetesCollector(ctx, utils.Runner,
+				config.Namespaces, config.TimeRange)...)
 		case FilterEtcd:
+			collectors = append(collectors, etcdLogs(config.TimeRange)...)
+		case FilterEtcdBackup:
 			collectors = append(collectors, etcdBackup()...)
 		}
 	}
@@ -58,10 +64,18 @@ func Collect(ctx context.Context, config Config, w io.Writer) error {
 	defer os.RemoveAll(dir)
 
 	rw := NewFileWriter(dir)
-	err = collectors.Collect(ctx, rw, utils.Runner)
+	truncated, err := CollectWithBudget(ctx, collectors, rw, utils.Runner, config.MaxSize)
 	if err != nil {
 		config.WithError(err).Warn("Failed to collect diagnostics.")
 	}
+	if len(truncated) != 0 {
+		config.WithField("files", truncated).Warn("Some diagnostics have been truncated to fit the report size limit.")
+		err = ioutil.WriteFile(filepath.Join(dir, TruncatedFilesIndex),
+			[]byte(strings.Join(truncated, "\n")+"\n"), defaults.SharedReadWriteMask)
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+	}
 
 	reader, writer := io.Pipe()
 	go func() {
@@ -82,7 +96,13 @@ func Collect(ctx context.Context, config Config, w io.Writer) error {
 
 func (r *Config) checkAndSetDefaults() error {
 	if len(r.Filters) == 0 {
-		r.Filters = AllFilters
+		r.Filters = DefaultFilters
+	}
+	if err := r.TimeRange.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.MaxSize < 0 {
+		return trace.BadParameter("report size limit can't be negative")
 	}
 	if r.FieldLogger == nil {
 		r.FieldLogger = log.WithField(trace.Component, "report-collector")
@@ -100,6 +120,13 @@ type Config struct {
 	// Packages specifies the package service for the package
 	// diagnostics collector
 	Packages pack.PackageService
+	// TimeRange limits the collected logs to the specified period
+	TimeRange TimeRange
+	// MaxSize limits the total size of the collected diagnostics in bytes.
+	// The size is not limited if unspecified
+	MaxSize int64
+	// Namespaces limits Kubernetes diagnostics to the specified namespaces
+	Namespaces []string
 }
 
 const (
@@ -109,9 +136,38 @@ const (
 	// FilterKubernetes defines a report collection filter to fetch kubernetes diagnostics
 	FilterKubernetes = "kubernetes"
 
-	// FilterEtcd defines a report collection filter to fetch etcd data
+	// FilterEtcd defines a report collection filter to fetch etcd logs
 	FilterEtcd = "etcd"
+
+	// FilterEtcdBackup defines a report collection filter to fetch the etcd
+	// data of gravity and planet. The data cannot be reliably scrubbed of
+	// secrets, so it is only collected if requested explicitly
+	FilterEtcdBackup = "etcd-backup"
+
+	// FilterCluster defines a report collection filter to fetch cluster
+	// information and the output of the application dump hook
+	FilterCluster = "cluster"
+
+	// FilterOperations defines a report collection filter to fetch cluster operation logs
+	FilterOperations = "operations"
+
+	// FilterApplication defines a report collection filter to run
+	// application-defined collectors
+	FilterApplication = "application"
 )
 
+// TruncatedFilesIndex names the report file that lists the files
+// truncated to fit the report size limit
+const TruncatedFilesIndex = "truncated-files"
+
+// RemovedFilesIndex names the report file that lists the files
+// removed from the report because they could not be scrubbed
+const RemovedFilesIndex = "removed-files"
+
 // AllFilters lists all available collector filters
-var AllFilters = []string{FilterSystem, FilterKubernetes, FilterEtcd}
+var AllFilters = []string{FilterSystem, FilterKubernetes, FilterEtcd, FilterEtcdBackup,
+	FilterCluster, FilterOperations, FilterApplication}
+
+// DefaultFilters lists the collector filters used unless specified explicitly
+var DefaultFilters = []string{FilterSystem, FilterKubernetes, FilterEtcd,
+	FilterCluster, FilterOperations, FilterApplication}
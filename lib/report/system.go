@@ -27,8 +27,9 @@ import (
 	"github.com/gravitational/trace"
 )
 
-// NewSystemCollector returns a list of collectors to fetch system information
-func NewSystemCollector() Collectors {
+// NewSystemCollector returns a list of collectors to fetch system information.
+// timeRange limits the collected journal to the specified period
+func NewSystemCollector(timeRange TimeRange) Collectors {
 	var collectors Collectors
 	add := func(additional ...Collector) {
 		collectors = append(collectors, additional...)
@@ -36,9 +37,9 @@ func NewSystemCollector() Collectors {
 
 	add(basicSystemInfo()...)
 	add(planetServices()...)
-	add(syslogExportLogs())
+	add(syslogExportLogs(timeRange))
 	add(systemFileLogs()...)
-	add(planetLogs()...)
+	add(planetLogs(timeRange)...)
 
 	return collectors
 }
@@ -98,11 +99,15 @@ func planetServices() Collectors {
 }
 
 // syslogExportLogs fetches host journal logs
-func syslogExportLogs() Collector {
-	const script = `
+func syslogExportLogs(timeRange TimeRange) Collector {
+	const template = `
 #!/bin/bash
-/bin/journalctl --no-pager --output=export | /bin/gzip -f`
-	return Script("gravity-system.log.gz", script)
+/bin/journalctl --no-pager --output=export %v| /bin/gzip -f`
+	var args string
+	for _, arg := range timeRange.JournalArgs() {
+		args += arg + " "
+	}
+	return Script("gravity-system.log.gz", fmt.Sprintf(template, args))
 }
 
 // systemFileLogs fetches gravity platform-related logs
@@ -120,14 +125,14 @@ cat %v 2> /dev/null || true`
 }
 
 // planetLogs fetches planet syslog messages as well as the fresh journal entries
-func planetLogs() Collectors {
+func planetLogs(timeRange TimeRange) Collectors {
 	return Collectors{
 		// Fetch planet journal entries for the last two days
 		// The log can be imported as a journal with systemd-journal-remote:
 		//
 		// $ cat ./node-1-planet-journal-export.log | /lib/systemd/systemd-journal-remote -o ./journal/system.journal -
 		Self("planet-journal-export.log.gz",
-			"system", "export-runtime-journal"),
+			append([]string{"system", "export-runtime-journal"}, timeRange.Args()...)...),
 	}
 }
 
@@ -140,6 +145,14 @@ func etcdBackup() Collectors {
 	}
 }
 
+// etcdLogs fetches etcd service logs from the planet journal
+func etcdLogs(timeRange TimeRange) Collectors {
+	args := append([]string{"/bin/journalctl", "--no-pager", "--unit=etcd"}, timeRange.JournalArgs()...)
+	return Collectors{
+		Cmd("etcd-logs", utils.PlanetCommandArgs(args...)...),
+	}
+}
+
 func fetchEtc(name string) CollectorFunc {
 	args := []string{
 		"cz", "--ignore-failed-read", "--dereference", "--ignore-command-error",
@@ -0,0 +1,155 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// TimeRange limits the collected logs to the specified period.
+// Zero Since or Until leave the respective end of the period open
+type TimeRange struct {
+	// Since is the start of the period
+	Since time.Time
+	// Until is the end of the period
+	Until time.Time
+}
+
+// Check validates this time range
+func (r TimeRange) Check() error {
+	if !r.Since.IsZero() && !r.Until.IsZero() && r.Until.Before(r.Since) {
+		return trace.BadParameter("end of the log period %v is before its start %v",
+			r.Until.Format(time.RFC3339), r.Since.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// IsEmpty returns true if the range does not limit the logs
+func (r TimeRange) IsEmpty() bool {
+	return r.Since.IsZero() && r.Until.IsZero()
+}
+
+// Args returns the gravity command line flags for this range
+func (r TimeRange) Args() (args []string) {
+	if !r.Since.IsZero() {
+		args = append(args, fmt.Sprintf("--since=%v", r.Since.UTC().Format(time.RFC3339)))
+	}
+	if !r.Until.IsZero() {
+		args = append(args, fmt.Sprintf("--until=%v", r.Until.UTC().Format(time.RFC3339)))
+	}
+	return args
+}
+
+// JournalArgs returns the journalctl command line flags for this range
+func (r TimeRange) JournalArgs() (args []string) {
+	if !r.Since.IsZero() {
+		args = append(args, fmt.Sprintf("--since=@%v", r.Since.Unix()))
+	}
+	if !r.Until.IsZero() {
+		args = append(args, fmt.Sprintf("--until=@%v", r.Until.Unix()))
+	}
+	return args
+}
+
+// KubectlLogsArgs returns the kubectl logs command line flags for this range.
+// kubectl does not support the end of the period so logs are requested
+// with timestamps to be filtered with NewUntilWriter
+func (r TimeRange) KubectlLogsArgs() (args []string) {
+	if !r.Since.IsZero() {
+		args = append(args, fmt.Sprintf("--since-time=%v", r.Since.UTC().Format(time.RFC3339)))
+	}
+	if !r.Until.IsZero() {
+		args = append(args, "--timestamps")
+	}
+	return args
+}
+
+// ParseTime parses the value either as a timestamp in RFC3339 format
+// or as a duration before now
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		if duration < 0 {
+			return time.Time{}, trace.BadParameter("duration %q can't be negative", value)
+		}
+		return now.Add(-duration), nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, trace.BadParameter(
+			"expected a duration like 24h or a timestamp like 2006-01-02T15:04:05Z, got %q", value)
+	}
+	return timestamp, nil
+}
+
+// NewUntilWriter returns a writer that drops log lines prefixed with
+// a timestamp after until. Lines without a timestamp are passed through.
+// The returned writer must be closed to flush the last incomplete line
+func NewUntilWriter(w io.Writer, until time.Time) io.WriteCloser {
+	return &untilWriter{w: w, until: until}
+}
+
+// Write buffers the data and forwards the complete lines
+// that are not after the end of the period.
+// Implements io.Writer
+func (r *untilWriter) Write(p []byte) (int, error) {
+	r.buf = append(r.buf, p...)
+	for {
+		i := bytes.IndexByte(r.buf, '\n')
+		if i == -1 {
+			return len(p), nil
+		}
+		if err := r.writeLine(r.buf[:i+1]); err != nil {
+			return 0, trace.ConvertSystemError(err)
+		}
+		r.buf = r.buf[i+1:]
+	}
+}
+
+// Close flushes the last incomplete line.
+// Implements io.Closer
+func (r *untilWriter) Close() error {
+	if len(r.buf) == 0 {
+		return nil
+	}
+	err := r.writeLine(r.buf)
+	r.buf = nil
+	return trace.ConvertSystemError(err)
+}
+
+func (r *untilWriter) writeLine(line []byte) error {
+	fields := bytes.SplitN(line, []byte(" "), 2)
+	timestamp, err := time.Parse(time.RFC3339Nano, string(bytes.TrimSpace(fields[0])))
+	if err == nil && timestamp.After(r.until) {
+		return nil
+	}
+	_, err = r.w.Write(line)
+	return err
+}
+
+type untilWriter struct {
+	w     io.Writer
+	until time.Time
+	buf   []byte
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/utils"
+
+	. "gopkg.in/check.v1"
+)
+
+func (r *S) TestParsesTimeRange(c *C) {
+	now := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	since, err := ParseTime("24h", now)
+	c.Assert(err, IsNil)
+	c.Assert(since, Equals, now.Add(-24*time.Hour))
+	until, err := ParseTime("2018-10-01T11:00:00Z", now)
+	c.Assert(err, IsNil)
+	c.Assert(until.Equal(now.Add(-time.Hour)), Equals, true)
+	_, err = ParseTime("yesterday", now)
+	c.Assert(err, NotNil)
+
+	timeRange := TimeRange{Since: since, Until: until}
+	c.Assert(timeRange.Check(), IsNil)
+	c.Assert(timeRange.JournalArgs(), DeepEquals, []string{
+		"--since=@1538308800", "--until=@1538391600",
+	})
+	c.Assert(timeRange.KubectlLogsArgs(), DeepEquals, []string{
+		"--since-time=2018-09-30T12:00:00Z", "--timestamps",
+	})
+	c.Assert(TimeRange{Since: until, Until: since}.Check(), NotNil)
+
+	profile, err := GetProfile(ProfileMinimal)
+	c.Assert(err, IsNil)
+	c.Assert(profile.LogRange(now), Equals, TimeRange{Since: since})
+}
+
+func (r *S) TestUntilWriterDropsLaterLines(c *C) {
+	var out bytes.Buffer
+	w := NewUntilWriter(&out, time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC))
+	_, err := io.WriteString(w, "2018-10-01T11:59:59.5Z before\ncontinuation\n2018-10-01T1")
+	c.Assert(err, IsNil)
+	_, err = io.WriteString(w, "2:00:01Z after\n2018-10-01T12:00:00Z last")
+	c.Assert(err, IsNil)
+	c.Assert(w.Close(), IsNil)
+	c.Assert(out.String(), Equals, "2018-10-01T11:59:59.5Z before\ncontinuation\n2018-10-01T12:00:00Z last")
+}
+
+func (r *S) TestCollectWithBudget(c *C) {
+	dir := c.MkDir()
+	collectors := Collectors{
+		Cmd("small", "echo", "-n", "abc"),
+		Cmd("large", "echo", "-n", strings.Repeat("x", 100)),
+		Cmd("last", "echo", "-n", strings.Repeat("y", 100)),
+	}
+	truncated, err := CollectWithBudget(context.TODO(), collectors,
+		NewFileWriter(dir), utils.Runner, 60)
+	c.Assert(err, IsNil)
+	c.Assert(truncated, DeepEquals, []string{"large", "last"})
+	for name, size := range map[string]int{"small": 3, "large": 28, "last": 29} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		c.Assert(err, IsNil)
+		c.Assert(len(data), Equals, size, Commentf(name))
+	}
+}
+
+func (r *S) TestBudgetKeepsCompressedFilesValid(c *C) {
+	dir := c.MkDir()
+	w := NewBudgetFileWriter(NewFileWriter(dir), 4096)
+
+	var lines bytes.Buffer
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&lines, "%v %v\n", i, strings.Repeat("x", i%50))
+	}
+	var input bytes.Buffer
+	gz := gzip.NewWriter(&input)
+	_, err := gz.Write(lines.Bytes())
+	c.Assert(err, IsNil)
+	c.Assert(gz.Close(), IsNil)
+	writeFile(c, w, "lines.log.gz", input.Bytes())
+
+	input.Reset()
+	gz = gzip.NewWriter(&input)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"small", "large", "last"} {
+		data := []byte(name)
+		if name == "large" {
+			data = lines.Bytes()
+		}
+		c.Assert(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}), IsNil)
+		_, err = tw.Write(data)
+		c.Assert(err, IsNil)
+	}
+	c.Assert(tw.Close(), IsNil)
+	c.Assert(gz.Close(), IsNil)
+	writeFile(c, w, "files.tar.gz", input.Bytes())
+
+	c.Assert(w.Truncated(), DeepEquals, []string{"lines.log.gz", "files.tar.gz"})
+	c.Assert(w.written <= 4096, Equals, true, Commentf("written %v", w.written))
+
+	f, err := os.Open(filepath.Join(dir, "lines.log.gz"))
+	c.Assert(err, IsNil)
+	defer f.Close()
+	gzr, err := gzip.NewReader(f)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(gzr)
+	c.Assert(err, IsNil)
+	c.Assert(len(data) > 0, Equals, true)
+	c.Assert(bytes.HasPrefix(lines.Bytes(), data), Equals, true)
+	c.Assert(bytes.HasSuffix(data, []byte("\n")), Equals, true)
+
+	f, err = os.Open(filepath.Join(dir, "files.tar.gz"))
+	c.Assert(err, IsNil)
+	defer f.Close()
+	gzr, err = gzip.NewReader(f)
+	c.Assert(err, IsNil)
+	tr := tar.NewReader(gzr)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		names = append(names, header.Name)
+	}
+	c.Assert(names, DeepEquals, []string{"small", "last"})
+}
+
+func writeFile(c *C, fileWriter FileWriter, name string, data []byte) {
+	w, err := fileWriter.NewWriter(name)
+	c.Assert(err, IsNil)
+	_, err = w.Write(data)
+	c.Assert(err, IsNil)
+	c.Assert(w.Close(), IsNil)
+}
@@ -20,7 +20,6 @@ import (
 	"context"
 	"regexp"
 	"strconv"
-	"strings"
 
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
@@ -56,6 +55,13 @@ var (
 // The specified directory is expected to be on the same filesystem
 // as the Docker graph directory (which might not exist at this point).
 func ValidateDocker(d Docker, dir string) (failed []*pb.Probe, err error) {
+	var probes health.Probes
+	CheckDocker(d, dir, &probes)
+	return probes.GetFailed(), nil
+}
+
+// CheckDocker checks Docker requirements and reports all probes to reporter
+func CheckDocker(d Docker, dir string, reporter health.Reporter) {
 	var checkers []health.Checker
 
 	checkers = append(checkers,
@@ -70,27 +76,38 @@ func ValidateDocker(d Docker, dir string) (failed []*pb.Probe, err error) {
 	}
 
 	all := monitoring.NewCompositeChecker("docker", checkers)
-	var probes health.Probes
-
-	all.Check(context.TODO(), &probes)
-	return probes.GetFailed(), nil
+	all.Check(context.TODO(), reporter)
 }
 
 // ValidateKubelet will check kubelet configuration
 func ValidateKubelet(profile NodeProfile, manifest Manifest) (failed []*pb.Probe) {
+	var probes health.Probes
+	CheckKubelet(profile, manifest, &probes)
+	return probes.GetFailed()
+}
+
+// CheckKubelet checks kubelet configuration and reports all probes to reporter
+func CheckKubelet(profile NodeProfile, manifest Manifest, reporter health.Reporter) {
 	checkers := append([]health.Checker{},
 		DefaultKernelModuleChecker,
 		monitoring.NewCGroupChecker("cpu", "cpuacct", "cpuset", "memory"),
 	)
 	checker := monitoring.NewCompositeChecker("kubelet", checkers)
-
-	var probes health.Probes
-	checker.Check(context.TODO(), &probes)
-	return probes.GetFailed()
+	checker.Check(context.TODO(), reporter)
 }
 
 // ValidateRequirements will assess local node to match requirements
 func ValidateRequirements(reqs Requirements, stateDir string) (failed []*pb.Probe, err error) {
+	var probes health.Probes
+	if err := CheckRequirements(reqs, stateDir, &probes); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return probes.GetFailed(), nil
+}
+
+// CheckRequirements assesses local node to match requirements
+// and reports all probes to reporter
+func CheckRequirements(reqs Requirements, stateDir string, reporter health.Reporter) error {
 	var checkers []health.Checker
 	checkers = append(checkers, monitoring.NewHostChecker(
 		monitoring.HostConfig{
@@ -116,7 +133,7 @@ func ValidateRequirements(reqs Requirements, stateDir string) (failed []*pb.Prob
 	for _, port := range reqs.Network.Ports {
 		portRange, err := parsePortRanges(port.Protocol, port.Ranges)
 		if err != nil {
-			return nil, trace.Wrap(err, "invalid port range in %+v", port)
+			return trace.Wrap(err, "invalid port range in %+v", port)
 		}
 		portRanges = append(portRanges, portRange...)
 	}
@@ -140,19 +157,9 @@ func ValidateRequirements(reqs Requirements, stateDir string) (failed []*pb.Prob
 		}))
 	}
 
-	for _, check := range reqs.CustomChecks {
-		checkers = append(checkers,
-			monitoring.NewScriptChecker(monitoring.Script{
-				Reader:      strings.NewReader(check.Script),
-				Description: check.Description,
-			}))
-	}
-
 	all := monitoring.NewCompositeChecker("common requirements", checkers)
-	var probes health.Probes
-
-	all.Check(context.TODO(), &probes)
-	return probes.GetFailed(), nil
+	all.Check(context.TODO(), reporter)
+	return nil
 }
 
 // shouldCheckVolume determines if this volume should be checked
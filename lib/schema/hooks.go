@@ -18,6 +18,10 @@ package schema
 
 import (
 	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
 
 	"github.com/gravitational/trace"
 
@@ -77,6 +81,9 @@ type Hooks struct {
 	Backup *Hook `json:"backup,omitempty"`
 	// Restore restores application state from a backup
 	Restore *Hook `json:"restore,omitempty"`
+	// VerifyRestore restores a backup without touching live data
+	// to verify that the backup is restorable
+	VerifyRestore *Hook `json:"verifyRestore,omitempty"`
 	// NetworkInstall is a hook for installing a custom overlay network
 	NetworkInstall *Hook `json:"networkInstall,omitempty"`
 	// NetworkUpdate is a hook for updating a custom overlay network
@@ -98,6 +105,29 @@ func (h Hooks) AllHooks() (all []*Hook) {
 	return all
 }
 
+// Check validates the execution policies of all hooks.
+// Hooks that do not specify their type are validated as the type
+// they are declared with
+func (h Hooks) Check() error {
+	var errors []error
+	value := reflect.ValueOf(h)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if field.IsNil() {
+			continue
+		}
+		hook := *field.Interface().(*Hook)
+		if hook.Type == "" {
+			tag := value.Type().Field(i).Tag.Get("json")
+			hook.Type = HookType(strings.Split(tag, ",")[0])
+		}
+		if err := hook.Check(); err != nil {
+			errors = append(errors, trace.Wrap(err))
+		}
+	}
+	return trace.NewAggregate(errors...)
+}
+
 // Hook defines a hook as either a shell script run in the context
 // of the automatically created job or the raw job spec
 type Hook struct {
@@ -105,6 +135,117 @@ type Hook struct {
 	Type HookType `json:"type,omitempty"`
 	// Job is a URL of (file:// or http://) or a literal value of a k8s job
 	Job string `json:"job,omitempty"`
+	// Timeout is the maximum hook job running time in Go duration format, e.g. 10m.
+	// Overrides the job's active deadline
+	Timeout string `json:"timeout,omitempty"`
+	// Retries is the number of times the hook is retried after a failure
+	Retries int `json:"retries,omitempty"`
+	// RetryBackoff is the delay before the first retry in Go duration format.
+	// The delay doubles with every subsequent retry
+	RetryBackoff string `json:"retryBackoff,omitempty"`
+	// OnFailure defines how the operation running the hook handles
+	// the hook failure after all retries have been exhausted
+	OnFailure HookFailurePolicy `json:"onFailure,omitempty"`
+}
+
+// Check validates the hook execution policy
+func (h Hook) Check() error {
+	if h.Timeout != "" {
+		timeout, err := time.ParseDuration(h.Timeout)
+		if err != nil || timeout <= 0 {
+			return trace.BadParameter("hook %v: invalid timeout %q, "+
+				"should be a positive duration, e.g. 10m", h.Type, h.Timeout)
+		}
+	}
+	if h.Retries < 0 || h.Retries > defaults.HookMaxRetries {
+		return trace.BadParameter("hook %v: retries should be between 0 and %v, got %v",
+			h.Type, defaults.HookMaxRetries, h.Retries)
+	}
+	if h.RetryBackoff != "" {
+		backoff, err := time.ParseDuration(h.RetryBackoff)
+		if err != nil || backoff <= 0 {
+			return trace.BadParameter("hook %v: invalid retry backoff %q, "+
+				"should be a positive duration, e.g. 10s", h.Type, h.RetryBackoff)
+		}
+	}
+	switch h.OnFailure {
+	case "", HookFailureAbort, HookFailureContinue, HookFailureRollback:
+	default:
+		return trace.BadParameter("hook %v: unknown failure policy %q, supported are %v",
+			h.Type, h.OnFailure, AllHookFailurePolicies)
+	}
+	if h.OnFailure == HookFailureRollback && !h.Type.SupportsRollback() {
+		return trace.BadParameter("hook %v: failure policy %q is only supported by %v hooks",
+			h.Type, h.OnFailure, RollbackHooks)
+	}
+	return nil
+}
+
+// GetTimeout returns the hook job timeout or 0 if the hook does not specify one
+func (h Hook) GetTimeout() time.Duration {
+	timeout, err := time.ParseDuration(h.Timeout)
+	if err != nil {
+		return 0
+	}
+	return timeout
+}
+
+// GetRetryBackoff returns the delay before the first hook retry
+func (h Hook) GetRetryBackoff() time.Duration {
+	backoff, err := time.ParseDuration(h.RetryBackoff)
+	if err != nil || backoff <= 0 {
+		return defaults.HookRetryBackoff
+	}
+	return backoff
+}
+
+// GetFailurePolicy returns the hook failure policy
+func (h Hook) GetFailurePolicy() HookFailurePolicy {
+	if h.OnFailure == "" {
+		return HookFailureAbort
+	}
+	return h.OnFailure
+}
+
+// HookFailurePolicy defines how the operation running a hook
+// handles the hook failure
+type HookFailurePolicy string
+
+const (
+	// HookFailureAbort fails the operation
+	HookFailureAbort HookFailurePolicy = "abort"
+	// HookFailureContinue ignores the failure and continues the operation
+	HookFailureContinue HookFailurePolicy = "continue"
+	// HookFailureRollback fails the operation and rolls back its plan
+	// which runs the application's rollback hooks.
+	// Only supported by the update hooks, see RollbackHooks
+	HookFailureRollback HookFailurePolicy = "rollback"
+)
+
+// AllHookFailurePolicies lists the supported hook failure policies
+var AllHookFailurePolicies = []HookFailurePolicy{
+	HookFailureAbort,
+	HookFailureContinue,
+	HookFailureRollback,
+}
+
+// RollbackHooks lists hooks that support the rollback failure policy:
+// only the update operation can be rolled back
+var RollbackHooks = []HookType{
+	HookNetworkUpdate,
+	HookUpdate,
+	HookUpdated,
+}
+
+// SupportsRollback returns true if the failure of this hook
+// can be handled by rolling back the operation
+func (h HookType) SupportsRollback() bool {
+	for _, hookType := range RollbackHooks {
+		if h == hookType {
+			return true
+		}
+	}
+	return false
 }
 
 // Empty determines if the hook set is empty
@@ -191,6 +332,11 @@ const (
 	// created backup.
 	// The hook is scheduled on the same node where the restore command runs
 	HookRestore HookType = "restore"
+	// HookVerifyRestore defines a hook to verify a previously created backup.
+	// The hook is expected to restore the backup into its own namespace
+	// without touching live data.
+	// The hook is scheduled on the same node where the restore command runs
+	HookVerifyRestore HookType = "verifyRestore"
 	// HookNetworkInstall defines a hook used to install a custom overlay network
 	HookNetworkInstall = "networkInstall"
 	// HookNetworkUpdate defines a hook to update the overlay network
@@ -232,6 +378,7 @@ func AllHooks() []HookType {
 		HookDump,
 		HookBackup,
 		HookRestore,
+		HookVerifyRestore,
 		HookNetworkInstall,
 		HookNetworkUpdate,
 		HookNetworkRollback,
@@ -296,6 +443,8 @@ func HookFromString(hookType HookType, manifest Manifest) (*Hook, error) {
 		hook = manifest.Hooks.Backup
 	case HookRestore:
 		hook = manifest.Hooks.Restore
+	case HookVerifyRestore:
+		hook = manifest.Hooks.VerifyRestore
 	case HookNetworkInstall:
 		hook = manifest.Hooks.NetworkInstall
 	case HookNetworkUpdate:
@@ -18,6 +18,9 @@ package schema
 
 import (
 	"reflect"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
 
 	. "gopkg.in/check.v1"
 	batchv1 "k8s.io/api/batch/v1"
@@ -135,3 +138,138 @@ hooks:
 	c.Assert(err, IsNil)
 	c.Assert(installJob, DeepEquals, job)
 }
+
+func (r *HooksSuite) TestDecodesHookPolicy(c *C) {
+	const manifest = `
+apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: test
+  resourceVersion: 0.0.1
+hooks:
+  update:
+    timeout: 15m
+    retries: 3
+    retryBackoff: 30s
+    onFailure: rollback
+    job: |
+      apiVersion: batch/v1
+      kind: Job
+      metadata:
+        name: update
+  status:
+    job: |
+      apiVersion: batch/v1
+      kind: Job
+      metadata:
+        name: status
+`
+	m, err := ParseManifestYAML([]byte(manifest))
+	c.Assert(err, IsNil)
+
+	update := m.Hooks.Updating
+	c.Assert(update.GetTimeout(), Equals, 15*time.Minute)
+	c.Assert(update.Retries, Equals, 3)
+	c.Assert(update.GetRetryBackoff(), Equals, 30*time.Second)
+	c.Assert(update.GetFailurePolicy(), Equals, HookFailureRollback)
+
+	status := m.Hooks.Status
+	c.Assert(status.GetTimeout(), Equals, time.Duration(0))
+	c.Assert(status.Retries, Equals, 0)
+	c.Assert(status.GetRetryBackoff(), Equals, defaults.HookRetryBackoff)
+	c.Assert(status.GetFailurePolicy(), Equals, HookFailureAbort)
+}
+
+func (r *HooksSuite) TestDecodesVerifyRestoreHook(c *C) {
+	const manifest = `
+apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: test
+  resourceVersion: 0.0.1
+hooks:
+  restore:
+    job: |
+      apiVersion: batch/v1
+      kind: Job
+      metadata:
+        name: restore
+  verifyRestore:
+    job: |
+      apiVersion: batch/v1
+      kind: Job
+      metadata:
+        name: verify-restore
+`
+	m, err := ParseManifestYAML([]byte(manifest))
+	c.Assert(err, IsNil)
+
+	hook, err := HookFromString(HookVerifyRestore, *m)
+	c.Assert(err, IsNil)
+	c.Assert(hook.Type, Equals, HookVerifyRestore)
+	c.Assert(hook, Not(Equals), m.Hooks.Restore)
+}
+
+func (r *HooksSuite) TestValidatesHookPolicy(c *C) {
+	var testCases = []struct {
+		hook    Hook
+		comment string
+		valid   bool
+	}{
+		{
+			hook:    Hook{Type: HookInstall},
+			comment: "no policy",
+			valid:   true,
+		},
+		{
+			hook:    Hook{Type: HookInstall, Timeout: "5m", Retries: 2, RetryBackoff: "5s", OnFailure: HookFailureContinue},
+			comment: "full policy",
+			valid:   true,
+		},
+		{
+			hook:    Hook{Type: HookInstall, Timeout: "five minutes"},
+			comment: "invalid timeout",
+		},
+		{
+			hook:    Hook{Type: HookInstall, Timeout: "-1m"},
+			comment: "negative timeout",
+		},
+		{
+			hook:    Hook{Type: HookInstall, Retries: defaults.HookMaxRetries + 1},
+			comment: "too many retries",
+		},
+		{
+			hook:    Hook{Type: HookInstall, RetryBackoff: "0s"},
+			comment: "zero backoff",
+		},
+		{
+			hook:    Hook{Type: HookInstall, OnFailure: "ignore"},
+			comment: "unknown failure policy",
+		},
+		{
+			hook:    Hook{Type: HookUpdate, OnFailure: HookFailureRollback},
+			comment: "rollback policy on update hook",
+			valid:   true,
+		},
+		{
+			hook:    Hook{Type: HookInstall, OnFailure: HookFailureRollback},
+			comment: "rollback policy on install hook",
+		},
+		{
+			hook:    Hook{Type: HookNodeAdding, OnFailure: HookFailureRollback},
+			comment: "rollback policy on expand hook",
+		},
+		{
+			hook:    Hook{Type: HookNodeRemoving, OnFailure: HookFailureRollback},
+			comment: "rollback policy on shrink hook",
+		},
+	}
+	for _, tc := range testCases {
+		err := tc.hook.Check()
+		if tc.valid {
+			c.Assert(err, IsNil, Commentf(tc.comment))
+		} else {
+			c.Assert(err, NotNil, Commentf(tc.comment))
+		}
+	}
+}
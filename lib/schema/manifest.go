@@ -73,6 +73,8 @@ type Manifest struct {
 	SystemOptions *SystemOptions `json:"systemOptions,omitempty"`
 	// Extensions allows to enable/disable various custom features
 	Extensions *Extensions `json:"extensions,omitempty"`
+	// Report customizes the cluster debug report
+	Report *Report `json:"report,omitempty"`
 	// WebConfig allows to specify config.js used by UI to customize installer
 	WebConfig string `json:"webConfig,omitempty"`
 }
@@ -773,15 +775,78 @@ func (d Device) Format() string {
 	return strings.Join(parts, ";")
 }
 
-// CustomCheck defines a script that runs a custom preflight check
+// CustomCheck defines a custom preflight check.
+// The check runs either a script or a command and validates
+// its exit code and, optionally, its output
 type CustomCheck struct {
+	// Name is the check name
+	Name string `json:"name,omitempty"`
 	// Description provides a readable description for the check
 	Description string `json:"description,omitempty"`
 	// Script defines the contents of the check script.
 	// It is provided to the shell verbatim in a temporary file
 	Script string `json:"script,omitempty"`
+	// Command defines the command to run as an alternative to Script
+	Command []string `json:"command,omitempty"`
+	// ExitCode is the expected exit code, 0 by default
+	ExitCode int `json:"exitCode,omitempty"`
+	// OutputPattern is the regular expression the combined
+	// output of the check is expected to match
+	OutputPattern string `json:"outputPattern,omitempty"`
+	// Severity is the severity of the check failure: fail or warn.
+	// Failed checks with warn severity are reported without
+	// failing the validation. Defaults to fail
+	Severity string `json:"severity,omitempty"`
+	// Remediation describes how to fix the check failure
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// Check validates this custom check
+func (c CustomCheck) Check() error {
+	if c.Script == "" && len(c.Command) == 0 {
+		return trace.BadParameter("custom check %q should specify either script or command",
+			c.GetName())
+	}
+	if c.Script != "" && len(c.Command) != 0 {
+		return trace.BadParameter("custom check %q can't specify both script and command",
+			c.GetName())
+	}
+	if c.OutputPattern != "" {
+		if _, err := regexp.Compile(c.OutputPattern); err != nil {
+			return trace.BadParameter("invalid output pattern %q in custom check %q: %v",
+				c.OutputPattern, c.GetName(), err)
+		}
+	}
+	switch c.Severity {
+	case "", CheckSeverityFail, CheckSeverityWarn:
+	default:
+		return trace.BadParameter("unsupported severity %q in custom check %q, supported are %q and %q",
+			c.Severity, c.GetName(), CheckSeverityFail, CheckSeverityWarn)
+	}
+	return nil
+}
+
+// GetName returns the name of the check for display.
+// Falls back to the description if the name is not set
+func (c CustomCheck) GetName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Description
+}
+
+// IsWarning returns true if the failure of this check is a warning
+func (c CustomCheck) IsWarning() bool {
+	return c.Severity == CheckSeverityWarn
 }
 
+const (
+	// CheckSeverityFail specifies that the custom check failure fails the validation
+	CheckSeverityFail = "fail"
+	// CheckSeverityWarn specifies that the custom check failure is reported as a warning
+	CheckSeverityWarn = "warn"
+)
+
 // DevicesForProfile returns a list of required devices for the specified profile
 func (m Manifest) DevicesForProfile(profileName string) ([]Device, error) {
 	profile, err := m.NodeProfiles.ByName(profileName)
@@ -1130,6 +1195,32 @@ type ExternalService struct {
 	Args []string `json:"args,omitempty"`
 }
 
+// Report customizes the cluster debug report
+type Report struct {
+	// Collectors lists application-defined diagnostics collectors
+	Collectors []ReportCollector `json:"collectors,omitempty"`
+}
+
+// ReportCollector defines an application-defined diagnostics collector.
+// The collector command is executed inside the planet container
+type ReportCollector struct {
+	// Name is the name of the file the command output is written to
+	Name string `json:"name"`
+	// Command is the command to run with arguments
+	Command []string `json:"command"`
+	// AllNodes specifies whether the command is run on every node.
+	// By default, it is only run on a single master node
+	AllNodes bool `json:"allNodes,omitempty"`
+}
+
+// ReportCollectors returns the application-defined report collectors
+func (m Manifest) ReportCollectors() []ReportCollector {
+	if m.Report == nil {
+		return nil
+	}
+	return m.Report.Collectors
+}
+
 // Extensions defines various custom application features
 type Extensions struct {
 	// Encryption allows to encrypt installer packages
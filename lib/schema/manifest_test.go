@@ -641,3 +641,81 @@ extensions:
 			Commentf("Test case %v failed", tc))
 	}
 }
+
+func (s *ManifestSuite) TestReportCollectors(c *C) {
+	bytes := []byte(`apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: app
+  resourceVersion: 0.0.1
+report:
+  collectors:
+  - name: app-status
+    command: ["kubectl", "get", "pods", "-n", "app"]
+  - name: app-disk
+    command: ["df", "-h", "/var/lib/app"]
+    allNodes: true`)
+	m, err := ParseManifestYAML(bytes)
+	c.Assert(err, IsNil)
+	c.Assert(m.ReportCollectors(), DeepEquals, []ReportCollector{
+		{Name: "app-status", Command: []string{"kubectl", "get", "pods", "-n", "app"}},
+		{Name: "app-disk", Command: []string{"df", "-h", "/var/lib/app"}, AllNodes: true},
+	})
+
+	bytes = []byte(`apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: app
+  resourceVersion: 0.0.1
+report:
+  collectors:
+  - name: app-status`)
+	_, err = ParseManifestYAML(bytes)
+	c.Assert(err, NotNil)
+}
+
+func (s *ManifestSuite) TestCustomChecks(c *C) {
+	bytes := []byte(`apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: myapp
+  resourceVersion: 0.0.1
+installer:
+  flavors:
+    items:
+      - name: one
+        nodes:
+          - profile: node
+            count: 1
+nodeProfiles:
+  - name: node
+    requirements:
+      customChecks:
+        - name: nfs
+          description: NFS server is reachable
+          command: ["showmount", "-e", "nfs.example.com"]
+          outputPattern: "^Export list"
+          severity: warn
+          remediation: Make sure the NFS server is running.`)
+	manifest, err := ParseManifestYAML(bytes)
+	c.Assert(err, IsNil)
+	checks := manifest.NodeProfiles[0].Requirements.CustomChecks
+	c.Assert(checks, DeepEquals, []CustomCheck{{
+		Name:          "nfs",
+		Description:   "NFS server is reachable",
+		Command:       []string{"showmount", "-e", "nfs.example.com"},
+		OutputPattern: "^Export list",
+		Severity:      CheckSeverityWarn,
+		Remediation:   "Make sure the NFS server is running.",
+	}})
+	c.Assert(checks[0].IsWarning(), Equals, true)
+
+	for _, check := range []CustomCheck{
+		{Name: "empty"},
+		{Name: "both", Script: "true", Command: []string{"true"}},
+		{Name: "pattern", Command: []string{"true"}, OutputPattern: "("},
+		{Name: "severity", Command: []string{"true"}, Severity: "critical"},
+	} {
+		c.Assert(check.Check(), NotNil, Commentf(check.Name))
+	}
+}
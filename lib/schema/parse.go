@@ -154,6 +154,12 @@ func CheckAndSetDefaults(manifest *Manifest) error {
 		}
 	}
 
+	if manifest.Hooks != nil {
+		if err := manifest.Hooks.Check(); err != nil {
+			errors = append(errors, trace.Wrap(err))
+		}
+	}
+
 	for i, nodeProfile := range manifest.NodeProfiles {
 		for j := range nodeProfile.Requirements.Volumes {
 			if err := manifest.NodeProfiles[i].Requirements.Volumes[j].CheckAndSetDefaults(); err != nil {
@@ -298,6 +304,10 @@ func checkRequirements(reqs Requirements) error {
 		errors = append(errors, device.Check())
 	}
 
+	for _, check := range reqs.CustomChecks {
+		errors = append(errors, check.Check())
+	}
+
 	return trace.NewAggregate(errors...)
 }
 
@@ -268,8 +268,14 @@ const manifestSchema = `
                     "items": {
                       "type": "object",
                       "properties": {
+                        "name": {"type": "string"},
                         "description": {"type": "string"},
-                        "script": {"type": "string"}
+                        "script": {"type": "string"},
+                        "command": {"type": "array", "items": {"type": "string"}},
+                        "exitCode": {"type": "number"},
+                        "outputPattern": {"type": "string"},
+                        "severity": {"type": "string", "enum": ["fail", "warn"]},
+                        "remediation": {"type": "string"}
                       }
                     }
                   }
@@ -348,7 +354,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "clusterProvision"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "clusterDeprovision": {
@@ -356,7 +366,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "clusterDeprovision"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "nodesProvision": {
@@ -364,7 +378,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "nodesProvision"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "nodesDeprovision": {
@@ -372,7 +390,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "nodesDeprovision"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "install": {
@@ -380,7 +402,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "install"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "postInstall": {
@@ -388,7 +414,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "postInstall"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "uninstall": {
@@ -396,7 +426,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "uninstall"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "preUninstall": {
@@ -404,7 +438,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "preUninstall"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "preNodeAdd": {
@@ -412,7 +450,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "preNodeAdd"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "postNodeAdd": {
@@ -420,7 +462,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "postNodeAdd"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "preNodeRemove": {
@@ -428,7 +474,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "preNodeRemove"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "postNodeRemove": {
@@ -436,7 +486,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "postNodeRemove"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "preUpdate": {
@@ -444,7 +498,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "preUpdate"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "update": {
@@ -452,7 +510,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "update"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "postUpdate": {
@@ -460,7 +522,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "postUpdate"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "rollback": {
@@ -468,7 +534,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "rollback"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "postRollback": {
@@ -476,7 +546,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "postRollback"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "status": {
@@ -484,7 +558,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "status"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "info": {
@@ -492,7 +570,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "info"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "licenseUpdated": {
@@ -500,7 +582,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "licenseUpdated"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "start": {
@@ -508,7 +594,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "start"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "stop": {
@@ -516,7 +606,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "stop"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "dump": {
@@ -524,7 +618,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "dump"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "backup": {
@@ -532,7 +630,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "backup"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "restore": {
@@ -540,7 +642,23 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "restore"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
+              }
+            },
+            "verifyRestore": {
+              "type": "object",
+              "additionalProperties": false,
+              "properties": {
+                "type": {"type": "string", "default": "verifyRestore"},
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "networkInstall": {
@@ -548,7 +666,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "networkInstall"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "networkUpdate": {
@@ -556,7 +678,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "networkUpdate"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             },
             "networkRollback": {
@@ -564,7 +690,11 @@ const manifestSchema = `
               "additionalProperties": false,
               "properties": {
                 "type": {"type": "string", "default": "networkRollback"},
-                "job": {"type": "string"}
+                "job": {"type": "string"},
+                "timeout": {"type": "string"},
+                "retries": {"type": "integer", "minimum": 0},
+                "retryBackoff": {"type": "string"},
+                "onFailure": {"type": "string", "enum": ["abort", "continue", "rollback"]}
               }
             }
           }
@@ -590,6 +720,25 @@ const manifestSchema = `
             "configuration": {"$ref": "#/definitions/onOff"}
           }
         },
+        "report": {
+          "type": "object",
+          "additionalProperties": false,
+          "properties": {
+            "collectors": {
+              "type": "array",
+              "items": {
+                "type": "object",
+                "required": ["name", "command"],
+                "additionalProperties": false,
+                "properties": {
+                  "name": {"type": "string"},
+                  "command": {"type": "array", "items": {"type": "string"}, "minItems": 1},
+                  "allNodes": {"type": "boolean"}
+                }
+              }
+            }
+          }
+        },
         "webConfig": {"type": "string"}
       }
     },
@@ -0,0 +1,393 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+)
+
+// BackupPolicies manages cluster backup policies
+type BackupPolicies interface {
+	// UpsertBackupPolicy creates or updates the backup policy
+	UpsertBackupPolicy(BackupPolicy) error
+	// GetBackupPolicy returns the backup policy by name
+	GetBackupPolicy(name string) (BackupPolicy, error)
+	// GetBackupPolicies returns all backup policies
+	GetBackupPolicies() ([]BackupPolicy, error)
+	// DeleteBackupPolicy deletes the backup policy by name
+	DeleteBackupPolicy(name string) error
+}
+
+// BackupPolicy defines how often the cluster is backed up,
+// where the backups are stored and for how long
+type BackupPolicy interface {
+	// Resource provides common resource methods
+	teleservices.Resource
+	// CheckAndSetDefaults validates the policy and sets defaults
+	CheckAndSetDefaults() error
+	// GetInterval returns the interval between backups
+	GetInterval() time.Duration
+	// GetTimeout returns the maximum duration of the backup
+	GetTimeout() time.Duration
+	// GetRetention returns the backup retention settings
+	GetRetention() BackupRetention
+	// GetDestination returns the backup destination
+	GetDestination() BackupDestination
+	// GetEncryptionKey returns the key backups are encrypted with
+	GetEncryptionKey() string
+	// WithoutSecrets returns a copy of the policy without the encryption key
+	// and the object storage secret access key
+	WithoutSecrets() BackupPolicy
+}
+
+// NewBackupPolicy creates a new backup policy resource
+func NewBackupPolicy(name string, spec BackupPolicySpecV2) BackupPolicy {
+	return &BackupPolicyV2{
+		Kind:    KindBackupPolicy,
+		Version: teleservices.V2,
+		Metadata: teleservices.Metadata{
+			Name:      name,
+			Namespace: defaults.Namespace,
+		},
+		Spec: spec,
+	}
+}
+
+// BackupPolicyV2 is the backup policy resource
+type BackupPolicyV2 struct {
+	// Kind is the resource kind
+	Kind string `json:"kind"`
+	// Version is the resource version
+	Version string `json:"version"`
+	// Metadata is the resource metadata
+	Metadata teleservices.Metadata `json:"metadata"`
+	// Spec is the backup policy spec
+	Spec BackupPolicySpecV2 `json:"spec"`
+}
+
+// BackupPolicySpecV2 is the backup policy spec
+type BackupPolicySpecV2 struct {
+	// Schedule defines when backups are taken
+	Schedule BackupSchedule `json:"schedule"`
+	// Timeout is the maximum duration of a single backup
+	Timeout teleservices.Duration `json:"timeout,omitempty"`
+	// Retention defines how long backups are kept
+	Retention BackupRetention `json:"retention,omitempty"`
+	// Destination defines where backups are stored
+	Destination BackupDestination `json:"destination"`
+	// EncryptionKey is an optional passphrase backups are encrypted with
+	EncryptionKey string `json:"encryption_key,omitempty"`
+}
+
+// BackupSchedule defines when backups are taken
+type BackupSchedule struct {
+	// Interval is the interval between backups
+	Interval teleservices.Duration `json:"interval"`
+}
+
+// BackupRetention defines how long backups are kept.
+// The most recent backup is always kept
+type BackupRetention struct {
+	// Count is the maximum number of backups to keep
+	Count int `json:"count,omitempty"`
+	// MaxAge is the maximum age of backups to keep
+	MaxAge teleservices.Duration `json:"max_age,omitempty"`
+}
+
+// BackupDestination defines where backups are stored.
+// Exactly one of the destinations should be set
+type BackupDestination struct {
+	// Path is the directory backups are stored in on the node
+	// running the active cluster controller
+	Path string `json:"path,omitempty"`
+	// S3 is the S3-compatible object storage backups are uploaded to
+	S3 *S3Destination `json:"s3,omitempty"`
+}
+
+// String returns the destination description
+func (r BackupDestination) String() string {
+	if r.S3 != nil {
+		return fmt.Sprintf("s3://%v/%v", r.S3.Bucket, r.S3.Prefix)
+	}
+	return r.Path
+}
+
+// S3Destination describes the S3-compatible object storage bucket
+type S3Destination struct {
+	// Bucket is the bucket name
+	Bucket string `json:"bucket"`
+	// Prefix is the optional object key prefix
+	Prefix string `json:"prefix,omitempty"`
+	// Region is the bucket region
+	Region string `json:"region,omitempty"`
+	// Endpoint is the optional endpoint of the S3-compatible service
+	Endpoint string `json:"endpoint,omitempty"`
+	// AccessKeyID is the access key ID.
+	// The default credentials chain is used if unspecified
+	AccessKeyID string `json:"access_key_id,omitempty"`
+	// SecretAccessKey is the secret access key
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+}
+
+// GetName returns the policy name
+func (r *BackupPolicyV2) GetName() string {
+	return r.Metadata.Name
+}
+
+// SetName sets the policy name
+func (r *BackupPolicyV2) SetName(name string) {
+	r.Metadata.Name = name
+}
+
+// GetMetadata returns the policy metadata
+func (r *BackupPolicyV2) GetMetadata() teleservices.Metadata {
+	return r.Metadata
+}
+
+// SetExpiry sets the policy expiration time
+func (r *BackupPolicyV2) SetExpiry(expires time.Time) {
+	r.Metadata.SetExpiry(expires)
+}
+
+// Expiry returns the policy expiration time
+func (r *BackupPolicyV2) Expiry() time.Time {
+	return r.Metadata.Expiry()
+}
+
+// SetTTL sets the policy TTL
+func (r *BackupPolicyV2) SetTTL(clock clockwork.Clock, ttl time.Duration) {
+	r.Metadata.SetTTL(clock, ttl)
+}
+
+// GetInterval returns the interval between backups
+func (r *BackupPolicyV2) GetInterval() time.Duration {
+	return r.Spec.Schedule.Interval.Duration
+}
+
+// GetTimeout returns the maximum duration of the backup
+func (r *BackupPolicyV2) GetTimeout() time.Duration {
+	return r.Spec.Timeout.Duration
+}
+
+// GetRetention returns the backup retention settings
+func (r *BackupPolicyV2) GetRetention() BackupRetention {
+	return r.Spec.Retention
+}
+
+// GetDestination returns the backup destination
+func (r *BackupPolicyV2) GetDestination() BackupDestination {
+	return r.Spec.Destination
+}
+
+// GetEncryptionKey returns the key backups are encrypted with
+func (r *BackupPolicyV2) GetEncryptionKey() string {
+	return r.Spec.EncryptionKey
+}
+
+// WithoutSecrets returns a copy of the policy without the encryption key
+// and the object storage secret access key
+func (r *BackupPolicyV2) WithoutSecrets() BackupPolicy {
+	out := *r
+	out.Spec.EncryptionKey = ""
+	if r.Spec.Destination.S3 != nil {
+		s3 := *r.Spec.Destination.S3
+		s3.SecretAccessKey = ""
+		out.Spec.Destination.S3 = &s3
+	}
+	return &out
+}
+
+// CheckAndSetDefaults validates the policy and sets defaults
+func (r *BackupPolicyV2) CheckAndSetDefaults() error {
+	if r.Metadata.Name == "" {
+		return trace.BadParameter("missing parameter Name")
+	}
+	if strings.ContainsAny(r.Metadata.Name, `/\`) {
+		return trace.BadParameter("backup policy name %q cannot contain slashes", r.Metadata.Name)
+	}
+	if r.Spec.Schedule.Interval.Duration < defaults.BackupMinInterval {
+		return trace.BadParameter("backup interval should be at least %v", defaults.BackupMinInterval)
+	}
+	if r.Spec.Timeout.Duration == 0 {
+		r.Spec.Timeout = teleservices.NewDuration(defaults.HookJobDeadline)
+	}
+	if r.Spec.Retention.Count < 0 {
+		return trace.BadParameter("retention count cannot be negative")
+	}
+	if r.Spec.Retention.MaxAge.Duration < 0 {
+		return trace.BadParameter("retention max age cannot be negative")
+	}
+	destination := r.Spec.Destination
+	switch {
+	case destination.Path != "" && destination.S3 != nil:
+		return trace.BadParameter("only one of path or s3 destinations can be set")
+	case destination.Path != "":
+		if !filepath.IsAbs(destination.Path) {
+			return trace.BadParameter("destination path %q should be absolute", destination.Path)
+		}
+	case destination.S3 != nil:
+		if destination.S3.Bucket == "" {
+			return trace.BadParameter("missing s3 destination bucket")
+		}
+		// the secret access key is omitted from policies retrieved without secrets,
+		// see CheckBackupPolicySecrets
+		if destination.S3.AccessKeyID == "" && destination.S3.SecretAccessKey != "" {
+			return trace.BadParameter("both s3 access key ID and secret access key should be set")
+		}
+	default:
+		return trace.BadParameter("missing backup destination")
+	}
+	return nil
+}
+
+// CheckBackupPolicySecrets makes sure the policy has all the secrets
+// it needs to take backups
+func CheckBackupPolicySecrets(policy BackupPolicy) error {
+	s3 := policy.GetDestination().S3
+	if s3 != nil && s3.AccessKeyID != "" && s3.SecretAccessKey == "" {
+		return trace.BadParameter("both s3 access key ID and secret access key should be set")
+	}
+	return nil
+}
+
+// UnmarshalBackupPolicy unmarshals the backup policy from JSON or YAML
+func UnmarshalBackupPolicy(data []byte) (BackupPolicy, error) {
+	if len(data) == 0 {
+		return nil, trace.BadParameter("empty backup policy")
+	}
+	jsonData, err := teleutils.ToJSON(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var header teleservices.ResourceHeader
+	if err := json.Unmarshal(jsonData, &header); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	switch header.Version {
+	case teleservices.V2:
+		var policy BackupPolicyV2
+		err := teleutils.UnmarshalWithSchema(GetBackupPolicySchema(), &policy, jsonData)
+		if err != nil {
+			return nil, trace.BadParameter("%v", err)
+		}
+		policy.Metadata.CheckAndSetDefaults()
+		if err := policy.CheckAndSetDefaults(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &policy, nil
+	}
+	return nil, trace.BadParameter(
+		"%v resource version %q is not supported", KindBackupPolicy, header.Version)
+}
+
+// MarshalBackupPolicy marshals the backup policy into JSON
+func MarshalBackupPolicy(policy BackupPolicy, opts ...teleservices.MarshalOption) ([]byte, error) {
+	return json.Marshal(policy)
+}
+
+// BackupPolicySpecV2Schema is the JSON schema of the backup policy spec
+const BackupPolicySpecV2Schema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["schedule", "destination"],
+  "properties": {
+    "schedule": {
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["interval"],
+      "properties": {
+        "interval": {"type": "string"}
+      }
+    },
+    "timeout": {"type": "string"},
+    "retention": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "count": {"type": "integer"},
+        "max_age": {"type": "string"}
+      }
+    },
+    "destination": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "path": {"type": "string"},
+        "s3": {
+          "type": "object",
+          "additionalProperties": false,
+          "required": ["bucket"],
+          "properties": {
+            "bucket": {"type": "string"},
+            "prefix": {"type": "string"},
+            "region": {"type": "string"},
+            "endpoint": {"type": "string"},
+            "access_key_id": {"type": "string"},
+            "secret_access_key": {"type": "string"}
+          }
+        }
+      }
+    },
+    "encryption_key": {"type": "string"}
+  }
+}`
+
+// GetBackupPolicySchema returns the backup policy JSON schema
+func GetBackupPolicySchema() string {
+	return fmt.Sprintf(teleservices.V2SchemaTemplate, teleservices.MetadataSchema,
+		BackupPolicySpecV2Schema, "")
+}
+
+// Backup describes a cluster backup stored by a backup policy
+type Backup struct {
+	// Name is the unique backup name
+	Name string `json:"name"`
+	// Policy is the name of the policy the backup was taken by
+	Policy string `json:"policy"`
+	// ClusterName is the name of the backed up cluster
+	ClusterName string `json:"cluster_name"`
+	// Application is the cluster application package
+	Application string `json:"application"`
+	// Created is the backup creation time
+	Created time.Time `json:"created"`
+	// Size is the size of the backup archive in bytes
+	Size int64 `json:"size"`
+	// Encrypted is whether the backup archive is encrypted
+	Encrypted bool `json:"encrypted"`
+	// Components lists the parts of the cluster state in the backup
+	Components []string `json:"components"`
+}
+
+const (
+	// BackupComponentApplication is the output of the application backup hook
+	BackupComponentApplication = "application"
+	// BackupComponentState is the gravity state exported from the storage backend
+	BackupComponentState = "state"
+	// BackupComponentEtcd is the snapshot of the etcd keyspace
+	BackupComponentEtcd = "etcd"
+)
@@ -39,6 +39,8 @@ type Interface interface {
 	GetKubeletConfig() *Kubelet
 	// GetGlobalConfig returns the global configuration
 	GetGlobalConfig() *Global
+	// GetComponentConfigs returns the configuration of all components
+	GetComponentConfigs() ComponentConfigs
 	// SetCloudProvider sets the cloud provider for this configuration
 	SetCloudProvider(provider string)
 }
@@ -109,6 +111,11 @@ func (r *Resource) GetGlobalConfig() *Global {
 	return r.Spec.Global
 }
 
+// GetComponentConfigs returns the configuration of all components
+func (r *Resource) GetComponentConfigs() ComponentConfigs {
+	return r.Spec.ComponentConfigs
+}
+
 // SetCloudProvider sets the cloud provider for this configuration
 func (r *Resource) SetCloudProvider(provider string) {
 	if r.Spec.Global == nil {
@@ -186,6 +193,20 @@ type Spec struct {
 type ComponentConfigs struct {
 	// Kubelet defines kubelet configuration
 	Kubelet *Kubelet `json:"kubelet,omitempty"`
+	// Etcd defines etcd configuration
+	Etcd *Etcd `json:"etcd,omitempty"`
+}
+
+// IsEmpty returns true if no component is configured
+func (r ComponentConfigs) IsEmpty() bool {
+	return r.Kubelet == nil && r.Etcd == nil
+}
+
+// HasNodeComponents returns true if the configuration includes components
+// that also run on regular (non-master) nodes.
+// Etcd runs on every node, either as a cluster member or as a proxy
+func (r ComponentConfigs) HasNodeComponents() bool {
+	return r.Kubelet != nil || r.Etcd != nil
 }
 
 // Kubelet defines kubelet configuration
@@ -197,6 +218,12 @@ type Kubelet struct {
 	Config json.RawMessage `json:"config,omitempty"`
 }
 
+// Etcd defines etcd configuration
+type Etcd struct {
+	// ExtraArgs lists additional command line arguments
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+}
+
 // ControlPlaneComponent defines configuration of a control plane component
 type ControlPlaneComponent struct {
 	json.RawMessage
@@ -371,11 +398,24 @@ const specSchemaTemplate = `{
                 "enforceNodeAllocatable": {"type": "array", "items": {"type": "string"}}
               }
             },
-            "extraArgs": {"type": "array", "items": {"type": "string"}}
+            "extraArgs": {"$ref": "#/definitions/extraArgs"}
+          }
+        },
+        "etcd": {
+          "type": "object",
+          "additionalProperties": false,
+          "properties": {
+            "extraArgs": {"$ref": "#/definitions/extraArgs"}
           }
         }
       }
     }
+  },
+  "definitions": {
+    "extraArgs": {
+      "type": "array",
+      "items": {"type": "string", "pattern": "^--[a-zA-Z0-9][a-zA-Z0-9-]*(=.*)?$"}
+    }
   }
 }`
 
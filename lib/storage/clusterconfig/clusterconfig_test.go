@@ -187,6 +187,38 @@ spec:
 			},
 			comment: "consumes global configuration",
 		},
+		{
+			in: `kind: clusterconfiguration
+version: v1
+spec:
+  etcd:
+    extraArgs: ['--heartbeat-interval=500']`,
+			resource: &Resource{
+				Kind:    storage.KindClusterConfiguration,
+				Version: "v1",
+				Metadata: teleservices.Metadata{
+					Name:      constants.ClusterConfigurationMap,
+					Namespace: defaults.KubeSystemNamespace,
+				},
+				Spec: Spec{
+					ComponentConfigs: ComponentConfigs{
+						Etcd: &Etcd{
+							ExtraArgs: []string{"--heartbeat-interval=500"},
+						},
+					},
+				},
+			},
+			comment: "consumes etcd configuration",
+		},
+		{
+			in: `kind: clusterconfiguration
+version: v1
+spec:
+  etcd:
+    extraArgs: ['heartbeat-interval=500']`,
+			error:   trace.BadParameter(`failed to validate: .*extraArgs.*`),
+			comment: "validates command line arguments",
+		},
 	}
 	for _, tc := range testCases {
 		comment := Commentf(tc.comment)
@@ -229,6 +261,16 @@ func validate(expectedConfig kubeletConfiguration) func(obtained, expected *Reso
 	}
 }
 
+func (*S) TestComponentConfigs(c *C) {
+	c.Assert(NewEmpty().GetComponentConfigs().IsEmpty(), Equals, true)
+
+	resource := NewEmpty()
+	resource.Spec.Etcd = &Etcd{ExtraArgs: []string{"--heartbeat-interval=500"}}
+	configs := resource.GetComponentConfigs()
+	c.Assert(configs.IsEmpty(), Equals, false)
+	c.Assert(configs.HasNodeComponents(), Equals, true)
+}
+
 type kubeletConfiguration struct {
 	metav1.TypeMeta `json:",inline"`
 	Address         string `json:"address"`
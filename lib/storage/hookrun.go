@@ -0,0 +1,143 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gravitational/gravity/lib/loc"
+
+	"github.com/gravitational/trace"
+)
+
+// HookRuns stores the history of application hook runs
+type HookRuns interface {
+	// UpsertHookRun creates or updates the hook run record
+	UpsertHookRun(HookRun) error
+	// GetHookRun returns the hook run by ID
+	GetHookRun(id string) (*HookRun, error)
+	// GetHookRuns returns the hook runs matching the filter,
+	// most recent first
+	GetHookRuns(HookRunFilter) ([]HookRun, error)
+	// DeleteHookRun deletes the hook run by ID
+	DeleteHookRun(id string) error
+}
+
+// HookRun records a single execution of an application hook
+type HookRun struct {
+	// ID identifies the hook run. It is the name of the hook job
+	ID string `json:"id"`
+	// Namespace is the namespace of the hook job
+	Namespace string `json:"namespace"`
+	// Application is the application package the hook belongs to
+	Application loc.Locator `json:"application"`
+	// Hook is the hook type
+	Hook string `json:"hook"`
+	// State is the hook run state: running, completed or failed
+	State string `json:"state"`
+	// Started is the time the hook job has been created
+	Started time.Time `json:"started"`
+	// Finished is the time the hook job has completed or failed
+	Finished time.Time `json:"finished"`
+	// ExitCode is the exit code of the hook container
+	ExitCode int `json:"exit_code"`
+	// Error is the hook job failure message
+	Error string `json:"error,omitempty"`
+	// Logs are the captured hook job logs
+	Logs string `json:"logs,omitempty"`
+	// LogsTruncated is set if only the tail of the logs has been kept
+	LogsTruncated bool `json:"logs_truncated,omitempty"`
+}
+
+// Check validates this hook run
+func (r HookRun) Check() error {
+	if r.ID == "" {
+		return trace.BadParameter("missing hook run ID")
+	}
+	if r.Hook == "" {
+		return trace.BadParameter("missing hook type")
+	}
+	if r.Started.IsZero() {
+		return trace.BadParameter("missing hook start time")
+	}
+	switch r.State {
+	case HookRunRunning, HookRunCompleted, HookRunFailed:
+	default:
+		return trace.BadParameter("unknown hook run state %q", r.State)
+	}
+	return nil
+}
+
+// IsFinished returns true if the hook has completed or failed
+func (r HookRun) IsFinished() bool {
+	return r.State == HookRunCompleted || r.State == HookRunFailed
+}
+
+// Duration returns the duration of the finished hook run
+func (r HookRun) Duration() time.Duration {
+	if !r.IsFinished() || r.Finished.Before(r.Started) {
+		return 0
+	}
+	return r.Finished.Sub(r.Started)
+}
+
+// String returns a textual representation of this hook run
+func (r HookRun) String() string {
+	return fmt.Sprintf("hook(%v, app=%v, id=%v, state=%v)",
+		r.Hook, r.Application, r.ID, r.State)
+}
+
+// HookRunFilter selects the hook runs to return
+type HookRunFilter struct {
+	// Application optionally selects the runs of the application
+	// with the specified repository and name. If the version is set,
+	// only the runs of this version are selected
+	Application *loc.Locator `json:"application,omitempty"`
+	// Hook optionally selects the runs of the specified hook type
+	Hook string `json:"hook,omitempty"`
+	// Limit optionally limits the number of returned runs
+	Limit int `json:"limit,omitempty"`
+	// SkipLogs omits the hook job logs from the returned runs
+	SkipLogs bool `json:"skip_logs,omitempty"`
+}
+
+// Matches returns true if the hook run matches this filter
+func (f HookRunFilter) Matches(run HookRun) bool {
+	if f.Hook != "" && f.Hook != run.Hook {
+		return false
+	}
+	if f.Application != nil {
+		if f.Application.Repository != run.Application.Repository ||
+			f.Application.Name != run.Application.Name {
+			return false
+		}
+		if f.Application.Version != "" && f.Application.Version != run.Application.Version {
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	// HookRunRunning is the state of a hook that is running
+	HookRunRunning = "running"
+	// HookRunCompleted is the state of a hook that has completed successfully
+	HookRunCompleted = "completed"
+	// HookRunFailed is the state of a hook that has failed
+	HookRunFailed = "failed"
+)
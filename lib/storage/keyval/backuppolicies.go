@@ -0,0 +1,81 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+func (b *backend) UpsertBackupPolicy(policy storage.BackupPolicy) error {
+	if err := policy.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	bytes, err := storage.MarshalBackupPolicy(policy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = b.upsertValBytes(b.key(backupPoliciesP, policy.GetName()),
+		bytes, b.ttl(policy.Expiry()))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+func (b *backend) GetBackupPolicy(name string) (storage.BackupPolicy, error) {
+	bytes, err := b.getValBytes(b.key(backupPoliciesP, name))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("backup policy %q not found", name)
+		}
+		return nil, trace.Wrap(err)
+	}
+	policy, err := storage.UnmarshalBackupPolicy(bytes)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return policy, nil
+}
+
+func (b *backend) GetBackupPolicies() ([]storage.BackupPolicy, error) {
+	names, err := b.getKeys(b.key(backupPoliciesP))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var policies []storage.BackupPolicy
+	for _, name := range names {
+		policy, err := b.GetBackupPolicy(name)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+func (b *backend) DeleteBackupPolicy(name string) error {
+	err := b.deleteKey(b.key(backupPoliciesP, name))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("backup policy %q not found", name)
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
@@ -149,6 +149,14 @@ func (s *BSuite) TestOperationsCRUD(c *C) {
 	s.suite.OperationsCRUD(c)
 }
 
+func (s *BSuite) TestPhaseLogCRUD(c *C) {
+	s.suite.PhaseLogCRUD(c)
+}
+
+func (s *BSuite) TestWatch(c *C) {
+	s.suite.Watch(c)
+}
+
 func (s *BSuite) TestCreatesApplication(c *C) {
 	s.suite.CreatesApplication(c)
 }
@@ -240,3 +248,15 @@ func (s *BSuite) TestClusterLogin(c *C) {
 func (s *BSuite) TestIndexFile(c *C) {
 	s.suite.IndexFile(c)
 }
+
+func (s *BSuite) TestBackupPoliciesCRUD(c *C) {
+	s.suite.BackupPoliciesCRUD(c)
+}
+
+func (s *BSuite) TestHookRunsCRUD(c *C) {
+	s.suite.HookRunsCRUD(c)
+}
+
+func (s *BSuite) TestResourceRevisions(c *C) {
+	s.suite.ResourceRevisions(c)
+}
@@ -37,6 +37,7 @@ const (
 	operationsP                 = "ops"
 	appOperationsP              = "appops"
 	changelogP                  = "changelog"
+	phaseLogP                   = "phaselog"
 	activeOperationsP           = "activeops"
 	repositoriesP               = "repos"
 	packagesP                   = "packages"
@@ -77,6 +78,10 @@ const (
 	dnsP                        = "dns"
 	chartsP                     = "charts"
 	indexP                      = "index"
+	backupPoliciesP             = "backuppolicies"
+	hookRunsP                   = "hookruns"
+	hookRunLogsP                = "hookrunlogs"
+	resourceRevisionsP          = "resourcerevisions"
 
 	// AllCollectionIDs identifies a collection without a specification (an ID)
 	AllCollectionIDs = "__all__"
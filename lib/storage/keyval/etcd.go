@@ -26,6 +26,7 @@ import (
 
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
 
 	"github.com/cenkalti/backoff"
@@ -370,6 +371,19 @@ func (e *engine) getValBytes(key key) ([]byte, error) {
 	return e.codec.DecodeBytesFromString(re.Node.Value)
 }
 
+// getExpiry returns the expiration time of the specified key
+// or zero time if the key does not expire
+func (e *engine) getExpiry(key key) (time.Time, error) {
+	re, err := e.Get(context.TODO(), ekey(key), nil)
+	if err != nil {
+		return time.Time{}, convertErr(err)
+	}
+	if re.Node.Expiration == nil {
+		return time.Time{}, nil
+	}
+	return re.Node.Expiration.UTC(), nil
+}
+
 func (e *engine) getVal(key key, val interface{}) error {
 	re, err := e.Get(context.TODO(), ekey(key), nil)
 	if err != nil {
@@ -453,6 +467,90 @@ func (e *engine) getKeys(key key) ([]string, error) {
 	return vals, nil
 }
 
+// watch returns a watch of all keys under the specified directory.
+// The watch starts after the current etcd index so only the changes
+// made after the watch has been created are streamed
+func (e *engine) watch(dir key) (kvWatch, error) {
+	prefix := ekey(dir)
+	api := client.NewKeysAPI(e.client)
+	var index uint64
+	resp, err := api.Get(context.TODO(), prefix, nil)
+	switch etcdErr := err.(type) {
+	case nil:
+		index = resp.Index
+	case client.Error:
+		if etcdErr.Code != client.ErrorCodeKeyNotFound {
+			return nil, trace.Wrap(convertErr(err))
+		}
+		index = etcdErr.Index
+	default:
+		return nil, trace.Wrap(convertErr(err))
+	}
+	return func(ctx context.Context, fn func(kvEvent) error) error {
+		watcher := api.Watcher(prefix, &client.WatcherOptions{AfterIndex: index, Recursive: true})
+		for {
+			resp, err := watcher.Next(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				if etcdErr, ok := err.(client.Error); ok && etcdErr.Code == client.ErrorCodeEventIndexCleared {
+					// the watcher has fallen behind the retained event history,
+					// some changes are lost so resume from the current index
+					log.Warnf("Storage watch has fallen behind, resuming from index %v.", etcdErr.Index)
+					watcher = api.Watcher(prefix, &client.WatcherOptions{AfterIndex: etcdErr.Index, Recursive: true})
+					continue
+				}
+				return trace.Wrap(convertErr(err))
+			}
+			change, err := e.newKVEvent(prefix, resp)
+			if err != nil {
+				log.WithError(err).Warnf("Failed to decode change of %v.", resp.Node.Key)
+				continue
+			}
+			if change == nil {
+				continue
+			}
+			if err := fn(*change); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+	}, nil
+}
+
+// newKVEvent converts the etcd watch response to the key change.
+// Returns nil for changes of directories other than deletes
+func (e *engine) newKVEvent(prefix string, resp *client.Response) (*kvEvent, error) {
+	var path []string
+	for _, name := range strings.Split(strings.TrimPrefix(resp.Node.Key, prefix+"/"), "/") {
+		path = append(path, strings.Replace(name, "%2F", "/", -1))
+	}
+	change := kvEvent{path: path}
+	switch resp.Action {
+	case "delete", "compareAndDelete", "expire":
+		change.typ = storage.EventTypeDelete
+		if resp.PrevNode == nil || resp.PrevNode.Dir {
+			return &change, nil
+		}
+		value, err := e.codec.DecodeBytesFromString(resp.PrevNode.Value)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		change.value = value
+	default:
+		if resp.Node.Dir {
+			return nil, nil
+		}
+		change.typ = storage.EventTypePut
+		value, err := e.codec.DecodeBytesFromString(resp.Node.Value)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		change.value = value
+	}
+	return &change, nil
+}
+
 func convertErr(e error) error {
 	if e == nil {
 		return nil
@@ -173,6 +173,14 @@ func (s *ESuite) TestOperationsCRUD(c *C) {
 	s.suite.OperationsCRUD(c)
 }
 
+func (s *ESuite) TestPhaseLogCRUD(c *C) {
+	s.suite.PhaseLogCRUD(c)
+}
+
+func (s *ESuite) TestWatch(c *C) {
+	s.suite.Watch(c)
+}
+
 func (s *ESuite) TestCreatesApplication(c *C) {
 	s.suite.CreatesApplication(c)
 }
@@ -264,3 +272,15 @@ func (s *ESuite) TestClusterLogin(c *C) {
 func (s *ESuite) TestIndexFile(c *C) {
 	s.suite.IndexFile(c)
 }
+
+func (s *ESuite) TestBackupPoliciesCRUD(c *C) {
+	s.suite.BackupPoliciesCRUD(c)
+}
+
+func (s *ESuite) TestHookRunsCRUD(c *C) {
+	s.suite.HookRunsCRUD(c)
+}
+
+func (s *ESuite) TestResourceRevisions(c *C) {
+	s.suite.ResourceRevisions(c)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"sort"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertHookRun creates or updates the hook run record.
+// The logs are stored separately so the history can be listed
+// without reading them
+func (b *backend) UpsertHookRun(run storage.HookRun) error {
+	if err := run.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	logs := run.Logs
+	run.Logs = ""
+	err := b.upsertVal(b.key(hookRunsP, run.ID), run, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if logs == "" {
+		err := b.deleteKey(b.key(hookRunLogsP, run.ID))
+		if err != nil && !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+		return nil
+	}
+	err = b.upsertValBytes(b.key(hookRunLogsP, run.ID), []byte(logs), forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+func (b *backend) GetHookRun(id string) (*storage.HookRun, error) {
+	return b.getHookRun(id, true)
+}
+
+func (b *backend) GetHookRuns(filter storage.HookRunFilter) ([]storage.HookRun, error) {
+	ids, err := b.getKeys(b.key(hookRunsP))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var runs []storage.HookRun
+	for _, id := range ids {
+		run, err := b.getHookRun(id, !filter.SkipLogs)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		if filter.Matches(*run) {
+			runs = append(runs, *run)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Started.After(runs[j].Started)
+	})
+	if filter.Limit > 0 && len(runs) > filter.Limit {
+		runs = runs[:filter.Limit]
+	}
+	return runs, nil
+}
+
+func (b *backend) DeleteHookRun(id string) error {
+	err := b.deleteKey(b.key(hookRunsP, id))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("hook run %q not found", id)
+		}
+		return trace.Wrap(err)
+	}
+	err = b.deleteKey(b.key(hookRunLogsP, id))
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// getHookRun returns the hook run by ID, optionally with its logs
+func (b *backend) getHookRun(id string, withLogs bool) (*storage.HookRun, error) {
+	var run storage.HookRun
+	err := b.getVal(b.key(hookRunsP, id), &run)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("hook run %q not found", id)
+		}
+		return nil, trace.Wrap(err)
+	}
+	if !withLogs {
+		run.Logs = ""
+		return &run, nil
+	}
+	logs, err := b.getValBytes(b.key(hookRunLogsP, id))
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	// runs recorded before the logs have been stored separately
+	// keep the logs inline
+	if err == nil {
+		run.Logs = string(logs)
+	}
+	return &run, nil
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package keyval
 
 import (
+	"fmt"
 	"sort"
 
+	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
 
@@ -204,6 +206,91 @@ func (b *backend) GetOperationPlanChangelog(clusterName, operationID string) (st
 	return storage.PlanChangelog(out), nil
 }
 
+// CreateOperationPhaseLogEntries saves the log entries captured during
+// a single phase execution attempt as a single record.
+// Only the most recent records of the operation are kept, and they
+// are removed together with the operation
+func (b *backend) CreateOperationPhaseLogEntries(log storage.PhaseLog) error {
+	if len(log) == 0 {
+		return nil
+	}
+	clusterName, operationID := log[0].ClusterName, log[0].OperationID
+	if clusterName == "" || operationID == "" {
+		return trace.BadParameter("missing cluster name or operation ID")
+	}
+	entries := make(storage.PhaseLog, 0, len(log))
+	created := log[0].Created
+	for _, entry := range log {
+		if entry.ClusterName != clusterName || entry.OperationID != operationID {
+			return trace.BadParameter("all entries should belong to operation(%v, %v)",
+				clusterName, operationID)
+		}
+		if entry.ID == "" {
+			entry.ID = uuid.New()
+		}
+		if entry.Created.Before(created) {
+			created = entry.Created
+		}
+		entries = append(entries, entry)
+	}
+	if created.IsZero() {
+		created = b.Now().UTC()
+	}
+	// record IDs sort in the order the records have been logged
+	id := fmt.Sprintf("%019d-%v", created.UnixNano(), entries[0].ID)
+	err := b.upsertVal(b.key(
+		sitesP, clusterName, operationsP, operationID, phaseLogP, id, valP), entries, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(b.prunePhaseLog(clusterName, operationID))
+}
+
+// prunePhaseLog removes the oldest phase log records of the operation
+// beyond the maximum number of records
+func (b *backend) prunePhaseLog(clusterName, operationID string) error {
+	ids, err := b.getKeys(b.key(sitesP, clusterName, operationsP, operationID, phaseLogP))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(ids) <= defaults.PhaseLogMaxRecords {
+		return nil
+	}
+	sort.Strings(ids)
+	for _, id := range ids[:len(ids)-defaults.PhaseLogMaxRecords] {
+		err := b.deleteDir(b.key(sitesP, clusterName, operationsP, operationID, phaseLogP, id))
+		if err != nil && !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// GetOperationPhaseLog returns all phase log entries for an operation
+func (b *backend) GetOperationPhaseLog(clusterName, operationID string) (storage.PhaseLog, error) {
+	ids, err := b.getKeys(b.key(sitesP, clusterName, operationsP, operationID, phaseLogP))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var out storage.PhaseLog
+	for _, id := range ids {
+		var entries storage.PhaseLog
+		err = b.getVal(b.key(
+			sitesP, clusterName, operationsP, operationID, phaseLogP, id, valP), &entries)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		for _, entry := range entries {
+			utils.UTC(&entry.Created)
+			out = append(out, entry)
+		}
+	}
+	return out.ForPhase(""), nil
+}
+
 // CreateAppOperation creates a new application operation
 func (b *backend) CreateAppOperation(op storage.AppOperation) (*storage.AppOperation, error) {
 	err := op.Check()
@@ -0,0 +1,157 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// pgleader implements leader election on a PostgreSQL table.
+// The leader holds a lease on the election key that it renews
+// before the term expires
+type pgleader struct {
+	logrus.FieldLogger
+	db        *sql.DB
+	pauseC    chan struct{}
+	closeC    chan struct{}
+	closeOnce sync.Once
+}
+
+func newPostgresLeader(db *sql.DB) *pgleader {
+	return &pgleader{
+		FieldLogger: logrus.WithField(trace.Component, "postgres-leader"),
+		db:          db,
+		pauseC:      make(chan struct{}),
+		closeC:      make(chan struct{}),
+	}
+}
+
+// AddWatch starts watching the key for changes and sending them
+// to the valuesC channel.
+// The key is polled with the specified retry period
+func (l *pgleader) AddWatch(key string, retry time.Duration, valuesC chan string) {
+	go func() {
+		ticker := time.NewTicker(retry)
+		defer ticker.Stop()
+		var sent string
+		for {
+			value, err := l.getLeader(key)
+			if err != nil {
+				l.WithError(err).Debug("Failed to query leader.")
+			}
+			if err == nil && value != "" && value != sent {
+				select {
+				case valuesC <- value:
+					sent = value
+				case <-l.closeC:
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-l.closeC:
+				return
+			}
+		}
+	}()
+}
+
+// AddVoter adds a voter that tries to elect the given value
+// by setting the key to the value for the given term.
+// Once elected, the voter renews the lease until it steps down
+// or the context is cancelled
+func (l *pgleader) AddVoter(ctx context.Context, key, value string, term time.Duration) error {
+	if value == "" {
+		return trace.BadParameter("voter value for key cannot be empty")
+	}
+	if term < time.Second {
+		return trace.BadParameter("term cannot be < 1s")
+	}
+	go func() {
+		ticker := time.NewTicker(term / 5)
+		defer ticker.Stop()
+		for {
+			if err := l.elect(key, value, term); err != nil {
+				l.WithError(err).Debug("Voter error.")
+			}
+			select {
+			case <-l.pauseC:
+				l.Debug("Was asked to step down, pausing heartbeat.")
+				select {
+				case <-time.After(term * 2):
+				case <-l.closeC:
+					return
+				case <-ctx.Done():
+					return
+				}
+			case <-ticker.C:
+			case <-l.closeC:
+				return
+			case <-ctx.Done():
+				l.Debugf("Removing voter for %v.", value)
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// StepDown makes the voter pause its attempts to renew the lease
+// thus giving up its leadership
+func (l *pgleader) StepDown() {
+	select {
+	case l.pauseC <- struct{}{}:
+	case <-l.closeC:
+	}
+}
+
+// elect takes over the key if it has expired or renews
+// the lease if the key is already held by the value
+func (l *pgleader) elect(key, value string, term time.Duration) error {
+	_, err := l.db.Exec(`INSERT INTO gravity_leader (key, value, expires)
+VALUES ($1, $2, now() + $3::bigint * interval '1 microsecond')
+ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires = excluded.expires
+WHERE gravity_leader.value = excluded.value OR gravity_leader.expires <= now()`,
+		key, value, ttlMicroseconds(term))
+	return trace.Wrap(convertPostgresErr(err))
+}
+
+// getLeader returns the value of the current leader or an empty string
+// if there is no leader
+func (l *pgleader) getLeader(key string) (value string, err error) {
+	err = l.db.QueryRow(`SELECT value FROM gravity_leader WHERE key = $1 AND expires > now()`,
+		key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", trace.Wrap(convertPostgresErr(err))
+	}
+	return value, nil
+}
+
+func (l *pgleader) close() {
+	l.closeOnce.Do(func() {
+		close(l.closeC)
+	})
+}
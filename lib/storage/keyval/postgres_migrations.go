@@ -0,0 +1,154 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"database/sql"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// postgresMigration describes a single step of the database schema migration
+type postgresMigration struct {
+	// version is the schema version after the migration
+	version int
+	// description describes the migration
+	description string
+	// statements lists the SQL statements the migration consists of
+	statements []string
+}
+
+// postgresMigrations lists the database schema migrations in the order
+// of versions.
+// Migrations are only ever appended: once released, a migration
+// must not be modified
+var postgresMigrations = []postgresMigration{
+	{
+		version:     1,
+		description: "key/value table",
+		statements: []string{
+			`CREATE TABLE gravity_kv (
+	key TEXT PRIMARY KEY,
+	parent TEXT NOT NULL,
+	name TEXT NOT NULL,
+	dir BOOLEAN NOT NULL DEFAULT FALSE,
+	value BYTEA,
+	expires TIMESTAMPTZ
+)`,
+			`CREATE INDEX gravity_kv_parent_idx ON gravity_kv (parent)`,
+			`CREATE INDEX gravity_kv_expires_idx ON gravity_kv (expires) WHERE expires IS NOT NULL`,
+		},
+	},
+	{
+		version:     2,
+		description: "leader election table",
+		statements: []string{
+			`CREATE TABLE gravity_leader (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL,
+	expires TIMESTAMPTZ NOT NULL
+)`,
+		},
+	},
+	{
+		version:     3,
+		description: "key prefix index",
+		statements: []string{
+			// text_pattern_ops allows prefix LIKE queries to use the index
+			// independently of the database collation
+			`CREATE INDEX gravity_kv_key_prefix_idx ON gravity_kv (key text_pattern_ops)`,
+		},
+	},
+}
+
+// migratePostgres applies the migrations missing from the database.
+// Concurrent migrations from several processes are serialized with
+// an advisory lock.
+// Returns the resulting schema version
+func migratePostgres(db *sql.DB) (version int, err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, trace.Wrap(convertPostgresErr(err))
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	_, err = tx.Exec(`SELECT pg_advisory_xact_lock($1)`, postgresMigrationLockID)
+	if err != nil {
+		return 0, trace.Wrap(convertPostgresErr(err))
+	}
+	_, err = tx.Exec(`CREATE TABLE IF NOT EXISTS gravity_schema_migrations (
+	version INTEGER PRIMARY KEY,
+	description TEXT NOT NULL,
+	applied TIMESTAMPTZ NOT NULL DEFAULT now()
+)`)
+	if err != nil {
+		return 0, trace.Wrap(convertPostgresErr(err))
+	}
+	version, err = postgresSchemaVersion(tx)
+	if err != nil {
+		return 0, trace.Wrap(convertPostgresErr(err))
+	}
+	latest := postgresMigrations[len(postgresMigrations)-1].version
+	if version > latest {
+		return 0, trace.BadParameter("database schema version %v is newer than the supported version %v, "+
+			"was the database used by a newer version?", version, latest)
+	}
+	for _, migration := range postgresMigrations {
+		if migration.version <= version {
+			continue
+		}
+		log.Infof("Migrating database schema to version %v: %v.", migration.version, migration.description)
+		for _, statement := range migration.statements {
+			if _, err = tx.Exec(statement); err != nil {
+				return 0, trace.Wrap(convertPostgresErr(err),
+					"failed to migrate database schema to version %v", migration.version)
+			}
+		}
+		_, err = tx.Exec(`INSERT INTO gravity_schema_migrations (version, description) VALUES ($1, $2)`,
+			migration.version, migration.description)
+		if err != nil {
+			return 0, trace.Wrap(convertPostgresErr(err))
+		}
+		version = migration.version
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, trace.Wrap(convertPostgresErr(err))
+	}
+	return version, nil
+}
+
+// postgresSchemaVersion returns the version of the last applied migration
+func postgresSchemaVersion(q querier) (version int, err error) {
+	err = q.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM gravity_schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	return version, nil
+}
+
+// querier is implemented by both database and transaction
+type querier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// postgresMigrationLockID identifies the advisory lock that serializes
+// the schema migrations
+const postgresMigrationLockID = 0x67726176
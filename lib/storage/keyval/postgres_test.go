@@ -0,0 +1,333 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage/suite"
+
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+	. "gopkg.in/check.v1"
+)
+
+// PSuite runs the storage conformance suite against PostgreSQL.
+// The suite is enabled with PQ=true and PQ_URI set to the connection
+// string of the test database, e.g.
+//
+//	PQ=true PQ_URI=postgres://postgres@localhost/gravity?sslmode=disable
+type PSuite struct {
+	backend *tempPostgres
+	suite   suite.StorageSuite
+}
+
+var _ = Suite(&PSuite{})
+
+// tempPostgres helps to create and destroy ad-hoc
+// databases in PostgreSQL
+type tempPostgres struct {
+	key     string
+	clock   clockwork.FakeClock
+	backend *postgresBackend
+}
+
+func (t *tempPostgres) Delete() error {
+	_, err := t.backend.db.Exec(`DELETE FROM gravity_kv WHERE key = $1 OR key LIKE $2`,
+		t.key, descendantsPattern(t.key))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = t.backend.db.Exec(`DELETE FROM gravity_leader WHERE key LIKE $1`, descendantsPattern(t.key))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(t.backend.Close())
+}
+
+func newTempPostgres(uri string) (*tempPostgres, error) {
+	if uri == "" {
+		return nil, trace.BadParameter("missing PostgreSQL connection string")
+	}
+	token, err := teleutils.CryptoRandomHex(6)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	fakeClock := clockwork.NewFakeClock()
+	key := fmt.Sprintf("gravity-test/%v", token)
+	b, err := NewPostgres(PostgresConfig{
+		URI:   uri,
+		Key:   key,
+		Clock: fakeClock,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &tempPostgres{key: key, clock: fakeClock, backend: b}, nil
+}
+
+func (s *PSuite) SetUpTest(c *C) {
+	log.SetOutput(os.Stderr)
+
+	if ok, _ := strconv.ParseBool(os.Getenv(defaults.TestPostgres)); !ok {
+		c.Skip("Skipping test suite for PostgreSQL")
+		return
+	}
+
+	var err error
+	s.backend, err = newTempPostgres(os.Getenv(defaults.TestPostgresURI))
+	c.Assert(err, IsNil)
+
+	s.suite.Backend = s.backend.backend
+	s.suite.Clock = s.backend.clock
+}
+
+func (s *PSuite) TearDownTest(c *C) {
+	if s.backend != nil {
+		err := s.backend.Delete()
+		if err != nil {
+			log.Error(trace.DebugReport(err))
+		}
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *PSuite) TestSchemaVersion(c *C) {
+	version, err := s.backend.backend.SchemaVersion()
+	c.Assert(err, IsNil)
+	c.Assert(version, Equals, postgresMigrations[len(postgresMigrations)-1].version)
+
+	// migrating up-to-date schema is a no-op
+	version, err = migratePostgres(s.backend.backend.db)
+	c.Assert(err, IsNil)
+	c.Assert(version, Equals, postgresMigrations[len(postgresMigrations)-1].version)
+}
+
+func (s *PSuite) TestLeaderElection(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	key := s.backend.key + "/leader"
+	err := s.backend.backend.AddVoter(ctx, key, "node-1", time.Second)
+	c.Assert(err, IsNil)
+
+	valuesC := make(chan string, 1)
+	s.backend.backend.AddWatch(key, 100*time.Millisecond, valuesC)
+	select {
+	case value := <-valuesC:
+		c.Assert(value, Equals, "node-1")
+	case <-time.After(5 * time.Second):
+		c.Fatal("timeout waiting for leader")
+	}
+}
+
+func (s *PSuite) TestAccountsCRUD(c *C) {
+	s.suite.AccountsCRUD(c)
+}
+
+func (s *PSuite) TestRepositoriesCRUD(c *C) {
+	s.suite.RepositoriesCRUD(c)
+}
+
+func (s *PSuite) TestSitesCRUD(c *C) {
+	s.suite.SitesCRUD(c)
+}
+
+func (s *PSuite) TestProgressEntriesCRUD(c *C) {
+	s.suite.ProgressEntriesCRUD(c)
+}
+
+func (s *PSuite) TestConnectorsCRUD(c *C) {
+	s.suite.ConnectorsCRUD(c)
+}
+
+func (s *PSuite) TestUsersCRUD(c *C) {
+	s.suite.UsersCRUD(c)
+}
+
+func (s *PSuite) TestUserTokensCRUD(c *C) {
+	s.suite.UserTokensCRUD(c)
+}
+
+func (s *PSuite) TestProvisioningTokensCRUD(c *C) {
+	s.suite.ProvisioningTokensCRUD(c)
+}
+
+func (s *PSuite) TestAPIKeys(c *C) {
+	s.suite.APIKeysCRUD(c)
+}
+
+func (s *PSuite) TestUserInvites(c *C) {
+	s.suite.UserInvitesCRUD(c)
+}
+
+func (s *PSuite) TestLoginEntriesCRUD(c *C) {
+	s.suite.LoginEntriesCRUD(c)
+}
+
+func (s *PSuite) TestPermissionsCRUD(c *C) {
+	s.suite.PermissionsCRUD(c)
+}
+
+func (s *PSuite) TestOperationsCRUD(c *C) {
+	s.suite.OperationsCRUD(c)
+}
+
+func (s *PSuite) TestPhaseLogCRUD(c *C) {
+	s.suite.PhaseLogCRUD(c)
+}
+
+func (s *PSuite) TestWatch(c *C) {
+	s.suite.Watch(c)
+}
+
+func (s *PSuite) TestCreatesApplication(c *C) {
+	s.suite.CreatesApplication(c)
+}
+
+func (s *PSuite) TestDeletesApplication(c *C) {
+	s.suite.DeletesApplication(c)
+}
+
+func (s *PSuite) TestRetrievesApplications(c *C) {
+	s.suite.RetrievesApplications(c)
+}
+
+func (s *PSuite) TestCreatesAppImportOperation(c *C) {
+	s.suite.CreatesAppImportOperation(c)
+}
+
+func (s *PSuite) TestUpdatesAppImportOperation(c *C) {
+	s.suite.UpdatesAppImportOperation(c)
+}
+
+func (s *PSuite) TestConnectors(c *C) {
+	s.suite.ConnectorsCRUD(c)
+}
+
+func (s *PSuite) TestWebSessions(c *C) {
+	s.suite.WebSessionsCRUD(c)
+}
+
+func (s *PSuite) TestAuthoritiesCRUD(c *C) {
+	s.suite.AuthoritiesCRUD(c)
+}
+
+func (s *PSuite) TestNodesCRUD(c *C) {
+	s.suite.NodesCRUD(c)
+}
+
+func (s *PSuite) TestReverseTunnelsCRUD(c *C) {
+	s.suite.ReverseTunnelsCRUD(c)
+}
+
+func (s *PSuite) TestLocksCRUD(c *C) {
+	s.suite.LocksCRUD(c)
+}
+
+func (s *PSuite) TestPeersCRUD(c *C) {
+	s.suite.PeersCRUD(c)
+}
+
+func (s *PSuite) TestObjectsCRUD(c *C) {
+	s.suite.ObjectsCRUD(c)
+}
+
+func (s *PSuite) TestChangesetsCRUD(c *C) {
+	s.suite.ChangesetsCRUD(c)
+}
+
+func (s *PSuite) TestOpsCenterLinksCRUD(c *C) {
+	s.suite.OpsCenterLinksCRUD(c)
+}
+
+func (s *PSuite) TestRolesCRUD(c *C) {
+	s.suite.RolesCRUD(c)
+}
+
+func (s *PSuite) TestNamespacesCRUD(c *C) {
+	s.suite.NamespacesCRUD(c)
+}
+
+func (s *PSuite) TestLoginAttempts(c *C) {
+	s.suite.LoginAttempts(c)
+}
+
+func (s *PSuite) TestLocalCluster(c *C) {
+	s.suite.LocalCluster(c)
+}
+
+func (s *PSuite) TestSAMLCRUD(c *C) {
+	s.suite.SAMLCRUD(c)
+}
+
+func (s *PSuite) TestClusterAgentCreds(c *C) {
+	s.suite.ClusterAgentCreds(c)
+}
+
+func (s *PSuite) TestClusterLogin(c *C) {
+	s.suite.ClusterLogin(c)
+}
+
+func (s *PSuite) TestIndexFile(c *C) {
+	s.suite.IndexFile(c)
+}
+
+func (s *PSuite) TestBackupPoliciesCRUD(c *C) {
+	s.suite.BackupPoliciesCRUD(c)
+}
+
+func (s *PSuite) TestHookRunsCRUD(c *C) {
+	s.suite.HookRunsCRUD(c)
+}
+
+func (s *PSuite) TestResourceRevisions(c *C) {
+	s.suite.ResourceRevisions(c)
+}
+
+func (s *PSuite) TestExpiresKeysOnAccess(c *C) {
+	engine := s.backend.backend.kvengine.(*pgengine)
+	dir := engine.key("expiry", "dir")
+	c.Assert(engine.createDir(dir, time.Millisecond), IsNil)
+	c.Assert(engine.upsertVal(append(dir, "value"), "value", forever), IsNil)
+	time.Sleep(10 * time.Millisecond)
+
+	var value string
+	err := engine.getVal(append(dir, "value"), &value)
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+	keys, err := engine.getKeys(engine.key("expiry"))
+	c.Assert(err, IsNil)
+	c.Assert(keys, HasLen, 0)
+}
+
+func (s *PSuite) TestDeletesDirectoryWithPatternCharacters(c *C) {
+	engine := s.backend.backend.kvengine.(*pgengine)
+	c.Assert(engine.upsertVal(engine.key("pattern", "a_b", "value"), "value", forever), IsNil)
+	c.Assert(engine.upsertVal(engine.key("pattern", "aXb", "value"), "value", forever), IsNil)
+
+	c.Assert(engine.deleteDir(engine.key("pattern", "a_b")), IsNil)
+	keys, err := engine.getKeys(engine.key("pattern"))
+	c.Assert(err, IsNil)
+	c.Assert(keys, DeepEquals, []string{"aXb"})
+}
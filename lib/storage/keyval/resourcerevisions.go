@@ -0,0 +1,166 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+func (b *backend) CreateResourceRevision(revision storage.ResourceRevision) (*storage.ResourceRevision, error) {
+	if err := revision.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// revision numbers are allocated optimistically, retry if another
+	// revision of the same resource has been created concurrently
+	for i := 0; i < maxRevisionAttempts; i++ {
+		revisions, err := b.getRevisionNumbers(revision.Kind, revision.Name)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		revision.Revision = 1
+		if len(revisions) != 0 {
+			revision.Revision = revisions[len(revisions)-1] + 1
+		}
+		err = b.createVal(b.revisionKey(revision.Kind, revision.Name, revision.Revision), revision, forever)
+		if err == nil {
+			return &revision, nil
+		}
+		if !trace.IsAlreadyExists(err) {
+			return nil, trace.Wrap(err)
+		}
+	}
+	return nil, trace.CompareFailed("failed to record revision of %v/%v, too many concurrent changes",
+		revision.Kind, revision.Name)
+}
+
+func (b *backend) GetResourceRevision(kind, name string, revision int) (*storage.ResourceRevision, error) {
+	var out storage.ResourceRevision
+	err := b.getVal(b.revisionKey(kind, name, revision), &out)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("revision %v of %v %q not found", revision, kind, name)
+		}
+		return nil, trace.Wrap(err)
+	}
+	utils.UTC(&out.Created)
+	return &out, nil
+}
+
+func (b *backend) GetResourceRevisions(filter storage.ResourceRevisionFilter) ([]storage.ResourceRevision, error) {
+	if err := filter.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var names []string
+	if filter.Name != nil {
+		names = append(names, revisionName(*filter.Name))
+	} else {
+		var err error
+		names, err = b.getKeys(b.key(resourceRevisionsP, filter.Kind))
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	var out []storage.ResourceRevision
+	for _, name := range names {
+		if name == singletonRevisionName {
+			name = ""
+		}
+		revisions, err := b.getRevisionNumbers(filter.Kind, name)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, revision := range revisions {
+			item, err := b.GetResourceRevision(filter.Kind, name, revision)
+			if err != nil {
+				if trace.IsNotFound(err) {
+					continue
+				}
+				return nil, trace.Wrap(err)
+			}
+			out = append(out, *item)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Created.Equal(out[j].Created) {
+			return out[i].Revision > out[j].Revision
+		}
+		return out[i].Created.After(out[j].Created)
+	})
+	if filter.Limit > 0 && len(out) > filter.Limit {
+		out = out[:filter.Limit]
+	}
+	return out, nil
+}
+
+func (b *backend) DeleteResourceRevision(kind, name string, revision int) error {
+	err := b.deleteKey(b.revisionKey(kind, name, revision))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("revision %v of %v %q not found", revision, kind, name)
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// getRevisionNumbers returns the revision numbers of the specified
+// resource in ascending order
+func (b *backend) getRevisionNumbers(kind, name string) ([]int, error) {
+	keys, err := b.getKeys(b.key(resourceRevisionsP, kind, revisionName(name)))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	revisions := make([]int, 0, len(keys))
+	for _, key := range keys {
+		revision, err := strconv.Atoi(key)
+		if err != nil {
+			log.Warnf("Invalid resource revision key %v.", key)
+			continue
+		}
+		revisions = append(revisions, revision)
+	}
+	sort.Ints(revisions)
+	return revisions, nil
+}
+
+func (b *backend) revisionKey(kind, name string, revision int) key {
+	return b.key(resourceRevisionsP, kind, revisionName(name), strconv.Itoa(revision))
+}
+
+// revisionName returns the key of the resource with the specified name.
+// Resources the cluster can only have one of have no name
+func revisionName(name string) string {
+	if name == "" {
+		return singletonRevisionName
+	}
+	return name
+}
+
+const (
+	// singletonRevisionName is the key used for resources without a name
+	singletonRevisionName = "__singleton__"
+	// maxRevisionAttempts is the number of attempts to allocate
+	// a revision number
+	maxRevisionAttempts = 5
+)
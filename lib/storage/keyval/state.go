@@ -0,0 +1,469 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// StateArchiveVersion is the version of the storage state archive format
+const StateArchiveVersion = 1
+
+// StateArchive is the portable snapshot of the storage backend state.
+//
+// The archive is serialized as a gzip-compressed stream of JSON records,
+// one per line: the header, the items and the footer with the number
+// of items and their checksum
+type StateArchive struct {
+	// Header describes the archive
+	Header StateHeader
+	// Items lists the values of the backend
+	Items []StateItem
+}
+
+// StateHeader describes the state archive
+type StateHeader struct {
+	// Version is the archive format version
+	Version int `json:"version"`
+	// Created is the time the archive was created
+	Created time.Time `json:"created"`
+	// Backend is the type of the backend the archive was exported from
+	Backend string `json:"backend"`
+}
+
+// StateItem is a single value of the storage backend
+type StateItem struct {
+	// Key is the key of the value relative to the backend root
+	Key []string `json:"key"`
+	// Value is the raw value
+	Value []byte `json:"value"`
+	// Expires is the expiration time of the value.
+	// It is not set if the value does not expire
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// String returns the item key as a path
+func (r StateItem) String() string {
+	return strings.Join(r.Key, "/")
+}
+
+// StateFooter completes the state archive
+type StateFooter struct {
+	// Items is the number of items in the archive
+	Items int `json:"items"`
+	// SHA256 is the checksum of the item records
+	SHA256 string `json:"sha256"`
+}
+
+// stateRecord is a single record of the serialized state archive
+type stateRecord struct {
+	Header *StateHeader `json:"header,omitempty"`
+	Item   *StateItem   `json:"item,omitempty"`
+	Footer *StateFooter `json:"footer,omitempty"`
+}
+
+// ExportConfig defines the state export parameters
+type ExportConfig struct {
+	// ExcludeSecrets omits the collections with secrets, such as
+	// backup policies with backup encryption keys and object storage credentials
+	ExcludeSecrets bool
+}
+
+// ExportState walks all collections of the specified backend and writes
+// them to w as a state archive.
+// Locks and leader election keys are not exported.
+// Values are read one by one so the archive is not a consistent snapshot
+// of the backend: values written concurrently with the export may or may
+// not be included.
+// Returns the footer of the written archive
+func ExportState(backend storage.Backend, w io.Writer, config ExportConfig) (*StateFooter, error) {
+	b, err := unwrapBackend(backend)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	gz := gzip.NewWriter(w)
+	writer := &stateWriter{w: gz, hash: sha256.New()}
+	err = writer.write(stateRecord{Header: &StateHeader{
+		Version: StateArchiveVersion,
+		Created: b.Now().UTC(),
+		Backend: engineType(b.kvengine),
+	}}, false)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = walkState(b.kvengine, stateRoot(b.kvengine), nil, func(item StateItem) error {
+		if config.ExcludeSecrets && secretStateCollections[item.Key[0]] {
+			return nil
+		}
+		writer.items++
+		return trace.Wrap(writer.write(stateRecord{Item: &item}, true))
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	footer := StateFooter{
+		Items:  writer.items,
+		SHA256: hex.EncodeToString(writer.hash.Sum(nil)),
+	}
+	if err := writer.write(stateRecord{Footer: &footer}, false); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &footer, nil
+}
+
+// ReadStateArchive reads the state archive from r and validates it
+func ReadStateArchive(r io.Reader) (*StateArchive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, trace.BadParameter("invalid state archive: %v", err)
+	}
+	defer gz.Close()
+	reader := bufio.NewReader(gz)
+	hash := sha256.New()
+	var archive StateArchive
+	var header *StateHeader
+	var footer *StateFooter
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, trace.BadParameter("invalid state archive: %v", err)
+		}
+		if len(bytes.TrimSpace(line)) != 0 {
+			if footer != nil {
+				return nil, trace.BadParameter("invalid state archive: unexpected data after footer")
+			}
+			var record stateRecord
+			if err := json.Unmarshal(line, &record); err != nil {
+				return nil, trace.BadParameter("invalid state archive record: %v", err)
+			}
+			switch {
+			case record.Header != nil:
+				if header != nil {
+					return nil, trace.BadParameter("invalid state archive: duplicate header")
+				}
+				header = record.Header
+			case record.Item != nil:
+				if header == nil {
+					return nil, trace.BadParameter("invalid state archive: missing header")
+				}
+				if err := checkStateItem(*record.Item); err != nil {
+					return nil, trace.Wrap(err)
+				}
+				hash.Write(line)
+				archive.Items = append(archive.Items, *record.Item)
+			case record.Footer != nil:
+				footer = record.Footer
+			default:
+				return nil, trace.BadParameter("invalid state archive: unknown record %s", line)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if header == nil {
+		return nil, trace.BadParameter("invalid state archive: missing header")
+	}
+	if header.Version != StateArchiveVersion {
+		return nil, trace.BadParameter("unsupported state archive version %v, expected %v",
+			header.Version, StateArchiveVersion)
+	}
+	if footer == nil {
+		return nil, trace.BadParameter("state archive is truncated: missing footer")
+	}
+	if footer.Items != len(archive.Items) {
+		return nil, trace.BadParameter("state archive is corrupted: expected %v items, got %v",
+			footer.Items, len(archive.Items))
+	}
+	if checksum := hex.EncodeToString(hash.Sum(nil)); checksum != footer.SHA256 {
+		return nil, trace.BadParameter("state archive is corrupted: checksum mismatch")
+	}
+	archive.Header = *header
+	return &archive, nil
+}
+
+// ImportConfig defines the state import parameters
+type ImportConfig struct {
+	// Backend is the backend to import the state into
+	Backend storage.Backend
+	// Archive is the state to import
+	Archive StateArchive
+	// DryRun computes the import result without writing anything
+	DryRun bool
+	// Force overwrites conflicting items
+	Force bool
+}
+
+// ImportResult describes the result of the state import
+type ImportResult struct {
+	// Created lists the items missing from the backend
+	Created []string
+	// Conflicts lists the items that exist in the backend with a different value
+	Conflicts []string
+	// Unchanged is the number of items that exist in the backend with the same value
+	Unchanged int
+	// Expired lists the items that have expired since the export and are skipped
+	Expired []string
+}
+
+// ImportState imports the state archive into the backend.
+// Items missing from the backend are created, items with the same value
+// are left intact.
+// If any item exists in the backend with a different value, nothing is
+// imported and CompareFailed error is returned unless config.Force is set,
+// in which case the conflicting items are overwritten.
+// Items that expire keep their expiration time, expired items are skipped.
+// The import is not atomic: if it fails midway, the items written so far
+// are not rolled back
+func ImportState(config ImportConfig) (*ImportResult, error) {
+	b, err := unwrapBackend(config.Backend)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	root := stateRoot(b.kvengine)
+	var result ImportResult
+	var writes []StateItem
+	now := b.Now().UTC()
+	for _, item := range config.Archive.Items {
+		if item.Expires != nil && !item.Expires.After(now) {
+			result.Expired = append(result.Expired, item.String())
+			continue
+		}
+		existing, err := b.getValBytes(itemKey(b.kvengine, root, item))
+		switch {
+		case trace.IsNotFound(err):
+			result.Created = append(result.Created, item.String())
+			writes = append(writes, item)
+		case err != nil:
+			return nil, trace.Wrap(err, "failed to read %v", item)
+		case bytes.Equal(existing, item.Value):
+			result.Unchanged++
+		default:
+			result.Conflicts = append(result.Conflicts, item.String())
+			writes = append(writes, item)
+		}
+	}
+	if len(result.Conflicts) != 0 && !config.Force {
+		return &result, trace.CompareFailed("%v items conflict with the existing state",
+			len(result.Conflicts))
+	}
+	if config.DryRun {
+		return &result, nil
+	}
+	for _, item := range writes {
+		err := b.upsertValBytes(itemKey(b.kvengine, root, item), item.Value, item.ttl(now))
+		if err != nil {
+			return nil, trace.Wrap(err, "failed to import %v", item)
+		}
+	}
+	return &result, nil
+}
+
+// ttl returns the time to live of the item relative to now
+func (r StateItem) ttl(now time.Time) time.Duration {
+	if r.Expires == nil {
+		return forever
+	}
+	return r.Expires.Sub(now)
+}
+
+// expiryGetter is implemented by engines that support key expiration
+type expiryGetter interface {
+	// getExpiry returns the expiration time of the specified key
+	// or zero time if the key does not expire
+	getExpiry(key key) (time.Time, error)
+}
+
+type stateWriter struct {
+	w     io.Writer
+	hash  hash.Hash
+	items int
+}
+
+func (r *stateWriter) write(record stateRecord, hashed bool) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	data = append(data, '\n')
+	if hashed {
+		r.hash.Write(data)
+	}
+	_, err = r.w.Write(data)
+	return trace.Wrap(err)
+}
+
+// walkState invokes fn for each value under the specified directory
+func walkState(engine kvengine, dir key, path []string, fn func(StateItem) error) error {
+	names, err := engine.getKeys(dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, name := range names {
+		if len(path) == 0 && excludedStateCollections[name] {
+			continue
+		}
+		itemKey := append(append(key{}, dir...), name)
+		itemPath := append(append([]string{}, path...), unescapeKey(engine, name))
+		value, err := engine.getValBytes(itemKey)
+		switch {
+		case trace.IsBadParameter(err):
+			// the key is a directory
+			if err := walkState(engine, itemKey, itemPath, fn); err != nil {
+				return trace.Wrap(err)
+			}
+		case trace.IsNotFound(err):
+			// the key has been removed or has expired
+		case err != nil:
+			return trace.Wrap(err)
+		default:
+			item := StateItem{Key: itemPath, Value: value}
+			if err := getItemExpiry(engine, itemKey, &item); err != nil {
+				if trace.IsNotFound(err) {
+					continue
+				}
+				return trace.Wrap(err)
+			}
+			if err := fn(item); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+	}
+	return nil
+}
+
+// getItemExpiry sets the expiration time of the item if the engine
+// supports key expiration and the key expires
+func getItemExpiry(engine kvengine, key key, item *StateItem) error {
+	getter, ok := engine.(expiryGetter)
+	if !ok {
+		return nil
+	}
+	expires, err := getter.getExpiry(key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !expires.IsZero() {
+		item.Expires = &expires
+	}
+	return nil
+}
+
+func checkStateItem(item StateItem) error {
+	if len(item.Key) == 0 {
+		return trace.BadParameter("invalid state archive: item with empty key")
+	}
+	for _, name := range item.Key {
+		if name == "" || name == "." || name == ".." {
+			return trace.BadParameter("invalid state archive: invalid key %q", item)
+		}
+	}
+	if excludedStateCollections[item.Key[0]] {
+		return trace.BadParameter("invalid state archive: %q cannot be imported", item)
+	}
+	return nil
+}
+
+// stateRoot returns the root key all collections of the engine are stored under
+func stateRoot(engine kvengine) key {
+	root := engine.key("")
+	return root[:len(root)-1]
+}
+
+// itemKey returns the engine key of the specified item
+func itemKey(engine kvengine, root key, item StateItem) key {
+	out := append(key{}, root...)
+	for _, name := range item.Key {
+		out = append(out, escapeKey(engine, name))
+	}
+	return out
+}
+
+// escapeKey escapes the key name for engines that use slash as a key separator
+func escapeKey(kv kvengine, name string) string {
+	switch kv.(type) {
+	case *engine, *pgengine:
+		return strings.Replace(name, "/", "%2F", -1)
+	}
+	return name
+}
+
+// unescapeKey reverts escapeKey
+func unescapeKey(kv kvengine, name string) string {
+	switch kv.(type) {
+	case *engine, *pgengine:
+		return strings.Replace(name, "%2F", "/", -1)
+	}
+	return name
+}
+
+// engineType returns the type of the backend engine
+func engineType(kv kvengine) string {
+	switch kv.(type) {
+	case *engine:
+		return constants.ETCDBackend
+	case *pgengine:
+		return constants.PostgresBackend
+	default:
+		return constants.BoltBackend
+	}
+}
+
+// unwrapBackend returns the key/value backend implementation
+func unwrapBackend(b storage.Backend) (*backend, error) {
+	switch b := b.(type) {
+	case *backend:
+		return b, nil
+	case *electingBackend:
+		return unwrapBackend(b.Backend)
+	case *postgresBackend:
+		return b.backend, nil
+	}
+	return nil, trace.NotImplemented("backend %T does not support state export", b)
+}
+
+// excludedStateCollections lists the top-level collections
+// that are not part of the exported state
+var excludedStateCollections = map[string]bool{
+	// locks are only meaningful to the running processes
+	locksP: true,
+	// leader election key shares the root with the etcd backend
+	"leader": true,
+}
+
+// secretStateCollections lists the top-level collections with secrets
+// that are omitted from the exported state if requested
+var secretStateCollections = map[string]bool{
+	backupPoliciesP: true,
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+type StateSuite struct {
+	source *tempBolt
+	target *tempBolt
+}
+
+var _ = Suite(&StateSuite{})
+
+func (s *StateSuite) SetUpTest(c *C) {
+	var err error
+	s.source, err = newTempBolt()
+	c.Assert(err, IsNil)
+	s.target, err = newTempBolt()
+	c.Assert(err, IsNil)
+}
+
+func (s *StateSuite) TearDownTest(c *C) {
+	c.Assert(s.source.Delete(), IsNil)
+	c.Assert(s.target.Delete(), IsNil)
+}
+
+func (s *StateSuite) TestExportsAndImportsState(c *C) {
+	account := s.createState(c)
+	err := s.source.backend.AcquireLock("lock", time.Hour)
+	c.Assert(err, IsNil)
+
+	archive := s.export(c)
+	c.Assert(archive.Header.Backend, Equals, "bolt")
+	for _, item := range archive.Items {
+		c.Assert(item.Key[0], Not(Equals), locksP)
+	}
+
+	result, err := ImportState(ImportConfig{
+		Backend: s.target.backend,
+		Archive: *archive,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(result.Created, HasLen, len(archive.Items))
+	c.Assert(result.Conflicts, HasLen, 0)
+
+	out, err := s.target.backend.GetAccount(account.ID)
+	c.Assert(err, IsNil)
+	c.Assert(*out, DeepEquals, account)
+	sites, err := s.target.backend.GetSites(account.ID)
+	c.Assert(err, IsNil)
+	c.Assert(sites, HasLen, 1)
+
+	// importing the same state again is a no-op
+	result, err = ImportState(ImportConfig{
+		Backend: s.target.backend,
+		Archive: *archive,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(result.Created, HasLen, 0)
+	c.Assert(result.Unchanged, Equals, len(archive.Items))
+}
+
+func (s *StateSuite) TestReportsConflicts(c *C) {
+	account := s.createState(c)
+	archive := s.export(c)
+
+	_, err := s.target.backend.CreateAccount(storage.Account{ID: account.ID, Org: "other"})
+	c.Assert(err, IsNil)
+
+	result, err := ImportState(ImportConfig{
+		Backend: s.target.backend,
+		Archive: *archive,
+	})
+	c.Assert(trace.IsCompareFailed(err), Equals, true)
+	c.Assert(result.Conflicts, DeepEquals, []string{"accounts/" + account.ID + "/val"})
+	sites, err := s.target.backend.GetSites(account.ID)
+	c.Assert(err, IsNil)
+	c.Assert(sites, HasLen, 0, Commentf("nothing should be imported on conflict"))
+
+	result, err = ImportState(ImportConfig{
+		Backend: s.target.backend,
+		Archive: *archive,
+		Force:   true,
+		DryRun:  true,
+	})
+	c.Assert(err, IsNil)
+	out, err := s.target.backend.GetAccount(account.ID)
+	c.Assert(err, IsNil)
+	c.Assert(out.Org, Equals, "other", Commentf("dry run should not modify the state"))
+
+	_, err = ImportState(ImportConfig{
+		Backend: s.target.backend,
+		Archive: *archive,
+		Force:   true,
+	})
+	c.Assert(err, IsNil)
+	out, err = s.target.backend.GetAccount(account.ID)
+	c.Assert(err, IsNil)
+	c.Assert(*out, DeepEquals, account)
+}
+
+func (s *StateSuite) TestSkipsExpiredItems(c *C) {
+	past := s.target.clock.Now().UTC().Add(-time.Hour)
+	future := s.target.clock.Now().UTC().Add(time.Hour)
+	archive := StateArchive{
+		Header: StateHeader{Version: StateArchiveVersion},
+		Items: []StateItem{
+			{Key: []string{"tokens", "expired"}, Value: []byte("expired"), Expires: &past},
+			{Key: []string{"tokens", "active"}, Value: []byte("active"), Expires: &future},
+		},
+	}
+
+	result, err := ImportState(ImportConfig{
+		Backend: s.target.backend,
+		Archive: archive,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(result.Created, DeepEquals, []string{"tokens/active"})
+	c.Assert(result.Expired, DeepEquals, []string{"tokens/expired"})
+}
+
+func (s *StateSuite) TestExcludesSecrets(c *C) {
+	s.createState(c)
+	err := s.source.backend.UpsertBackupPolicy(storage.NewBackupPolicy("nightly", storage.BackupPolicySpecV2{
+		Schedule:      storage.BackupSchedule{Interval: teleservices.NewDuration(24 * time.Hour)},
+		Destination:   storage.BackupDestination{Path: "/var/backups"},
+		EncryptionKey: "secret",
+	}))
+	c.Assert(err, IsNil)
+
+	archive := s.export(c)
+	c.Assert(hasCollection(archive, backupPoliciesP), Equals, true)
+
+	var buf bytes.Buffer
+	_, err = ExportState(s.source.backend, &buf, ExportConfig{ExcludeSecrets: true})
+	c.Assert(err, IsNil)
+	archive, err = ReadStateArchive(&buf)
+	c.Assert(err, IsNil)
+	c.Assert(hasCollection(archive, backupPoliciesP), Equals, false)
+	c.Assert(hasCollection(archive, "accounts"), Equals, true)
+}
+
+func (s *StateSuite) TestRejectsCorruptedArchive(c *C) {
+	s.createState(c)
+	var buf bytes.Buffer
+	_, err := ExportState(s.source.backend, &buf, ExportConfig{})
+	c.Assert(err, IsNil)
+
+	gz, err := gzip.NewReader(&buf)
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(gz)
+	c.Assert(err, IsNil)
+
+	// drop the footer
+	lines := bytes.SplitAfter(bytes.TrimSpace(data), []byte("\n"))
+	truncated := bytes.Join(lines[:len(lines)-1], nil)
+	_, err = ReadStateArchive(compress(c, truncated))
+	c.Assert(trace.IsBadParameter(err), Equals, true)
+	c.Assert(err, ErrorMatches, ".*missing footer.*")
+
+	// tamper with an item
+	tampered := bytes.Replace(data, []byte(`"key":["accounts"`), []byte(`"key":["account"`), 1)
+	_, err = ReadStateArchive(compress(c, tampered))
+	c.Assert(err, ErrorMatches, ".*checksum mismatch.*")
+}
+
+func (s *StateSuite) createState(c *C) storage.Account {
+	account, err := s.source.backend.CreateAccount(storage.Account{Org: "example"})
+	c.Assert(err, IsNil)
+	_, err = s.source.backend.CreateSite(storage.Site{
+		AccountID: account.ID,
+		Domain:    "example.com",
+		Created:   time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+		App: storage.Package{
+			Repository: "example.com",
+			Name:       "app",
+			Version:    "0.0.1",
+		},
+	})
+	c.Assert(err, IsNil)
+	return *account
+}
+
+func (s *StateSuite) export(c *C) *StateArchive {
+	var buf bytes.Buffer
+	footer, err := ExportState(s.source.backend, &buf, ExportConfig{})
+	c.Assert(err, IsNil)
+	archive, err := ReadStateArchive(&buf)
+	c.Assert(err, IsNil)
+	c.Assert(archive.Items, HasLen, footer.Items)
+	return archive
+}
+
+func hasCollection(archive *StateArchive, collection string) bool {
+	for _, item := range archive.Items {
+		if item.Key[0] == collection {
+			return true
+		}
+	}
+	return false
+}
+
+func compress(c *C, data []byte) *bytes.Buffer {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write(data)
+	c.Assert(err, IsNil)
+	c.Assert(gz.Close(), IsNil)
+	return &buf
+}
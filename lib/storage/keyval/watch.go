@@ -0,0 +1,353 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// NewWatcher returns a new watcher for the storage changes matching the filter.
+//
+// Engines that support watches natively (etcd) stream the changes directly,
+// other engines are polled for changes of the watched collections
+func (b *backend) NewWatcher(ctx context.Context, filter storage.WatchFilter) (storage.Watcher, error) {
+	if err := filter.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var watch kvWatch
+	var err error
+	if engine, ok := b.kvengine.(nativeWatcher); ok {
+		watch, err = engine.watch(stateRoot(b.kvengine))
+	} else {
+		watch, err = b.pollWatch(filter, defaults.WatchPollInterval)
+	}
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	w := &watcher{
+		eventsC: make(chan storage.Event, defaults.WatchBufferSize),
+		doneC:   make(chan struct{}),
+		cancel:  cancel,
+	}
+	go func() {
+		err := watch(ctx, func(change kvEvent) error {
+			event, err := newStorageEvent(change)
+			if err != nil {
+				log.WithError(err).Warnf("Failed to decode change of %v.", change)
+				return nil
+			}
+			if event == nil || !filter.Matches(*event) {
+				return nil
+			}
+			select {
+			case w.eventsC <- *event:
+				return nil
+			case <-ctx.Done():
+				return trace.Wrap(ctx.Err())
+			}
+		})
+		if ctx.Err() != nil {
+			err = nil
+		}
+		w.closeWithError(err)
+	}()
+	return w, nil
+}
+
+// kvEvent describes the change of a single key
+type kvEvent struct {
+	// typ is the type of the change
+	typ storage.EventType
+	// path is the unescaped path of the key relative to the backend root
+	path []string
+	// value is the new value of the key or, for deletes, its last
+	// known value if available
+	value []byte
+}
+
+// String returns the changed key as a path
+func (r kvEvent) String() string {
+	return strings.Join(r.path, "/")
+}
+
+// kvWatch streams the key changes to fn until the context expires
+// or fn returns an error
+type kvWatch func(ctx context.Context, fn func(kvEvent) error) error
+
+// nativeWatcher is implemented by the engines that support watches
+type nativeWatcher interface {
+	// watch returns a watch of all keys under the specified directory.
+	// Only the changes made after the watch has been created are streamed
+	watch(dir key) (kvWatch, error)
+}
+
+// pollWatch returns a watch that detects the changes by polling
+// the collections selected by the filter with the specified interval
+func (b *backend) pollWatch(filter storage.WatchFilter, interval time.Duration) (kvWatch, error) {
+	prev, err := b.snapshot(filter, nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return func(ctx context.Context, fn func(kvEvent) error) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return nil
+			}
+			next, err := b.snapshot(filter, prev)
+			if err != nil {
+				log.WithError(err).Warn("Failed to poll storage for changes.")
+				continue
+			}
+			for _, change := range prev.diff(next) {
+				if err := fn(change); err != nil {
+					return trace.Wrap(err)
+				}
+			}
+			prev = next
+		}
+	}, nil
+}
+
+// kvSnapshot maps the keys of the watched collections to their values
+type kvSnapshot map[string]kvEvent
+
+// snapshot reads the current values of the collections selected by the filter.
+// Progress entries are never updated, so the values of the entries
+// already seen in the previous snapshot prev are not read again
+func (b *backend) snapshot(filter storage.WatchFilter, prev kvSnapshot) (kvSnapshot, error) {
+	snapshot := make(kvSnapshot)
+	if filter.HasKind(teleservices.KindClusterConfig) {
+		if err := snapshot.add(b, clusterConfigP, clusterConfigGeneralP); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	watchOperations := filter.HasKind(storage.KindOperation) || filter.HasKind(storage.KindProgressEntry)
+	if !filter.HasKind(storage.KindCluster) && !watchOperations {
+		return snapshot, nil
+	}
+	domains := []string{filter.SiteDomain}
+	if filter.SiteDomain == "" {
+		var err error
+		if domains, err = b.getNames(b.key(sitesP)); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	for _, domain := range domains {
+		if filter.HasKind(storage.KindCluster) {
+			if err := snapshot.add(b, sitesP, domain, valP); err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
+		if !watchOperations {
+			continue
+		}
+		operationIDs := []string{filter.OperationID}
+		if filter.OperationID == "" {
+			var err error
+			if operationIDs, err = b.getNames(b.key(sitesP, domain, operationsP)); err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
+		for _, operationID := range operationIDs {
+			if filter.HasKind(storage.KindOperation) {
+				if err := snapshot.add(b, sitesP, domain, operationsP, operationID, valP); err != nil {
+					return nil, trace.Wrap(err)
+				}
+			}
+			if !filter.HasKind(storage.KindProgressEntry) {
+				continue
+			}
+			ids, err := b.getNames(b.key(sitesP, domain, operationsP, operationID, progressP))
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			for _, id := range ids {
+				path := []string{sitesP, domain, operationsP, operationID, progressP, id}
+				if change, ok := prev[strings.Join(path, "/")]; ok {
+					snapshot[change.String()] = change
+					continue
+				}
+				if err := snapshot.add(b, path...); err != nil {
+					return nil, trace.Wrap(err)
+				}
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// add reads the value of the key with the specified path into the snapshot
+func (r kvSnapshot) add(b *backend, path ...string) error {
+	value, err := b.getValBytes(b.key(path[0], path[1:]...))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	change := kvEvent{typ: storage.EventTypePut, path: path, value: value}
+	r[change.String()] = change
+	return nil
+}
+
+// diff returns the changes between this and the next snapshot
+func (r kvSnapshot) diff(next kvSnapshot) (changes []kvEvent) {
+	for key, change := range next {
+		if prev, ok := r[key]; !ok || string(prev.value) != string(change.value) {
+			changes = append(changes, change)
+		}
+	}
+	for key, change := range r {
+		if _, ok := next[key]; !ok {
+			change.typ = storage.EventTypeDelete
+			changes = append(changes, change)
+		}
+	}
+	// parents are listed before their children
+	sort.Slice(changes, func(i, j int) bool {
+		if len(changes[i].path) != len(changes[j].path) {
+			return len(changes[i].path) < len(changes[j].path)
+		}
+		return changes[i].String() < changes[j].String()
+	})
+	return changes
+}
+
+// getNames returns the unescaped names of the keys in the specified directory
+func (b *backend) getNames(dir key) ([]string, error) {
+	names, err := b.getKeys(dir)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, trace.Wrap(err)
+	}
+	for i := range names {
+		names[i] = unescapeKey(b.kvengine, names[i])
+	}
+	return names, nil
+}
+
+// newStorageEvent converts the key change to the storage event.
+// Returns nil if the key does not belong to a watched collection
+func newStorageEvent(change kvEvent) (*storage.Event, error) {
+	path := change.path
+	event := storage.Event{Type: change.typ}
+	switch {
+	case len(path) == 2 && path[0] == clusterConfigP && path[1] == clusterConfigGeneralP:
+		event.Kind = teleservices.KindClusterConfig
+		event.ClusterConfig = json.RawMessage(change.value)
+	case len(path) < 2 || path[0] != sitesP:
+		return nil, nil
+	// deleting a cluster or an operation removes its directory
+	case len(path) == 2 && change.typ == storage.EventTypeDelete:
+		event.Kind = storage.KindCluster
+		event.Cluster = &storage.Site{Domain: path[1]}
+	case len(path) == 4 && path[2] == operationsP && change.typ == storage.EventTypeDelete:
+		event.Kind = storage.KindOperation
+		event.Operation = &storage.SiteOperation{SiteDomain: path[1], ID: path[3]}
+	case len(path) == 3 && path[2] == valP:
+		event.Kind = storage.KindCluster
+		event.Cluster = &storage.Site{Domain: path[1]}
+		if err := decodeValue(change.value, event.Cluster); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	case len(path) == 5 && path[2] == operationsP && path[4] == valP:
+		event.Kind = storage.KindOperation
+		event.Operation = &storage.SiteOperation{SiteDomain: path[1], ID: path[3]}
+		if err := decodeValue(change.value, event.Operation); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		utils.UTC(&event.Operation.Created)
+		utils.UTC(&event.Operation.Updated)
+	case len(path) == 6 && path[2] == operationsP && path[4] == progressP:
+		event.Kind = storage.KindProgressEntry
+		event.Progress = &storage.ProgressEntry{SiteDomain: path[1], OperationID: path[3], ID: path[5]}
+		if err := decodeValue(change.value, event.Progress); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	default:
+		return nil, nil
+	}
+	return &event, nil
+}
+
+// decodeValue decodes the value into out.
+// Empty values (of deleted keys) are ignored
+func decodeValue(value []byte, out interface{}) error {
+	if len(value) == 0 {
+		return nil
+	}
+	return trace.Wrap(json.Unmarshal(value, out))
+}
+
+type watcher struct {
+	eventsC chan storage.Event
+	doneC   chan struct{}
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	err     error
+}
+
+// Events returns the channel with the change events
+func (w *watcher) Events() <-chan storage.Event {
+	return w.eventsC
+}
+
+// Done returns the channel that is closed when the watcher is closed
+func (w *watcher) Done() <-chan struct{} {
+	return w.doneC
+}
+
+// Error returns the error the watcher has been closed with, if any
+func (w *watcher) Error() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close closes the watcher and waits for it to stop
+func (w *watcher) Close() error {
+	w.cancel()
+	<-w.doneC
+	return nil
+}
+
+func (w *watcher) closeWithError(err error) {
+	w.mu.Lock()
+	w.err = err
+	w.mu.Unlock()
+	close(w.doneC)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"gopkg.in/check.v1"
+)
+
+type LogForwarderSuite struct{}
+
+var _ = check.Suite(&LogForwarderSuite{})
+
+func (s *LogForwarderSuite) TestParsesLogForwarder(c *check.C) {
+	forwarder, err := GetLogForwarderMarshaler().Unmarshal([]byte(`kind: logforwarder
+version: v2
+metadata:
+  name: syslog
+spec:
+  address: 192.168.100.1:514
+  protocol: udp`))
+	c.Assert(err, check.IsNil)
+	c.Assert(forwarder.CheckAndSetDefaults(), check.IsNil)
+	c.Assert(forwarder.GetAddress(), check.Equals, "192.168.100.1:514")
+	c.Assert(forwarder.GetProtocol(), check.Equals, "udp")
+}
+
+func (s *LogForwarderSuite) TestValidatesLogForwarder(c *check.C) {
+	forwarder := NewLogForwarder("syslog", "127.0.0.1:514", "")
+	c.Assert(forwarder.CheckAndSetDefaults(), check.IsNil)
+	c.Assert(forwarder.GetProtocol(), check.Equals, "tcp")
+
+	forwarder = NewLogForwarder("syslog", "", "tcp")
+	c.Assert(forwarder.CheckAndSetDefaults(), check.NotNil, check.Commentf("missing address"))
+
+	forwarder = NewLogForwarder("syslog", "127.0.0.1:514", "http")
+	c.Assert(forwarder.CheckAndSetDefaults(), check.NotNil, check.Commentf("unsupported protocol"))
+}
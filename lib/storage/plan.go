@@ -17,6 +17,8 @@ limitations under the License.
 package storage
 
 import (
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gravitational/gravity/lib/loc"
@@ -83,6 +85,14 @@ type OperationPhase struct {
 	Parallel bool `json:"parallel"`
 	// Updated is the last phase update time
 	Updated time.Time `json:"updated,omitempty" yaml:"updated,omitempty"`
+	// Started is the time the last attempt to execute the phase has started
+	Started time.Time `json:"started,omitempty" yaml:"started,omitempty"`
+	// Finished is the time the last attempt to execute the phase has finished
+	Finished time.Time `json:"finished,omitempty" yaml:"finished,omitempty"`
+	// Attempts is the number of times the phase has been started
+	Attempts int `json:"attempts,omitempty" yaml:"attempts,omitempty"`
+	// Node is the name of the node the phase was last executed on
+	Node string `json:"node,omitempty" yaml:"node,omitempty"`
 	// Data is optional phase-specific data attached to the phase
 	Data *OperationPhaseData `json:"data,omitempty" yaml:"data,omitempty"`
 	// Error is the error that happened during phase execution
@@ -143,6 +153,9 @@ type ElectionChange struct {
 type GarbageCollectOperationData struct {
 	// RemoteApps lists remote applications known to cluster
 	RemoteApps []Application `json:"remote_apps,omitempty" yaml:"remote_apps,omitempty"`
+	// RegistryAllowlist lists additional docker images to retain in the registry
+	// as repository[:tag] patterns
+	RegistryAllowlist []string `json:"registry_allowlist,omitempty" yaml:"registry_allowlist,omitempty"`
 }
 
 // UpdateOperationData describes configuration for update operations
@@ -246,6 +259,8 @@ type PlanChange struct {
 	Created time.Time `json:"created"`
 	// Error is the error that happened during phase execution
 	Error *trace.RawTrace `json:"error"`
+	// Node is the name of the node the change has been made on
+	Node string `json:"node,omitempty"`
 }
 
 // PlanChangelog is a list of plan state changes
@@ -265,6 +280,46 @@ func (c PlanChangelog) Latest(phaseID string) *PlanChange {
 	return latest
 }
 
+// PhaseLogEntry is a single log entry captured during phase execution
+type PhaseLogEntry struct {
+	// ID is the entry ID
+	ID string `json:"id"`
+	// ClusterName is the name of the cluster for the operation
+	ClusterName string `json:"cluster_name"`
+	// OperationID is the ID of the operation the entry is for
+	OperationID string `json:"operation_id"`
+	// PhaseID is the ID of the phase that logged the entry
+	PhaseID string `json:"phase_id"`
+	// Node is the name of the node the phase was executed on
+	Node string `json:"node,omitempty"`
+	// Attempt is the phase execution attempt the entry was logged during
+	Attempt int `json:"attempt,omitempty"`
+	// Created is the entry timestamp
+	Created time.Time `json:"created"`
+	// Level is the entry log level
+	Level string `json:"level"`
+	// Message is the logged message
+	Message string `json:"message"`
+}
+
+// PhaseLog is a list of phase log entries
+type PhaseLog []PhaseLogEntry
+
+// ForPhase returns the entries logged by the specified phase or any
+// of its subphases sorted by time
+func (l PhaseLog) ForPhase(phaseID string) PhaseLog {
+	var result PhaseLog
+	for _, entry := range l {
+		if phaseID == "" || entry.PhaseID == phaseID || strings.HasPrefix(entry.PhaseID, phaseID+"/") {
+			result = append(result, entry)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Created.Before(result[j].Created)
+	})
+	return result
+}
+
 // HasSubphases returns true if the phase has 1 or more subphases
 func (p OperationPhase) HasSubphases() bool {
 	return len(p.Phases) > 0
@@ -309,6 +364,53 @@ func (p OperationPhase) GetLastUpdateTime() time.Time {
 	return last
 }
 
+// GetStarted returns the time the phase has started.
+// For a phase with subphases, this is the earliest time any
+// of its subphases has started
+func (p OperationPhase) GetStarted() time.Time {
+	if len(p.Phases) == 0 {
+		return p.Started
+	}
+	var started time.Time
+	for _, phase := range p.Phases {
+		phaseStarted := phase.GetStarted()
+		if !phaseStarted.IsZero() && (started.IsZero() || phaseStarted.Before(started)) {
+			started = phaseStarted
+		}
+	}
+	return started
+}
+
+// GetFinished returns the time the phase has finished.
+// For a phase with subphases, this is the latest time any of its
+// subphases has finished provided all of them have finished
+func (p OperationPhase) GetFinished() time.Time {
+	if len(p.Phases) == 0 {
+		return p.Finished
+	}
+	var finished time.Time
+	for _, phase := range p.Phases {
+		phaseFinished := phase.GetFinished()
+		if phaseFinished.IsZero() {
+			return time.Time{}
+		}
+		if phaseFinished.After(finished) {
+			finished = phaseFinished
+		}
+	}
+	return finished
+}
+
+// Duration returns the duration of the last phase execution attempt.
+// Returns 0 if the phase has not finished
+func (p OperationPhase) Duration() time.Duration {
+	started, finished := p.GetStarted(), p.GetFinished()
+	if started.IsZero() || finished.IsZero() || finished.Before(started) {
+		return 0
+	}
+	return finished.Sub(started)
+}
+
 // GetState returns the phase state based on the states of all its subphases
 func (p OperationPhase) GetState() string {
 	// if the phase doesn't have subphases, then just return its state from property
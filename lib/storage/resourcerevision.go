@@ -0,0 +1,113 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// ResourceRevisions stores the history of changes to cluster resources
+type ResourceRevisions interface {
+	// CreateResourceRevision records a new revision of the resource
+	// and returns it with the assigned revision number
+	CreateResourceRevision(ResourceRevision) (*ResourceRevision, error)
+	// GetResourceRevision returns the specified revision of the resource
+	GetResourceRevision(kind, name string, revision int) (*ResourceRevision, error)
+	// GetResourceRevisions returns the revisions matching the filter,
+	// most recent first
+	GetResourceRevisions(ResourceRevisionFilter) ([]ResourceRevision, error)
+	// DeleteResourceRevision deletes the specified revision of the resource
+	DeleteResourceRevision(kind, name string, revision int) error
+}
+
+// ResourceRevision records a single change to a cluster resource
+type ResourceRevision struct {
+	// Kind is the resource kind
+	Kind string `json:"kind"`
+	// Name is the resource name, empty for resources
+	// the cluster can only have one of
+	Name string `json:"name,omitempty"`
+	// Revision is the revision number, starting from 1
+	// for each resource
+	Revision int `json:"revision"`
+	// Action is the change action: created, updated or deleted
+	Action string `json:"action"`
+	// Author is the user who has made the change
+	Author string `json:"author"`
+	// Created is the time the change has been made
+	Created time.Time `json:"created"`
+	// Resource is the resource as it has been applied.
+	// For deletions, it is the last value of the resource if known
+	Resource json.RawMessage `json:"resource,omitempty"`
+}
+
+// Check validates this revision
+func (r ResourceRevision) Check() error {
+	if r.Kind == "" {
+		return trace.BadParameter("missing resource kind")
+	}
+	if r.Created.IsZero() {
+		return trace.BadParameter("missing revision time")
+	}
+	switch r.Action {
+	case ResourceCreated, ResourceUpdated:
+		if len(r.Resource) == 0 {
+			return trace.BadParameter("missing resource")
+		}
+	case ResourceDeleted:
+	default:
+		return trace.BadParameter("unknown resource action %q", r.Action)
+	}
+	return nil
+}
+
+// String returns a textual representation of this revision
+func (r ResourceRevision) String() string {
+	return fmt.Sprintf("revision(%v, kind=%v, name=%v, action=%v, author=%v)",
+		r.Revision, r.Kind, r.Name, r.Action, r.Author)
+}
+
+// ResourceRevisionFilter selects the resource revisions to return
+type ResourceRevisionFilter struct {
+	// Kind selects the revisions of the resources of this kind
+	Kind string `json:"kind"`
+	// Name optionally selects the revisions of the resource with this name
+	Name *string `json:"name,omitempty"`
+	// Limit optionally limits the number of returned revisions
+	Limit int `json:"limit,omitempty"`
+}
+
+// Check validates this filter
+func (f ResourceRevisionFilter) Check() error {
+	if f.Kind == "" {
+		return trace.BadParameter("missing resource kind")
+	}
+	return nil
+}
+
+const (
+	// ResourceCreated is the action of a revision that has created the resource
+	ResourceCreated = "created"
+	// ResourceUpdated is the action of a revision that has updated the resource
+	ResourceUpdated = "updated"
+	// ResourceDeleted is the action of a revision that has deleted the resource
+	ResourceDeleted = "deleted"
+)
@@ -75,6 +75,8 @@ const (
 	KindRelease = "release"
 	// KindInvite defines the user invite token.
 	KindInvite = "invite"
+	// KindBackupPolicy defines the cluster backup policy resource type
+	KindBackupPolicy = "backuppolicy"
 )
 
 // CanonicalKind translates the specified kind to canonical form.
@@ -109,6 +111,8 @@ func CanonicalKind(kind string) string {
 		return KindPersistentStorage
 	case KindAuthGateway, "gw":
 		return KindAuthGateway
+	case KindBackupPolicy, "backuppolicies", "backup":
+		return KindBackupPolicy
 	}
 	return kind
 }
@@ -176,6 +180,7 @@ var SupportedGravityResources = []string{
 	KindRuntimeEnvironment,
 	KindClusterConfiguration,
 	KindPersistentStorage,
+	KindBackupPolicy,
 }
 
 // SupportedGravityResourcesToRemove is a list of resources supported by
@@ -191,6 +196,7 @@ var SupportedGravityResourcesToRemove = []string{
 	KindTLSKeyPair,
 	KindRuntimeEnvironment,
 	KindClusterConfiguration,
+	KindBackupPolicy,
 }
 
 // MetadataSchema is a copy of teleport/lib/services.MetadataSchema but with
@@ -492,6 +492,11 @@ type SiteOperations interface {
 	CreateOperationPlanChange(PlanChange) (*PlanChange, error)
 	// GetOperationPlanChangelog returns all state transition entries for a plan
 	GetOperationPlanChangelog(clusterName, operationID string) (PlanChangelog, error)
+	// CreateOperationPhaseLogEntries saves the log entries captured during
+	// a single phase execution attempt
+	CreateOperationPhaseLogEntries(PhaseLog) error
+	// GetOperationPhaseLog returns all phase log entries for an operation
+	GetOperationPhaseLog(clusterName, operationID string) (PhaseLog, error)
 }
 
 // Reason details the reason a site is in a particular state
@@ -1488,6 +1493,10 @@ type Backend interface {
 	LegacyRoles
 	SystemMetadata
 	Charts
+	Events
+	BackupPolicies
+	HookRuns
+	ResourceRevisions
 }
 
 const (
@@ -19,6 +19,7 @@ limitations under the License.
 package suite
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"time"
@@ -992,6 +993,63 @@ func (s *StorageSuite) OperationsCRUD(c *C) {
 	})
 }
 
+func (s *StorageSuite) PhaseLogCRUD(c *C) {
+	now := time.Date(2015, 11, 16, 1, 2, 3, 0, time.UTC)
+	entries := []storage.PhaseLogEntry{
+		{
+			ClusterName: "a.example.com",
+			OperationID: "op1",
+			PhaseID:     "/masters/node-1/drain",
+			Node:        "node-1",
+			Attempt:     1,
+			Created:     now.Add(time.Second),
+			Level:       "info",
+			Message:     "Drain node-1.",
+		},
+		{
+			ClusterName: "a.example.com",
+			OperationID: "op1",
+			PhaseID:     "/init",
+			Node:        "node-1",
+			Attempt:     1,
+			Created:     now,
+			Level:       "info",
+			Message:     "Initialize operation.",
+		},
+	}
+	c.Assert(s.Backend.CreateOperationPhaseLogEntries(entries), IsNil)
+
+	log, err := s.Backend.GetOperationPhaseLog("a.example.com", "op1")
+	c.Assert(err, IsNil)
+	c.Assert(log, HasLen, 2)
+	c.Assert(log[0].ID, Not(Equals), "")
+	entries[0].ID, entries[1].ID = log[1].ID, log[0].ID
+	c.Assert(log, DeepEquals, storage.PhaseLog{entries[1], entries[0]})
+	c.Assert(log.ForPhase("/masters"), DeepEquals, storage.PhaseLog{entries[0]})
+
+	log, err = s.Backend.GetOperationPhaseLog("a.example.com", "op2")
+	c.Assert(err, IsNil)
+	c.Assert(log, HasLen, 0)
+
+	// only the most recent records are kept
+	for i := 0; i < defaults.PhaseLogMaxRecords; i++ {
+		entry := entries[0]
+		entry.ID = fmt.Sprintf("entry-%v", i)
+		entry.Created = now.Add(time.Duration(i+2) * time.Second)
+		c.Assert(s.Backend.CreateOperationPhaseLogEntries(storage.PhaseLog{entry}), IsNil)
+	}
+	log, err = s.Backend.GetOperationPhaseLog("a.example.com", "op1")
+	c.Assert(err, IsNil)
+	c.Assert(log, HasLen, defaults.PhaseLogMaxRecords)
+	c.Assert(log[0].ID, Equals, "entry-0")
+
+	// the records are removed with the operation
+	c.Assert(s.Backend.DeleteSiteOperation("a.example.com", "op1"), IsNil)
+	log, err = s.Backend.GetOperationPhaseLog("a.example.com", "op1")
+	c.Assert(err, IsNil)
+	c.Assert(log, HasLen, 0)
+}
+
 func (s *StorageSuite) LoginEntriesCRUD(c *C) {
 	// Create
 	entry := storage.LoginEntry{
@@ -1973,3 +2031,321 @@ func addToIndex(indexFile repo.IndexFile, name, version string) (updated, previo
 	}}
 	return indexCopy, &indexFile
 }
+
+func (s *StorageSuite) Watch(c *C) {
+	a, err := s.Backend.CreateAccount(storage.Account{Org: "test"})
+	c.Assert(err, IsNil)
+	repo, err := s.Backend.CreateRepository(storage.NewRepository("example.com"))
+	c.Assert(err, IsNil)
+	app, err := s.Backend.CreatePackage(storage.Package{
+		Repository: repo.GetName(),
+		Name:       "app",
+		Version:    "0.0.1",
+		Manifest:   []byte("1"),
+		Type:       string(storage.AppUser),
+	})
+	c.Assert(err, IsNil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watcher, err := s.Backend.NewWatcher(ctx, storage.WatchFilter{SiteDomain: "a.example.com"})
+	c.Assert(err, IsNil)
+	defer watcher.Close()
+
+	// changes of other clusters are filtered out
+	_, err = s.Backend.CreateSite(storage.Site{Created: now, AccountID: a.ID, Domain: "b.example.com", App: *app})
+	c.Assert(err, IsNil)
+	site, err := s.Backend.CreateSite(storage.Site{Created: now, AccountID: a.ID, Domain: "a.example.com", App: *app})
+	c.Assert(err, IsNil)
+	event := expectEvent(c, watcher)
+	c.Assert(event.Type, Equals, storage.EventTypePut)
+	c.Assert(event.Kind, Equals, storage.KindCluster)
+	c.Assert(event.Cluster.Domain, Equals, site.Domain)
+
+	op, err := s.Backend.CreateSiteOperation(storage.SiteOperation{
+		AccountID:  a.ID,
+		SiteDomain: site.Domain,
+		Type:       "test",
+		Created:    now,
+		Updated:    now,
+		State:      "new",
+	})
+	c.Assert(err, IsNil)
+	event = expectEvent(c, watcher)
+	c.Assert(event.Kind, Equals, storage.KindOperation)
+	c.Assert(*event.Operation, DeepEquals, *op)
+
+	progress, err := s.Backend.CreateProgressEntry(storage.ProgressEntry{
+		SiteDomain:  site.Domain,
+		OperationID: op.ID,
+		Created:     now,
+		Completion:  10,
+		State:       "in_progress",
+		Message:     "setting up load balancers",
+	})
+	c.Assert(err, IsNil)
+	event = expectEvent(c, watcher)
+	c.Assert(event.Kind, Equals, storage.KindProgressEntry)
+	c.Assert(*event.Progress, DeepEquals, *progress)
+
+	op.State = "completed"
+	op, err = s.Backend.UpdateSiteOperation(*op)
+	c.Assert(err, IsNil)
+	event = expectEvent(c, watcher)
+	c.Assert(event.Type, Equals, storage.EventTypePut)
+	c.Assert(event.Operation.State, Equals, "completed")
+
+	err = s.Backend.DeleteSiteOperation(site.Domain, op.ID)
+	c.Assert(err, IsNil)
+	event = expectEvent(c, watcher)
+	c.Assert(event.Type, Equals, storage.EventTypeDelete)
+	c.Assert(event.Kind, Equals, storage.KindOperation)
+	c.Assert(event.Operation.ID, Equals, op.ID)
+
+	c.Assert(watcher.Close(), IsNil)
+	select {
+	case <-watcher.Done():
+	default:
+		c.Fatal("watcher is not closed")
+	}
+	c.Assert(watcher.Error(), IsNil)
+}
+
+func expectEvent(c *C, watcher storage.Watcher) storage.Event {
+	select {
+	case event := <-watcher.Events():
+		return event
+	case <-watcher.Done():
+		c.Fatalf("watcher closed: %v", watcher.Error())
+	case <-time.After(10 * time.Second):
+		c.Fatal("timeout waiting for event")
+	}
+	return storage.Event{}
+}
+
+// BackupPoliciesCRUD tests backup policies operations
+func (s *StorageSuite) BackupPoliciesCRUD(c *C) {
+	out, err := s.Backend.GetBackupPolicies()
+	c.Assert(err, IsNil)
+	c.Assert(len(out), Equals, 0)
+
+	_, err = s.Backend.GetBackupPolicy("daily")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	policy := storage.NewBackupPolicy("daily", storage.BackupPolicySpecV2{
+		Schedule: storage.BackupSchedule{Interval: teleservices.NewDuration(24 * time.Hour)},
+		Retention: storage.BackupRetention{
+			Count:  7,
+			MaxAge: teleservices.NewDuration(30 * 24 * time.Hour),
+		},
+		Destination:   storage.BackupDestination{Path: "/var/backups"},
+		EncryptionKey: "secret",
+	})
+	c.Assert(s.Backend.UpsertBackupPolicy(policy), IsNil)
+
+	out, err = s.Backend.GetBackupPolicies()
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, []storage.BackupPolicy{policy})
+
+	policy.(*storage.BackupPolicyV2).Spec.Destination = storage.BackupDestination{
+		S3: &storage.S3Destination{Bucket: "backups", Prefix: "cluster"},
+	}
+	c.Assert(s.Backend.UpsertBackupPolicy(policy), IsNil)
+
+	fetched, err := s.Backend.GetBackupPolicy("daily")
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, fetched, policy)
+
+	invalid := storage.NewBackupPolicy("invalid", storage.BackupPolicySpecV2{
+		Schedule: storage.BackupSchedule{Interval: teleservices.NewDuration(time.Minute)},
+	})
+	c.Assert(trace.IsBadParameter(s.Backend.UpsertBackupPolicy(invalid)), Equals, true)
+
+	c.Assert(s.Backend.DeleteBackupPolicy("daily"), IsNil)
+	c.Assert(trace.IsNotFound(s.Backend.DeleteBackupPolicy("daily")), Equals, true)
+
+	out, err = s.Backend.GetBackupPolicies()
+	c.Assert(err, IsNil)
+	c.Assert(len(out), Equals, 0)
+}
+
+// HookRunsCRUD tests hook runs operations
+func (s *StorageSuite) HookRunsCRUD(c *C) {
+	out, err := s.Backend.GetHookRuns(storage.HookRunFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(len(out), Equals, 0)
+
+	_, err = s.Backend.GetHookRun("install-1")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	now := s.Clock.Now().UTC()
+	install := storage.HookRun{
+		ID:          "install-1",
+		Namespace:   "kube-system",
+		Application: loc.MustParseLocator("gravitational.io/app:0.0.1"),
+		Hook:        "install",
+		State:       storage.HookRunRunning,
+		Started:     now,
+	}
+	c.Assert(s.Backend.UpsertHookRun(install), IsNil)
+	update := storage.HookRun{
+		ID:          "update-1",
+		Namespace:   "kube-system",
+		Application: loc.MustParseLocator("gravitational.io/app:0.0.2"),
+		Hook:        "update",
+		State:       storage.HookRunFailed,
+		Started:     now.Add(time.Hour),
+		Finished:    now.Add(time.Hour + time.Minute),
+		ExitCode:    1,
+		Error:       "BackoffLimitExceeded",
+		Logs:        "update failed\n",
+	}
+	c.Assert(s.Backend.UpsertHookRun(update), IsNil)
+
+	install.State = storage.HookRunCompleted
+	install.Finished = now.Add(time.Minute)
+	install.Logs = "installed\n"
+	c.Assert(s.Backend.UpsertHookRun(install), IsNil)
+
+	fetched, err := s.Backend.GetHookRun("install-1")
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, fetched, &install)
+	c.Assert(fetched.Duration(), Equals, time.Minute)
+
+	out, err = s.Backend.GetHookRuns(storage.HookRunFilter{})
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, []storage.HookRun{update, install})
+
+	out, err = s.Backend.GetHookRuns(storage.HookRunFilter{Limit: 1})
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, []storage.HookRun{update})
+
+	out, err = s.Backend.GetHookRuns(storage.HookRunFilter{Hook: "update", SkipLogs: true})
+	c.Assert(err, IsNil)
+	c.Assert(out, HasLen, 1)
+	c.Assert(out[0].Logs, Equals, "")
+
+	out, err = s.Backend.GetHookRuns(storage.HookRunFilter{Hook: "install"})
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, []storage.HookRun{install})
+
+	application := loc.MustParseLocator("gravitational.io/app:0.0.1")
+	out, err = s.Backend.GetHookRuns(storage.HookRunFilter{Application: &application})
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, []storage.HookRun{install})
+
+	application.Version = ""
+	out, err = s.Backend.GetHookRuns(storage.HookRunFilter{Application: &application})
+	c.Assert(err, IsNil)
+	c.Assert(out, HasLen, 2)
+
+	c.Assert(trace.IsBadParameter(s.Backend.UpsertHookRun(storage.HookRun{ID: "invalid"})), Equals, true)
+
+	c.Assert(s.Backend.DeleteHookRun("install-1"), IsNil)
+	c.Assert(trace.IsNotFound(s.Backend.DeleteHookRun("install-1")), Equals, true)
+
+	out, err = s.Backend.GetHookRuns(storage.HookRunFilter{})
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, []storage.HookRun{update})
+}
+
+// ResourceRevisions tests resource revisions operations
+func (s *StorageSuite) ResourceRevisions(c *C) {
+	out, err := s.Backend.GetResourceRevisions(storage.ResourceRevisionFilter{Kind: "logforwarder"})
+	c.Assert(err, IsNil)
+	c.Assert(len(out), Equals, 0)
+
+	now := s.Clock.Now().UTC()
+	created, err := s.Backend.CreateResourceRevision(storage.ResourceRevision{
+		Kind:     "logforwarder",
+		Name:     "forwarder1",
+		Action:   storage.ResourceCreated,
+		Author:   "alice@example.com",
+		Created:  now,
+		Resource: []byte(`{"kind":"logforwarder"}`),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(created.Revision, Equals, 1)
+
+	updated, err := s.Backend.CreateResourceRevision(storage.ResourceRevision{
+		Kind:     "logforwarder",
+		Name:     "forwarder1",
+		Action:   storage.ResourceUpdated,
+		Author:   "bob@example.com",
+		Created:  now.Add(time.Minute),
+		Resource: []byte(`{"kind":"logforwarder","version":"v2"}`),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(updated.Revision, Equals, 2)
+
+	other, err := s.Backend.CreateResourceRevision(storage.ResourceRevision{
+		Kind:     "logforwarder",
+		Name:     "forwarder2",
+		Action:   storage.ResourceDeleted,
+		Author:   "alice@example.com",
+		Created:  now.Add(2 * time.Minute),
+		Resource: []byte(`{"kind":"logforwarder"}`),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(other.Revision, Equals, 1)
+
+	config, err := s.Backend.CreateResourceRevision(storage.ResourceRevision{
+		Kind:     "clusterconfiguration",
+		Action:   storage.ResourceCreated,
+		Author:   "alice@example.com",
+		Created:  now,
+		Resource: []byte(`{"kind":"clusterconfiguration"}`),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(config.Revision, Equals, 1)
+
+	fetched, err := s.Backend.GetResourceRevision("logforwarder", "forwarder1", 2)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, fetched, updated)
+
+	_, err = s.Backend.GetResourceRevision("logforwarder", "forwarder1", 3)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	out, err = s.Backend.GetResourceRevisions(storage.ResourceRevisionFilter{Kind: "logforwarder"})
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, []storage.ResourceRevision{*other, *updated, *created})
+
+	name := "forwarder1"
+	out, err = s.Backend.GetResourceRevisions(storage.ResourceRevisionFilter{Kind: "logforwarder", Name: &name, Limit: 1})
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, []storage.ResourceRevision{*updated})
+
+	name = ""
+	out, err = s.Backend.GetResourceRevisions(storage.ResourceRevisionFilter{Kind: "clusterconfiguration", Name: &name})
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, []storage.ResourceRevision{*config})
+
+	err = s.Backend.DeleteResourceRevision("logforwarder", "forwarder1", 1)
+	c.Assert(err, IsNil)
+	err = s.Backend.DeleteResourceRevision("logforwarder", "forwarder1", 1)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	name = "forwarder1"
+	out, err = s.Backend.GetResourceRevisions(storage.ResourceRevisionFilter{Kind: "logforwarder", Name: &name})
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, []storage.ResourceRevision{*updated})
+
+	// revision numbers are not reused after older revisions are deleted
+	next, err := s.Backend.CreateResourceRevision(storage.ResourceRevision{
+		Kind:     "logforwarder",
+		Name:     "forwarder1",
+		Action:   storage.ResourceUpdated,
+		Created:  now.Add(3 * time.Minute),
+		Resource: []byte(`{"kind":"logforwarder"}`),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(next.Revision, Equals, 3)
+
+	_, err = s.Backend.CreateResourceRevision(storage.ResourceRevision{
+		Kind:    "logforwarder",
+		Name:    "forwarder1",
+		Action:  storage.ResourceUpdated,
+		Created: now,
+	})
+	c.Assert(trace.IsBadParameter(err), Equals, true)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gravitational/gravity/lib/utils"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+)
+
+// Events watches the changes of the storage state
+type Events interface {
+	// NewWatcher returns a new watcher that receives the changes
+	// matching the specified filter.
+	// The watcher is closed when the context expires
+	NewWatcher(ctx context.Context, filter WatchFilter) (Watcher, error)
+}
+
+// Watcher receives the storage change events
+type Watcher interface {
+	// Events returns the channel with the change events
+	Events() <-chan Event
+	// Done returns the channel that is closed when the watcher is closed
+	Done() <-chan struct{}
+	// Error returns the error the watcher has been closed with, if any
+	Error() error
+	// Close closes the watcher and releases its resources
+	Close() error
+}
+
+// EventType defines the type of the change event
+type EventType string
+
+const (
+	// EventTypePut is emitted when a resource is created or updated
+	EventTypePut EventType = "put"
+	// EventTypeDelete is emitted when a resource is deleted
+	EventTypeDelete EventType = "delete"
+)
+
+const (
+	// KindOperation identifies the operation change events
+	KindOperation = "operation"
+	// KindProgressEntry identifies the operation progress change events
+	KindProgressEntry = "progress"
+)
+
+// WatchKinds lists the kinds of resources that can be watched
+var WatchKinds = []string{
+	KindCluster,
+	KindOperation,
+	KindProgressEntry,
+	teleservices.KindClusterConfig,
+}
+
+// Event describes a single change of the storage state
+type Event struct {
+	// Type is the type of the change
+	Type EventType `json:"type"`
+	// Kind is the kind of the changed resource
+	Kind string `json:"kind"`
+	// Cluster is set for the cluster events
+	Cluster *Site `json:"cluster,omitempty"`
+	// Operation is set for the operation events
+	Operation *SiteOperation `json:"operation,omitempty"`
+	// Progress is set for the progress entry events
+	Progress *ProgressEntry `json:"progress,omitempty"`
+	// ClusterConfig is the serialized cluster configuration,
+	// set for the cluster configuration events
+	ClusterConfig json.RawMessage `json:"cluster_config,omitempty"`
+}
+
+// WatchFilter limits the set of events received by a watcher
+type WatchFilter struct {
+	// Kinds lists the kinds of resources to watch.
+	// All kinds are watched if unspecified
+	Kinds []string `json:"kinds,omitempty"`
+	// SiteDomain limits the events to the specified cluster
+	SiteDomain string `json:"site_domain,omitempty"`
+	// OperationID limits the operation and progress events
+	// to the specified operation
+	OperationID string `json:"operation_id,omitempty"`
+}
+
+// Check makes sure the filter is valid
+func (f WatchFilter) Check() error {
+	for _, kind := range f.Kinds {
+		if !utils.StringInSlice(WatchKinds, kind) {
+			return trace.BadParameter("unsupported watch kind %q, supported are: %v",
+				kind, WatchKinds)
+		}
+	}
+	return nil
+}
+
+// HasKind returns true if the filter selects the specified kind
+func (f WatchFilter) HasKind(kind string) bool {
+	return len(f.Kinds) == 0 || utils.StringInSlice(f.Kinds, kind)
+}
+
+// Matches returns true if the event is selected by the filter
+func (f WatchFilter) Matches(event Event) bool {
+	if !f.HasKind(event.Kind) {
+		return false
+	}
+	switch {
+	case event.Cluster != nil:
+		return f.SiteDomain == "" || f.SiteDomain == event.Cluster.Domain
+	case event.Operation != nil:
+		return (f.SiteDomain == "" || f.SiteDomain == event.Operation.SiteDomain) &&
+			(f.OperationID == "" || f.OperationID == event.Operation.ID)
+	case event.Progress != nil:
+		return (f.SiteDomain == "" || f.SiteDomain == event.Progress.SiteDomain) &&
+			(f.OperationID == "" || f.OperationID == event.Progress.OperationID)
+	}
+	return true
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 )
 
 // S3 is the mocked S3 API client
@@ -149,6 +150,34 @@ func (s *S3) ListObjectsV2(input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Outpu
 	}, nil
 }
 
+func (s *S3) ListObjectsV2WithContext(ctx aws.Context, input *s3.ListObjectsV2Input, options ...request.Option) (*s3.ListObjectsV2Output, error) {
+	return s.ListObjectsV2(input)
+}
+
+func (s *S3) PutObjectWithContext(ctx aws.Context, input *s3.PutObjectInput, options ...request.Option) (*s3.PutObjectOutput, error) {
+	data, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	s.Objects[aws.StringValue(input.Key)] = S3Object{Data: data, Created: time.Now()}
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (s *S3) DeleteObjectWithContext(ctx aws.Context, input *s3.DeleteObjectInput, options ...request.Option) (*s3.DeleteObjectOutput, error) {
+	delete(s.Objects, aws.StringValue(input.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+// UploadWithContext implements the S3 upload manager interface
+func (s *S3) UploadWithContext(ctx aws.Context, input *s3manager.UploadInput, options ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	data, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	s.Objects[aws.StringValue(input.Key)] = S3Object{Data: data, Created: time.Now()}
+	return &s3manager.UploadOutput{}, nil
+}
+
 func (s *S3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
 	return s.GetObjectWithContext(context.TODO(), input)
 }
@@ -32,7 +32,7 @@ import (
 )
 
 // AutomaticUpgrade starts automatic upgrade process
-func AutomaticUpgrade(ctx context.Context, localEnv, updateEnv *localenv.LocalEnvironment) (err error) {
+func AutomaticUpgrade(ctx context.Context, localEnv, updateEnv *localenv.LocalEnvironment, parallelPhases int) (err error) {
 	clusterEnv, err := localEnv.NewClusterEnvironment()
 	if err != nil {
 		return trace.Wrap(err)
@@ -49,12 +49,13 @@ func AutomaticUpgrade(ctx context.Context, localEnv, updateEnv *localenv.LocalEn
 
 	config := Config{
 		Config: update.Config{
-			Operation:    (*ops.SiteOperation)(operation),
-			Operator:     clusterEnv.Operator,
-			Backend:      clusterEnv.Backend,
-			LocalBackend: updateEnv.Backend,
-			Runner:       runner,
-			Silent:       localEnv.Silent,
+			Operation:      (*ops.SiteOperation)(operation),
+			Operator:       clusterEnv.Operator,
+			Backend:        clusterEnv.Backend,
+			LocalBackend:   updateEnv.Backend,
+			Runner:         runner,
+			Silent:         localEnv.Silent,
+			ParallelPhases: parallelPhases,
 		},
 		HostLocalBackend:  localEnv.Backend,
 		HostLocalPackages: localEnv.Packages,
@@ -101,9 +101,10 @@ func newMachine(ctx context.Context, c Config) (*fsm.FSM, error) {
 		return nil, trace.Wrap(err)
 	}
 	fsm, err := fsm.New(fsm.Config{
-		Engine: engine,
-		Logger: logger,
-		Runner: c.Runner,
+		Engine:         engine,
+		Logger:         logger,
+		Runner:         c.Runner,
+		ParallelPhases: c.ParallelPhases,
 	})
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -288,6 +289,7 @@ func (f *engine) ChangePhaseState(ctx context.Context, change fsm.StateChange) e
 		NewState:    change.State,
 		Error:       utils.ToRawTrace(change.Error),
 		Created:     time.Now().UTC(),
+		Node:        change.Node,
 	})
 	if err != nil {
 		f.WithError(err).Warnf("Error recording phase state change %+v.", change)
@@ -302,6 +304,11 @@ func (f *engine) ChangePhaseState(ctx context.Context, change fsm.StateChange) e
 	return nil
 }
 
+// RecordPhaseLog saves the log entries captured during phase execution
+func (f *engine) RecordPhaseLog(ctx context.Context, log storage.PhaseLog) error {
+	return trace.Wrap(fsm.RecordPhaseLog(f.LocalBackend, log))
+}
+
 func (f *engine) reconcilePlan(ctx context.Context) error {
 	plan, err := f.reconciler.ReconcilePlan(ctx, f.plan)
 	if err != nil {
@@ -94,7 +94,8 @@ func fsmSpec(c Config) fsm.FSMSpecFunc {
 
 		logger := &fsm.Logger{
 			FieldLogger: log.WithFields(log.Fields{
-				constants.FieldPhase: p.Phase.ID,
+				constants.FieldPhase:       p.Phase.ID,
+				constants.FieldOperationID: p.Plan.OperationID,
 			}),
 			Key:      fsm.OperationKey(p.Plan),
 			Operator: c.Operator,
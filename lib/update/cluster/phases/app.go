@@ -85,6 +85,10 @@ func (p *updatePhaseApp) Execute(ctx context.Context) error {
 	} else {
 		err = p.runHooks(ctx, schema.HookNetworkUpdate, schema.HookUpdate, schema.HookUpdated)
 	}
+	if err != nil && app.HookFailurePolicy(err) == schema.HookFailureRollback {
+		// the rollback hooks are run when the plan is rolled back
+		p.Warnf("Hook failure policy requests rollback of %v: %v.", p.Package, err)
+	}
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -100,6 +104,16 @@ func (p *updatePhaseApp) Rollback(ctx context.Context) error {
 	return nil
 }
 
+// DryRun describes the hooks this phase would run or the bootstrap
+// resources it would create
+func (p *updatePhaseApp) DryRun(context.Context) ([]fsm.Action, error) {
+	if p.Package.Name == constants.BootstrapConfigPackage {
+		return []fsm.Action{fsm.NewAction(fsm.ActionUpdateObject,
+			"create bootstrap resources from %v", p.Package)}, nil
+	}
+	return p.describeHooks(schema.HookNetworkUpdate, schema.HookUpdate, schema.HookUpdated)
+}
+
 func (p *updatePhaseApp) createBootstrapResources() error {
 	reader, err := p.Apps.GetAppResources(p.Package)
 	if err != nil {
@@ -159,6 +173,11 @@ func (p *updatePhaseBeforeApp) Rollback(context.Context) error {
 	return nil
 }
 
+// DryRun describes the pre-update hook this phase would run
+func (p *updatePhaseBeforeApp) DryRun(context.Context) ([]fsm.Action, error) {
+	return p.describeHooks(schema.HookBeforeUpdate)
+}
+
 type phaseApp struct {
 	// Apps is the cluster apps service
 	Apps app.Applications
@@ -214,6 +233,11 @@ func (p *phaseApp) runHooks(ctx context.Context, hooks ...schema.HookType) error
 		defer writer.Close()
 		go streamHook(hook, reader, p.FieldLogger)
 		_, err = app.StreamAppHook(ctx, p.Apps, req, writer)
+		if err != nil && app.HookFailurePolicy(err) == schema.HookFailureContinue {
+			p.Warnf("Ignoring failure of %v(%v) hook as requested by its failure policy: %v.",
+				p.Package, hook, err)
+			continue
+		}
 		if err != nil {
 			return trace.Wrap(err, "%v(%v) hook failed", p.Package, hook)
 		}
@@ -221,6 +245,26 @@ func (p *phaseApp) runHooks(ctx context.Context, hooks ...schema.HookType) error
 	return nil
 }
 
+// describeHooks returns the actions for the specified hooks
+// the application package has
+func (p *phaseApp) describeHooks(hooks ...schema.HookType) (actions []fsm.Action, err error) {
+	for _, hook := range hooks {
+		_, err := app.CheckHasAppHook(p.Apps, app.HookRunRequest{
+			Application: p.Package,
+			Hook:        hook,
+		})
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		actions = append(actions, fsm.NewAction(fsm.ActionRunHook,
+			"run %v hook for %v", hook, p.Package))
+	}
+	return actions, nil
+}
+
 func streamHook(hook schema.HookType, reader io.ReadCloser, logger log.FieldLogger) {
 	defer reader.Close()
 	scanner := bufio.NewScanner(reader)
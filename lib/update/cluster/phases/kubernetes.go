@@ -70,6 +70,12 @@ func (p *phaseTaint) Rollback(ctx context.Context) error {
 	return nil
 }
 
+// DryRun describes the taint this phase would add to the node
+func (p *phaseTaint) DryRun(context.Context) ([]fsm.Action, error) {
+	return []fsm.Action{fsm.NewAction(fsm.ActionUpdateObject,
+		"add taint %v=%v to node %v", defaults.RunLevelLabel, defaults.RunLevelSystem, p.Server.KubeNodeID())}, nil
+}
+
 // phaseUntaint defines the operation of removing a taint from the node
 type phaseUntaint struct {
 	kubernetesOperation
@@ -102,6 +108,12 @@ func (p *phaseUntaint) Rollback(context.Context) error {
 	return nil
 }
 
+// DryRun describes the taint this phase would remove from the node
+func (p *phaseUntaint) DryRun(context.Context) ([]fsm.Action, error) {
+	return []fsm.Action{fsm.NewAction(fsm.ActionUpdateObject,
+		"remove taint %v=%v from node %v", defaults.RunLevelLabel, defaults.RunLevelSystem, p.Server.KubeNodeID())}, nil
+}
+
 // phaseDrain defines the operation of draining a node
 type phaseDrain struct {
 	kubernetesOperation
@@ -136,6 +148,12 @@ func (p *phaseDrain) Rollback(ctx context.Context) error {
 	return trace.Wrap(err)
 }
 
+// DryRun describes the node this phase would drain
+func (p *phaseDrain) DryRun(context.Context) ([]fsm.Action, error) {
+	return []fsm.Action{fsm.NewAction(fsm.ActionDrainNode,
+		"drain node %v", p.Server.KubeNodeID())}, nil
+}
+
 // phaseKubeletPermissions defines the operation to bootstrap additional permissions for kubelet.
 // This is necessary for a master node that is upgraded first and needs to update node status (via patch)
 // on an older api server.
@@ -166,6 +184,12 @@ func (p *phaseKubeletPermissions) Rollback(context.Context) error {
 	return trace.Wrap(removeKubeletPermissions(p.Client))
 }
 
+// DryRun describes the RBAC objects this phase would create
+func (p *phaseKubeletPermissions) DryRun(context.Context) ([]fsm.Action, error) {
+	return []fsm.Action{fsm.NewAction(fsm.ActionUpdateObject,
+		"create kubelet cluster role and cluster role binding")}, nil
+}
+
 // phaseUncordon defines the operation of uncordoning a node
 type phaseUncordon struct {
 	kubernetesOperation
@@ -195,6 +219,12 @@ func (p *phaseUncordon) Rollback(context.Context) error {
 	return nil
 }
 
+// DryRun describes the node this phase would uncordon
+func (p *phaseUncordon) DryRun(context.Context) ([]fsm.Action, error) {
+	return []fsm.Action{fsm.NewAction(fsm.ActionUpdateObject,
+		"uncordon node %v", p.Server.KubeNodeID())}, nil
+}
+
 // phaseEndpoints defines the operation waiting for DNS/cluster endpoints after
 // a node has been drained
 type phaseEndpoints struct {
@@ -264,6 +294,11 @@ func (p *kubernetesOperation) PostCheck(context.Context) error {
 	return nil
 }
 
+// RetryPolicy retries the phase on transient API server and etcd errors
+func (*kubernetesOperation) RetryPolicy() fsm.RetryPolicy {
+	return fsm.TransientRetryPolicy()
+}
+
 func taint(ctx context.Context, client corev1.NodeInterface, node string, add addTaint) error {
 	taint := v1.Taint{
 		Key:    defaults.RunLevelLabel,
@@ -175,6 +175,42 @@ func (p *updatePhaseSystem) getInstalledConfigPackage(loc loc.Locator) (*loc.Loc
 	return configPackage, nil
 }
 
+// DryRun describes the packages this phase would update on the node
+// and the services it would restart as a result
+func (p *updatePhaseSystem) DryRun(context.Context) ([]fsm.Action, error) {
+	actions := []fsm.Action{
+		fsm.NewAction(fsm.ActionPullPackage, "pull package %v", p.GravityPackage),
+		fsm.NewAction(fsm.ActionInstallPackage, "install gravity binary from %v", p.GravityPackage),
+	}
+	if p.Server.Runtime.Update != nil {
+		actions = append(actions,
+			fsm.NewAction(fsm.ActionPullPackage, "pull package %v", p.Server.Runtime.Update.Package),
+			fsm.NewAction(fsm.ActionPullPackage, "pull package %v", p.Server.Runtime.Update.ConfigPackage),
+			fsm.NewAction(fsm.ActionInstallPackage, "update runtime package %v to %v",
+				p.Server.Runtime.Installed, p.Server.Runtime.Update.Package),
+			fsm.NewAction(fsm.ActionRestartService, "restart runtime service %v",
+				p.Server.Runtime.Update.Package))
+	}
+	if p.Server.Teleport.Update != nil {
+		actions = append(actions,
+			fsm.NewAction(fsm.ActionPullPackage, "pull package %v", p.Server.Teleport.Update.Package))
+		if p.Server.Teleport.Update.NodeConfigPackage != nil {
+			actions = append(actions, fsm.NewAction(fsm.ActionPullPackage, "pull package %v",
+				*p.Server.Teleport.Update.NodeConfigPackage))
+		}
+		actions = append(actions,
+			fsm.NewAction(fsm.ActionInstallPackage, "update teleport package %v to %v",
+				p.Server.Teleport.Installed, p.Server.Teleport.Update.Package),
+			fsm.NewAction(fsm.ActionRestartService, "restart teleport service %v",
+				p.Server.Teleport.Update.Package))
+	}
+	if p.Server.Runtime.SecretsPackage != nil {
+		actions = append(actions, fsm.NewAction(fsm.ActionInstallPackage,
+			"install runtime secrets package %v", *p.Server.Runtime.SecretsPackage))
+	}
+	return actions, nil
+}
+
 // Rollback runs rolls back the system upgrade on the node
 func (p *updatePhaseSystem) Rollback(ctx context.Context) error {
 	updater, err := system.New(system.Config{
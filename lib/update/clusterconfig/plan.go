@@ -119,5 +119,8 @@ func shouldUpdateNodes(clusterConfig clusterconfig.Interface, numNodes int) bool
 	if config := clusterConfig.GetGlobalConfig(); config != nil && len(config.FeatureGates) != 0 {
 		hasComponentUpdate = true
 	}
-	return (clusterConfig.GetKubeletConfig() != nil || hasComponentUpdate) && numNodes != 0
+	if clusterConfig.GetComponentConfigs().HasNodeComponents() {
+		hasComponentUpdate = true
+	}
+	return hasComponentUpdate && numNodes != 0
 }
@@ -720,3 +720,12 @@ var testOperator = testRotator{
 type testRotator struct {
 	runtimeConfigPackage loc.Locator
 }
+
+func (S) TestUpdatesNodesForNodeComponents(c *C) {
+	config := clusterconfig.NewEmpty()
+	c.Assert(shouldUpdateNodes(config, 1), Equals, false)
+
+	config.Spec.Etcd = &clusterconfig.Etcd{ExtraArgs: []string{"--heartbeat-interval=500"}}
+	c.Assert(shouldUpdateNodes(config, 1), Equals, true)
+	c.Assert(shouldUpdateNodes(config, 0), Equals, false)
+}
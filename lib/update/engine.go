@@ -40,8 +40,9 @@ func NewMachine(ctx context.Context, config Config, engine *Engine) (*fsm.FSM, e
 		return nil, trace.Wrap(err)
 	}
 	machine, err := fsm.New(fsm.Config{
-		Engine: engine,
-		Runner: config.Runner,
+		Engine:         engine,
+		Runner:         config.Runner,
+		ParallelPhases: config.ParallelPhases,
 	})
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -149,6 +150,7 @@ func (r *Engine) ChangePhaseState(ctx context.Context, change fsm.StateChange) e
 		NewState:    change.State,
 		Error:       utils.ToRawTrace(change.Error),
 		Created:     time.Now().UTC(),
+		Node:        change.Node,
 	})
 	if err != nil {
 		return trace.Wrap(err)
@@ -161,6 +163,11 @@ func (r *Engine) ChangePhaseState(ctx context.Context, change fsm.StateChange) e
 	return nil
 }
 
+// RecordPhaseLog saves the log entries captured during phase execution
+func (r *Engine) RecordPhaseLog(ctx context.Context, log storage.PhaseLog) error {
+	return trace.Wrap(fsm.RecordPhaseLog(r.LocalBackend, log))
+}
+
 // RunCommand executes the phase specified by params on the specified server
 // using the provided runner
 func (r *Engine) RunCommand(ctx context.Context, runner rpc.RemoteRunner, server storage.Server, params fsm.Params) error {
@@ -174,6 +181,19 @@ func (r *Engine) RunCommand(ctx context.Context, runner rpc.RemoteRunner, server
 	return runner.Run(ctx, server, args...)
 }
 
+// RunRollbackCommand rolls back the phase specified by params on the specified server
+// using the provided runner
+func (r *Engine) RunRollbackCommand(ctx context.Context, runner rpc.RemoteRunner, server storage.Server, params fsm.Params) error {
+	args := []string{"plan", "rollback",
+		"--phase", params.PhaseID,
+		"--operation-id", r.Operation.ID,
+	}
+	if params.Force {
+		args = append(args, "--force")
+	}
+	return runner.Run(ctx, server, args...)
+}
+
 // GetPlan returns the most up-to-date operation plan
 func (r *Engine) GetPlan() (*storage.OperationPlan, error) {
 	return &r.plan, nil
@@ -18,6 +18,7 @@ package phases
 
 import (
 	"context"
+	"fmt"
 	"io"
 
 	"github.com/gravitational/gravity/lib/app"
@@ -109,6 +110,21 @@ func (r *updateConfig) Rollback(context.Context) error {
 	return trace.Wrap(err)
 }
 
+// DryRun describes the configuration packages this phase would generate
+// and the cluster environment update
+func (r *updateConfig) DryRun(context.Context) (actions []libfsm.Action, err error) {
+	for _, update := range r.updates {
+		actions = append(actions, libfsm.Action{
+			Node: update.Hostname,
+			Kind: libfsm.ActionInstallPackage,
+			Description: fmt.Sprintf("generate runtime configuration package %v",
+				update.Runtime.Update.ConfigPackage),
+		})
+	}
+	return append(actions, libfsm.NewAction(libfsm.ActionUpdateObject,
+		"update cluster environment variables")), nil
+}
+
 // PreCheck is a no-op
 func (r *updateConfig) PreCheck(context.Context) error {
 	return nil
@@ -67,7 +67,8 @@ type Dispatcher interface {
 func (r updateDispatcher) Dispatch(params fsm.ExecutorParams, remote fsm.Remote) (fsm.PhaseExecutor, error) {
 	logger := &fsm.Logger{
 		FieldLogger: log.WithFields(log.Fields{
-			constants.FieldPhase: params.Phase.ID,
+			constants.FieldPhase:       params.Phase.ID,
+			constants.FieldOperationID: params.Plan.OperationID,
 		}),
 		Key:      params.Key(),
 		Operator: r.Operator,
@@ -64,6 +64,12 @@ func (p *tainter) Rollback(ctx context.Context) error {
 	return nil
 }
 
+// DryRun describes the taint this phase would add to the node
+func (p *tainter) DryRun(context.Context) ([]libfsm.Action, error) {
+	return []libfsm.Action{libfsm.NewAction(libfsm.ActionUpdateObject,
+		"add taint %v=%v to node %v", defaults.RunLevelLabel, defaults.RunLevelSystem, p.Server.KubeNodeID())}, nil
+}
+
 // NewUntaint returns a new executor for removing a taint from a node
 func NewUntaint(params libfsm.ExecutorParams, client *kubeapi.Clientset, logger log.FieldLogger) (*untainter, error) {
 	op, err := newKubernetesOperation(params, client, logger)
@@ -91,6 +97,12 @@ func (*untainter) Rollback(context.Context) error {
 	return nil
 }
 
+// DryRun describes the taint this phase would remove from the node
+func (p *untainter) DryRun(context.Context) ([]libfsm.Action, error) {
+	return []libfsm.Action{libfsm.NewAction(libfsm.ActionUpdateObject,
+		"remove taint %v=%v from node %v", defaults.RunLevelLabel, defaults.RunLevelSystem, p.Server.KubeNodeID())}, nil
+}
+
 // NewDrain returns a new executor for draining a node
 func NewDrain(params libfsm.ExecutorParams, client *kubeapi.Clientset, logger log.FieldLogger) (*drainer, error) {
 	op, err := newKubernetesOperation(params, client, logger)
@@ -120,6 +132,12 @@ func (p *drainer) Rollback(ctx context.Context) error {
 	return trace.Wrap(err)
 }
 
+// DryRun describes the node this phase would drain
+func (p *drainer) DryRun(context.Context) ([]libfsm.Action, error) {
+	return []libfsm.Action{libfsm.NewAction(libfsm.ActionDrainNode,
+		"drain node %v", p.Server.KubeNodeID())}, nil
+}
+
 // NewUncordon returns a new executor for uncordoning a node
 func NewUncordon(params libfsm.ExecutorParams, client *kubeapi.Clientset, logger log.FieldLogger) (*uncordoner, error) {
 	op, err := newKubernetesOperation(params, client, logger)
@@ -144,6 +162,12 @@ func (*uncordoner) Rollback(context.Context) error {
 	return nil
 }
 
+// DryRun describes the node this phase would uncordon
+func (p *uncordoner) DryRun(context.Context) ([]libfsm.Action, error) {
+	return []libfsm.Action{libfsm.NewAction(libfsm.ActionUpdateObject,
+		"uncordon node %v", p.Server.KubeNodeID())}, nil
+}
+
 // NewEndpoints returns a new executor for waiting for cluster controller endpoints
 // to become active
 func NewEndpoints(params libfsm.ExecutorParams, client *kubeapi.Clientset, logger log.FieldLogger) (*endpoints, error) {
@@ -210,6 +234,11 @@ func (*kubernetesOperation) PostCheck(context.Context) error {
 	return nil
 }
 
+// RetryPolicy retries the phase on transient API server and etcd errors
+func (*kubernetesOperation) RetryPolicy() libfsm.RetryPolicy {
+	return libfsm.TransientRetryPolicy()
+}
+
 func taint(ctx context.Context, client corev1.NodeInterface, node string, add addTaint) error {
 	taint := v1.Taint{
 		Key:    defaults.RunLevelLabel,
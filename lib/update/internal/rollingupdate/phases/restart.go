@@ -103,6 +103,22 @@ func (r *restart) Rollback(ctx context.Context) error {
 	return trace.Wrap(err)
 }
 
+// DryRun describes the packages this phase would pull and
+// the runtime container it would restart
+func (r *restart) DryRun(context.Context) ([]libfsm.Action, error) {
+	var actions []libfsm.Action
+	for _, update := range r.updates() {
+		actions = append(actions, libfsm.NewAction(libfsm.ActionPullPackage,
+			"pull package %v", update))
+	}
+	return append(actions,
+		libfsm.NewAction(libfsm.ActionInstallPackage, "update runtime package %v to %v",
+			r.update.Runtime.Installed, r.update.Runtime.Update.Package),
+		libfsm.NewAction(libfsm.ActionRestartService, "restart runtime container with configuration %v",
+			r.update.Runtime.Update.ConfigPackage),
+	), nil
+}
+
 // PreCheck is a no-op
 func (*restart) PreCheck(context.Context) error {
 	return nil
@@ -114,8 +130,7 @@ func (*restart) PostCheck(context.Context) error {
 }
 
 func (r *restart) pullUpdates() error {
-	updates := []loc.Locator{r.update.Runtime.Update.Package, r.update.Runtime.Update.ConfigPackage}
-	for _, update := range updates {
+	for _, update := range r.updates() {
 		r.Infof("Pulling package update: %v.", update)
 		_, err := libapp.PullPackage(libapp.PackagePullRequest{
 			SrcPack: r.packages,
@@ -129,6 +144,10 @@ func (r *restart) pullUpdates() error {
 	return nil
 }
 
+func (r *restart) updates() []loc.Locator {
+	return []loc.Locator{r.update.Runtime.Update.Package, r.update.Runtime.Update.ConfigPackage}
+}
+
 type restart struct {
 	// FieldLogger specifies the logger for the phase
 	log.FieldLogger
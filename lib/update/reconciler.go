@@ -90,9 +90,13 @@ func (r *reconciler) trySyncChangelogFromEtcd(ctx context.Context) error {
 	return nil
 }
 
-// syncChangelog will sync changelog entries from src to dst storage
+// syncChangelog will sync changelog and phase log entries from src to dst storage
 func (r *reconciler) syncChangelog(src storage.Backend, dst storage.Backend) error {
-	return trace.Wrap(SyncChangelog(src, dst, r.cluster, r.operationID))
+	err := SyncChangelog(src, dst, r.cluster, r.operationID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(SyncPhaseLog(src, dst, r.cluster, r.operationID))
 }
 
 type reconciler struct {
@@ -125,6 +129,31 @@ func SyncChangelog(src storage.Backend, dst storage.Backend, clusterName string,
 	return nil
 }
 
+// SyncPhaseLog will sync phase log entries from src to dst storage
+func SyncPhaseLog(src storage.Backend, dst storage.Backend, clusterName string, operationID string) error {
+	srcLog, err := src.GetOperationPhaseLog(clusterName, operationID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	dstLog, err := dst.GetOperationPhaseLog(clusterName, operationID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	dstEntries := make(map[string]struct{}, len(dstLog))
+	for _, entry := range dstLog {
+		dstEntries[entry.ID] = struct{}{}
+	}
+	var missing storage.PhaseLog
+	for _, entry := range srcLog {
+		if _, ok := dstEntries[entry.ID]; !ok {
+			missing = append(missing, entry)
+		}
+	}
+	return trace.Wrap(dst.CreateOperationPhaseLogEntries(missing))
+}
+
 // isEtcdAvailable verifies that the etcd cluster is healthy
 func isEtcdAvailable(ctx context.Context, logger logrus.FieldLogger) (bool, error) {
 	_, err := utils.RunCommand(ctx, logger, utils.PlanetCommandArgs(defaults.EtcdCtlBin, "cluster-health")...)
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/loc"
@@ -30,6 +31,7 @@ import (
 	"github.com/gravitational/gravity/lib/ops/events"
 	"github.com/gravitational/gravity/lib/pack"
 	"github.com/gravitational/gravity/lib/rpc"
+	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
 
@@ -100,6 +102,20 @@ func (r *Updater) RunPhase(ctx context.Context, phase string, phaseTimeout time.
 	}))
 }
 
+// DryRun returns the actions the specified phase would perform
+// without executing it.
+// If phase is the root phase, the actions of the whole plan are returned
+func (r *Updater) DryRun(ctx context.Context, phase string, force bool) ([]fsm.Action, error) {
+	actions, err := r.machine.DryRun(ctx, fsm.Params{
+		PhaseID: phase,
+		Force:   force,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return actions, nil
+}
+
 // SetPhase sets phase state without executing it.
 func (r *Updater) SetPhase(ctx context.Context, phase, state string) error {
 	return r.machine.ChangePhaseState(ctx, fsm.StateChange{
@@ -173,6 +189,11 @@ func (r *Updater) executePlan(ctx context.Context) error {
 	planErr := r.machine.ExecutePlan(ctx, progress)
 	if planErr != nil {
 		r.WithError(planErr).Warn("Failed to execute plan.")
+		if r.RollbackOnFailure || app.HookFailurePolicy(planErr) == schema.HookFailureRollback {
+			if err := r.rollbackPlan(ctx, progress); err != nil {
+				r.WithError(err).Warn("Failed to roll back plan.")
+			}
+		}
 	}
 
 	err := r.machine.Complete(planErr)
@@ -195,6 +216,30 @@ func (r *Updater) executePlan(ctx context.Context) error {
 	return nil
 }
 
+// rollbackPlan rolls back all started phases of the plan in reverse order
+func (r *Updater) rollbackPlan(ctx context.Context, progress utils.Progress) error {
+	plan, err := r.machine.GetPlan()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	phases := fsm.FlattenPlan(plan)
+	for i := len(phases) - 1; i >= 0; i-- {
+		phase := phases[i]
+		if phase.HasSubphases() || phase.IsUnstarted() || phase.IsRolledBack() {
+			continue
+		}
+		r.Infof("Rolling back phase %v.", phase.ID)
+		err := r.machine.RollbackPhase(ctx, fsm.Params{
+			PhaseID:  phase.ID,
+			Progress: progress,
+		})
+		if err != nil {
+			return trace.Wrap(err, "failed to roll back phase %q", phase.ID)
+		}
+	}
+	return nil
+}
+
 func (r *Updater) updateProgress(lastProgress *ops.ProgressEntry) *ops.ProgressEntry {
 	progress, err := r.Operator.GetSiteOperationProgress(r.Operation.Key())
 	if err != nil {
@@ -243,6 +288,14 @@ type Config struct {
 	LocalBackend storage.Backend
 	// Runner specifies the runner for remote commands
 	Runner rpc.AgentRepository
+	// ParallelPhases is the maximum number of independent plan phases
+	// executed concurrently. Phases are executed in plan order if unset
+	ParallelPhases int
+	// RollbackOnFailure specifies whether the executed phases are
+	// rolled back automatically if the plan fails.
+	// The plan is also rolled back if it has failed because of a hook
+	// with the rollback failure policy
+	RollbackOnFailure bool
 	// FieldLogger is the logger to use
 	log.FieldLogger
 	// Silent controls whether the process outputs messages to stdout
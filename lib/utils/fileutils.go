@@ -415,6 +415,29 @@ func EnsureLineInFile(path, line string) error {
 	return nil
 }
 
+// RemoveLineFromFile removes all occurrences of the provided line from the
+// specified file. Returns NotFound if the file does not contain the line
+func RemoveLineFromFile(path, line string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	var lines []string
+	var found bool
+	for _, existing := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(existing) == strings.TrimSpace(line) {
+			found = true
+			continue
+		}
+		lines = append(lines, existing)
+	}
+	if !found {
+		return trace.NotFound("line %q not found", line)
+	}
+	return trace.ConvertSystemError(ioutil.WriteFile(path,
+		[]byte(strings.Join(lines, "\n")), defaults.SharedReadMask))
+}
+
 // Chown adjusts ownership of the specified directory and all its subdirectories
 func Chown(dir, uid, gid string) error {
 	out, err := exec.Command("chown", "-R", fmt.Sprintf("%v:%v", uid, gid), dir).CombinedOutput()
@@ -61,3 +61,13 @@ qwe
 1234
 5678`))
 }
+
+func (s *FileutilsSuite) TestRemoveLine(c *C) {
+	path := filepath.Join(c.MkDir(), "test")
+	c.Assert(ioutil.WriteFile(path, []byte("789\n1234\nqwe"), defaults.SharedReadMask), IsNil)
+	c.Assert(RemoveLineFromFile(path, " 1234"), IsNil)
+	c.Assert(RemoveLineFromFile(path, "1234"), FitsTypeOf, trace.NotFound(""))
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "789\nqwe")
+}
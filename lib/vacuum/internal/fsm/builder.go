@@ -29,14 +29,18 @@ import (
 )
 
 // NewOperationPlan returns a new plan for the specified operation
-// and the given set of servers
-func NewOperationPlan(operation ops.SiteOperation, servers []storage.Server, remoteApps []storage.Application) (*storage.OperationPlan, error) {
+// and the given set of servers.
+// registryAllowlist lists additional docker images to retain in the registry
+func NewOperationPlan(operation ops.SiteOperation, servers []storage.Server, remoteApps []storage.Application, registryAllowlist []string) (*storage.OperationPlan, error) {
 	masters, _ := libfsm.SplitServers(servers)
 	if len(masters) == 0 {
 		return nil, trace.NotFound("no master servers found in cluster state")
 	}
 
-	builder := phaseBuilder{remoteApps: remoteApps}
+	builder := phaseBuilder{
+		remoteApps:        remoteApps,
+		registryAllowlist: registryAllowlist,
+	}
 
 	registry := *builder.registry(masters)
 	packages := *builder.packages(servers)
@@ -66,6 +70,11 @@ func (r phaseBuilder) registry(masters []storage.Server) *phase {
 		node.Data = &storage.OperationPhaseData{
 			Server: &masters[i],
 		}
+		if len(r.registryAllowlist) != 0 {
+			node.Data.GarbageCollect = &storage.GarbageCollectOperationData{
+				RegistryAllowlist: r.registryAllowlist,
+			}
+		}
 		root.AddSequential(node)
 	}
 	return &root
@@ -124,7 +133,8 @@ func (r phaseBuilder) node(server storage.Server, parent phase, format string) p
 }
 
 type phaseBuilder struct {
-	remoteApps []storage.Application
+	remoteApps        []storage.Application
+	registryAllowlist []string
 }
 
 // AddSequential will append sub-phases which depend one upon another
@@ -50,7 +50,7 @@ func (S) TestSingleNodePlan(c *C) {
 		},
 	}
 
-	plan, err := NewOperationPlan(operation, servers, remoteApps)
+	plan, err := NewOperationPlan(operation, servers, remoteApps, nil)
 	c.Assert(err, IsNil)
 	c.Assert(plan, compare.DeepEquals, &storage.OperationPlan{
 		OperationID:   operation.ID,
@@ -129,7 +129,7 @@ func (S) TestMultiNodePlan(c *C) {
 		},
 	}
 
-	plan, err := NewOperationPlan(operation, servers, remoteApps)
+	plan, err := NewOperationPlan(operation, servers, remoteApps, nil)
 	c.Assert(err, IsNil)
 	c.Assert(plan, compare.DeepEquals, &storage.OperationPlan{
 		OperationID:   operation.ID,
@@ -225,3 +225,32 @@ func (S) TestMultiNodePlan(c *C) {
 		},
 	})
 }
+
+func (S) TestRegistryAllowlist(c *C) {
+	operation := ops.SiteOperation{
+		ID:         "1",
+		AccountID:  "0",
+		Type:       ops.OperationGarbageCollect,
+		SiteDomain: "cluster",
+	}
+	servers := []storage.Server{
+		{Hostname: "node-1", ClusterRole: string(schema.ServiceRoleMaster)},
+		{Hostname: "node-2", ClusterRole: string(schema.ServiceRoleNode)},
+	}
+	allowlist := []string{"custom/*", "nginx:1.9"}
+
+	plan, err := NewOperationPlan(operation, servers, nil, allowlist)
+	c.Assert(err, IsNil)
+	c.Assert(plan.Phases[0].Phases, compare.DeepEquals, []storage.OperationPhase{
+		{
+			ID:          "/registry/node-1",
+			Description: `Prune unused docker images on node "node-1"`,
+			Data: &storage.OperationPhaseData{
+				Server: &servers[0],
+				GarbageCollect: &storage.GarbageCollectOperationData{
+					RegistryAllowlist: allowlist,
+				},
+			},
+		},
+	})
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/gravitational/trace"
 	"github.com/pborman/uuid"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
 )
 
 // New returns a new state machine for garbage collection
@@ -101,6 +102,8 @@ type Config struct {
 	RemoteApps []storage.Application
 	// Apps is the cluster application service
 	Apps app.Applications
+	// Client is the cluster kubernetes client
+	Client kubernetes.Interface
 	// Operator is the cluster operator service
 	Operator ops.Operator
 	// LocalPackages is the machine-local pack service
@@ -183,6 +186,7 @@ func (r *engine) ChangePhaseState(ctx context.Context, change libfsm.StateChange
 			NewState:    change.State,
 			Error:       utils.ToRawTrace(change.Error),
 			Created:     time.Now().UTC(),
+			Node:        change.Node,
 		})
 	if err != nil {
 		return trace.Wrap(err)
@@ -230,7 +234,8 @@ func configToExecutor(config Config) libfsm.FSMSpecFunc {
 	return func(params libfsm.ExecutorParams, remote libfsm.Remote) (libfsm.PhaseExecutor, error) {
 		logger := &libfsm.Logger{
 			FieldLogger: log.WithFields(log.Fields{
-				constants.FieldPhase: params.Phase.ID,
+				constants.FieldPhase:       params.Phase.ID,
+				constants.FieldOperationID: params.Plan.OperationID,
 			}),
 			Key:      params.Key(),
 			Operator: config.Operator,
@@ -259,6 +264,7 @@ func configToExecutor(config Config) libfsm.FSMSpecFunc {
 				config.App.Locator,
 				config.Apps,
 				config.Packages,
+				config.Client,
 				config.Silent, logger)
 
 		default:
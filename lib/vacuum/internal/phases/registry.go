@@ -20,19 +20,16 @@ import (
 	"context"
 
 	"github.com/gravitational/gravity/lib/app"
-	"github.com/gravitational/gravity/lib/constants"
-	"github.com/gravitational/gravity/lib/defaults"
-	"github.com/gravitational/gravity/lib/docker"
 	libfsm "github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/localenv"
 	"github.com/gravitational/gravity/lib/pack"
-	"github.com/gravitational/gravity/lib/state"
 	"github.com/gravitational/gravity/lib/vacuum/prune"
 	"github.com/gravitational/gravity/lib/vacuum/prune/registry"
 
 	"github.com/gravitational/trace"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
 )
 
 // NewRegistry creates a new executor to prune unused docker images on a node
@@ -41,29 +38,20 @@ func NewRegistry(
 	clusterApp loc.Locator,
 	clusterApps app.Applications,
 	clusterPackages pack.PackageService,
+	client kubernetes.Interface,
 	silent localenv.Silent,
 	logger log.FieldLogger,
 ) (*registryExecutor, error) {
-	stateDir, err := state.GetStateDir()
-	if err != nil {
-		return nil, trace.Wrap(err)
-	}
-
-	imageService, err := docker.NewImageService(docker.RegistryConnectionRequest{
-		RegistryAddress: constants.LocalRegistryAddr,
-		CertName:        constants.DockerRegistry,
-		CACertPath:      state.Secret(stateDir, defaults.RootCertFilename),
-		ClientCertPath:  state.Secret(stateDir, "kubelet.cert"),
-		ClientKeyPath:   state.Secret(stateDir, "kubelet.key"),
-	})
-	if err != nil {
-		return nil, trace.Wrap(err)
+	var allowlist []string
+	if params.Phase.Data != nil && params.Phase.Data.GarbageCollect != nil {
+		allowlist = params.Phase.Data.GarbageCollect.RegistryAllowlist
 	}
 	pruner, err := registry.New(registry.Config{
-		App:          &clusterApp,
-		Apps:         clusterApps,
-		Packages:     clusterPackages,
-		ImageService: imageService,
+		App:       &clusterApp,
+		Apps:      clusterApps,
+		Packages:  clusterPackages,
+		Client:    client,
+		Allowlist: allowlist,
 		Config: prune.Config{
 			Silent:      silent,
 			FieldLogger: logger,
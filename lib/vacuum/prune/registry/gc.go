@@ -0,0 +1,492 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// collect determines the images, manifests and blobs in the registry
+// storage rooted at dir that are not referenced by refs.
+//
+// The collection mirrors the mark and sweep of the docker distribution
+// garbage collector: manifests reachable from the retained tags or referenced
+// by digest are marked along with the blobs they reference, everything else
+// is subject to removal
+func collect(dir string, refs *referenceSet, logger log.FieldLogger) (*collection, error) {
+	root := filepath.Join(dir, storageDir)
+	repositories, err := readRepositories(filepath.Join(root, repositoriesDir))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	c := &collection{
+		root:        root,
+		FieldLogger: logger,
+		marked:      make(map[string]struct{}),
+	}
+	for _, repository := range repositories {
+		repository.retained = make(map[string]struct{})
+		for _, tag := range repository.sortedTags() {
+			digest := repository.tags[tag]
+			if !refs.retainsTag(repository.name, tag) && !refs.retainsDigest(repository.name, digest) {
+				c.images = append(c.images, image{repository: repository, tag: tag})
+				continue
+			}
+			if err := c.markManifest(repository, digest); err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
+		for _, digest := range repository.revisions {
+			if refs.retainsDigest(repository.name, digest) {
+				if err := c.markManifest(repository, digest); err != nil {
+					return nil, trace.Wrap(err)
+				}
+			}
+		}
+		c.repositories = append(c.repositories, repository)
+	}
+	if err := c.collectBlobs(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return c, nil
+}
+
+// Result returns the summary of this collection
+func (r *collection) Result() Result {
+	result := Result{
+		Blobs:          len(r.blobs),
+		ReclaimedBytes: r.reclaimedBytes,
+	}
+	for _, image := range r.images {
+		result.Images = append(result.Images, image.String())
+	}
+	for _, repository := range r.repositories {
+		for _, digest := range repository.revisions {
+			if _, ok := repository.retained[digest]; !ok {
+				result.Manifests++
+			}
+		}
+	}
+	return result
+}
+
+// sweep removes the unreferenced images, manifests and blobs from the storage.
+// The registry service is expected to be stopped
+func (r *collection) sweep() error {
+	for _, image := range r.images {
+		r.Debugf("Remove tag %v.", image)
+		if err := removeAll(image.repository.tagDir(image.tag)); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	for _, repository := range r.repositories {
+		if len(repository.retained) == 0 {
+			r.Debugf("Remove repository %v.", repository.name)
+			if err := removeAll(repository.dir); err != nil {
+				return trace.Wrap(err)
+			}
+			continue
+		}
+		if err := r.sweepRepository(repository); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	for _, blob := range r.blobs {
+		r.Debugf("Remove blob %v.", blob)
+		if err := removeAll(r.blobDir(blob)); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+func (r *collection) sweepRepository(repository *repository) error {
+	for _, digest := range repository.revisions {
+		if _, ok := repository.retained[digest]; ok {
+			continue
+		}
+		r.Debugf("Remove manifest %v@%v.", repository.name, digest)
+		if err := removeAll(repository.revisionDir(digest)); err != nil {
+			return trace.Wrap(err)
+		}
+		// drop the references to the removed manifest from the tag history
+		for tag := range repository.tags {
+			if err := removeAll(repository.tagIndexDir(tag, digest)); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+	}
+	for _, digest := range repository.layers {
+		if _, ok := r.marked[digest]; ok {
+			continue
+		}
+		if err := removeAll(repository.layerDir(digest)); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// markManifest marks the manifest with the specified digest and all blobs
+// it references
+func (r *collection) markManifest(repository *repository, digest string) error {
+	if _, ok := repository.retained[digest]; ok {
+		return nil
+	}
+	repository.retained[digest] = struct{}{}
+	r.marked[digest] = struct{}{}
+	path, err := r.blobPath(digest)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			r.Warnf("Manifest %v@%v is missing from storage.", repository.name, digest)
+			return nil
+		}
+		return trace.ConvertSystemError(err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		r.Warnf("Failed to parse manifest %v@%v: %v.", repository.name, digest, err)
+		return nil
+	}
+	if m.Config != nil {
+		r.marked[m.Config.Digest] = struct{}{}
+	}
+	for _, layer := range m.Layers {
+		r.marked[layer.Digest] = struct{}{}
+	}
+	for _, layer := range m.FSLayers {
+		r.marked[layer.BlobSum] = struct{}{}
+	}
+	// manifest lists reference the platform-specific manifests
+	// of the same repository
+	for _, child := range m.Manifests {
+		if err := r.markManifest(repository, child.Digest); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// collectBlobs determines the unmarked blobs and the space they occupy
+func (r *collection) collectBlobs() error {
+	dir := filepath.Join(r.root, blobsDir)
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return nil
+			}
+			return trace.ConvertSystemError(err)
+		}
+		if fi.IsDir() || fi.Name() != blobDataFile {
+			return nil
+		}
+		// blobs are stored as blobs/<algorithm>/<prefix>/<hex>/data
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) != 4 {
+			return nil
+		}
+		digest := parts[0] + ":" + parts[2]
+		if _, ok := r.marked[digest]; ok {
+			return nil
+		}
+		r.blobs = append(r.blobs, digest)
+		r.reclaimedBytes += fi.Size()
+		return nil
+	})
+	return trace.Wrap(err)
+}
+
+func (r *collection) blobPath(digest string) (string, error) {
+	algorithm, hex, err := splitDigest(digest)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return filepath.Join(r.root, blobsDir, algorithm, hex[:2], hex, blobDataFile), nil
+}
+
+func (r *collection) blobDir(digest string) string {
+	algorithm, hex, _ := splitDigest(digest)
+	return filepath.Join(r.root, blobsDir, algorithm, hex[:2], hex)
+}
+
+// collection describes the result of marking the registry storage
+type collection struct {
+	log.FieldLogger
+	// root is the root directory of the registry storage
+	root string
+	// repositories lists the repositories in the storage
+	repositories []*repository
+	// images lists the tags to remove
+	images []image
+	// marked is the set of referenced blob digests
+	marked map[string]struct{}
+	// blobs lists the digests of the blobs to remove
+	blobs []string
+	// reclaimedBytes is the size of the blobs to remove
+	reclaimedBytes int64
+}
+
+// Result describes the outcome of the registry garbage collection
+type Result struct {
+	// Images lists the removed images as repository:tag
+	Images []string
+	// Manifests is the number of removed image manifests
+	Manifests int
+	// Blobs is the number of removed blobs
+	Blobs int
+	// ReclaimedBytes is the total size of the removed blobs
+	ReclaimedBytes int64
+}
+
+// readRepositories returns all repositories found in the specified directory.
+// Repository names can be nested so the directory is searched recursively
+// for directories with manifests
+func readRepositories(dir string) (repositories []*repository, err error) {
+	err = filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return nil
+			}
+			return trace.ConvertSystemError(err)
+		}
+		if !fi.IsDir() || path == dir {
+			return nil
+		}
+		if strings.HasPrefix(fi.Name(), "_") {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, manifestsDir)); err != nil {
+			return nil
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		repository, err := readRepository(filepath.ToSlash(name), path)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		repositories = append(repositories, repository)
+		return nil
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return repositories, nil
+}
+
+func readRepository(name, dir string) (*repository, error) {
+	r := &repository{
+		name: name,
+		dir:  dir,
+		tags: make(map[string]string),
+	}
+	tags, err := readDirNames(filepath.Join(dir, manifestsDir, tagsDir))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, tag := range tags {
+		digest, err := readLink(filepath.Join(r.tagDir(tag), "current", linkFile))
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		r.tags[tag] = digest
+	}
+	r.revisions, err = readDigests(filepath.Join(dir, manifestsDir, revisionsDir))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	r.layers, err = readDigests(filepath.Join(dir, layersDir))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return r, nil
+}
+
+func (r *repository) sortedTags() []string {
+	tags := make([]string, 0, len(r.tags))
+	for tag := range r.tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func (r *repository) tagDir(tag string) string {
+	return filepath.Join(r.dir, manifestsDir, tagsDir, tag)
+}
+
+func (r *repository) tagIndexDir(tag, digest string) string {
+	algorithm, hex, _ := splitDigest(digest)
+	return filepath.Join(r.tagDir(tag), "index", algorithm, hex)
+}
+
+func (r *repository) revisionDir(digest string) string {
+	algorithm, hex, _ := splitDigest(digest)
+	return filepath.Join(r.dir, manifestsDir, revisionsDir, algorithm, hex)
+}
+
+func (r *repository) layerDir(digest string) string {
+	algorithm, hex, _ := splitDigest(digest)
+	return filepath.Join(r.dir, layersDir, algorithm, hex)
+}
+
+// repository describes a single repository in the registry storage
+type repository struct {
+	// name is the repository name
+	name string
+	// dir is the repository directory
+	dir string
+	// tags maps tags to manifest digests
+	tags map[string]string
+	// revisions lists the digests of all manifests in the repository
+	revisions []string
+	// layers lists the digests of all blobs linked to the repository
+	layers []string
+	// retained is the set of marked manifest digests
+	retained map[string]struct{}
+}
+
+// String returns the image as repository:tag
+func (r image) String() string {
+	return r.repository.name + ":" + r.tag
+}
+
+// image identifies a tag in the repository
+type image struct {
+	repository *repository
+	tag        string
+}
+
+// manifest lists the fields of schema1, schema2 and OCI image manifests
+// and manifest lists relevant for marking
+type manifest struct {
+	// Config references the image configuration blob
+	Config *descriptor `json:"config,omitempty"`
+	// Layers references the image layer blobs
+	Layers []descriptor `json:"layers,omitempty"`
+	// Manifests references the manifests of a manifest list
+	Manifests []descriptor `json:"manifests,omitempty"`
+	// FSLayers references the layer blobs of a schema1 manifest
+	FSLayers []struct {
+		BlobSum string `json:"blobSum"`
+	} `json:"fsLayers,omitempty"`
+}
+
+// descriptor references a blob by digest
+type descriptor struct {
+	// Digest is the blob digest
+	Digest string `json:"digest"`
+}
+
+// readDigests returns the digests of the links stored as <algorithm>/<hex>/link
+// in the specified directory
+func readDigests(dir string) (digests []string, err error) {
+	algorithms, err := readDirNames(dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, algorithm := range algorithms {
+		hexes, err := readDirNames(filepath.Join(dir, algorithm))
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, hex := range hexes {
+			digests = append(digests, algorithm+":"+hex)
+		}
+	}
+	return digests, nil
+}
+
+// readDirNames returns the names of the subdirectories of dir.
+// Returns an empty list if the directory does not exist
+func readDirNames(dir string) (names []string, err error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, trace.ConvertSystemError(err)
+	}
+	for _, info := range infos {
+		if info.IsDir() {
+			names = append(names, info.Name())
+		}
+	}
+	return names, nil
+}
+
+func readLink(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func splitDigest(digest string) (algorithm, hex string, err error) {
+	parts := strings.SplitN(digest, ":", 2)
+	if len(parts) != 2 || len(parts[1]) < 2 {
+		return "", "", trace.BadParameter("invalid digest %q", digest)
+	}
+	return parts[0], parts[1], nil
+}
+
+func removeAll(path string) error {
+	return trace.ConvertSystemError(os.RemoveAll(path))
+}
+
+const (
+	// storageDir is the root of the docker distribution filesystem
+	// storage relative to the registry directory
+	storageDir = "docker/registry/v2"
+	// repositoriesDir is the directory with repositories
+	repositoriesDir = "repositories"
+	// blobsDir is the directory with blobs
+	blobsDir = "blobs"
+	// manifestsDir is the repository directory with manifest links
+	manifestsDir = "_manifests"
+	// layersDir is the repository directory with blob links
+	layersDir = "_layers"
+	// tagsDir is the manifests directory with tags
+	tagsDir = "tags"
+	// revisionsDir is the manifests directory with manifest revisions
+	revisionsDir = "revisions"
+	// linkFile is the name of the file with the link target digest
+	linkFile = "link"
+	// blobDataFile is the name of the file with the blob contents
+	blobDataFile = "data"
+)
@@ -0,0 +1,293 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gravitational/gravity/lib/archive"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/check.v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRegistry(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (*S) SetUpSuite(c *check.C) {
+	if testing.Verbose() {
+		log.SetLevel(log.DebugLevel)
+	}
+}
+
+func (*S) TestCollectsUnreferencedImages(c *check.C) {
+	// setup
+	dir := c.MkDir()
+	r := newTestRegistry(c, dir)
+	base := r.blob("base layer")
+	web1 := r.image("app/web", "1.0", base, r.blob("web 1.0 layer"))
+	web09 := r.image("app/web", "0.9", base, r.blob("web 0.9 layer"))
+	untagged := r.manifest("app/web", r.blob("web debug layer"))
+	r.image("custom/tool", "latest", r.blob("tool layer"))
+	db := r.image("pulled/db", "2.0", r.blob("db layer"))
+	r.image("orphan/image", "1", r.blob("orphan layer"))
+
+	refs := newReferenceSet()
+	c.Assert(refs.addPattern("custom/*"), check.IsNil)
+	refs.addTag("app/web", "1.0")
+	c.Assert(refs.addPods([]v1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{Image: "leader.telekube.local:5000/pulled/db:2.0"},
+					{Image: "leader.telekube.local:5000/app/web@" + untagged},
+				},
+			},
+		},
+	}), check.IsNil)
+
+	// exercise
+	collection, err := collect(dir, refs, log.StandardLogger())
+	c.Assert(err, check.IsNil)
+
+	// verify
+	result := collection.Result()
+	c.Assert(result.Images, check.DeepEquals, []string{"app/web:0.9", "orphan/image:1"})
+	c.Assert(result.Manifests, check.Equals, 2)
+	// web 0.9 and orphan layers with their configs and manifests
+	c.Assert(result.Blobs, check.Equals, 6)
+	c.Assert(result.ReclaimedBytes, check.Equals, r.size["web 0.9 layer"]+r.size["orphan layer"]+
+		r.size[web09]+r.size[r.configs[web09]]+r.manifestSize("orphan/image"))
+
+	c.Assert(collection.sweep(), check.IsNil)
+	c.Assert(r.exists("repositories/app/web/_manifests/tags/1.0"), check.Equals, true)
+	c.Assert(r.exists("repositories/app/web/_manifests/tags/0.9"), check.Equals, false)
+	c.Assert(r.exists(revisionPath("app/web", web1)), check.Equals, true)
+	c.Assert(r.exists(revisionPath("app/web", untagged)), check.Equals, true)
+	c.Assert(r.exists(revisionPath("app/web", web09)), check.Equals, false)
+	c.Assert(r.exists(revisionPath("pulled/db", db)), check.Equals, true)
+	c.Assert(r.exists("repositories/custom/tool/_manifests/tags/latest"), check.Equals, true)
+	c.Assert(r.exists("repositories/orphan/image"), check.Equals, false)
+	c.Assert(r.exists(blobPath(base)), check.Equals, true)
+	c.Assert(r.exists(blobPath(web09)), check.Equals, false)
+	c.Assert(r.exists(layerPath("app/web", r.digest("web 0.9 layer"))), check.Equals, false)
+	c.Assert(r.exists(layerPath("app/web", base)), check.Equals, true)
+
+	collection, err = collect(dir, refs, log.StandardLogger())
+	c.Assert(err, check.IsNil)
+	c.Assert(collection.Result(), check.DeepEquals, Result{})
+}
+
+func (*S) TestRetainsManifestListChildren(c *check.C) {
+	dir := c.MkDir()
+	r := newTestRegistry(c, dir)
+	amd64 := r.manifest("app/multi", r.blob("amd64 layer"))
+	arm64 := r.manifest("app/multi", r.blob("arm64 layer"))
+	r.manifestList("app/multi", "1.0", amd64, arm64)
+
+	refs := newReferenceSet()
+	refs.addTag("app/multi", "1.0")
+
+	collection, err := collect(dir, refs, log.StandardLogger())
+	c.Assert(err, check.IsNil)
+	c.Assert(collection.Result(), check.DeepEquals, Result{})
+}
+
+func (*S) TestReadsPackageImages(c *check.C) {
+	digest := "sha256:" + fmt.Sprintf("%x", sha256.Sum256([]byte("manifest")))
+	tarball := archive.MustCreateMemArchive([]*archive.Item{
+		archive.ItemFromString("registry/docker/registry/v2/repositories/gravitational/debian-tall/_manifests/tags/0.0.1/current/link", digest),
+		archive.ItemFromString("registry/docker/registry/v2/repositories/gravitational/debian-tall/_manifests/tags/0.0.1/index/sha256/abc/link", digest),
+		archive.ItemFromString("resources/app.yaml", "kind: Application"),
+	})
+
+	refs := newReferenceSet()
+	c.Assert(refs.addPackageImages(tarball), check.IsNil)
+	c.Assert(refs.tags, check.DeepEquals, map[string]struct{}{
+		"gravitational/debian-tall:0.0.1": {},
+	})
+	c.Assert(refs.digests, check.DeepEquals, map[string]struct{}{
+		"gravitational/debian-tall@" + digest: {},
+	})
+}
+
+func (*S) TestReadsWorkloadTemplates(c *check.C) {
+	template := newPodTemplate("cronjob", metav1.ObjectMeta{Name: "backup", Namespace: "kube-system"}, v1.PodSpec{
+		InitContainers: []v1.Container{{Image: "leader.telekube.local:5000/tools/init:1.0"}},
+		Containers:     []v1.Container{{Image: "leader.telekube.local:5000/tools/backup:2.0"}},
+	})
+	c.Assert(template.owner, check.Equals, "cronjob kube-system/backup")
+
+	refs := newReferenceSet()
+	c.Assert(refs.addPodSpec(template.spec), check.IsNil)
+	c.Assert(refs.tags, check.DeepEquals, map[string]struct{}{
+		"tools/init:1.0":   {},
+		"tools/backup:2.0": {},
+	})
+}
+
+func (*S) TestMatchesPatterns(c *check.C) {
+	refs := newReferenceSet()
+	c.Assert(refs.addPattern("custom/*"), check.IsNil)
+	c.Assert(refs.addPattern("nginx:1.*"), check.IsNil)
+	c.Assert(refs.addImage("busybox"), check.IsNil)
+	c.Assert(refs.addPattern("[invalid"), check.NotNil)
+
+	c.Assert(refs.retainsTag("custom/tool", "latest"), check.Equals, true)
+	c.Assert(refs.retainsTag("custom/nested/tool", "latest"), check.Equals, false)
+	c.Assert(refs.retainsTag("nginx", "1.9"), check.Equals, true)
+	c.Assert(refs.retainsTag("nginx", "2.0"), check.Equals, false)
+	c.Assert(refs.retainsTag("busybox", "latest"), check.Equals, true)
+}
+
+func newTestRegistry(c *check.C, dir string) *testRegistry {
+	return &testRegistry{
+		c:       c,
+		root:    filepath.Join(dir, storageDir),
+		size:    make(map[string]int64),
+		configs: make(map[string]string),
+		digests: make(map[string]string),
+	}
+}
+
+// blob writes the specified contents as a blob and returns its digest
+func (r *testRegistry) blob(contents string) string {
+	digest := "sha256:" + fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))
+	r.write(blobPath(digest), contents)
+	r.size[contents] = int64(len(contents))
+	r.size[digest] = int64(len(contents))
+	r.digests[contents] = digest
+	return digest
+}
+
+func (r *testRegistry) digest(contents string) string {
+	return r.digests[contents]
+}
+
+// manifest writes an untagged image manifest with the specified layers
+// and returns its digest
+func (r *testRegistry) manifest(repository string, layers ...string) string {
+	config := r.blob(fmt.Sprintf("config of %v %v", repository, layers))
+	m := manifest{Config: &descriptor{Digest: config}}
+	for _, layer := range layers {
+		m.Layers = append(m.Layers, descriptor{Digest: layer})
+		r.write(layerPath(repository, layer), layer)
+	}
+	r.write(layerPath(repository, config), config)
+	digest := r.writeManifest(repository, m)
+	r.configs[digest] = config
+	return digest
+}
+
+// image writes an image manifest with the specified layers and tags it
+func (r *testRegistry) image(repository, tag string, layers ...string) string {
+	digest := r.manifest(repository, layers...)
+	r.tag(repository, tag, digest)
+	return digest
+}
+
+func (r *testRegistry) manifestList(repository, tag string, manifests ...string) string {
+	var m manifest
+	for _, digest := range manifests {
+		m.Manifests = append(m.Manifests, descriptor{Digest: digest})
+	}
+	digest := r.writeManifest(repository, m)
+	r.tag(repository, tag, digest)
+	return digest
+}
+
+func (r *testRegistry) writeManifest(repository string, m manifest) string {
+	data, err := json.Marshal(m)
+	r.c.Assert(err, check.IsNil)
+	digest := r.blob(string(data))
+	r.write(revisionPath(repository, digest), digest)
+	r.manifests = append(r.manifests, testManifest{repository: repository, digest: digest})
+	return digest
+}
+
+func (r *testRegistry) tag(repository, tag, digest string) {
+	tagDir := filepath.Join(repositoriesDir, repository, manifestsDir, tagsDir, tag)
+	r.write(filepath.Join(tagDir, "current", linkFile), digest)
+	algorithm, hex, _ := splitDigest(digest)
+	r.write(filepath.Join(tagDir, "index", algorithm, hex, linkFile), digest)
+}
+
+// manifestSize returns the total size of the manifests, configs and layers
+// of the specified repository that are not shared with other repositories
+func (r *testRegistry) manifestSize(repository string) (size int64) {
+	for _, m := range r.manifests {
+		if m.repository == repository {
+			size += r.size[m.digest] + r.size[r.configs[m.digest]]
+		}
+	}
+	return size
+}
+
+func (r *testRegistry) exists(path string) bool {
+	_, err := os.Stat(filepath.Join(r.root, path))
+	return err == nil
+}
+
+func (r *testRegistry) write(path, contents string) {
+	path = filepath.Join(r.root, path)
+	r.c.Assert(os.MkdirAll(filepath.Dir(path), 0755), check.IsNil)
+	r.c.Assert(ioutil.WriteFile(path, []byte(contents), 0644), check.IsNil)
+}
+
+type testRegistry struct {
+	c    *check.C
+	root string
+	// size maps blob contents and digests to blob size
+	size map[string]int64
+	// configs maps manifest digests to config digests
+	configs map[string]string
+	// digests maps blob contents to digests
+	digests   map[string]string
+	manifests []testManifest
+}
+
+type testManifest struct {
+	repository string
+	digest     string
+}
+
+func blobPath(digest string) string {
+	algorithm, hex, _ := splitDigest(digest)
+	return filepath.Join(blobsDir, algorithm, hex[:2], hex, blobDataFile)
+}
+
+func revisionPath(repository, digest string) string {
+	algorithm, hex, _ := splitDigest(digest)
+	return filepath.Join(repositoriesDir, repository, manifestsDir, revisionsDir, algorithm, hex, linkFile)
+}
+
+func layerPath(repository, digest string) string {
+	algorithm, hex, _ := splitDigest(digest)
+	return filepath.Join(repositoriesDir, repository, layersDir, algorithm, hex, linkFile)
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/archive"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+
+	dockerarchive "github.com/docker/docker/pkg/archive"
+	"github.com/gravitational/trace"
+	"k8s.io/api/core/v1"
+)
+
+// newReferenceSet returns an empty set of image references
+func newReferenceSet() *referenceSet {
+	return &referenceSet{
+		tags:    make(map[string]struct{}),
+		digests: make(map[string]struct{}),
+	}
+}
+
+// referenceSet is the set of docker images to retain in the registry.
+// Images are identified by repository without the registry address,
+// so the same image is retained regardless of the registry it has been
+// referenced with
+type referenceSet struct {
+	// tags is the set of referenced repository:tag pairs
+	tags map[string]struct{}
+	// digests is the set of referenced repository@digest pairs
+	digests map[string]struct{}
+	// patterns lists the allowlist patterns
+	patterns []imagePattern
+}
+
+// addImage adds the specified image reference to the set.
+// The image is specified as [registry/]repository[:tag|@digest]
+func (r *referenceSet) addImage(image string) error {
+	parsed, err := loc.ParseDockerImage(image)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if isDigest(parsed.Tag) {
+		r.addDigest(parsed.Repository, parsed.Tag)
+		return nil
+	}
+	r.addTag(parsed.Repository, parsed.Tag)
+	return nil
+}
+
+// addTag adds the specified tag of the repository to the set
+func (r *referenceSet) addTag(repository, tag string) {
+	if tag == "" {
+		tag = defaultTag
+	}
+	r.tags[repository+":"+tag] = struct{}{}
+}
+
+// addDigest adds the manifest with the specified digest to the set
+func (r *referenceSet) addDigest(repository, digest string) {
+	r.digests[repository+"@"+digest] = struct{}{}
+}
+
+// addPattern adds the allowlist pattern to the set.
+// The pattern is specified as repository[:tag] where both repository and tag
+// can use shell glob syntax. Patterns without a tag match all tags of the repository.
+// Patterns with a digest reference this exact manifest
+func (r *referenceSet) addPattern(pattern string) error {
+	parsed, err := loc.ParseDockerImage(pattern)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if isDigest(parsed.Tag) {
+		r.addDigest(parsed.Repository, parsed.Tag)
+		return nil
+	}
+	tag := parsed.Tag
+	if tag == "" {
+		tag = "*"
+	}
+	for _, glob := range []string{parsed.Repository, tag} {
+		if _, err := path.Match(glob, ""); err != nil {
+			return trace.BadParameter("invalid image pattern %q: %v", pattern, err)
+		}
+	}
+	r.patterns = append(r.patterns, imagePattern{repository: parsed.Repository, tag: tag})
+	return nil
+}
+
+// retainsTag returns true if the specified tag of the repository is referenced
+func (r *referenceSet) retainsTag(repository, tag string) bool {
+	if _, ok := r.tags[repository+":"+tag]; ok {
+		return true
+	}
+	for _, pattern := range r.patterns {
+		if pattern.matches(repository, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// retainsDigest returns true if the manifest with the specified digest
+// is referenced by digest
+func (r *referenceSet) retainsDigest(repository, digest string) bool {
+	_, ok := r.digests[repository+"@"+digest]
+	return ok
+}
+
+// addPods adds the images of all containers of the specified pods to the set
+func (r *referenceSet) addPods(pods []v1.Pod) error {
+	for _, pod := range pods {
+		if err := r.addPodSpec(pod.Spec); err != nil {
+			return trace.Wrap(err, "invalid image of pod %v/%v", pod.Namespace, pod.Name)
+		}
+		var statuses []v1.ContainerStatus
+		statuses = append(statuses, pod.Status.InitContainerStatuses...)
+		statuses = append(statuses, pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			// image IDs of images pulled from a registry reference the manifest digest,
+			// for example docker-pullable://registry:5000/repository@sha256:<hex>
+			imageID := strings.TrimPrefix(status.ImageID, dockerPullablePrefix)
+			if !strings.Contains(imageID, "@") {
+				continue
+			}
+			if err := r.addImage(imageID); err != nil {
+				return trace.Wrap(err, "invalid image ID of pod %v/%v", pod.Namespace, pod.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// addPodSpec adds the images of all containers of the specified pod spec to the set
+func (r *referenceSet) addPodSpec(spec v1.PodSpec) error {
+	var containers []v1.Container
+	containers = append(containers, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, container := range containers {
+		if err := r.addImage(container.Image); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// addPackageImages adds the images exported into the registry directory
+// of the specified application package tarball
+func (r *referenceSet) addPackageImages(tarball io.Reader) error {
+	decompressed, err := dockerarchive.DecompressStream(tarball)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer decompressed.Close()
+	prefix := filepath.Join(defaults.RegistryDir, storageDir, repositoriesDir)
+	return archive.TarGlobWithPrefix(tar.NewReader(decompressed), prefix,
+		func(header *tar.Header, reader *tar.Reader) error {
+			relpath := strings.TrimPrefix(filepath.Clean(header.Name), prefix+"/")
+			repository, tag, ok := parseTagLink(relpath)
+			if !ok {
+				return nil
+			}
+			digest, err := ioutil.ReadAll(io.LimitReader(reader, maxLinkSize))
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			r.addTag(repository, tag)
+			r.addDigest(repository, strings.TrimSpace(string(digest)))
+			return nil
+		})
+}
+
+// parseTagLink parses the path of the tag link relative to the repositories
+// directory: <repository>/_manifests/tags/<tag>/current/link
+func parseTagLink(relpath string) (repository, tag string, ok bool) {
+	index := strings.Index(relpath, "/"+manifestsDir+"/"+tagsDir+"/")
+	if index <= 0 {
+		return "", "", false
+	}
+	repository = relpath[:index]
+	tag = strings.TrimPrefix(relpath[index:], "/"+manifestsDir+"/"+tagsDir+"/")
+	if !strings.HasSuffix(tag, "/current/link") {
+		return "", "", false
+	}
+	tag = strings.TrimSuffix(tag, "/current/link")
+	if tag == "" || strings.Contains(tag, "/") {
+		return "", "", false
+	}
+	return repository, tag, true
+}
+
+func (r imagePattern) matches(repository, tag string) bool {
+	if matched, _ := path.Match(r.repository, repository); !matched {
+		return false
+	}
+	matched, _ := path.Match(r.tag, tag)
+	return matched
+}
+
+// imagePattern matches images by repository and tag globs
+type imagePattern struct {
+	repository string
+	tag        string
+}
+
+func isDigest(tag string) bool {
+	return strings.Contains(tag, ":")
+}
+
+const (
+	// defaultTag is the tag of images referenced without a tag
+	defaultTag = "latest"
+	// dockerPullablePrefix is the prefix of container image IDs
+	// that reference images by digest
+	dockerPullablePrefix = "docker-pullable://"
+	// maxLinkSize limits the size of a link file read from a package
+	maxLinkSize = 1024
+)
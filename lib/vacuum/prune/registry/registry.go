@@ -18,12 +18,11 @@ package registry
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	apps "github.com/gravitational/gravity/lib/app"
-	appservice "github.com/gravitational/gravity/lib/app/service"
 	"github.com/gravitational/gravity/lib/defaults"
-	"github.com/gravitational/gravity/lib/docker"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/pack"
 	"github.com/gravitational/gravity/lib/state"
@@ -31,8 +30,13 @@ import (
 	"github.com/gravitational/gravity/lib/utils"
 	"github.com/gravitational/gravity/lib/vacuum/prune"
 
+	"github.com/dustin/go-humanize"
+	"github.com/gravitational/rigging"
 	"github.com/gravitational/trace"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 // New creates a new registry cleaner
@@ -50,15 +54,22 @@ func (r *Config) checkAndSetDefaults() error {
 	if r.App == nil {
 		return trace.BadParameter("application package is required")
 	}
-	if r.ImageService == nil {
-		return trace.BadParameter("docker image service is required")
-	}
 	if r.Packages == nil {
 		return trace.BadParameter("cluster package service is required")
 	}
 	if r.Apps == nil {
 		return trace.BadParameter("cluster application service is required")
 	}
+	if r.Client == nil {
+		return trace.BadParameter("kubernetes client is required")
+	}
+	if r.RegistryDir == "" {
+		stateDir, err := state.GetStateDir()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		r.RegistryDir = state.RegistryDir(stateDir)
+	}
 	if r.FieldLogger == nil {
 		r.FieldLogger = log.WithField(trace.Component, "gc:registry")
 	}
@@ -75,70 +86,237 @@ type Config struct {
 	Packages pack.PackageService
 	// Apps specifies the cluster application service
 	Apps apps.Applications
-	// ImageService specifies the docker image service
-	ImageService docker.ImageService
+	// Client specifies the kubernetes client used to query the running pods and workloads
+	Client kubernetes.Interface
+	// Allowlist lists additional images to retain as repository[:tag] patterns
+	Allowlist []string
+	// RegistryDir specifies the location of the registry state.
+	// Defaults to the registry directory in the planet state directory
+	RegistryDir string
 }
 
-// Prune removes unused docker images.
-// The registry state is reset by deleting the state from the filesystem
-// and re-running the docker image export for the cluster application.
+// Prune removes docker images that are not referenced by the application
+// packages in the cluster repository, the running pods, the pod templates of
+// workloads or the allowlist from the local
+// docker registry and reclaims the space occupied by the unreferenced blobs.
+// In dry-run mode, only reports the images to remove and the reclaimable space
 func (r *cleanup) Prune(ctx context.Context) (err error) {
-	r.PrintStep("Stop registry service")
-	if !r.DryRun {
-		err = r.registryStop(ctx)
-		defer func() {
-			if err == nil {
-				return
-			}
-			if errStart := r.registryStart(ctx); errStart != nil {
-				r.Warn(errStart)
-			}
-		}()
+	refs, err := r.collectReferences(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	if r.DryRun {
+		collection, err := collect(r.RegistryDir, refs, r.FieldLogger)
 		if err != nil {
 			return trace.Wrap(err)
 		}
+		r.report(collection.Result())
+		return nil
 	}
 
-	stateDir, err := state.GetStateDir()
+	r.PrintStep("Stop registry service")
+	err = r.registryStop(ctx)
+	defer func() {
+		if err == nil {
+			return
+		}
+		if errStart := r.registryStart(ctx); errStart != nil {
+			r.Warn(errStart)
+		}
+	}()
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
-	dir := state.RegistryDir(stateDir)
-	r.PrintStep("Delete registry state directory %v", dir)
-	if !r.DryRun {
-		err = utils.RemoveContents(dir)
-		if err != nil {
-			return trace.Wrap(trace.ConvertSystemError(err),
-				"failed to remove old registry state from %v.", dir)
-		}
+	collection, err := collect(r.RegistryDir, refs, r.FieldLogger)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	r.report(collection.Result())
+	err = collection.sweep()
+	if err != nil {
+		return trace.Wrap(err, "failed to remove unreferenced images from %v", r.RegistryDir)
 	}
 
 	r.PrintStep("Start registry service")
-	if !r.DryRun {
-		err = r.registryStart(ctx)
+	err = r.registryStart(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	return nil
+}
+
+// collectReferences builds the set of images to retain
+func (r *cleanup) collectReferences(ctx context.Context) (*referenceSet, error) {
+	refs := newReferenceSet()
+	for _, pattern := range r.Allowlist {
+		if err := refs.addPattern(pattern); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+
+	seen := make(map[string]struct{})
+	err := r.addAppImages(refs, *r.App, seen)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	applications, err := r.Apps.ListApps(apps.ListAppsRequest{
+		Repository: r.App.Repository,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, application := range applications {
+		err = r.addAppImages(refs, application.Package, seen)
 		if err != nil {
-			return trace.Wrap(err)
+			return nil, trace.Wrap(err)
 		}
 	}
 
-	r.PrintStep("Sync application state with registry")
-	if r.DryRun {
+	r.PrintStep("Collect images of running pods")
+	pods, err := r.Client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, trace.Wrap(rigging.ConvertError(err))
+	}
+	err = refs.addPods(pods.Items)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	r.PrintStep("Collect images of workloads")
+	err = r.addWorkloadImages(refs)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return refs, nil
+}
+
+// addWorkloadImages adds the images of the pod templates of all deployments,
+// statefulsets, daemonsets, replicasets, jobs and cronjobs to refs, so images
+// of workloads that are scaled down or not scheduled at the moment are retained
+func (r *cleanup) addWorkloadImages(refs *referenceSet) error {
+	templates, err := r.listPodTemplates()
+	if err != nil {
+		return trace.Wrap(rigging.ConvertError(err))
+	}
+	for _, template := range templates {
+		if err := refs.addPodSpec(template.spec); err != nil {
+			return trace.Wrap(err, "invalid image of %v", template.owner)
+		}
+	}
+	return nil
+}
+
+// listPodTemplates returns the pod templates of all workloads in the cluster
+func (r *cleanup) listPodTemplates() (templates []podTemplate, err error) {
+	opts := metav1.ListOptions{}
+	deployments, err := r.Client.AppsV1().Deployments(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, item := range deployments.Items {
+		templates = append(templates, newPodTemplate("deployment", item.ObjectMeta, item.Spec.Template.Spec))
+	}
+	statefulSets, err := r.Client.AppsV1().StatefulSets(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, item := range statefulSets.Items {
+		templates = append(templates, newPodTemplate("statefulset", item.ObjectMeta, item.Spec.Template.Spec))
+	}
+	daemonSets, err := r.Client.AppsV1().DaemonSets(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, item := range daemonSets.Items {
+		templates = append(templates, newPodTemplate("daemonset", item.ObjectMeta, item.Spec.Template.Spec))
+	}
+	replicaSets, err := r.Client.AppsV1().ReplicaSets(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, item := range replicaSets.Items {
+		templates = append(templates, newPodTemplate("replicaset", item.ObjectMeta, item.Spec.Template.Spec))
+	}
+	jobs, err := r.Client.BatchV1().Jobs(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, item := range jobs.Items {
+		templates = append(templates, newPodTemplate("job", item.ObjectMeta, item.Spec.Template.Spec))
+	}
+	cronJobs, err := r.Client.BatchV1beta1().CronJobs(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, item := range cronJobs.Items {
+		templates = append(templates, newPodTemplate("cronjob", item.ObjectMeta, item.Spec.JobTemplate.Spec.Template.Spec))
+	}
+	return templates, nil
+}
+
+func newPodTemplate(kind string, meta metav1.ObjectMeta, spec v1.PodSpec) podTemplate {
+	return podTemplate{
+		owner: fmt.Sprintf("%v %v/%v", kind, meta.Namespace, meta.Name),
+		spec:  spec,
+	}
+}
+
+// podTemplate is the pod template of a workload
+type podTemplate struct {
+	// owner identifies the workload
+	owner string
+	// spec is the pod spec of the template
+	spec v1.PodSpec
+}
+
+// addAppImages adds the images of the specified application package,
+// its base application and dependencies to refs
+func (r *cleanup) addAppImages(refs *referenceSet, locator loc.Locator, seen map[string]struct{}) error {
+	if _, ok := seen[locator.String()]; ok {
 		return nil
 	}
-	err = appservice.SyncApp(ctx, appservice.SyncRequest{
-		PackService:  r.Packages,
-		AppService:   r.Apps,
-		ImageService: r.ImageService,
-		Package:      *r.App,
-	})
+	seen[locator.String()] = struct{}{}
+
+	application, err := r.Apps.GetApp(locator)
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	if base := application.Manifest.Base(); base != nil {
+		if err := r.addAppImages(refs, *base, seen); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	for _, dep := range application.Manifest.Dependencies.Apps {
+		if err := r.addAppImages(refs, dep.Locator, seen); err != nil {
+			return trace.Wrap(err)
+		}
+	}
 
+	r.PrintStep("Collect images of application %v", locator)
+	_, reader, err := r.Packages.ReadPackage(locator)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+	err = refs.addPackageImages(reader)
+	if err != nil {
+		return trace.Wrap(err, "failed to read images of %v", locator)
+	}
 	return nil
 }
 
+func (r *cleanup) report(result Result) {
+	for _, image := range result.Images {
+		r.PrintStep("Remove image %v", image)
+	}
+	r.PrintStep("Reclaim %v from %v unreferenced blobs and %v manifests",
+		humanize.Bytes(uint64(result.ReclaimedBytes)), result.Blobs, result.Manifests)
+}
+
 func (r *cleanup) registryStart(ctx context.Context) error {
 	out, err := r.serviceStart(ctx)
 	if err != nil {
@@ -34,6 +34,7 @@ import (
 
 	"github.com/gravitational/trace"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
 )
 
 // New returns new garbage collector for the specified configuration
@@ -103,6 +104,23 @@ func (r *Collector) RunPhase(ctx context.Context, phase string, phaseTimeout tim
 	}))
 }
 
+// DryRun returns the actions the specified garbage collection phase
+// would perform without executing it.
+func (r *Collector) DryRun(ctx context.Context, phase string, force bool) ([]libfsm.Action, error) {
+	machine, err := r.init()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	actions, err := machine.DryRun(ctx, libfsm.Params{
+		PhaseID: phase,
+		Force:   force,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return actions, nil
+}
+
 // SetPhase sets the specified phase state without executing it.
 func (r *Collector) SetPhase(ctx context.Context, phase, state string) error {
 	machine, err := r.init()
@@ -135,6 +153,7 @@ func (r *Collector) init() (*libfsm.FSM, error) {
 		App:           r.App,
 		RemoteApps:    r.RemoteApps,
 		Apps:          r.Apps,
+		Client:        r.Client,
 		Packages:      r.Packages,
 		LocalPackages: r.LocalPackages,
 		Operation:     r.Operation,
@@ -226,6 +245,12 @@ type Config struct {
 	RemoteApps []storage.Application
 	// Apps is the cluster application service
 	Apps app.Applications
+	// Client is the cluster kubernetes client.
+	// It is required to prune docker images on master nodes
+	Client kubernetes.Interface
+	// RegistryAllowlist lists additional docker images to retain
+	// in the registry as repository[:tag] patterns
+	RegistryAllowlist []string
 	// Packages is the cluster package service
 	Packages libpack.PackageService
 	// LocalPackages is the service for packages local to the node
@@ -22,11 +22,14 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"time"
 
 	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/app/hooks"
-	"github.com/gravitational/gravity/lib/archive"
+	libbackup "github.com/gravitational/gravity/lib/backup"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/localenv"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/utils"
@@ -71,6 +74,10 @@ func backup(env *localenv.LocalEnvironment, tarball string, timeout time.Duratio
 					log.Errorf("failed to remove backup directory %s: %v", backupPath, err)
 				}
 			}()
+			err = writeBackupManifest(apps, req.Application, backupPath)
+			if err != nil {
+				return trace.Wrap(err)
+			}
 			err = compressDirectory(backupPath, tarball)
 			if err != nil {
 				return trace.Wrap(err)
@@ -80,7 +87,7 @@ func backup(env *localenv.LocalEnvironment, tarball string, timeout time.Duratio
 		})
 }
 
-func restore(env *localenv.LocalEnvironment, tarball string, timeout time.Duration, follow, silent bool) error {
+func restore(env *localenv.LocalEnvironment, tarball string, timeout time.Duration, follow, silent bool, encryptionKey string) error {
 	ctx := context.Background()
 	// if we're streaming logs to stdout, no much sense in showing our progress indicator
 	noProgress := silent || follow
@@ -89,20 +96,20 @@ func restore(env *localenv.LocalEnvironment, tarball string, timeout time.Durati
 	progress.NextStep("restoring from %v", tarball)
 	return runBackupRestore(env, "restore",
 		func(env *localenv.LocalEnvironment, backupPath string, req *app.HookRunRequest) error {
-			f, err := os.Open(tarball)
-			if err != nil {
-				return trace.Wrap(err, "failed to open the tarball %q with backed up data", tarball)
-			}
-			defer f.Close()
-			err = dockerarchive.Untar(f, backupPath, archive.DefaultOptions())
-			if err != nil {
-				return trace.Wrap(err)
-			}
 			defer func() {
-				if err = os.RemoveAll(backupPath); err != nil {
+				if err := os.RemoveAll(backupPath); err != nil {
 					log.Errorf("failed to remove restore directory %s: %v", backupPath, err)
 				}
 			}()
+			manifest, err := unpackBackup(tarball, backupPath, encryptionKey)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			if manifest == nil {
+				log.Warnf("Backup %v has no manifest, skipping verification.", tarball)
+			} else {
+				setBackupHookDir(req, manifest.HookDir)
+			}
 			req.Hook = schema.HookRestore
 			if timeout != 0 {
 				req.Timeout = timeout
@@ -120,6 +127,163 @@ func restore(env *localenv.LocalEnvironment, tarball string, timeout time.Durati
 		})
 }
 
+// verifyRestore validates the backup tarball against its manifest and
+// runs the application verifyRestore hook in a scratch namespace.
+// The regular restore hook is never run as it may modify live data,
+// verification is refused if the application does not have a dedicated hook
+func verifyRestore(env *localenv.LocalEnvironment, tarball string, timeout time.Duration, follow, silent bool, encryptionKey string) error {
+	ctx := context.Background()
+	// if we're streaming logs to stdout, no much sense in showing our progress indicator
+	noProgress := silent || follow
+	progress := utils.NewProgress(ctx, "verify", 3, noProgress)
+	defer progress.Stop()
+	progress.NextStep("verifying %v", tarball)
+	err := runBackupRestore(env, "restore verification",
+		func(env *localenv.LocalEnvironment, backupPath string, req *app.HookRunRequest) error {
+			defer func() {
+				if err := os.RemoveAll(backupPath); err != nil {
+					log.Errorf("failed to remove restore directory %s: %v", backupPath, err)
+				}
+			}()
+			manifest, err := unpackBackup(tarball, backupPath, encryptionKey)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			if manifest == nil {
+				return trace.BadParameter("backup %v has no manifest and cannot be verified", tarball)
+			}
+			progress.NextStep("verified %v files of backup of %v", len(manifest.Files), manifest.Application)
+			apps, err := env.SiteApps()
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			if err := checkVerifyRestoreHook(apps, req.Application, *manifest); err != nil {
+				return trace.Wrap(err)
+			}
+			id, err := teleutils.CryptoRandomHex(3)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			setBackupHookDir(req, manifest.HookDir)
+			req.Hook = schema.HookVerifyRestore
+			req.Namespace = fmt.Sprintf("restore-verify-%v", id)
+			req.Env = map[string]string{constants.EnvRestoreVerify: "true"}
+			if timeout != 0 {
+				req.Timeout = timeout
+			}
+			ref, err := app.StreamAppHook(ctx, apps, *req, getStreamingWriter(silent, follow))
+			if ref != nil {
+				defer func() {
+					err := apps.DeleteAppHookJob(ctx, app.DeleteAppHookJobRequest{
+						HookRef:         *ref,
+						Cascade:         true,
+						DeleteNamespace: true,
+					})
+					if err != nil {
+						log.Warnf("failed to delete hook %v: %v",
+							ref, trace.DebugReport(err))
+					}
+				}()
+			}
+			if err != nil {
+				return trace.Wrap(err, "verifyRestore hook failed in namespace %v", req.Namespace)
+			}
+			progress.NextStep("verifyRestore hook succeeded in namespace %v", req.Namespace)
+			return nil
+		})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if silent {
+		return nil
+	}
+	fmt.Printf("Backup %v is restorable.\n", tarball)
+	return nil
+}
+
+// writeBackupManifest writes the manifest with checksums of the
+// backup data in dir along with the application hooks
+func writeBackupManifest(apps app.Applications, locator loc.Locator, dir string) error {
+	application, err := apps.GetApp(locator)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	manifest := libbackup.Manifest{
+		Created:     time.Now().UTC(),
+		Application: locator,
+	}
+	if hooks := application.Manifest.Hooks; hooks != nil {
+		manifest.BackupHook = hooks.Backup
+		manifest.RestoreHook = hooks.Restore
+		manifest.VerifyRestoreHook = hooks.VerifyRestore
+	}
+	_, err = libbackup.WriteManifest(dir, manifest)
+	return trace.Wrap(err)
+}
+
+// unpackBackup extracts the backup tarball into dir and validates it against
+// the backup manifest.
+// Returns nil manifest for backups taken without one
+func unpackBackup(tarball, dir, encryptionKey string) (*libbackup.Manifest, error) {
+	f, err := os.Open(tarball)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to open the tarball %q with backed up data", tarball)
+	}
+	defer f.Close()
+	if err := libbackup.Unpack(f, dir, encryptionKey); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if _, err := libbackup.ReadManifest(dir); err != nil {
+		if trace.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, trace.Wrap(err)
+	}
+	manifest, err := libbackup.Verify(dir)
+	if err != nil {
+		return nil, trace.Wrap(err, "backup %v failed verification", tarball)
+	}
+	return manifest, nil
+}
+
+// checkVerifyRestoreHook makes sure the installed application has
+// a dedicated hook to verify backups
+func checkVerifyRestoreHook(apps app.Applications, locator loc.Locator, manifest libbackup.Manifest) error {
+	application, err := apps.GetApp(locator)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	hook, err := schema.HookFromString(schema.HookVerifyRestore, application.Manifest)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.BadParameter("application %v does not have a %v hook, "+
+				"backup checksums are valid but the restore cannot be verified "+
+				"without touching live data", locator, schema.HookVerifyRestore)
+		}
+		return trace.Wrap(err)
+	}
+	if !locator.IsEqualTo(manifest.Application) {
+		log.Warnf("Backup was taken of %v, verifying with %v.", manifest.Application, locator)
+	}
+	if manifest.VerifyRestoreHook != nil && hook.Job != manifest.VerifyRestoreHook.Job {
+		log.Warnf("Verify restore hook of %v differs from the one in the backup.", locator)
+	}
+	return nil
+}
+
+// setBackupHookDir points the backup volume of the hook request
+// to the hook data directory relative to the backup root
+func setBackupHookDir(req *app.HookRunRequest, hookDir string) {
+	if hookDir == "" {
+		return
+	}
+	for _, volume := range req.Volumes {
+		if volume.Name == hooks.VolumeBackup && volume.HostPath != nil {
+			volume.HostPath.Path = path.Join(volume.HostPath.Path, hookDir)
+		}
+	}
+}
+
 func runBackupRestore(env *localenv.LocalEnvironment, operation string,
 	fn func(env *localenv.LocalEnvironment, backupPath string, req *app.HookRunRequest) error) (err error) {
 
@@ -0,0 +1,141 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/tool/common"
+
+	"github.com/buger/goterm"
+	"github.com/dustin/go-humanize"
+	"github.com/ghodss/yaml"
+	"github.com/gravitational/trace"
+)
+
+// listBackups lists the backups taken by the cluster backup policies
+func listBackups(env *localenv.LocalEnvironment, format constants.Format) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	backups, err := operator.ListBackups(context.TODO(), cluster.Key())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	switch format {
+	case constants.EncodingText:
+		if len(backups) == 0 {
+			env.Println("No backups found.")
+			return nil
+		}
+		t := goterm.NewTable(0, 10, 5, ' ', 0)
+		common.PrintTableHeader(t, []string{"Name", "Policy", "Created", "Size", "Encrypted", "Components"})
+		for _, backup := range backups {
+			fmt.Fprintf(t, "%v\t%v\t%v\t%v\t%v\t%v\n",
+				backup.Name,
+				backup.Policy,
+				backup.Created.Format(constants.HumanDateFormatSeconds),
+				humanize.Bytes(uint64(backup.Size)),
+				backup.Encrypted,
+				strings.Join(backup.Components, ", "))
+		}
+		fmt.Println(t.String())
+	case constants.EncodingJSON:
+		bytes, err := json.Marshal(backups)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Println(string(bytes))
+	case constants.EncodingYAML:
+		bytes, err := yaml.Marshal(backups)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Println(string(bytes))
+	default:
+		return trace.BadParameter("unsupported output format %q, supported are %v",
+			format, constants.OutputFormats)
+	}
+	return nil
+}
+
+// getBackup downloads the backup archive with the specified name to path
+func getBackup(env *localenv.LocalEnvironment, name, path string) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	reader, err := operator.GetBackupReader(context.TODO(), cluster.Key(), name)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+	if path == "" {
+		path = name + ".tar.gz"
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, defaults.PrivateFileMask)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	_, err = io.Copy(f, reader)
+	if err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		os.Remove(path)
+		return trace.Wrap(err)
+	}
+	env.Printf("Backup %v has been written to %v.\n", name, path)
+	return nil
+}
+
+// deleteBackup deletes the backup with the specified name
+func deleteBackup(env *localenv.LocalEnvironment, name string) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = operator.DeleteBackup(context.TODO(), cluster.Key(), name)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.Printf("Backup %v has been deleted.\n", name)
+	return nil
+}
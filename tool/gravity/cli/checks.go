@@ -17,19 +17,26 @@ limitations under the License.
 package cli
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/gravitational/gravity/lib/app/service"
 	"github.com/gravitational/gravity/lib/checks"
+	"github.com/gravitational/gravity/lib/checks/autofix"
 	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/localenv"
 	"github.com/gravitational/gravity/lib/pack"
 	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/state"
 	upgradechecks "github.com/gravitational/gravity/lib/update/cluster/checks"
+	"github.com/gravitational/gravity/lib/utils"
 
 	"github.com/fatih/color"
 	pb "github.com/gravitational/satellite/agent/proto/agentpb"
@@ -41,13 +48,40 @@ type preflightChecksConfig struct {
 	imagePath    string
 	profileName  string
 	autoFix      bool
-	timeout      time.Duration
+	// legacyAutoFix loads missing kernel modules and sets kernel parameters
+	// without confirmation, as the hidden --autofix flag always did
+	legacyAutoFix bool
+	stopServices  bool
+	confirmed     bool
+	revert        bool
+	timeout       time.Duration
+	format        constants.Format
 }
 
 func executePreflightChecks(env *localenv.LocalEnvironment, config preflightChecksConfig) error {
 	ctx, cancel := context.WithTimeout(context.Background(), config.timeout)
 	defer cancel()
 
+	if config.revert {
+		return revertFixes(ctx, env)
+	}
+
+	if config.stopServices && !config.autoFix {
+		return trace.BadParameter("--stop-services requires --fix")
+	}
+
+	if config.format != constants.EncodingText {
+		if !utils.StringInSlice(formatsToStrings(checks.ResultFormats), string(config.format)) {
+			return trace.BadParameter("unsupported output format %q, supported are %v",
+				config.format, append([]constants.Format{constants.EncodingText}, checks.ResultFormats...))
+		}
+		if config.autoFix && !config.confirmed {
+			return trace.BadParameter("--confirm is required with --fix and --format=%v", config.format)
+		}
+		// only the results are written to stdout
+		env.Silent = localenv.Silent(true)
+	}
+
 	err := localenv.DetectCluster(env)
 	if err != nil && !trace.IsNotFound(err) {
 		return trace.Wrap(err)
@@ -79,30 +113,167 @@ func checkInstall(ctx context.Context, env *localenv.LocalEnvironment, config pr
 		}
 	}
 	env.PrintStep("Running checks against node profile %q", profileName)
+	progress := utils.NewProgress(ctx, "check", -1, bool(env.Silent))
 	result, err := checks.ValidateLocal(ctx, checks.LocalChecksRequest{
 		Manifest: *manifest,
 		Role:     profileName,
-		AutoFix:  config.autoFix,
+		AutoFix:  config.legacyAutoFix && !config.autoFix,
 	})
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	if len(result.Failed)+len(result.Fixable) == 0 {
-		env.PrintStep(color.GreenString("Checks have succeeded!"))
+	if len(result.Warnings) != 0 {
+		env.PrintStep("%v", color.YellowString("The following checks produced warnings:\n%v",
+			checks.FormatFailedChecks(result.Warnings)))
+	}
+	fixer := autofix.Fixer{
+		Progress:          progress,
+		CreateDirectories: true,
+		StopServices:      config.stopServices,
+	}
+	failed, fixable := fixer.GetFixable(result.GetFailed())
+	fixed := result.Fixed
+	if len(fixed) != 0 {
+		env.PrintStep("%v", color.GreenString("The following checks have been fixed:\n%v",
+			checks.FormatFailedChecks(fixed)))
+	}
+	if len(fixable) != 0 && config.autoFix {
+		newlyFixed, unfixed, err := fixChecks(ctx, env, fixer, fixable, config.confirmed)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if len(newlyFixed) != 0 {
+			env.PrintStep("%v", color.GreenString("The following checks have been fixed:\n%v",
+				checks.FormatFailedChecks(newlyFixed)))
+		}
+		fixed = append(fixed, newlyFixed...)
+		failed, fixable = append(failed, unfixed...), nil
+	}
+	if config.format != constants.EncodingText {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		results := checks.NewResults(hostname, failed)
+		for _, probe := range fixable {
+			fixableResult := checks.NewResult(hostname, *probe)
+			fixableResult.Remediation, _ = fixer.Describe(probe)
+			results = append(results, fixableResult)
+		}
+		results = append(results, checks.NewResults(hostname, result.Warnings)...)
+		results = append(results, checks.NewResults(hostname, result.Passed)...)
+		for _, probe := range fixed {
+			fixedResult := checks.NewResult(hostname, *probe)
+			fixedResult.Status = checks.ResultFixed
+			results = append(results, fixedResult)
+		}
+		return trace.Wrap(writeCheckResults(results, config.format))
+	}
+	if len(failed)+len(fixable) == 0 {
+		env.PrintStep("%v", color.GreenString("Checks have succeeded!"))
 		return nil
 	}
 	var failedErr, fixableErr error
-	if len(result.Failed) > 0 {
+	if len(failed) > 0 {
 		failedErr = trace.BadParameter(fmt.Sprintf("The following checks failed:\n%v",
-			checks.FormatFailedChecks(result.Failed)))
+			checks.FormatFailedChecks(failed)))
 	}
-	if len(result.Fixable) > 0 {
-		fixableErr = trace.BadParameter(fmt.Sprintf("The following checks failed, provide --autofix flag to let gravity to autofix them:\n%v",
-			checks.FormatFailedChecks(result.Fixable)))
+	if len(fixable) > 0 {
+		fixableErr = trace.BadParameter(fmt.Sprintf("The following checks failed, provide --fix flag to let gravity fix them:\n%v",
+			checks.FormatFailedChecks(fixable)))
 	}
 	return trace.NewAggregate(failedErr, fixableErr)
 }
 
+// fixChecks attempts to fix the failed probes after confirmation
+// and records the changes made to the host so they can be reverted
+func fixChecks(ctx context.Context, env *localenv.LocalEnvironment, fixer autofix.Fixer, probes []*pb.Probe, confirmed bool) (fixed, unfixed []*pb.Probe, err error) {
+	var buf bytes.Buffer
+	for _, probe := range probes {
+		description, err := fixer.Describe(probe)
+		if err != nil {
+			return nil, nil, trace.Wrap(err)
+		}
+		fmt.Fprintf(&buf, "\t* %v\n", description)
+	}
+	env.PrintStep("The following changes will be made to fix the failed checks:\n%v", buf.String())
+	if !confirmed {
+		if err := enforceConfirmation("Proceed?"); err != nil {
+			return nil, nil, trace.Wrap(err)
+		}
+	}
+	path, err := autofixRecordPath()
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	record, err := autofix.ReadRecord(path)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	fixer.Record = record
+	fixed, unfixed = fixer.Fix(ctx, probes)
+	if err := autofix.WriteRecord(path, *record); err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	if len(record.Changes) != 0 {
+		env.PrintStep("Changes have been recorded in %v, use --revert flag to revert them", path)
+	}
+	return fixed, unfixed, nil
+}
+
+// revertFixes reverts the changes recorded by the previous runs with --fix
+func revertFixes(ctx context.Context, env *localenv.LocalEnvironment) error {
+	path, err := autofixRecordPath()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	record, err := autofix.ReadRecord(path)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(record.Changes) == 0 {
+		env.PrintStep("No changes to revert")
+		return nil
+	}
+	progress := utils.NewProgress(ctx, "revert", -1, bool(env.Silent))
+	remaining, revertErr := autofix.Revert(ctx, *record, progress)
+	if err := autofix.WriteRecord(path, *remaining); err != nil {
+		return trace.NewAggregate(revertErr, err)
+	}
+	if revertErr != nil {
+		return trace.Wrap(revertErr)
+	}
+	env.PrintStep("%v", color.GreenString("Reverted %v changes", len(record.Changes)))
+	return nil
+}
+
+// writeCheckResults outputs the check results to stdout in the specified format.
+// Returns an error if any of the checks has failed
+func writeCheckResults(results checks.Results, format constants.Format) error {
+	if err := results.Write(os.Stdout, format); err != nil {
+		return trace.Wrap(err)
+	}
+	if failed := results.Failed(); len(failed) != 0 {
+		return trace.BadParameter("%v checks failed", len(failed))
+	}
+	return nil
+}
+
+func formatsToStrings(formats []constants.Format) (result []string) {
+	for _, format := range formats {
+		result = append(result, string(format))
+	}
+	return result
+}
+
+func autofixRecordPath() (string, error) {
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return filepath.Join(stateDir, defaults.LocalDir, defaults.AutofixRecordFile), nil
+}
+
 func checkUpgrade(ctx context.Context, env *localenv.LocalEnvironment, config preflightChecksConfig) error {
 	tarballEnv, err := localenv.NewTarballEnvironment(localenv.TarballEnvironmentArgs{
 		StateDir: config.imagePath,
@@ -150,14 +321,21 @@ func checkUpgrade(ctx context.Context, env *localenv.LocalEnvironment, config pr
 	}
 	env.PrintStep("Running upgrade checks for cluster image %v:%v",
 		manifest.Metadata.Name, manifest.Metadata.ResourceVersion)
-	checksErr := checker.Run(ctx)
+	results := checker.Check(ctx)
 	if err := rpcAgentShutdown(env); err != nil {
 		log.WithError(err).Error("Failed to shutdown agents.")
 	}
-	if checksErr != nil {
-		return trace.Wrap(checksErr)
+	if config.format != constants.EncodingText {
+		return trace.Wrap(writeCheckResults(results, config.format))
+	}
+	if warnings := results.Warnings(); len(warnings) != 0 {
+		env.PrintStep("%v", color.YellowString("The following checks produced warnings:\n%v",
+			warnings.Format()))
+	}
+	if err := results.Error(); err != nil {
+		return trace.Wrap(err)
 	}
-	env.PrintStep(color.GreenString("Checks have succeeded!"))
+	env.PrintStep("%v", color.GreenString("Checks have succeeded!"))
 	return nil
 }
 
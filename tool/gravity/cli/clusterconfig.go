@@ -93,6 +93,9 @@ func executeConfigPhase(env *localenv.LocalEnvironment, environ LocalEnvironment
 		return trace.Wrap(err)
 	}
 	defer updater.Close()
+	if params.DryRun {
+		return trace.Wrap(dryRunUpdatePhase(updater, params))
+	}
 	err = updater.RunPhase(context.TODO(), params.PhaseID, params.Timeout, params.Force)
 	return trace.Wrap(err)
 }
@@ -225,12 +228,13 @@ func (configInitializer) newUpdater(
 ) (*update.Updater, error) {
 	config := clusterconfig.Config{
 		Config: update.Config{
-			Operation:    &operation,
-			Operator:     operator,
-			Backend:      clusterEnv.Backend,
-			LocalBackend: updateEnv.Backend,
-			Runner:       runner,
-			Silent:       localEnv.Silent,
+			Operation:         &operation,
+			Operator:          operator,
+			Backend:           clusterEnv.Backend,
+			LocalBackend:      updateEnv.Backend,
+			Runner:            runner,
+			Silent:            localEnv.Silent,
+			RollbackOnFailure: true,
 			FieldLogger: logrus.WithFields(logrus.Fields{
 				trace.Component: "update:clusterconfig",
 				"operation":     operation,
@@ -303,6 +307,7 @@ const (
 The operation might take a few minutes to complete.
 
 The operation will start automatically once you approve it.
+If the operation fails, the configuration changes are rolled back automatically.
 If you want to review the operation plan first or execute it manually step by step,
 run the operation in manual mode by specifying '--manual' flag.
 
@@ -18,6 +18,7 @@ package cli
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/constants"
@@ -64,6 +65,7 @@ func newUpgradeConfig(g *Application) (*upgradeConfig, error) {
 		Manual:           *g.UpgradeCmd.Manual,
 		SkipVersionCheck: *g.UpgradeCmd.SkipVersionCheck,
 		Values:           values,
+		ParallelPhases:   *g.UpgradeCmd.ParallelPhases,
 	}, nil
 }
 
@@ -77,6 +79,9 @@ type upgradeConfig struct {
 	SkipVersionCheck bool
 	// Values are helm values in a marshaled yaml format.
 	Values []byte
+	// ParallelPhases is the maximum number of independent plan phases
+	// to execute concurrently.
+	ParallelPhases int
 }
 
 func updateTrigger(
@@ -104,9 +109,10 @@ func newClusterUpdater(
 	config upgradeConfig,
 ) (updater, error) {
 	init := &clusterInitializer{
-		updatePackage: config.UpgradePackage,
-		unattended:    !config.Manual,
-		values:        config.Values,
+		updatePackage:  config.UpgradePackage,
+		unattended:     !config.Manual,
+		values:         config.Values,
+		parallelPhases: config.ParallelPhases,
 	}
 	updater, err := newUpdater(ctx, localEnv, updateEnv, init)
 	if err != nil {
@@ -132,10 +138,22 @@ func executeUpdatePhase(env *localenv.LocalEnvironment, environ LocalEnvironment
 		return trace.Wrap(err)
 	}
 	defer updater.Close()
+	if params.DryRun {
+		return trace.Wrap(dryRunUpdatePhase(updater, params))
+	}
 	err = updater.RunPhase(context.TODO(), params.PhaseID, params.Timeout, params.Force)
 	return trace.Wrap(err)
 }
 
+func dryRunUpdatePhase(updater *update.Updater, params PhaseParams) error {
+	actions, err := updater.DryRun(context.TODO(), params.PhaseID, params.Force)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	printDryRun(actions)
+	return nil
+}
+
 func rollbackUpdatePhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params PhaseParams, operation ops.SiteOperation) error {
 	updateEnv, err := environ.NewUpdateEnv()
 	if err != nil {
@@ -263,7 +281,7 @@ func (r clusterInitializer) newOperationPlan(
 	return plan, nil
 }
 
-func (clusterInitializer) newUpdater(
+func (r clusterInitializer) newUpdater(
 	ctx context.Context,
 	operator ops.Operator,
 	operation ops.SiteOperation,
@@ -273,11 +291,12 @@ func (clusterInitializer) newUpdater(
 ) (*update.Updater, error) {
 	config := clusterupdate.Config{
 		Config: update.Config{
-			Operation:    &operation,
-			Operator:     clusterEnv.Operator,
-			Backend:      clusterEnv.Backend,
-			LocalBackend: updateEnv.Backend,
-			Runner:       runner,
+			Operation:      &operation,
+			Operator:       clusterEnv.Operator,
+			Backend:        clusterEnv.Backend,
+			LocalBackend:   updateEnv.Backend,
+			Runner:         runner,
+			ParallelPhases: r.parallelPhases,
 		},
 		HostLocalBackend:  localEnv.Backend,
 		HostLocalPackages: localEnv.Packages,
@@ -293,6 +312,9 @@ func (clusterInitializer) newUpdater(
 func (r clusterInitializer) updateDeployRequest(req deployAgentsRequest) deployAgentsRequest {
 	if r.unattended {
 		req.leaderParams = constants.RPCAgentUpgradeFunction
+		if r.parallelPhases > 0 {
+			req.leaderParams = fmt.Sprintf("%v %v", req.leaderParams, r.parallelPhases)
+		}
 	}
 	return req
 }
@@ -302,6 +324,9 @@ type clusterInitializer struct {
 	updatePackage string
 	unattended    bool
 	values        []byte
+	// parallelPhases is the maximum number of independent plan phases
+	// to execute concurrently
+	parallelPhases int
 }
 
 const (
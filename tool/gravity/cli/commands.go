@@ -24,6 +24,7 @@ import (
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/storage"
 
+	"github.com/alecthomas/units"
 	"github.com/gravitational/configure"
 	"gopkg.in/alecthomas/kingpin.v2"
 )
@@ -106,12 +107,24 @@ type Application struct {
 	RegistryCmd RegistryCmd
 	// RegistryListCmd displays images from the registry
 	RegistryListCmd RegistryListCmd
-	// BackupCmd launches app backup hook
+	// BackupCmd manages cluster backups
 	BackupCmd BackupCmd
+	// BackupCreateCmd launches app backup hook
+	BackupCreateCmd BackupCreateCmd
+	// BackupListCmd lists backups taken by backup policies
+	BackupListCmd BackupListCmd
+	// BackupGetCmd downloads a backup taken by a backup policy
+	BackupGetCmd BackupGetCmd
+	// BackupDeleteCmd deletes a backup taken by a backup policy
+	BackupDeleteCmd BackupDeleteCmd
 	// RestoreCmd launches app restore hook
 	RestoreCmd RestoreCmd
-	// CheckCmd checks that the host satisfies app manifest requirements
+	// CheckCmd combines subcommands for checks
 	CheckCmd CheckCmd
+	// CheckPreflightCmd checks that the host satisfies app manifest requirements
+	CheckPreflightCmd CheckPreflightCmd
+	// CheckNetworkCmd measures network performance between cluster nodes
+	CheckNetworkCmd CheckNetworkCmd
 	// AppCmd combines subcommands for app service
 	AppCmd AppCmd
 	// AppInstallCmd installs an application from an application image
@@ -150,8 +163,12 @@ type Application struct {
 	AppPullCmd AppPullCmd
 	// AppPushCmd pushes app to specified cluster
 	AppPushCmd AppPushCmd
-	// AppHookCmd launches specified app hook
+	// AppHookCmd combines subcommands for app hooks
 	AppHookCmd AppHookCmd
+	// AppHookRunCmd launches specified app hook
+	AppHookRunCmd AppHookRunCmd
+	// AppHookHistoryCmd displays the history of app hook runs
+	AppHookHistoryCmd AppHookHistoryCmd
 	// AppUnpackCmd unpacks specified app resources
 	AppUnpackCmd AppUnpackCmd
 	// WizardCmd starts installer in UI mode
@@ -180,6 +197,8 @@ type Application struct {
 	PackListCmd PackListCmd
 	// PackDeleteCmd deletes specified package
 	PackDeleteCmd PackDeleteCmd
+	// PackMigrateCmd converts local packages to deduplicated storage
+	PackMigrateCmd PackMigrateCmd
 	// PackConfigureCmd configures package
 	PackConfigureCmd PackConfigureCmd
 	// PackCommandCmd launches package command
@@ -274,6 +293,12 @@ type Application struct {
 	SystemReportCmd SystemReportCmd
 	// SystemStateDirCmd shows local state directory
 	SystemStateDirCmd SystemStateDirCmd
+	// SystemStateCmd combines storage state related subcommands
+	SystemStateCmd SystemStateCmd
+	// SystemStateExportCmd exports the storage backend state to an archive
+	SystemStateExportCmd SystemStateExportCmd
+	// SystemStateImportCmd imports the storage backend state from an archive
+	SystemStateImportCmd SystemStateImportCmd
 	// SystemDevicemapperCmd combines devicemapper related subcommands
 	SystemDevicemapperCmd SystemDevicemapperCmd
 	// SystemDevicemapperMountCmd configures devicemapper environment
@@ -315,6 +340,14 @@ type Application struct {
 	ResourceRemoveCmd ResourceRemoveCmd
 	// ResourceGetCmd shows specified resource
 	ResourceGetCmd ResourceGetCmd
+	// ResourceDiffCmd shows the difference between declared and cluster resources
+	ResourceDiffCmd ResourceDiffCmd
+	// ResourceApplyCmd synchronizes cluster resources with declared resources
+	ResourceApplyCmd ResourceApplyCmd
+	// ResourceHistoryCmd displays the history of resource changes
+	ResourceHistoryCmd ResourceHistoryCmd
+	// ResourceRollbackCmd re-applies a previous resource revision
+	ResourceRollbackCmd ResourceRollbackCmd
 	// TopCmd displays cluster metrics in terminal
 	TopCmd TopCmd
 }
@@ -402,6 +435,9 @@ type InstallCmd struct {
 	Set *[]string
 	// Values is a list of YAML files with Helm chart values.
 	Values *[]string
+	// ParallelPhases is the maximum number of independent plan phases
+	// to execute concurrently
+	ParallelPhases *int
 }
 
 // JoinCmd joins to the installer or existing cluster
@@ -433,6 +469,9 @@ type JoinCmd struct {
 	// the client will simply connect to the service and stream its output and errors
 	// and control whether it should stop
 	FromService *bool
+	// ParallelPhases is the maximum number of independent plan phases
+	// to execute concurrently
+	ParallelPhases *int
 }
 
 // AutoJoinCmd uses cloud provider info to join existing cluster
@@ -502,6 +541,9 @@ type PlanCmd struct {
 	OperationID *string
 	// SkipVersionCheck suppresses version mismatch errors
 	SkipVersionCheck *bool
+	// DryRun displays the actions the plan phases would perform
+	// without executing them
+	DryRun *bool
 }
 
 // PlanDisplayCmd displays plan of a specific operation
@@ -511,6 +553,12 @@ type PlanDisplayCmd struct {
 	Output *constants.Format
 	// Short is a shorthand for short output format
 	Short *bool
+	// Phase optionally limits the output to the specified phase
+	Phase *string
+	// Logs displays the log captured during phase execution
+	Logs *bool
+	// Timing displays the phase execution timing summary
+	Timing *bool
 }
 
 // PlanExecuteCmd executes a phase of an active operation
@@ -658,6 +706,9 @@ type UpgradeCmd struct {
 	Set *[]string
 	// Values is a list of YAML files with Helm chart values.
 	Values *[]string
+	// ParallelPhases is the maximum number of independent plan phases
+	// to execute concurrently
+	ParallelPhases *int
 }
 
 // StatusCmd displays cluster status
@@ -700,9 +751,14 @@ type RegistryListCmd struct {
 	Format *constants.Format
 }
 
-// BackupCmd launches app backup hook
+// BackupCmd manages cluster backups
 type BackupCmd struct {
 	*kingpin.CmdClause
+}
+
+// BackupCreateCmd launches app backup hook
+type BackupCreateCmd struct {
+	*kingpin.CmdClause
 	// Tarball is backup tarball name
 	Tarball *string
 	// Timeout is operation timeout
@@ -711,6 +767,29 @@ type BackupCmd struct {
 	Follow *bool
 }
 
+// BackupListCmd lists backups taken by backup policies
+type BackupListCmd struct {
+	*kingpin.CmdClause
+	// Format is the output format
+	Format *constants.Format
+}
+
+// BackupGetCmd downloads a backup taken by a backup policy
+type BackupGetCmd struct {
+	*kingpin.CmdClause
+	// Name is the backup name
+	Name *string
+	// Output is the path to write the backup archive to
+	Output *string
+}
+
+// BackupDeleteCmd deletes a backup taken by a backup policy
+type BackupDeleteCmd struct {
+	*kingpin.CmdClause
+	// Name is the backup name
+	Name *string
+}
+
 // RestoreCmd launches app restore hook
 type RestoreCmd struct {
 	*kingpin.CmdClause
@@ -720,23 +799,60 @@ type RestoreCmd struct {
 	Timeout *time.Duration
 	// Follow tails operation logs
 	Follow *bool
+	// Verify validates the backup and runs the restore hook
+	// in a scratch namespace instead of restoring
+	Verify *bool
+	// EncryptionKey is the key to decrypt an encrypted backup with
+	EncryptionKey *string
 }
 
-// CheckCmd checks that the host satisfies app manifest requirements
+// CheckCmd combines subcommands for checks
 type CheckCmd struct {
 	*kingpin.CmdClause
+}
+
+// CheckPreflightCmd checks that the host satisfies app manifest requirements
+type CheckPreflightCmd struct {
+	*kingpin.CmdClause
 	// ManifestFile is path to app manifest file
 	ManifestFile *string
 	// Profile is profile name to check against
 	Profile *string
 	// AutoFix enables automatic fixing of some failed checks
 	AutoFix *bool
+	// LegacyAutoFix loads missing kernel modules and sets kernel parameters
+	// without confirmation and change record
+	LegacyAutoFix *bool
+	// StopServices allows fixes that stop conflicting services
+	StopServices *bool
+	// Confirmed suppresses the confirmation prompt before fixing
+	Confirmed *bool
+	// Revert reverts the changes made by the previous fixes
+	Revert *bool
+	// Format is the output format: text, json or junit
+	Format *constants.Format
 	// ImagePath is path to unpacked cluster image
 	ImagePath *string
 	// Timeout is the time allotted to run preflight checks
 	Timeout *time.Duration
 }
 
+// CheckNetworkCmd measures latency, packet loss, MTU and bandwidth
+// between every pair of cluster nodes
+type CheckNetworkCmd struct {
+	*kingpin.CmdClause
+	// Bandwidth enables the bandwidth test
+	Bandwidth *bool
+	// PingCount is the number of packets sent to each node
+	PingCount *int
+	// Interval repeats the test with the specified interval
+	Interval *time.Duration
+	// History displays the saved results instead of running the test
+	History *bool
+	// Format is the output format
+	Format *constants.Format
+}
+
 // AppCmd combines subcommands for app service
 type AppCmd struct {
 	*kingpin.CmdClause
@@ -964,9 +1080,14 @@ type AppPushCmd struct {
 	OpsCenterURL *string
 }
 
-// AppHookCmd launches specified app hook
+// AppHookCmd combines subcommands for app hooks
 type AppHookCmd struct {
 	*kingpin.CmdClause
+}
+
+// AppHookRunCmd launches specified app hook
+type AppHookRunCmd struct {
+	*kingpin.CmdClause
 	// Package is app locator
 	Package *loc.Locator
 	// HookName specifies hook to launch
@@ -975,6 +1096,21 @@ type AppHookCmd struct {
 	Env *map[string]string
 }
 
+// AppHookHistoryCmd displays the history of app hook runs
+type AppHookHistoryCmd struct {
+	*kingpin.CmdClause
+	// App optionally selects the runs of the specified application
+	App *string
+	// Hook optionally selects the runs of the specified hook
+	Hook *string
+	// Limit is the maximum number of runs to display
+	Limit *int
+	// ID displays the details and logs of the run with the specified ID
+	ID *string
+	// Format is the output format
+	Format *constants.Format
+}
+
 // AppUnpackCmd unpacks app resources
 type AppUnpackCmd struct {
 	*kingpin.CmdClause
@@ -1133,6 +1269,11 @@ type PackDeleteCmd struct {
 	OpsCenterURL *string
 }
 
+// PackMigrateCmd converts local packages to deduplicated storage
+type PackMigrateCmd struct {
+	*kingpin.CmdClause
+}
+
 // PackConfigureCmd configures package
 type PackConfigureCmd struct {
 	*kingpin.CmdClause
@@ -1283,6 +1424,24 @@ type ReportCmd struct {
 	*kingpin.CmdClause
 	// FilePath is the report tarball path
 	FilePath *string
+	// Profile is the report profile
+	Profile *string
+	// Collectors overrides the collectors of the profile
+	Collectors *[]string
+	// Since overrides the start of the period of the collected logs.
+	// Either a duration before now or a timestamp
+	Since *string
+	// Until limits the collected logs to the entries before the specified
+	// duration before now or timestamp
+	Until *string
+	// MaxSize limits the total size of the report
+	MaxSize *units.Base2Bytes
+	// Namespaces overrides the Kubernetes namespaces to collect diagnostics for
+	Namespaces *[]string
+	// Nodes overrides the nodes to collect diagnostics from
+	Nodes *[]string
+	// RedactIPs scrubs IP addresses from the report
+	RedactIPs *bool
 }
 
 // SiteCmd combines cluster related subcommands
@@ -1531,6 +1690,16 @@ type SystemReportCmd struct {
 	Filter *[]string
 	// Compressed allows to gzip the tarball
 	Compressed *bool
+	// Since limits the collected logs to the entries after the specified
+	// duration before now or timestamp
+	Since *string
+	// Until limits the collected logs to the entries before the specified
+	// duration before now or timestamp
+	Until *string
+	// MaxSize limits the total size of the collected diagnostics
+	MaxSize *units.Base2Bytes
+	// Namespaces limits Kubernetes diagnostics to the specified namespaces
+	Namespaces *[]string
 }
 
 // SystemStateDirCmd shows local state directory
@@ -1538,6 +1707,41 @@ type SystemStateDirCmd struct {
 	*kingpin.CmdClause
 }
 
+// SystemStateCmd combines storage state related subcommands
+type SystemStateCmd struct {
+	*kingpin.CmdClause
+}
+
+// SystemStateExportCmd exports the storage backend state to an archive
+type SystemStateExportCmd struct {
+	*kingpin.CmdClause
+	// Path is the path to the archive
+	Path *string
+	// Backend is the type of the backend to export the state from
+	Backend *string
+	// BoltPath is the path to the bolt database
+	BoltPath *string
+	// PostgresURI is the PostgreSQL connection URI
+	PostgresURI *string
+}
+
+// SystemStateImportCmd imports the storage backend state from an archive
+type SystemStateImportCmd struct {
+	*kingpin.CmdClause
+	// Path is the path to the archive
+	Path *string
+	// Backend is the type of the backend to import the state into
+	Backend *string
+	// BoltPath is the path to the bolt database
+	BoltPath *string
+	// PostgresURI is the PostgreSQL connection URI
+	PostgresURI *string
+	// DryRun only reports the changes without applying them
+	DryRun *bool
+	// Force overwrites the conflicting items
+	Force *bool
+}
+
 // SystemDevicemapperCmd combines devicemapper related subcommands
 type SystemDevicemapperCmd struct {
 	*kingpin.CmdClause
@@ -1565,11 +1769,19 @@ type SystemExportRuntimeJournalCmd struct {
 	*kingpin.CmdClause
 	// OutputFile specifies the path of the resulting tarball
 	OutputFile *string
+	// Since limits the exported journal to the entries after the specified time
+	Since *string
+	// Until limits the exported journal to the entries before the specified time
+	Until *string
 }
 
 // SystemStreamRuntimeJournalCmd streams contents of the runtime journal
 type SystemStreamRuntimeJournalCmd struct {
 	*kingpin.CmdClause
+	// Since limits the journal to the entries after the specified time
+	Since *string
+	// Until limits the journal to the entries before the specified time
+	Until *string
 }
 
 // SystemGCJournalCmd manages cleanup of journal files
@@ -1603,6 +1815,8 @@ type SystemGCRegistryCmd struct {
 	// DryRun displays the images to be removed
 	// without actually removing anything
 	DryRun *bool
+	// Allowlist lists additional images to retain
+	Allowlist *[]string
 }
 
 // GarbageCollectCmd prunes unused cluster resources
@@ -1613,6 +1827,8 @@ type GarbageCollectCmd struct {
 	// Confirmed is whether the user has confirmed the removal of custom docker
 	// images
 	Confirmed *bool
+	// RegistryAllowlist lists additional images to retain in the cluster registry
+	RegistryAllowlist *[]string
 }
 
 // GarbageCollectPlanCmd displays the plan of the garbage collection operation
@@ -1718,6 +1934,67 @@ type ResourceGetCmd struct {
 	User *string
 }
 
+// ResourceDiffCmd shows the difference between declared and cluster resources
+type ResourceDiffCmd struct {
+	*kingpin.CmdClause
+	// Path is path to a resource file or a directory with resource files
+	Path *string
+	// Prune includes removal of resources that are not declared
+	Prune *bool
+	// PruneKinds limits the resource kinds to prune
+	PruneKinds *[]string
+	// User is resource owner
+	User *string
+}
+
+// ResourceApplyCmd synchronizes cluster resources with declared resources
+type ResourceApplyCmd struct {
+	*kingpin.CmdClause
+	// Path is path to a resource file or a directory with resource files
+	Path *string
+	// Prune removes resources that are not declared
+	Prune *bool
+	// PruneKinds limits the resource kinds to prune
+	PruneKinds *[]string
+	// User is resource owner
+	User *string
+	// Manual controls whether an operation is created in manual mode.
+	// If resource is managed with the help of a cluster operation,
+	// setting this to true will not cause the operation to start automatically
+	Manual *bool
+	// Confirmed suppresses confirmation prompt
+	Confirmed *bool
+}
+
+// ResourceHistoryCmd displays the history of resource changes
+type ResourceHistoryCmd struct {
+	*kingpin.CmdClause
+	// Resource is the resource as kind or kind/name
+	Resource *string
+	// Revision optionally selects the revision to display
+	Revision *int
+	// Limit is the maximum number of revisions to display
+	Limit *int
+	// Format is output format
+	Format *constants.Format
+}
+
+// ResourceRollbackCmd re-applies a previous resource revision
+type ResourceRollbackCmd struct {
+	*kingpin.CmdClause
+	// Resource is the resource as kind or kind/name
+	Resource *string
+	// Revision is the revision to roll back to.
+	// Defaults to the revision preceding the latest one
+	Revision *int
+	// Manual controls whether an operation is created in manual mode.
+	// If resource is managed with the help of a cluster operation,
+	// setting this to true will not cause the operation to start automatically
+	Manual *bool
+	// Confirmed suppresses confirmation prompt
+	Confirmed *bool
+}
+
 // TopCmd displays cluster metrics in terminal.
 type TopCmd struct {
 	*kingpin.CmdClause
@@ -164,6 +164,9 @@ type InstallConfig struct {
 	writeStateDir string
 	// Values are helm values in marshaled yaml format
 	Values []byte
+	// ParallelPhases is the maximum number of independent plan phases
+	// to execute concurrently
+	ParallelPhases int
 }
 
 // NewInstallConfig creates install config from the passed CLI args and flags
@@ -215,6 +218,7 @@ func NewInstallConfig(env *localenv.LocalEnvironment, g *Application) (*InstallC
 		Remote:             *g.InstallCmd.Remote,
 		FromService:        *g.InstallCmd.FromService,
 		Values:             values,
+		ParallelPhases:     *g.InstallCmd.ParallelPhases,
 		Printer:            env,
 	}, nil
 }
@@ -413,6 +417,7 @@ func (i *InstallConfig) NewInstallerConfig(
 		Operator:           wizard.Operator,
 		LocalAgent:         !i.Remote,
 		Values:             i.Values,
+		ParallelPhases:     i.ParallelPhases,
 	}, nil
 
 }
@@ -677,23 +682,27 @@ type JoinConfig struct {
 	// SkipWizard specifies to the join agents that this join request is not too a wizard,
 	// and as such wizard connectivity should be skipped
 	SkipWizard bool
+	// ParallelPhases is the maximum number of independent plan phases
+	// to execute concurrently
+	ParallelPhases int
 }
 
 // NewJoinConfig populates join configuration from the provided CLI application
 func NewJoinConfig(g *Application) JoinConfig {
 	return JoinConfig{
-		SystemLogFile: *g.SystemLogFile,
-		UserLogFile:   *g.UserLogFile,
-		PeerAddrs:     *g.JoinCmd.PeerAddr,
-		AdvertiseAddr: *g.JoinCmd.AdvertiseAddr,
-		ServerAddr:    *g.JoinCmd.ServerAddr,
-		Token:         *g.JoinCmd.Token,
-		Role:          *g.JoinCmd.Role,
-		SystemDevice:  *g.JoinCmd.SystemDevice,
-		DockerDevice:  *g.JoinCmd.DockerDevice,
-		Mounts:        *g.JoinCmd.Mounts,
-		OperationID:   *g.JoinCmd.OperationID,
-		FromService:   *g.JoinCmd.FromService,
+		SystemLogFile:  *g.SystemLogFile,
+		UserLogFile:    *g.UserLogFile,
+		PeerAddrs:      *g.JoinCmd.PeerAddr,
+		AdvertiseAddr:  *g.JoinCmd.AdvertiseAddr,
+		ServerAddr:     *g.JoinCmd.ServerAddr,
+		Token:          *g.JoinCmd.Token,
+		Role:           *g.JoinCmd.Role,
+		SystemDevice:   *g.JoinCmd.SystemDevice,
+		DockerDevice:   *g.JoinCmd.DockerDevice,
+		Mounts:         *g.JoinCmd.Mounts,
+		OperationID:    *g.JoinCmd.OperationID,
+		FromService:    *g.JoinCmd.FromService,
+		ParallelPhases: *g.JoinCmd.ParallelPhases,
 	}
 }
 
@@ -732,6 +741,7 @@ func (j *JoinConfig) NewPeerConfig(env, joinEnv *localenv.LocalEnvironment) (con
 		StateDir:           joinEnv.StateDir,
 		OperationID:        j.OperationID,
 		SkipWizard:         j.SkipWizard,
+		ParallelPhases:     j.ParallelPhases,
 	}, nil
 }
 
@@ -1118,7 +1128,10 @@ func InstallerGenerateLocalReport(env *localenv.LocalEnvironment) func(context.C
 				os.Remove(f.Name())
 			}
 		}()
-		err = systemReport(env, report.AllFilters, true, f)
+		err = systemReport(env, report.Config{
+			Filters:    report.DefaultFilters,
+			Compressed: true,
+		}, f)
 		if err != nil {
 			return trace.ConvertSystemError(err)
 		}
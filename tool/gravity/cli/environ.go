@@ -83,6 +83,9 @@ func executeEnvironPhase(env *localenv.LocalEnvironment, environ LocalEnvironmen
 		return trace.Wrap(err)
 	}
 	defer updater.Close()
+	if params.DryRun {
+		return trace.Wrap(dryRunUpdatePhase(updater, params))
+	}
 	err = updater.RunPhase(context.TODO(), params.PhaseID, params.Timeout, params.Force)
 	return trace.Wrap(err)
 }
@@ -18,14 +18,14 @@ package cli
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
-	"github.com/gravitational/gravity/lib/docker"
 	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/httplib"
 	"github.com/gravitational/gravity/lib/localenv"
 	"github.com/gravitational/gravity/lib/ops"
-	"github.com/gravitational/gravity/lib/state"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/vacuum"
 	"github.com/gravitational/gravity/lib/vacuum/prune"
@@ -37,10 +37,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func garbageCollect(env *localenv.LocalEnvironment, manual, confirmed bool) error {
+func garbageCollect(env *localenv.LocalEnvironment, manual, confirmed bool, registryAllowlist []string) error {
 	if !confirmed {
-		env.Println("This operation will also remove docker images that " +
-			"you manually pushed to the docker registry. Are you sure?")
+		env.Println(registryPruneWarning("--registry-allow"))
 		resp, err := confirm()
 		if err != nil {
 			return trace.Wrap(err)
@@ -51,7 +50,7 @@ func garbageCollect(env *localenv.LocalEnvironment, manual, confirmed bool) erro
 		}
 	}
 
-	collector, err := newCollector(env)
+	collector, err := newCollector(env, registryAllowlist)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -84,7 +83,7 @@ $ gravity plan resume`)
 	return nil
 }
 
-func newCollector(env *localenv.LocalEnvironment) (*vacuum.Collector, error) {
+func newCollector(env *localenv.LocalEnvironment, registryAllowlist []string) (*vacuum.Collector, error) {
 	clusterPackages, err := env.ClusterPackages()
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -186,17 +185,19 @@ func newCollector(env *localenv.LocalEnvironment) (*vacuum.Collector, error) {
 			Locator:  cluster.App.Package,
 			Manifest: cluster.App.Manifest,
 		},
-		RemoteApps:    remoteApps,
-		Apps:          clusterApps,
-		Packages:      clusterPackages,
-		LocalPackages: env.Packages,
-		Operator:      operator,
-		Operation:     operation,
-		Servers:       cluster.ClusterState.Servers,
-		ClusterKey:    cluster.Key(),
-		RuntimePath:   runtimePath,
-		Silent:        env.Silent,
-		Runner:        runner,
+		RemoteApps:        remoteApps,
+		RegistryAllowlist: registryAllowlist,
+		Apps:              clusterApps,
+		Client:            clusterEnv.Client,
+		Packages:          clusterPackages,
+		LocalPackages:     env.Packages,
+		Operator:          operator,
+		Operation:         operation,
+		Servers:           cluster.ClusterState.Servers,
+		ClusterKey:        cluster.Key(),
+		RuntimePath:       runtimePath,
+		Silent:            env.Silent,
+		Runner:            runner,
 	})
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -243,7 +244,7 @@ func getGarbageCollector(env *localenv.LocalEnvironment, operation *ops.SiteOper
 	}
 	runner := libfsm.NewAgentRunner(creds)
 
-	return vacuum.New(vacuum.Config{
+	config := vacuum.Config{
 		App: &storage.Application{
 			Locator:  cluster.App.Package,
 			Manifest: cluster.App.Manifest,
@@ -258,7 +259,15 @@ func getGarbageCollector(env *localenv.LocalEnvironment, operation *ops.SiteOper
 		RuntimePath:   runtimePath,
 		Silent:        env.Silent,
 		Runner:        runner,
-	})
+	}
+	// the kubernetes client is only required to prune docker images on masters
+	client, _, err := httplib.GetClusterKubeClient(env.DNS.Addr())
+	if err != nil {
+		log.Warnf("Failed to create kubernetes client: %v.", trace.DebugReport(err))
+	} else {
+		config.Client = client
+	}
+	return vacuum.New(config)
 }
 
 func executeGarbageCollectPhase(env *localenv.LocalEnvironment, params PhaseParams, operation *ops.SiteOperation) error {
@@ -266,6 +275,14 @@ func executeGarbageCollectPhase(env *localenv.LocalEnvironment, params PhasePara
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	if params.DryRun {
+		actions, err := collector.DryRun(context.TODO(), params.PhaseID, params.Force)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		printDryRun(actions)
+		return nil
+	}
 	return collector.RunPhase(context.TODO(), params.PhaseID, params.Timeout, params.Force)
 }
 
@@ -277,10 +294,9 @@ func setGarbageCollectPhase(env *localenv.LocalEnvironment, params SetPhaseParam
 	return collector.SetPhase(context.TODO(), params.PhaseID, params.State)
 }
 
-func removeUnusedImages(env *localenv.LocalEnvironment, dryRun, confirmed bool) error {
+func removeUnusedImages(env *localenv.LocalEnvironment, dryRun, confirmed bool, allowlist []string) error {
 	if !dryRun && !confirmed {
-		env.Println("This operation will also remove docker images that " +
-			"you manually pushed to the docker registry on this node. Are you sure?")
+		env.Println(registryPruneWarning("--allow"))
 		resp, err := confirm()
 		if err != nil {
 			return trace.Wrap(err)
@@ -291,37 +307,26 @@ func removeUnusedImages(env *localenv.LocalEnvironment, dryRun, confirmed bool)
 		}
 	}
 
-	stateDir, err := state.GetStateDir()
-	if err != nil {
-		return trace.Wrap(err)
-	}
-
 	clusterEnv, err := env.NewClusterEnvironment()
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
-	cluster, err := clusterEnv.Operator.GetLocalSite()
-	if err != nil {
-		return trace.Wrap(err)
+	if clusterEnv.Client == nil {
+		return trace.BadParameter("this operation can only be executed on one of the master nodes")
 	}
 
-	imageService, err := docker.NewImageService(docker.RegistryConnectionRequest{
-		RegistryAddress: constants.LocalRegistryAddr,
-		CertName:        constants.DockerRegistry,
-		CACertPath:      state.Secret(stateDir, defaults.RootCertFilename),
-		ClientCertPath:  state.Secret(stateDir, "kubelet.cert"),
-		ClientKeyPath:   state.Secret(stateDir, "kubelet.key"),
-	})
+	cluster, err := clusterEnv.Operator.GetLocalSite()
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
 	config := registry.Config{
-		App:          &cluster.App.Package,
-		Apps:         clusterEnv.Apps,
-		Packages:     clusterEnv.Packages,
-		ImageService: imageService,
+		App:       &cluster.App.Package,
+		Apps:      clusterEnv.Apps,
+		Packages:  clusterEnv.Packages,
+		Client:    clusterEnv.Client,
+		Allowlist: allowlist,
 		Config: prune.Config{
 			DryRun:      dryRun,
 			FieldLogger: logrus.WithField(trace.Component, "gc/registry"),
@@ -447,3 +452,15 @@ func removeUnusedJournalFiles(env *localenv.LocalEnvironment, machineIDFile, log
 	err = pruner.Prune(context.TODO())
 	return trace.Wrap(err)
 }
+
+// registryPruneWarning returns the confirmation prompt for removing docker images
+// from the cluster registry that lists the images that are kept
+func registryPruneWarning(allowFlag string) string {
+	return fmt.Sprintf("This operation will remove all docker images from the cluster registry, "+
+		"including images that you manually pushed, except for:\n"+
+		"  * images of all applications in the cluster repository and their dependencies\n"+
+		"  * images of pods running in any namespace\n"+
+		"  * images of deployments, statefulsets, daemonsets, replicasets, jobs and cronjobs in any namespace\n"+
+		"  * images that match %v\n"+
+		"Are you sure?", allowFlag)
+}
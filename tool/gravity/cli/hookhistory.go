@@ -0,0 +1,186 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/tool/common"
+
+	"github.com/buger/goterm"
+	"github.com/fatih/color"
+	"github.com/gravitational/trace"
+)
+
+type appHookHistoryConfig struct {
+	app    string
+	hook   string
+	limit  int
+	id     string
+	format constants.Format
+}
+
+// appHookHistory displays the recorded application hook runs
+func appHookHistory(env *localenv.LocalEnvironment, config appHookHistoryConfig) error {
+	switch config.format {
+	case constants.EncodingText, constants.EncodingJSON:
+	default:
+		return trace.BadParameter("unsupported output format %q, supported are %v",
+			config.format, []constants.Format{constants.EncodingText, constants.EncodingJSON})
+	}
+	apps, err := env.SiteApps()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	filter := storage.HookRunFilter{
+		Hook:  config.hook,
+		Limit: config.limit,
+	}
+	if config.id != "" {
+		filter.Limit = 0
+	}
+	if config.app != "" {
+		filter.Application, err = parseHookHistoryApp(config.app)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	runs, err := apps.GetAppHookRuns(filter)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if config.id == "" {
+		return trace.Wrap(printHookRuns(os.Stdout, runs, config.format))
+	}
+	for _, run := range runs {
+		if run.ID == config.id {
+			return trace.Wrap(printHookRun(os.Stdout, run, config.format))
+		}
+	}
+	return trace.NotFound("hook run %q not found", config.id)
+}
+
+// parseHookHistoryApp parses the application specified as name,
+// repository/name or repository/name:version
+func parseHookHistoryApp(app string) (*loc.Locator, error) {
+	if locator, err := loc.ParseLocator(app); err == nil {
+		return locator, nil
+	}
+	locator := loc.Locator{Repository: defaults.SystemAccountOrg, Name: app}
+	if parts := strings.Split(app, "/"); len(parts) == 2 {
+		locator.Repository, locator.Name = parts[0], parts[1]
+	}
+	if locator.Repository == "" || locator.Name == "" || strings.ContainsAny(locator.Name, ":/") {
+		return nil, trace.BadParameter("invalid application %q, should be name, "+
+			"repository/name or repository/name:version", app)
+	}
+	return &locator, nil
+}
+
+// printHookRuns outputs the list of hook runs
+func printHookRuns(w io.Writer, runs []storage.HookRun, format constants.Format) error {
+	if format == constants.EncodingJSON {
+		if runs == nil {
+			runs = []storage.HookRun{}
+		}
+		return trace.Wrap(json.NewEncoder(w).Encode(runs))
+	}
+	if len(runs) == 0 {
+		fmt.Fprintln(w, "No hook runs found.")
+		return nil
+	}
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"ID", "Application", "Hook", "Started", "Duration", "State", "Exit Code"})
+	for _, run := range runs {
+		fmt.Fprintf(t, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			run.ID,
+			run.Application,
+			run.Hook,
+			run.Started.Format(constants.HumanDateFormatSeconds),
+			formatHookRunDuration(run),
+			formatHookRunState(run),
+			formatHookRunExitCode(run))
+	}
+	fmt.Fprintln(w, t.String())
+	return nil
+}
+
+// printHookRun outputs the details and logs of the hook run
+func printHookRun(w io.Writer, run storage.HookRun, format constants.Format) error {
+	if format == constants.EncodingJSON {
+		return trace.Wrap(json.NewEncoder(w).Encode(run))
+	}
+	fmt.Fprintf(w, "ID:\t\t%v\n", run.ID)
+	fmt.Fprintf(w, "Application:\t%v\n", run.Application)
+	fmt.Fprintf(w, "Hook:\t\t%v\n", run.Hook)
+	fmt.Fprintf(w, "Namespace:\t%v\n", run.Namespace)
+	fmt.Fprintf(w, "Started:\t%v\n", run.Started.Format(constants.HumanDateFormatSeconds))
+	if run.IsFinished() {
+		fmt.Fprintf(w, "Finished:\t%v (%v)\n", run.Finished.Format(constants.HumanDateFormatSeconds),
+			formatHookRunDuration(run))
+	}
+	fmt.Fprintf(w, "State:\t\t%v\n", formatHookRunState(run))
+	fmt.Fprintf(w, "Exit Code:\t%v\n", formatHookRunExitCode(run))
+	if run.Error != "" {
+		fmt.Fprintf(w, "Error:\t\t%v\n", run.Error)
+	}
+	if run.Logs == "" {
+		fmt.Fprintln(w, "\nNo logs have been captured.")
+		return nil
+	}
+	if run.LogsTruncated {
+		fmt.Fprintf(w, "\nLogs (last %v bytes):\n", defaults.HookRunLogsMaxSize)
+	} else {
+		fmt.Fprintln(w, "\nLogs:")
+	}
+	_, err := io.WriteString(w, run.Logs)
+	return trace.ConvertSystemError(err)
+}
+
+func formatHookRunDuration(run storage.HookRun) string {
+	if !run.IsFinished() {
+		return "-"
+	}
+	return run.Duration().Round(time.Second).String()
+}
+
+func formatHookRunState(run storage.HookRun) string {
+	switch run.State {
+	case storage.HookRunCompleted:
+		return color.GreenString(run.State)
+	case storage.HookRunFailed:
+		return color.RedString(run.State)
+	}
+	return run.State
+}
+
+func formatHookRunExitCode(run storage.HookRun) string {
+	if !run.IsFinished() {
+		return "-"
+	}
+	return fmt.Sprint(run.ExitCode)
+}
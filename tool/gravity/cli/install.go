@@ -576,7 +576,7 @@ func executePhaseFromService(
 		InterruptHandler: interrupt,
 		Printer:          env,
 	}
-	if params.isResume() {
+	if params.isResume() && !params.DryRun {
 		config.Lifecycle = &installerclient.AutomaticLifecycle{
 			Aborter:            installerAbortOperation(env),
 			Completer:          InstallerCompleteOperation(env),
@@ -592,9 +592,10 @@ func executePhaseFromService(
 	phaseCtx, phaseCancel := context.WithTimeout(context.Background(), params.Timeout)
 	defer phaseCancel()
 	return trace.Wrap(client.ExecutePhase(phaseCtx, installerclient.Phase{
-		ID:    params.PhaseID,
-		Force: params.Force,
-		Key:   operation.Key(),
+		ID:     params.PhaseID,
+		Force:  params.Force,
+		Key:    operation.Key(),
+		DryRun: params.DryRun,
 	}))
 }
 
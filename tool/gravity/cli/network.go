@@ -0,0 +1,233 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/checks"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/system/signals"
+	"github.com/gravitational/gravity/tool/common"
+
+	"github.com/buger/goterm"
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/gravitational/trace"
+)
+
+type checkNetworkConfig struct {
+	testBandwidth bool
+	pingCount     int
+	interval      time.Duration
+	history       bool
+	format        constants.Format
+}
+
+// checkNetwork measures network performance between every pair of cluster
+// nodes, saves and displays the results.
+// The test runs in the foreground, scheduling it is left to the operator
+func checkNetwork(env *localenv.LocalEnvironment, config checkNetworkConfig) error {
+	switch config.format {
+	case constants.EncodingText, constants.EncodingJSON:
+	default:
+		return trace.BadParameter("unsupported output format %q, supported are %v",
+			config.format, []constants.Format{constants.EncodingText, constants.EncodingJSON})
+	}
+	dir, err := networkTestDir()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if config.history {
+		matrices, err := checks.GetNetworkMatrices(dir)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		return trace.Wrap(printNetworkHistory(os.Stdout, matrices, config.format))
+	}
+	if config.format != constants.EncodingText {
+		// only the results are written to stdout
+		env.Silent = localenv.Silent(true)
+	}
+
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := signals.WatchTerminationSignals(ctx, cancel, env)
+	defer interrupt.Close()
+
+	credentials, err := rpcAgentDeployHelper(ctx, env, "", "")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer func() {
+		if err := rpcAgentShutdown(env); err != nil {
+			log.WithError(err).Error("Failed to shutdown agents.")
+		}
+	}()
+
+	for {
+		env.PrintStep("Testing network between %v nodes", len(cluster.ClusterState.Servers))
+		matrix, err := checks.TestNetwork(ctx, checks.NetworkTestConfig{
+			Remote:        checks.NewRemote(fsm.NewAgentRunner(credentials)),
+			Servers:       cluster.ClusterState.Servers,
+			PingCount:     config.pingCount,
+			TestBandwidth: config.testBandwidth,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if err := checks.SaveNetworkMatrix(dir, *matrix, defaults.NetworkTestRetain); err != nil {
+			return trace.Wrap(err)
+		}
+		if err := printNetworkMatrix(os.Stdout, *matrix, config.format); err != nil {
+			return trace.Wrap(err)
+		}
+		if config.interval == 0 {
+			return nil
+		}
+		select {
+		case <-time.After(config.interval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// printNetworkMatrix outputs the network test results as a matrix
+// for each network with the tested nodes as rows and their peers as columns
+func printNetworkMatrix(w io.Writer, matrix checks.NetworkMatrix, format constants.Format) error {
+	if format == constants.EncodingJSON {
+		return trace.Wrap(json.NewEncoder(w).Encode(matrix))
+	}
+	fmt.Fprintf(w, "Network test at %v (latency, packet loss, MTU, bandwidth):\n",
+		matrix.Created.Format(constants.HumanDateFormatSeconds))
+	for _, network := range []string{checks.NetworkHost, checks.NetworkOverlay} {
+		t := goterm.NewTable(0, 10, 5, ' ', 0)
+		header := []string{fmt.Sprintf("%v network", network)}
+		for _, node := range matrix.Nodes {
+			header = append(header, node.Hostname)
+		}
+		common.PrintTableHeader(t, header)
+		for _, from := range matrix.Nodes {
+			fmt.Fprint(t, from.Hostname)
+			for _, to := range matrix.Nodes {
+				fmt.Fprintf(t, "\t%v", formatNetworkResult(matrix, from, to, network))
+			}
+			fmt.Fprintln(t)
+		}
+		fmt.Fprintln(w, t.String())
+	}
+	failed := matrix.Failed()
+	if len(failed) == 0 {
+		fmt.Fprintln(w, color.GreenString("All nodes can reach each other."))
+		return nil
+	}
+	fmt.Fprintln(w, color.RedString("The following tests failed:"))
+	for _, result := range failed {
+		message := result.Error
+		if message == "" {
+			message = fmt.Sprintf("%v%% packet loss", result.PacketLoss)
+		}
+		fmt.Fprintf(w, "\t[%v] %v -> %v over %v network: %v\n", constants.FailureMark,
+			result.From, result.To, result.Network, message)
+	}
+	return nil
+}
+
+func formatNetworkResult(matrix checks.NetworkMatrix, from, to checks.NetworkNode, network string) string {
+	if from.AdvertiseIP == to.AdvertiseIP {
+		return "-"
+	}
+	result, ok := matrix.Get(from.AdvertiseIP, to.AdvertiseIP, network)
+	if !ok {
+		return "n/a"
+	}
+	if result.Error != "" && result.PacketLoss == 0 && result.Latency == 0 {
+		return color.RedString("error")
+	}
+	text := fmt.Sprintf("%v %v%% %v", result.Latency.Round(time.Microsecond), result.PacketLoss, result.MTU)
+	if result.Bandwidth != 0 {
+		text = fmt.Sprintf("%v %v/s", text, humanize.Bytes(result.Bandwidth))
+	}
+	if result.IsFailed() {
+		return color.RedString(text)
+	}
+	return text
+}
+
+// printNetworkHistory outputs the summary of the saved network test results
+func printNetworkHistory(w io.Writer, matrices []checks.NetworkMatrix, format constants.Format) error {
+	if format == constants.EncodingJSON {
+		if matrices == nil {
+			matrices = []checks.NetworkMatrix{}
+		}
+		return trace.Wrap(json.NewEncoder(w).Encode(matrices))
+	}
+	if len(matrices) == 0 {
+		fmt.Fprintln(w, "No network test results found.")
+		return nil
+	}
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Created", "Nodes", "Failed", "Max Latency", "Max Packet Loss"})
+	for _, matrix := range matrices {
+		var maxLatency time.Duration
+		var maxLoss float64
+		for _, result := range matrix.Results {
+			if result.Latency > maxLatency {
+				maxLatency = result.Latency
+			}
+			if result.PacketLoss > maxLoss {
+				maxLoss = result.PacketLoss
+			}
+		}
+		fmt.Fprintf(t, "%v\t%v\t%v\t%v\t%v%%\n",
+			matrix.Created.Format(constants.HumanDateFormatSeconds),
+			len(matrix.Nodes),
+			len(matrix.Failed()),
+			maxLatency.Round(time.Microsecond),
+			maxLoss)
+	}
+	fmt.Fprintln(w, t.String())
+	return nil
+}
+
+func networkTestDir() (string, error) {
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return filepath.Join(stateDir, defaults.LocalDir, defaults.NetworkTestDir), nil
+}
@@ -18,6 +18,7 @@ package cli
 
 import (
 	"context"
+	"os"
 	"sort"
 	"strings"
 	"time"
@@ -48,6 +49,9 @@ type PhaseParams struct {
 	Timeout time.Duration
 	// SkipVersionCheck overrides the verification of binary version compatibility
 	SkipVersionCheck bool
+	// DryRun specifies whether to only display the actions the phase
+	// would perform without executing it
+	DryRun bool
 }
 
 func (r PhaseParams) isResume() bool {
@@ -72,10 +76,14 @@ func resumeOperation(localEnv *localenv.LocalEnvironment, environ LocalEnvironme
 		Timeout:          params.Timeout,
 		SkipVersionCheck: params.SkipVersionCheck,
 		OperationID:      params.OperationID,
+		DryRun:           params.DryRun,
 	})
 	if err == nil {
 		return nil
 	}
+	if params.DryRun {
+		return trace.Wrap(err)
+	}
 	if !trace.IsNotFound(err) {
 		return trace.Wrap(err)
 	}
@@ -108,6 +116,11 @@ func executePhase(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentF
 	}
 }
 
+// printDryRun outputs the actions recorded during a plan dry-run
+func printDryRun(actions []fsm.Action) {
+	fsm.FormatDryRunText(os.Stdout, actions)
+}
+
 // setPhase sets the specified phase state without executing it.
 func setPhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params SetPhaseParams) error {
 	op, err := getActiveOperation(env, environ, params.OperationID)
@@ -26,6 +26,8 @@ import (
 	"time"
 
 	"github.com/gravitational/gravity/lib/app/service"
+	"github.com/gravitational/gravity/lib/blob"
+	"github.com/gravitational/gravity/lib/blob/dedup"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/httplib"
 	"github.com/gravitational/gravity/lib/loc"
@@ -35,6 +37,7 @@ import (
 	"github.com/gravitational/gravity/tool/common"
 
 	"github.com/docker/docker/pkg/archive"
+	"github.com/dustin/go-humanize"
 	"github.com/gravitational/configure"
 	"github.com/gravitational/trace"
 )
@@ -148,7 +151,7 @@ func exportPackage(env *localenv.LocalEnvironment, loc loc.Locator, opsCenterURL
 
 func listPackages(app *localenv.LocalEnvironment, repositoryFilter string, opsCenterURL string) error {
 	var repository string
-	return foreachPackage(app, repositoryFilter, opsCenterURL, func(env pack.PackageEnvelope) error {
+	err := foreachPackage(app, repositoryFilter, opsCenterURL, func(env pack.PackageEnvelope) error {
 		if repository != env.Locator.Repository {
 			repository = env.Locator.Repository
 			common.PrintHeader(repository)
@@ -162,6 +165,44 @@ func listPackages(app *localenv.LocalEnvironment, repositoryFilter string, opsCe
 		}
 		return nil
 	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// storage statistics are only available for the local package service
+	if opsCenterURL != "" {
+		return nil
+	}
+	deduplicator, ok := app.Objects.(blob.Deduplicator)
+	if !ok {
+		return nil
+	}
+	stats, err := deduplicator.GetDedupStats()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	app.Printf("\nStorage: %v in %v BLOBs, %v on disk in %v chunks, deduplication ratio %.2f\n",
+		humanize.Bytes(uint64(stats.LogicalBytes)), stats.BLOBs,
+		humanize.Bytes(uint64(stats.PhysicalBytes)), stats.Chunks, stats.Ratio())
+	return nil
+}
+
+func migratePackages(env *localenv.LocalEnvironment) error {
+	objects, ok := env.Objects.(*dedup.Objects)
+	if !ok {
+		return trace.BadParameter("local package storage does not support deduplication")
+	}
+	result, err := objects.Migrate()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	stats, err := objects.GetDedupStats()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.Printf("Migrated %v BLOBs (%v), storage now takes %v, deduplication ratio %.2f\n",
+		len(result.Migrated), humanize.Bytes(uint64(result.BytesBefore)),
+		humanize.Bytes(uint64(stats.PhysicalBytes)), stats.Ratio())
+	return nil
 }
 
 func foreachPackage(app *localenv.LocalEnvironment, repositoryFilter string, opsCenterURL string, fn func(env pack.PackageEnvelope) error) error {
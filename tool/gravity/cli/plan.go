@@ -64,7 +64,17 @@ func initUpdateOperationPlan(localEnv, updateEnv *localenv.LocalEnvironment) err
 	return trace.Wrap(err)
 }
 
-func displayOperationPlan(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentFactory, operationID string, format constants.Format) error {
+// displayPlanOptions specifies the operation plan output options
+type displayPlanOptions struct {
+	// format is the plan output format
+	format constants.Format
+	// phaseID optionally limits the output to the specified phase
+	phaseID string
+	// timing specifies whether to output the phase execution timing summary
+	timing bool
+}
+
+func displayOperationPlan(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentFactory, operationID string, options displayPlanOptions) error {
 	op, err := getLastOperation(localEnv, environ, operationID)
 	if err != nil {
 		if trace.IsNotFound(err) {
@@ -77,32 +87,96 @@ To restart the installation, use 'gravity resume' after fixing the issues.
 		return trace.Wrap(err)
 	}
 	if op.IsCompleted() {
-		return displayClusterOperationPlan(localEnv, op.Key(), format)
+		return displayClusterOperationPlan(localEnv, op.Key(), options)
 	}
 	switch op.Type {
 	case ops.OperationInstall:
-		err = displayInstallOperationPlan(op.Key(), format)
+		err = displayInstallOperationPlan(op.Key(), options)
 	case ops.OperationExpand:
-		err = displayExpandOperationPlan(environ, op.Key(), format)
+		err = displayExpandOperationPlan(environ, op.Key(), options)
 	case ops.OperationUpdate:
-		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), format)
+		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), options)
 	case ops.OperationUpdateRuntimeEnviron:
-		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), format)
+		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), options)
 	case ops.OperationUpdateConfig:
-		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), format)
+		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), options)
 	case ops.OperationGarbageCollect:
-		err = displayClusterOperationPlan(localEnv, op.Key(), format)
+		err = displayClusterOperationPlan(localEnv, op.Key(), options)
 	default:
 		return trace.BadParameter("unknown operation type %q", op.Type)
 	}
 	if err != nil && trace.IsNotFound(err) {
 		// Fallback to cluster plan
-		return displayClusterOperationPlan(localEnv, op.Key(), format)
+		return displayClusterOperationPlan(localEnv, op.Key(), options)
 	}
 	return trace.Wrap(err)
 }
 
-func displayClusterOperationPlan(env *localenv.LocalEnvironment, opKey ops.SiteOperationKey, format constants.Format) error {
+// displayPhaseLog outputs the log captured during execution of the specified
+// phase (or all phases if unspecified) of the operation.
+// The log is stored on the node that executed the phase: update operations
+// synchronize it with the cluster
+func displayPhaseLog(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentFactory, operationID, phaseID string) error {
+	op, err := getLastOperation(localEnv, environ, operationID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var log storage.PhaseLog
+	switch {
+	case op.IsCompleted():
+		log, err = getClusterPhaseLog(localEnv, op.Key())
+	case op.Type == ops.OperationInstall:
+		log, err = localEnv.Backend.GetOperationPhaseLog(op.SiteDomain, op.ID)
+	case op.Type == ops.OperationExpand:
+		log, err = getExpandPhaseLog(environ, op.Key())
+	case op.Type == ops.OperationUpdate,
+		op.Type == ops.OperationUpdateRuntimeEnviron,
+		op.Type == ops.OperationUpdateConfig:
+		log, err = getUpdatePhaseLog(localEnv, environ, op.Key())
+	default:
+		log, err = getClusterPhaseLog(localEnv, op.Key())
+	}
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	fsm.FormatPhaseLogText(os.Stdout, log.ForPhase(phaseID))
+	return nil
+}
+
+func getClusterPhaseLog(env *localenv.LocalEnvironment, opKey ops.SiteOperationKey) (storage.PhaseLog, error) {
+	clusterEnv, err := env.NewClusterEnvironment()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return clusterEnv.Backend.GetOperationPhaseLog(opKey.SiteDomain, opKey.OperationID)
+}
+
+func getExpandPhaseLog(environ LocalEnvironmentFactory, opKey ops.SiteOperationKey) (storage.PhaseLog, error) {
+	joinEnv, err := environ.NewJoinEnv()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer joinEnv.Close()
+	return joinEnv.Backend.GetOperationPhaseLog(opKey.SiteDomain, opKey.OperationID)
+}
+
+func getUpdatePhaseLog(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentFactory, opKey ops.SiteOperationKey) (storage.PhaseLog, error) {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	clusterEnv, err := localEnv.NewClusterEnvironment(localenv.WithEtcdTimeout(1 * time.Second))
+	if err == nil {
+		err = update.SyncPhaseLog(clusterEnv.Backend, updateEnv.Backend, opKey.SiteDomain, opKey.OperationID)
+	}
+	if err != nil {
+		log.WithError(err).Warn("Failed to sync phase log with the cluster.")
+	}
+	return updateEnv.Backend.GetOperationPhaseLog(opKey.SiteDomain, opKey.OperationID)
+}
+
+func displayClusterOperationPlan(env *localenv.LocalEnvironment, opKey ops.SiteOperationKey, options displayPlanOptions) error {
 	clusterEnv, err := env.NewClusterEnvironment()
 	if err != nil {
 		return trace.Wrap(err)
@@ -111,11 +185,11 @@ func displayClusterOperationPlan(env *localenv.LocalEnvironment, opKey ops.SiteO
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	err = outputPlan(*plan, format)
+	err = outputPlan(*plan, options)
 	return trace.Wrap(err)
 }
 
-func displayUpdateOperationPlan(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentFactory, opKey ops.SiteOperationKey, format constants.Format) error {
+func displayUpdateOperationPlan(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentFactory, opKey ops.SiteOperationKey, options displayPlanOptions) error {
 	updateEnv, err := environ.NewUpdateEnv()
 	if err != nil {
 		return trace.Wrap(err)
@@ -131,29 +205,29 @@ func displayUpdateOperationPlan(localEnv *localenv.LocalEnvironment, environ Loc
 	} else {
 		plan = reconciledPlan
 	}
-	err = outputPlan(*plan, format)
+	err = outputPlan(*plan, options)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 	return nil
 }
 
-func displayInstallOperationPlan(opKey ops.SiteOperationKey, format constants.Format) error {
+func displayInstallOperationPlan(opKey ops.SiteOperationKey, options displayPlanOptions) error {
 	plan, err := getPlanFromWizard(opKey)
 	if err == nil {
 		log.Debug("Showing install operation plan retrieved from wizard process.")
-		return trace.Wrap(outputPlan(*plan, format))
+		return trace.Wrap(outputPlan(*plan, options))
 	}
 	plan, err = getPlanFromWizardBackend(opKey)
 	if err != nil {
 		return trace.Wrap(err, "failed to get plan for the install operation.\n"+
 			"Make suer you are running 'gravity plan' from the installer node.")
 	}
-	return trace.Wrap(outputPlan(*plan, format))
+	return trace.Wrap(outputPlan(*plan, options))
 }
 
 // displayExpandOperationPlan shows plan of the join operation from the local join backend
-func displayExpandOperationPlan(environ LocalEnvironmentFactory, opKey ops.SiteOperationKey, format constants.Format) error {
+func displayExpandOperationPlan(environ LocalEnvironmentFactory, opKey ops.SiteOperationKey, options displayPlanOptions) error {
 	joinEnv, err := environ.NewJoinEnv()
 	if err != nil {
 		return trace.Wrap(err)
@@ -164,11 +238,22 @@ func displayExpandOperationPlan(environ LocalEnvironmentFactory, opKey ops.SiteO
 		return trace.Wrap(err)
 	}
 	log.Debug("Showing join operation plan retrieved from local join backend.")
-	return outputPlan(*plan, format)
+	return outputPlan(*plan, options)
 }
 
-func outputPlan(plan storage.OperationPlan, format constants.Format) (err error) {
-	switch format {
+func outputPlan(plan storage.OperationPlan, options displayPlanOptions) (err error) {
+	if options.phaseID != "" {
+		phase, err := fsm.FindPhase(&plan, options.phaseID)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		plan.Phases = []storage.OperationPhase{*phase}
+	}
+	if options.timing {
+		fsm.FormatOperationPlanTiming(os.Stdout, plan)
+		return nil
+	}
+	switch options.format {
 	case constants.EncodingYAML:
 		err = fsm.FormatOperationPlanYAML(os.Stdout, plan)
 	case constants.EncodingJSON:
@@ -180,7 +265,7 @@ func outputPlan(plan storage.OperationPlan, format constants.Format) (err error)
 		fsm.FormatOperationPlanShort(os.Stdout, plan)
 		err = explainPlan(plan.Phases)
 	default:
-		return trace.BadParameter("unknown output format %q", format)
+		return trace.BadParameter("unknown output format %q", options.format)
 	}
 	if err != nil {
 		return trace.Wrap(err)
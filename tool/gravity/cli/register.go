@@ -26,6 +26,7 @@ import (
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/modules"
+	"github.com/gravitational/gravity/lib/report"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
@@ -98,6 +99,7 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.InstallCmd.FromService = g.InstallCmd.Flag("from-service", "Run in service mode.").Hidden().Bool()
 	g.InstallCmd.Set = g.InstallCmd.Flag("set", "Set Helm chart values on the command line. Can be specified multiple times and/or as comma-separated values: key1=val1,key2=val2.").Strings()
 	g.InstallCmd.Values = g.InstallCmd.Flag("values", "Set Helm chart values from the provided YAML file. Can be specified multiple times.").Strings()
+	g.InstallCmd.ParallelPhases = g.InstallCmd.Flag("parallel-phases", "Maximum number of independent operation plan phases to execute concurrently. Phases are executed one at a time in plan order if unset.").Int()
 
 	g.JoinCmd.CmdClause = g.Command("join", "Join the existing cluster or an on-going install operation.")
 	g.JoinCmd.PeerAddr = g.JoinCmd.Arg("peer-addrs", "One or several IP addresses of cluster nodes to join, as comma-separated values.").String()
@@ -111,6 +113,7 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.JoinCmd.CloudProvider = g.JoinCmd.Flag("cloud-provider", "[DEPRECATED] This flag has no effect and will be removed in a future version.").String()
 	g.JoinCmd.OperationID = g.JoinCmd.Flag("operation-id", "ID of the operation that was created via UI.").Hidden().String()
 	g.JoinCmd.FromService = g.JoinCmd.Flag("from-service", "Run in service mode.").Hidden().Bool()
+	g.JoinCmd.ParallelPhases = g.JoinCmd.Flag("parallel-phases", "Maximum number of independent operation plan phases to execute concurrently. Phases are executed one at a time in plan order if unset.").Int()
 
 	g.AutoJoinCmd.CmdClause = g.Command("autojoin", "Use cloud provider data to join a node to existing cluster.")
 	g.AutoJoinCmd.ClusterName = g.AutoJoinCmd.Arg("cluster-name", "Cluster name used for discovery.").Required().String()
@@ -142,10 +145,14 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.PlanCmd.CmdClause = g.Command("plan", "Manage operation plan.")
 	g.PlanCmd.OperationID = g.PlanCmd.Flag("operation-id", "ID of the active operation. It not specified, the last operation will be used.").Hidden().String()
 	g.PlanCmd.SkipVersionCheck = g.PlanCmd.Flag("skip-version-check", "Bypass version compatibility check.").Hidden().Bool()
+	g.PlanCmd.DryRun = g.PlanCmd.Flag("dry-run", "Display the actions the operation phases would perform without executing them.").Bool()
 
 	g.PlanDisplayCmd.CmdClause = g.PlanCmd.Command("display", "Display a plan for an ongoing operation.").Default()
 	g.PlanDisplayCmd.Output = common.Format(g.PlanDisplayCmd.Flag("output", fmt.Sprintf("Output format: %v.", constants.OutputFormats)).Short('o').Default(string(constants.EncodingText)))
 	g.PlanDisplayCmd.Short = g.PlanDisplayCmd.Flag("short", "Short output format.").Bool()
+	g.PlanDisplayCmd.Phase = g.PlanDisplayCmd.Flag("phase", "Limit the output to the specified phase and its subphases.").String()
+	g.PlanDisplayCmd.Logs = g.PlanDisplayCmd.Flag("logs", "Display the log captured during phase execution.").Bool()
+	g.PlanDisplayCmd.Timing = g.PlanDisplayCmd.Flag("timing", "Display the phase execution timing summary.").Bool()
 
 	g.PlanExecuteCmd.CmdClause = g.PlanCmd.Command("execute", "Execute the specified operation phase.")
 	g.PlanExecuteCmd.Phase = g.PlanExecuteCmd.Flag("phase", "Phase ID to execute.").String()
@@ -190,6 +197,7 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.UpgradeCmd.SkipVersionCheck = g.UpgradeCmd.Flag("skip-version-check", "Bypass version compatibility check.").Hidden().Bool()
 	g.UpgradeCmd.Set = g.UpgradeCmd.Flag("set", "Set Helm chart values on the command line. Can be specified multiple times and/or as comma-separated values: key1=val1,key2=val2.").Strings()
 	g.UpgradeCmd.Values = g.UpgradeCmd.Flag("values", "Set Helm chart values from the provided YAML file. Can be specified multiple times.").Strings()
+	g.UpgradeCmd.ParallelPhases = g.UpgradeCmd.Flag("parallel-phases", "Maximum number of independent operation plan phases to execute concurrently. Phases are executed one at a time in plan order if unset.").Int()
 
 	g.UpdateUploadCmd.CmdClause = g.UpdateCmd.Command("upload", "Upload update package to locally running site").Hidden()
 	g.UpdateUploadCmd.OpsCenterURL = g.UpdateUploadCmd.Flag("ops-url", "Optional Gravity Hub URL to upload new packages to (defaults to local gravity site)").Default(defaults.GravityServiceURL).String()
@@ -225,23 +233,50 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.RegistryListCmd.Format = common.Format(g.RegistryListCmd.Flag("format", fmt.Sprintf("Output format: %v.", constants.OutputFormats)).Default(string(constants.EncodingText)))
 
 	// backup
-	g.BackupCmd.CmdClause = g.Command("backup", "Launch the cluster's backup hook.")
-	g.BackupCmd.Tarball = g.BackupCmd.Arg("to", "Tarball to create with results of the backup hook.").Required().String()
-	g.BackupCmd.Timeout = g.BackupCmd.Flag("timeout", "Active deadline for the backup job, in Go duration format (e.g. 30s, 5m, etc.). If not specified, the value from manifest is used. If that is not specified as well, the default value of 20 minutes is used.").Duration()
-	g.BackupCmd.Follow = g.BackupCmd.Flag("follow", "Output backup job logs to the stdout.").Bool()
+	g.BackupCmd.CmdClause = g.Command("backup", "Back up the cluster and manage backups taken by backup policies.")
+
+	g.BackupCreateCmd.CmdClause = g.BackupCmd.Command("create", "Launch the cluster's backup hook.").Default()
+	g.BackupCreateCmd.Tarball = g.BackupCreateCmd.Arg("to", "Tarball to create with results of the backup hook.").Required().String()
+	g.BackupCreateCmd.Timeout = g.BackupCreateCmd.Flag("timeout", "Active deadline for the backup job, in Go duration format (e.g. 30s, 5m, etc.). If not specified, the value from manifest is used. If that is not specified as well, the default value of 20 minutes is used.").Duration()
+	g.BackupCreateCmd.Follow = g.BackupCreateCmd.Flag("follow", "Output backup job logs to the stdout.").Bool()
+
+	g.BackupListCmd.CmdClause = g.BackupCmd.Command("ls", "List backups taken by backup policies.")
+	g.BackupListCmd.Format = common.Format(g.BackupListCmd.Flag("format", fmt.Sprintf("Output format: %v.", constants.OutputFormats)).Default(string(constants.EncodingText)))
+
+	g.BackupGetCmd.CmdClause = g.BackupCmd.Command("get", "Download a backup taken by a backup policy.")
+	g.BackupGetCmd.Name = g.BackupGetCmd.Arg("name", "Name of the backup to download.").Required().String()
+	g.BackupGetCmd.Output = g.BackupGetCmd.Arg("output", "Path to write the backup archive to. Defaults to <name>.tar.gz in the current directory.").String()
+
+	g.BackupDeleteCmd.CmdClause = g.BackupCmd.Command("delete", "Delete a backup taken by a backup policy.")
+	g.BackupDeleteCmd.Name = g.BackupDeleteCmd.Arg("name", "Name of the backup to delete.").Required().String()
 
 	g.CheckCmd.CmdClause = g.Command("check", "Execute preflight checks")
-	g.CheckCmd.ManifestFile = g.CheckCmd.Arg("manifest", "Cluster image manifest file").Default(defaults.ManifestFileName).String()
-	g.CheckCmd.Profile = g.CheckCmd.Flag("profile", "Name of the node profile to check against").Short('p').String()
-	g.CheckCmd.AutoFix = g.CheckCmd.Flag("autofix", "Attempt to fix discovered problems on a best-effort basis").Bool()
-	g.CheckCmd.ImagePath = g.CheckCmd.Flag("image-path", "Path to unpacked cluster image").String()
-	g.CheckCmd.Timeout = g.CheckCmd.Flag("timeout", "Checks execution timeout").Default(defaults.PreflightChecksTimeout.String()).Duration()
+	g.CheckPreflightCmd.CmdClause = g.CheckCmd.Command("preflight", "Execute preflight checks").Default()
+	g.CheckPreflightCmd.ManifestFile = g.CheckPreflightCmd.Arg("manifest", "Cluster image manifest file").Default(defaults.ManifestFileName).String()
+	g.CheckPreflightCmd.Profile = g.CheckPreflightCmd.Flag("profile", "Name of the node profile to check against").Short('p').String()
+	g.CheckPreflightCmd.AutoFix = g.CheckPreflightCmd.Flag("fix", "Attempt to fix discovered problems on a best-effort basis").Bool()
+	g.CheckPreflightCmd.LegacyAutoFix = g.CheckPreflightCmd.Flag("autofix", "Load missing kernel modules and set kernel parameters without confirmation").Hidden().Bool()
+	g.CheckPreflightCmd.StopServices = g.CheckPreflightCmd.Flag("stop-services", "Allow --fix to stop and disable conflicting services and services occupying required ports").Bool()
+	g.CheckPreflightCmd.Confirmed = g.CheckPreflightCmd.Flag("confirm", "Do not ask for confirmation before fixing problems.").Bool()
+	g.CheckPreflightCmd.Revert = g.CheckPreflightCmd.Flag("revert", "Revert the changes made by previous runs with --fix").Bool()
+	g.CheckPreflightCmd.Format = common.Format(g.CheckPreflightCmd.Flag("format", "Output format: text, json or junit.").Default(string(constants.EncodingText)))
+	g.CheckPreflightCmd.ImagePath = g.CheckPreflightCmd.Flag("image-path", "Path to unpacked cluster image").String()
+	g.CheckPreflightCmd.Timeout = g.CheckPreflightCmd.Flag("timeout", "Checks execution timeout").Default(defaults.PreflightChecksTimeout.String()).Duration()
+
+	g.CheckNetworkCmd.CmdClause = g.CheckCmd.Command("network", "Measure latency, packet loss, MTU and bandwidth between cluster nodes")
+	g.CheckNetworkCmd.Bandwidth = g.CheckNetworkCmd.Flag("bandwidth", "Measure bandwidth between every pair of nodes").Bool()
+	g.CheckNetworkCmd.PingCount = g.CheckNetworkCmd.Flag("count", "Number of packets sent to each node").Default(strconv.Itoa(defaults.NetworkTestPingCount)).Int()
+	g.CheckNetworkCmd.Interval = g.CheckNetworkCmd.Flag("interval", "Repeat the test with the specified interval until interrupted. Use cron or a systemd timer to run the test on a schedule").Duration()
+	g.CheckNetworkCmd.History = g.CheckNetworkCmd.Flag("history", "Display the results of the previous tests").Bool()
+	g.CheckNetworkCmd.Format = common.Format(g.CheckNetworkCmd.Flag("format", fmt.Sprintf("Output format: %v.", []constants.Format{constants.EncodingText, constants.EncodingJSON})).Default(string(constants.EncodingText)))
 
 	// restore
 	g.RestoreCmd.CmdClause = g.Command("restore", "Launch the cluster's restore hook.")
 	g.RestoreCmd.Tarball = g.RestoreCmd.Arg("from", "Tarball with backup data to restore from.").Required().String()
 	g.RestoreCmd.Follow = g.RestoreCmd.Flag("follow", "Output restore job logs to the stdout.").Bool()
 	g.RestoreCmd.Timeout = g.RestoreCmd.Flag("timeout", fmt.Sprintf("Maximum time a restore job is active. Defaults to the value from the manifest or %v if unspecified.", defaults.HookJobDeadline)).Duration()
+	g.RestoreCmd.Verify = g.RestoreCmd.Flag("verify", "Validate the backup checksums and run the application verifyRestore hook in a scratch namespace. Fails if the application has no verifyRestore hook.").Bool()
+	g.RestoreCmd.EncryptionKey = g.RestoreCmd.Flag("encryption-key", "Key to decrypt the backup with if it is encrypted.").String()
 
 	// operations on gravity applications
 	g.AppCmd.CmdClause = g.Command("app", "Operations with application images and releases.")
@@ -357,11 +392,20 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.AppPushCmd.Package = Locator(g.AppPushCmd.Arg("pkg", "application package").Required())
 	g.AppPushCmd.OpsCenterURL = g.AppPushCmd.Flag("ops-url", "remote Gravity Hub URL").Required().String()
 
+	g.AppHookCmd.CmdClause = g.AppCmd.Command("hook", "Run application hooks and display the history of hook runs.")
+
 	// run an application hook
-	g.AppHookCmd.CmdClause = g.AppCmd.Command("hook", "run the specified application hook").Hidden()
-	g.AppHookCmd.Package = Locator(g.AppHookCmd.Arg("pkg", "application package").Required())
-	g.AppHookCmd.HookName = g.AppHookCmd.Arg("hook-name", fmt.Sprintf("name of the hook (one of %v)", schema.AllHooks())).Required().String()
-	g.AppHookCmd.Env = g.AppHookCmd.Flag("env", "additional environment variables to provide to hook job as key=value pairs. Can be specified multiple times").StringMap()
+	g.AppHookRunCmd.CmdClause = g.AppHookCmd.Command("run", "run the specified application hook").Default().Hidden()
+	g.AppHookRunCmd.Package = Locator(g.AppHookRunCmd.Arg("pkg", "application package").Required())
+	g.AppHookRunCmd.HookName = g.AppHookRunCmd.Arg("hook-name", fmt.Sprintf("name of the hook (one of %v)", schema.AllHooks())).Required().String()
+	g.AppHookRunCmd.Env = g.AppHookRunCmd.Flag("env", "additional environment variables to provide to hook job as key=value pairs. Can be specified multiple times").StringMap()
+
+	g.AppHookHistoryCmd.CmdClause = g.AppHookCmd.Command("history", "Display the history of application hook runs.")
+	g.AppHookHistoryCmd.App = g.AppHookHistoryCmd.Arg("app", "Application to display the hook runs of, as name, repository/name or repository/name:version").String()
+	g.AppHookHistoryCmd.Hook = g.AppHookHistoryCmd.Flag("hook", fmt.Sprintf("Display only the runs of the specified hook (one of %v)", schema.AllHooks())).String()
+	g.AppHookHistoryCmd.Limit = g.AppHookHistoryCmd.Flag("limit", "Maximum number of hook runs to display").Default("20").Int()
+	g.AppHookHistoryCmd.ID = g.AppHookHistoryCmd.Flag("id", "Display the details and logs of the hook run with the specified ID").String()
+	g.AppHookHistoryCmd.Format = common.Format(g.AppHookHistoryCmd.Flag("format", fmt.Sprintf("Output format: %v.", []constants.Format{constants.EncodingText, constants.EncodingJSON})).Default(string(constants.EncodingText)))
 
 	// unpack application resources
 	g.AppUnpackCmd.CmdClause = g.AppCmd.Command("unpack", "unpack application resources").Hidden()
@@ -442,6 +486,9 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.PackDeleteCmd.Locator = Locator(g.PackDeleteCmd.Arg("pkg", "package name"))
 	g.PackDeleteCmd.OpsCenterURL = g.PackDeleteCmd.Flag("ops-url", "optional remote Gravity Hub URL").String()
 
+	// migrate packages to deduplicated storage
+	g.PackMigrateCmd.CmdClause = g.PackCmd.Command("migrate", "convert local packages to deduplicated storage").Hidden()
+
 	// configure package
 	g.PackConfigureCmd.CmdClause = g.PackCmd.Command("configure", "configure a package").Interspersed(false).Hidden()
 	g.PackConfigureCmd.Package = Locator(g.PackConfigureCmd.Arg("pkg", "package name to configure").Required())
@@ -532,6 +579,14 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	// get cluster diagnostics report
 	g.ReportCmd.CmdClause = g.Command("report", "Collect tarball with cluster's diagnostic information.")
 	g.ReportCmd.FilePath = g.ReportCmd.Flag("file", "File name with collected diagnostic information.").Default("report.tar.gz").String()
+	g.ReportCmd.Profile = g.ReportCmd.Flag("profile", fmt.Sprintf("Report profile, one of %v.", report.ProfileNames())).Default(report.ProfileFull).Enum(report.ProfileNames()...)
+	g.ReportCmd.Collectors = g.ReportCmd.Flag("collector", fmt.Sprintf("Collector to run instead of the ones from the profile, one of %v. Can be repeated.", report.AllFilters)).Enums(report.AllFilters...)
+	g.ReportCmd.Since = g.ReportCmd.Flag("since", "Only collect logs after the specified duration before now or timestamp, e.g. 24h or 2018-10-01T00:00:00Z.").String()
+	g.ReportCmd.Until = g.ReportCmd.Flag("until", "Only collect logs before the specified duration before now or timestamp, e.g. 1h or 2018-10-02T00:00:00Z.").String()
+	g.ReportCmd.MaxSize = g.ReportCmd.Flag("max-size", "Limit the total size of the collected diagnostics, e.g. 500MB. Collector output over the limit is truncated.").Bytes()
+	g.ReportCmd.Namespaces = g.ReportCmd.Flag("namespace", "Only collect Kubernetes diagnostics for the specified namespace. Can be repeated.").Strings()
+	g.ReportCmd.Nodes = g.ReportCmd.Flag("node", "Only collect diagnostics from the node with the specified hostname or address. Can be repeated.").Strings()
+	g.ReportCmd.RedactIPs = g.ReportCmd.Flag("redact-ips", "Scrub IP addresses from the report in addition to certificates, tokens and passwords.").Bool()
 
 	// operations on sites
 	g.SiteCmd.CmdClause = g.Command("site", "operations on gravity sites")
@@ -656,9 +711,29 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.SystemReportCmd.CmdClause = g.SystemCmd.Command("report", "collect system diagnostics and output as gzipped tarball to terminal").Hidden()
 	g.SystemReportCmd.Filter = g.SystemReportCmd.Flag("filter", "collect only specific diagnostics ('system', 'kubernetes'). Collect everything if unspecified").Strings()
 	g.SystemReportCmd.Compressed = g.SystemReportCmd.Flag("compressed", "whether to compress the tarball").Default("true").Bool()
+	g.SystemReportCmd.Since = g.SystemReportCmd.Flag("since", "only collect logs after the specified duration before now or timestamp").String()
+	g.SystemReportCmd.Until = g.SystemReportCmd.Flag("until", "only collect logs before the specified duration before now or timestamp").String()
+	g.SystemReportCmd.MaxSize = g.SystemReportCmd.Flag("max-size", "limit the total size of the collected diagnostics").Bytes()
+	g.SystemReportCmd.Namespaces = g.SystemReportCmd.Flag("namespace", "only collect kubernetes diagnostics for the specified namespace").Strings()
 
 	g.SystemStateDirCmd.CmdClause = g.SystemCmd.Command("state-dir", "show where all gravity data is stored on the node").Hidden()
 
+	g.SystemStateCmd.CmdClause = g.SystemCmd.Command("state", "export and import the cluster storage state")
+	g.SystemStateExportCmd.CmdClause = g.SystemStateCmd.Command("export", "export the storage backend state to an archive")
+	g.SystemStateExportCmd.Path = g.SystemStateExportCmd.Arg("path", "path to the archive, '-' for stdout").Required().String()
+	g.SystemStateExportCmd.Backend = g.SystemStateExportCmd.Flag("backend", "type of the backend to export the state from").
+		Default(constants.ETCDBackend).Enum(constants.BoltBackend, constants.ETCDBackend, constants.PostgresBackend)
+	g.SystemStateExportCmd.BoltPath = g.SystemStateExportCmd.Flag("bolt-path", "path to the bolt database").String()
+	g.SystemStateExportCmd.PostgresURI = g.SystemStateExportCmd.Flag("postgres-uri", "PostgreSQL connection URI").String()
+	g.SystemStateImportCmd.CmdClause = g.SystemStateCmd.Command("import", "import the storage backend state from an archive")
+	g.SystemStateImportCmd.Path = g.SystemStateImportCmd.Arg("path", "path to the archive, '-' for stdin").Required().String()
+	g.SystemStateImportCmd.Backend = g.SystemStateImportCmd.Flag("backend", "type of the backend to import the state into").
+		Default(constants.ETCDBackend).Enum(constants.BoltBackend, constants.ETCDBackend, constants.PostgresBackend)
+	g.SystemStateImportCmd.BoltPath = g.SystemStateImportCmd.Flag("bolt-path", "path to the bolt database").String()
+	g.SystemStateImportCmd.PostgresURI = g.SystemStateImportCmd.Flag("postgres-uri", "PostgreSQL connection URI").String()
+	g.SystemStateImportCmd.DryRun = g.SystemStateImportCmd.Flag("dry-run", "only report the changes without applying them").Bool()
+	g.SystemStateImportCmd.Force = g.SystemStateImportCmd.Flag("force", "overwrite the items that conflict with the existing state").Bool()
+
 	// manage docker devicemapper environment
 	g.SystemDevicemapperCmd.CmdClause = g.SystemCmd.Command("devicemapper", "manage docker devicemapper environment").Hidden()
 	g.SystemDevicemapperMountCmd.CmdClause = g.SystemDevicemapperCmd.Command("mount", "configure devicemapper environment").Hidden()
@@ -669,13 +744,18 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	// journal helpers
 	g.SystemExportRuntimeJournalCmd.CmdClause = g.SystemCmd.Command("export-runtime-journal", "Export runtime journal logs to a file").Hidden()
 	g.SystemExportRuntimeJournalCmd.OutputFile = g.SystemExportRuntimeJournalCmd.Flag("output", "Name of resulting tarball. Output to stdout if unspecified").String()
+	g.SystemExportRuntimeJournalCmd.Since = g.SystemExportRuntimeJournalCmd.Flag("since", "Only export entries after the specified duration before now or timestamp").String()
+	g.SystemExportRuntimeJournalCmd.Until = g.SystemExportRuntimeJournalCmd.Flag("until", "Only export entries before the specified duration before now or timestamp").String()
 
 	g.SystemStreamRuntimeJournalCmd.CmdClause = g.SystemCmd.Command("stream-runtime-journal", "Stream runtime journal to stdout").Hidden()
+	g.SystemStreamRuntimeJournalCmd.Since = g.SystemStreamRuntimeJournalCmd.Flag("since", "Only stream entries after the specified duration before now or timestamp").String()
+	g.SystemStreamRuntimeJournalCmd.Until = g.SystemStreamRuntimeJournalCmd.Flag("until", "Only stream entries before the specified duration before now or timestamp").String()
 
 	// pruning cluster resources
 	g.GarbageCollectCmd.CmdClause = g.Command("gc", "Prune cluster resources")
 	g.GarbageCollectCmd.Manual = g.GarbageCollectCmd.Flag("manual", "Do not start the operation automatically").Short('m').Bool()
 	g.GarbageCollectCmd.Confirmed = g.GarbageCollectCmd.Flag("confirm", "Confirm to remove unrelated docker images").Short('c').Bool()
+	g.GarbageCollectCmd.RegistryAllowlist = g.GarbageCollectCmd.Flag("registry-allow", "Docker image to retain in the cluster registry as repository[:tag], glob patterns are supported. Can be repeated").Strings()
 
 	// system clean up tasks
 	systemGCCmd := g.SystemCmd.Command("gc", "Run system clean up tasks")
@@ -696,7 +776,8 @@ func RegisterCommands(app *kingpin.Application) *Application {
 
 	g.SystemGCRegistryCmd.CmdClause = systemGCCmd.Command("registry", "Prune unused docker images on this node.")
 	g.SystemGCRegistryCmd.Confirm = g.SystemGCRegistryCmd.Flag("confirm", "Confirm to remove unrelated docker").Bool()
-	g.SystemGCRegistryCmd.DryRun = g.SystemGCRegistryCmd.Flag("dry-run", "Only list docker images to remove w/o removing them").Bool()
+	g.SystemGCRegistryCmd.DryRun = g.SystemGCRegistryCmd.Flag("dry-run", "Only list docker images to remove and the reclaimable space w/o removing them").Bool()
+	g.SystemGCRegistryCmd.Allowlist = g.SystemGCRegistryCmd.Flag("allow", "Docker image to retain in the registry as repository[:tag], glob patterns are supported. Can be repeated").Strings()
 
 	// operations on planet (planet plugin)
 	g.PlanetCmd.CmdClause = g.Command("planet", "operations with planet").Hidden()
@@ -747,6 +828,34 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.ResourceGetCmd.WithSecrets = g.ResourceGetCmd.Flag("with-secrets", "Include secret properties like private keys.").Default("false").Bool()
 	g.ResourceGetCmd.User = g.ResourceGetCmd.Flag("user", "User to display resources for. Defaults to the currently logged in user.").String()
 
+	g.ResourceDiffCmd.CmdClause = g.ResourceCmd.Command("diff", "Show the difference between resources declared in a file or directory and cluster resources, e.g. gravity resource diff -f resources/.")
+	g.ResourceDiffCmd.Path = g.ResourceDiffCmd.Flag("filename", "Resource definition file or directory with resource definition files.").Short('f').Required().String()
+	g.ResourceDiffCmd.Prune = g.ResourceDiffCmd.Flag("prune", "Include removal of resources that are not declared.").Bool()
+	g.ResourceDiffCmd.PruneKinds = g.ResourceDiffCmd.Flag("prune-kind", fmt.Sprintf("Resource kind to prune, can be repeated. Defaults to the kinds of declared resources. One of: %v.",
+		modules.GetResources().SupportedResourcesToRemove())).Strings()
+	g.ResourceDiffCmd.User = g.ResourceDiffCmd.Flag("user", "User to compare the resources for. Defaults to the currently logged in user.").String()
+
+	g.ResourceApplyCmd.CmdClause = g.ResourceCmd.Command("apply", "Create, update and optionally remove cluster resources to match resources declared in a file or directory, e.g. gravity resource apply -f resources/ --prune.")
+	g.ResourceApplyCmd.Path = g.ResourceApplyCmd.Flag("filename", "Resource definition file or directory with resource definition files.").Short('f').Required().String()
+	g.ResourceApplyCmd.Prune = g.ResourceApplyCmd.Flag("prune", "Remove resources that are not declared.").Bool()
+	g.ResourceApplyCmd.PruneKinds = g.ResourceApplyCmd.Flag("prune-kind", fmt.Sprintf("Resource kind to prune, can be repeated. Defaults to the kinds of declared resources. One of: %v.",
+		modules.GetResources().SupportedResourcesToRemove())).Strings()
+	g.ResourceApplyCmd.User = g.ResourceApplyCmd.Flag("user", "User to apply the resources for. Defaults to the currently logged in user.").String()
+	g.ResourceApplyCmd.Manual = g.ResourceApplyCmd.Flag("manual", "Manually execute operation phases for resources which trigger an operation.").Short('m').Bool()
+	g.ResourceApplyCmd.Confirmed = g.ResourceApplyCmd.Flag("confirm", "Do not ask for confirmation.").Bool()
+
+	g.ResourceHistoryCmd.CmdClause = g.ResourceCmd.Command("history", "Display the history of resource changes, e.g. gravity resource history logforwarder/forwarder1.")
+	g.ResourceHistoryCmd.Resource = g.ResourceHistoryCmd.Arg("resource", "Resource as kind or kind/name. Displays changes to all resources of the kind if name is omitted.").Required().String()
+	g.ResourceHistoryCmd.Revision = g.ResourceHistoryCmd.Flag("revision", "Display the resource as of the specified revision.").Int()
+	g.ResourceHistoryCmd.Limit = g.ResourceHistoryCmd.Flag("limit", "Maximum number of revisions to display.").Default("20").Int()
+	g.ResourceHistoryCmd.Format = common.Format(g.ResourceHistoryCmd.Flag("format", fmt.Sprintf("Output format: %v.", []constants.Format{constants.EncodingText, constants.EncodingJSON})).Default(string(constants.EncodingText)))
+
+	g.ResourceRollbackCmd.CmdClause = g.ResourceCmd.Command("rollback", "Re-apply a previous revision of a resource, e.g. gravity resource rollback clusterconfiguration --revision=2.")
+	g.ResourceRollbackCmd.Resource = g.ResourceRollbackCmd.Arg("resource", "Resource as kind/name, or kind for resources the cluster can only have one of.").Required().String()
+	g.ResourceRollbackCmd.Revision = g.ResourceRollbackCmd.Flag("revision", "Revision to roll back to. Defaults to the revision preceding the latest one.").Int()
+	g.ResourceRollbackCmd.Manual = g.ResourceRollbackCmd.Flag("manual", "Manually execute operation phases for resources which trigger an operation.").Short('m').Bool()
+	g.ResourceRollbackCmd.Confirmed = g.ResourceRollbackCmd.Flag("confirm", "Do not ask for confirmation.").Bool()
+
 	g.TopCmd.CmdClause = g.Command("top", "Display cluster monitoring information.")
 	g.TopCmd.Interval = g.TopCmd.Flag("interval", "Interval to display data for, in Go duration format.").Default(defaults.MetricsInterval.String()).Duration()
 	g.TopCmd.Step = g.TopCmd.Flag("step", "Max time b/w two datapoints, in Go duration format.").Default(defaults.MetricsStep.String()).Duration()
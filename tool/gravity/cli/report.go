@@ -19,6 +19,7 @@ package cli
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/gravitational/gravity/lib/localenv"
 	"github.com/gravitational/gravity/lib/report"
@@ -27,15 +28,31 @@ import (
 )
 
 // systemReport collects system diagnostics and outputs them as a (optionally compressed) tarball
-// to the stdout.
-// filters define the specific diagnostics to collect ('system', 'kubernetes'),
-// if empty all diagnostics are collected.
-func systemReport(env *localenv.LocalEnvironment, filters []string, compressed bool, w io.Writer) error {
-	config := report.Config{
-		Filters:    filters,
-		Compressed: compressed,
-		Packages:   env.Packages,
-	}
+// to the specified writer.
+// config.Filters define the specific diagnostics to collect ('system', 'kubernetes'),
+// if empty all diagnostics are collected
+func systemReport(env *localenv.LocalEnvironment, config report.Config, w io.Writer) error {
+	config.Packages = env.Packages
 	err := report.Collect(context.TODO(), config, w)
 	return trace.Wrap(err)
 }
+
+// parseTimeRange parses the log period from the command line values.
+// Each value is either a duration before now or a timestamp in RFC3339 format
+func parseTimeRange(since, until string) (*report.TimeRange, error) {
+	now := time.Now().UTC()
+	var timeRange report.TimeRange
+	var err error
+	timeRange.Since, err = report.ParseTime(since, now)
+	if err != nil {
+		return nil, trace.Wrap(err, "invalid --since value")
+	}
+	timeRange.Until, err = report.ParseTime(until, now)
+	if err != nil {
+		return nil, trace.Wrap(err, "invalid --until value")
+	}
+	if err := timeRange.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &timeRange, nil
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/modules"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/resources"
+	"github.com/gravitational/gravity/lib/ops/resources/gravity"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+	"github.com/gravitational/gravity/tool/common"
+
+	"github.com/buger/goterm"
+	"github.com/fatih/color"
+	"github.com/ghodss/yaml"
+	"github.com/gravitational/trace"
+)
+
+// resourceHistory displays the recorded revisions of the specified resource.
+// If revision is set, the resource as of this revision is displayed
+func resourceHistory(env *localenv.LocalEnvironment, resource string, revision, limit int, format constants.Format) error {
+	switch format {
+	case constants.EncodingText, constants.EncodingJSON:
+	default:
+		return trace.BadParameter("unsupported output format %q, supported are %v",
+			format, []constants.Format{constants.EncodingText, constants.EncodingJSON})
+	}
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := env.LocalCluster()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	filter, err := parseResourceRevisionFilter(resource, revision != 0)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if revision == 0 {
+		filter.Limit = limit
+	}
+	revisions, err := operator.GetResourceRevisions(context.TODO(), ops.GetResourceRevisionsRequest{
+		SiteKey:                cluster.Key(),
+		ResourceRevisionFilter: *filter,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if revision == 0 {
+		return trace.Wrap(printResourceRevisions(os.Stdout, revisions, format))
+	}
+	target, err := findResourceRevision(revisions, revision)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(printResourceRevision(os.Stdout, *target, format))
+}
+
+// rollbackResource re-applies the specified revision of the resource.
+// If the resource had been removed in the specified revision, it is removed.
+// Resources that are managed with the help of a cluster operation are
+// rolled back with a new operation
+func rollbackResource(env *localenv.LocalEnvironment, factory LocalEnvironmentFactory, resource string, revision int, manual, confirmed bool) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := env.LocalCluster()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	filter, err := parseResourceRevisionFilter(resource, true)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	revisions, err := operator.GetResourceRevisions(context.TODO(), ops.GetResourceRevisionsRequest{
+		SiteKey:                cluster.Key(),
+		ResourceRevisionFilter: *filter,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if revision == 0 {
+		if len(revisions) < 2 {
+			return trace.NotFound("%v has no previous revision to roll back to", resource)
+		}
+		revision = revisions[1].Revision
+	}
+	target, err := findResourceRevision(revisions, revision)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(revisions) != 0 && revisions[0].Revision == target.Revision {
+		return trace.BadParameter("revision %v is the latest revision of %v", revision, resource)
+	}
+	if !confirmed {
+		err := enforceConfirmation("Roll back %v to revision %v %v by %v on %v?", resource,
+			target.Revision, target.Action, target.Author,
+			target.Created.Format(constants.HumanDateFormatSeconds))
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	gravityResources, err := gravity.New(gravity.Config{
+		Operator:                operator,
+		CurrentUser:             env.CurrentUser(),
+		Silent:                  env.Silent,
+		ClusterOperationHandler: NewDefaultClusterOperationHandler(factory),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	control := resources.NewControl(gravityResources)
+	if target.Action == storage.ResourceDeleted {
+		err = control.Remove(context.TODO(), resources.RemoveRequest{
+			SiteKey:   cluster.Key(),
+			Kind:      target.Kind,
+			Name:      target.Name,
+			Force:     true,
+			Manual:    manual,
+			Confirmed: true,
+		})
+	} else {
+		err = control.Create(context.TODO(), bytes.NewReader(target.Resource), resources.CreateRequest{
+			SiteKey:   cluster.Key(),
+			Upsert:    true,
+			Manual:    manual,
+			Confirmed: true,
+		})
+	}
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.Printf("Rolled back %v to revision %v.\n", resource, target.Revision)
+	return nil
+}
+
+// parseResourceRevisionFilter parses the resource specified as kind or kind/name.
+// If requireName is set, the name is mandatory for all resources
+// but those the cluster can only have one of
+func parseResourceRevisionFilter(resource string, requireName bool) (*storage.ResourceRevisionFilter, error) {
+	parts := strings.SplitN(resource, "/", 2)
+	kind := modules.GetResources().CanonicalKind(parts[0])
+	supported := modules.GetResources().SupportedResources()
+	if !utils.StringInSlice(supported, kind) {
+		return nil, trace.BadParameter("unknown resource kind %q, supported are: %v",
+			parts[0], supported)
+	}
+	filter := storage.ResourceRevisionFilter{Kind: kind}
+	var name string
+	if len(parts) == 2 {
+		name = parts[1]
+	}
+	switch {
+	case resources.IsSingletonKind(kind):
+		filter.Name = new(string)
+	case name != "":
+		filter.Name = &name
+	case requireName:
+		return nil, trace.BadParameter("specify the resource as %v/<name>", kind)
+	}
+	return &filter, nil
+}
+
+func findResourceRevision(revisions []storage.ResourceRevision, revision int) (*storage.ResourceRevision, error) {
+	for _, item := range revisions {
+		if item.Revision == revision {
+			return &item, nil
+		}
+	}
+	return nil, trace.NotFound("revision %v not found", revision)
+}
+
+// printResourceRevisions outputs the list of resource revisions
+func printResourceRevisions(w io.Writer, revisions []storage.ResourceRevision, format constants.Format) error {
+	if format == constants.EncodingJSON {
+		if revisions == nil {
+			revisions = []storage.ResourceRevision{}
+		}
+		return trace.Wrap(json.NewEncoder(w).Encode(revisions))
+	}
+	if len(revisions) == 0 {
+		fmt.Fprintln(w, "No revisions found.")
+		return nil
+	}
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Revision", "Resource", "Action", "Author", "Time"})
+	for _, revision := range revisions {
+		fmt.Fprintf(t, "%v\t%v\t%v\t%v\t%v\n",
+			revision.Revision,
+			formatRevisionResource(revision),
+			formatRevisionAction(revision),
+			revision.Author,
+			revision.Created.Format(constants.HumanDateFormatSeconds))
+	}
+	fmt.Fprintln(w, t.String())
+	return nil
+}
+
+// printResourceRevision outputs the resource as of the specified revision
+func printResourceRevision(w io.Writer, revision storage.ResourceRevision, format constants.Format) error {
+	if format == constants.EncodingJSON {
+		return trace.Wrap(json.NewEncoder(w).Encode(revision))
+	}
+	fmt.Fprintf(w, "# Revision %v of %v, %v by %v on %v\n",
+		revision.Revision,
+		formatRevisionResource(revision),
+		revision.Action,
+		revision.Author,
+		revision.Created.Format(constants.HumanDateFormatSeconds))
+	if revision.Action == storage.ResourceDeleted {
+		fmt.Fprintln(w, "# The resource has been removed, its last value was:")
+	}
+	if len(revision.Resource) == 0 {
+		return nil
+	}
+	data, err := yaml.JSONToYAML(revision.Resource)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = w.Write(data)
+	return trace.ConvertSystemError(err)
+}
+
+func formatRevisionResource(revision storage.ResourceRevision) string {
+	if revision.Name == "" {
+		return revision.Kind
+	}
+	return fmt.Sprintf("%v/%v", revision.Kind, revision.Name)
+}
+
+func formatRevisionAction(revision storage.ResourceRevision) string {
+	switch revision.Action {
+	case storage.ResourceCreated:
+		return color.GreenString(revision.Action)
+	case storage.ResourceDeleted:
+		return color.RedString(revision.Action)
+	}
+	return revision.Action
+}
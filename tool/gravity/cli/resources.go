@@ -146,6 +146,97 @@ func getResources(env *localenv.LocalEnvironment, kind string, name string, with
 	return nil
 }
 
+// diffResources shows the changes required to bring cluster resources
+// in sync with the resources declared at the specified path
+func diffResources(env *localenv.LocalEnvironment, path string, prune bool, pruneKinds []string, user string) error {
+	control, req, err := newSyncRequest(env, nil, path, prune, pruneKinds, user)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	changes, err := control.Diff(*req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(changes.WriteText(os.Stdout))
+}
+
+// applyResources creates, updates and, if prune is set, removes cluster
+// resources to match the resources declared at the specified path.
+// Resources are applied in dependency order after the user has approved
+// the changes, unless confirmed is set
+func applyResources(
+	env *localenv.LocalEnvironment,
+	factory LocalEnvironmentFactory,
+	path string,
+	prune bool,
+	pruneKinds []string,
+	user string,
+	manual, confirmed bool,
+) error {
+	control, req, err := newSyncRequest(env, factory, path, prune, pruneKinds, user)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	changes, err := control.Diff(*req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := changes.WriteText(os.Stdout); err != nil {
+		return trace.Wrap(err)
+	}
+	if changes.IsEmpty() {
+		return nil
+	}
+	if !confirmed {
+		if err := enforceConfirmation("Apply %v change(s)?", len(changes)); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	req.Manual = manual
+	// changes have been approved as a whole
+	req.Confirmed = true
+	err = control.Apply(context.TODO(), changes, *req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.Printf("Applied %v change(s).\n", len(changes))
+	return nil
+}
+
+func newSyncRequest(env *localenv.LocalEnvironment, factory LocalEnvironmentFactory, path string, prune bool, pruneKinds []string, user string) (*resources.ResourceControl, *resources.SyncRequest, error) {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	cluster, err := env.LocalCluster()
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	config := gravity.Config{
+		Operator:    operator,
+		CurrentUser: env.CurrentUser(),
+		Silent:      env.Silent,
+	}
+	if factory != nil {
+		config.ClusterOperationHandler = NewDefaultClusterOperationHandler(factory)
+	}
+	gravityResources, err := gravity.New(config)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	declared, err := resources.ReadPath(path)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	return resources.NewControl(gravityResources), &resources.SyncRequest{
+		SiteKey:    cluster.Key(),
+		Resources:  declared,
+		Prune:      prune,
+		PruneKinds: pruneKinds,
+		Owner:      user,
+	}, nil
+}
+
 // NewDefaultClusterOperationHandler creates an instance of the default cluster operation
 // handler
 func NewDefaultClusterOperationHandler(factory LocalEnvironmentFactory) clusterOperationHandler {
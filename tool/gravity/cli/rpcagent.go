@@ -22,6 +22,7 @@ import (
 	"io"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -366,8 +367,19 @@ func rpcAgentShutdown(env *localenv.LocalEnvironment) error {
 	return trace.Wrap(err)
 }
 
+// executeAutomaticUpgrade executes the upgrade operation.
+// The optional argument specifies the maximum number of plan phases
+// to execute concurrently
 func executeAutomaticUpgrade(ctx context.Context, localEnv, upgradeEnv *localenv.LocalEnvironment, args []string) error {
-	return trace.Wrap(clusterupdate.AutomaticUpgrade(ctx, localEnv, upgradeEnv))
+	var parallelPhases int
+	if len(args) != 0 {
+		var err error
+		parallelPhases, err = strconv.Atoi(args[0])
+		if err != nil {
+			return trace.BadParameter("invalid number of parallel phases %q", args[0])
+		}
+	}
+	return trace.Wrap(clusterupdate.AutomaticUpgrade(ctx, localEnv, upgradeEnv, parallelPhases))
 }
 
 func executeSyncOperationPlan(ctx context.Context, localEnv, updateEnv *localenv.LocalEnvironment, args []string) error {
@@ -33,7 +33,9 @@ import (
 	"github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/httplib"
 	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/process"
+	"github.com/gravitational/gravity/lib/report"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/state"
 	"github.com/gravitational/gravity/lib/systemservice"
@@ -97,7 +99,9 @@ func InitAndCheck(g *Application, cmd string) error {
 		g.PlanCmd.FullCommand(),
 		g.PlanDisplayCmd.FullCommand(),
 		g.UpgradeCmd.FullCommand(),
-		g.ResourceCreateCmd.FullCommand():
+		g.ResourceCreateCmd.FullCommand(),
+		g.ResourceApplyCmd.FullCommand(),
+		g.ResourceRollbackCmd.FullCommand():
 		if *g.Debug {
 			teleutils.InitLogger(teleutils.LoggingForDaemon, level)
 		}
@@ -125,6 +129,8 @@ func InitAndCheck(g *Application, cmd string) error {
 		g.PlanRollbackCmd.FullCommand(),
 		g.ResourceCreateCmd.FullCommand(),
 		g.ResourceRemoveCmd.FullCommand(),
+		g.ResourceApplyCmd.FullCommand(),
+		g.ResourceRollbackCmd.FullCommand(),
 		g.OpsAgentCmd.FullCommand():
 		utils.InitLogging(*g.SystemLogFile)
 		// install and join command also duplicate their logs to the file in
@@ -186,12 +192,16 @@ func InitAndCheck(g *Application, cmd string) error {
 		g.RemoveCmd.FullCommand(),
 		g.SystemDevicemapperMountCmd.FullCommand(),
 		g.SystemDevicemapperUnmountCmd.FullCommand(),
-		g.BackupCmd.FullCommand(),
+		g.BackupCreateCmd.FullCommand(),
+		g.BackupListCmd.FullCommand(),
+		g.BackupGetCmd.FullCommand(),
+		g.BackupDeleteCmd.FullCommand(),
 		g.RestoreCmd.FullCommand(),
 		g.GarbageCollectCmd.FullCommand(),
 		g.SystemGCRegistryCmd.FullCommand(),
 		g.OpsAgentCmd.FullCommand(),
-		g.CheckCmd.FullCommand(),
+		g.CheckPreflightCmd.FullCommand(),
+		g.CheckNetworkCmd.FullCommand(),
 		g.ReportCmd.FullCommand():
 		if err := checkRunningAsRoot(); err != nil {
 			return trace.Wrap(err)
@@ -375,8 +385,12 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 				Timeout:          *g.PlanExecuteCmd.PhaseTimeout,
 				SkipVersionCheck: *g.PlanCmd.SkipVersionCheck,
 				OperationID:      *g.PlanCmd.OperationID,
+				DryRun:           *g.PlanCmd.DryRun,
 			})
 	case g.PlanSetCmd.FullCommand():
+		if *g.PlanCmd.DryRun {
+			return trace.BadParameter("--dry-run is not supported when setting phase state")
+		}
 		return setPhase(localEnv, g, SetPhaseParams{
 			OperationID: *g.PlanCmd.OperationID,
 			PhaseID:     *g.PlanSetCmd.Phase,
@@ -389,8 +403,12 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 				Timeout:          *g.PlanResumeCmd.PhaseTimeout,
 				SkipVersionCheck: *g.PlanCmd.SkipVersionCheck,
 				OperationID:      *g.PlanCmd.OperationID,
+				DryRun:           *g.PlanCmd.DryRun,
 			})
 	case g.PlanRollbackCmd.FullCommand():
+		if *g.PlanCmd.DryRun {
+			return trace.BadParameter("--dry-run is not supported for rollback")
+		}
 		return rollbackPhase(localEnv, g,
 			PhaseParams{
 				PhaseID:          *g.PlanRollbackCmd.Phase,
@@ -400,13 +418,34 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 				OperationID:      *g.PlanCmd.OperationID,
 			})
 	case g.PlanDisplayCmd.FullCommand():
+		if *g.PlanCmd.DryRun {
+			return executePhase(localEnv, g,
+				PhaseParams{
+					PhaseID:          fsm.RootPhase,
+					Timeout:          defaults.DryRunTimeout,
+					SkipVersionCheck: *g.PlanCmd.SkipVersionCheck,
+					OperationID:      *g.PlanCmd.OperationID,
+					DryRun:           true,
+				})
+		}
+		if *g.PlanDisplayCmd.Logs {
+			return displayPhaseLog(localEnv, g,
+				*g.PlanCmd.OperationID, *g.PlanDisplayCmd.Phase)
+		}
 		outputFormat := *g.PlanDisplayCmd.Output
 		if *g.PlanDisplayCmd.Short {
 			outputFormat = constants.EncodingShort
 		}
-		return displayOperationPlan(localEnv, g,
-			*g.PlanCmd.OperationID, outputFormat)
+		return displayOperationPlan(localEnv, g, *g.PlanCmd.OperationID,
+			displayPlanOptions{
+				format:  outputFormat,
+				phaseID: *g.PlanDisplayCmd.Phase,
+				timing:  *g.PlanDisplayCmd.Timing,
+			})
 	case g.PlanCompleteCmd.FullCommand():
+		if *g.PlanCmd.DryRun {
+			return trace.BadParameter("--dry-run is not supported for plan completion")
+		}
 		return completeOperationPlan(localEnv, g, *g.PlanCmd.OperationID)
 	case g.LeaveCmd.FullCommand():
 		return leave(localEnv, leaveConfig{
@@ -571,13 +610,21 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 		return pushApp(localEnv,
 			*g.AppPushCmd.Package,
 			*g.AppPushCmd.OpsCenterURL)
-	case g.AppHookCmd.FullCommand():
+	case g.AppHookRunCmd.FullCommand():
 		req := appapi.HookRunRequest{
-			Application: *g.AppHookCmd.Package,
-			Hook:        schema.HookType(*g.AppHookCmd.HookName),
-			Env:         *g.AppHookCmd.Env,
+			Application: *g.AppHookRunCmd.Package,
+			Hook:        schema.HookType(*g.AppHookRunCmd.HookName),
+			Env:         *g.AppHookRunCmd.Env,
 		}
 		return outputAppHook(localEnv, req)
+	case g.AppHookHistoryCmd.FullCommand():
+		return appHookHistory(localEnv, appHookHistoryConfig{
+			app:    *g.AppHookHistoryCmd.App,
+			hook:   *g.AppHookHistoryCmd.Hook,
+			limit:  *g.AppHookHistoryCmd.Limit,
+			id:     *g.AppHookHistoryCmd.ID,
+			format: *g.AppHookHistoryCmd.Format,
+		})
 	case g.AppUnpackCmd.FullCommand():
 		return unpackAppResources(localEnv,
 			*g.AppUnpackCmd.Package,
@@ -617,6 +664,8 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			*g.PackDeleteCmd.Locator,
 			*g.PackDeleteCmd.Force,
 			*g.PackDeleteCmd.OpsCenterURL)
+	case g.PackMigrateCmd.FullCommand():
+		return migratePackages(localEnv)
 	case g.PackConfigureCmd.FullCommand():
 		return configurePackage(localEnv,
 			*g.PackConfigureCmd.Package,
@@ -679,7 +728,20 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			*g.APIKeyDeleteCmd.Email,
 			*g.APIKeyDeleteCmd.Token)
 	case g.ReportCmd.FullCommand():
-		return getClusterReport(localEnv, *g.ReportCmd.FilePath)
+		timeRange, err := parseTimeRange(*g.ReportCmd.Since, *g.ReportCmd.Until)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		return getClusterReport(localEnv, *g.ReportCmd.FilePath, ops.GetClusterReportRequest{
+			Profile:    *g.ReportCmd.Profile,
+			Filters:    *g.ReportCmd.Collectors,
+			Since:      timeRange.Since,
+			Until:      timeRange.Until,
+			MaxSize:    int64(*g.ReportCmd.MaxSize),
+			Namespaces: *g.ReportCmd.Namespaces,
+			Nodes:      *g.ReportCmd.Nodes,
+			RedactIPs:  *g.ReportCmd.RedactIPs,
+		})
 	// cluster commands
 	case g.SiteListCmd.FullCommand():
 		return listSites(localEnv, *g.SiteListCmd.OpsCenterURL)
@@ -744,18 +806,35 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			*g.SystemRollbackCmd.WithStatus)
 	case g.SystemStepDownCmd.FullCommand():
 		return stepDown(localEnv)
-	case g.BackupCmd.FullCommand():
+	case g.BackupCreateCmd.FullCommand():
 		return backup(localEnv,
-			*g.BackupCmd.Tarball,
-			*g.BackupCmd.Timeout,
-			*g.BackupCmd.Follow,
+			*g.BackupCreateCmd.Tarball,
+			*g.BackupCreateCmd.Timeout,
+			*g.BackupCreateCmd.Follow,
 			*g.Silent)
+	case g.BackupListCmd.FullCommand():
+		return listBackups(localEnv, *g.BackupListCmd.Format)
+	case g.BackupGetCmd.FullCommand():
+		return getBackup(localEnv,
+			*g.BackupGetCmd.Name,
+			*g.BackupGetCmd.Output)
+	case g.BackupDeleteCmd.FullCommand():
+		return deleteBackup(localEnv, *g.BackupDeleteCmd.Name)
 	case g.RestoreCmd.FullCommand():
+		if *g.RestoreCmd.Verify {
+			return verifyRestore(localEnv,
+				*g.RestoreCmd.Tarball,
+				*g.RestoreCmd.Timeout,
+				*g.RestoreCmd.Follow,
+				*g.Silent,
+				*g.RestoreCmd.EncryptionKey)
+		}
 		return restore(localEnv,
 			*g.RestoreCmd.Tarball,
 			*g.RestoreCmd.Timeout,
 			*g.RestoreCmd.Follow,
-			*g.Silent)
+			*g.Silent,
+			*g.RestoreCmd.EncryptionKey)
 	case g.SystemServiceInstallCmd.FullCommand():
 		req := &systemservice.NewPackageServiceRequest{
 			Package:       *g.SystemServiceInstallCmd.Package,
@@ -787,18 +866,48 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 	case g.SystemUninstallCmd.FullCommand():
 		return systemUninstall(localEnv, *g.SystemUninstallCmd.Confirmed)
 	case g.SystemReportCmd.FullCommand():
-		return systemReport(localEnv,
-			*g.SystemReportCmd.Filter,
-			*g.SystemReportCmd.Compressed,
-			os.Stdout)
+		timeRange, err := parseTimeRange(*g.SystemReportCmd.Since, *g.SystemReportCmd.Until)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		return systemReport(localEnv, report.Config{
+			Filters:    *g.SystemReportCmd.Filter,
+			Compressed: *g.SystemReportCmd.Compressed,
+			TimeRange:  *timeRange,
+			MaxSize:    int64(*g.SystemReportCmd.MaxSize),
+			Namespaces: *g.SystemReportCmd.Namespaces,
+		}, os.Stdout)
 	case g.SystemStateDirCmd.FullCommand():
 		return printStateDir()
+	case g.SystemStateExportCmd.FullCommand():
+		return exportState(localEnv, *g.SystemStateExportCmd.Path, stateBackendConfig{
+			backendType: *g.SystemStateExportCmd.Backend,
+			boltPath:    *g.SystemStateExportCmd.BoltPath,
+			postgresURI: *g.SystemStateExportCmd.PostgresURI,
+		})
+	case g.SystemStateImportCmd.FullCommand():
+		return importState(localEnv, *g.SystemStateImportCmd.Path, stateBackendConfig{
+			backendType: *g.SystemStateImportCmd.Backend,
+			boltPath:    *g.SystemStateImportCmd.BoltPath,
+			postgresURI: *g.SystemStateImportCmd.PostgresURI,
+		}, *g.SystemStateImportCmd.DryRun, *g.SystemStateImportCmd.Force)
 	case g.SystemExportRuntimeJournalCmd.FullCommand():
-		return exportRuntimeJournal(localEnv, *g.SystemExportRuntimeJournalCmd.OutputFile)
+		timeRange, err := parseTimeRange(*g.SystemExportRuntimeJournalCmd.Since, *g.SystemExportRuntimeJournalCmd.Until)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		return exportRuntimeJournal(localEnv, *g.SystemExportRuntimeJournalCmd.OutputFile, *timeRange)
 	case g.SystemStreamRuntimeJournalCmd.FullCommand():
-		return streamRuntimeJournal(localEnv)
+		timeRange, err := parseTimeRange(*g.SystemStreamRuntimeJournalCmd.Since, *g.SystemStreamRuntimeJournalCmd.Until)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		return streamRuntimeJournal(localEnv, *timeRange)
 	case g.GarbageCollectCmd.FullCommand():
-		return garbageCollect(localEnv, *g.GarbageCollectCmd.Manual, *g.GarbageCollectCmd.Confirmed)
+		return garbageCollect(localEnv,
+			*g.GarbageCollectCmd.Manual,
+			*g.GarbageCollectCmd.Confirmed,
+			*g.GarbageCollectCmd.RegistryAllowlist)
 	case g.SystemGCJournalCmd.FullCommand():
 		return removeUnusedJournalFiles(localEnv,
 			*g.SystemGCJournalCmd.MachineIDFile,
@@ -810,7 +919,8 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 	case g.SystemGCRegistryCmd.FullCommand():
 		return removeUnusedImages(localEnv,
 			*g.SystemGCRegistryCmd.DryRun,
-			*g.SystemGCRegistryCmd.Confirm)
+			*g.SystemGCRegistryCmd.Confirm,
+			*g.SystemGCRegistryCmd.Allowlist)
 	case g.PlanetEnterCmd.FullCommand(), g.EnterCmd.FullCommand():
 		return planetEnter(localEnv, extraArgs)
 	case g.ExecCmd.FullCommand():
@@ -860,6 +970,32 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			*g.ResourceGetCmd.WithSecrets,
 			*g.ResourceGetCmd.Format,
 			*g.ResourceGetCmd.User)
+	case g.ResourceDiffCmd.FullCommand():
+		return diffResources(localEnv,
+			*g.ResourceDiffCmd.Path,
+			*g.ResourceDiffCmd.Prune,
+			*g.ResourceDiffCmd.PruneKinds,
+			*g.ResourceDiffCmd.User)
+	case g.ResourceApplyCmd.FullCommand():
+		return applyResources(localEnv, g,
+			*g.ResourceApplyCmd.Path,
+			*g.ResourceApplyCmd.Prune,
+			*g.ResourceApplyCmd.PruneKinds,
+			*g.ResourceApplyCmd.User,
+			*g.ResourceApplyCmd.Manual,
+			*g.ResourceApplyCmd.Confirmed)
+	case g.ResourceHistoryCmd.FullCommand():
+		return resourceHistory(localEnv,
+			*g.ResourceHistoryCmd.Resource,
+			*g.ResourceHistoryCmd.Revision,
+			*g.ResourceHistoryCmd.Limit,
+			*g.ResourceHistoryCmd.Format)
+	case g.ResourceRollbackCmd.FullCommand():
+		return rollbackResource(localEnv, g,
+			*g.ResourceRollbackCmd.Resource,
+			*g.ResourceRollbackCmd.Revision,
+			*g.ResourceRollbackCmd.Manual,
+			*g.ResourceRollbackCmd.Confirmed)
 	case g.RPCAgentDeployCmd.FullCommand():
 		return rpcAgentDeploy(localEnv,
 			*g.RPCAgentDeployCmd.LeaderArgs,
@@ -876,13 +1012,26 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			*g.RPCAgentRunCmd.Args)
 	case g.RPCAgentShutdownCmd.FullCommand():
 		return rpcAgentShutdown(localEnv)
-	case g.CheckCmd.FullCommand():
+	case g.CheckPreflightCmd.FullCommand():
 		return executePreflightChecks(localEnv, preflightChecksConfig{
-			manifestPath: *g.CheckCmd.ManifestFile,
-			imagePath:    *g.CheckCmd.ImagePath,
-			profileName:  *g.CheckCmd.Profile,
-			autoFix:      *g.CheckCmd.AutoFix,
-			timeout:      *g.CheckCmd.Timeout,
+			manifestPath:  *g.CheckPreflightCmd.ManifestFile,
+			imagePath:     *g.CheckPreflightCmd.ImagePath,
+			profileName:   *g.CheckPreflightCmd.Profile,
+			autoFix:       *g.CheckPreflightCmd.AutoFix,
+			legacyAutoFix: *g.CheckPreflightCmd.LegacyAutoFix,
+			stopServices:  *g.CheckPreflightCmd.StopServices,
+			confirmed:     *g.CheckPreflightCmd.Confirmed,
+			revert:        *g.CheckPreflightCmd.Revert,
+			timeout:       *g.CheckPreflightCmd.Timeout,
+			format:        *g.CheckPreflightCmd.Format,
+		})
+	case g.CheckNetworkCmd.FullCommand():
+		return checkNetwork(localEnv, checkNetworkConfig{
+			testBandwidth: *g.CheckNetworkCmd.Bandwidth,
+			pingCount:     *g.CheckNetworkCmd.PingCount,
+			interval:      *g.CheckNetworkCmd.Interval,
+			history:       *g.CheckNetworkCmd.History,
+			format:        *g.CheckNetworkCmd.Format,
 		})
 	case g.TopCmd.FullCommand():
 		return top(localEnv,
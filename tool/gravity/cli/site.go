@@ -121,7 +121,7 @@ func listSites(env *localenv.LocalEnvironment, opsCenterURL string) error {
 	return nil
 }
 
-func getClusterReport(env *localenv.LocalEnvironment, targetFile string) error {
+func getClusterReport(env *localenv.LocalEnvironment, targetFile string, req ops.GetClusterReportRequest) error {
 	f, err := os.Create(targetFile)
 	if err != nil {
 		return trace.Wrap(err)
@@ -138,10 +138,11 @@ func getClusterReport(env *localenv.LocalEnvironment, targetFile string) error {
 		return trace.Wrap(err)
 	}
 
-	report, err := operator.GetSiteReport(ops.SiteKey{
+	req.SiteKey = ops.SiteKey{
 		AccountID:  site.AccountID,
 		SiteDomain: site.Domain,
-	})
+	}
+	report, err := operator.GetSiteReport(req)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -0,0 +1,159 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"io"
+	"os"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/gravitational/trace"
+)
+
+// stateBackendConfig describes the storage backend to export
+// the state from or import the state into
+type stateBackendConfig struct {
+	// backendType is the type of the backend
+	backendType string
+	// boltPath is the path to the bolt database
+	boltPath string
+	// postgresURI is the PostgreSQL connection URI
+	postgresURI string
+}
+
+// open connects to the configured storage backend
+func (r stateBackendConfig) open() (storage.Backend, error) {
+	switch r.backendType {
+	case constants.BoltBackend:
+		if r.boltPath == "" {
+			return nil, trace.BadParameter("bolt backend requires --bolt-path")
+		}
+		backend, err := keyval.NewBolt(keyval.BoltConfig{
+			Path:  r.boltPath,
+			Multi: true,
+		})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return backend, nil
+	case constants.ETCDBackend:
+		config, err := keyval.LocalEtcdConfig(0)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		backend, err := keyval.NewETCD(*config)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return backend, nil
+	case constants.PostgresBackend:
+		if r.postgresURI == "" {
+			return nil, trace.BadParameter("postgres backend requires --postgres-uri")
+		}
+		backend, err := keyval.NewPostgres(keyval.PostgresConfig{URI: r.postgresURI})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return backend, nil
+	}
+	return nil, trace.BadParameter("unsupported backend %q", r.backendType)
+}
+
+// exportState exports the state of the configured storage backend
+// to the archive at the specified path
+func exportState(env *localenv.LocalEnvironment, path string, config stateBackendConfig) error {
+	backend, err := config.open()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer backend.Close()
+	if path == "-" {
+		_, err = keyval.ExportState(backend, os.Stdout, keyval.ExportConfig{})
+		return trace.Wrap(err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, defaults.PrivateFileMask)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	footer, err := keyval.ExportState(backend, f, keyval.ExportConfig{})
+	if err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		os.Remove(path)
+		return trace.Wrap(err)
+	}
+	env.Printf("Exported %v items from %v backend to %v.\n", footer.Items, config.backendType, path)
+	return nil
+}
+
+// importState imports the state archive at the specified path
+// into the configured storage backend
+func importState(env *localenv.LocalEnvironment, path string, config stateBackendConfig, dryRun, force bool) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		defer f.Close()
+		r = f
+	}
+	archive, err := keyval.ReadStateArchive(r)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.Printf("Importing %v items exported from %v backend on %v.\n",
+		len(archive.Items), archive.Header.Backend, archive.Header.Created.Format(constants.HumanDateFormatSeconds))
+	backend, err := config.open()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer backend.Close()
+	result, err := keyval.ImportState(keyval.ImportConfig{
+		Backend: backend,
+		Archive: *archive,
+		DryRun:  dryRun,
+		Force:   force,
+	})
+	if result != nil && len(result.Conflicts) != 0 {
+		env.Println("Items that conflict with the existing state:")
+		for _, conflict := range result.Conflicts {
+			env.Printf("  * %v\n", conflict)
+		}
+	}
+	if trace.IsCompareFailed(err) {
+		return trace.BadParameter("%v items conflict with the existing state, use --force to overwrite them",
+			len(result.Conflicts))
+	}
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.Printf("Created: %v, overwritten: %v, unchanged: %v, expired: %v.\n",
+		len(result.Created), len(result.Conflicts), result.Unchanged, len(result.Expired))
+	if dryRun {
+		env.Println("Dry run, no changes have been made.")
+	}
+	return nil
+}